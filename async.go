@@ -0,0 +1,453 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrInvalidRingCapacity represents that the given ring capacity of
+	// the asynchronous exporter is invalid. The capacity must be a power
+	// of 2 and greater than 0.
+	ErrInvalidRingCapacity = errors.New("invalid ring capacity")
+
+	// ErrInvalidExporter represents that the given exporter is invalid,
+	// usually because the exporter is not provided.
+	ErrInvalidExporter = errors.New("invalid exporter")
+)
+
+// entryRingSlot is the structure of a slot of the entry ring.
+type entryRingSlot struct {
+	sequence uint64
+	entry *Entry
+}
+
+// entryRing is the structure of a bounded lock-free multi-producer
+// single-consumer ring of log entries.
+//
+// Each slot has a sequence number that indicates whether the slot can be
+// written by producers or read by the consumer, so producers only contend
+// on the head position through CAS operations, and the consumer never
+// contends with producers.
+type entryRing struct {
+	head uint64
+	_ [7]uint64
+	tail uint64
+	_ [7]uint64
+	mask uint64
+	slots []entryRingSlot
+}
+
+// push appends the given log entry to the ring. It returns false if the
+// ring is full.
+//
+// This function is thread-safe.
+func (r *entryRing) push(entry *Entry) bool {
+	for {
+		position := atomic.LoadUint64(&r.head)
+		slot := &r.slots[position & r.mask]
+		difference := int64(atomic.LoadUint64(&slot.sequence) - position)
+		if difference == 0 {
+			if atomic.CompareAndSwapUint64(&r.head, position, position + 1) {
+				slot.entry = entry
+				atomic.StoreUint64(&slot.sequence, position + 1)
+				return true
+			}
+		} else if difference < 0 {
+			return false
+		}
+	}
+}
+
+// pop removes and returns the oldest log entry of the ring. It returns nil
+// if the ring is empty.
+//
+// Please note that this function must only be called by the consumer.
+func (r *entryRing) pop() *Entry {
+	position := r.tail
+	slot := &r.slots[position & r.mask]
+	if atomic.LoadUint64(&slot.sequence) != position + 1 {
+		return nil
+	}
+	entry := slot.entry
+	slot.entry = nil
+	atomic.StoreUint64(&slot.sequence, position + r.mask + 1)
+	atomic.StoreUint64(&r.tail, position + 1)
+	return entry
+}
+
+// newEntryRing creates and returns an entry ring instance with the given
+// capacity, which must be a power of 2.
+func newEntryRing(capacity int) *entryRing {
+	instance := &entryRing {
+		mask: uint64(capacity - 1),
+		slots: make([]entryRingSlot, capacity),
+	}
+	for index := 0; index < capacity; index++ {
+		instance.slots[index].sequence = uint64(index)
+	}
+	return instance
+}
+
+// AsyncExporter is the structure of the asynchronous exporter instance.
+//
+// The asynchronous exporter decouples loggers from a specific exporter.
+// Snapshots of log entries are appended to a bounded lock-free ring, and a
+// single coroutine exports them to the specific exporter in order, so that
+// loggers do not contend on the encoder and synchronizer of the exporter,
+// which reduces the tail latency of the output APIs under contention.
+//
+// If the ring is full, the output APIs either wait for free space or drop
+// the log entry, depending on the DropOnFull option. Errors of the specific
+// exporter cannot be returned to loggers, so they are reported to the
+// diagnostics channel.
+//
+// The API provided by the asynchronous exporter is thread-safe.
+type AsyncExporter struct {
+	exporter Exporter
+	ring *entryRing
+	dropOnFull bool
+
+	pushed uint64
+	popped uint64
+	exported uint64
+	dropped uint64
+	abandoned uint64
+	sleeping int32
+	closed int32
+	abandoning int32
+	producers int32
+	waiters int32
+
+	wake chan struct { }
+	progressMutex sync.Mutex
+	progress chan struct { }
+	context context.Context
+	contextCancel context.CancelFunc
+	contextWaitGroup *sync.WaitGroup
+}
+
+// Export appends a snapshot of the given log entry to the ring, and then
+// returns any errors encountered. The log entry is exported to the specific
+// exporter asynchronously.
+//
+// If the exporter is closed while waiting for free space of the ring, the
+// log entry is abandoned and ErrClosed is returned.
+func (e *AsyncExporter) Export(entry *Entry) error {
+	// The producer is counted before checking whether the exporter is
+	// closed, so that the Shutdown function can wait for the producers
+	// that have passed the check, and no log entry is appended after the
+	// ring is drained.
+	atomic.AddInt32(&e.producers, 1)
+	defer e.leave()
+	if atomic.LoadInt32(&e.closed) == 1 {
+		return ErrClosed
+	}
+	instance := entry.Clone()
+	for !e.ring.push(instance) {
+		if e.dropOnFull {
+			atomic.AddUint64(&e.dropped, 1)
+			return nil
+		}
+		if atomic.LoadInt32(&e.closed) == 1 {
+			atomic.AddUint64(&e.abandoned, 1)
+			return ErrClosed
+		}
+		// Wait until a log entry has been popped from the ring or the
+		// exporter has been closed.
+		popped := atomic.LoadUint64(&e.popped)
+		e.await(context.Background(), func() bool {
+			return atomic.LoadUint64(&e.popped) != popped ||
+				atomic.LoadInt32(&e.closed) == 1
+		})
+	}
+	atomic.AddUint64(&e.pushed, 1)
+	if atomic.CompareAndSwapInt32(&e.sleeping, 1, 0) {
+		select {
+		case e.wake <- struct { } { }:
+		default:
+		}
+	}
+	return nil
+}
+
+// leave uncounts a producer of the Export function, and then wakes up the
+// Shutdown function if the exporter has been closed.
+func (e *AsyncExporter) leave() {
+	atomic.AddInt32(&e.producers, -1)
+	if atomic.LoadInt32(&e.closed) == 1 {
+		e.notify()
+	}
+}
+
+// await waits until the given condition is satisfied or the given context
+// is done. The condition is checked again each time the notify function is
+// called. It returns false if the context is done first.
+func (e *AsyncExporter) await(ctx context.Context, condition func() bool) bool {
+	// The waiter is counted before the condition is checked, so that the
+	// notify function called after changing the state never skips it.
+	atomic.AddInt32(&e.waiters, 1)
+	defer atomic.AddInt32(&e.waiters, -1)
+	for {
+		e.progressMutex.Lock()
+		progress := e.progress
+		e.progressMutex.Unlock()
+		if condition() {
+			return true
+		}
+		select {
+		case <-progress:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// notify wakes up the coroutines waiting in the await function, and does
+// nothing if there are none. It must be called after changing the state
+// that the waiters check.
+func (e *AsyncExporter) notify() {
+	if atomic.LoadInt32(&e.waiters) == 0 {
+		return
+	}
+	e.progressMutex.Lock()
+	close(e.progress)
+	e.progress = make(chan struct { })
+	e.progressMutex.Unlock()
+}
+
+// exportHandler exports the log entries of the ring to the specific
+// exporter until the context has been marked as complete and the ring is
+// empty.
+//
+// This function should run in an independent coroutine context.
+func (e *AsyncExporter) exportHandler() {
+	defer e.contextWaitGroup.Done()
+	failing := false
+	for {
+		entry := e.ring.pop()
+		if entry == nil {
+			// Announce sleeping before checking the ring again, so that
+			// a producer that appends a log entry after the check will
+			// wake up the coroutine.
+			atomic.StoreInt32(&e.sleeping, 1)
+			if entry = e.ring.pop(); entry == nil {
+				select {
+				case <-e.wake:
+					continue
+				case <-e.context.Done():
+					if entry = e.ring.pop(); entry == nil {
+						return
+					}
+				}
+			}
+			atomic.StoreInt32(&e.sleeping, 0)
+		}
+		atomic.AddUint64(&e.popped, 1)
+		e.notify()
+		if atomic.LoadInt32(&e.abandoning) == 1 {
+			// The deadline of the shutdown has been exceeded.
+			atomic.AddUint64(&e.abandoned, 1)
+			continue
+		}
+		err := e.exporter.Export(entry)
+		if err != nil && !failing {
+			diagnose("asynchronous exporting failed: %v", err)
+		}
+		failing = err != nil
+		atomic.AddUint64(&e.exported, 1)
+		e.notify()
+	}
+}
+
+// wait waits until the log entries appended before the call have been
+// exported.
+func (e *AsyncExporter) wait() {
+	e.waitContext(context.Background())
+}
+
+// waitContext waits until the log entries appended before the call have
+// been exported or the given context is done. It returns false if the
+// context is done first.
+func (e *AsyncExporter) waitContext(ctx context.Context) bool {
+	pushed := atomic.LoadUint64(&e.pushed)
+	return e.await(ctx, func() bool {
+		return atomic.LoadUint64(&e.exported) >= pushed
+	})
+}
+
+// Sync waits until the log entries appended before the call have been
+// exported, and then calls the Sync function of the specific exporter.
+//
+// Finally, any errors encountered are returned.
+func (e *AsyncExporter) Sync() error {
+	e.wait()
+	return e.exporter.Sync()
+}
+
+// Close stops accepting log entries, waits until the appended log entries
+// have been exported, and then closes the specific exporter.
+//
+// Finally, any errors encountered are returned.
+func (e *AsyncExporter) Close() error {
+	_, err := e.Shutdown(context.Background())
+	return err
+}
+
+// Shutdown stops accepting log entries, waits until the appended log
+// entries have been exported or the given context is done, and then shuts
+// down the specific exporter with the same context. The log entries that
+// have not been exported when the context is done are abandoned, but the
+// log entry that is being exported is still waited for.
+//
+// Finally, it returns the number of abandoned log entries, including the
+// log entries abandoned by the specific exporter, and any errors
+// encountered. If the context is done before the appended log entries
+// have been exported, the error of the context is returned. For details,
+// please refer to the comment section of the Shutdowner interface.
+func (e *AsyncExporter) Shutdown(ctx context.Context) (uint64, error) {
+	if !atomic.CompareAndSwapInt32(&e.closed, 0, 1) {
+		return 0, ErrClosed
+	}
+	// The producers that have passed the check of the Export function
+	// either append their log entries or give up soon, including the
+	// producers waiting for free space of the ring.
+	e.notify()
+	e.await(context.Background(), func() bool {
+		return atomic.LoadInt32(&e.producers) == 0
+	})
+	drained := e.waitContext(ctx)
+	if !drained {
+		atomic.StoreInt32(&e.abandoning, 1)
+	}
+	e.contextCancel()
+	e.contextWaitGroup.Wait()
+	abandoned, err := shutdownExporter(ctx, e.exporter)
+	if !drained && err == nil {
+		err = ctx.Err()
+	}
+	return atomic.LoadUint64(&e.abandoned) + abandoned, err
+}
+
+// Dropped returns the number of log entries dropped because the ring was
+// full.
+func (e *AsyncExporter) Dropped() uint64 {
+	return atomic.LoadUint64(&e.dropped)
+}
+
+// Saturation returns the ratio of the number of queued log entries to the
+// capacity of the ring, where 0 means that the ring is empty and 1 means
+// that the ring is full. For details, please refer to the comment section
+// of the Saturation interface.
+func (e *AsyncExporter) Saturation() float64 {
+	exported := atomic.LoadUint64(&e.exported)
+	pushed := atomic.LoadUint64(&e.pushed)
+	if pushed <= exported {
+		return 0
+	}
+	return float64(pushed - exported) / float64(e.ring.mask + 1)
+}
+
+// AsyncExporterOption is a structure that contains asynchronous exporter
+// options.
+type AsyncExporterOption struct {
+	// Exporter represents the exporter that log entries are exported to
+	// asynchronously. The asynchronous exporter takes ownership of it and
+	// closes it when the asynchronous exporter is closed. It must be
+	// provided.
+	Exporter Exporter
+
+	// Capacity represents the number of log entries that the ring can
+	// hold. The value must be a power of 2. If not provided, the default
+	// value is 1024.
+	Capacity int
+
+	// DropOnFull represents whether to drop log entries when the ring is
+	// full instead of waiting for free space. The number of dropped log
+	// entries can be obtained by the Dropped function. If not provided,
+	// the default value is false.
+	DropOnFull bool
+}
+
+// UseExporter uses the given exporter as the value of the option Exporter.
+// For details, please refer to the comment section of the Exporter option.
+// Then return to the option instance itself.
+func (o *AsyncExporterOption) UseExporter(exporter Exporter) *AsyncExporterOption {
+	o.Exporter = exporter
+	return o
+}
+
+// UseCapacity uses the given capacity as the value of the option Capacity.
+// For details, please refer to the comment section of the Capacity option.
+// Then return to the option instance itself.
+func (o *AsyncExporterOption) UseCapacity(capacity int) *AsyncExporterOption {
+	o.Capacity = capacity
+	return o
+}
+
+// UseDropOnFull enables the option DropOnFull. For details, please refer
+// to the comment section of the DropOnFull option. Then return to the
+// option instance itself.
+func (o *AsyncExporterOption) UseDropOnFull() *AsyncExporterOption {
+	o.DropOnFull = true
+	return o
+}
+
+// Build builds and returns an asynchronous exporter instance and any
+// errors encountered.
+func (o *AsyncExporterOption) Build() (*AsyncExporter, error) {
+	if o.Exporter == nil {
+		return nil, ErrInvalidExporter
+	}
+	if o.Capacity <= 0 || o.Capacity & (o.Capacity - 1) != 0 {
+		return nil, ErrInvalidRingCapacity
+	}
+	context, contextCancel := context.WithCancel(
+		context.Background())
+	instance := &AsyncExporter {
+		exporter: o.Exporter,
+		ring: newEntryRing(o.Capacity),
+		dropOnFull: o.DropOnFull,
+		wake: make(chan struct { }, 1),
+		progress: make(chan struct { }),
+		context: context,
+		contextCancel: contextCancel,
+		contextWaitGroup: &sync.WaitGroup { },
+	}
+	instance.contextWaitGroup.Add(1)
+	go instance.exportHandler()
+	return instance, nil
+}
+
+// NewAsyncExporterOption creates and returns an instance of the
+// asynchronous exporter option with default optional values. The exporter
+// must be provided before building.
+func NewAsyncExporterOption() *AsyncExporterOption {
+	return &AsyncExporterOption {
+		Capacity: 1024,
+	}
+}
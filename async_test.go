@@ -0,0 +1,302 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntryRing(t *testing.T) {
+	ring := newEntryRing(4)
+	assert.Nil(t, ring.pop(), "Unexpected ring entry")
+
+	entries := make([]*Entry, 5)
+	for index := 0; index < len(entries); index++ {
+		entries[index] = &Entry { Sequence: uint64(index) }
+	}
+	for index := 0; index < 4; index++ {
+		assert.True(t, ring.push(entries[index]), "Unexpected push result")
+	}
+	assert.False(t, ring.push(entries[4]), "Unexpected push result")
+
+	assert.Equal(t, entries[0], ring.pop(), "Unexpected ring entry")
+	assert.True(t, ring.push(entries[4]), "Unexpected push result")
+	for index := 1; index < len(entries); index++ {
+		assert.Equal(t, entries[index], ring.pop(), "Unexpected ring entry")
+	}
+	assert.Nil(t, ring.pop(), "Unexpected ring entry")
+}
+
+func TestAsyncExporterOption(t *testing.T) {
+	option := NewAsyncExporterOption()
+
+	_, err := option.Build()
+	assert.Equal(t, ErrInvalidExporter, err, "Unexpected build error")
+
+	exporter := &testRecordExporter { }
+	option.UseExporter(exporter)
+	option.UseCapacity(100)
+	option.UseDropOnFull()
+
+	assert.Equal(t, exporter, option.Exporter, "Unexpected option value")
+	assert.Equal(t, 100, option.Capacity, "Unexpected option value")
+	assert.True(t, option.DropOnFull, "Unexpected option value")
+
+	_, err = option.Build()
+	assert.Equal(t, ErrInvalidRingCapacity, err, "Unexpected build error")
+}
+
+func TestAsyncExporter(t *testing.T) {
+	exporter := &testRecordExporter { }
+
+	async, err := NewAsyncExporterOption().
+		UseExporter(exporter).
+		UseCapacity(16).Build()
+	assert.NoError(t, err, "Unexpected create error")
+
+	group := sync.WaitGroup { }
+	for index := 0; index < 4; index++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			for count := 0; count < 100; count++ {
+				_ = async.Export(entry)
+			}
+		}()
+	}
+	group.Wait()
+
+	assert.NoError(t, async.Sync(), "Unexpected sync error")
+	assert.Len(t, exporter.entries, 400, "Unexpected exported entries")
+	assert.Equal(t, uint64(0), async.Dropped(), "Unexpected dropped count")
+
+	assert.NoError(t, async.Export(entry), "Unexpected export error")
+	assert.NoError(t, async.Close(), "Unexpected close error")
+	assert.Len(t, exporter.entries, 401, "Unexpected exported entries")
+	assert.Equal(t, ErrClosed, async.Export(entry), "Unexpected export error")
+}
+
+// testBlockExporter is an exporter whose first export blocks until the
+// release channel is closed.
+type testBlockExporter struct {
+	testRecordExporter
+	started chan struct { }
+	release chan struct { }
+}
+
+func (e *testBlockExporter) Export(entry *Entry) error {
+	if len(e.entries) == 0 {
+		close(e.started)
+		<-e.release
+	}
+	return e.testRecordExporter.Export(entry)
+}
+
+func TestAsyncExporterShutdown(t *testing.T) {
+	exporter := &testBlockExporter {
+		started: make(chan struct { }),
+		release: make(chan struct { }),
+	}
+	async, err := NewAsyncExporterOption().
+		UseExporter(exporter).
+		UseCapacity(16).Build()
+	assert.NoError(t, err, "Unexpected create error")
+
+	for index := 0; index < 5; index++ {
+		assert.NoError(t, async.Export(entry), "Unexpected export error")
+	}
+	<-exporter.started
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		time.Millisecond * 20)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		// Wait for the shutdown to notice that the deadline has been
+		// exceeded before the blocked export returns.
+		time.Sleep(time.Millisecond * 50)
+		close(exporter.release)
+	}()
+	abandoned, err := async.Shutdown(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded),
+		"Unexpected shutdown error")
+	assert.Equal(t, uint64(4), abandoned, "Unexpected abandoned count")
+	assert.Len(t, exporter.entries, 1, "Unexpected exported entries")
+
+	_, err = async.Shutdown(context.Background())
+	assert.Equal(t, ErrClosed, err, "Unexpected shutdown error")
+}
+
+func TestAsyncExporterShutdownFull(t *testing.T) {
+	exporter := &testBlockExporter {
+		started: make(chan struct { }),
+		release: make(chan struct { }),
+	}
+	async, err := NewAsyncExporterOption().
+		UseExporter(exporter).
+		UseCapacity(2).Build()
+	assert.NoError(t, err, "Unexpected create error")
+
+	for index := 0; index < 3; index++ {
+		assert.NoError(t, async.Export(entry), "Unexpected export error")
+	}
+	<-exporter.started
+
+	// The producer waits for free space of the full ring until the
+	// exporter is closed.
+	result := make(chan error, 1)
+	go func() {
+		result <- async.Export(entry)
+	}()
+	for atomic.LoadInt32(&async.producers) == 0 {
+		runtime.Gosched()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		time.Millisecond * 20)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		time.Sleep(time.Millisecond * 50)
+		close(exporter.release)
+	}()
+	abandoned, err := async.Shutdown(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded),
+		"Unexpected shutdown error")
+	assert.Equal(t, ErrClosed, <-result, "Unexpected export error")
+	assert.Equal(t, uint64(3), abandoned, "Unexpected abandoned count")
+	assert.Len(t, exporter.entries, 1, "Unexpected exported entries")
+}
+
+func TestAsyncExporterShutdownConcurrent(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		exporter := &testRecordExporter { }
+		async, err := NewAsyncExporterOption().
+			UseExporter(exporter).
+			UseCapacity(4).Build()
+		assert.NoError(t, err, "Unexpected create error")
+
+		var accepted, rejected uint64
+		group := sync.WaitGroup { }
+		for index := 0; index < 4; index++ {
+			group.Add(1)
+			go func() {
+				defer group.Done()
+				for count := 0; count < 100; count++ {
+					if async.Export(entry) == nil {
+						atomic.AddUint64(&accepted, 1)
+					} else {
+						atomic.AddUint64(&rejected, 1)
+					}
+				}
+			}()
+		}
+		abandoned, err := async.Shutdown(context.Background())
+		assert.NoError(t, err, "Unexpected shutdown error")
+		group.Wait()
+
+		// Each accepted log entry is exported, and the log entries that
+		// were waiting for free space when the exporter was closed are
+		// abandoned.
+		assert.Len(t, exporter.entries, int(accepted),
+			"Unexpected exported entries")
+		assert.LessOrEqual(t, abandoned, rejected,
+			"Unexpected abandoned count")
+	}
+}
+
+// testStallWriter is a writer that stalls periodically, simulating the
+// latency spikes of a real storage device.
+type testStallWriter struct {
+	count uint64
+}
+
+func (w *testStallWriter) Write(buffer []byte) (int, error) {
+	if atomic.AddUint64(&w.count, 1) % 512 == 0 {
+		time.Sleep(time.Microsecond * 200)
+	}
+	return len(buffer), nil
+}
+
+func newBenchmarkExporter() Exporter {
+	syncer, _ := NewStandardSyncerOption().
+		UseWriter(&testStallWriter { }).
+		UseCacheCapacity(0).Build()
+	exporter, _ := NewStandardExporterOption().
+		UseSyncer(syncer).Build()
+	return exporter
+}
+
+// benchmarkExportLatency exports log entries from parallel goroutines
+// paced by simulated application work, and reports the median and tail
+// latency of the Export function.
+func benchmarkExportLatency(b *testing.B, exporter Exporter) {
+	work := time.Duration(runtime.GOMAXPROCS(0)) * time.Microsecond
+	mutex := sync.Mutex { }
+	var latencies []time.Duration
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		local := make([]time.Duration, 0, 1024)
+		for pb.Next() {
+			for start := time.Now(); time.Since(start) < work; {
+			}
+			start := time.Now()
+			_ = exporter.Export(entry)
+			local = append(local, time.Since(start))
+		}
+		mutex.Lock()
+		latencies = append(latencies, local...)
+		mutex.Unlock()
+	})
+	b.StopTimer()
+	_ = exporter.Close()
+
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+	b.ReportMetric(float64(latencies[len(latencies) / 2]), "p50-ns")
+	b.ReportMetric(float64(latencies[len(latencies) * 99 / 100]), "p99-ns")
+	b.ReportMetric(float64(latencies[len(latencies) * 999 / 1000]), "p999-ns")
+}
+
+func BenchmarkStandardExporterLatency(b *testing.B) {
+	benchmarkExportLatency(b, newBenchmarkExporter())
+}
+
+func BenchmarkAsyncExporterLatency(b *testing.B) {
+	exporter, _ := NewAsyncExporterOption().
+		UseExporter(newBenchmarkExporter()).
+		UseCapacity(8192).Build()
+	benchmarkExportLatency(b, exporter)
+}
@@ -0,0 +1,337 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+var (
+	// ErrAuditChainBroken represents that a record of an audit log does
+	// not match the hash chain, which means that the audit log has been
+	// modified, truncated in the middle or corrupted.
+	ErrAuditChainBroken = errors.New("audit chain broken")
+
+	// ErrInvalidAudit represents that the given audit exporter option is
+	// invalid, usually because the encoder or the file name is not
+	// provided.
+	ErrInvalidAudit = errors.New("invalid audit option")
+
+	// errAuditRecordTorn represents that the last record of an audit log
+	// has no newline, which means that its writing has been interrupted.
+	errAuditRecordTorn = errors.New("torn audit record")
+)
+
+// auditHashLength is the length of the hexadecimal hash of the chain and
+// the following space at the beginning of each record.
+const auditHashLength = sha256.Size * 2 + 1
+
+// AuditExporter is the structure of the audit exporter instance.
+//
+// The audit exporter writes log entries to a tamper-evident audit log
+// for compliance use cases. Each log entry is encoded, written to the
+// file without internal caching and synchronized to the persistent
+// storage device before the Export function returns.
+//
+// Each record of the audit log is the hexadecimal SHA-256 hash of the
+// chain, followed by a space and the encoded log entry data. The hash
+// of each record is calculated from the hash of the previous record and
+// the encoded log entry data of the record, and the hash of the first
+// record is calculated from 32 zero bytes. Modifying, inserting or
+// removing any record breaks the chain of all subsequent records, which
+// can be detected by the VerifyAuditLog function.
+//
+// Records are separated by newlines, so the encoder must encode each log
+// entry into a single line ending with a newline, such as the JSON
+// encoder does.
+type AuditExporter struct {
+	span LevelSpan
+	encoder Encoder
+	name string
+	syncer Syncer
+	mutex sync.Mutex
+	hash [sha256.Size]byte
+	offset int64
+	torn bool
+	buffer []byte
+}
+
+// Export encodes a given log entry, chains it to the previous record and
+// writes the record to the audit log, and then synchronizes the audit
+// log to the persistent storage device.
+//
+// Finally, any errors encountered are returned.
+func (e *AuditExporter) Export(entry *Entry) error {
+	if !e.span.Contains(entry.Level) {
+		return nil
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.torn {
+		if err := e.tear(); err != nil {
+			return err
+		}
+	}
+	// The first bytes of the buffer are reserved for the hash, which is
+	// only known after the log entry has been encoded.
+	var reserved [auditHashLength]byte
+	buffer, err := e.encoder.Encode(append(e.buffer[ : 0],
+		reserved[ : ]...), entry)
+	if err != nil {
+		return err
+	}
+	e.buffer = buffer[ : 0]
+	hash := auditHash(e.hash, buffer[auditHashLength : ])
+	hex.Encode(buffer, hash[ : ])
+	buffer[sha256.Size * 2] = ' '
+	if _, err = e.syncer.Write(buffer); err != nil {
+		// The part of the record that has been written is removed, so
+		// that the next record is not appended to it.
+		e.torn = true
+		_ = e.tear()
+		return err
+	}
+	// The chain only advances after the record has been written, so that
+	// a failed record does not break the chain of subsequent records.
+	e.hash = hash
+	e.offset += int64(len(buffer))
+	return e.syncer.Sync()
+}
+
+// tear truncates the audit log to the end of the last record written,
+// which removes the part of a failed record, and then returns any errors
+// encountered. The caller must hold the mutex.
+func (e *AuditExporter) tear() error {
+	if err := os.Truncate(e.name, e.offset); err != nil {
+		return err
+	}
+	e.torn = false
+	return nil
+}
+
+// Hash returns the hash of the chain of the last record written to the
+// audit log, which can be stored elsewhere to detect the removal of the
+// last records.
+func (e *AuditExporter) Hash() [sha256.Size]byte {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.hash
+}
+
+// Sync synchronizes the audit log to the persistent storage device. Each
+// record is already synchronized when it is exported.
+//
+// Finally, any errors encountered are returned.
+func (e *AuditExporter) Sync() error {
+	return e.syncer.Sync()
+}
+
+// Close closes the audit log.
+//
+// Finally, any errors encountered are returned.
+func (e *AuditExporter) Close() error {
+	return e.syncer.Close()
+}
+
+// auditHash returns the hash of the chain of a record with the given
+// hash of the previous record and the given encoded log entry data.
+func auditHash(previous [sha256.Size]byte, data []byte) [sha256.Size]byte {
+	hash := sha256.New()
+	_, _ = hash.Write(previous[ : ])
+	_, _ = hash.Write(data)
+	var result [sha256.Size]byte
+	hash.Sum(result[ : 0])
+	return result
+}
+
+// VerifyAuditLog reads the records of an audit log from the given reader
+// and checks the hash chain of each record. For details, please refer to
+// the comment section of the AuditExporter structure.
+//
+// Finally, it returns the hash of the chain of the last valid record, the
+// number of valid records and any errors encountered. If a record does not
+// match the hash chain, ErrAuditChainBroken is returned.
+func VerifyAuditLog(reader io.Reader) ([sha256.Size]byte, int, error) {
+	hash, records, _, err := verifyAuditLog(reader)
+	if err == errAuditRecordTorn {
+		err = ErrAuditChainBroken
+	}
+	return hash, records, err
+}
+
+// verifyAuditLog is the implementation of the VerifyAuditLog function,
+// which also returns the offset of the end of the last valid record. If
+// the last record has no newline, errAuditRecordTorn is returned.
+func verifyAuditLog(reader io.Reader) ([sha256.Size]byte, int, int64, error) {
+	var hash [sha256.Size]byte
+	var expected [sha256.Size]byte
+	records := 0
+	var offset int64
+	buffered := bufio.NewReader(reader)
+	for {
+		line, err := buffered.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) == 0 {
+				return hash, records, offset, nil
+			}
+			// A record without the newline has been truncated.
+			return hash, records, offset, errAuditRecordTorn
+		}
+		if err != nil {
+			return hash, records, offset, err
+		}
+		if len(line) <= auditHashLength || line[sha256.Size * 2] != ' ' {
+			return hash, records, offset, ErrAuditChainBroken
+		}
+		_, err = hex.Decode(expected[ : ], line[ : sha256.Size * 2])
+		if err != nil {
+			return hash, records, offset, ErrAuditChainBroken
+		}
+		actual := auditHash(hash, line[auditHashLength : ])
+		if !bytes.Equal(actual[ : ], expected[ : ]) {
+			return hash, records, offset, ErrAuditChainBroken
+		}
+		hash = actual
+		records++
+		offset += int64(len(line))
+	}
+}
+
+// AuditExporterOption is a structure that contains options for the audit
+// exporter.
+type AuditExporterOption struct {
+	// Span represents the log level span. If the level of a log entry is
+	// included in the log level span, the log entry will be processed,
+	// otherwise it will be discarded. If not provided, the default value
+	// is DEBUG level to FATAL level.
+	Span LevelSpan
+
+	// Encoder represents the encoder used to encode log entries, which
+	// must encode each log entry into a single line. If not provided, the
+	// default value is the JSON encoder.
+	Encoder Encoder
+
+	// FileName represents the path name of the audit log file. If the file
+	// already exists, the hash chain of its records is verified and new
+	// records continue the chain. If the last record has no newline, its
+	// writing has been interrupted (for example, by a crash), so it is
+	// removed before new records are written. If not provided, the
+	// default value is audit.log.
+	FileName string
+
+	// FileMode represents the permission bits used when the audit log file
+	// is created. If not provided, the default value is 0600.
+	FileMode os.FileMode
+}
+
+// UseSpan uses the given start and end log levels as the value of the
+// Span option. For details, please refer to the comment section of the
+// Span option. Then return to the option instance itself.
+func (o *AuditExporterOption) UseSpan(start, end Level) *AuditExporterOption {
+	o.Span = LevelSpan {
+		Start: start,
+		End: end,
+	}
+	return o
+}
+
+// UseEncoder uses the given encoder as the value of the Encoder option.
+// For details, please refer to the comment section of the Encoder option.
+// Then return to the option instance itself.
+func (o *AuditExporterOption) UseEncoder(encoder Encoder) *AuditExporterOption {
+	o.Encoder = encoder
+	return o
+}
+
+// UseFile uses the given name and permission bits as the values of the
+// FileName and FileMode options. For details, please refer to the comment
+// section of these options. Then return to the option instance itself.
+func (o *AuditExporterOption) UseFile(name string, mode os.FileMode) *AuditExporterOption {
+	o.FileName = name
+	o.FileMode = mode
+	return o
+}
+
+// Build builds and returns an audit exporter instance. If the existing
+// audit log file does not match the hash chain, ErrAuditChainBroken is
+// returned.
+func (o *AuditExporterOption) Build() (*AuditExporter, error) {
+	if o.Encoder == nil || len(o.FileName) == 0 {
+		return nil, ErrInvalidAudit
+	}
+	instance := &AuditExporter {
+		span: o.Span,
+		encoder: o.Encoder,
+		name: o.FileName,
+	}
+	handle, err := os.Open(o.FileName)
+	if err == nil {
+		instance.hash, _, instance.offset, err = verifyAuditLog(handle)
+		_ = handle.Close()
+		if err == errAuditRecordTorn {
+			diagnose("audit exporter removed a torn record from %s",
+				o.FileName)
+			err = os.Truncate(o.FileName, instance.offset)
+		}
+		if err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	instance.syncer, err = NewFileSyncerOption().UseName(o.FileName).
+		UseFileMode(o.FileMode).UseCacheCapacity(0).Build()
+	if err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// NewAuditExporterOption creates and returns an instance of the audit
+// exporter option with default optional values.
+func NewAuditExporterOption() *AuditExporterOption {
+	// The error is discarded and usually does not occur.
+	encoder, _ := NewJSONEncoder()
+	return &AuditExporterOption {
+		Span: LevelSpan {
+			Start: LevelDebug,
+			End: LevelFatal,
+		},
+		Encoder: encoder,
+		FileName: "audit.log",
+		FileMode: 0600,
+	}
+}
+
+// NewAuditExporter creates and returns an instance of an audit exporter
+// using the default optional values.
+func NewAuditExporter() (*AuditExporter, error) {
+	return NewAuditExporterOption().Build()
+}
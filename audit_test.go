@@ -0,0 +1,173 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditExporterOption(t *testing.T) {
+	option := NewAuditExporterOption()
+
+	option.UseSpan(LevelInfo, LevelFatal)
+	option.UseFile("", 0600)
+
+	assert.Equal(t, LevelInfo, option.Span.Start, "Unexpected option value")
+	assert.Equal(t, os.FileMode(0600), option.FileMode,
+		"Unexpected option value")
+
+	_, err := option.Build()
+	assert.Equal(t, ErrInvalidAudit, err, "Unexpected build error")
+}
+
+func TestAuditExporter(t *testing.T) {
+	directory, err := ioutil.TempDir("", "santa")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	name := directory + "/audit.log"
+	exporter, err := NewAuditExporterOption().UseFile(name, 0600).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	for _, message := range []string { "login", "update", "logout" } {
+		err = exporter.Export(&Entry {
+			Level: LevelInfo,
+			Message: StringMessage(message),
+		})
+		assert.NoError(t, err, "Unexpected export error")
+	}
+	hash := exporter.Hash()
+	assert.NoError(t, exporter.Close(), "Unexpected close error")
+
+	data, err := ioutil.ReadFile(name)
+	assert.NoError(t, err, "Unexpected read error")
+	last, records, err := VerifyAuditLog(bytes.NewReader(data))
+	assert.NoError(t, err, "Unexpected verify error")
+	assert.Equal(t, 3, records, "Unexpected verify result")
+	assert.Equal(t, hash, last, "Unexpected verify result")
+
+	// The chain continues after the audit log is reopened.
+	exporter, err = NewAuditExporterOption().UseFile(name, 0600).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.Equal(t, hash, exporter.Hash(), "Unexpected instance error")
+	err = exporter.Export(&Entry {
+		Level: LevelWarning,
+		Message: StringMessage("shutdown"),
+	})
+	assert.NoError(t, err, "Unexpected export error")
+	assert.NoError(t, exporter.Close(), "Unexpected close error")
+
+	data, err = ioutil.ReadFile(name)
+	assert.NoError(t, err, "Unexpected read error")
+	_, records, err = VerifyAuditLog(bytes.NewReader(data))
+	assert.NoError(t, err, "Unexpected verify error")
+	assert.Equal(t, 4, records, "Unexpected verify result")
+
+	// Modifying a record breaks the chain.
+	tampered := bytes.Replace(data, []byte("update"), []byte("delete"), 1)
+	_, records, err = VerifyAuditLog(bytes.NewReader(tampered))
+	assert.Equal(t, ErrAuditChainBroken, err, "Unexpected verify error")
+	assert.Equal(t, 1, records, "Unexpected verify result")
+
+	assert.NoError(t, ioutil.WriteFile(name, tampered, 0600),
+		"Unexpected write error")
+	_, err = NewAuditExporterOption().UseFile(name, 0600).Build()
+	assert.Equal(t, ErrAuditChainBroken, err, "Unexpected build error")
+}
+
+// testShortSyncer is a synchronizer whose next write writes only part of
+// the data and fails.
+type testShortSyncer struct {
+	Syncer
+	short bool
+}
+
+func (s *testShortSyncer) Write(buffer []byte) (int, error) {
+	if !s.short {
+		return s.Syncer.Write(buffer)
+	}
+	s.short = false
+	size, _ := s.Syncer.Write(buffer[ : len(buffer) / 2])
+	return size, errors.New("Error")
+}
+
+func TestAuditExporterTorn(t *testing.T) {
+	SetDiagnosticsWriter(&bytes.Buffer { })
+	defer SetDiagnosticsWriter(os.Stderr)
+
+	directory, err := ioutil.TempDir("", "santa")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	name := directory + "/audit.log"
+	exporter, err := NewAuditExporterOption().UseFile(name, 0600).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	entry := &Entry {
+		Level: LevelInfo,
+		Message: StringMessage("login"),
+	}
+	assert.NoError(t, exporter.Export(entry), "Unexpected export error")
+
+	// The part of a failed record is removed.
+	exporter.syncer = &testShortSyncer {
+		Syncer: exporter.syncer,
+		short: true,
+	}
+	assert.Error(t, exporter.Export(entry), "Expected export error")
+	assert.NoError(t, exporter.Export(entry), "Unexpected export error")
+	hash := exporter.Hash()
+	assert.NoError(t, exporter.Close(), "Unexpected close error")
+
+	data, err := ioutil.ReadFile(name)
+	assert.NoError(t, err, "Unexpected read error")
+	_, records, err := VerifyAuditLog(bytes.NewReader(data))
+	assert.NoError(t, err, "Unexpected verify error")
+	assert.Equal(t, 2, records, "Unexpected verify result")
+
+	// A record interrupted by a crash is removed when the audit log is
+	// reopened, and the chain continues from the last complete record.
+	torn := append(append([]byte(nil), data...), data[ : 20]...)
+	assert.NoError(t, ioutil.WriteFile(name, torn, 0600),
+		"Unexpected write error")
+	_, _, err = VerifyAuditLog(bytes.NewReader(torn))
+	assert.Equal(t, ErrAuditChainBroken, err, "Unexpected verify error")
+
+	exporter, err = NewAuditExporterOption().UseFile(name, 0600).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.Equal(t, hash, exporter.Hash(), "Unexpected instance error")
+	assert.NoError(t, exporter.Export(entry), "Unexpected export error")
+	assert.NoError(t, exporter.Close(), "Unexpected close error")
+
+	data, err = ioutil.ReadFile(name)
+	assert.NoError(t, err, "Unexpected read error")
+	_, records, err = VerifyAuditLog(bytes.NewReader(data))
+	assert.NoError(t, err, "Unexpected verify error")
+	assert.Equal(t, 3, records, "Unexpected verify result")
+}
@@ -0,0 +1,297 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrInvalidBatch represents that the given batch exporter option is
+	// invalid, usually because the encoder or synchronizer is not provided
+	// or the maximum number of entries is not greater than 0.
+	ErrInvalidBatch = errors.New("invalid batch option")
+)
+
+// BatchExporter is the structure of the batch exporter instance.
+//
+// The batch exporter encodes log entries like the standard exporter, but
+// instead of writing each encoded log entry to the synchronizer, it
+// accumulates the encoded log entries and writes them with a single Write
+// call once the maximum number of entries has been accumulated or the
+// maximum delay has elapsed since the first accumulated log entry. This
+// greatly reduces the number of system calls of high-rate loggers, even
+// if the internal cache of the synchronizer is disabled.
+//
+// Accumulated log entries are also written when the exporter is
+// synchronized or closed. If a batch cannot be written, the error is
+// returned to the caller that triggered the write (or reported to the
+// diagnostics channel if the maximum delay has elapsed), and the log
+// entries of the batch are discarded.
+//
+// The API provided by the batch exporter is thread-safe.
+type BatchExporter struct {
+	span LevelSpan
+	encoder Encoder
+	syncer Syncer
+	maxEntries int
+	maxDelay time.Duration
+	timer *time.Timer
+
+	mutex sync.Mutex
+	writeMutex sync.Mutex
+	buffer []byte
+	spare []byte
+	entries int
+	closed int32
+}
+
+// Export encodes the given log entry and appends it to the batch. If the
+// batch has accumulated the maximum number of entries, it is written to
+// the synchronizer.
+//
+// Finally, any errors encountered are returned. If the exporter has been
+// closed, ErrClosed is returned.
+func (e *BatchExporter) Export(entry *Entry) error {
+	if atomic.LoadInt32(&e.closed) == 1 {
+		return ErrClosed
+	}
+	if !e.span.Contains(entry.Level) {
+		return nil
+	}
+	e.mutex.Lock()
+	size := len(e.buffer)
+	buffer, err := e.encoder.Encode(e.buffer, entry)
+	if err != nil {
+		e.buffer = buffer[ : size]
+		e.mutex.Unlock()
+		return err
+	}
+	e.buffer = buffer
+	e.entries++
+	if e.entries >= e.maxEntries {
+		return e.flush()
+	}
+	if e.entries == 1 && e.timer != nil {
+		e.timer.Reset(e.maxDelay)
+	}
+	e.mutex.Unlock()
+	return nil
+}
+
+// flush writes the accumulated log entries to the synchronizer with a
+// single Write call, and then returns any errors encountered. Batches are
+// written in the order in which they are flushed.
+//
+// Please note that the caller must hold the mutex, which is released by
+// this function.
+func (e *BatchExporter) flush() error {
+	if e.entries == 0 {
+		e.mutex.Unlock()
+		return nil
+	}
+	batch := e.buffer
+	e.buffer = e.spare[ : 0]
+	e.spare = nil
+	e.entries = 0
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+	// The write mutex is acquired before the mutex is released, so that
+	// the next batch cannot be written before this batch.
+	e.writeMutex.Lock()
+	e.mutex.Unlock()
+	_, err := e.syncer.Write(batch)
+	e.writeMutex.Unlock()
+
+	e.mutex.Lock()
+	if e.spare == nil {
+		e.spare = batch[ : 0]
+	}
+	e.mutex.Unlock()
+	return err
+}
+
+// delayHandler writes the accumulated log entries after the maximum delay
+// has elapsed. It is called by the timer of the exporter in an independent
+// coroutine context.
+func (e *BatchExporter) delayHandler() {
+	e.mutex.Lock()
+	if err := e.flush(); err != nil {
+		diagnose("batch writing on delay failed: %v", err)
+	}
+}
+
+// Sync writes the accumulated log entries to the synchronizer, and then
+// calls the Sync function of the synchronizer.
+//
+// Finally, any errors encountered are returned. If the exporter has been
+// closed, ErrClosed is returned.
+func (e *BatchExporter) Sync() error {
+	if atomic.LoadInt32(&e.closed) == 1 {
+		return ErrClosed
+	}
+	e.mutex.Lock()
+	if err := e.flush(); err != nil {
+		return err
+	}
+	return e.syncer.Sync()
+}
+
+// Close writes the accumulated log entries to the synchronizer, and then
+// closes the synchronizer.
+//
+// Finally, any errors encountered are returned. If the exporter has
+// already been closed, ErrClosed is returned.
+func (e *BatchExporter) Close() error {
+	if !atomic.CompareAndSwapInt32(&e.closed, 0, 1) {
+		return ErrClosed
+	}
+	e.mutex.Lock()
+	err := e.flush()
+	return appendError(err, e.syncer.Close())
+}
+
+// BatchExporterOption is a structure that contains options for the batch
+// exporter.
+type BatchExporterOption struct {
+	// Span represents the log level span. If the level of a log entry is
+	// included in the log level span, the log entry will be processed,
+	// otherwise it will be discarded. If not provided, the default value
+	// is DEBUG level to FATAL level.
+	Span LevelSpan
+
+	// Encoder represents the encoder used to encode log entries. If not
+	// provided, the default value is the standard encoder.
+	Encoder Encoder
+
+	// Syncer represents the synchronizer to which batches are written. If
+	// not provided, the default value is the discard synchronizer.
+	Syncer Syncer
+
+	// MaxEntries represents the maximum number of log entries accumulated
+	// in a batch. When a batch reaches it, the batch is written. If not
+	// provided, the default value is 64.
+	MaxEntries int
+
+	// MaxDelay represents the maximum time that the first log entry of a
+	// batch is accumulated before the batch is written. If the value of
+	// this option is 0, batches are only written when they are full or the
+	// exporter is synchronized or closed. If not provided, the default
+	// value is 10 milliseconds.
+	MaxDelay time.Duration
+}
+
+// UseSpan uses the given log level span as the value of the Span option.
+// For details, please refer to the comment section of the Span option.
+// Then return to the option instance itself.
+func (o *BatchExporterOption) UseSpan(start, end Level) *BatchExporterOption {
+	o.Span = LevelSpan {
+		Start: start,
+		End: end,
+	}
+	return o
+}
+
+// UseEncoder uses the given encoder as the value of the Encoder option.
+// For details, please refer to the comment section of the Encoder option.
+// Then return to the option instance itself.
+func (o *BatchExporterOption) UseEncoder(encoder Encoder) *BatchExporterOption {
+	o.Encoder = encoder
+	return o
+}
+
+// UseSyncer uses the given synchronizer as the value of the Syncer option.
+// For details, please refer to the comment section of the Syncer option.
+// Then return to the option instance itself.
+func (o *BatchExporterOption) UseSyncer(syncer Syncer) *BatchExporterOption {
+	o.Syncer = syncer
+	return o
+}
+
+// UseMaxEntries uses the given number as the value of the MaxEntries
+// option. For details, please refer to the comment section of the
+// MaxEntries option. Then return to the option instance itself.
+func (o *BatchExporterOption) UseMaxEntries(entries int) *BatchExporterOption {
+	o.MaxEntries = entries
+	return o
+}
+
+// UseMaxDelay uses the given duration as the value of the MaxDelay option.
+// For details, please refer to the comment section of the MaxDelay option.
+// Then return to the option instance itself.
+func (o *BatchExporterOption) UseMaxDelay(delay time.Duration) *BatchExporterOption {
+	o.MaxDelay = delay
+	return o
+}
+
+// Validate checks whether the values of the options are valid, and returns
+// an error describing the first invalid value.
+func (o *BatchExporterOption) Validate() error {
+	if o.Encoder == nil || o.Syncer == nil || o.MaxEntries <= 0 ||
+		o.MaxDelay < 0 {
+		return ErrInvalidBatch
+	}
+	return o.Span.Validate()
+}
+
+// Build builds and returns an instance of the batch exporter and any
+// errors encountered.
+func (o *BatchExporterOption) Build() (*BatchExporter, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	instance := &BatchExporter {
+		span: o.Span,
+		encoder: o.Encoder,
+		syncer: o.Syncer,
+		maxEntries: o.MaxEntries,
+		maxDelay: o.MaxDelay,
+	}
+	if o.MaxDelay > 0 {
+		instance.timer = time.AfterFunc(o.MaxDelay, instance.delayHandler)
+		instance.timer.Stop()
+	}
+	return instance, nil
+}
+
+// NewBatchExporterOption creates and returns a batch exporter option
+// instance with default option values.
+func NewBatchExporterOption() *BatchExporterOption {
+	// The error is discarded and usually does not occur.
+	encoder, _ := NewStandardEncoder()
+	syncer, _ := NewDiscardSyncer()
+	return &BatchExporterOption {
+		Span: LevelSpan {
+			Start: LevelDebug,
+			End: LevelFatal,
+		},
+		Encoder: encoder,
+		Syncer: syncer,
+		MaxEntries: 64,
+		MaxDelay: time.Millisecond * 10,
+	}
+}
@@ -0,0 +1,110 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"bytes"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testCountWriter is a writer that counts the number of writes.
+type testCountWriter struct {
+	testLockedWriter
+	writes int32
+}
+
+func (w *testCountWriter) Write(buffer []byte) (int, error) {
+	atomic.AddInt32(&w.writes, 1)
+	return w.testLockedWriter.Write(buffer)
+}
+
+func newBatchTestSyncer(t *testing.T, writer *testCountWriter) Syncer {
+	syncer, err := NewStandardSyncerOption().UseWriter(writer).
+		UseCacheCapacity(0).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	return syncer
+}
+
+func TestBatchExporterOption(t *testing.T) {
+	option := NewBatchExporterOption()
+	assert.Equal(t, 64, option.MaxEntries, "Unexpected option value")
+	assert.Equal(t, time.Millisecond * 10, option.MaxDelay,
+		"Unexpected option value")
+
+	_, err := NewBatchExporterOption().UseMaxEntries(0).Build()
+	assert.Equal(t, ErrInvalidBatch, err, "Unexpected build error")
+	_, err = NewBatchExporterOption().UseSyncer(nil).Build()
+	assert.Equal(t, ErrInvalidBatch, err, "Unexpected build error")
+}
+
+func TestBatchExporterExport(t *testing.T) {
+	writer := &testCountWriter { }
+	exporter, err := NewBatchExporterOption().
+		UseSyncer(newBatchTestSyncer(t, writer)).
+		UseMaxEntries(4).
+		UseMaxDelay(0).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	for index := 0; index < 10; index++ {
+		assert.NoError(t, exporter.Export(entry), "Unexpected export error")
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&writer.writes),
+		"Unexpected write count")
+
+	assert.NoError(t, exporter.Sync(), "Unexpected sync error")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&writer.writes),
+		"Unexpected write count")
+	assert.Equal(t, 10, bytes.Count(writer.data, []byte("\n")),
+		"Unexpected written entries")
+
+	assert.NoError(t, exporter.Close(), "Unexpected close error")
+	assert.Equal(t, ErrClosed, exporter.Export(entry),
+		"Unexpected export error")
+	assert.Equal(t, ErrClosed, exporter.Close(), "Unexpected close error")
+}
+
+func TestBatchExporterMaxDelay(t *testing.T) {
+	writer := &testCountWriter { }
+	exporter, err := NewBatchExporterOption().
+		UseSyncer(newBatchTestSyncer(t, writer)).
+		UseMaxDelay(time.Millisecond * 10).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	assert.NoError(t, exporter.Export(entry), "Unexpected export error")
+	assert.NoError(t, exporter.Export(entry), "Unexpected export error")
+	for index := 0; index < 100; index++ {
+		if atomic.LoadInt32(&writer.writes) > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&writer.writes),
+		"Unexpected write count")
+	assert.NoError(t, exporter.Close(), "Unexpected close error")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&writer.writes),
+		"Unexpected write count")
+}
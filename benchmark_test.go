@@ -0,0 +1,195 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// The benchmarks in this file measure the loggers end to end. To compare
+// the performance of two releases, run the same command on both and then
+// compare the results with benchstat (golang.org/x/perf/cmd/benchstat):
+//
+//	go test -run '^$' -bench 'Struct|Template|Standard' -benchmem \
+//		-count 10 . > new.txt
+//	benchstat old.txt new.txt
+//
+// Each benchmark runs the variants listed in benchmarkVariants as
+// sub-benchmarks, so a single variant can be selected with a pattern such
+// as 'BenchmarkStructJSON/NoSampling'.
+
+type benchmarkVariant struct {
+	name string
+	sampling bool
+	caller bool
+}
+
+var benchmarkVariants = []benchmarkVariant {
+	{ name: "Sampling", sampling: true },
+	{ name: "NoSampling" },
+	{ name: "Caller", caller: true },
+	{ name: "SamplingCaller", sampling: true, caller: true },
+}
+
+// newBenchmarkOption creates and returns a standard logger option that
+// discards the output, using the given encoder type and variant.
+func newBenchmarkOption(encoder string, variant benchmarkVariant) *StandardOption {
+	option := NewStandardOption()
+	if encoder == EncoderJSON {
+		option.Encoding.UseJSON()
+	} else {
+		option.Encoding.UseStandard()
+	}
+	option.Encoding.DisableSourceLocation = !variant.caller
+	option.Flushing.Interval = 0
+	option.Outputting.UseDiscard()
+	option.ErrorOutputting.UseDiscard()
+	option.UseLevel(LevelDebug)
+	if !variant.sampling {
+		option.DisableSampling()
+	}
+	return option
+}
+
+// benchmarkBuild is the type of a function that builds a logger using the
+// given option, and returns its print and close functions.
+type benchmarkBuild func(*StandardOption) (func() error, func() error, error)
+
+// benchmarkPrint runs the given print function for each variant, using
+// the given build function to create a logger for each variant.
+func benchmarkPrint(b *testing.B, parallel bool, encoder string,
+	build benchmarkBuild) {
+	for _, variant := range benchmarkVariants {
+		variant := variant
+		b.Run(variant.name, func(b *testing.B) {
+			print, close, err := build(newBenchmarkOption(encoder, variant))
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer close()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			if !parallel {
+				for index := 0; index < b.N; index++ {
+					_ = print()
+				}
+				return
+			}
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_ = print()
+				}
+			})
+		})
+	}
+}
+
+func buildBenchmarkStruct(option *StandardOption) (func() error, func() error, error) {
+	logger, err := (&StructOption { StandardOption: *option }).Build()
+	if err != nil {
+		return nil, nil, err
+	}
+	fields := newBenchmarkFields()
+	return func() error {
+		return logger.Infos("Hello Test!", fields...)
+	}, logger.Close, nil
+}
+
+func buildBenchmarkTemplate(option *StandardOption) (func() error, func() error, error) {
+	logger, err := (&TemplateOption { StandardOption: *option }).Build()
+	if err != nil {
+		return nil, nil, err
+	}
+	return func() error {
+		return logger.Infof("Hello %s! %d %f %t", "Test", 100, 99.5, true)
+	}, logger.Close, nil
+}
+
+func buildBenchmarkStandard(option *StandardOption) (func() error, func() error, error) {
+	logger, err := option.Build()
+	if err != nil {
+		return nil, nil, err
+	}
+	message := StringMessage("Hello Test!")
+	return func() error {
+		return logger.Info(message)
+	}, logger.Close, nil
+}
+
+func BenchmarkStructJSON(b *testing.B) {
+	benchmarkPrint(b, false, EncoderJSON, buildBenchmarkStruct)
+}
+
+func BenchmarkStructJSONParallel(b *testing.B) {
+	benchmarkPrint(b, true, EncoderJSON, buildBenchmarkStruct)
+}
+
+func BenchmarkStructStandard(b *testing.B) {
+	benchmarkPrint(b, false, EncoderStandard, buildBenchmarkStruct)
+}
+
+func BenchmarkStructStandardParallel(b *testing.B) {
+	benchmarkPrint(b, true, EncoderStandard, buildBenchmarkStruct)
+}
+
+func BenchmarkTemplateJSON(b *testing.B) {
+	benchmarkPrint(b, false, EncoderJSON, buildBenchmarkTemplate)
+}
+
+func BenchmarkTemplateJSONParallel(b *testing.B) {
+	benchmarkPrint(b, true, EncoderJSON, buildBenchmarkTemplate)
+}
+
+func BenchmarkTemplateStandard(b *testing.B) {
+	benchmarkPrint(b, false, EncoderStandard, buildBenchmarkTemplate)
+}
+
+func BenchmarkTemplateStandardParallel(b *testing.B) {
+	benchmarkPrint(b, true, EncoderStandard, buildBenchmarkTemplate)
+}
+
+func BenchmarkStandardJSON(b *testing.B) {
+	benchmarkPrint(b, false, EncoderJSON, buildBenchmarkStandard)
+}
+
+func BenchmarkStandardJSONParallel(b *testing.B) {
+	benchmarkPrint(b, true, EncoderJSON, buildBenchmarkStandard)
+}
+
+func BenchmarkStandardStandard(b *testing.B) {
+	benchmarkPrint(b, false, EncoderStandard, buildBenchmarkStandard)
+}
+
+func BenchmarkStandardStandardParallel(b *testing.B) {
+	benchmarkPrint(b, true, EncoderStandard, buildBenchmarkStandard)
+}
+
+func BenchmarkStructJSONFile(b *testing.B) {
+	name := filepath.Join(b.TempDir(), "benchmark.log")
+	benchmarkPrint(b, false, EncoderJSON, func(option *StandardOption) (func() error, func() error, error) {
+		option.Outputting.UseFile(name)
+		return buildBenchmarkStruct(option)
+	})
+}
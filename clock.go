@@ -0,0 +1,142 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Clock is the public interface of the clock.
+//
+// The logger uses the clock to obtain the generation time of each log
+// entry. By using a custom clock, applications can inject deterministic
+// timestamps (for example, in tests), or force all log entries to use
+// a specific time zone or wall clock.
+type Clock interface {
+	// Now returns the current time of the clock.
+	Now() time.Time
+}
+
+// SystemClock is the structure of the system clock instance. The system
+// clock returns the current local time of the system, and it is the
+// default clock of the logger.
+type SystemClock struct { }
+
+// Now returns the current local time of the system.
+func (c SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// UTCClock is the structure of the UTC clock instance. The UTC clock
+// returns the current time of the system in UTC.
+type UTCClock struct { }
+
+// Now returns the current time of the system in UTC.
+func (c UTCClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+// FixedClock is the structure of the fixed clock instance. The fixed
+// clock always returns the same time, and it is usually used to generate
+// deterministic log entries in tests.
+type FixedClock struct {
+	// Time represents the time that the clock always returns.
+	Time time.Time
+}
+
+// Now returns the fixed time of the clock.
+func (c FixedClock) Now() time.Time {
+	return c.Time
+}
+
+// CoarseClock is the structure of the coarse clock instance. The coarse
+// clock caches the time of an underlying clock and returns the cached
+// time, which is refreshed periodically by the Run function at the given
+// resolution. It trades the precision of the timestamps of log entries for
+// avoiding the overhead of reading the underlying clock for each log entry
+// in extreme throughput scenarios.
+//
+// The coarse clock is safe for concurrent use by multiple goroutines.
+type CoarseClock struct {
+	clock Clock
+	resolution time.Duration
+	now atomic.Value
+}
+
+// Now returns the cached time of the underlying clock.
+func (c *CoarseClock) Now() time.Time {
+	return c.now.Load().(time.Time)
+}
+
+// Update refreshes the cached time from the underlying clock immediately.
+func (c *CoarseClock) Update() {
+	c.now.Store(c.clock.Now())
+}
+
+// Resolution returns the interval at which the Run function refreshes the
+// cached time.
+func (c *CoarseClock) Resolution() time.Duration {
+	return c.resolution
+}
+
+// Run refreshes the cached time from the underlying clock at the resolution
+// of the clock until the given context is done and returns.
+//
+// This function should run in an independent coroutine context.
+func (c *CoarseClock) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.resolution)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Update()
+		}
+	}
+}
+
+// NewCoarseClock creates and returns a coarse clock instance caching the
+// time of the given clock, which is refreshed at the given resolution. If
+// the given clock is nil, the system clock is used. If the resolution is
+// not positive, the resolution is 1 millisecond.
+//
+// The cached time is only refreshed while the Run function is running,
+// and the StandardLogger runs it automatically when the ClockResolution
+// option is provided.
+func NewCoarseClock(clock Clock, resolution time.Duration) *CoarseClock {
+	if clock == nil {
+		clock = SystemClock { }
+	}
+	if resolution <= 0 {
+		resolution = time.Millisecond
+	}
+	instance := &CoarseClock {
+		clock: clock,
+		resolution: resolution,
+	}
+	instance.Update()
+	return instance
+}
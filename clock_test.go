@@ -0,0 +1,121 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	assert.False(t, SystemClock { }.Now().IsZero(), "Unexpected clock time")
+	assert.Equal(t, time.UTC, UTCClock { }.Now().Location(),
+		"Unexpected clock location")
+
+	timestamp := time.Unix(1597326990, 0)
+	assert.Equal(t, timestamp, FixedClock { Time: timestamp }.Now(),
+		"Unexpected clock time")
+}
+
+func TestLoggerClock(t *testing.T) {
+	exporter := &testRecordExporter { }
+	timestamp := time.Unix(1597326990, 0)
+
+	option := NewOption()
+	option.Clock = FixedClock { Time: timestamp }
+	option.Exporters = append(option.Exporters, exporter)
+
+	logger, err := option.Build()
+	assert.NoError(t, err, "Unexpected create error")
+
+	assert.NoError(t, logger.Print(LevelInfo, StringMessage(
+		"Hello Test!")), "Unexpected print error")
+
+	assert.Len(t, exporter.entries, 1, "Unexpected log entries")
+	assert.Equal(t, timestamp, exporter.entries[0].Time,
+		"Unexpected log entry time")
+
+	standardOption := NewStandardOption()
+	standardOption.UseClock(UTCClock { })
+	assert.Equal(t, UTCClock { }, standardOption.Clock,
+		"Unexpected option value")
+}
+
+func TestCoarseClock(t *testing.T) {
+	clock := &testStepClock {
+		now: time.Unix(1597326990, 0),
+	}
+	coarse := NewCoarseClock(clock, 0)
+	assert.Equal(t, time.Millisecond, coarse.Resolution(),
+		"Unexpected clock resolution")
+
+	timestamp := clock.now
+	clock.now = timestamp.Add(time.Second)
+	assert.Equal(t, timestamp, coarse.Now(), "Unexpected clock time")
+	coarse.Update()
+	assert.Equal(t, clock.now, coarse.Now(), "Unexpected clock time")
+
+	coarse = NewCoarseClock(nil, time.Millisecond)
+	timestamp = coarse.Now()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct { })
+	go func() {
+		coarse.Run(ctx)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool {
+		return coarse.Now().After(timestamp)
+	}, time.Second, time.Millisecond, "Unexpected clock time")
+	cancel()
+	<-done
+}
+
+func TestLoggerClockResolution(t *testing.T) {
+	exporter := &testRecordExporter { }
+	timestamp := time.Unix(1597326990, 0)
+
+	option := NewStandardOption().UseExporters(exporter).
+		UseClock(FixedClock { Time: timestamp }).
+		UseClockResolution(time.Millisecond)
+	option.Outputting.UseDiscard()
+	option.ErrorOutputting.UseDiscard()
+
+	logger, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.IsType(t, &CoarseClock { }, logger.clock, "Unexpected clock")
+
+	assert.NoError(t, logger.InfoString("Hello Test!"),
+		"Unexpected print error")
+	assert.Len(t, exporter.entries, 1, "Unexpected log entries")
+	assert.Equal(t, timestamp, exporter.entries[0].Time,
+		"Unexpected log entry time")
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+
+	_, err = NewStandardOption().UseClockResolution(-time.Second).Build()
+	assert.True(t, errors.Is(err, ErrInvalidClockResolution),
+		"Unexpected build error")
+}
@@ -0,0 +1,109 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"context"
+)
+
+// StructPrinter is the interface of the structured log message output API,
+// which is implemented by the structured logger and the structured logger
+// decorator.
+type StructPrinter interface {
+	// Prints outputs a structured log message with a given log level,
+	// given description text and fields, and then returns any errors
+	// encountered.
+	Prints(level Level, text string, fields ...Field) error
+
+	// Debugs outputs a structured log message with a log level of DEBUG.
+	Debugs(text string, fields ...Field) error
+
+	// Infos outputs a structured log message with a log level of INFO.
+	Infos(text string, fields ...Field) error
+
+	// Warnings outputs a structured log message with a log level of
+	// WARNING.
+	Warnings(text string, fields ...Field) error
+
+	// Errors outputs a structured log message with a log level of ERROR.
+	Errors(text string, fields ...Field) error
+
+	// Fatals outputs a structured log message with a log level of FATAL.
+	Fatals(text string, fields ...Field) error
+}
+
+// contextKey is the type of the key of the logger stored in contexts.
+type contextKey struct { }
+
+// NewContext returns a copy of the given context that stores the given
+// logger. It is usually used by middleware to pass a decorator with the
+// fields of a request (for example, the request ID) to the handlers of the
+// request.
+func NewContext(ctx context.Context, logger StructPrinter) context.Context {
+	return context.WithValue(ctx, contextKey { }, logger)
+}
+
+// FromContext returns the logger stored in the given context by the
+// NewContext function. If the context does not store a logger, it returns
+// a logger that discards all log messages, so that the returned logger
+// can always be used.
+func FromContext(ctx context.Context) StructPrinter {
+	if logger, ok := ctx.Value(contextKey { }).(StructPrinter); ok {
+		return logger
+	}
+	return discardPrinter { }
+}
+
+// discardPrinter is the structure of the structured logger that discards
+// all log messages.
+type discardPrinter struct { }
+
+// Prints discards the log message and returns nil.
+func (discardPrinter) Prints(level Level, text string, fields ...Field) error {
+	return nil
+}
+
+// Debugs discards the log message and returns nil.
+func (discardPrinter) Debugs(text string, fields ...Field) error {
+	return nil
+}
+
+// Infos discards the log message and returns nil.
+func (discardPrinter) Infos(text string, fields ...Field) error {
+	return nil
+}
+
+// Warnings discards the log message and returns nil.
+func (discardPrinter) Warnings(text string, fields ...Field) error {
+	return nil
+}
+
+// Errors discards the log message and returns nil.
+func (discardPrinter) Errors(text string, fields ...Field) error {
+	return nil
+}
+
+// Fatals discards the log message and returns nil.
+func (discardPrinter) Fatals(text string, fields ...Field) error {
+	return nil
+}
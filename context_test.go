@@ -0,0 +1,71 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	_ StructPrinter = (*StructLogger)(nil)
+	_ StructPrinter = (*StructDecorator)(nil)
+)
+
+func TestContext(t *testing.T) {
+	logger, err := NewStruct()
+	assert.NoError(t, err, "Unexpected create error")
+
+	exporter := &testRecordExporter { }
+	logger.exporters = []Exporter { exporter }
+
+	decorator := logger.Decorator()
+	decorator.AddFields(String("requestId", "1"))
+	ctx := NewContext(context.Background(), decorator)
+	assert.Equal(t, decorator, FromContext(ctx), "Unexpected context logger")
+	assert.NoError(t, FromContext(ctx).Infos("Hello Test!"),
+		"Unexpected print error")
+	decorator.Free()
+
+	assert.Len(t, exporter.entries, 1, "Unexpected log entries")
+	assert.Equal(t, `{"requestId": "1"}`, string(exporter.entries[0].
+		Message.(StructMessage).Fields.SerializeJSON(nil)),
+		"Unexpected log entry")
+	assert.Contains(t, exporter.entries[0].SourceLocation.File,
+		"context_test.go", "Unexpected source location")
+
+	printer := FromContext(context.Background())
+	assert.Equal(t, discardPrinter { }, printer, "Unexpected context logger")
+	assert.NoError(t, printer.Prints(LevelInfo, "Hello Test!"),
+		"Unexpected print error")
+	assert.NoError(t, printer.Debugs("Hello Test!"), "Unexpected print error")
+	assert.NoError(t, printer.Infos("Hello Test!"), "Unexpected print error")
+	assert.NoError(t, printer.Warnings("Hello Test!"),
+		"Unexpected print error")
+	assert.NoError(t, printer.Errors("Hello Test!"), "Unexpected print error")
+	assert.NoError(t, printer.Fatals("Hello Test!"), "Unexpected print error")
+
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+}
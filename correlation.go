@@ -0,0 +1,297 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidCorrelation represents that the given correlation hook
+	// option is invalid, usually because the field name or the generator
+	// is not provided.
+	ErrInvalidCorrelation = errors.New("invalid correlation option")
+)
+
+// IDGenerator is the public interface of the generator of correlation
+// IDs. Any type that implements this interface can be used by the
+// correlation hook to generate custom ID schemes.
+type IDGenerator interface {
+	// NewID generates and returns a new unique ID.
+	//
+	// Please note that this function must be thread-safe.
+	NewID() string
+}
+
+// timeRandom is the structure of the source of the timestamps and random
+// bits of time-ordered IDs.
+type timeRandom struct {
+	clock Clock
+	mutex sync.Mutex
+}
+
+// read returns the current UNIX time in milliseconds, and fills the given
+// buffer slice with random bytes. If the random bytes cannot be read from
+// the cryptographically secure random number generator, the buffer slice
+// is filled with bytes derived from the current time instead.
+func (r *timeRandom) read(buffer []byte) uint64 {
+	clock := r.clock
+	if clock == nil {
+		clock = SystemClock { }
+	}
+	now := clock.Now()
+	if _, err := rand.Read(buffer); err != nil {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		seed := uint64(time.Now().UnixNano())
+		for index := range buffer {
+			// The xorshift generator is good enough for uniqueness.
+			seed ^= seed << 13
+			seed ^= seed >> 7
+			seed ^= seed << 17
+			buffer[index] = byte(seed)
+		}
+	}
+	return uint64(now.UnixNano() / int64(time.Millisecond))
+}
+
+// UUIDv7Generator is the structure of the generator of UUIDs of version 7,
+// which are time-ordered UUIDs defined by RFC 9562 that consist of a
+// 48-bit UNIX timestamp in milliseconds and 74 random bits, such as
+// "01890a5d-ac96-774b-bcce-b302099a8057".
+//
+// The zero value of the generator is ready to use.
+type UUIDv7Generator struct {
+	// Clock represents the clock of the timestamps. If not provided, the
+	// default value is the system clock.
+	Clock Clock
+}
+
+// NewID generates and returns a new UUID of version 7.
+func (g *UUIDv7Generator) NewID() string {
+	var uuid [16]byte
+	source := timeRandom { clock: g.Clock }
+	timestamp := source.read(uuid[6 : ])
+	uuid[0] = byte(timestamp >> 40)
+	uuid[1] = byte(timestamp >> 32)
+	binary.BigEndian.PutUint32(uuid[2 : 6], uint32(timestamp))
+	uuid[6] = uuid[6] & 0x0f | 0x70
+	uuid[8] = uuid[8] & 0x3f | 0x80
+	buffer := make([]byte, 36)
+	hex.Encode(buffer[0 : 8], uuid[0 : 4])
+	buffer[8] = '-'
+	hex.Encode(buffer[9 : 13], uuid[4 : 6])
+	buffer[13] = '-'
+	hex.Encode(buffer[14 : 18], uuid[6 : 8])
+	buffer[18] = '-'
+	hex.Encode(buffer[19 : 23], uuid[8 : 10])
+	buffer[23] = '-'
+	hex.Encode(buffer[24 : ], uuid[10 : ])
+	return string(buffer)
+}
+
+// ulidAlphabet is the Crockford's Base32 alphabet used by ULIDs.
+const ulidAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator is the structure of the generator of ULIDs, which are
+// lexicographically sortable IDs that consist of a 48-bit UNIX timestamp
+// in milliseconds and 80 random bits encoded with Crockford's Base32,
+// such as "01ARZ3NDEKTSV4RRFFQ69G5FAV".
+//
+// The zero value of the generator is ready to use.
+type ULIDGenerator struct {
+	// Clock represents the clock of the timestamps. If not provided, the
+	// default value is the system clock.
+	Clock Clock
+}
+
+// NewID generates and returns a new ULID.
+func (g *ULIDGenerator) NewID() string {
+	var random [10]byte
+	source := timeRandom { clock: g.Clock }
+	timestamp := source.read(random[ : ])
+	buffer := make([]byte, 26)
+	// The timestamp is encoded into the first 10 characters.
+	for index := 9; index >= 0; index-- {
+		buffer[index] = ulidAlphabet[timestamp & 0x1f]
+		timestamp >>= 5
+	}
+	// The random bits are encoded into the last 16 characters, 5 bytes
+	// into each group of 8 characters.
+	for group := 0; group < 2; group++ {
+		var bits uint64
+		for _, value := range random[group * 5 : group * 5 + 5] {
+			bits = bits << 8 | uint64(value)
+		}
+		for index := 7; index >= 0; index-- {
+			buffer[10 + group * 8 + index] = ulidAlphabet[bits & 0x1f]
+			bits >>= 5
+		}
+	}
+	return string(buffer)
+}
+
+// CorrelationHook is the structure of the correlation hook instance.
+//
+// The correlation hook ensures that each log entry has a correlation ID
+// (also known as request ID). If neither a field of the structured message
+// nor a label of the log entry has one of the accepted names, a new ID is
+// generated by the generator of the hook and added to the log entry. The
+// ID is added as a field to structured messages, and as a label to log
+// entries with other types of messages.
+//
+// Usually, the correlation ID of a request is added by a decorator (for
+// example, by the santahttp middleware), and the correlation hook only
+// generates IDs for log entries that are not related to any request.
+type CorrelationHook struct {
+	name string
+	names []string
+	generator IDGenerator
+}
+
+// Print adds a new correlation ID to the given log entry if it does not
+// have one, and then returns nil.
+func (h *CorrelationHook) Print(entry *Entry) error {
+	var message *StructMessage
+	switch value := entry.Message.(type) {
+	case StructMessage:
+		message = &value
+	case *StructMessage:
+		message = value
+	}
+	if message != nil {
+		for _, field := range message.Fields {
+			if h.accepted(field.Name) {
+				return nil
+			}
+		}
+	}
+	for _, label := range entry.Labels.Labels() {
+		if h.accepted(label.Key) {
+			return nil
+		}
+	}
+	id := h.generator.NewID()
+	if message == nil {
+		entry.Labels = entry.Labels.Append(NewLabel(h.name, id))
+		return nil
+	}
+	// The fields of the message belong to the caller, so they are copied
+	// instead of being appended to.
+	fields := make(ElementObject, 0, len(message.Fields) + 1)
+	fields = append(fields, message.Fields...)
+	entry.SetMessage(StructMessage {
+		Text: message.Text,
+		Fields: append(fields, String(h.name, id)),
+	})
+	return nil
+}
+
+// accepted checks whether the given name is one of the accepted names of
+// correlation IDs.
+func (h *CorrelationHook) accepted(name string) bool {
+	if name == h.name {
+		return true
+	}
+	for _, accepted := range h.names {
+		if name == accepted {
+			return true
+		}
+	}
+	return false
+}
+
+// CorrelationHookOption is a structure that contains options for the
+// correlation hook.
+type CorrelationHookOption struct {
+	// FieldName represents the name of the field or label of generated
+	// correlation IDs. If not provided, the default value is
+	// "correlationId".
+	FieldName string
+
+	// AcceptedNames represents the names of other fields or labels that
+	// are accepted as the correlation ID, such as "requestId" and
+	// "traceId". If not provided, only the FieldName option is accepted.
+	AcceptedNames []string
+
+	// Generator represents the generator of correlation IDs. If not
+	// provided, the default value is the UUIDv7 generator.
+	Generator IDGenerator
+}
+
+// UseFieldName uses the given name as the value of the FieldName option.
+// For details, please refer to the comment section of the FieldName
+// option. Then return to the option instance itself.
+func (o *CorrelationHookOption) UseFieldName(name string) *CorrelationHookOption {
+	o.FieldName = name
+	return o
+}
+
+// UseAcceptedNames appends the given names to the value of the option
+// AcceptedNames. For details, please refer to the comment section of the
+// AcceptedNames option. Then return to the option instance itself.
+func (o *CorrelationHookOption) UseAcceptedNames(names ...string) *CorrelationHookOption {
+	o.AcceptedNames = append(o.AcceptedNames, names...)
+	return o
+}
+
+// UseGenerator uses the given generator as the value of the Generator
+// option. For details, please refer to the comment section of the
+// Generator option. Then return to the option instance itself.
+func (o *CorrelationHookOption) UseGenerator(generator IDGenerator) *CorrelationHookOption {
+	o.Generator = generator
+	return o
+}
+
+// Build builds and returns an instance of the correlation hook and any
+// errors encountered.
+func (o *CorrelationHookOption) Build() (*CorrelationHook, error) {
+	if len(o.FieldName) == 0 || o.Generator == nil {
+		return nil, ErrInvalidCorrelation
+	}
+	return &CorrelationHook {
+		name: o.FieldName,
+		names: append([]string(nil), o.AcceptedNames...),
+		generator: o.Generator,
+	}, nil
+}
+
+// NewCorrelationHookOption creates and returns a correlation hook option
+// instance with default option values.
+func NewCorrelationHookOption() *CorrelationHookOption {
+	return &CorrelationHookOption {
+		FieldName: "correlationId",
+		Generator: &UUIDv7Generator { },
+	}
+}
+
+// NewCorrelationHook creates and returns a correlation hook instance
+// using the default optional values.
+func NewCorrelationHook() (*CorrelationHook, error) {
+	return NewCorrelationHookOption().Build()
+}
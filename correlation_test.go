@@ -0,0 +1,113 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testStaticGenerator struct { }
+
+func (g testStaticGenerator) NewID() string {
+	return "id"
+}
+
+func TestUUIDv7Generator(t *testing.T) {
+	clock := &testStepClock { now: time.Unix(1700000000, 0) }
+	generator := &UUIDv7Generator { Clock: clock }
+
+	id := generator.NewID()
+	assert.Regexp(t, regexp.MustCompile(
+		"^018bcfe5-6800-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$"), id,
+		"Unexpected UUID")
+	assert.NotEqual(t, id, generator.NewID(), "Unexpected duplicate UUID")
+
+	clock.now = clock.now.Add(time.Millisecond)
+	assert.Less(t, id, generator.NewID(), "Unexpected UUID order")
+}
+
+func TestULIDGenerator(t *testing.T) {
+	clock := &testStepClock { now: time.Unix(1469918176, 385000000) }
+	generator := &ULIDGenerator { Clock: clock }
+
+	id := generator.NewID()
+	assert.Regexp(t, regexp.MustCompile(
+		"^01ARYZ6S41[0-9A-HJKMNP-TV-Z]{16}$"), id, "Unexpected ULID")
+	assert.NotEqual(t, id, generator.NewID(), "Unexpected duplicate ULID")
+
+	clock.now = clock.now.Add(time.Millisecond)
+	assert.Less(t, id, generator.NewID(), "Unexpected ULID order")
+}
+
+func TestCorrelationHookOption(t *testing.T) {
+	_, err := NewCorrelationHookOption().UseFieldName("").Build()
+	assert.Equal(t, ErrInvalidCorrelation, err, "Unexpected build error")
+
+	option := NewCorrelationHookOption()
+	option.Generator = nil
+	_, err = option.Build()
+	assert.Equal(t, ErrInvalidCorrelation, err, "Unexpected build error")
+
+	hook, err := NewCorrelationHook()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.Equal(t, "correlationId", hook.name, "Unexpected field name")
+}
+
+func TestCorrelationHook(t *testing.T) {
+	hook, err := NewCorrelationHookOption().
+		UseGenerator(testStaticGenerator { }).
+		UseAcceptedNames("requestId").
+		Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	fields := make(ElementObject, 1, 2)
+	fields[0] = Int("status", 200)
+	entry := &Entry { Message: &StructMessage { Text: "done",
+		Fields: fields } }
+	assert.NoError(t, hook.Print(entry), "Unexpected print error")
+	assert.Equal(t, StructMessage { Text: "done", Fields: ElementObject {
+		Int("status", 200), String("correlationId", "id") } },
+		entry.Message, "Unexpected message")
+	assert.Equal(t, ElementObject { Int("status", 200) }, fields,
+		"Unexpected modified fields")
+
+	message := &StructMessage { Text: "done", Fields: ElementObject {
+		String("requestId", "1") } }
+	entry = &Entry { Message: message }
+	assert.NoError(t, hook.Print(entry), "Unexpected print error")
+	assert.Equal(t, message, entry.Message, "Unexpected message")
+
+	entry = &Entry { Message: "done",
+		Labels: NewSerializedLabels(NewLabel("correlationId", "1")) }
+	assert.NoError(t, hook.Print(entry), "Unexpected print error")
+	assert.Equal(t, 1, entry.Labels.Count(), "Unexpected labels")
+
+	entry = &Entry { Message: "done" }
+	assert.NoError(t, hook.Print(entry), "Unexpected print error")
+	assert.Equal(t, Labels { NewLabel("correlationId", "id") },
+		entry.Labels.Labels(), "Unexpected labels")
+}
@@ -0,0 +1,415 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// CSVColumnTime represents the column of the time of log entries,
+	// which is formatted with the time layout of the CSV encoder.
+	CSVColumnTime = "time"
+
+	// CSVColumnLevel represents the column of the level of log entries,
+	// such as "INFO".
+	CSVColumnLevel = "level"
+
+	// CSVColumnName represents the column of the name of log entries.
+	CSVColumnName = "name"
+
+	// CSVColumnMessage represents the column of the message text of log
+	// entries.
+	CSVColumnMessage = "message"
+
+	// CSVColumnSource represents the column of the source location of log
+	// entries, such as "main.go:42".
+	CSVColumnSource = "source"
+
+	// CSVColumnSequence represents the column of the sequence number of
+	// log entries.
+	CSVColumnSequence = "sequence"
+
+	// CSVColumnLabelPrefix is the prefix of columns of the values of
+	// labels, followed by the key name of the label, such as "label:user".
+	CSVColumnLabelPrefix = "label:"
+
+	// CSVColumnFieldPrefix is the prefix of columns of the values of the
+	// top-level fields of structured messages, followed by the name of the
+	// field, such as "field:duration".
+	CSVColumnFieldPrefix = "field:"
+)
+
+var (
+	// ErrInvalidCSVColumn represents that a column of the CSV encoder is
+	// unknown, or no column is provided.
+	ErrInvalidCSVColumn = errors.New("invalid csv column")
+)
+
+// csvColumn is the structure of a parsed column of the CSV encoder.
+type csvColumn struct {
+	kind string
+	key string
+}
+
+// CSVEncoder is the structure of the CSV encoder instance.
+//
+// The CSV encoder encodes each log entry into a row of comma-separated
+// values, which is useful for quick analysis in spreadsheets and data
+// warehouses. The columns are configurable and are drawn from the
+// metadata of log entries, the values of labels and the values of the
+// top-level fields of structured messages. Missing values are encoded as
+// empty cells. For the available columns, please refer to the comment
+// section of the constants beginning with CSVColumn...
+//
+// Cells that contain commas, double quotes or line breaks are quoted and
+// double quotes are escaped according to RFC 4180. String values that
+// start with "=", "+", "-" or "@" are prefixed with a single quote if the
+// EscapeFormulas option is enabled, so that spreadsheets do not evaluate
+// them as formulas.
+//
+// The header row of the columns can be written by synchronizers using the
+// banner returned by the Header function.
+type CSVEncoder struct {
+	columns []csvColumn
+	names []string
+	layout string
+	separator string
+	escapeFormulas bool
+	option EncoderOption
+	serializer SerializerOption
+}
+
+// Encode encodes a given log entry into a row of comma-separated values,
+// then appends to the given buffer slice, and finally returns the
+// appended buffer slice.
+func (e *CSVEncoder) Encode(buffer []byte, entry *Entry) ([]byte, error) {
+	if buffer == nil {
+		buffer = make([]byte, 0, 256)
+	}
+	for index, column := range e.columns {
+		if index > 0 {
+			buffer = append(buffer, ',')
+		}
+		buffer = e.appendColumn(buffer, column, entry)
+	}
+	return append(buffer, e.separator...), nil
+}
+
+// appendColumn appends the cell of the given column of the given log
+// entry to the given buffer slice, and then returns the appended buffer
+// slice.
+func (e *CSVEncoder) appendColumn(buffer []byte, column csvColumn,
+	entry *Entry) []byte {
+	switch column.kind {
+	case CSVColumnTime:
+		if e.option.UTC {
+			return entry.Time.UTC().AppendFormat(buffer, e.layout)
+		}
+		return entry.Time.AppendFormat(buffer, e.layout)
+	case CSVColumnLevel:
+		return append(buffer, entry.Level.Format()...)
+	case CSVColumnName:
+		return e.appendText(buffer, entry.Name)
+	case CSVColumnMessage:
+		if entry.Message == nil {
+			return buffer
+		}
+		return e.appendText(buffer, messageText(entry.Message))
+	case CSVColumnSource:
+		if !entry.SourceLocation.Parsed {
+			return buffer
+		}
+		start := len(buffer)
+		return quoteCSVCell(entry.SourceLocation.AppendString(buffer),
+			start)
+	case CSVColumnSequence:
+		if entry.Sequence == 0 {
+			return buffer
+		}
+		return strconv.AppendUint(buffer, entry.Sequence, 10)
+	case CSVColumnLabelPrefix:
+		labels := entry.Labels.Labels()
+		for index := 0; index < len(labels); index++ {
+			if labels[index].Key == column.key {
+				return e.appendText(buffer, labels[index].Value)
+			}
+		}
+		return buffer
+	case CSVColumnFieldPrefix:
+		var fields ElementObject
+		switch message := entry.Message.(type) {
+		case StructMessage:
+			fields = message.Fields
+		case *StructMessage:
+			fields = message.Fields
+		}
+		for index := 0; index < len(fields); index++ {
+			if fields[index].Name == column.key {
+				return e.appendElement(buffer, fields[index].Element)
+			}
+		}
+	}
+	return buffer
+}
+
+// appendElement appends the cell of the given element to the given buffer
+// slice, and then returns the appended buffer slice. String elements are
+// encoded as they are, and elements of other types are encoded as JSON
+// values.
+func (e *CSVEncoder) appendElement(buffer []byte, element Element) []byte {
+	switch element.Type {
+	case TypeString:
+		value := element.String
+		if limit := e.option.MaxFieldBytes; limit > 0 && len(value) > limit {
+			value = value[ : truncateIndex(value, limit)] + "…(" +
+				strconv.Itoa(len(value)) + " bytes)"
+		}
+		return e.appendText(buffer, value)
+	case TypeBytes:
+		return e.appendText(buffer, string(element.Bytes()))
+	case TypeNil:
+		return buffer
+	}
+	start := len(buffer)
+	return quoteCSVCell(element.SerializeJSONOption(buffer, &e.serializer),
+		start)
+}
+
+// appendText appends the cell of the given string value to the given
+// buffer slice, and then returns the appended buffer slice.
+func (e *CSVEncoder) appendText(buffer []byte, value string) []byte {
+	start := len(buffer)
+	if e.escapeFormulas && len(value) > 0 {
+		switch value[0] {
+		case '=', '+', '-', '@':
+			buffer = append(buffer, '\'')
+		}
+	}
+	return quoteCSVCell(append(buffer, value...), start)
+}
+
+// quoteCSVCell quotes the cell that starts at the given offset of the
+// given buffer slice if it contains commas, double quotes or line breaks,
+// and then returns the buffer slice.
+func quoteCSVCell(buffer []byte, start int) []byte {
+	if bytes.IndexAny(buffer[start : ], ",\"\r\n") < 0 {
+		return buffer
+	}
+	value := string(buffer[start : ])
+	buffer = append(buffer[ : start], '"')
+	for index := 0; index < len(value); index++ {
+		if value[index] == '"' {
+			buffer = append(buffer, '"')
+		}
+		buffer = append(buffer, value[index])
+	}
+	return append(buffer, '"')
+}
+
+// Option returns the value of the basic options of the encoder, and the
+// application can optimize the actual behavior by checking the values
+// of the options.
+func (e *CSVEncoder) Option() EncoderOption {
+	return e.option
+}
+
+// Header returns a banner that appends the header row of the columns of
+// the encoder. For details, please refer to the comment section of the
+// Banner data type.
+func (e *CSVEncoder) Header() Banner {
+	return func(buffer []byte) []byte {
+		for index, name := range e.names {
+			if index > 0 {
+				buffer = append(buffer, ',')
+			}
+			start := len(buffer)
+			buffer = quoteCSVCell(append(buffer, name...), start)
+		}
+		return append(buffer, e.separator...)
+	}
+}
+
+// CSVEncoderOption is a structure that contains options for the CSV
+// encoder.
+type CSVEncoderOption struct {
+	EncoderOption
+
+	// Columns represents the columns of rows in order. For details, please
+	// refer to the comment section of the constants beginning with
+	// CSVColumn... If not provided, the default value is the columns of
+	// the time, level, name and message of log entries.
+	Columns []string
+
+	// TimeLayout represents the time formatting layout style used when
+	// encoding the time of the log entry. If not provided, the default
+	// value is time.RFC3339Nano.
+	TimeLayout string
+
+	// CRLF represents whether rows are terminated with CRLF as specified
+	// by RFC 4180, instead of LF. If not provided, the default value is
+	// false.
+	CRLF bool
+
+	// EscapeFormulas represents whether to prefix string values that
+	// start with "=", "+", "-" or "@" with a single quote, which prevents
+	// formula injection when the CSV data is opened with spreadsheets. If
+	// not provided, the default value is true.
+	EscapeFormulas bool
+}
+
+// UseEncoderOption uses the given encoder option as part of the CSV
+// encoder option. Only the UTC and MaxFieldBytes options and the options
+// of the serialization of fields take effect, the columns are determined
+// by the Columns option. Then return to the option instance itself.
+func (o *CSVEncoderOption) UseEncoderOption(option EncoderOption) *CSVEncoderOption {
+	o.EncoderOption = option
+	return o
+}
+
+// UseColumns uses the given columns as the value of the Columns option.
+// For details, please refer to the comment section of the Columns option.
+// Then return to the option instance itself.
+func (o *CSVEncoderOption) UseColumns(columns ...string) *CSVEncoderOption {
+	o.Columns = columns
+	return o
+}
+
+// UseTimeLayout uses the given layout as the value of the option TimeLayout.
+// For details, please refer to the comment section of the TimeLayout option.
+// Then return to the option instance itself.
+func (o *CSVEncoderOption) UseTimeLayout(layout string) *CSVEncoderOption {
+	o.TimeLayout = layout
+	return o
+}
+
+// UseCRLF enables the CRLF option. For details, please refer to the
+// comment section of the CRLF option. Then return to the option instance
+// itself.
+func (o *CSVEncoderOption) UseCRLF() *CSVEncoderOption {
+	o.CRLF = true
+	return o
+}
+
+// UseEscapeFormulas uses the given value as the value of the option
+// EscapeFormulas. For details, please refer to the comment section of the
+// EscapeFormulas option. Then return to the option instance itself.
+func (o *CSVEncoderOption) UseEscapeFormulas(escape bool) *CSVEncoderOption {
+	o.EscapeFormulas = escape
+	return o
+}
+
+// Validate checks whether the values of the options are valid, and returns
+// an error describing the first invalid value.
+func (o *CSVEncoderOption) Validate() error {
+	if len(o.Columns) == 0 {
+		return ErrInvalidCSVColumn
+	}
+	for _, column := range o.Columns {
+		if _, err := parseCSVColumn(column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseCSVColumn parses and returns the given column, and returns an error
+// wrapping ErrInvalidCSVColumn if the column is unknown.
+func parseCSVColumn(column string) (csvColumn, error) {
+	switch column {
+	case CSVColumnTime, CSVColumnLevel, CSVColumnName, CSVColumnMessage,
+		CSVColumnSource, CSVColumnSequence:
+		return csvColumn { kind: column }, nil
+	}
+	for _, prefix := range []string { CSVColumnLabelPrefix,
+		CSVColumnFieldPrefix } {
+		if strings.HasPrefix(column, prefix) && len(column) > len(prefix) {
+			return csvColumn {
+				kind: prefix,
+				key: column[len(prefix) : ],
+			}, nil
+		}
+	}
+	return csvColumn { }, fmt.Errorf("%w: %q", ErrInvalidCSVColumn, column)
+}
+
+// Clone creates and returns a copy of the option instance. Modifying the
+// copy does not affect the option instance, and vice versa.
+func (o *CSVEncoderOption) Clone() *CSVEncoderOption {
+	option := *o
+	option.Columns = append([]string(nil), o.Columns...)
+	return &option
+}
+
+// Build builds and returns an instance of the CSV encoder and any errors
+// encountered.
+func (o *CSVEncoderOption) Build() (*CSVEncoder, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	instance := &CSVEncoder {
+		names: append([]string(nil), o.Columns...),
+		layout: o.TimeLayout,
+		separator: "\n",
+		escapeFormulas: o.EscapeFormulas,
+		option: o.EncoderOption,
+		serializer: SerializerOption {
+			EncoderOption: o.EncoderOption,
+			EncoderKeys: NewEncoderKeys(),
+		},
+	}
+	for _, name := range o.Columns {
+		column, _ := parseCSVColumn(name)
+		instance.columns = append(instance.columns, column)
+	}
+	if len(instance.layout) == 0 {
+		instance.layout = time.RFC3339Nano
+	}
+	if o.CRLF {
+		instance.separator = "\r\n"
+	}
+	return instance, nil
+}
+
+// NewCSVEncoderOption creates and returns a CSV encoder option instance
+// with default optional values.
+func NewCSVEncoderOption() *CSVEncoderOption {
+	return &CSVEncoderOption {
+		EncoderOption: NewEncoderOption(),
+		Columns: []string { CSVColumnTime, CSVColumnLevel, CSVColumnName,
+			CSVColumnMessage },
+		TimeLayout: time.RFC3339Nano,
+		EscapeFormulas: true,
+	}
+}
+
+// NewCSVEncoder creates and returns a CSV encoder instance using the
+// default optional values.
+func NewCSVEncoder() (*CSVEncoder, error) {
+	return NewCSVEncoderOption().Build()
+}
@@ -0,0 +1,100 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVEncoderOption(t *testing.T) {
+	option := NewCSVEncoderOption()
+	assert.Equal(t, []string { "time", "level", "name", "message" },
+		option.Columns, "Unexpected option value")
+	assert.True(t, option.EscapeFormulas, "Unexpected option value")
+
+	for _, columns := range [][]string { nil, { "time", "unknown" },
+		{ "label:" } } {
+		_, err := option.Clone().UseColumns(columns...).Build()
+		assert.True(t, errors.Is(err, ErrInvalidCSVColumn),
+			"Unexpected build error")
+	}
+}
+
+func TestCSVEncoder(t *testing.T) {
+	encoder, err := NewCSVEncoderOption().UseColumns("time", "level",
+		"name", "label:user", "field:duration", "field:query",
+		"field:missing", "sequence", "message").
+		UseTimeLayout(time.RFC3339).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	header := string(encoder.Header()(nil))
+	assert.Equal(t, "time,level,name,label:user,field:duration," +
+		"field:query,field:missing,sequence,message\n", header,
+		"Unexpected header")
+
+	buffer, err := encoder.Encode(nil, &Entry {
+		Time: time.Date(2024, time.May, 1, 8, 0, 0, 0, time.UTC),
+		Level: LevelWarning,
+		Name: "db",
+		Labels: NewSerializedLabels(NewLabel("user", "=cmd()")),
+		Message: StructMessage {
+			Text: "slow query, \"users\"\nretrying",
+			Fields: ElementObject {
+				Int("duration", 1500),
+				String("query", "select a, b"),
+			},
+		},
+		Sequence: 7,
+	})
+	assert.NoError(t, err, "Unexpected encode error")
+	assert.Equal(t, "2024-05-01T08:00:00Z,WARNING,db,'=cmd(),1500," +
+		"\"select a, b\",,7,\"slow query, \"\"users\"\"\nretrying\"\n",
+		string(buffer), "Unexpected encoding result")
+
+	// The encoding result can be parsed by a standard CSV reader.
+	records, err := csv.NewReader(strings.NewReader(header +
+		string(buffer))).ReadAll()
+	assert.NoError(t, err, "Unexpected read error")
+	assert.Equal(t, "slow query, \"users\"\nretrying", records[1][8],
+		"Unexpected record value")
+}
+
+func TestCSVEncoderCRLF(t *testing.T) {
+	encoder, err := NewCSVEncoderOption().UseColumns("level", "message").
+		UseCRLF().UseEscapeFormulas(false).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	buffer, err := encoder.Encode(nil, &Entry {
+		Level: LevelInfo,
+		Message: StringMessage("-1"),
+	})
+	assert.NoError(t, err, "Unexpected encode error")
+	assert.Equal(t, "INFO,-1\r\n", string(buffer),
+		"Unexpected encoding result")
+}
@@ -0,0 +1,456 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+// Decorator is the structure of the logger decorator instance.
+//
+// The decorator shares the exporters, hooks, sampler and minimum log level
+// of a logger, but allows the name and labels of the output log entries to
+// be changed without creating a copy of the logger. This is useful when a
+// group of log entries (for example, log entries related to a request)
+// need additional labels, because the decorator instance is cheap to create
+// and does not need to be closed.
+//
+// Decorator instances are allocated from the global pool. After the
+// decorator is no longer in use, the application can call the Free
+// function to return it to the global pool to reduce the number of heap
+// memory allocations.
+//
+// The output API provided by the decorator is thread-safe, but the APIs
+// that change the decorator instance are not thread-safe.
+type Decorator struct {
+	logger *Logger
+	name string
+	labels SerializedLabels
+}
+
+// reset resets the decorator to use the name and labels of the given
+// logger.
+func (d *Decorator) reset(logger *Logger) {
+	settings := logger.loadSettings()
+	d.logger = logger
+	d.name = settings.name
+	d.labels = settings.labels
+}
+
+// SetName sets the name of the log entries output by the decorator to
+// the given name.
+//
+// Please note that this API is not thread-safe.
+func (d *Decorator) SetName(name string) {
+	d.name = name
+}
+
+// AddLabels adds one or more given labels to the labels of the log entries
+// output by the decorator. The labels of the logger are kept.
+//
+// Please note that this API is not thread-safe.
+func (d *Decorator) AddLabels(labels ...Label) {
+	d.labels = d.labels.Append(labels...)
+}
+
+// Output outputs log entries for a given log level and message using the
+// name and labels of the decorator, and then returns any errors
+// encountered. For details, please refer to the comment section of the
+// Output function of the Logger structure.
+func (d *Decorator) Output(callDepth int, level Level, message Message) error {
+	return d.logger.output(callDepth + 1, level, message, d.name, d.labels)
+}
+
+// Print outputs log entries for a given log level and message, and then
+// returns any errors encountered.
+func (d *Decorator) Print(level Level, message Message) error {
+	return d.Output(2, level, message)
+}
+
+// OutputDPanic outputs a log entry of the given message like the DPanic...
+// APIs of the loggers using the name and labels of the decorator, and then
+// returns any errors encountered. For details, please refer to the comment
+// section of the OutputDPanic function of the Logger structure.
+func (d *Decorator) OutputDPanic(callDepth int, message Message) error {
+	return d.logger.dpanic(callDepth + 1, message, d.name, d.labels)
+}
+
+// Free returns the decorator instance to the global pool. After the
+// refund, the decorator instance is not allowed to be used again,
+// otherwise the behavior is undefined.
+func (d *Decorator) Free() {
+	*d = Decorator { }
+	pool.decorator.base.Put(d)
+}
+
+// StandardDecorator is the structure of the standard logger decorator
+// instance. It provides the same multi-log level output API as the
+// standard logger. For details, please refer to the comment section of
+// the Decorator structure.
+type StandardDecorator struct {
+	Decorator
+}
+
+// Debug outputs a given log message with a log level of DEBUG, and then
+// returns any errors encountered.
+func (d *StandardDecorator) Debug(message Message) error {
+	return d.Output(2, LevelDebug, message)
+}
+
+// Info outputs a given log message with a log level of INFO, and then
+// returns any errors encountered.
+func (d *StandardDecorator) Info(message Message) error {
+	return d.Output(2, LevelInfo, message)
+}
+
+// Warning outputs a given log message with a log level of WARNING, and
+// then returns any errors encountered.
+func (d *StandardDecorator) Warning(message Message) error {
+	return d.Output(2, LevelWarning, message)
+}
+
+// Error outputs a given log message with a log level of ERROR, and then
+// returns any errors encountered.
+func (d *StandardDecorator) Error(message Message) error {
+	return d.Output(2, LevelError, message)
+}
+
+// Fatal outputs a given log message with a log level of FATAL, and then
+// returns any errors encountered.
+func (d *StandardDecorator) Fatal(message Message) error {
+	return d.Output(2, LevelFatal, message)
+}
+
+// PrintString outputs a string log message with a given log level and a
+// given text, and then returns any errors encountered. It is equivalent
+// to calling the Output function with a StringMessage.
+func (d *StandardDecorator) PrintString(level Level, text string) error {
+	return d.Output(2, level, StringMessage(text))
+}
+
+// DebugString outputs a string log message with a log level of DEBUG and a
+// given text, and then returns any errors encountered.
+func (d *StandardDecorator) DebugString(text string) error {
+	return d.Output(2, LevelDebug, StringMessage(text))
+}
+
+// InfoString outputs a string log message with a log level of INFO and a
+// given text, and then returns any errors encountered.
+func (d *StandardDecorator) InfoString(text string) error {
+	return d.Output(2, LevelInfo, StringMessage(text))
+}
+
+// WarningString outputs a string log message with a log level of WARNING and a
+// given text, and then returns any errors encountered.
+func (d *StandardDecorator) WarningString(text string) error {
+	return d.Output(2, LevelWarning, StringMessage(text))
+}
+
+// ErrorString outputs a string log message with a log level of ERROR and a
+// given text, and then returns any errors encountered.
+func (d *StandardDecorator) ErrorString(text string) error {
+	return d.Output(2, LevelError, StringMessage(text))
+}
+
+// FatalString outputs a string log message with a log level of FATAL and a
+// given text, and then returns any errors encountered.
+func (d *StandardDecorator) FatalString(text string) error {
+	return d.Output(2, LevelFatal, StringMessage(text))
+}
+
+// DPanic outputs a given log message with a log level of ERROR, and then
+// returns any errors encountered. If the logger is in development mode,
+// it panics after the log entry is output. For details, please refer to
+// the comment section of the Development option of the Option structure.
+func (d *StandardDecorator) DPanic(message Message) error {
+	return d.OutputDPanic(2, message)
+}
+
+// DPanicString outputs a string log message with a given text like the
+// DPanic function, and then returns any errors encountered.
+func (d *StandardDecorator) DPanicString(text string) error {
+	return d.OutputDPanic(2, StringMessage(text))
+}
+
+// Free returns the decorator instance to the global pool. After the
+// refund, the decorator instance is not allowed to be used again,
+// otherwise the behavior is undefined.
+func (d *StandardDecorator) Free() {
+	*d = StandardDecorator { }
+	pool.decorator.standard.Put(d)
+}
+
+// TemplateDecorator is the structure of the template logger decorator
+// instance. It provides the same template log message output API as the
+// template logger. For details, please refer to the comment section of
+// the Decorator structure.
+type TemplateDecorator struct {
+	StandardDecorator
+}
+
+// Printf outputs a template log message with a given log level, a given
+// template string and one or more parameters, and then returns any errors
+// encountered.
+func (d *TemplateDecorator) Printf(level Level, template string, args ...interface { }) error {
+	message := pool.Message.Template.New(template, args)
+	err := d.Output(2, level, message)
+	pool.Message.Template.Free(message)
+	return err
+}
+
+// Debugf outputs a template log message with a log level of DEBUG, a given
+// template string and one or more parameters, and then returns any errors
+// encountered.
+func (d *TemplateDecorator) Debugf(template string, args ...interface { }) error {
+	message := pool.Message.Template.New(template, args)
+	err := d.Output(2, LevelDebug, message)
+	pool.Message.Template.Free(message)
+	return err
+}
+
+// Infof outputs a template log message with a log level of INFO, a given
+// template string and one or more parameters, and then returns any errors
+// encountered.
+func (d *TemplateDecorator) Infof(template string, args ...interface { }) error {
+	message := pool.Message.Template.New(template, args)
+	err := d.Output(2, LevelInfo, message)
+	pool.Message.Template.Free(message)
+	return err
+}
+
+// Warningf outputs a template log message with a log level of WARNING, a
+// given template string and one or more parameters, and then returns any
+// errors encountered.
+func (d *TemplateDecorator) Warningf(template string, args ...interface { }) error {
+	message := pool.Message.Template.New(template, args)
+	err := d.Output(2, LevelWarning, message)
+	pool.Message.Template.Free(message)
+	return err
+}
+
+// Errorf outputs a template log message with a log level of ERROR, a given
+// template string and one or more parameters, and then returns any errors
+// encountered.
+func (d *TemplateDecorator) Errorf(template string, args ...interface { }) error {
+	message := pool.Message.Template.New(template, args)
+	err := d.Output(2, LevelError, message)
+	pool.Message.Template.Free(message)
+	return err
+}
+
+// Fatalf outputs a template log message with a log level of FATAL, a given
+// template string and one or more parameters, and then returns any errors
+// encountered.
+func (d *TemplateDecorator) Fatalf(template string, args ...interface { }) error {
+	message := pool.Message.Template.New(template, args)
+	err := d.Output(2, LevelFatal, message)
+	pool.Message.Template.Free(message)
+	return err
+}
+
+// DPanicf outputs a template log message with a log level of ERROR, a
+// given template string and one or more parameters, and then returns any
+// errors encountered. If the logger is in development mode, it panics
+// after the log entry is output. For details, please refer to the comment
+// section of the Development option of the Option structure.
+func (d *TemplateDecorator) DPanicf(template string, args ...interface { }) error {
+	message := pool.Message.Template.New(template, args)
+	err := d.OutputDPanic(2, message)
+	pool.Message.Template.Free(message)
+	return err
+}
+
+// Free returns the decorator instance to the global pool. After the
+// refund, the decorator instance is not allowed to be used again,
+// otherwise the behavior is undefined.
+func (d *TemplateDecorator) Free() {
+	*d = TemplateDecorator { }
+	pool.decorator.template.Put(d)
+}
+
+// StructDecorator is the structure of the structured logger decorator
+// instance. It provides the same structured log message output API as the
+// structured logger, and allows one or more fields to be added to each
+// structured log message it outputs. For details, please refer to the
+// comment section of the Decorator structure.
+type StructDecorator struct {
+	StandardDecorator
+
+	fields []Field
+}
+
+// reset resets the decorator to use the name and labels of the given
+// logger, and removes the fields that have been added.
+func (d *StructDecorator) reset(logger *Logger) {
+	d.StandardDecorator.reset(logger)
+	d.fields = d.fields[ : 0]
+}
+
+// AddFields adds one or more given fields to each structured log message
+// output by the decorator. The added fields are placed before the fields
+// given to each output API.
+//
+// Please note that this API is not thread-safe.
+func (d *StructDecorator) AddFields(fields ...Field) {
+	d.fields = append(d.fields, fields...)
+}
+
+// prints outputs a structured log message with a given log level, given
+// description text and fields, and then returns any errors encountered.
+func (d *StructDecorator) prints(level Level, text string, fields []Field) error {
+	message := pool.Message.Structure.New(text, d.merge(fields))
+	err := d.Output(3, level, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// dpanics outputs a structured log message with the given description
+// text, and the fields of the decorator followed by the given fields, like
+// the DPanics function of the structured logger, and then returns any
+// errors encountered.
+func (d *StructDecorator) dpanics(text string, fields []Field) error {
+	message := pool.Message.Structure.New(text, d.merge(fields))
+	err := d.OutputDPanic(3, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// merge returns the fields of the decorator followed by the given fields.
+func (d *StructDecorator) merge(fields []Field) []Field {
+	if len(d.fields) == 0 {
+		return fields
+	}
+	merged := make([]Field, 0, len(d.fields) + len(fields))
+	merged = append(merged, d.fields...)
+	return append(merged, fields...)
+}
+
+// Prints outputs a structured log message with a given log level,
+// given description text and fields, and then returns any errors
+// encountered.
+func (d *StructDecorator) Prints(level Level, text string, fields ...Field) error {
+	return d.prints(level, text, fields)
+}
+
+// Debugs outputs a structured log message with a log level of DEBUG,
+// given description text and fields, and then returns any errors
+// encountered.
+func (d *StructDecorator) Debugs(text string, fields ...Field) error {
+	return d.prints(LevelDebug, text, fields)
+}
+
+// Infos outputs a structured log message with a log level of INFO,
+// given description text and fields, and then returns any errors
+// encountered.
+func (d *StructDecorator) Infos(text string, fields ...Field) error {
+	return d.prints(LevelInfo, text, fields)
+}
+
+// Warnings outputs a structured log message with a log level of WARNING,
+// given description text and fields, and then returns any errors
+// encountered.
+func (d *StructDecorator) Warnings(text string, fields ...Field) error {
+	return d.prints(LevelWarning, text, fields)
+}
+
+// Errors outputs a structured log message with a log level of ERROR,
+// given description text and fields, and then returns any errors
+// encountered.
+func (d *StructDecorator) Errors(text string, fields ...Field) error {
+	return d.prints(LevelError, text, fields)
+}
+
+// Fatals outputs a structured log message with a log level of FATAL,
+// given description text and fields, and then returns any errors
+// encountered.
+func (d *StructDecorator) Fatals(text string, fields ...Field) error {
+	return d.prints(LevelFatal, text, fields)
+}
+
+// Printw outputs a structured log message with a given log level, given
+// description text and fields built from the given alternating keys and
+// values, and then returns any errors encountered. For details, please
+// refer to the comment section of the KeysAndValues function.
+func (d *StructDecorator) Printw(level Level, text string, keysAndValues ...interface { }) error {
+	return d.prints(level, text, KeysAndValues(keysAndValues...))
+}
+
+// Debugw outputs a structured log message with a log level of DEBUG,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func (d *StructDecorator) Debugw(text string, keysAndValues ...interface { }) error {
+	return d.prints(LevelDebug, text, KeysAndValues(keysAndValues...))
+}
+
+// Infow outputs a structured log message with a log level of INFO,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func (d *StructDecorator) Infow(text string, keysAndValues ...interface { }) error {
+	return d.prints(LevelInfo, text, KeysAndValues(keysAndValues...))
+}
+
+// Warningw outputs a structured log message with a log level of WARNING,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func (d *StructDecorator) Warningw(text string, keysAndValues ...interface { }) error {
+	return d.prints(LevelWarning, text, KeysAndValues(keysAndValues...))
+}
+
+// Errorw outputs a structured log message with a log level of ERROR,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func (d *StructDecorator) Errorw(text string, keysAndValues ...interface { }) error {
+	return d.prints(LevelError, text, KeysAndValues(keysAndValues...))
+}
+
+// Fatalw outputs a structured log message with a log level of FATAL,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func (d *StructDecorator) Fatalw(text string, keysAndValues ...interface { }) error {
+	return d.prints(LevelFatal, text, KeysAndValues(keysAndValues...))
+}
+
+// DPanics outputs a structured log message with a log level of ERROR,
+// given description text and fields, and then returns any errors
+// encountered. If the logger is in development mode, it panics after the
+// log entry is output. For details, please refer to the comment section
+// of the Development option of the Option structure.
+func (d *StructDecorator) DPanics(text string, fields ...Field) error {
+	return d.dpanics(text, fields)
+}
+
+// DPanicw outputs a structured log message with given description text and
+// fields built from the given alternating keys and values like the DPanics
+// function, and then returns any errors encountered.
+func (d *StructDecorator) DPanicw(text string, keysAndValues ...interface { }) error {
+	return d.dpanics(text, KeysAndValues(keysAndValues...))
+}
+
+// Free returns the decorator instance to the global pool. After the
+// refund, the decorator instance is not allowed to be used again,
+// otherwise the behavior is undefined.
+func (d *StructDecorator) Free() {
+	fields := d.fields
+	for index := 0; index < len(fields); index++ {
+		fields[index] = Field { }
+	}
+	*d = StructDecorator { }
+	d.fields = fields[ : 0]
+	pool.decorator.structure.Put(d)
+}
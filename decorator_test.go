@@ -0,0 +1,197 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRecordExporter struct {
+	entries []*Entry
+}
+
+func (e *testRecordExporter) Export(entry *Entry) error {
+	e.entries = append(e.entries, entry.Clone())
+	return nil
+}
+
+func (e *testRecordExporter) Sync() error {
+	return nil
+}
+
+func (e *testRecordExporter) Close() error {
+	return nil
+}
+
+func TestDecorator(t *testing.T) {
+	exporter := &testRecordExporter { }
+
+	option := NewOption()
+	option.Name = "test"
+	option.Labels = append(option.Labels, NewLabel("instanceId", "1"))
+	option.Exporters = append(option.Exporters, exporter)
+
+	logger, err := option.Build()
+	assert.NoError(t, err, "Unexpected create error")
+
+	decorator := logger.Decorator()
+	decorator.SetName("decorator")
+	decorator.AddLabels(NewLabel("requestId", "2"))
+
+	err = decorator.Print(LevelInfo, StringMessage("Hello Test!"))
+	assert.NoError(t, err, "Unexpected print error")
+	decorator.Free()
+
+	err = logger.Print(LevelInfo, StringMessage("Hello Test!"))
+	assert.NoError(t, err, "Unexpected print error")
+
+	assert.Len(t, exporter.entries, 2, "Unexpected log entries")
+	assert.Equal(t, "decorator", exporter.entries[0].Name,
+		"Unexpected log entry")
+	assert.Equal(t, Labels { NewLabel("instanceId", "1"), NewLabel(
+		"requestId", "2") }, exporter.entries[0].Labels.Labels(),
+		"Unexpected log entry")
+	assert.Equal(t, "test", exporter.entries[1].Name,
+		"Unexpected log entry")
+	assert.Equal(t, 1, exporter.entries[1].Labels.Count(),
+		"Unexpected log entry")
+}
+
+func TestStandardDecorator(t *testing.T) {
+	logger, err := NewStandard()
+	assert.NoError(t, err, "Unexpected create error")
+
+	exporter := &testRecordExporter { }
+	logger.exporters = []Exporter { exporter }
+
+	decorator := logger.Decorator()
+	decorator.SetName("decorator")
+	assert.NoError(t, decorator.Debug(StringMessage("Hello Test!")),
+		"Unexpected print error")
+	assert.NoError(t, decorator.Info(StringMessage("Hello Test!")),
+		"Unexpected print error")
+	assert.NoError(t, decorator.Warning(StringMessage("Hello Test!")),
+		"Unexpected print error")
+	assert.NoError(t, decorator.Error(StringMessage("Hello Test!")),
+		"Unexpected print error")
+	assert.NoError(t, decorator.Fatal(StringMessage("Hello Test!")),
+		"Unexpected print error")
+	assert.NoError(t, decorator.DebugString("Hello Test!"),
+		"Unexpected print error")
+	assert.NoError(t, decorator.InfoString("Hello Test!"),
+		"Unexpected print error")
+	assert.NoError(t, decorator.WarningString("Hello Test!"),
+		"Unexpected print error")
+	assert.NoError(t, decorator.ErrorString("Hello Test!"),
+		"Unexpected print error")
+	assert.NoError(t, decorator.FatalString("Hello Test!"),
+		"Unexpected print error")
+	assert.NoError(t, decorator.PrintString(LevelInfo, "Hello Test!"),
+		"Unexpected print error")
+	decorator.Free()
+
+	assert.Len(t, exporter.entries, 11, "Unexpected log entries")
+	for index, level := range []Level { LevelDebug, LevelInfo,
+		LevelWarning, LevelError, LevelFatal, LevelDebug, LevelInfo,
+		LevelWarning, LevelError, LevelFatal, LevelInfo } {
+		assert.Equal(t, StringMessage("Hello Test!"),
+			exporter.entries[index].Message, "Unexpected log entry")
+		assert.Equal(t, level, exporter.entries[index].Level,
+			"Unexpected log entry")
+		assert.Equal(t, "decorator", exporter.entries[index].Name,
+			"Unexpected log entry")
+		assert.Contains(t, exporter.entries[index].SourceLocation.File,
+			"decorator_test.go", "Unexpected source location")
+	}
+
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+}
+
+func TestTemplateDecorator(t *testing.T) {
+	logger, err := NewTemplate()
+	assert.NoError(t, err, "Unexpected create error")
+
+	exporter := &testRecordExporter { }
+	logger.exporters = []Exporter { exporter }
+
+	decorator := logger.Decorator()
+	decorator.AddLabels(NewLabel("requestId", "1"))
+	assert.NoError(t, decorator.Infof("Hello %s!", "test"),
+		"Unexpected print error")
+	decorator.Free()
+
+	assert.Len(t, exporter.entries, 1, "Unexpected log entries")
+	assert.Equal(t, `"Hello test!"`, string(exporter.entries[0].Message.(
+		TemplateMessage).SerializeJSON(nil)), "Unexpected log entry")
+	assert.Equal(t, 1, exporter.entries[0].Labels.Count(),
+		"Unexpected log entry")
+
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+}
+
+func TestStructDecorator(t *testing.T) {
+	logger, err := NewStruct()
+	assert.NoError(t, err, "Unexpected create error")
+
+	exporter := &testRecordExporter { }
+	logger.exporters = []Exporter { exporter }
+
+	decorator := logger.Decorator()
+	decorator.AddFields(String("requestId", "1"))
+	assert.NoError(t, decorator.Infos("Hello Test!", Int("age", 100)),
+		"Unexpected print error")
+	decorator.Free()
+
+	decorator = logger.Decorator()
+	assert.NoError(t, decorator.Infos("Hello Test!"),
+		"Unexpected print error")
+	decorator.Free()
+
+	decorator = logger.Decorator()
+	decorator.AddFields(String("requestId", "2"))
+	assert.NoError(t, decorator.Infow("Hello Test!", "age", 100),
+		"Unexpected print error")
+	assert.NoError(t, decorator.Printw(LevelError, "Hello Test!"),
+		"Unexpected print error")
+	decorator.Free()
+
+	assert.Len(t, exporter.entries, 4, "Unexpected log entries")
+	assert.Equal(t, `{"requestId": "2", "age": 100}`, string(exporter.
+		entries[2].Message.(StructMessage).Fields.SerializeJSON(nil)),
+		"Unexpected log entry")
+	assert.Equal(t, LevelError, exporter.entries[3].Level,
+		"Unexpected log entry")
+	assert.Contains(t, exporter.entries[2].SourceLocation.File,
+		"decorator_test.go", "Unexpected source location")
+	assert.Equal(t, `{"requestId": "1", "age": 100}`, string(exporter.
+		entries[0].Message.(StructMessage).Fields.SerializeJSON(nil)),
+		"Unexpected log entry")
+	assert.Len(t, exporter.entries[1].Message.(StructMessage).Fields, 0,
+		"Unexpected log entry")
+	assert.Contains(t, exporter.entries[0].SourceLocation.File,
+		"decorator_test.go", "Unexpected source location")
+
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+}
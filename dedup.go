@@ -0,0 +1,225 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidDedup represents that the given deduplication exporter
+	// option is invalid, usually because the exporter is not provided or
+	// the window is not greater than 0.
+	ErrInvalidDedup = errors.New("invalid dedup option")
+)
+
+// DedupExporter is the structure of the deduplication exporter instance.
+//
+// The deduplication exporter wraps an exporter and collapses consecutive
+// identical log entries, like the classic "last message repeated N times"
+// behavior of syslog, which shrinks the log volume during error loops.
+// Log entries are identical if they have the same level, name and message
+// (including the fields of structured messages).
+//
+// The first log entry of a run is exported immediately, and its repeats
+// within the window are suppressed. When a different log entry arrives,
+// the window expires or the exporter is synchronized or closed, a summary
+// log entry with the same level, name and labels is exported, whose
+// structured message has the text "last message repeated N times" and
+// the field "repeated" with the number of suppressed repeats.
+//
+// The API provided by the deduplication exporter is thread-safe.
+type DedupExporter struct {
+	exporter Exporter
+	window time.Duration
+	clock Clock
+
+	mutex sync.Mutex
+	last *Entry
+	key []byte
+	scratch []byte
+	start time.Time
+	repeats int
+}
+
+// Export exports the given log entry to the wrapped exporter, unless it
+// repeats the last log entry within the window. For details, please refer
+// to the comment section of the DedupExporter structure.
+//
+// Finally, any errors encountered are returned.
+func (e *DedupExporter) Export(entry *Entry) error {
+	now := e.clock.Now()
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.scratch = dedupKey(e.scratch[ : 0], entry)
+	if e.last != nil && now.Sub(e.start) < e.window &&
+		e.last.Level == entry.Level && e.last.Name == entry.Name &&
+		bytes.Equal(e.key, e.scratch) {
+		e.repeats++
+		return nil
+	}
+	if err := e.exportSummary(now); err != nil {
+		return err
+	}
+	e.key, e.scratch = e.scratch, e.key
+	e.last = entry.Clone()
+	e.start = now
+	return e.exporter.Export(entry)
+}
+
+// exportSummary exports the summary log entry of the suppressed repeats
+// of the last log entry, if any.
+//
+// Please note that the caller must hold the mutex.
+func (e *DedupExporter) exportSummary(now time.Time) error {
+	if e.repeats == 0 {
+		return nil
+	}
+	summary := *e.last
+	summary.Time = now
+	summary.SetMessage(StructMessage {
+		Text: "last message repeated " + strconv.Itoa(e.repeats) +
+			" times",
+		Fields: ElementObject {
+			Int("repeated", int64(e.repeats)),
+		},
+	})
+	e.repeats = 0
+	return e.exporter.Export(&summary)
+}
+
+// dedupKey appends the message of the given log entry in a comparable
+// form to the given buffer slice, and then returns the appended buffer
+// slice.
+func dedupKey(buffer []byte, entry *Entry) []byte {
+	switch message := entry.Message.(type) {
+	case nil:
+		return buffer
+	case JSONSerializer:
+		return message.SerializeJSON(buffer)
+	}
+	return append(buffer, messageText(entry.Message)...)
+}
+
+// Sync exports the summary log entry of the suppressed repeats, if any,
+// and then calls the Sync function of the wrapped exporter.
+//
+// Finally, any errors encountered are returned.
+func (e *DedupExporter) Sync() error {
+	now := e.clock.Now()
+	e.mutex.Lock()
+	err := e.exportSummary(now)
+	e.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	return e.exporter.Sync()
+}
+
+// Close exports the summary log entry of the suppressed repeats, if any,
+// and then calls the Close function of the wrapped exporter.
+//
+// Finally, any errors encountered are returned.
+func (e *DedupExporter) Close() error {
+	now := e.clock.Now()
+	e.mutex.Lock()
+	err := e.exportSummary(now)
+	e.mutex.Unlock()
+	if err != nil {
+		_ = e.exporter.Close()
+		return err
+	}
+	return e.exporter.Close()
+}
+
+// DedupExporterOption is a structure that contains options for the
+// deduplication exporter.
+type DedupExporterOption struct {
+	// Exporter represents the wrapped exporter. This option is required.
+	Exporter Exporter
+
+	// Window represents the maximum time after the first log entry of a
+	// run during which its repeats are suppressed. After the window
+	// expires, the summary is exported and the next repeat starts a new
+	// run. If not provided, the default value is 30 seconds.
+	Window time.Duration
+
+	// Clock represents the clock used to determine whether the window has
+	// expired. If not provided, the default value is the system clock.
+	Clock Clock
+}
+
+// UseExporter uses the given exporter as the value of the Exporter option.
+// For details, please refer to the comment section of the Exporter option.
+// Then return to the option instance itself.
+func (o *DedupExporterOption) UseExporter(exporter Exporter) *DedupExporterOption {
+	o.Exporter = exporter
+	return o
+}
+
+// UseWindow uses the given duration as the value of the Window option.
+// For details, please refer to the comment section of the Window option.
+// Then return to the option instance itself.
+func (o *DedupExporterOption) UseWindow(window time.Duration) *DedupExporterOption {
+	o.Window = window
+	return o
+}
+
+// UseClock uses the given clock as the value of the option Clock. For
+// details, please refer to the comment section of the Clock option. Then
+// return to the option instance itself.
+func (o *DedupExporterOption) UseClock(clock Clock) *DedupExporterOption {
+	o.Clock = clock
+	return o
+}
+
+// Build builds and returns an instance of the deduplication exporter and
+// any errors encountered.
+func (o *DedupExporterOption) Build() (*DedupExporter, error) {
+	if o.Exporter == nil || o.Window <= 0 {
+		return nil, ErrInvalidDedup
+	}
+	clock := o.Clock
+	if clock == nil {
+		clock = SystemClock { }
+	}
+	return &DedupExporter {
+		exporter: o.Exporter,
+		window: o.Window,
+		clock: clock,
+	}, nil
+}
+
+// NewDedupExporterOption creates and returns a deduplication exporter
+// option instance with default option values. The exporter must be
+// provided before building.
+func NewDedupExporterOption() *DedupExporterOption {
+	return &DedupExporterOption {
+		Window: time.Second * 30,
+		Clock: SystemClock { },
+	}
+}
@@ -0,0 +1,89 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupExporterOption(t *testing.T) {
+	option := NewDedupExporterOption()
+	assert.Equal(t, time.Second * 30, option.Window, "Unexpected option value")
+
+	_, err := option.Build()
+	assert.Equal(t, ErrInvalidDedup, err, "Unexpected build error")
+
+	option.UseExporter(&testRecordExporter { }).UseWindow(time.Minute)
+	assert.Equal(t, time.Minute, option.Window, "Unexpected option value")
+
+	_, err = option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	option.UseWindow(0)
+	_, err = option.Build()
+	assert.Equal(t, ErrInvalidDedup, err, "Unexpected build error")
+}
+
+func TestDedupExporter(t *testing.T) {
+	record := &testRecordExporter { }
+	clock := &testStepClock { now: time.Unix(0, 0) }
+	exporter, err := NewDedupExporterOption().UseExporter(record).
+		UseWindow(time.Minute).UseClock(clock).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	export := func(level Level, text string) {
+		err := exporter.Export(&Entry {
+			Level: level,
+			Message: StringMessage(text),
+		})
+		assert.NoError(t, err, "Unexpected export error")
+	}
+	for index := 0; index < 4; index++ {
+		export(LevelError, "connection refused")
+	}
+	export(LevelWarning, "connection refused")
+	assert.Len(t, record.entries, 3, "Unexpected export result")
+	assert.Equal(t, "last message repeated 3 times",
+		messageText(record.entries[1].Message), "Unexpected summary")
+	assert.Equal(t, LevelError, record.entries[1].Level,
+		"Unexpected summary")
+
+	// Repeats after the window start a new run.
+	export(LevelWarning, "connection refused")
+	clock.now = clock.now.Add(time.Minute)
+	export(LevelWarning, "connection refused")
+	assert.Len(t, record.entries, 5, "Unexpected export result")
+	assert.Equal(t, "last message repeated 1 times",
+		messageText(record.entries[3].Message), "Unexpected summary")
+
+	// Pending repeats are summarized when the exporter is synchronized.
+	export(LevelWarning, "connection refused")
+	assert.NoError(t, exporter.Sync(), "Unexpected sync error")
+	assert.Len(t, record.entries, 6, "Unexpected export result")
+	assert.NoError(t, exporter.Sync(), "Unexpected sync error")
+	assert.Len(t, record.entries, 6, "Unexpected export result")
+	assert.NoError(t, exporter.Close(), "Unexpected close error")
+}
@@ -0,0 +1,97 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// diagnostics is the internal self-diagnostics channel of the package.
+//
+// Errors that cannot be returned to the caller (for example, failures of
+// automatic flushing and reconnection events of network synchronizers)
+// and anomalies of the global pool are reported to the writer of the
+// diagnostics channel instead of being silently discarded.
+var diagnostics = struct {
+	mutex sync.Mutex
+	writer io.Writer
+} {
+	writer: os.Stderr,
+}
+
+// SetDiagnosticsWriter uses the given writer as the fallback writer of the
+// internal self-diagnostics channel. Syncer flush failures, reconnection
+// events and pool anomalies that cannot be returned to the caller are
+// written to it, one line per report. If the given writer is nil, the
+// reports are discarded. By default, the reports are written to os.Stderr.
+//
+// This function is thread-safe.
+func SetDiagnosticsWriter(writer io.Writer) {
+	diagnostics.mutex.Lock()
+	diagnostics.writer = writer
+	diagnostics.mutex.Unlock()
+}
+
+// diagnose formats the given report according to the given format and
+// arguments, and writes it to the writer of the diagnostics channel. Any
+// errors encountered while writing are discarded.
+//
+// This function is thread-safe.
+func diagnose(format string, args ...interface { }) {
+	diagnostics.mutex.Lock()
+	defer diagnostics.mutex.Unlock()
+	if diagnostics.writer == nil {
+		return
+	}
+	buffer := make([]byte, 0, 128)
+	buffer = append(buffer, "santa: "...)
+	buffer = time.Now().AppendFormat(buffer, time.RFC3339)
+	buffer = append(buffer, ' ')
+	buffer = append(buffer, fmt.Sprintf(format, args...)...)
+	buffer = append(buffer, '\n')
+	_, _ = diagnostics.writer.Write(buffer)
+}
+
+// diagnoseLimited works like the diagnose function, but discards the report
+// if the previous report sharing the given timestamp was written within the
+// given interval. The timestamp is the UNIX nanosecond timestamp of the last
+// written report, and is updated atomically.
+//
+// This function is thread-safe.
+func diagnoseLimited(last *int64, interval time.Duration, format string,
+	args ...interface { }) {
+	now := time.Now().UnixNano()
+	previous := atomic.LoadInt64(last)
+	if previous != 0 && now - previous < int64(interval) {
+		return
+	}
+	if !atomic.CompareAndSwapInt64(last, previous, now) {
+		return
+	}
+	diagnose(format, args...)
+}
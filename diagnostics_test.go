@@ -0,0 +1,83 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnose(t *testing.T) {
+	buffer := &bytes.Buffer { }
+	SetDiagnosticsWriter(buffer)
+	defer SetDiagnosticsWriter(os.Stderr)
+
+	diagnose("Hello %s!", "Test")
+	assert.True(t, strings.HasPrefix(buffer.String(), "santa: "),
+		"Unexpected diagnostics report")
+	assert.True(t, strings.HasSuffix(buffer.String(), " Hello Test!\n"),
+		"Unexpected diagnostics report")
+
+	SetDiagnosticsWriter(nil)
+	diagnose("Hello %s!", "Test")
+	assert.Equal(t, 1, strings.Count(buffer.String(), "\n"),
+		"Unexpected diagnostics report")
+}
+
+func TestDiagnosePool(t *testing.T) {
+	buffer := &bytes.Buffer { }
+	SetDiagnosticsWriter(buffer)
+	defer SetDiagnosticsWriter(os.Stderr)
+
+	instance := NewExporterBufferPool(16)
+	for index := 0; index < 2; index++ {
+		grown := make([]byte, 0, 1024)
+		instance.Free(&grown)
+	}
+	assert.Equal(t, 1, strings.Count(buffer.String(),
+		"discarded exporter buffer of 1024 bytes"),
+		"Unexpected diagnostics report")
+}
+
+func TestDiagnoseLimited(t *testing.T) {
+	buffer := &bytes.Buffer { }
+	SetDiagnosticsWriter(buffer)
+	defer SetDiagnosticsWriter(os.Stderr)
+
+	var last int64
+	for index := 0; index < 3; index++ {
+		diagnoseLimited(&last, time.Hour, "Hello %s!", "Test")
+	}
+	assert.Equal(t, 1, strings.Count(buffer.String(), "\n"),
+		"Unexpected diagnostics report")
+
+	last -= int64(time.Hour)
+	diagnoseLimited(&last, time.Hour, "Hello %s!", "Test")
+	assert.Equal(t, 2, strings.Count(buffer.String(), "\n"),
+		"Unexpected diagnostics report")
+}
@@ -24,6 +24,7 @@ package santa
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
 	"time"
 )
@@ -32,8 +33,13 @@ var (
 	// ErrUnsupportedMessage represents that the message type of the
 	// given log entry is not supported, usually because the message
 	// does not correctly implement the message serialization interface
-	// of the encoder.
+	// of the encoder and cannot be serialized by the fallback either.
 	ErrUnsupportedMessage = errors.New("unsupported message type")
+
+	// ErrDuplicateField represents that the structured message of a log
+	// entry contains fields with duplicate names, and the DuplicateFields
+	// option of the encoder is DuplicateError.
+	ErrDuplicateField = errors.New("duplicate field name")
 )
 
 // EncoderOption is a structure that contains options for the encoder.
@@ -51,6 +57,16 @@ type EncoderOption struct {
 	// encoding result. If not provided, the default value is true.
 	EncodeSourceLocation bool
 
+	// SourceLocationSpan represents the span of the levels of log entries
+	// whose source location is encoded, if the EncodeSourceLocation option
+	// is enabled. For example, the span from LevelError to LevelFatal only
+	// encodes the source location of ERROR and FATAL log entries, and the
+	// loggers built with the encoder skip obtaining the source location of
+	// the log entries below the span. If not provided or the value is the
+	// zero value, the source location of log entries of all levels is
+	// encoded.
+	SourceLocationSpan LevelSpan
+
 	// EncodeLabels represents whether to encode one or more labels of
 	// the log entry and append them to the encoding result. If not
 	// provided, the default value is true.
@@ -65,6 +81,95 @@ type EncoderOption struct {
 	// and append it to the encoding result. If not provided, the default
 	// value is true.
 	EncodeLevel bool
+
+	// OmitEmpty represents whether to omit the parts of the log entry that
+	// are empty instead of encoding them as null values, which includes
+	// labels without any label, an empty name and a source location that
+	// has not been parsed. This produces smaller encoding results for log
+	// ingestion systems. If not provided, the default value is false.
+	OmitEmpty bool
+
+	// UTC represents whether to convert the time of the log entry to UTC
+	// before encoding it, regardless of the time zone of the time. It only
+	// affects encoders that format the time with a time layout. If not
+	// provided, the default value is false.
+	UTC bool
+
+	// MaxFieldBytes represents the maximum number of bytes of the value of
+	// each string and byte slice field of structured messages. Oversized
+	// values are truncated and marked with an ellipsis followed by their
+	// original length, for example "abc…(1048576 bytes)", which protects
+	// log pipelines from accidental huge payloads. If not provided or the
+	// value is 0, the values of fields are not limited.
+	MaxFieldBytes int
+
+	// BinaryEncoding represents the encoding of the values of binary fields
+	// of structured messages, and its options are defined by the constants
+	// beginning with Binary... For details, please refer to the comment
+	// section of the Binary function. If not provided, the default value is
+	// BinaryBase64.
+	BinaryEncoding BinaryEncoding
+
+	// FloatPolicy represents how the non-finite values (NaN and ±Inf) of
+	// float fields of structured messages are serialized, and its options
+	// are defined by the constants beginning with Float... If not provided,
+	// the default value is FloatNull.
+	FloatPolicy FloatPolicy
+
+	// TimeFormat represents the format of the values of time fields of
+	// structured messages, and its options are defined by the constants
+	// beginning with Time... It does not affect the time of the log entry,
+	// and time fields created with the TimeAs function use their own
+	// format. If not provided, the default value is TimeUnixNano.
+	TimeFormat TimeFormat
+
+	// DuplicateFields represents how fields with duplicate names within
+	// the same object of structured messages are handled, and its options
+	// are defined by the constants beginning with Duplicate... If not
+	// provided, the default value is DuplicateAllow.
+	DuplicateFields DuplicatePolicy
+
+	// SortFields represents whether to sort the fields of each object of
+	// structured messages by name, which produces deterministic encoding
+	// results. If not provided, the default value is false.
+	SortFields bool
+
+	// ValidateRawJSON represents whether to validate the values of raw JSON
+	// fields (see the RawJSON function) of structured messages. Invalid
+	// values are serialized as JSON strings instead of being injected
+	// verbatim. If not provided, the default value is false.
+	ValidateRawJSON bool
+
+	// FallbackUnsupported represents whether to encode the messages that
+	// cannot be serialized by the encoder (for example, messages that do
+	// not implement the serializer interfaces of the JSON encoder and
+	// cannot be serialized by the encoding/json package either) as strings
+	// formatted with the "%+v" verb of the fmt package, instead of failing
+	// with the ErrUnsupportedMessage error. A rate-limited warning is
+	// reported to the diagnostics channel when this happens, so that the
+	// integration mistake is still noticed. If not provided, the default
+	// value is false.
+	FallbackUnsupported bool
+}
+
+// validateSourceLocationSpan checks whether the SourceLocationSpan option
+// is the zero value or a valid span, and returns an error if not.
+func (o EncoderOption) validateSourceLocationSpan() error {
+	if o.SourceLocationSpan == (LevelSpan { }) {
+		return nil
+	}
+	return o.SourceLocationSpan.Validate()
+}
+
+// SourceLocationEnabled returns whether the source location of log entries
+// of the given level is encoded according to the EncodeSourceLocation and
+// SourceLocationSpan options.
+func (o EncoderOption) SourceLocationEnabled(level Level) bool {
+	if !o.EncodeSourceLocation {
+		return false
+	}
+	return o.SourceLocationSpan == (LevelSpan { }) ||
+		o.SourceLocationSpan.Contains(level)
 }
 
 // NewEncoderOption returns an encoder option value with default optional
@@ -87,9 +192,10 @@ func NewEncoderOption() EncoderOption {
 // encodes log entries into JSON strings that can be easily parsed by
 // machines.
 //
-// The message type of any log entry must implement the serializer
-// interface provided by the specific encoder, otherwise the encoder does
-// not know how to encode the message.
+// The message type of any log entry should implement the serializer
+// interface provided by the specific encoder, otherwise the encoder falls
+// back to a much slower generic serialization of the message, or fails if
+// the message cannot be serialized at all.
 type Encoder interface {
 	// Encode encodes a given log entry into consecutive bytes in a specific
 	// format, then appends to the given buffer slice, and finally returns
@@ -139,6 +245,22 @@ type EncoderKeys struct {
 	// message of the log entry. If not provided, the default value is
 	// "message".
 	MessageKey string
+
+	// TextPayloadKey represents the name of the key used when encoding
+	// the text part of a structured log message. If not provided, the
+	// default value is "text".
+	TextPayloadKey string
+
+	// JSONPayloadKey represents the name of the key used when encoding
+	// the fields part of a structured log message. If not provided, the
+	// default value is "payload".
+	JSONPayloadKey string
+
+	// SequenceKey represents the name of the key used when encoding the
+	// sequence number of the log entry. The sequence number is only
+	// encoded if the logger generates it. If not provided, the default
+	// value is "sequence".
+	SequenceKey string
 }
 
 // NewEncoderKeys returns an EncoderKeys value with the name of the key
@@ -151,14 +273,93 @@ func NewEncoderKeys() EncoderKeys {
 		NameKey: "name",
 		LevelKey: "level",
 		MessageKey: "message",
+		TextPayloadKey: "text",
+		JSONPayloadKey: "payload",
+		SequenceKey: "sequence",
 	}
 }
 
+// NewGCPEncoderKeys returns an EncoderKeys value with the names of the
+// keys used by the structured logging of Google Cloud Platform, so that
+// the encoded log entries can be ingested by Cloud Logging without any
+// conversion.
+func NewGCPEncoderKeys() EncoderKeys {
+	return EncoderKeys {
+		TimeKey: "timestamp",
+		SourceLocationKey: "sourceLocation",
+		LabelsKey: "labels",
+		NameKey: "logName",
+		LevelKey: "severity",
+		MessageKey: "message",
+		TextPayloadKey: "textPayload",
+		JSONPayloadKey: "jsonPayload",
+		SequenceKey: "sequence",
+	}
+}
+
+// SerializerOption is a structure that contains the encoder options and
+// the encoder keys of a specific encoder. It is passed to the messages that
+// implement the JSONOptionSerializer or StandardOptionSerializer interface,
+// so that messages can be serialized according to the options of the
+// encoder.
+type SerializerOption struct {
+	EncoderOption
+	EncoderKeys
+
+	// standard represents whether the elements are serialized into a
+	// standard log string, so that the values implementing the
+	// StandardSerializer interface are serialized by it.
+	standard bool
+}
+
+// plain returns whether elements are serialized in the same way as without
+// serializer options, regardless of the MaxFieldBytes option.
+func (o *SerializerOption) plain() bool {
+	return o.BinaryEncoding == BinaryBase64 && o.FloatPolicy == FloatNull &&
+		o.TimeFormat == "" && o.DuplicateFields == DuplicateAllow &&
+		!o.SortFields && !o.ValidateRawJSON
+}
+
+// duplicatedFields returns whether the given message is a structured
+// message containing fields with duplicate names.
+func duplicatedFields(message Message) bool {
+	switch message := message.(type) {
+	case StructMessage:
+		return message.Fields.duplicated()
+	case *StructMessage:
+		return message.Fields.duplicated()
+	}
+	return false
+}
+
+// unsupportedWarned is the UNIX nanosecond timestamp of the last warning
+// about messages of unsupported types, which is used to limit the rate of
+// the warnings.
+var unsupportedWarned int64
+
+// unsupportedWarnInterval is the minimum interval between two warnings
+// about messages of unsupported types.
+const unsupportedWarnInterval = time.Minute
+
+// fallbackText reports a rate-limited warning about the given message of
+// an unsupported type, and returns the message formatted with the "%+v"
+// verb of the fmt package.
+func fallbackText(message Message) string {
+	diagnoseLimited(&unsupportedWarned, unsupportedWarnInterval,
+		"encoder: unsupported message type %T, encoded as a string", message)
+	return fmt.Sprintf("%+v", message)
+}
+
+// defaultEncoderKeys is the EncoderKeys value with the names of the keys
+// of the default log entry. It is used when serializing messages without
+// a specific encoder.
+var defaultEncoderKeys = NewEncoderKeys()
+
 // StandardSerializer is the public interface of the standard serializer.
 //
-// Any message type of a log entry encoded by a standard encoder must
-// implement this interface, otherwise the standard encoder does not know
-// how to encode the message part of a log entry.
+// Any message type of a log entry encoded by a standard encoder should
+// implement this interface, otherwise the standard encoder falls back to
+// the fmt package to encode the message part of a log entry.
 type StandardSerializer interface {
 	// SerializeStandard serializes the message or any content and appends
 	// to the given buffer slice, and then returns the appended buffer
@@ -166,6 +367,19 @@ type StandardSerializer interface {
 	SerializeStandard(buffer []byte) []byte
 }
 
+// StandardOptionSerializer is the public interface of the standard
+// serializer that uses the options of a specific encoder.
+//
+// If the message type of a log entry implements this interface, the
+// standard encoder uses it instead of the StandardSerializer interface.
+type StandardOptionSerializer interface {
+	// SerializeStandardOption serializes the message or any content using
+	// the given serializer options and appends to the given buffer slice,
+	// and then returns the appended buffer slice. The given options must
+	// not be modified.
+	SerializeStandardOption(buffer []byte, option *SerializerOption) []byte
+}
+
 // StandardEncoder is the structure of a standard encoder instance.
 // 
 // Standard encoders encode log entries into human-readable strings,
@@ -174,27 +388,34 @@ type StandardSerializer interface {
 // encoder is a good choice.
 //
 // Please note that the message type of any log entry encoded with a
-// standard encoder must implement the StandardSerializer interface,
-// otherwise the standard encoder does not know how to encode the
-// message part of the log entry.
+// standard encoder should implement the StandardSerializer interface,
+// otherwise the standard encoder encodes the message part of the log
+// entry as a string formatted by the String function of the fmt.Stringer
+// interface or the "%v" verb of the fmt package, which is much slower.
 type StandardEncoder struct {
 	layout string
 	option EncoderOption
+	serializer SerializerOption
 }
 
 // Encode encodes a given log entry into consecutive bytes in a specific
 // format, then appends to the given buffer slice, and finally returns
 // the appended buffer slice.
 func (e *StandardEncoder) Encode(buffer []byte, entry *Entry) ([]byte, error) {
+	if e.option.DuplicateFields == DuplicateError &&
+		duplicatedFields(entry.Message) {
+		return nil, ErrDuplicateField
+	}
 	if e.option.EncodeTime {
 		if len(e.layout) == 0 {
 			buffer = strconv.AppendInt(buffer, entry.Time.UnixNano(), 10)
 		} else {
-			buffer = entry.Time.AppendFormat(buffer, e.layout)
+			buffer = e.time(entry).AppendFormat(buffer, e.layout)
 		}
 		buffer = append(buffer, ' ')
 	}
-	if e.option.EncodeSourceLocation {
+	if e.option.SourceLocationEnabled(entry.Level) &&
+		(entry.SourceLocation.Parsed || !e.option.OmitEmpty) {
 		buffer = entry.SourceLocation.AppendString(buffer)
 		buffer = append(buffer, ' ')
 	}
@@ -211,13 +432,23 @@ func (e *StandardEncoder) Encode(buffer []byte, entry *Entry) ([]byte, error) {
 		buffer = append(buffer, entry.Level.Format()...)
 		buffer = append(buffer, "] "...)
 	}
+	if entry.Sequence > 0 {
+		buffer = append(buffer, '#')
+		buffer = strconv.AppendUint(buffer, entry.Sequence, 10)
+		buffer = append(buffer, ' ')
+	}
 	switch message := entry.Message.(type) {
 	case nil:
 		buffer = append(buffer, "null"...)
+	case StandardOptionSerializer:
+		buffer = message.SerializeStandardOption(buffer, &e.serializer)
 	case StandardSerializer:
 		buffer = message.SerializeStandard(buffer)
+	case fmt.Stringer:
+		buffer = StringMessage(message.String()).SerializeStandard(buffer)
 	default:
-		return nil, ErrUnsupportedMessage
+		buffer = StringMessage(fmt.Sprintf("%v", message)).
+			SerializeStandard(buffer)
 	}
 	return append(buffer, '\n'), nil
 }
@@ -229,6 +460,15 @@ func (e *StandardEncoder) Option() EncoderOption {
 	return e.option
 }
 
+// time returns the time of the given log entry to be encoded. If the UTC
+// option is enabled, the time is converted to UTC.
+func (e *StandardEncoder) time(entry *Entry) time.Time {
+	if e.option.UTC {
+		return entry.Time.UTC()
+	}
+	return entry.Time
+}
+
 // StandardEncoderOption is a structure that contains options for standard
 // encoders.
 type StandardEncoderOption struct {
@@ -259,11 +499,26 @@ func (o *StandardEncoderOption) UseTimeLayout(layout string) *StandardEncoderOpt
 	return o
 }
 
+// Clone creates and returns a copy of the option instance. Modifying the
+// copy does not affect the option instance, and vice versa.
+func (o *StandardEncoderOption) Clone() *StandardEncoderOption {
+	option := *o
+	return &option
+}
+
 // Build builds and returns a standard encoder instance.
 func (o *StandardEncoderOption) Build() (*StandardEncoder, error) {
+	if err := o.validateSourceLocationSpan(); err != nil {
+		return nil, err
+	}
 	return &StandardEncoder {
 		layout: o.TimeLayout,
 		option: o.EncoderOption,
+		serializer: SerializerOption {
+			EncoderOption: o.EncoderOption,
+			EncoderKeys: NewEncoderKeys(),
+			standard: true,
+		},
 	}, nil
 }
 
@@ -284,9 +539,9 @@ func NewStandardEncoder() (*StandardEncoder, error) {
 
 // JSONSerializer is the public interface of JSON serializer.
 //
-// Any message type of a log entry encoded with a JSON encoder must
-// implement this interface, otherwise the JSON encoder does not know
-// how to encode the message part of a log entry.
+// Any message type of a log entry encoded with a JSON encoder should
+// implement this interface, otherwise the JSON encoder falls back to the
+// encoding/json package to encode the message part of a log entry.
 type JSONSerializer interface {
 	// SerializeJSON serializes the message or any content and appends
 	// to the given buffer slice, and then returns the appended buffer
@@ -294,6 +549,36 @@ type JSONSerializer interface {
 	SerializeJSON(buffer []byte) []byte
 }
 
+// JSONKeysSerializer is the public interface of JSON serializer that
+// uses the key names of a specific encoder.
+//
+// If the message type of a log entry implements this interface, the
+// JSON encoder uses it instead of the JSONSerializer interface, so that
+// the key names inside the message (for example, the key names of the
+// text and fields of a structured message) can be customized.
+type JSONKeysSerializer interface {
+	// SerializeJSONKeys serializes the message or any content using the
+	// given key names and appends to the given buffer slice, and then
+	// returns the appended buffer slice. The given key names must not
+	// be modified.
+	SerializeJSONKeys(buffer []byte, keys *EncoderKeys) []byte
+}
+
+// JSONOptionSerializer is the public interface of JSON serializer that
+// uses the options of a specific encoder.
+//
+// If the message type of a log entry implements this interface, the JSON
+// encoder uses it instead of the JSONKeysSerializer and JSONSerializer
+// interfaces. The options include the key names and the encoder options
+// (for example, the maximum number of bytes of field values).
+type JSONOptionSerializer interface {
+	// SerializeJSONOption serializes the message or any content using the
+	// given serializer options and appends to the given buffer slice, and
+	// then returns the appended buffer slice. The given options must not
+	// be modified.
+	SerializeJSONOption(buffer []byte, option *SerializerOption) []byte
+}
+
 // JSONEncoder is the structure of the JSON encoder instance.
 //
 // The JSON encoder is a structured log encoder. The structured
@@ -303,22 +588,23 @@ type JSONSerializer interface {
 // is easier for humans to read.
 //
 // Please note that the message type of any log entry encoded with the
-// JSON encoder must implement the JSONSerializer interface, otherwise
-// the JSON encoder does not know how to encode the message part of the
-// log entry.
+// JSON encoder should implement the JSONSerializer interface, otherwise
+// the JSON encoder serializes the message part of the log entry using
+// the encoding/json package, which is much slower.
 type JSONEncoder struct {
 	layout string
 	keys EncoderKeys
 	option EncoderOption
+	serializer SerializerOption
 }
 
 // Encode encodes a given log entry into consecutive bytes in a specific
 // format, then appends to the given buffer slice, and finally returns
 // the appended buffer slice.
 func (e *JSONEncoder) Encode(buffer []byte, entry *Entry) ([]byte, error) {
-	message, ok := entry.Message.(JSONSerializer)
-	if !ok {
-		return nil, ErrUnsupportedMessage
+	if e.option.DuplicateFields == DuplicateError &&
+		duplicatedFields(entry.Message) {
+		return nil, ErrDuplicateField
 	}
 	buffer = append(buffer, '{')
 	if e.option.EncodeTime {
@@ -331,18 +617,20 @@ func (e *JSONEncoder) Encode(buffer []byte, entry *Entry) ([]byte, error) {
 			buffer = append(buffer, ", "...)
 		} else {
 			buffer = append(buffer, "\": \""...)
-			buffer = entry.Time.AppendFormat(buffer, e.layout)
+			buffer = e.time(entry).AppendFormat(buffer, e.layout)
 			buffer = append(buffer, "\", "...)
 		}
 	}
-	if e.option.EncodeSourceLocation {
+	if e.option.SourceLocationEnabled(entry.Level) &&
+		(entry.SourceLocation.Parsed || !e.option.OmitEmpty) {
 		buffer = append(buffer, '"')
 		buffer = append(buffer, e.keys.SourceLocationKey...)
 		buffer = append(buffer, "\": "...)
 		buffer = entry.SourceLocation.SerializeJSON(buffer)
 		buffer = append(buffer, ", "...)
 	}
-	if e.option.EncodeLabels {
+	if e.option.EncodeLabels && (entry.Labels.Count() > 0 ||
+		!e.option.OmitEmpty) {
 		buffer = append(buffer, '"')
 		buffer = append(buffer, e.keys.LabelsKey...)
 		buffer = append(buffer, `": `...)
@@ -354,7 +642,8 @@ func (e *JSONEncoder) Encode(buffer []byte, entry *Entry) ([]byte, error) {
 		}
 		buffer = append(buffer, ", "...)
 	}
-	if e.option.EncodeName {
+	if e.option.EncodeName && (len(entry.Name) > 0 ||
+		!e.option.OmitEmpty) {
 		buffer = append(buffer, '"')
 		buffer = append(buffer, e.keys.NameKey...)
 
@@ -375,10 +664,34 @@ func (e *JSONEncoder) Encode(buffer []byte, entry *Entry) ([]byte, error) {
 		buffer = entry.Level.AppendFormat(buffer)
 		buffer = append(buffer, "\", "...)
 	}
+	if entry.Sequence > 0 {
+		buffer = append(buffer, '"')
+		buffer = append(buffer, e.keys.SequenceKey...)
+		buffer = append(buffer, "\": "...)
+		buffer = strconv.AppendUint(buffer, entry.Sequence, 10)
+		buffer = append(buffer, ", "...)
+	}
 	buffer = append(buffer, '"')
 	buffer = append(buffer, e.keys.MessageKey...)
 	buffer = append(buffer, "\": "...)
-	buffer = message.SerializeJSON(buffer)
+	switch serializer := entry.Message.(type) {
+	case JSONOptionSerializer:
+		buffer = serializer.SerializeJSONOption(buffer, &e.serializer)
+	case JSONKeysSerializer:
+		buffer = serializer.SerializeJSONKeys(buffer, &e.keys)
+	case JSONSerializer:
+		buffer = serializer.SerializeJSON(buffer)
+	default:
+		var err error
+		buffer, err = appendReflectJSON(buffer, entry.Message)
+		if err == nil {
+			break
+		}
+		if !e.option.FallbackUnsupported {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedMessage, err)
+		}
+		buffer, _ = appendReflectJSON(buffer, fallbackText(entry.Message))
+	}
 	return append(buffer, "}\n"...), nil
 }
 
@@ -389,6 +702,15 @@ func (e *JSONEncoder) Option() EncoderOption {
 	return e.option
 }
 
+// time returns the time of the given log entry to be encoded. If the UTC
+// option is enabled, the time is converted to UTC.
+func (e *JSONEncoder) time(entry *Entry) time.Time {
+	if e.option.UTC {
+		return entry.Time.UTC()
+	}
+	return entry.Time
+}
+
 // JSONEncoderOption is a structure containing options for the JSON encoder.
 type JSONEncoderOption struct {
 	StandardEncoderOption
@@ -411,12 +733,35 @@ func (o *JSONEncoderOption) UseEncoderKeys(keys EncoderKeys) *JSONEncoderOption
 	return o
 }
 
+// UseGCPEncoderKeys uses the key names of the structured logging of Google
+// Cloud Platform as part of the JSON encoder options. For details, please
+// refer to the comment section of the NewGCPEncoderKeys function. Then
+// return to the option instance itself.
+func (o *JSONEncoderOption) UseGCPEncoderKeys() *JSONEncoderOption {
+	o.EncoderKeys = NewGCPEncoderKeys()
+	return o
+}
+
+// Clone creates and returns a copy of the option instance. Modifying the
+// copy does not affect the option instance, and vice versa.
+func (o *JSONEncoderOption) Clone() *JSONEncoderOption {
+	option := *o
+	return &option
+}
+
 // Build builds and returns an instance of the JSON encoder.
 func (o *JSONEncoderOption) Build() (*JSONEncoder, error) {
+	if err := o.validateSourceLocationSpan(); err != nil {
+		return nil, err
+	}
 	return &JSONEncoder {
 		layout: o.TimeLayout,
 		keys: o.EncoderKeys,
 		option: o.EncoderOption,
+		serializer: SerializerOption {
+			EncoderOption: o.EncoderOption,
+			EncoderKeys: o.EncoderKeys,
+		},
 	}, nil
 }
 
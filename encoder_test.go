@@ -23,7 +23,12 @@
 package santa
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"math"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -151,3 +156,492 @@ func TestJSONEncoderOption(t *testing.T) {
 	_, err := option.Build()
 	assert.NoError(t, err, "Unexpected build error")
 }
+
+func TestJSONEncoderEncodeKeys(t *testing.T) {
+	buffer := make([]byte, 0, 1024)
+
+	option := NewJSONEncoderOption()
+	option.UseGCPEncoderKeys()
+	option.EncodeSourceLocation = false
+
+	assert.Equal(t, NewGCPEncoderKeys(), option.EncoderKeys,
+		"Unexpected option value")
+
+	encoder, err := option.Build()
+	assert.NoError(t, err, "Unexpected JSON encoder creation error")
+
+	instance := *entry
+	instance.Message = StructMessage {
+		Text: "Hello Test!",
+		Fields: ElementObject {
+			String("name", "test"),
+		},
+	}
+
+	buffer, err = encoder.Encode(buffer, &instance)
+	assert.NoError(t, err, "Unexpected JSON encoder error")
+
+	const expected = `{
+		"timestamp": 1597326990071993900,
+		"labels": {
+			"instanceId": "d325ef24327c"
+		},
+		"logName": "test",
+		"severity": "INFO",
+		"message": {
+			"textPayload": "Hello Test!",
+			"jsonPayload": {
+				"name": "test"
+			}
+		}
+	}`
+
+	assert.JSONEq(t, expected, string(buffer),
+		"Unexpected JSON encoder output")
+}
+
+func TestEncoderOmitEmpty(t *testing.T) {
+	buffer := make([]byte, 0, 1024)
+
+	instance := Entry {
+		Time: entry.Time,
+		Level: LevelInfo,
+		Message: StringMessage("Hello Test!"),
+	}
+
+	option := NewJSONEncoderOption()
+	option.OmitEmpty = true
+
+	encoder, err := option.Build()
+	assert.NoError(t, err, "Unexpected JSON encoder creation error")
+
+	buffer, err = encoder.Encode(buffer, &instance)
+	assert.NoError(t, err, "Unexpected JSON encoder error")
+
+	assert.JSONEq(t, `{
+		"timestamp": 1597326990071993900,
+		"level": "INFO",
+		"message": "Hello Test!"
+	}`, string(buffer), "Unexpected JSON encoder output")
+
+	standardOption := NewStandardEncoderOption()
+	standardOption.OmitEmpty = true
+	standardOption.UseTimeLayout("")
+
+	standardEncoder, err := standardOption.Build()
+	assert.NoError(t, err, "Unexpected standard encoder creation error")
+
+	buffer, err = standardEncoder.Encode(buffer[ : 0], &instance)
+	assert.NoError(t, err, "Unexpected standard encoder error")
+
+	assert.Equal(t, "1597326990071993900 [INFO] \"Hello Test!\"\n",
+		string(buffer), "Unexpected standard encoder output")
+}
+
+func TestEncoderUTC(t *testing.T) {
+	buffer := make([]byte, 0, 1024)
+
+	instance := Entry {
+		Time: entry.Time,
+		Level: LevelInfo,
+		Message: StringMessage("Hello Test!"),
+	}
+
+	option := NewStandardEncoderOption()
+	option.UseTimeLayout(time.RFC3339Nano)
+	option.EncodeSourceLocation = false
+	option.UTC = true
+
+	encoder, err := option.Build()
+	assert.NoError(t, err, "Unexpected standard encoder creation error")
+
+	buffer, err = encoder.Encode(buffer, &instance)
+	assert.NoError(t, err, "Unexpected standard encoder error")
+
+	assert.Equal(t, "2020-08-13T13:56:30.0719939Z [INFO] \"Hello Test!\"\n",
+		string(buffer), "Unexpected standard encoder output")
+
+	jsonOption := NewJSONEncoderOption()
+	jsonOption.UseTimeLayout(time.RFC3339Nano)
+	jsonOption.EncodeSourceLocation = false
+	jsonOption.OmitEmpty = true
+	jsonOption.UTC = true
+
+	jsonEncoder, err := jsonOption.Build()
+	assert.NoError(t, err, "Unexpected JSON encoder creation error")
+
+	buffer, err = jsonEncoder.Encode(buffer[ : 0], &instance)
+	assert.NoError(t, err, "Unexpected JSON encoder error")
+
+	assert.JSONEq(t, `{
+		"timestamp": "2020-08-13T13:56:30.0719939Z",
+		"level": "INFO",
+		"message": "Hello Test!"
+	}`, string(buffer), "Unexpected JSON encoder output")
+}
+
+func TestEncoderSequence(t *testing.T) {
+	buffer := make([]byte, 0, 1024)
+
+	instance := Entry {
+		Time: entry.Time,
+		Level: LevelInfo,
+		Message: StringMessage("Hello Test!"),
+		Sequence: 42,
+	}
+
+	option := NewJSONEncoderOption()
+	option.OmitEmpty = true
+
+	encoder, err := option.Build()
+	assert.NoError(t, err, "Unexpected JSON encoder creation error")
+
+	buffer, err = encoder.Encode(buffer, &instance)
+	assert.NoError(t, err, "Unexpected JSON encoder error")
+
+	assert.JSONEq(t, `{
+		"timestamp": 1597326990071993900,
+		"level": "INFO",
+		"sequence": 42,
+		"message": "Hello Test!"
+	}`, string(buffer), "Unexpected JSON encoder output")
+
+	standardOption := NewStandardEncoderOption()
+	standardOption.OmitEmpty = true
+	standardOption.UseTimeLayout("")
+
+	standardEncoder, err := standardOption.Build()
+	assert.NoError(t, err, "Unexpected standard encoder creation error")
+
+	buffer, err = standardEncoder.Encode(buffer[ : 0], &instance)
+	assert.NoError(t, err, "Unexpected standard encoder error")
+
+	assert.Equal(t, "1597326990071993900 [INFO] #42 \"Hello Test!\"\n",
+		string(buffer), "Unexpected standard encoder output")
+}
+
+func TestEncoderMaxFieldBytes(t *testing.T) {
+	buffer := make([]byte, 0, 1024)
+
+	instance := Entry {
+		Time: entry.Time,
+		Level: LevelInfo,
+		Message: StructMessage {
+			Text: "Hello Test!",
+			Fields: ElementObject {
+				String("short", "abc"),
+				String("long", "abcdefgh"),
+				String("unicode", "ab世界"),
+				ByteString("bytes", []byte("abcdefgh")),
+				Strings("strings", []string { "abc", "abcdefgh" }),
+				Object("object", String("nested", "abcdefgh")),
+			},
+		},
+	}
+
+	option := NewJSONEncoderOption()
+	option.OmitEmpty = true
+	option.MaxFieldBytes = 4
+
+	encoder, err := option.Build()
+	assert.NoError(t, err, "Unexpected JSON encoder creation error")
+
+	buffer, err = encoder.Encode(buffer, &instance)
+	assert.NoError(t, err, "Unexpected JSON encoder error")
+
+	assert.JSONEq(t, `{
+		"timestamp": 1597326990071993900,
+		"level": "INFO",
+		"message": {
+			"text": "Hello Test!",
+			"payload": {
+				"short": "abc",
+				"long": "abcd…(8 bytes)",
+				"unicode": "ab…(8 bytes)",
+				"bytes": "abcd…(8 bytes)",
+				"strings": ["abc", "abcd…(8 bytes)"],
+				"object": {"nested": "abcd…(8 bytes)"}
+			}
+		}
+	}`, string(buffer), "Unexpected JSON encoder output")
+
+	standardOption := NewStandardEncoderOption()
+	standardOption.OmitEmpty = true
+	standardOption.MaxFieldBytes = 4
+	standardOption.UseTimeLayout("")
+
+	standardEncoder, err := standardOption.Build()
+	assert.NoError(t, err, "Unexpected standard encoder creation error")
+
+	buffer, err = standardEncoder.Encode(buffer[ : 0], &instance)
+	assert.NoError(t, err, "Unexpected standard encoder error")
+
+	assert.Contains(t, string(buffer), `"long": "abcd…(8 bytes)"`,
+		"Unexpected standard encoder output")
+}
+
+func TestEncoderBinaryEncoding(t *testing.T) {
+	instance := Entry {
+		Time: entry.Time,
+		Level: LevelInfo,
+		Message: StructMessage {
+			Text: "Hello Test!",
+			Fields: ElementObject {
+				Binary("binary", []byte { 0xde, 0xad, 0xbe, 0xef }),
+				Object("object", Binary("nested", []byte { 0xff })),
+			},
+		},
+	}
+
+	option := NewJSONEncoderOption()
+	option.OmitEmpty = true
+
+	encoder, err := option.Build()
+	assert.NoError(t, err, "Unexpected JSON encoder creation error")
+
+	buffer, err := encoder.Encode(nil, &instance)
+	assert.NoError(t, err, "Unexpected JSON encoder error")
+	assert.Contains(t, string(buffer),
+		`"payload": {"binary": "3q2+7w==", "object": {"nested": "/w=="}}`,
+		"Unexpected JSON encoder output")
+
+	option.BinaryEncoding = BinaryHex
+	encoder, err = option.Build()
+	assert.NoError(t, err, "Unexpected JSON encoder creation error")
+
+	buffer, err = encoder.Encode(buffer[ : 0], &instance)
+	assert.NoError(t, err, "Unexpected JSON encoder error")
+	assert.Contains(t, string(buffer),
+		`"payload": {"binary": "deadbeef", "object": {"nested": "ff"}}`,
+		"Unexpected JSON encoder output")
+}
+
+func TestEncoderFloatPolicy(t *testing.T) {
+	instance := Entry {
+		Time: entry.Time,
+		Level: LevelInfo,
+		Message: StructMessage {
+			Text: "Hello Test!",
+			Fields: ElementObject {
+				Float64("nan", math.NaN()),
+				Float64("ratio", 0.5),
+			},
+		},
+	}
+
+	option := NewJSONEncoderOption()
+	option.OmitEmpty = true
+
+	encoder, err := option.Build()
+	assert.NoError(t, err, "Unexpected JSON encoder creation error")
+
+	buffer, err := encoder.Encode(nil, &instance)
+	assert.NoError(t, err, "Unexpected JSON encoder error")
+	assert.Contains(t, string(buffer),
+		`"payload": {"nan": null, "ratio": 0.5}`,
+		"Unexpected JSON encoder output")
+
+	option.FloatPolicy = FloatString
+	encoder, err = option.Build()
+	assert.NoError(t, err, "Unexpected JSON encoder creation error")
+
+	buffer, err = encoder.Encode(buffer[ : 0], &instance)
+	assert.NoError(t, err, "Unexpected JSON encoder error")
+	assert.Contains(t, string(buffer),
+		`"payload": {"nan": "NaN", "ratio": 0.5}`,
+		"Unexpected JSON encoder output")
+
+	option.FloatPolicy = FloatOmit
+	encoder, err = option.Build()
+	assert.NoError(t, err, "Unexpected JSON encoder creation error")
+
+	buffer, err = encoder.Encode(buffer[ : 0], &instance)
+	assert.NoError(t, err, "Unexpected JSON encoder error")
+	assert.Contains(t, string(buffer), `"payload": {"ratio": 0.5}`,
+		"Unexpected JSON encoder output")
+}
+
+func TestEncoderTimeFormat(t *testing.T) {
+	instance := Entry {
+		Time: entry.Time,
+		Level: LevelInfo,
+		Message: StructMessage {
+			Text: "Hello Test!",
+			Fields: ElementObject {
+				Time("time", entry.Time.UTC()),
+				TimeAs("nanos", entry.Time, TimeUnixNano),
+			},
+		},
+	}
+
+	option := NewJSONEncoderOption()
+	option.OmitEmpty = true
+	option.TimeFormat = TimeRFC3339Nano
+
+	encoder, err := option.Build()
+	assert.NoError(t, err, "Unexpected JSON encoder creation error")
+
+	buffer, err := encoder.Encode(nil, &instance)
+	assert.NoError(t, err, "Unexpected JSON encoder error")
+	assert.JSONEq(t, `{
+		"timestamp": 1597326990071993900,
+		"level": "INFO",
+		"message": {
+			"text": "Hello Test!",
+			"payload": {
+				"time": "2020-08-13T13:56:30.0719939Z",
+				"nanos": 1597326990071993900
+			}
+		}
+	}`, string(buffer), "Unexpected JSON encoder output")
+}
+
+func TestEncoderDuplicateFields(t *testing.T) {
+	instance := Entry {
+		Time: entry.Time,
+		Level: LevelInfo,
+		Message: StructMessage {
+			Text: "Hello Test!",
+			Fields: ElementObject {
+				String("name", "a"),
+				String("name", "b"),
+			},
+		},
+	}
+
+	option := NewJSONEncoderOption()
+	option.DuplicateFields = DuplicateError
+	encoder, err := option.Build()
+	assert.NoError(t, err, "Unexpected JSON encoder creation error")
+	_, err = encoder.Encode(nil, &instance)
+	assert.Equal(t, ErrDuplicateField, err, "Unexpected JSON encoder error")
+
+	standardOption := NewStandardEncoderOption()
+	standardOption.DuplicateFields = DuplicateError
+	standardEncoder, err := standardOption.Build()
+	assert.NoError(t, err, "Unexpected standard encoder creation error")
+	_, err = standardEncoder.Encode(nil, &instance)
+	assert.Equal(t, ErrDuplicateField, err, "Unexpected standard encoder error")
+
+	option.DuplicateFields = DuplicateLastWins
+	encoder, err = option.Build()
+	assert.NoError(t, err, "Unexpected JSON encoder creation error")
+	buffer, err := encoder.Encode(nil, &instance)
+	assert.NoError(t, err, "Unexpected JSON encoder error")
+	assert.Contains(t, string(buffer), `"payload": {"name": "b"}`,
+		"Unexpected JSON encoder output")
+}
+
+type testStringerMessage struct { }
+
+func (m testStringerMessage) String() string {
+	return "Hello Test!"
+}
+
+func TestEncoderFallbackMessage(t *testing.T) {
+	instance := Entry {
+		Time: entry.Time,
+		Level: LevelInfo,
+		Message: struct { Code int } { 404 },
+	}
+
+	standardEncoder, err := NewStandardEncoder()
+	assert.NoError(t, err, "Unexpected standard encoder creation error")
+	output, err := standardEncoder.Encode(nil, &instance)
+	assert.NoError(t, err, "Unexpected standard encoder error")
+	assert.Contains(t, string(output), `[INFO] "{404}"`,
+		"Unexpected standard encoder output")
+
+	encoder, err := NewJSONEncoder()
+	assert.NoError(t, err, "Unexpected JSON encoder creation error")
+	output, err = encoder.Encode(nil, &instance)
+	assert.NoError(t, err, "Unexpected JSON encoder error")
+	assert.Contains(t, string(output), `"message": {"Code":404}}`,
+		"Unexpected JSON encoder output")
+
+	instance.Message = testStringerMessage { }
+	output, err = standardEncoder.Encode(nil, &instance)
+	assert.NoError(t, err, "Unexpected standard encoder error")
+	assert.Contains(t, string(output), `[INFO] "Hello Test!"`,
+		"Unexpected standard encoder output")
+	output, err = encoder.Encode(nil, &instance)
+	assert.NoError(t, err, "Unexpected JSON encoder error")
+	assert.Contains(t, string(output), `"message": {}}`,
+		"Unexpected JSON encoder output")
+
+	instance.Message = nil
+	output, err = encoder.Encode(nil, &instance)
+	assert.NoError(t, err, "Unexpected JSON encoder error")
+	assert.Contains(t, string(output), `"message": null}`,
+		"Unexpected JSON encoder output")
+}
+
+func TestEncoderFallbackUnsupported(t *testing.T) {
+	buffer := &bytes.Buffer { }
+	SetDiagnosticsWriter(buffer)
+	defer SetDiagnosticsWriter(os.Stderr)
+	unsupportedWarned = 0
+
+	instance := Entry {
+		Time: entry.Time,
+		Level: LevelInfo,
+		Message: struct { Code chan int } { },
+	}
+
+	option := NewJSONEncoderOption()
+	encoder, err := option.Build()
+	assert.NoError(t, err, "Unexpected JSON encoder creation error")
+	_, err = encoder.Encode(nil, &instance)
+	assert.True(t, errors.Is(err, ErrUnsupportedMessage),
+		"Unexpected JSON encoder error")
+
+	option.FallbackUnsupported = true
+	encoder, err = option.Build()
+	assert.NoError(t, err, "Unexpected JSON encoder creation error")
+	for index := 0; index < 2; index++ {
+		output, err := encoder.Encode(nil, &instance)
+		assert.NoError(t, err, "Unexpected JSON encoder error")
+		assert.Contains(t, string(output), `"message": "{Code:<nil>}"}`,
+			"Unexpected JSON encoder output")
+	}
+
+	assert.Equal(t, 1, strings.Count(buffer.String(),
+		"unsupported message type struct { Code chan int }"),
+		"Unexpected diagnostics report")
+}
+
+func TestEncoderSourceLocationSpan(t *testing.T) {
+	option := NewJSONEncoderOption()
+	option.EncoderOption = encoderOption
+	option.SourceLocationSpan = LevelSpan {
+		Start: LevelError,
+		End: LevelFatal,
+	}
+	assert.False(t, option.SourceLocationEnabled(LevelInfo),
+		"Unexpected source location policy")
+	assert.True(t, option.SourceLocationEnabled(LevelError),
+		"Unexpected source location policy")
+
+	encoder, err := option.Build()
+	assert.NoError(t, err, "Unexpected JSON encoder creation error")
+
+	instance := entry.Clone()
+	output, err := encoder.Encode(nil, instance)
+	assert.NoError(t, err, "Unexpected JSON encoder error")
+	assert.NotContains(t, string(output), "sourceLocation",
+		"Unexpected JSON encoder output")
+
+	instance.Level = LevelError
+	output, err = encoder.Encode(nil, instance)
+	assert.NoError(t, err, "Unexpected JSON encoder error")
+	assert.Contains(t, string(output), "sourceLocation",
+		"Unexpected JSON encoder output")
+
+	option.SourceLocationSpan = LevelSpan {
+		Start: LevelFatal,
+		End: LevelError,
+	}
+	_, err = option.Build()
+	assert.True(t, errors.Is(err, ErrInvalidSpan),
+		"Unexpected JSON encoder creation error")
+}
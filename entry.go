@@ -26,6 +26,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -42,6 +43,11 @@ type EntrySourceLocation struct {
 	// Line represents the line number of the source file where
 	// the caller of the log entry is printed.
 	Line int
+
+	// Function represents the fully qualified name of the caller
+	// function that printed the log entry. If empty, the name is
+	// resolved from the Proc field when needed.
+	Function string
 	
 	// Parsed represents whether the source of the log entry has
 	// been successfully parsed.
@@ -78,20 +84,54 @@ func (s EntrySourceLocation) SerializeJSON(buffer []byte) []byte {
 	buffer = append(buffer, "\", \"line\": "...)
 	buffer = strconv.AppendInt(buffer, int64(s.Line), 10)
 	buffer = append(buffer, ", \"function\": \""...)
-	buffer = append(buffer, runtime.FuncForPC(s.Proc).Name()...)
+	buffer = append(buffer, s.FunctionName()...)
 	return append(buffer, "\"}"...)
 }
 
-// newEntrySourceLocation receives the return value of the runtime.Caller
-// function to facilitate the creation of the value of the source location
-// of the log entry.
-func newEntrySourceLocation(p uintptr, f string, l int, ok bool) EntrySourceLocation {
-	return EntrySourceLocation {
-		Proc: p,
-		File: f,
-		Line: l,
-		Parsed: ok,
+// FunctionName returns the fully qualified name of the caller function
+// that printed the log entry. If the name is unknown, it returns an
+// empty string.
+func (s EntrySourceLocation) FunctionName() string {
+	if len(s.Function) > 0 {
+		return s.Function
+	}
+	if proc := runtime.FuncForPC(s.Proc); proc != nil {
+		return proc.Name()
 	}
+	return ""
+}
+
+// sourceLocations is the cache of the resolved source locations of call
+// sites, keyed by the program counter of each call site. Resolving the
+// file, line and function of a program counter is much more expensive
+// than obtaining it, and the number of call sites of an application is
+// bounded, so each call site is only resolved once.
+var sourceLocations sync.Map
+
+// captureSourceLocation returns the source location of the caller of the
+// function that calls this function, skipping the given number of stack
+// frames in the same way as the runtime.Caller function called by that
+// function.
+func captureSourceLocation(skip int) EntrySourceLocation {
+	var pcs [1]uintptr
+	// The frames of the runtime.Callers function and this function are
+	// skipped as well.
+	if runtime.Callers(skip + 2, pcs[ : ]) == 0 {
+		return EntrySourceLocation { }
+	}
+	if location, ok := sourceLocations.Load(pcs[0]); ok {
+		return location.(EntrySourceLocation)
+	}
+	frame, _ := runtime.CallersFrames(pcs[ : ]).Next()
+	location := EntrySourceLocation {
+		Proc: frame.PC,
+		File: frame.File,
+		Line: frame.Line,
+		Function: frame.Function,
+		Parsed: len(frame.File) > 0,
+	}
+	sourceLocations.Store(pcs[0], location)
+	return location
 }
 
 // Entry is the structure of the log entry instance.
@@ -110,6 +150,16 @@ type Entry struct {
 	// The value can be nil.
 	Message Message
 
+	// Fields represents the fields of the structured message of the log
+	// entry, which allows hooks, samplers and exporters to inspect the
+	// fields without asserting the type of the message. If the message is
+	// not a structured message, the value is nil.
+	//
+	// The fields are shared with the message and must not be modified. To
+	// change the fields, replace the message with the SetMessage function,
+	// which updates the value as well.
+	Fields ElementObject
+
 	// SourceLocation represents the source code location of the log
 	// entry, usually the calling location of the log entry operation.
 	SourceLocation EntrySourceLocation
@@ -121,4 +171,57 @@ type Entry struct {
 	// details, please refer to the annotation section of the
 	// SerializedLabels structure.
 	Labels SerializedLabels
+
+	// Sequence represents the sequence number of the log entry. Sequence
+	// numbers are incremented atomically by the logger (and all copies
+	// of it) starting from 1, which allows consumers to detect lost log
+	// entries and restore their order. Only the log entries accepted by
+	// the sampler and hooks are numbered, so the sampled out log entries
+	// do not cause gaps. Hooks observe the value 0. If the logger does not
+	// generate sequence numbers, the value is 0.
+	Sequence uint64
+}
+
+// Clone creates and returns a deep copy of the log entry, including a
+// snapshot of the message of the log entry. For details, please refer to
+// the comment section of the MessageCloner interface.
+//
+// The log entries passed to hooks and exporters are allocated from the
+// entry pool and will be reused immediately after being exported, which
+// means that any hook or exporter that needs to retain a log entry after
+// returning (for example, to process it asynchronously in another
+// coroutine) must retain a copy created by this function instead.
+//
+// Please note that the returned copy is not allocated from the entry pool
+// and should not be returned to it.
+func (e *Entry) Clone() *Entry {
+	instance := *e
+	instance.Message = CloneMessage(e.Message)
+	if fields, ok := messageFields(instance.Message); ok {
+		instance.Fields = fields
+	} else if e.Fields != nil {
+		instance.Fields = e.Fields.Clone()
+	}
+	return &instance
+}
+
+// SetMessage uses the given message as the message of the log entry, and
+// updates the Fields of the log entry to the fields of the message. Hooks
+// that replace the message of log entries should use this function, so
+// that the fields of log entries stay consistent with their messages.
+func (e *Entry) SetMessage(message Message) {
+	e.Message = message
+	e.Fields, _ = messageFields(message)
+}
+
+// messageFields returns the fields of the given message if it is a
+// structured message, otherwise it returns false.
+func messageFields(message Message) (ElementObject, bool) {
+	switch message := message.(type) {
+	case StructMessage:
+		return message.Fields, true
+	case *StructMessage:
+		return message.Fields, true
+	}
+	return nil, false
 }
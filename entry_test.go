@@ -53,3 +53,88 @@ func TestEntrySourceLocation(t *testing.T) {
 	assert.JSONEq(t, expected, string(buffer),
 		"Unexpected append result")
 }
+
+func TestCaptureSourceLocation(t *testing.T) {
+	locations := make([]EntrySourceLocation, 2)
+	for index := 0; index < len(locations); index++ {
+		locations[index] = captureSourceLocation(0)
+	}
+
+	assert.True(t, locations[0].Parsed, "Unexpected source location")
+	assert.Contains(t, locations[0].File, "entry_test.go",
+		"Unexpected source location")
+	assert.Equal(t, "github.com/nobody-night/santa.TestCaptureSourceLocation",
+		locations[0].FunctionName(), "Unexpected source location")
+	assert.Equal(t, locations[0], locations[1], "Unexpected source location")
+
+	cached := 0
+	sourceLocations.Range(func(key, value interface { }) bool {
+		if value.(EntrySourceLocation) == locations[0] {
+			cached++
+		}
+		return true
+	})
+	assert.Equal(t, 1, cached, "Unexpected cached source locations")
+
+	location := EntrySourceLocation { Proc: locations[0].Proc }
+	assert.Equal(t, locations[0].Function, location.FunctionName(),
+		"Unexpected function name")
+	assert.Empty(t, EntrySourceLocation { }.FunctionName(),
+		"Unexpected function name")
+}
+
+func TestEntryClone(t *testing.T) {
+	fields := []Field {
+		String("name", "test"),
+		ByteString("bytes", []byte("Hello")),
+	}
+
+	message := pool.Message.Structure.New("Hello Test!", fields)
+
+	instance := pool.Entry.New()
+	instance.Level = LevelInfo
+	instance.SetMessage(message)
+	instance.Name = "test"
+
+	clone := instance.Clone()
+
+	// Modify the original data after the log entry has been exported.
+	fields[0] = Int("age", 100)
+	fields[1].Interface.([]byte)[0] = 'h'
+	pool.Message.Structure.Free(message)
+	pool.Entry.Free(instance)
+
+	assert.Equal(t, LevelInfo, clone.Level, "Unexpected clone value")
+	assert.Equal(t, "test", clone.Name, "Unexpected clone value")
+	assert.IsType(t, StructMessage { }, clone.Message,
+		"Unexpected clone message type")
+
+	snapshot := clone.Message.(StructMessage)
+	assert.Equal(t, "Hello Test!", snapshot.Text, "Unexpected clone message")
+	assert.Equal(t, `{"name": "test", "bytes": "Hello"}`, string(
+		snapshot.Fields.SerializeJSON(nil)), "Unexpected clone message")
+
+	assert.Equal(t, snapshot.Fields, clone.Fields, "Unexpected clone fields")
+
+	clone = (&Entry { Message: StringMessage("Hello Test!") }).Clone()
+	assert.Equal(t, StringMessage("Hello Test!"), clone.Message,
+		"Unexpected clone message")
+	assert.Nil(t, clone.Fields, "Unexpected clone fields")
+}
+
+func TestEntrySetMessage(t *testing.T) {
+	instance := &Entry { }
+	fields := ElementObject { String("name", "test") }
+
+	instance.SetMessage(StructMessage { Text: "Hello Test!",
+		Fields: fields })
+	assert.Equal(t, fields, instance.Fields, "Unexpected entry fields")
+
+	instance.SetMessage(&StructMessage { Text: "Hello Test!" })
+	assert.Equal(t, ElementObject(nil), instance.Fields,
+		"Unexpected entry fields")
+
+	instance.SetMessage(StructMessage { Fields: fields })
+	instance.SetMessage(StringMessage("Hello Test!"))
+	assert.Nil(t, instance.Fields, "Unexpected entry fields")
+}
@@ -0,0 +1,90 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"errors"
+	"strings"
+)
+
+// MultiError is a data type that represents multiple errors encountered by
+// an operation that does not stop at the first error, for example, closing
+// all exporters of a logger.
+//
+// The errors.Is and errors.As functions check each of the errors in order,
+// so a specific error can still be detected in a multiple error instance.
+type MultiError []error
+
+// Error returns the error messages of all errors, separated by semicolons.
+func (e MultiError) Error() string {
+	messages := make([]string, len(e))
+	for index, err := range e {
+		messages[index] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Is checks whether any of the errors matches the given target error. For
+// details, please refer to the comment section of the errors.Is function.
+func (e MultiError) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error that matches the given target, and if so, sets
+// the target to the error value and returns true. For details, please refer
+// to the comment section of the errors.As function.
+func (e MultiError) As(target interface { }) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Errors returns the errors of the multiple error instance.
+func (e MultiError) Errors() []error {
+	return e
+}
+
+// appendError appends the given error to the given result error, and then
+// returns the result error. If the given error is nil, the result error is
+// returned unchanged. If only one error is encountered, the error itself is
+// returned instead of a multiple error instance.
+func appendError(result error, err error) error {
+	switch {
+	case err == nil:
+		return result
+	case result == nil:
+		return err
+	}
+	if errs, ok := result.(MultiError); ok {
+		return append(errs, err)
+	}
+	return MultiError { result, err }
+}
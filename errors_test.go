@@ -0,0 +1,55 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiError(t *testing.T) {
+	first := errors.New("first")
+	second := &ExporterError { Err: errors.New("second") }
+
+	assert.Nil(t, appendError(nil, nil), "Unexpected append result")
+	assert.Equal(t, first, appendError(nil, first),
+		"Unexpected append result")
+	assert.Equal(t, first, appendError(first, nil),
+		"Unexpected append result")
+
+	err := appendError(appendError(appendError(nil, first), second),
+		ErrClosed)
+	assert.Equal(t, "first; second; instance has been closed", err.Error(),
+		"Unexpected error message")
+	assert.Equal(t, 3, len(err.(MultiError).Errors()),
+		"Unexpected error count")
+	assert.True(t, errors.Is(err, first), "Unexpected is result")
+	assert.True(t, errors.Is(err, ErrClosed), "Unexpected is result")
+	assert.False(t, errors.Is(err, ErrInvalidType), "Unexpected is result")
+
+	var target *ExporterError
+	assert.True(t, errors.As(err, &target), "Unexpected as result")
+	assert.Equal(t, second, target, "Unexpected as result")
+}
@@ -0,0 +1,181 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// SyncCloser is the public interface of the instance that can be flushed
+// and closed when the application exits.
+//
+// All logger types provided by Santa (including but not limited to: the
+// standard logger, template logger and structured logger) implement this
+// interface.
+type SyncCloser interface {
+	// Sync writes the internal cache data of a specific synchronizer to a
+	// specific storage device, and then returns any errors encountered.
+	Sync() error
+
+	// Close closes the instance, and then returns any errors encountered.
+	Close() error
+}
+
+// exitRegistry is a structure that contains the instances that need to
+// be flushed and closed when the application exits.
+type exitRegistry struct {
+	mutex sync.Mutex
+	instances []SyncCloser
+	signals chan os.Signal
+}
+
+// exit is the default exit registry instance, which is shared globally.
+var exit = &exitRegistry { }
+
+// RegisterExitFlush registers one or more given loggers to the exit
+// registry. When the ExitFlush function is called, or a signal handled by
+// the HandleExitSignals function is received, each registered logger will
+// be flushed and closed in the order of registration.
+//
+// This helps to avoid the loss of log entry data that is still cached by
+// the synchronizer when the application is terminated, which is very
+// common in containers.
+//
+// The API is thread-safe.
+func RegisterExitFlush(loggers ...SyncCloser) {
+	exit.mutex.Lock()
+	exit.instances = append(exit.instances, loggers...)
+	exit.mutex.Unlock()
+}
+
+// UnregisterExitFlush removes one or more given loggers from the exit
+// registry. Loggers that have not been registered will be ignored.
+//
+// The API is thread-safe.
+func UnregisterExitFlush(loggers ...SyncCloser) {
+	exit.mutex.Lock()
+	for index := 0; index < len(loggers); index++ {
+		for position := 0; position < len(exit.instances); position++ {
+			if exit.instances[position] != loggers[index] {
+				continue
+			}
+			exit.instances = append(exit.instances[ : position],
+				exit.instances[position + 1 : ]...)
+			break
+		}
+	}
+	exit.mutex.Unlock()
+}
+
+// ExitFlush flushes and closes all registered loggers, and then removes
+// them from the exit registry. Even if an error is encountered, the
+// remaining loggers will still be flushed and closed, and then the first
+// error encountered is returned.
+//
+// Usually, the application should call this function before exiting, for
+// example: defer santa.ExitFlush() in the main function.
+//
+// The API is thread-safe.
+func ExitFlush() error {
+	exit.mutex.Lock()
+	instances := exit.instances
+	exit.instances = nil
+	exit.mutex.Unlock()
+
+	var result error
+	for index := 0; index < len(instances); index++ {
+		err := instances[index].Sync()
+		if err != nil && result == nil {
+			result = err
+		}
+		err = instances[index].Close()
+		if err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
+
+// HandleExitSignals installs a handler for one or more given signals. When
+// any of the signals is received, all registered loggers are flushed and
+// closed by the ExitFlush function, and then the application exits with the
+// conventional exit code of the signal (128 + signal number).
+//
+// If no signal is given, SIGTERM and SIGINT are handled by default. If the
+// handler has been installed, calling this function again only changes the
+// handled signals.
+//
+// The API is thread-safe.
+func HandleExitSignals(signals ...os.Signal) {
+	if len(signals) == 0 {
+		signals = []os.Signal { syscall.SIGTERM, syscall.SIGINT }
+	}
+	exit.mutex.Lock()
+	defer exit.mutex.Unlock()
+	if exit.signals != nil {
+		signal.Stop(exit.signals)
+		signal.Notify(exit.signals, signals...)
+		return
+	}
+	exit.signals = make(chan os.Signal, 1)
+	signal.Notify(exit.signals, signals...)
+	go exitHandler(exit.signals)
+}
+
+// StopExitSignals uninstalls the handler installed by the HandleExitSignals
+// function. After that, the signals are handled in the default way again.
+//
+// The API is thread-safe.
+func StopExitSignals() {
+	exit.mutex.Lock()
+	defer exit.mutex.Unlock()
+	if exit.signals == nil {
+		return
+	}
+	signal.Stop(exit.signals)
+	close(exit.signals)
+	exit.signals = nil
+}
+
+// exitHandler waits for the given signal channel to receive a signal, and
+// then flushes and closes all registered loggers and exits the application.
+// If the signal channel is closed, it returns directly.
+//
+// This function should run in an independent coroutine context.
+func exitHandler(signals chan os.Signal) {
+	received, ok := <-signals
+	if !ok {
+		return
+	}
+	// Discard any errors encountered, the application is exiting and
+	// there is no more place to report them.
+	_ = ExitFlush()
+	code := 1
+	if number, ok := received.(syscall.Signal); ok {
+		code = 128 + int(number)
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,62 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitFlush(t *testing.T) {
+	logger, err := NewStandardBenchmark(false, EncoderStandard)
+	assert.NoError(t, err, "Unexpected create error")
+
+	unregistered, err := NewStandardBenchmark(false, EncoderStandard)
+	assert.NoError(t, err, "Unexpected create error")
+
+	RegisterExitFlush(logger, unregistered)
+	UnregisterExitFlush(unregistered)
+
+	assert.NoError(t, ExitFlush(), "Unexpected flush error")
+	assert.True(t, logger.IsClosed(), "Unexpected logger state")
+	assert.False(t, unregistered.IsClosed(), "Unexpected logger state")
+
+	// The registry is empty after flushing.
+	assert.NoError(t, ExitFlush(), "Unexpected flush error")
+	assert.NoError(t, unregistered.Close(), "Unexpected close error")
+}
+
+func TestHandleExitSignals(t *testing.T) {
+	HandleExitSignals()
+	assert.NotNil(t, exit.signals, "Unexpected handler state")
+
+	HandleExitSignals(syscall.SIGTERM)
+	assert.NotNil(t, exit.signals, "Unexpected handler state")
+
+	StopExitSignals()
+	assert.Nil(t, exit.signals, "Unexpected handler state")
+
+	StopExitSignals()
+}
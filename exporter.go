@@ -22,6 +22,56 @@
 
 package santa
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// OversizePolicy is a data type that represents how the exporter handles
+// log entries whose encoded data exceeds the maximum number of bytes.
+type OversizePolicy uint8
+
+const (
+	// OversizeTruncate means that the message of an oversized log entry
+	// is truncated so that the encoded data fits the maximum number of
+	// bytes. The truncated message is marked with an ellipsis followed by
+	// the original length of the message text. The fields of structured
+	// messages are kept, so if the fields alone exceed the maximum number
+	// of bytes, the log entry is dropped like OversizeDrop.
+	OversizeTruncate OversizePolicy = iota
+
+	// OversizeDrop means that an oversized log entry is dropped, and a
+	// synthetic WARNING log entry describing the dropped log entry is
+	// written instead.
+	OversizeDrop
+
+	// OversizeSplit means that the message text of an oversized log entry
+	// is split into multiple consecutive log entries with the same time,
+	// level, name, labels and source location, so that the encoded data of
+	// each log entry fits the maximum number of bytes. The fields of
+	// structured messages are repeated in each log entry. If the message
+	// text cannot be split to fit, for example because a single character
+	// is wider than the available bytes, the log entry is dropped like
+	// OversizeDrop before any part of it is written.
+	OversizeSplit
+)
+
+var (
+	// ErrInvalidOversizePolicy represents that the given oversize policy
+	// or maximum number of bytes of log entries is invalid.
+	ErrInvalidOversizePolicy = errors.New("invalid oversize policy")
+
+	// ErrEntryTooLarge represents that the encoded data of a log entry
+	// exceeds the maximum number of bytes and cannot be reduced to fit it,
+	// usually because the maximum number of bytes is too small for even
+	// the metadata of the log entry.
+	ErrEntryTooLarge = errors.New("log entry too large")
+)
+
 // Exporter is a public interface for exporters.
 //
 // The exporter uses a specific encoder to encode log entries into
@@ -47,6 +97,10 @@ type Exporter interface {
 	// entry data to a specific storage device.
 	//
 	// Finally, any errors encountered are returned.
+	//
+	// Please note that the log entry is only valid until the function
+	// returns. Exporters that export log entries asynchronously must
+	// retain a copy created by the Clone function of the Entry structure.
 	Export(entry *Entry) error
 
 	// Sync writes the internal cache data of a specific synchronizer to a
@@ -65,6 +119,72 @@ type Exporter interface {
 	Close() error
 }
 
+// Shutdowner is a public interface for exporters that support graceful
+// shutdown with a deadline.
+//
+// Exporters that export log entries asynchronously (such as asynchronous
+// exporters) implement this interface, so that the log entries that have
+// been appended can be drained before the application exits, without
+// waiting for a failing storage device indefinitely.
+type Shutdowner interface {
+	// Shutdown stops accepting log entries and waits until the appended
+	// log entries have been exported or the given context is done, and
+	// then closes the exporter. The log entries that have not been
+	// exported when the context is done are abandoned.
+	//
+	// Finally, it returns the number of abandoned log entries and any
+	// errors encountered. If the context is done before the exporter is
+	// closed, the error of the context is returned.
+	Shutdown(ctx context.Context) (uint64, error)
+}
+
+// shutdownExporter shuts down the given exporter with the given context,
+// and then returns the number of abandoned log entries and any errors
+// encountered. If the exporter does not implement the Shutdowner interface,
+// it is closed in an independent coroutine, and the error of the context is
+// returned if the context is done before the exporter is closed.
+func shutdownExporter(ctx context.Context, exporter Exporter) (uint64, error) {
+	if instance, ok := exporter.(Shutdowner); ok {
+		return instance.Shutdown(ctx)
+	}
+	if ctx.Done() == nil {
+		// The context is never done, so there is no need to close the
+		// exporter in an independent coroutine.
+		return 0, exporter.Close()
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- exporter.Close()
+	}()
+	select {
+	case err := <-done:
+		return 0, err
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// ExporterError is the structure of an error returned by an exporter of
+// a logger. It is passed to the error handler of the logger, so that the
+// failing exporter can be identified.
+type ExporterError struct {
+	// Exporter represents the exporter that returned the error.
+	Exporter Exporter
+
+	// Err represents the error returned by the exporter.
+	Err error
+}
+
+// Error returns the error message of the error returned by the exporter.
+func (e *ExporterError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error returned by the exporter.
+func (e *ExporterError) Unwrap() error {
+	return e.Err
+}
+
 // StandardExporter is the structure of the standard exporter instance.
 // 
 // The standard exporter checks whether the level of each log entry is
@@ -76,14 +196,21 @@ type StandardExporter struct {
 	span LevelSpan
 	encoder Encoder
 	syncer Syncer
+	maxEntryBytes int
+	oversizePolicy OversizePolicy
+	closed int32
 }
 
 // Export encodes a given log entry into specific data using a specific
 // encoder, then uses a specific synchronizer to write the encoded log
 // entry data to a specific storage device.
 //
-// Finally, any errors encountered are returned.
+// Finally, any errors encountered are returned. If the exporter has been
+// closed, ErrClosed is returned.
 func (e *StandardExporter) Export(entry *Entry) error {
+	if atomic.LoadInt32(&e.closed) == 1 {
+		return ErrClosed
+	}
 	if !e.span.Contains(entry.Level) {
 		return nil
 	}
@@ -104,27 +231,182 @@ func (e *StandardExporter) Export(entry *Entry) error {
 		pool.Buffer.Exporter.Free(pointer)
 		return nil
 	}
-	_, err = e.syncer.Write(buffer)
+	if e.maxEntryBytes > 0 && len(buffer) > e.maxEntryBytes {
+		buffer, err = e.exportOversized(buffer, entry)
+	} else {
+		_, err = e.syncer.Write(buffer)
+	}
+	// The encoder may have grown the buffer, so the grown buffer is
+	// returned to the pool instead of the original one.
+	*pointer = buffer[ : 0]
 	pool.Buffer.Exporter.Free(pointer)
 	return err
 }
 
+// exportOversized handles the given log entry whose encoded data in the
+// given buffer slice exceeds the maximum number of bytes according to the
+// oversize policy, and then returns the buffer slice that may have been
+// grown and any errors encountered.
+func (e *StandardExporter) exportOversized(buffer []byte, entry *Entry) ([]byte, error) {
+	size := len(buffer)
+	text := messageText(entry.Message)
+	// The encoded size of the log entry without message text is used to
+	// determine how many bytes are available for the message text.
+	instance := *entry
+	instance.Message = withText(entry.Message, "")
+	buffer, err := e.encoder.Encode(buffer[ : 0], &instance)
+	if err != nil {
+		return buffer, err
+	}
+	available := e.maxEntryBytes - len(buffer)
+	switch e.oversizePolicy {
+	case OversizeTruncate:
+		marker := "…(" + strconv.Itoa(len(text)) + " bytes)"
+		end := truncateIndex(text, available - len(marker))
+		if end <= 0 {
+			break
+		}
+		instance.Message = withText(entry.Message, text[ : end] + marker)
+		buffer, err = e.encoder.Encode(buffer[ : 0], &instance)
+		if err != nil {
+			return buffer, err
+		}
+		if len(buffer) > e.maxEntryBytes {
+			break
+		}
+		_, err = e.syncer.Write(buffer)
+		return buffer, err
+	case OversizeSplit:
+		ends := splitIndexes(text, available)
+		if ends == nil {
+			break
+		}
+		start := 0
+		for _, end := range ends {
+			instance.Message = withText(entry.Message, text[start : end])
+			start = end
+			buffer, err = e.encoder.Encode(buffer[ : 0], &instance)
+			if err != nil {
+				return buffer, err
+			}
+			if _, err = e.syncer.Write(buffer); err != nil {
+				return buffer, err
+			}
+		}
+		return buffer, nil
+	}
+	// The synthetic log entry does not contain the labels of the dropped
+	// log entry, because the labels may be the reason for the size.
+	instance.Level = LevelWarning
+	instance.Labels = SerializedLabels { }
+	instance.Message = StringMessage("dropped oversized log entry of " +
+		strconv.Itoa(size) + " bytes (limit " + strconv.Itoa(
+		e.maxEntryBytes) + " bytes)")
+	buffer, err = e.encoder.Encode(buffer[ : 0], &instance)
+	if err != nil {
+		return buffer, err
+	}
+	if len(buffer) > e.maxEntryBytes {
+		return buffer, ErrEntryTooLarge
+	}
+	_, err = e.syncer.Write(buffer)
+	return buffer, err
+}
+
+// withText returns a message with the given text in place of the text of
+// the given message. The fields of structured messages are kept, and the
+// other messages are replaced with string messages.
+func withText(message Message, text string) Message {
+	switch message := message.(type) {
+	case StructMessage:
+		return StructMessage { Text: text, Fields: message.Fields }
+	case *StructMessage:
+		return StructMessage { Text: text, Fields: message.Fields }
+	}
+	return StringMessage(text)
+}
+
+// splitIndexes returns the end indexes of the consecutive parts of the
+// given text, each of which is at most the given limit of bytes and ends
+// at a character boundary. If the text is empty or cannot be split, for
+// example because a character is wider than the limit, it returns nil.
+func splitIndexes(text string, limit int) []int {
+	var ends []int
+	for start := 0; start < len(text); {
+		end := truncateIndex(text[start : ], limit)
+		if end <= 0 {
+			return nil
+		}
+		start += end
+		ends = append(ends, start)
+	}
+	return ends
+}
+
+// messageText returns the text of the given log entry message, which is
+// used to truncate or split the message of oversized log entries. Only the
+// text of structured messages is returned, the fields are kept separately
+// by the withText function.
+func messageText(message Message) string {
+	switch message := message.(type) {
+	case StringMessage:
+		return string(message)
+	case *StringMessage:
+		return string(*message)
+	case TemplateMessage:
+		return message.text()
+	case *TemplateMessage:
+		return message.text()
+	case StructMessage:
+		return message.Text
+	case *StructMessage:
+		return message.Text
+	case fmt.Stringer:
+		return message.String()
+	}
+	return fmt.Sprint(message)
+}
+
+// truncateIndex returns the largest index not greater than the given limit
+// at which the given text can be truncated without splitting a character.
+// If the given limit is not greater than 0, 0 is returned.
+func truncateIndex(text string, limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+	if limit >= len(text) {
+		return len(text)
+	}
+	for limit > 0 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+	return limit
+}
+
 // Sync writes the internal cache data of a specific synchronizer to a
 // specific storage device. If the specific storage device is based on
 // the file system, write the data cached by the file system to the
 // persistent storage device. For details, please refer to the Sync
 // function of the Syncer interface.
 //
-// Finally, any errors encountered are returned.
+// Finally, any errors encountered are returned. If the exporter has been
+// closed, ErrClosed is returned.
 func (e *StandardExporter) Sync() error {
+	if atomic.LoadInt32(&e.closed) == 1 {
+		return ErrClosed
+	}
 	return e.syncer.Sync()
 }
 
 // Close close a specific synchronizer. For details, please participate
 // in the Close function of the Syncer interface.
 //
-// Finally, any errors encountered are returned.
+// Finally, any errors encountered are returned. If the exporter has
+// already been closed, ErrClosed is returned.
 func (e *StandardExporter) Close() error {
+	if !atomic.CompareAndSwapInt32(&e.closed, 0, 1) {
+		return ErrClosed
+	}
 	return e.syncer.Close()
 }
 
@@ -144,6 +426,19 @@ type StandardExporterOption struct {
 	// data to a specific storage device. If not provided, the default
 	// value is the standard synchronizer.
 	Syncer Syncer
+
+	// MaxEntryBytes represents the maximum number of bytes of the encoded
+	// data of each log entry. Some transports (for example, UDP and Kafka)
+	// silently fail on large records, oversized log entries are handled
+	// according to the OversizePolicy option before they are written. If
+	// not provided or the value is 0, the size of log entries is not
+	// limited.
+	MaxEntryBytes int
+
+	// OversizePolicy represents how oversized log entries are handled. For
+	// details, please refer to the comment section of the OversizePolicy
+	// data type. If not provided, the default value is OversizeTruncate.
+	OversizePolicy OversizePolicy
 }
 
 // UseSpan uses the given start and end log levels as the value of the
@@ -173,12 +468,40 @@ func (o *StandardExporterOption) UseSyncer(syncer Syncer) *StandardExporterOptio
 	return o
 }
 
+// UseMaxEntryBytes uses the given maximum number of bytes and oversize
+// policy as the values of the MaxEntryBytes and OversizePolicy options.
+// For details, please refer to the comment section of these options. Then
+// return to the option instance itself.
+func (o *StandardExporterOption) UseMaxEntryBytes(limit int,
+	policy OversizePolicy) *StandardExporterOption {
+	o.MaxEntryBytes = limit
+	o.OversizePolicy = policy
+	return o
+}
+
+// Validate checks whether the values of the options are valid, and returns
+// an error describing the first invalid value.
+func (o *StandardExporterOption) Validate() error {
+	if err := o.Span.Validate(); err != nil {
+		return err
+	}
+	if o.MaxEntryBytes < 0 || o.OversizePolicy > OversizeSplit {
+		return ErrInvalidOversizePolicy
+	}
+	return nil
+}
+
 // Build builds and returns a standard exporter instance.
 func (o *StandardExporterOption) Build() (*StandardExporter, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
 	return &StandardExporter {
 		span: o.Span,
 		encoder: o.Encoder,
 		syncer: o.Syncer,
+		maxEntryBytes: o.MaxEntryBytes,
+		oversizePolicy: o.OversizePolicy,
 	}, nil
 }
 
@@ -23,6 +23,7 @@
 package santa
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -40,6 +41,11 @@ func TestStandardExporterExport(t *testing.T) {
 
 	err = exporter.Close()
 	assert.NoError(t, err, "Unexpected close error")
+
+	assert.Equal(t, ErrClosed, exporter.Export(entry),
+		"Unexpected export error")
+	assert.Equal(t, ErrClosed, exporter.Sync(), "Unexpected sync error")
+	assert.Equal(t, ErrClosed, exporter.Close(), "Unexpected close error")
 }
 
 func TestStandardExporterOption(t *testing.T) {
@@ -78,3 +84,143 @@ func TestStandardExporterOption(t *testing.T) {
 	assert.Equal(t, span, exporter.span,
 		"Unexpected instance error")
 }
+
+type testWriteSyncer struct {
+	writes []string
+}
+
+func (s *testWriteSyncer) Write(buffer []byte) (int, error) {
+	s.writes = append(s.writes, string(buffer))
+	return len(buffer), nil
+}
+
+func (s *testWriteSyncer) Sync() error {
+	return nil
+}
+
+func (s *testWriteSyncer) Close() error {
+	return nil
+}
+
+func TestStandardExporterMaxEntryBytes(t *testing.T) {
+	encoderOption := NewStandardEncoderOption()
+	encoderOption.UseTimeLayout("")
+	encoder, err := encoderOption.Build()
+	assert.NoError(t, err, "Unexpected create error")
+
+	instance := *entry
+	instance.Message = StringMessage("")
+	overhead, _ := encoder.Encode(nil, &instance)
+	limit := len(overhead) + 16
+	instance.Message = StringMessage(strings.Repeat("abcdefgh", 5))
+
+	option := NewStandardExporterOption()
+	option.UseEncoder(encoder)
+
+	_, err = option.UseMaxEntryBytes(-1, OversizeTruncate).Build()
+	assert.Equal(t, ErrInvalidOversizePolicy, err, "Unexpected build error")
+
+	syncer := &testWriteSyncer { }
+	option.UseSyncer(syncer)
+
+	exporter, err := option.UseMaxEntryBytes(limit, OversizeTruncate).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.NoError(t, exporter.Export(&instance), "Unexpected export error")
+	assert.Len(t, syncer.writes, 1, "Unexpected write count")
+	assert.Contains(t, syncer.writes[0], `"abc…(40 bytes)"`,
+		"Unexpected truncated entry")
+	assert.LessOrEqual(t, len(syncer.writes[0]), limit, "Unexpected size")
+
+	syncer.writes = nil
+	exporter, err = option.UseMaxEntryBytes(limit, OversizeSplit).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.NoError(t, exporter.Export(&instance), "Unexpected export error")
+	assert.Len(t, syncer.writes, 3, "Unexpected write count")
+	assert.Contains(t, syncer.writes[2], `"abcdefgh"`,
+		"Unexpected split entry")
+	for _, write := range syncer.writes {
+		assert.LessOrEqual(t, len(write), limit, "Unexpected size")
+	}
+
+	syncer.writes = nil
+	exporter, err = option.UseMaxEntryBytes(limit + 64, OversizeDrop).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	instance.Message = StringMessage(strings.Repeat("abcdefgh", 64))
+	assert.NoError(t, exporter.Export(&instance), "Unexpected export error")
+	assert.Len(t, syncer.writes, 1, "Unexpected write count")
+	assert.Contains(t, syncer.writes[0], "[WARNING]", "Unexpected level")
+	assert.Contains(t, syncer.writes[0], "dropped oversized log entry",
+		"Unexpected synthetic entry")
+
+	syncer.writes = nil
+	exporter, err = option.UseMaxEntryBytes(8, OversizeDrop).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.Equal(t, ErrEntryTooLarge, exporter.Export(&instance),
+		"Unexpected export error")
+	assert.Empty(t, syncer.writes, "Unexpected write count")
+}
+
+func TestStandardExporterOversizeSplitRune(t *testing.T) {
+	encoderOption := NewStandardEncoderOption()
+	encoderOption.UseTimeLayout("")
+	encoder, err := encoderOption.Build()
+	assert.NoError(t, err, "Unexpected create error")
+
+	instance := *entry
+	instance.Message = StringMessage("")
+	overhead, _ := encoder.Encode(nil, &instance)
+
+	// The first part fits, but the following character is wider than the
+	// available bytes, so the log entry is dropped before it is written.
+	syncer := &testWriteSyncer { }
+	exporter, err := NewStandardExporterOption().UseEncoder(encoder).
+		UseSyncer(syncer).UseMaxEntryBytes(len(overhead) + 2,
+		OversizeSplit).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	instance.Message = StringMessage("a😀")
+	assert.Equal(t, ErrEntryTooLarge, exporter.Export(&instance),
+		"Unexpected export error")
+	assert.Empty(t, syncer.writes, "Unexpected write count")
+
+	assert.Equal(t, []int { 1, 5, 9 }, splitIndexes("a😀😀", 4),
+		"Unexpected split indexes")
+	assert.Nil(t, splitIndexes("a😀", 2), "Unexpected split indexes")
+}
+
+func TestStandardExporterOversizeFields(t *testing.T) {
+	encoder, err := NewJSONEncoder()
+	assert.NoError(t, err, "Unexpected create error")
+
+	instance := *entry
+	instance.Message = StructMessage {
+		Fields: ElementObject { String("name", "test") },
+	}
+	overhead, _ := encoder.Encode(nil, &instance)
+	limit := len(overhead) + 24
+	instance.Message = &StructMessage {
+		Text: strings.Repeat("abcdefgh", 5),
+		Fields: ElementObject { String("name", "test") },
+	}
+
+	syncer := &testWriteSyncer { }
+	option := NewStandardExporterOption().UseEncoder(encoder).
+		UseSyncer(syncer)
+	exporter, err := option.UseMaxEntryBytes(limit, OversizeTruncate).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.NoError(t, exporter.Export(&instance), "Unexpected export error")
+	assert.Len(t, syncer.writes, 1, "Unexpected write count")
+	assert.Contains(t, syncer.writes[0], `"name": "test"`,
+		"Unexpected truncated entry")
+	assert.Contains(t, syncer.writes[0], "…(40 bytes)",
+		"Unexpected truncated entry")
+
+	syncer.writes = nil
+	exporter, err = option.UseMaxEntryBytes(limit, OversizeSplit).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.NoError(t, exporter.Export(&instance), "Unexpected export error")
+	assert.Len(t, syncer.writes, 2, "Unexpected write count")
+	for _, write := range syncer.writes {
+		assert.Contains(t, write, `"name": "test"`, "Unexpected split entry")
+		assert.LessOrEqual(t, len(write), limit, "Unexpected size")
+	}
+}
@@ -0,0 +1,258 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidFailover represents that the given failover synchronizer
+	// option is invalid. This is usually because the primary or secondary
+	// synchronizer is not provided, or the failure threshold or probing
+	// interval is not greater than 0.
+	ErrInvalidFailover = errors.New("invalid failover option")
+)
+
+// FailoverSyncer is the structure of the failover synchronizer instance.
+//
+// The failover synchronizer writes data to a primary synchronizer (for
+// example, a network synchronizer). After the primary synchronizer fails
+// a given number of consecutive times, the failover synchronizer switches
+// to a secondary synchronizer (for example, a file synchronizer). While
+// switched, a write is periodically used to probe the primary synchronizer,
+// and the failover synchronizer switches back once the probe succeeds.
+//
+// Failures of the Sync function of the primary synchronizer are counted
+// separately, because cached synchronizers usually only report errors when
+// flushing, so the primary synchronizer also fails over after the given
+// number of consecutive failed synchronizations.
+//
+// The data of each failed write of the primary synchronizer is written to
+// the secondary synchronizer, so that it is not lost. Please note that if
+// the primary synchronizer wrote part of the data before failing, that part
+// may be duplicated.
+//
+// The API provided by the failover synchronizer is thread-safe.
+type FailoverSyncer struct {
+	primary Syncer
+	secondary Syncer
+	threshold int
+	interval time.Duration
+	clock Clock
+
+	mutex sync.Mutex
+	failures int
+	syncFailures int
+	failedOver bool
+	probeTime time.Time
+}
+
+// Write writes the data of a given buffer slice to the primary synchronizer,
+// or to the secondary synchronizer if the failover synchronizer has failed
+// over. For details, please refer to the comment section of the
+// FailoverSyncer structure.
+//
+// Finally, it returns the number of bytes actually written and any
+// errors encountered.
+func (s *FailoverSyncer) Write(buffer []byte) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.failedOver {
+		now := s.clock.Now()
+		if now.Before(s.probeTime) {
+			return s.secondary.Write(buffer)
+		}
+		size, err := s.primary.Write(buffer)
+		if err == nil {
+			s.failures = 0
+			s.syncFailures = 0
+			s.failedOver = false
+			diagnose("failover syncer switched back to the primary syncer")
+			return size, nil
+		}
+		s.probeTime = now.Add(s.interval)
+		return s.secondary.Write(buffer)
+	}
+	size, err := s.primary.Write(buffer)
+	if err == nil {
+		s.failures = 0
+		return size, nil
+	}
+	s.fail(&s.failures, err)
+	return s.secondary.Write(buffer)
+}
+
+// fail counts a failure of the primary synchronizer with the given
+// counter, and switches to the secondary synchronizer once the counter
+// reaches the failure threshold. The caller must hold the mutex.
+func (s *FailoverSyncer) fail(failures *int, err error) {
+	*failures++
+	if *failures >= s.threshold && !s.failedOver {
+		s.failedOver = true
+		s.probeTime = s.clock.Now().Add(s.interval)
+		diagnose("failover syncer switched to the secondary syncer after "+
+			"%d failures: %v", *failures, err)
+	}
+}
+
+// Sync writes the internally cached data of the primary and secondary
+// synchronizers to their specific storage devices. Failures of the primary
+// synchronizer are counted, and if the failover synchronizer has failed
+// over, errors of the primary synchronizer are discarded.
+//
+// Finally, any errors encountered are returned.
+func (s *FailoverSyncer) Sync() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	err := s.primary.Sync()
+	if s.failedOver {
+		err = nil
+	} else if err != nil {
+		s.fail(&s.syncFailures, err)
+	} else {
+		s.syncFailures = 0
+	}
+	if secondaryErr := s.secondary.Sync(); err == nil {
+		err = secondaryErr
+	}
+	return err
+}
+
+// Close closes the primary and secondary synchronizers.
+//
+// Finally, the first error encountered is returned.
+func (s *FailoverSyncer) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	err := s.primary.Close()
+	if secondaryErr := s.secondary.Close(); err == nil {
+		err = secondaryErr
+	}
+	return err
+}
+
+// IsFailedOver checks whether the failover synchronizer has switched to
+// the secondary synchronizer.
+func (s *FailoverSyncer) IsFailedOver() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.failedOver
+}
+
+// FailoverSyncerOption is a structure containing failover synchronizer
+// options.
+type FailoverSyncerOption struct {
+	// Primary represents the synchronizer that data is written to
+	// normally. It must be provided.
+	Primary Syncer
+
+	// Secondary represents the synchronizer that data is written to after
+	// the primary synchronizer fails. It must be provided.
+	Secondary Syncer
+
+	// FailureThreshold represents the number of consecutive failures of
+	// the primary synchronizer after which the failover synchronizer
+	// switches to the secondary synchronizer. If not provided, the default
+	// value is 3.
+	FailureThreshold int
+
+	// ProbeInterval represents the interval at which the primary
+	// synchronizer is probed after the failover synchronizer switches to
+	// the secondary synchronizer. If not provided, the default value is
+	// 10 seconds.
+	ProbeInterval time.Duration
+
+	// Clock represents the clock used to determine when to probe the
+	// primary synchronizer. If not provided, the default value is the
+	// system clock.
+	Clock Clock
+}
+
+// UseSyncers uses the given synchronizers as the values of the options
+// Primary and Secondary. For details, please refer to the comment section
+// of these options. Then return to the option instance itself.
+func (o *FailoverSyncerOption) UseSyncers(primary, secondary Syncer) *FailoverSyncerOption {
+	o.Primary = primary
+	o.Secondary = secondary
+	return o
+}
+
+// UseFailureThreshold uses the given threshold as the value of the option
+// FailureThreshold. For details, please refer to the comment section of
+// the FailureThreshold option. Then return to the option instance itself.
+func (o *FailoverSyncerOption) UseFailureThreshold(threshold int) *FailoverSyncerOption {
+	o.FailureThreshold = threshold
+	return o
+}
+
+// UseProbeInterval uses the given interval as the value of the option
+// ProbeInterval. For details, please refer to the comment section of the
+// ProbeInterval option. Then return to the option instance itself.
+func (o *FailoverSyncerOption) UseProbeInterval(interval time.Duration) *FailoverSyncerOption {
+	o.ProbeInterval = interval
+	return o
+}
+
+// UseClock uses the given clock as the value of the option Clock. For
+// details, please refer to the comment section of the Clock option. Then
+// return to the option instance itself.
+func (o *FailoverSyncerOption) UseClock(clock Clock) *FailoverSyncerOption {
+	o.Clock = clock
+	return o
+}
+
+// Build builds and returns an instance of the failover synchronizer and
+// any errors encountered.
+func (o *FailoverSyncerOption) Build() (*FailoverSyncer, error) {
+	if o.Primary == nil || o.Secondary == nil {
+		return nil, ErrInvalidFailover
+	}
+	if o.FailureThreshold <= 0 || o.ProbeInterval <= 0 {
+		return nil, ErrInvalidFailover
+	}
+	clock := o.Clock
+	if clock == nil {
+		clock = SystemClock { }
+	}
+	return &FailoverSyncer {
+		primary: o.Primary,
+		secondary: o.Secondary,
+		threshold: o.FailureThreshold,
+		interval: o.ProbeInterval,
+		clock: clock,
+	}, nil
+}
+
+// NewFailoverSyncerOption creates and returns a failover synchronizer
+// option instance with default option values. The primary and secondary
+// synchronizers must be provided before building.
+func NewFailoverSyncerOption() *FailoverSyncerOption {
+	return &FailoverSyncerOption {
+		FailureThreshold: 3,
+		ProbeInterval: time.Second * 10,
+		Clock: SystemClock { },
+	}
+}
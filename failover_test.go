@@ -0,0 +1,161 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testFailingSyncer struct {
+	testWriteSyncer
+	err error
+}
+
+func (s *testFailingSyncer) Write(buffer []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	return s.testWriteSyncer.Write(buffer)
+}
+
+type testStepClock struct {
+	now time.Time
+}
+
+func (c *testStepClock) Now() time.Time {
+	return c.now
+}
+
+func TestFailoverSyncerOption(t *testing.T) {
+	option := NewFailoverSyncerOption()
+
+	_, err := option.Build()
+	assert.Equal(t, ErrInvalidFailover, err, "Unexpected build error")
+
+	primary := &testWriteSyncer { }
+	secondary := &testWriteSyncer { }
+	option.UseSyncers(primary, secondary)
+	option.UseFailureThreshold(2)
+	option.UseProbeInterval(time.Minute)
+
+	assert.Equal(t, primary, option.Primary, "Unexpected option value")
+	assert.Equal(t, secondary, option.Secondary, "Unexpected option value")
+	assert.Equal(t, 2, option.FailureThreshold, "Unexpected option value")
+	assert.Equal(t, time.Minute, option.ProbeInterval,
+		"Unexpected option value")
+
+	_, err = option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	option.UseFailureThreshold(0)
+	_, err = option.Build()
+	assert.Equal(t, ErrInvalidFailover, err, "Unexpected build error")
+}
+
+func TestFailoverSyncer(t *testing.T) {
+	SetDiagnosticsWriter(&bytes.Buffer { })
+	defer SetDiagnosticsWriter(os.Stderr)
+
+	clock := &testStepClock { now: time.Unix(0, 0) }
+	primary := &testFailingSyncer { err: errors.New("Error") }
+	secondary := &testWriteSyncer { }
+
+	syncer, err := NewFailoverSyncerOption().
+		UseSyncers(primary, secondary).
+		UseFailureThreshold(2).
+		UseProbeInterval(time.Second).
+		UseClock(clock).Build()
+	assert.NoError(t, err, "Unexpected create error")
+
+	for index := 0; index < 3; index++ {
+		_, err = syncer.Write([]byte("Hello Test!"))
+		assert.NoError(t, err, "Unexpected write error")
+	}
+	assert.True(t, syncer.IsFailedOver(), "Unexpected failover state")
+	assert.Len(t, secondary.writes, 3, "Unexpected secondary writes")
+
+	// The primary syncer has recovered, but is not probed until the
+	// probing interval has elapsed.
+	primary.err = nil
+	_, err = syncer.Write([]byte("Hello Test!"))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.Len(t, secondary.writes, 4, "Unexpected secondary writes")
+	assert.Empty(t, primary.writes, "Unexpected primary writes")
+
+	clock.now = clock.now.Add(time.Second)
+	_, err = syncer.Write([]byte("Hello Test!"))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.False(t, syncer.IsFailedOver(), "Unexpected failover state")
+	assert.Len(t, primary.writes, 1, "Unexpected primary writes")
+
+	assert.NoError(t, syncer.Sync(), "Unexpected sync error")
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+}
+
+// testSyncFailingSyncer is a synchronizer whose writes succeed and whose
+// synchronizations fail with the given error, like a cached synchronizer
+// that fails to flush.
+type testSyncFailingSyncer struct {
+	testWriteSyncer
+	err error
+}
+
+func (s *testSyncFailingSyncer) Sync() error {
+	return s.err
+}
+
+func TestFailoverSyncerSyncFailure(t *testing.T) {
+	SetDiagnosticsWriter(&bytes.Buffer { })
+	defer SetDiagnosticsWriter(os.Stderr)
+
+	primary := &testSyncFailingSyncer { err: errors.New("Error") }
+	secondary := &testWriteSyncer { }
+
+	syncer, err := NewFailoverSyncerOption().
+		UseSyncers(primary, secondary).
+		UseFailureThreshold(2).
+		UseProbeInterval(time.Second).
+		UseClock(&testStepClock { now: time.Unix(0, 0) }).Build()
+	assert.NoError(t, err, "Unexpected create error")
+
+	// The successful writes in between do not reset the failures of the
+	// synchronizations.
+	for index := 0; index < 2; index++ {
+		_, err = syncer.Write([]byte("Hello Test!"))
+		assert.NoError(t, err, "Unexpected write error")
+		assert.Equal(t, primary.err, syncer.Sync(), "Unexpected sync error")
+	}
+	assert.True(t, syncer.IsFailedOver(), "Unexpected failover state")
+
+	_, err = syncer.Write([]byte("Hello Test!"))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.Len(t, primary.writes, 2, "Unexpected primary writes")
+	assert.Len(t, secondary.writes, 1, "Unexpected secondary writes")
+	assert.NoError(t, syncer.Sync(), "Unexpected sync error")
+}
@@ -23,9 +23,16 @@
 package santa
 
 import (
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"math"
+	"reflect"
 	"strconv"
 	"time"
+	"unicode/utf8"
+	"unsafe"
 )
 
 // ElementType represents the native data type of an element. The
@@ -71,10 +78,99 @@ const (
 	TypeBytes
 
 	// TypeValue represents the native data type of the element is
-	// a value type that has implemented the relevant formatter interface.
+	// a value type that has implemented the relevant serializer interface.
 	// For details, please refer to the comment section of the Element
 	// structure.
 	TypeValue
+
+	// TypeObject represents the native data type of the element as
+	// ElementObject. For details, please refer to the comment section
+	// of the Element structure.
+	TypeObject
+
+	// TypeObjects represents the native data type of the element as
+	// ElementObjects. For details, please refer to the comment section
+	// of the Element structure.
+	TypeObjects
+
+	// TypeInts represents the native data type of the element as Int64
+	// slice. For details, please refer to the comment section of the
+	// Element structure.
+	TypeInts
+
+	// TypeUints represents the native data type of the element as Uint64
+	// slice. For details, please refer to the comment section of the
+	// Element structure.
+	TypeUints
+
+	// TypeFloat32s represents the native data type of the element as
+	// Float32 slice. For details, please refer to the comment section
+	// of the Element structure.
+	TypeFloat32s
+
+	// TypeFloat64s represents the native data type of the element as
+	// Float64 slice. For details, please refer to the comment section
+	// of the Element structure.
+	TypeFloat64s
+
+	// TypeBooleans represents the native data type of the element as
+	// Bool slice. For details, please refer to the comment section of
+	// the Element structure.
+	TypeBooleans
+
+	// TypeStrings represents the native data type of the element as
+	// String slice. For details, please refer to the comment section of
+	// the Element structure.
+	TypeStrings
+
+	// TypeTimes represents the native data type of the element as Time
+	// slice. For details, please refer to the comment section of the
+	// Element structure.
+	TypeTimes
+
+	// TypeNil represents the element has no value, and it is serialized
+	// as null. For details, please refer to the comment section of the
+	// Element structure.
+	TypeNil
+
+	// TypeBinary represents the native data type of the element as Byte
+	// slice containing binary data, which is serialized as a base64 or
+	// hexadecimal string. For details, please refer to the comment section
+	// of the Element structure.
+	TypeBinary
+
+	// TypeTime represents the native data type of the element as Time. The
+	// Unix time in nanoseconds is stored in the number container, the
+	// location in the interface container and the time format of the
+	// field (if any) in the string container. For details, please refer
+	// to the comment section of the Element structure.
+	TypeTime
+
+	// TypeNamespace represents the element opens a nested object, into
+	// which the subsequent fields of the same object are grouped. For
+	// details, please refer to the comment section of the Namespace
+	// function.
+	TypeNamespace
+
+	// TypeRaw represents the native data type of the element as Byte slice
+	// containing an encoded JSON value, which is serialized verbatim. For
+	// details, please refer to the comment section of the RawJSON function.
+	TypeRaw
+
+	// TypeDurations represents the native data type of the element as
+	// Duration slice. For details, please refer to the comment section of
+	// the Element structure.
+	TypeDurations
+
+	// TypeErrors represents the native data type of the element as Error
+	// slice. For details, please refer to the comment section of the
+	// Element structure.
+	TypeErrors
+
+	// TypeBytesSlice represents the native data type of the element as
+	// slice of Byte slices. For details, please refer to the comment
+	// section of the Element structure.
+	TypeBytesSlice
 )
 
 // Element is a structure that contains a value of native data type.
@@ -87,14 +183,19 @@ const (
 // member of an array or list can be an element.
 //
 // It is worth noting that any value that has implemented the relevant
-// formatter interface can use element storage. For example, the
-// ElementInts data type of the actual native data type []int64
-// implements the JSONFormatter interface, so when the SerializeJSON
-// function of the element is called, the SerializeJSON function of the
-// value of the ElementInts data type will be automatically called.
+// serializer interface can use element storage. For example, if a value
+// of a custom data type implements the JSONSerializer interface, when
+// the SerializeJSON function of the element is called, the SerializeJSON
+// function of the value will be automatically called.
 //
 // This means that applications can easily extend custom element types,
-// as long as these types implement the relevant formatter interface.
+// as long as these types implement the relevant serializer interface.
+//
+// Elements of slice and object types have their own element types (for
+// example, TypeInts), so they are serialized without calling a
+// serializer interface and without any memory allocation. Please use
+// the relevant functions of the element (for example, Ints) to get the
+// value of the native data type of these elements.
 type Element struct {
 	// Type represents the native data type of an element, and its
 	// optional options are constants starting with Type... If not
@@ -128,11 +229,11 @@ func (e Element) SerializeJSON(buffer []byte) []byte {
 	case TypeUint:
 		return strconv.AppendUint(buffer, uint64(e.Number), 10)
 	case TypeFloat32:
-		return strconv.AppendFloat(buffer, float64(math.Float32frombits(
-			uint32(e.Number))), 'f', -1, 32)
+		return appendFloat(buffer, float64(math.Float32frombits(
+			uint32(e.Number))), 32, FloatNull)
 	case TypeFloat64:
-		return strconv.AppendFloat(buffer, math.Float64frombits(
-			uint64(e.Number)), 'f', -1, 64)
+		return appendFloat(buffer, math.Float64frombits(
+			uint64(e.Number)), 64, FloatNull)
 	case TypeBoolean:
 		if e.Number > 0 {
 			return append(buffer, "true"...)
@@ -143,9 +244,41 @@ func (e Element) SerializeJSON(buffer []byte) []byte {
 		buffer = append(buffer, e.String...)
 		return append(buffer, '"')
 	case TypeBytes:
-		buffer = append(buffer, '"')
-		buffer = append(buffer, e.Interface.([]byte)...)
-		return append(buffer, '"')
+		return appendLimitedBytes(buffer, e.Bytes(), 0)
+	case TypeObject:
+		return e.Object().SerializeJSON(buffer)
+	case TypeObjects:
+		return e.Objects().SerializeJSON(buffer)
+	case TypeInts:
+		return e.Ints().SerializeJSON(buffer)
+	case TypeUints:
+		return e.Uints().SerializeJSON(buffer)
+	case TypeFloat32s:
+		return e.Float32s().SerializeJSON(buffer)
+	case TypeFloat64s:
+		return e.Float64s().SerializeJSON(buffer)
+	case TypeBooleans:
+		return e.Booleans().SerializeJSON(buffer)
+	case TypeStrings:
+		return e.Strings().SerializeJSON(buffer)
+	case TypeTimes:
+		return e.Times().SerializeJSON(buffer)
+	case TypeDurations:
+		return e.Durations().SerializeJSON(buffer)
+	case TypeErrors:
+		return e.Errors().SerializeJSON(buffer)
+	case TypeBytesSlice:
+		return e.BytesSlice().SerializeJSON(buffer)
+	case TypeNil:
+		return append(buffer, "null"...)
+	case TypeBinary:
+		return appendBinary(buffer, e.Bytes(), BinaryBase64, 0)
+	case TypeTime:
+		return appendTime(buffer, e.Time(), TimeFormat(e.String))
+	case TypeNamespace:
+		return append(buffer, "{}"...)
+	case TypeRaw:
+		return append(buffer, e.Bytes()...)
 	default:
 		element, ok := e.Interface.(JSONSerializer)
 		if !ok {
@@ -155,6 +288,413 @@ func (e Element) SerializeJSON(buffer []byte) []byte {
 	}
 }
 
+// SerializeJSONOption serializes the element into a JSON value string
+// using the given serializer options and appends it to the given buffer
+// slice, and then returns the appended buffer slice. The values of string
+// and byte slice elements (including the members of string slices and the
+// fields of objects) are truncated according to the MaxFieldBytes option,
+// the values of binary elements are encoded according to the
+// BinaryEncoding option, and the non-finite values of float elements are
+// serialized according to the FloatPolicy option.
+func (e Element) SerializeJSONOption(buffer []byte, option *SerializerOption) []byte {
+	limit := option.MaxFieldBytes
+	if limit <= 0 && option.plain() {
+		if e.Type != TypeValue {
+			if option.standard {
+				return e.SerializeStandard(buffer)
+			}
+			return e.SerializeJSON(buffer)
+		}
+	}
+	switch e.Type {
+	case TypeTime:
+		format := TimeFormat(e.String)
+		if format == "" {
+			format = option.TimeFormat
+		}
+		return appendTime(buffer, e.Time(), format)
+	case TypeTimes:
+		return e.Times().serializeJSON(buffer, option.TimeFormat)
+	case TypeFloat32:
+		return appendFloat(buffer, float64(math.Float32frombits(
+			uint32(e.Number))), 32, option.FloatPolicy)
+	case TypeFloat64:
+		return appendFloat(buffer, math.Float64frombits(
+			uint64(e.Number)), 64, option.FloatPolicy)
+	case TypeFloat32s:
+		return e.Float32s().serializeJSON(buffer, option.FloatPolicy)
+	case TypeFloat64s:
+		return e.Float64s().serializeJSON(buffer, option.FloatPolicy)
+	case TypeBinary:
+		return appendBinary(buffer, e.Bytes(), option.BinaryEncoding, limit)
+	case TypeRaw:
+		value := e.Bytes()
+		if !option.ValidateRawJSON || json.Valid(value) {
+			return append(buffer, value...)
+		}
+		data, _ := json.Marshal(string(value))
+		return append(buffer, data...)
+	case TypeString:
+		return appendLimitedString(buffer, e.String, limit)
+	case TypeBytes:
+		return appendLimitedBytes(buffer, e.Bytes(), limit)
+	case TypeStrings:
+		values := e.Strings()
+		buffer = append(buffer, '[')
+		tail := len(values) - 1
+		for index := 0; index < len(values); index++ {
+			buffer = appendLimitedString(buffer, values[index], limit)
+			if index < tail {
+				buffer = append(buffer, ", "...)
+			}
+		}
+		return append(buffer, ']')
+	case TypeErrors:
+		return e.Errors().serializeJSON(buffer, limit)
+	case TypeBytesSlice:
+		return e.BytesSlice().serializeJSON(buffer, limit)
+	case TypeObject:
+		return e.Object().SerializeJSONOption(buffer, option)
+	case TypeObjects:
+		return e.Objects().SerializeJSONOption(buffer, option)
+	case TypeValue:
+		if option.standard {
+			switch element := e.Interface.(type) {
+			case StandardOptionSerializer:
+				return element.SerializeStandardOption(buffer, option)
+			case StandardSerializer:
+				return element.SerializeStandard(buffer)
+			}
+		}
+		if element, ok := e.Interface.(JSONOptionSerializer); ok {
+			return element.SerializeJSONOption(buffer, option)
+		}
+	}
+	return e.SerializeJSON(buffer)
+}
+
+// appendLimitedString appends the given string as a JSON string to the
+// given buffer slice, and then returns the appended buffer slice. If the
+// given limit is greater than 0 and the string is longer than it, the
+// string is truncated at a character boundary and marked with an ellipsis
+// followed by its original length.
+func appendLimitedString(buffer []byte, value string, limit int) []byte {
+	buffer = append(buffer, '"')
+	if limit <= 0 || len(value) <= limit {
+		buffer = append(buffer, value...)
+		return append(buffer, '"')
+	}
+	end := limit
+	for end > 0 && !utf8.RuneStart(value[end]) {
+		end--
+	}
+	buffer = append(buffer, value[ : end]...)
+	buffer = append(buffer, "…("...)
+	buffer = strconv.AppendInt(buffer, int64(len(value)), 10)
+	return append(buffer, ` bytes)"`...)
+}
+
+// appendLimitedBytes appends the given UTF-8 text as a JSON string to the
+// given buffer slice like the appendLimitedString function, and then
+// returns the appended buffer slice. Invalid UTF-8 sequences are replaced
+// with the escaped replacement character, so that the JSON string is
+// always valid UTF-8.
+func appendLimitedBytes(buffer []byte, value []byte, limit int) []byte {
+	text := *(*string)(unsafe.Pointer(&value))
+	if utf8.Valid(value) {
+		return appendLimitedString(buffer, text, limit)
+	}
+	end := len(text)
+	if limit > 0 && end > limit {
+		end = limit
+		for end > 0 && !utf8.RuneStart(text[end]) {
+			end--
+		}
+	}
+	buffer = append(buffer, '"')
+	for offset := 0; offset < end; {
+		character, size := utf8.DecodeRuneInString(text[offset : end])
+		if character == utf8.RuneError && size == 1 {
+			buffer = append(buffer, `\ufffd`...)
+		} else {
+			buffer = append(buffer, text[offset : offset + size]...)
+		}
+		offset += size
+	}
+	if end < len(text) {
+		buffer = append(buffer, "…("...)
+		buffer = strconv.AppendInt(buffer, int64(len(text)), 10)
+		buffer = append(buffer, " bytes)"...)
+	}
+	return append(buffer, '"')
+}
+
+// FloatPolicy represents how the non-finite values (NaN and ±Inf) of float
+// fields are serialized, because JSON has no tokens for these values. Its
+// options are defined by the constants beginning with Float...
+type FloatPolicy uint8
+
+const (
+	// FloatNull represents non-finite float values are serialized as null.
+	FloatNull FloatPolicy = iota
+
+	// FloatString represents non-finite float values are serialized as the
+	// strings "NaN", "+Inf" and "-Inf".
+	FloatString
+
+	// FloatOmit represents fields whose value is a non-finite float value
+	// are omitted. Non-finite members of float slices are serialized as
+	// null, because omitting them would change the positions of the other
+	// members.
+	FloatOmit
+)
+
+// appendFloat appends the given float value with the given bit size as a
+// JSON value to the given buffer slice, and then returns the appended
+// buffer slice. Non-finite values are serialized according to the given
+// policy.
+func appendFloat(buffer []byte, value float64, bits int,
+	policy FloatPolicy) []byte {
+	if !math.IsNaN(value) && !math.IsInf(value, 0) {
+		return strconv.AppendFloat(buffer, value, 'f', -1, bits)
+	}
+	if policy != FloatString {
+		return append(buffer, "null"...)
+	}
+	switch {
+	case math.IsNaN(value):
+		return append(buffer, `"NaN"`...)
+	case value > 0:
+		return append(buffer, `"+Inf"`...)
+	default:
+		return append(buffer, `"-Inf"`...)
+	}
+}
+
+// omitted returns whether the field of the element is omitted according
+// to the given serializer options, which is the case for non-finite float
+// values with the FloatOmit policy.
+func (e Element) omitted(option *SerializerOption) bool {
+	if option.FloatPolicy != FloatOmit {
+		return false
+	}
+	var value float64
+	switch e.Type {
+	case TypeFloat32:
+		value = float64(math.Float32frombits(uint32(e.Number)))
+	case TypeFloat64:
+		value = math.Float64frombits(uint64(e.Number))
+	default:
+		return false
+	}
+	return math.IsNaN(value) || math.IsInf(value, 0)
+}
+
+// DuplicatePolicy represents how fields with duplicate names within the
+// same object are handled, because some strict JSON consumers reject
+// duplicate members. Its options are defined by the constants beginning
+// with Duplicate...
+type DuplicatePolicy uint8
+
+const (
+	// DuplicateAllow represents fields with duplicate names are serialized
+	// as they are.
+	DuplicateAllow DuplicatePolicy = iota
+
+	// DuplicateLastWins represents only the last field of the fields with
+	// the same name is serialized.
+	DuplicateLastWins
+
+	// DuplicateError represents the encoder returns ErrDuplicateField when
+	// a structured message contains fields with duplicate names.
+	DuplicateError
+)
+
+// BinaryEncoding represents the encoding of the values of binary fields
+// (see the Binary function), and its options are defined by the constants
+// beginning with Binary...
+type BinaryEncoding uint8
+
+const (
+	// BinaryBase64 represents binary values are encoded as base64 strings
+	// using the standard encoding with padding (RFC 4648).
+	BinaryBase64 BinaryEncoding = iota
+
+	// BinaryHex represents binary values are encoded as lowercase
+	// hexadecimal strings.
+	BinaryHex
+)
+
+// appendBinary encodes the given binary value using the given encoding and
+// appends it as a JSON string to the given buffer slice, and then returns
+// the appended buffer slice. If the given limit is greater than 0 and the
+// value is longer than it, only the first limit bytes are encoded and the
+// string is marked with an ellipsis followed by the original length.
+func appendBinary(buffer []byte, value []byte, encoding BinaryEncoding,
+	limit int) []byte {
+	length := len(value)
+	if limit > 0 && length > limit {
+		value = value[ : limit]
+	}
+	buffer = append(buffer, '"')
+	offset := len(buffer)
+	if encoding == BinaryHex {
+		buffer = append(buffer, make([]byte, hex.EncodedLen(len(value)))...)
+		hex.Encode(buffer[offset : ], value)
+	} else {
+		buffer = append(buffer, make([]byte, base64.StdEncoding.EncodedLen(
+			len(value)))...)
+		base64.StdEncoding.Encode(buffer[offset : ], value)
+	}
+	if len(value) < length {
+		buffer = append(buffer, "…("...)
+		buffer = strconv.AppendInt(buffer, int64(length), 10)
+		buffer = append(buffer, " bytes)"...)
+	}
+	return append(buffer, '"')
+}
+
+// SerializeStandard serializes the element into a standard log string
+// and appends it to the given buffer slice, and then returns the appended
+// buffer slice. Elements are serialized in the same way as JSON values,
+// unless the value of a TypeValue element, including the values nested in
+// objects, implements the StandardSerializer interface.
+func (e Element) SerializeStandard(buffer []byte) []byte {
+	switch e.Type {
+	case TypeValue:
+		if element, ok := e.Interface.(StandardSerializer); ok {
+			return element.SerializeStandard(buffer)
+		}
+	case TypeObject:
+		return e.Object().SerializeStandard(buffer)
+	case TypeObjects:
+		return e.Objects().SerializeStandard(buffer)
+	}
+	return e.SerializeJSON(buffer)
+}
+
+// FormatJSON is an alias of the SerializeJSON function.
+//
+// Deprecated: Use SerializeJSON instead.
+func (e Element) FormatJSON(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// FormatStandard is an alias of the SerializeStandard function.
+//
+// Deprecated: Use SerializeStandard instead.
+func (e Element) FormatStandard(buffer []byte) []byte {
+	return e.SerializeStandard(buffer)
+}
+
+// Bytes restores and returns the []byte value of the element, including
+// the value of binary and raw JSON elements. If the native data type of
+// the element is not Byte slice, returns nil.
+func (e Element) Bytes() []byte {
+	values, _ := e.Interface.([]byte)
+	return values
+}
+
+// Object restores and returns the ElementObject value of the element.
+// If the native data type of the element is not ElementObject, returns
+// nil.
+func (e Element) Object() ElementObject {
+	values, _ := e.Interface.(ElementObject)
+	return values
+}
+
+// Objects restores and returns the ElementObjects value of the element.
+// If the native data type of the element is not ElementObjects, returns
+// nil.
+func (e Element) Objects() ElementObjects {
+	values, _ := e.Interface.(ElementObjects)
+	return values
+}
+
+// Ints restores and returns the []int64 value of the element. If the
+// native data type of the element is not Int64 slice, returns nil.
+func (e Element) Ints() ElementInts {
+	values, _ := e.Interface.(ElementInts)
+	return values
+}
+
+// Uints restores and returns the []uint64 value of the element. If the
+// native data type of the element is not Uint64 slice, returns nil.
+func (e Element) Uints() ElementUint64s {
+	values, _ := e.Interface.(ElementUint64s)
+	return values
+}
+
+// Float32s restores and returns the []float32 value of the element. If
+// the native data type of the element is not Float32 slice, returns nil.
+func (e Element) Float32s() ElementFloat32s {
+	values, _ := e.Interface.(ElementFloat32s)
+	return values
+}
+
+// Float64s restores and returns the []float64 value of the element. If
+// the native data type of the element is not Float64 slice, returns nil.
+func (e Element) Float64s() ElementFloat64s {
+	values, _ := e.Interface.(ElementFloat64s)
+	return values
+}
+
+// Booleans restores and returns the []bool value of the element. If the
+// native data type of the element is not Bool slice, returns nil.
+func (e Element) Booleans() ElementBooleans {
+	values, _ := e.Interface.(ElementBooleans)
+	return values
+}
+
+// Strings restores and returns the []string value of the element. If
+// the native data type of the element is not String slice, returns nil.
+func (e Element) Strings() ElementStrings {
+	values, _ := e.Interface.(ElementStrings)
+	return values
+}
+
+// Time restores and returns the time.Time value of the element. If the
+// native data type of the element is not Time, returns the zero time.
+func (e Element) Time() time.Time {
+	if e.Type != TypeTime {
+		return time.Time { }
+	}
+	value := time.Unix(0, e.Number)
+	if location, ok := e.Interface.(*time.Location); ok {
+		return value.In(location)
+	}
+	return value
+}
+
+// Times restores and returns the []time.Time value of the element. If
+// the native data type of the element is not Time slice, returns nil.
+func (e Element) Times() ElementTimes {
+	values, _ := e.Interface.(ElementTimes)
+	return values
+}
+
+// Durations restores and returns the []time.Duration value of the element.
+// If the native data type of the element is not Duration slice, returns nil.
+func (e Element) Durations() ElementDurations {
+	values, _ := e.Interface.(ElementDurations)
+	return values
+}
+
+// Errors restores and returns the []error value of the element. If the
+// native data type of the element is not Error slice, returns nil.
+func (e Element) Errors() ElementErrors {
+	values, _ := e.Interface.(ElementErrors)
+	return values
+}
+
+// BytesSlice restores and returns the [][]byte value of the element. If
+// the native data type of the element is not slice of Byte slices,
+// returns nil.
+func (e Element) BytesSlice() ElementBytesSlice {
+	values, _ := e.Interface.(ElementBytesSlice)
+	return values
+}
+
 // Field is a structure that contains the name and value of a field.
 //
 // Fields use elements to store the value of a field's native data type.
@@ -253,14 +793,82 @@ func String(name string, value string) Field {
 	}
 }
 
+// TimeFormat represents the format of the values of time fields, and its
+// options are defined by the constants beginning with Time...
+type TimeFormat string
+
+const (
+	// TimeUnixNano represents time values are encoded as the number of
+	// nanoseconds elapsed since the Unix epoch.
+	TimeUnixNano TimeFormat = "unixNano"
+
+	// TimeUnixMilli represents time values are encoded as the number of
+	// milliseconds elapsed since the Unix epoch.
+	TimeUnixMilli TimeFormat = "unixMilli"
+
+	// TimeUnix represents time values are encoded as the number of seconds
+	// elapsed since the Unix epoch.
+	TimeUnix TimeFormat = "unix"
+
+	// TimeRFC3339Nano represents time values are encoded as strings using
+	// the time.RFC3339Nano layout in the location of the time.
+	TimeRFC3339Nano TimeFormat = "rfc3339Nano"
+)
+
+// appendTime appends the given time value as a JSON value in the given
+// format to the given buffer slice, and then returns the appended buffer
+// slice. If the format is empty or unknown, TimeUnixNano is used.
+func appendTime(buffer []byte, value time.Time, format TimeFormat) []byte {
+	switch format {
+	case TimeUnixMilli:
+		return strconv.AppendInt(buffer, value.Unix() * 1e3 +
+			int64(value.Nanosecond()) / 1e6, 10)
+	case TimeUnix:
+		return strconv.AppendInt(buffer, value.Unix(), 10)
+	case TimeRFC3339Nano:
+		buffer = append(buffer, '"')
+		buffer = value.AppendFormat(buffer, time.RFC3339Nano)
+		return append(buffer, '"')
+	default:
+		return strconv.AppendInt(buffer, value.UnixNano(), 10)
+	}
+}
+
+// RawJSON returns the value of a field with a given name and a given
+// []byte value containing an encoded JSON value, which is serialized
+// verbatim without copying the value, so that payloads can be passed
+// through without decoding and encoding them again. The value is not
+// validated unless the ValidateRawJSON encoder option is enabled. For
+// details, see the comments section of the Field structure. If the given
+// slice is nil or empty, the value of the field is null.
+func RawJSON(name string, value []byte) Field {
+	if len(value) == 0 {
+		return Nil(name)
+	}
+	field := ByteString(name, value)
+	field.Type = TypeRaw
+	return field
+}
+
 // Time returns the value of a field with a given name and a given
-// time value. For details, see the comments section of the Field
+// time value, which is encoded in the format given by the TimeFormat
+// encoder option. For details, see the comments section of the Field
 // structure.
 func Time(name string, value time.Time) Field {
+	return TimeAs(name, value, "")
+}
+
+// TimeAs returns the value of a field with a given name, a given time
+// value and a given time format, which overrides the TimeFormat encoder
+// option for the field. For details, see the comments section of the
+// Field structure.
+func TimeAs(name string, value time.Time, format TimeFormat) Field {
 	return Field {
 		Element: Element {
-			Type: TypeInt,
+			Type: TypeTime,
 			Number: value.UnixNano(),
+			String: string(format),
+			Interface: value.Location(),
 		},
 		Name: name,
 	}
@@ -268,8 +876,11 @@ func Time(name string, value time.Time) Field {
 
 // Error returns the value of a field with a given name and a given
 // error value. For details, see the comments section of the Field
-// structure.
+// structure. If the given error is nil, the value of the field is null.
 func Error(name string, value error) Field {
+	if isNil(value) {
+		return Nil(name)
+	}
 	return Field {
 		Element: Element {
 			Type: TypeString,
@@ -280,9 +891,24 @@ func Error(name string, value error) Field {
 }
 
 // Bytes returns the value of a field with a given name and a given
-// []byte value. For details, see the comments section of the Field
-// structure.
+// []byte value, which is equivalent to the Binary function, so the value
+// is serialized as a base64 string by default. For UTF-8 text, please use
+// the ByteString function instead. For details, see the comments section
+// of the Binary function.
 func Bytes(name string, value []byte) Field {
+	return Binary(name, value)
+}
+
+// ByteString returns the value of a field with a given name and a given
+// []byte value containing UTF-8 text, which is serialized as a string
+// without copying the value. Invalid UTF-8 sequences are serialized as
+// the replacement character. For binary data, please use the Binary
+// function instead. For details, see the comments section of the Field
+// structure. If the given slice is nil, the value of the field is null.
+func ByteString(name string, value []byte) Field {
+	if value == nil {
+		return Nil(name)
+	}
 	return Field {
 		Element: Element {
 			Type: TypeBytes,
@@ -292,10 +918,28 @@ func Bytes(name string, value []byte) Field {
 	}
 }
 
+// Binary returns the value of a field with a given name and a given
+// []byte value containing binary data, which is serialized as a base64
+// string by default, or as a hexadecimal string according to the
+// BinaryEncoding encoder option. For details, see the comments section of
+// the Field structure. If the given slice is nil, the value of the field
+// is null.
+func Binary(name string, value []byte) Field {
+	field := ByteString(name, value)
+	if field.Type == TypeBytes {
+		field.Type = TypeBinary
+	}
+	return field
+}
+
 // Value returns the value of a field with a given name and a given
-// value. The given value must have implemented the relevant formatter
-// interface. Please refer to the comments section of the Field
-// structure for details.
+// value. The given value must have implemented the relevant serializer
+// interface. If the given value is nil (including nil pointers, maps and
+// slices), the value of the field is null. Values that only implement
+// the json.Marshaler or the encoding.TextMarshaler interface are
+// serialized through those interfaces, in the same way as the Reflect
+// function. Please refer to the comments section of the Field structure
+// for details.
 func Value(name string, value interface { }) Field {
 	switch v := value.(type) {
 	case int:
@@ -330,6 +974,23 @@ func Value(name string, value interface { }) Field {
 		return Error(name, v)
 	case []byte:
 		return Bytes(name, v)
+	case time.Duration:
+		return Int(name, int64(v))
+	case []time.Duration:
+		return Durations(name, v)
+	case []error:
+		return Errors(name, v)
+	case [][]byte:
+		return BytesSlice(name, v)
+	}
+	if isNil(value) {
+		return Nil(name)
+	}
+	if _, ok := value.(JSONSerializer); !ok {
+		switch value.(type) {
+		case json.Marshaler, encoding.TextMarshaler:
+			return Reflect(name, value)
+		}
 	}
 
 	return Field {
@@ -341,6 +1002,82 @@ func Value(name string, value interface { }) Field {
 	}
 }
 
+// BadKeyName is the name of the fields created by the KeysAndValues function
+// for values without a valid key.
+const BadKeyName = "!BADKEY"
+
+// KeysAndValues returns the fields built from the given alternating keys
+// and values, such as "name", "test", "age", 100. Each key must be a string,
+// and each value is converted in the same way as the Value function, except
+// that values that do not implement any serializer interface are converted
+// in the same way as the Reflect function. A Field given in place of a key
+// is used as is.
+//
+// If a key is not a string, it is treated as a value whose field is named
+// BadKeyName. If the last key has no value, the key is treated as the value
+// of a field named BadKeyName. Such mistakes are also reported to the
+// diagnostics channel.
+func KeysAndValues(keysAndValues ...interface { }) []Field {
+	fields := make([]Field, 0, (len(keysAndValues) + 1) / 2)
+	for index := 0; index < len(keysAndValues); index++ {
+		switch key := keysAndValues[index].(type) {
+		case Field:
+			fields = append(fields, key)
+		case string:
+			if index == len(keysAndValues) - 1 {
+				diagnose("fields: key %q has no value", key)
+				fields = append(fields, String(BadKeyName, key))
+				break
+			}
+			index++
+			fields = append(fields, keyValue(key, keysAndValues[index]))
+		default:
+			diagnose("fields: non-string key %v of type %T", key, key)
+			fields = append(fields, keyValue(BadKeyName, key))
+		}
+	}
+	return fields
+}
+
+// keyValue returns the value of a field with a given name and a given value
+// of any type. For details, please refer to the comment section of the
+// KeysAndValues function.
+func keyValue(name string, value interface { }) Field {
+	field := Value(name, value)
+	if field.Type != TypeValue {
+		return field
+	}
+	if _, ok := field.Interface.(JSONSerializer); !ok {
+		return Reflect(name, value)
+	}
+	return field
+}
+
+// Nil returns the value of a field with a given name and a null value.
+// For details, see the comments section of the Field structure.
+func Nil(name string) Field {
+	return Field {
+		Element: Element {
+			Type: TypeNil,
+		},
+		Name: name,
+	}
+}
+
+// isNil returns whether the given value is nil, or a nil pointer, map,
+// slice, function, channel or interface.
+func isNil(value interface { }) bool {
+	if value == nil {
+		return true
+	}
+	switch reflected := reflect.ValueOf(value); reflected.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func,
+		reflect.Chan, reflect.Interface:
+		return reflected.IsNil()
+	}
+	return false
+}
+
 // ElementObject represents an element data type whose native data type
 // is []Fields. For details, please refer to the comment section of the
 // Element structure.
@@ -350,27 +1087,281 @@ type ElementObject []Field
 // it to the given buffer slice, and then returns the appended buffer
 // slice.
 func (e ElementObject) SerializeJSON(buffer []byte) []byte {
+	return e.serialize(buffer, false)
+}
+
+// serialize serializes the fields into a JSON string and appends it to the
+// given buffer slice, and then returns the appended buffer slice. If the
+// given standard flag is true, the fields are serialized by their
+// SerializeStandard function.
+func (e ElementObject) serialize(buffer []byte, standard bool) []byte {
 	buffer = append(buffer, '{')
-	tail := len(e) - 1
+	depth := 0
 	for index := 0; index < len(e); index++ {
+		if index > 0 && e[index - 1].Type != TypeNamespace {
+			buffer = append(buffer, ", "...)
+		}
 		buffer = append(buffer, '"')
 		buffer = append(buffer, e[index].Name...)
 		buffer = append(buffer, "\": "...)
-		buffer = e[index].SerializeJSON(buffer)
-		if index < tail {
+		if e[index].Type == TypeNamespace {
+			buffer = append(buffer, '{')
+			depth++
+			continue
+		}
+		if standard {
+			buffer = e[index].SerializeStandard(buffer)
+		} else {
+			buffer = e[index].SerializeJSON(buffer)
+		}
+	}
+	return appendClosingBraces(buffer, depth + 1)
+}
+
+// serializeOrdered serializes the fields into a JSON string according to
+// the SortFields and DuplicateFields options, and appends it to the given
+// buffer slice, and then returns the appended buffer slice. A namespace is
+// serialized as the last member of the object, whose value is the object
+// of the subsequent fields.
+func (e ElementObject) serializeOrdered(buffer []byte, option *SerializerOption) []byte {
+	end := len(e)
+	for index := 0; index < len(e); index++ {
+		if e[index].Type == TypeNamespace {
+			end = index + 1
+			break
+		}
+	}
+
+	var scratch [32]int
+	order := scratch[ : 0]
+	for index := 0; index < end; index++ {
+		if e[index].omitted(option) {
+			continue
+		}
+		if option.DuplicateFields == DuplicateLastWins &&
+			e[ : end].redefined(index, option) {
+			continue
+		}
+		order = append(order, index)
+	}
+	if option.SortFields {
+		// The number of fields is usually small, so a stable insertion
+		// sort is used to avoid any memory allocation.
+		for index := 1; index < len(order); index++ {
+			for position := index; position > 0 && e[order[position]].Name <
+				e[order[position - 1]].Name; position-- {
+				order[position], order[position - 1] = order[position - 1],
+					order[position]
+			}
+		}
+	}
+
+	buffer = append(buffer, '{')
+	for index := 0; index < len(order); index++ {
+		if index > 0 {
 			buffer = append(buffer, ", "...)
 		}
+		field := &e[order[index]]
+		buffer = append(buffer, '"')
+		buffer = append(buffer, field.Name...)
+		buffer = append(buffer, "\": "...)
+		if field.Type == TypeNamespace {
+			buffer = e[order[index] + 1 : ].serializeOrdered(buffer, option)
+			continue
+		}
+		buffer = field.SerializeJSONOption(buffer, option)
 	}
 	return append(buffer, '}')
 }
 
+// redefined returns whether the field at the given index is followed by a
+// field with the same name that is not omitted.
+func (e ElementObject) redefined(index int, option *SerializerOption) bool {
+	for position := index + 1; position < len(e); position++ {
+		if e[position].Name == e[index].Name && !e[position].omitted(option) {
+			return true
+		}
+	}
+	return false
+}
+
+// duplicated returns whether the fields, the fields of any namespace or
+// the fields of any nested object contain fields with duplicate names.
+func (e ElementObject) duplicated() bool {
+	for index := 0; index < len(e); index++ {
+		for position := index + 1; position < len(e); position++ {
+			if e[position].Name == e[index].Name {
+				return true
+			}
+			if e[position].Type == TypeNamespace {
+				break
+			}
+		}
+		switch e[index].Type {
+		case TypeNamespace:
+			return e[index + 1 : ].duplicated()
+		case TypeObject:
+			if e[index].Object().duplicated() {
+				return true
+			}
+		case TypeObjects:
+			objects := e[index].Objects()
+			for position := 0; position < len(objects); position++ {
+				if objects[position].duplicated() {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// appendClosingBraces appends the given number of closing braces to the
+// given buffer slice, and then returns the appended buffer slice.
+func appendClosingBraces(buffer []byte, count int) []byte {
+	for ; count > 0; count-- {
+		buffer = append(buffer, '}')
+	}
+	return buffer
+}
+
+// SerializeStandard serializes the element into a standard log string
+// and appends it to the given buffer slice, and then returns the appended
+// buffer slice. The fields are serialized by their SerializeStandard
+// function.
+func (e ElementObject) SerializeStandard(buffer []byte) []byte {
+	return e.serialize(buffer, true)
+}
+
+// SerializeJSONOption serializes the element into a JSON string using the
+// given serializer options and appends it to the given buffer slice, and
+// then returns the appended buffer slice. For details, please refer to the
+// comment section of the SerializeJSONOption function of the Element
+// structure.
+func (e ElementObject) SerializeJSONOption(buffer []byte, option *SerializerOption) []byte {
+	if option.SortFields || option.DuplicateFields == DuplicateLastWins {
+		return e.serializeOrdered(buffer, option)
+	}
+	buffer = append(buffer, '{')
+	separator := false
+	depth := 0
+	for index := 0; index < len(e); index++ {
+		if e[index].omitted(option) {
+			continue
+		}
+		if separator {
+			buffer = append(buffer, ", "...)
+		}
+		separator = true
+		buffer = append(buffer, '"')
+		buffer = append(buffer, e[index].Name...)
+		buffer = append(buffer, "\": "...)
+		if e[index].Type == TypeNamespace {
+			buffer = append(buffer, '{')
+			separator = false
+			depth++
+			continue
+		}
+		buffer = e[index].SerializeJSONOption(buffer, option)
+	}
+	return appendClosingBraces(buffer, depth + 1)
+}
+
+// SerializeStandardOption serializes the element into a standard log
+// string using the given serializer options and appends it to the given
+// buffer slice, and then returns the appended buffer slice. The values of
+// the fields implementing the StandardSerializer interface are serialized
+// by it.
+func (e ElementObject) SerializeStandardOption(buffer []byte, option *SerializerOption) []byte {
+	if !option.standard {
+		standard := *option
+		standard.standard = true
+		option = &standard
+	}
+	return e.SerializeJSONOption(buffer, option)
+}
+
+// FormatJSON is an alias of the SerializeJSON function.
+//
+// Deprecated: Use SerializeJSON instead.
+func (e ElementObject) FormatJSON(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// FormatStandard is an alias of the SerializeStandard function.
+//
+// Deprecated: Use SerializeStandard instead.
+func (e ElementObject) FormatStandard(buffer []byte) []byte {
+	return e.SerializeStandard(buffer)
+}
+
+// Clone creates and returns a deep copy of the fields. The values of the
+// fields of byte slice and object type are copied recursively, and the
+// values of other element types that reference data are shared.
+func (e ElementObject) Clone() ElementObject {
+	if e == nil {
+		return nil
+	}
+	fields := make(ElementObject, len(e))
+	copy(fields, e)
+	for index := 0; index < len(fields); index++ {
+		element := &fields[index].Element
+		switch element.Type {
+		case TypeBytes, TypeBinary, TypeRaw:
+			if value := element.Bytes(); len(value) > 0 {
+				element.Interface = append([]byte(nil), value...)
+			}
+		case TypeBytesSlice:
+			if value := element.BytesSlice(); len(value) > 0 {
+				values := make([][]byte, len(value))
+				for position := 0; position < len(value); position++ {
+					if value[position] != nil {
+						values[position] = append([]byte { },
+							value[position]...)
+					}
+				}
+				element.Interface = ElementBytesSlice(values)
+			}
+		case TypeObject:
+			if value := element.Object(); len(value) > 0 {
+				element.Interface = value.Clone()
+			}
+		case TypeObjects:
+			if value := element.Objects(); len(value) > 0 {
+				objects := make(ElementObjects, len(value))
+				for position := 0; position < len(value); position++ {
+					objects[position] = value[position].Clone()
+				}
+				element.Interface = objects
+			}
+		}
+	}
+	return fields
+}
+
+// Namespace returns a field with a given name that opens a nested object,
+// into which the subsequent fields of the same object (for example, the
+// fields of a structured message) are grouped, instead of building an
+// Object field manually. Namespaces can be nested, and all namespaces are
+// closed at the end of the object. For example, the fields String("id",
+// "1"), Namespace("user"), String("name", "test") are serialized as
+// {"id": "1", "user": {"name": "test"}}.
+func Namespace(name string) Field {
+	return Field {
+		Element: Element {
+			Type: TypeNamespace,
+		},
+		Name: name,
+	}
+}
+
 // Object returns the value of a field with a given name and a given
 // []Field value. For details, see the comments section of the Field
 // structure.
 func Object(name string, fields ...Field) Field {
 	return Field {
 		Element: Element {
-			Type: TypeValue,
+			Type: TypeObject,
 			Interface: ElementObject(fields),
 		},
 		Name: name,
@@ -397,13 +1388,57 @@ func (e ElementObjects) SerializeJSON(buffer []byte) []byte {
 	return append(buffer, ']')
 }
 
+// SerializeStandard serializes the element into a standard log string
+// and appends it to the given buffer slice, and then returns the appended
+// buffer slice.
+func (e ElementObjects) SerializeStandard(buffer []byte) []byte {
+	buffer = append(buffer, '[')
+	tail := len(e) - 1
+	for index := 0; index < len(e); index++ {
+		buffer = e[index].SerializeStandard(buffer)
+		if index < tail {
+			buffer = append(buffer, ", "...)
+		}
+	}
+	return append(buffer, ']')
+}
+
+// FormatJSON is an alias of the SerializeJSON function.
+//
+// Deprecated: Use SerializeJSON instead.
+func (e ElementObjects) FormatJSON(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// FormatStandard is an alias of the SerializeStandard function.
+//
+// Deprecated: Use SerializeStandard instead.
+func (e ElementObjects) FormatStandard(buffer []byte) []byte {
+	return e.SerializeStandard(buffer)
+}
+
+// SerializeJSONOption serializes the element into a JSON string using the
+// given serializer options and appends it to the given buffer slice, and
+// then returns the appended buffer slice.
+func (e ElementObjects) SerializeJSONOption(buffer []byte, option *SerializerOption) []byte {
+	buffer = append(buffer, '[')
+	tail := len(e) - 1
+	for index := 0; index < len(e); index++ {
+		buffer = e[index].SerializeJSONOption(buffer, option)
+		if index < tail {
+			buffer = append(buffer, ", "...)
+		}
+	}
+	return append(buffer, ']')
+}
+
 // Objects returns the value of a field with a given name and a given
 // []ElementObject value. For details, see the comments section of the
 // Field structure.
 func Objects(name string, values ...ElementObject) Field {
 	return Field {
 		Element: Element {
-			Type: TypeValue,
+			Type: TypeObjects,
 			Interface: ElementObjects(values),
 		},
 		Name: name,
@@ -430,13 +1465,37 @@ func (e ElementInts) SerializeJSON(buffer []byte) []byte {
 	return append(buffer, ']')
 }
 
+// SerializeStandard serializes the element into a standard log string
+// and appends it to the given buffer slice, and then returns the appended
+// buffer slice.
+func (e ElementInts) SerializeStandard(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// FormatJSON is an alias of the SerializeJSON function.
+//
+// Deprecated: Use SerializeJSON instead.
+func (e ElementInts) FormatJSON(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// FormatStandard is an alias of the SerializeStandard function.
+//
+// Deprecated: Use SerializeStandard instead.
+func (e ElementInts) FormatStandard(buffer []byte) []byte {
+	return e.SerializeStandard(buffer)
+}
+
 // Ints returns the value of a field with a given name and a given
 // []int64 value. For details, see the comments section of the Field
-// structure.
+// structure. If the given slice is nil, the value of the field is null.
 func Ints(name string, values []int64) Field {
+	if values == nil {
+		return Nil(name)
+	}
 	return Field {
 		Element: Element {
-			Type: TypeValue,
+			Type: TypeInts,
 			Interface: ElementInts(values),
 		},
 		Name: name,
@@ -463,13 +1522,37 @@ func (e ElementUint64s) SerializeJSON(buffer []byte) []byte {
 	return append(buffer, ']')
 }
 
+// SerializeStandard serializes the element into a standard log string
+// and appends it to the given buffer slice, and then returns the appended
+// buffer slice.
+func (e ElementUint64s) SerializeStandard(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// FormatJSON is an alias of the SerializeJSON function.
+//
+// Deprecated: Use SerializeJSON instead.
+func (e ElementUint64s) FormatJSON(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// FormatStandard is an alias of the SerializeStandard function.
+//
+// Deprecated: Use SerializeStandard instead.
+func (e ElementUint64s) FormatStandard(buffer []byte) []byte {
+	return e.SerializeStandard(buffer)
+}
+
 // Uints returns the value of a field with a given name and a given
 // []uint64 value. For details, see the comments section of the Field
-// structure.
+// structure. If the given slice is nil, the value of the field is null.
 func Uints(name string, values []uint64) Field {
+	if values == nil {
+		return Nil(name)
+	}
 	return Field {
 		Element: Element {
-			Type: TypeValue,
+			Type: TypeUints,
 			Interface: ElementUint64s(values),
 		},
 		Name: name,
@@ -485,11 +1568,17 @@ type ElementFloat32s []float32
 // it to the given buffer slice, and then returns the appended buffer
 // slice.
 func (e ElementFloat32s) SerializeJSON(buffer []byte) []byte {
+	return e.serializeJSON(buffer, FloatNull)
+}
+
+// serializeJSON serializes the element into a JSON string, serializing
+// non-finite members according to the given policy, and appends it to the
+// given buffer slice, and then returns the appended buffer slice.
+func (e ElementFloat32s) serializeJSON(buffer []byte, policy FloatPolicy) []byte {
 	buffer = append(buffer, '[')
 	tail := len(e) - 1
 	for index := 0; index < len(e); index++ {
-		buffer = strconv.AppendFloat(buffer, float64(e[index]),
-			'f', -1, 32)
+		buffer = appendFloat(buffer, float64(e[index]), 32, policy)
 		if index < tail {
 			buffer = append(buffer, ", "...)
 		}
@@ -497,13 +1586,37 @@ func (e ElementFloat32s) SerializeJSON(buffer []byte) []byte {
 	return append(buffer, ']')
 }
 
+// SerializeStandard serializes the element into a standard log string
+// and appends it to the given buffer slice, and then returns the appended
+// buffer slice.
+func (e ElementFloat32s) SerializeStandard(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// FormatJSON is an alias of the SerializeJSON function.
+//
+// Deprecated: Use SerializeJSON instead.
+func (e ElementFloat32s) FormatJSON(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// FormatStandard is an alias of the SerializeStandard function.
+//
+// Deprecated: Use SerializeStandard instead.
+func (e ElementFloat32s) FormatStandard(buffer []byte) []byte {
+	return e.SerializeStandard(buffer)
+}
+
 // Float32s returns the value of a field with a given name and a given
 // []float32 value. For details, see the comments section of the Field
-// structure.
+// structure. If the given slice is nil, the value of the field is null.
 func Float32s(name string, values []float32) Field {
+	if values == nil {
+		return Nil(name)
+	}
 	return Field {
 		Element: Element {
-			Type: TypeValue,
+			Type: TypeFloat32s,
 			Interface: ElementFloat32s(values),
 		},
 		Name: name,
@@ -519,11 +1632,17 @@ type ElementFloat64s []float64
 // it to the given buffer slice, and then returns the appended buffer
 // slice.
 func (e ElementFloat64s) SerializeJSON(buffer []byte) []byte {
+	return e.serializeJSON(buffer, FloatNull)
+}
+
+// serializeJSON serializes the element into a JSON string, serializing
+// non-finite members according to the given policy, and appends it to the
+// given buffer slice, and then returns the appended buffer slice.
+func (e ElementFloat64s) serializeJSON(buffer []byte, policy FloatPolicy) []byte {
 	buffer = append(buffer, '[')
 	tail := len(e) - 1
 	for index := 0; index < len(e); index++ {
-		buffer = strconv.AppendFloat(buffer, float64(e[index]),
-			'f', -1, 64)
+		buffer = appendFloat(buffer, float64(e[index]), 64, policy)
 		if index < tail {
 			buffer = append(buffer, ", "...)
 		}
@@ -531,13 +1650,37 @@ func (e ElementFloat64s) SerializeJSON(buffer []byte) []byte {
 	return append(buffer, ']')
 }
 
+// SerializeStandard serializes the element into a standard log string
+// and appends it to the given buffer slice, and then returns the appended
+// buffer slice.
+func (e ElementFloat64s) SerializeStandard(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// FormatJSON is an alias of the SerializeJSON function.
+//
+// Deprecated: Use SerializeJSON instead.
+func (e ElementFloat64s) FormatJSON(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// FormatStandard is an alias of the SerializeStandard function.
+//
+// Deprecated: Use SerializeStandard instead.
+func (e ElementFloat64s) FormatStandard(buffer []byte) []byte {
+	return e.SerializeStandard(buffer)
+}
+
 // Float64s returns the value of a field with a given name and a given
 // []float64 value. For details, see the comments section of the Field
-// structure.
+// structure. If the given slice is nil, the value of the field is null.
 func Float64s(name string, values []float64) Field {
+	if values == nil {
+		return Nil(name)
+	}
 	return Field {
 		Element: Element {
-			Type: TypeValue,
+			Type: TypeFloat64s,
 			Interface: ElementFloat64s(values),
 		},
 		Name: name,
@@ -564,13 +1707,37 @@ func (e ElementBooleans) SerializeJSON(buffer []byte) []byte {
 	return append(buffer, ']')
 }
 
+// SerializeStandard serializes the element into a standard log string
+// and appends it to the given buffer slice, and then returns the appended
+// buffer slice.
+func (e ElementBooleans) SerializeStandard(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// FormatJSON is an alias of the SerializeJSON function.
+//
+// Deprecated: Use SerializeJSON instead.
+func (e ElementBooleans) FormatJSON(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// FormatStandard is an alias of the SerializeStandard function.
+//
+// Deprecated: Use SerializeStandard instead.
+func (e ElementBooleans) FormatStandard(buffer []byte) []byte {
+	return e.SerializeStandard(buffer)
+}
+
 // Booleans returns the value of a field with a given name and a given
 // []bool value. For details, see the comments section of the Field
-// structure.
+// structure. If the given slice is nil, the value of the field is null.
 func Booleans(name string, values []bool) Field {
+	if values == nil {
+		return Nil(name)
+	}
 	return Field {
 		Element: Element {
-			Type: TypeValue,
+			Type: TypeBooleans,
 			Interface: ElementBooleans(values),
 		},
 		Name: name,
@@ -599,13 +1766,37 @@ func (e ElementStrings) SerializeJSON(buffer []byte) []byte {
 	return append(buffer, ']')
 }
 
+// SerializeStandard serializes the element into a standard log string
+// and appends it to the given buffer slice, and then returns the appended
+// buffer slice.
+func (e ElementStrings) SerializeStandard(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// FormatJSON is an alias of the SerializeJSON function.
+//
+// Deprecated: Use SerializeJSON instead.
+func (e ElementStrings) FormatJSON(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// FormatStandard is an alias of the SerializeStandard function.
+//
+// Deprecated: Use SerializeStandard instead.
+func (e ElementStrings) FormatStandard(buffer []byte) []byte {
+	return e.SerializeStandard(buffer)
+}
+
 // Strings returns the value of a field with a given name and a given
 // []string value. For details, see the comments section of the Field
-// structure.
+// structure. If the given slice is nil, the value of the field is null.
 func Strings(name string, values []string) Field {
+	if values == nil {
+		return Nil(name)
+	}
 	return Field {
 		Element: Element {
-			Type: TypeValue,
+			Type: TypeStrings,
 			Interface: ElementStrings(values),
 		},
 		Name: name,
@@ -621,10 +1812,17 @@ type ElementTimes []time.Time
 // it to the given buffer slice, and then returns the appended buffer
 // slice.
 func (e ElementTimes) SerializeJSON(buffer []byte) []byte {
+	return e.serializeJSON(buffer, "")
+}
+
+// serializeJSON serializes the element into a JSON string, encoding the
+// members in the given time format, and appends it to the given buffer
+// slice, and then returns the appended buffer slice.
+func (e ElementTimes) serializeJSON(buffer []byte, format TimeFormat) []byte {
 	buffer = append(buffer, '[')
 	tail := len(e) - 1
 	for index := 0; index < len(e); index++ {
-		buffer = strconv.AppendInt(buffer, e[index].UnixNano(), 10)
+		buffer = appendTime(buffer, e[index], format)
 		if index < tail {
 			buffer = append(buffer, ", "...)
 		}
@@ -632,15 +1830,200 @@ func (e ElementTimes) SerializeJSON(buffer []byte) []byte {
 	return append(buffer, ']')
 }
 
+// SerializeStandard serializes the element into a standard log string
+// and appends it to the given buffer slice, and then returns the appended
+// buffer slice.
+func (e ElementTimes) SerializeStandard(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// FormatJSON is an alias of the SerializeJSON function.
+//
+// Deprecated: Use SerializeJSON instead.
+func (e ElementTimes) FormatJSON(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// FormatStandard is an alias of the SerializeStandard function.
+//
+// Deprecated: Use SerializeStandard instead.
+func (e ElementTimes) FormatStandard(buffer []byte) []byte {
+	return e.SerializeStandard(buffer)
+}
+
 // Times returns the value of a field with a given name and a given
 // []time.Time value. For details, see the comments section of the Field
-// structure.
+// structure. If the given slice is nil, the value of the field is null.
 func Times(name string, values []time.Time) Field {
+	if values == nil {
+		return Nil(name)
+	}
 	return Field {
 		Element: Element {
-			Type: TypeValue,
+			Type: TypeTimes,
 			Interface: ElementTimes(values),
 		},
 		Name: name,
 	}
 }
+
+// ElementDurations represents an element data type whose native data type
+// is []time.Duration. For details, please refer to the comment section of
+// the Element structure.
+type ElementDurations []time.Duration
+
+// SerializeJSON serializes the element into a JSON string, encoding the
+// members as integers of nanoseconds, and appends it to the given buffer
+// slice, and then returns the appended buffer slice.
+func (e ElementDurations) SerializeJSON(buffer []byte) []byte {
+	buffer = append(buffer, '[')
+	tail := len(e) - 1
+	for index := 0; index < len(e); index++ {
+		buffer = strconv.AppendInt(buffer, int64(e[index]), 10)
+		if index < tail {
+			buffer = append(buffer, ", "...)
+		}
+	}
+	return append(buffer, ']')
+}
+
+// SerializeStandard serializes the element into a standard log string
+// and appends it to the given buffer slice, and then returns the appended
+// buffer slice.
+func (e ElementDurations) SerializeStandard(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// Durations returns the value of a field with a given name and a given
+// []time.Duration value, whose members are serialized as integers of
+// nanoseconds. For details, see the comments section of the Field
+// structure. If the given slice is nil, the value of the field is null.
+func Durations(name string, values []time.Duration) Field {
+	if values == nil {
+		return Nil(name)
+	}
+	return Field {
+		Element: Element {
+			Type: TypeDurations,
+			Interface: ElementDurations(values),
+		},
+		Name: name,
+	}
+}
+
+// ElementErrors represents an element data type whose native data type
+// is []error. For details, please refer to the comment section of the
+// Element structure.
+type ElementErrors []error
+
+// SerializeJSON serializes the element into a JSON string, encoding the
+// members as strings of their error messages and nil members as null,
+// and appends it to the given buffer slice, and then returns the appended
+// buffer slice.
+func (e ElementErrors) SerializeJSON(buffer []byte) []byte {
+	return e.serializeJSON(buffer, 0)
+}
+
+// serializeJSON serializes the element into a JSON string, truncating the
+// error messages according to the given limit in the same way as the
+// MaxFieldBytes encoder option, and appends it to the given buffer slice,
+// and then returns the appended buffer slice.
+func (e ElementErrors) serializeJSON(buffer []byte, limit int) []byte {
+	buffer = append(buffer, '[')
+	tail := len(e) - 1
+	for index := 0; index < len(e); index++ {
+		if isNil(e[index]) {
+			buffer = append(buffer, "null"...)
+		} else {
+			buffer = appendLimitedString(buffer, e[index].Error(), limit)
+		}
+		if index < tail {
+			buffer = append(buffer, ", "...)
+		}
+	}
+	return append(buffer, ']')
+}
+
+// SerializeStandard serializes the element into a standard log string
+// and appends it to the given buffer slice, and then returns the appended
+// buffer slice.
+func (e ElementErrors) SerializeStandard(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// Errors returns the value of a field with a given name and a given
+// []error value, whose members are serialized as their error messages,
+// or null for nil members. The error messages are obtained each time the
+// field is serialized. For details, see the comments section of the Field
+// structure. If the given slice is nil, the value of the field is null.
+func Errors(name string, values []error) Field {
+	if values == nil {
+		return Nil(name)
+	}
+	return Field {
+		Element: Element {
+			Type: TypeErrors,
+			Interface: ElementErrors(values),
+		},
+		Name: name,
+	}
+}
+
+// ElementBytesSlice represents an element data type whose native data type
+// is [][]byte. For details, please refer to the comment section of the
+// Element structure.
+type ElementBytesSlice [][]byte
+
+// SerializeJSON serializes the element into a JSON string, encoding the
+// members as strings in the same way as the ByteString function and nil
+// members as null, and appends it to the given buffer slice, and then
+// returns the appended buffer slice.
+func (e ElementBytesSlice) SerializeJSON(buffer []byte) []byte {
+	return e.serializeJSON(buffer, 0)
+}
+
+// serializeJSON serializes the element into a JSON string, truncating the
+// members according to the given limit in the same way as the MaxFieldBytes
+// encoder option, and appends it to the given buffer slice, and then
+// returns the appended buffer slice.
+func (e ElementBytesSlice) serializeJSON(buffer []byte, limit int) []byte {
+	buffer = append(buffer, '[')
+	tail := len(e) - 1
+	for index := 0; index < len(e); index++ {
+		if e[index] == nil {
+			buffer = append(buffer, "null"...)
+		} else {
+			buffer = appendLimitedString(buffer, *(*string)(unsafe.Pointer(
+				&e[index])), limit)
+		}
+		if index < tail {
+			buffer = append(buffer, ", "...)
+		}
+	}
+	return append(buffer, ']')
+}
+
+// SerializeStandard serializes the element into a standard log string
+// and appends it to the given buffer slice, and then returns the appended
+// buffer slice.
+func (e ElementBytesSlice) SerializeStandard(buffer []byte) []byte {
+	return e.SerializeJSON(buffer)
+}
+
+// BytesSlice returns the value of a field with a given name and a given
+// [][]byte value containing UTF-8 texts, whose members are serialized as
+// strings without copying them, or null for nil members. For details, see
+// the comments section of the Field structure. If the given slice is nil,
+// the value of the field is null.
+func BytesSlice(name string, values [][]byte) Field {
+	if values == nil {
+		return Nil(name)
+	}
+	return Field {
+		Element: Element {
+			Type: TypeBytesSlice,
+			Interface: ElementBytesSlice(values),
+		},
+		Name: name,
+	}
+}
@@ -23,7 +23,12 @@
 package santa
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"math"
+	"net"
+	"os"
 	"testing"
 	"time"
 
@@ -74,6 +79,11 @@ func TestElementSerializeJSON(t *testing.T) {
 		{
 			name: "bytes",
 			field: Bytes("bytes", []byte("Hello")),
+			expected: "\"SGVsbG8=\"",
+		},
+		{
+			name: "bytestring",
+			field: ByteString("bytestring", []byte("Hello")),
 			expected: "\"Hello\"",
 		},
 		{
@@ -183,3 +193,589 @@ func TestElementSerializeJSON(t *testing.T) {
 		)
 	}
 }
+
+func TestElementRestore(t *testing.T) {
+	fields := []Field {
+		String("name", "test"),
+		Int("age", 100),
+	}
+
+	field := Object("object", fields...)
+	assert.Equal(t, ElementObject(fields), field.Object(),
+		"Unexpected restore result")
+	assert.Nil(t, field.Ints(), "Unexpected restore result")
+
+	field = Ints("ints", []int64 { 10, 20, 30 })
+	assert.Equal(t, ElementInts { 10, 20, 30 }, field.Ints(),
+		"Unexpected restore result")
+	assert.Nil(t, field.Object(), "Unexpected restore result")
+
+	field = Strings("strings", []string { })
+	assert.Empty(t, field.Strings(), "Unexpected restore result")
+	assert.Equal(t, "[]", string(field.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+
+	field = Bytes("bytes", []byte { })
+	assert.Equal(t, `""`, string(field.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+}
+
+func newBenchmarkFields() []Field {
+	return []Field {
+		String("name", "test"),
+		Int("age", 100),
+		Float64("score", 99.5),
+		Boolean("active", true),
+		Bytes("token", []byte("Hello")),
+		Ints("ints", []int64 { 10, 20, 30 }),
+		Strings("strings", []string { "value1", "value2" }),
+		Object("object", String("name", "test"), Int("age", 100)),
+	}
+}
+
+func TestElementAllocations(t *testing.T) {
+	fields := newBenchmarkFields()
+	buffer := make([]byte, 0, 1024)
+
+	allocations := testing.AllocsPerRun(100, func() {
+		message := StructMessage {
+			Text: "Hello Test!",
+			Fields: fields,
+		}
+		buffer = message.Fields.SerializeJSON(buffer[ : 0])
+	})
+	assert.Equal(t, float64(0), allocations,
+		"Unexpected memory allocation")
+}
+
+func BenchmarkElementObjectSerializeJSON(b *testing.B) {
+	fields := ElementObject(newBenchmarkFields())
+	buffer := make([]byte, 0, 1024)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for index := 0; index < b.N; index++ {
+		buffer = fields.SerializeJSON(buffer[ : 0])
+	}
+}
+
+func BenchmarkSliceFields(b *testing.B) {
+	ints := []int64 { 10, 20, 30 }
+	floats := []float64 { 1.4, 1.5, 1.6 }
+	strings := []string { "value1", "value2" }
+	fields := make(ElementObject, 3)
+	buffer := make([]byte, 0, 1024)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for index := 0; index < b.N; index++ {
+		fields[0] = Ints("ints", ints)
+		fields[1] = Float64s("floats", floats)
+		fields[2] = Strings("strings", strings)
+		buffer = fields.SerializeJSON(buffer[ : 0])
+	}
+}
+
+type testStandardValue struct { }
+
+func (v testStandardValue) SerializeJSON(buffer []byte) []byte {
+	return append(buffer, `"json"`...)
+}
+
+func (v testStandardValue) SerializeStandard(buffer []byte) []byte {
+	return append(buffer, "standard"...)
+}
+
+func TestElementSerializeStandard(t *testing.T) {
+	for _, field := range []Field {
+		Int("int", 10),
+		String("string", "Hello"),
+		Bytes("bytes", []byte("Hello")),
+		Object("object", String("name", "test")),
+		Ints("ints", []int64 { 10, 20, 30 }),
+		Strings("strings", []string { "value1", "value2" }),
+	} {
+		assert.Equal(t, string(field.SerializeJSON(nil)), string(
+			field.SerializeStandard(nil)),
+			"Unexpected standard formatted append result")
+		assert.Equal(t, string(field.SerializeJSON(nil)), string(
+			field.FormatJSON(nil)), "Unexpected format result")
+	}
+
+	field := Value("value", testStandardValue { })
+	assert.Equal(t, `"json"`, string(field.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+	assert.Equal(t, "standard", string(field.SerializeStandard(nil)),
+		"Unexpected standard formatted append result")
+	assert.Equal(t, "standard", string(field.FormatStandard(nil)),
+		"Unexpected format result")
+}
+
+func TestElementFormatAliases(t *testing.T) {
+	for _, element := range []interface {
+		StandardSerializer
+		JSONSerializer
+		FormatStandard(buffer []byte) []byte
+		FormatJSON(buffer []byte) []byte
+	} {
+		ElementObject { String("name", "test") },
+		ElementObjects { { String("name", "test") } },
+		ElementInts { 10, 20 },
+		ElementUint64s { 10, 20 },
+		ElementFloat32s { 1.5 },
+		ElementFloat64s { 1.5 },
+		ElementBooleans { true, false },
+		ElementStrings { "value1", "value2" },
+		ElementTimes { time.Unix(0, 10) },
+	} {
+		assert.Equal(t, string(element.SerializeStandard(nil)), string(
+			element.FormatStandard(nil)), "Unexpected format result")
+		assert.Equal(t, string(element.SerializeJSON(nil)), string(
+			element.FormatJSON(nil)), "Unexpected format result")
+	}
+}
+
+func TestElementSerializeStandardNested(t *testing.T) {
+	field := Object("object", Value("value", testStandardValue { }),
+		Objects("objects", ElementObject {
+			Value("value", testStandardValue { }),
+		}))
+	assert.Equal(t, `{"value": "json", "objects": [{"value": "json"}]}`,
+		string(field.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+	assert.Equal(t, `{"value": standard, "objects": [{"value": standard}]}`,
+		string(field.SerializeStandard(nil)),
+		"Unexpected standard formatted append result")
+
+	fields := ElementObject { field }
+	option := &SerializerOption { }
+	assert.Equal(t, `{"object": {"value": "json", "objects": `+
+		`[{"value": "json"}]}}`, string(fields.SerializeJSONOption(nil,
+		option)), "Unexpected JSON formatted append result")
+	assert.Equal(t, `{"object": {"value": standard, "objects": `+
+		`[{"value": standard}]}}`, string(fields.SerializeStandardOption(
+		nil, option)), "Unexpected standard formatted append result")
+	option.SortFields = true
+	assert.Equal(t, `{"object": {"objects": [{"value": standard}], `+
+		`"value": standard}}`, string(fields.SerializeStandardOption(nil,
+		option)), "Unexpected standard formatted append result")
+}
+
+type testMarshalerValue struct {
+	Name string
+}
+
+func (v testMarshalerValue) MarshalJSON() ([]byte, error) {
+	return []byte(`{ "marshaled": "` + v.Name + `" }`), nil
+}
+
+type testMarshalerSerializer struct {
+	testMarshalerValue
+}
+
+func (v testMarshalerSerializer) SerializeJSON(buffer []byte) []byte {
+	return append(buffer, `"serialized"`...)
+}
+
+func TestValueMarshalers(t *testing.T) {
+	field := Value("value", testMarshalerValue { Name: "test" })
+	assert.Equal(t, `{"marshaled":"test"}`, string(field.SerializeJSON(nil)),
+		"Unexpected serialize result")
+	assert.Equal(t, `{"marshaled":"test"}`,
+		string(field.SerializeStandard(nil)), "Unexpected serialize result")
+
+	field = Value("address", net.ParseIP("192.168.1.1"))
+	assert.Equal(t, `"192.168.1.1"`, string(field.SerializeJSON(nil)),
+		"Unexpected serialize result")
+
+	field = Value("value", testMarshalerSerializer { })
+	assert.Equal(t, `"serialized"`, string(field.SerializeJSON(nil)),
+		"Unexpected serialize result")
+
+	field = Value("value", struct { } { })
+	assert.Equal(t, "???", string(field.SerializeJSON(nil)),
+		"Unexpected serialize result")
+}
+
+type testNilValue struct { }
+
+func (v *testNilValue) SerializeJSON(buffer []byte) []byte {
+	return append(buffer, `"value"`...)
+}
+
+func TestNilFields(t *testing.T) {
+	var pointer *testNilValue
+	var err error
+	var mapping map[string]int
+
+	for _, field := range []Field {
+		Nil("nil"),
+		Error("error", nil),
+		Error("error", err),
+		Bytes("bytes", nil),
+		Ints("ints", nil),
+		Uints("uints", nil),
+		Float32s("float32s", nil),
+		Float64s("float64s", nil),
+		Booleans("booleans", nil),
+		Strings("strings", nil),
+		Times("times", nil),
+		Value("value", nil),
+		Value("pointer", pointer),
+		Value("map", mapping),
+		Value("bytes", []byte(nil)),
+		Reflect("reflect", nil),
+	} {
+		assert.Equal(t, "null", string(field.SerializeJSON(nil)),
+			"Unexpected JSON formatted append result: %s", field.Name)
+		assert.Equal(t, "null", string(field.SerializeStandard(nil)),
+			"Unexpected standard formatted append result: %s", field.Name)
+	}
+
+	assert.Equal(t, TypeNil, Nil("nil").Type, "Unexpected field type")
+	assert.Equal(t, `"value"`, string(Value("value", &testNilValue { }).
+		SerializeJSON(nil)), "Unexpected JSON formatted append result")
+	assert.Equal(t, `{"nil": null}`, string(ElementObject {
+		Nil("nil") }.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+}
+
+func TestBinary(t *testing.T) {
+	value := []byte { 0xff, 0x00, '"', 'a' }
+
+	field := Binary("binary", value)
+	assert.Equal(t, TypeBinary, field.Type, "Unexpected field type")
+	assert.Equal(t, value, field.Bytes(), "Unexpected restore result")
+	assert.Equal(t, `"/wAiYQ=="`, string(field.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+	assert.Equal(t, `"/wAiYQ=="`, string(field.SerializeStandard(nil)),
+		"Unexpected standard formatted append result")
+
+	option := &SerializerOption { }
+	assert.Equal(t, `"/wAiYQ=="`, string(field.SerializeJSONOption(nil,
+		option)), "Unexpected JSON formatted append result")
+	option.BinaryEncoding = BinaryHex
+	assert.Equal(t, `"ff002261"`, string(field.SerializeJSONOption(nil,
+		option)), "Unexpected JSON formatted append result")
+	option.MaxFieldBytes = 2
+	assert.Equal(t, `"ff00…(4 bytes)"`, string(field.SerializeJSONOption(
+		nil, option)), "Unexpected JSON formatted append result")
+
+	assert.Equal(t, `""`, string(Binary("binary", []byte { }).
+		SerializeJSON(nil)), "Unexpected JSON formatted append result")
+	assert.Equal(t, "null", string(Binary("binary", nil).SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+
+	field = ByteString("text", []byte("Hello"))
+	assert.Equal(t, TypeBytes, field.Type, "Unexpected field type")
+	assert.Equal(t, `"Hello"`, string(field.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+
+	// Invalid UTF-8 sequences of text are replaced.
+	field = ByteString("text", []byte("a\xffb世\xe4"))
+	assert.Equal(t, `"a\ufffdb世\ufffd"`, string(field.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+	assert.True(t, json.Valid(field.SerializeJSON(nil)),
+		"Unexpected invalid JSON")
+	option.BinaryEncoding = BinaryBase64
+	option.MaxFieldBytes = 2
+	assert.Equal(t, `"a\ufffd…(7 bytes)"`, string(field.SerializeJSONOption(
+		nil, option)), "Unexpected JSON formatted append result")
+
+	field = Bytes("bytes", value)
+	assert.Equal(t, TypeBinary, field.Type, "Unexpected field type")
+	assert.Equal(t, `"/wAiYQ=="`, string(field.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+
+	fields := ElementObject { Binary("binary", value) }.Clone()
+	value[0] = 0
+	assert.Equal(t, `{"binary": "/wAiYQ=="}`, string(fields.SerializeJSON(
+		nil)), "Unexpected clone result")
+}
+
+func TestBinaryAllocations(t *testing.T) {
+	field := Binary("binary", []byte("Hello Test!"))
+	option := &SerializerOption { }
+	buffer := make([]byte, 0, 256)
+
+	allocations := testing.AllocsPerRun(100, func() {
+		option.BinaryEncoding = BinaryBase64
+		buffer = field.SerializeJSONOption(buffer[ : 0], option)
+		option.BinaryEncoding = BinaryHex
+		buffer = field.SerializeJSONOption(buffer[ : 0], option)
+	})
+	assert.Equal(t, float64(0), allocations, "Unexpected memory allocation")
+}
+
+func TestFloatPolicy(t *testing.T) {
+	fields := ElementObject {
+		Float64("nan", math.NaN()),
+		Float32("positive", float32(math.Inf(1))),
+		Float64("negative", math.Inf(-1)),
+		Float64("finite", 1.5),
+		Float64s("values", []float64 { 1, math.NaN(), math.Inf(1) }),
+		Float32s("values32", []float32 { float32(math.Inf(-1)) }),
+	}
+
+	expected := `{"nan": null, "positive": null, "negative": null, ` +
+		`"finite": 1.5, "values": [1, null, null], "values32": [null]}`
+	assert.Equal(t, expected, string(fields.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+	option := &SerializerOption { }
+	assert.Equal(t, expected, string(fields.SerializeJSONOption(nil,
+		option)), "Unexpected JSON formatted append result")
+
+	option.FloatPolicy = FloatString
+	assert.Equal(t, `{"nan": "NaN", "positive": "+Inf", "negative": "-Inf", `+
+		`"finite": 1.5, "values": [1, "NaN", "+Inf"], "values32": ["-Inf"]}`,
+		string(fields.SerializeJSONOption(nil, option)),
+		"Unexpected JSON formatted append result")
+
+	option.FloatPolicy = FloatOmit
+	assert.Equal(t, `{"finite": 1.5, "values": [1, null, null], `+
+		`"values32": [null]}`, string(fields.SerializeJSONOption(nil,
+		option)), "Unexpected JSON formatted append result")
+	assert.Equal(t, `{}`, string(fields[ : 3].SerializeJSONOption(nil,
+		option)), "Unexpected JSON formatted append result")
+	assert.Equal(t, `{"finite": 1.5}`, string(ElementObject {
+		Float64("nan", math.NaN()), Float64("finite", 1.5) }.
+		SerializeJSONOption(nil, option)),
+		"Unexpected JSON formatted append result")
+}
+
+func TestTimeFormat(t *testing.T) {
+	location := time.FixedZone("UTC+8", 8 * 60 * 60)
+	timestamp := time.Date(2020, 8, 13, 21, 56, 30, 71993900, location)
+
+	field := Time("time", timestamp)
+	assert.Equal(t, TypeTime, field.Type, "Unexpected field type")
+	assert.True(t, timestamp.Equal(field.Time()), "Unexpected restore result")
+	assert.Equal(t, location, field.Time().Location(),
+		"Unexpected restore result")
+	assert.Equal(t, "1597326990071993900", string(field.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+
+	fields := ElementObject {
+		Time("time", timestamp),
+		TimeAs("seconds", timestamp, TimeUnix),
+		Times("times", []time.Time { timestamp }),
+	}
+	for _, sample := range []struct {
+		format TimeFormat
+		expected string
+	} {
+		{
+			format: TimeUnixNano,
+			expected: `{"time": 1597326990071993900, "seconds": 1597326990, ` +
+				`"times": [1597326990071993900]}`,
+		},
+		{
+			format: TimeUnixMilli,
+			expected: `{"time": 1597326990071, "seconds": 1597326990, ` +
+				`"times": [1597326990071]}`,
+		},
+		{
+			format: TimeUnix,
+			expected: `{"time": 1597326990, "seconds": 1597326990, ` +
+				`"times": [1597326990]}`,
+		},
+		{
+			format: TimeRFC3339Nano,
+			expected: `{"time": "2020-08-13T21:56:30.0719939+08:00", ` +
+				`"seconds": 1597326990, ` +
+				`"times": ["2020-08-13T21:56:30.0719939+08:00"]}`,
+		},
+	} {
+		option := &SerializerOption { }
+		option.TimeFormat = sample.format
+		assert.Equal(t, sample.expected, string(fields.SerializeJSONOption(
+			nil, option)), "Unexpected JSON formatted append result")
+	}
+
+	field = TimeAs("time", time.Unix(-1, 500000000), TimeUnixMilli)
+	assert.Equal(t, "-500", string(field.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+	assert.True(t, Int("int", 1).Time().IsZero(), "Unexpected restore result")
+}
+
+func TestNamespace(t *testing.T) {
+	fields := ElementObject {
+		String("id", "1"),
+		Namespace("user"),
+		String("name", "test"),
+		Int("age", 100),
+		Namespace("address"),
+		String("city", "Shanghai"),
+	}
+	expected := `{"id": "1", "user": {"name": "test", "age": 100, ` +
+		`"address": {"city": "Shanghai"}}}`
+	assert.Equal(t, expected, string(fields.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+	assert.Equal(t, expected, string(fields.SerializeJSONOption(nil,
+		&SerializerOption { })), "Unexpected JSON formatted append result")
+
+	fields = ElementObject {
+		Namespace("empty"),
+	}
+	assert.Equal(t, `{"empty": {}}`, string(fields.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+
+	fields = ElementObject {
+		Namespace("metrics"),
+		Float64("nan", math.NaN()),
+		Float64("ratio", 0.5),
+	}
+	assert.Equal(t, `{"metrics": {"ratio": 0.5}}`, string(fields.
+		SerializeJSONOption(nil, &SerializerOption {
+			EncoderOption: EncoderOption { FloatPolicy: FloatOmit },
+		})), "Unexpected JSON formatted append result")
+
+	message := StructMessage {
+		Text: "Hello Test!",
+		Fields: ElementObject {
+			Object("object", Namespace("nested"), Int("value", 1)),
+			Int("value", 2),
+		},
+	}
+	assert.Equal(t, `{"text": "Hello Test!", "payload": {"object": `+
+		`{"nested": {"value": 1}}, "value": 2}}`, string(message.
+		SerializeJSON(nil)), "Unexpected JSON formatted append result")
+}
+
+func TestFieldOrdering(t *testing.T) {
+	fields := ElementObject {
+		String("b", "1"),
+		String("a", "2"),
+		String("b", "3"),
+		Object("object", Int("z", 1), Int("y", 2), Int("z", 3)),
+		Namespace("a"),
+		Int("d", 4),
+		Int("c", 5),
+	}
+
+	option := &SerializerOption { }
+	option.SortFields = true
+	assert.Equal(t, `{"a": "2", "a": {"c": 5, "d": 4}, "b": "1", "b": "3", `+
+		`"object": {"y": 2, "z": 1, "z": 3}}`, string(fields.
+		SerializeJSONOption(nil, option)),
+		"Unexpected JSON formatted append result")
+
+	option.DuplicateFields = DuplicateLastWins
+	assert.Equal(t, `{"a": {"c": 5, "d": 4}, "b": "3", `+
+		`"object": {"y": 2, "z": 3}}`, string(fields.SerializeJSONOption(
+		nil, option)), "Unexpected JSON formatted append result")
+
+	option.SortFields = false
+	assert.Equal(t, `{"b": "3", "object": {"y": 2, "z": 3}, `+
+		`"a": {"d": 4, "c": 5}}`, string(fields.SerializeJSONOption(
+		nil, option)), "Unexpected JSON formatted append result")
+
+	assert.True(t, fields.duplicated(), "Unexpected duplicate result")
+	assert.False(t, ElementObject { String("a", "1"), Namespace("b"),
+		String("a", "2") }.duplicated(), "Unexpected duplicate result")
+	assert.True(t, ElementObject { Namespace("b"), String("a", "1"),
+		String("a", "2") }.duplicated(), "Unexpected duplicate result")
+	assert.True(t, ElementObject { Objects("objects", ElementObject {
+		Int("a", 1), Int("a", 2) }) }.duplicated(),
+		"Unexpected duplicate result")
+
+	buffer := make([]byte, 0, 256)
+	option.SortFields = true
+	allocations := testing.AllocsPerRun(100, func() {
+		buffer = fields.SerializeJSONOption(buffer[ : 0], option)
+	})
+	assert.Equal(t, float64(0), allocations, "Unexpected memory allocation")
+}
+
+func TestRawJSON(t *testing.T) {
+	value := []byte(`{"id": 1, "tags": ["a"]}`)
+
+	field := RawJSON("payload", value)
+	assert.Equal(t, TypeRaw, field.Type, "Unexpected field type")
+	assert.Equal(t, value, field.Bytes(), "Unexpected restore result")
+	assert.Equal(t, string(value), string(field.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+	assert.Equal(t, string(value), string(field.SerializeStandard(nil)),
+		"Unexpected standard formatted append result")
+	assert.Equal(t, "null", string(RawJSON("payload", nil).SerializeJSON(
+		nil)), "Unexpected JSON formatted append result")
+	assert.Equal(t, "null", string(RawJSON("payload", []byte { }).
+		SerializeJSON(nil)), "Unexpected JSON formatted append result")
+
+	invalid := RawJSON("payload", []byte(`{"id": `))
+	option := &SerializerOption { }
+	assert.Equal(t, `{"id": `, string(invalid.SerializeJSONOption(nil,
+		option)), "Unexpected JSON formatted append result")
+	option.ValidateRawJSON = true
+	assert.Equal(t, `"{\"id\": "`, string(invalid.SerializeJSONOption(nil,
+		option)), "Unexpected JSON formatted append result")
+	assert.Equal(t, string(value), string(field.SerializeJSONOption(nil,
+		option)), "Unexpected JSON formatted append result")
+
+	fields := ElementObject { field }.Clone()
+	value[1] = ' '
+	assert.Equal(t, `{"payload": {"id": 1, "tags": ["a"]}}`,
+		string(fields.SerializeJSON(nil)), "Unexpected clone result")
+}
+
+func TestKeysAndValues(t *testing.T) {
+	buffer := &bytes.Buffer { }
+	SetDiagnosticsWriter(buffer)
+	defer SetDiagnosticsWriter(os.Stderr)
+
+	fields := ElementObject(KeysAndValues("name", "test", "age", 100,
+		Boolean("enabled", true), "point", struct { X int } { 1 }))
+	assert.Equal(t, `{"name": "test", "age": 100, "enabled": true, `+
+		`"point": {"X":1}}`, string(fields.SerializeJSON(nil)),
+		"Unexpected fields")
+	assert.Empty(t, buffer.String(), "Unexpected diagnostics report")
+
+	fields = ElementObject(KeysAndValues(1, "name", "test", "age"))
+	assert.Equal(t, `{"!BADKEY": 1, "name": "test", "!BADKEY": "age"}`,
+		string(fields.SerializeJSON(nil)), "Unexpected fields")
+	assert.Contains(t, buffer.String(), "non-string key 1 of type int",
+		"Unexpected diagnostics report")
+	assert.Contains(t, buffer.String(), `key "age" has no value`,
+		"Unexpected diagnostics report")
+
+	assert.Empty(t, KeysAndValues(), "Unexpected fields")
+}
+
+func TestDurationsErrorsBytesSlice(t *testing.T) {
+	fields := ElementObject {
+		Durations("durations", []time.Duration { time.Second, -1 }),
+		Errors("errors", []error { errors.New("failed"), nil }),
+		BytesSlice("bytes", [][]byte { []byte("a"), nil, { } }),
+		Durations("emptyDurations", []time.Duration { }),
+		Errors("nilErrors", nil),
+	}
+	assert.Equal(t, `{"durations": [1000000000, -1], `+
+		`"errors": ["failed", null], "bytes": ["a", null, ""], `+
+		`"emptyDurations": [], "nilErrors": null}`,
+		string(fields.SerializeJSON(nil)), "Unexpected fields")
+	assert.Equal(t, string(fields.SerializeJSON(nil)),
+		string(fields.SerializeStandard(nil)), "Unexpected fields")
+
+	assert.Equal(t, TypeDurations, Value("value", []time.Duration { }).Type,
+		"Unexpected field type")
+	assert.Equal(t, TypeErrors, Value("value", []error { }).Type,
+		"Unexpected field type")
+	assert.Equal(t, TypeBytesSlice, Value("value", [][]byte { }).Type,
+		"Unexpected field type")
+	assert.Equal(t, Int("value", int64(time.Second)), Value("value",
+		time.Second), "Unexpected field")
+
+	option := &SerializerOption { }
+	option.MaxFieldBytes = 2
+	assert.Equal(t, `["fa…(6 bytes)", null]`, string(fields[1].
+		SerializeJSONOption(nil, option)), "Unexpected limited errors")
+	assert.Equal(t, `["a", null, ""]`, string(fields[2].
+		SerializeJSONOption(nil, option)), "Unexpected limited bytes")
+
+	value := []byte("abc")
+	cloned := ElementObject { BytesSlice("bytes", [][]byte { value }) }.
+		Clone()
+	value[0] = 'x'
+	assert.Equal(t, `{"bytes": ["abc"]}`, string(cloned.SerializeJSON(nil)),
+		"Unexpected clone result")
+}
@@ -0,0 +1,108 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package santa
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// maxVectors is the maximum number of buffers written by a single writev
+// system call, which is the minimum IOV_MAX required by POSIX.
+const maxVectors = 16
+
+// writeVectored writes the given buffer slices to the given file in order
+// with the writev system call, so that multiple buffer slices are written
+// without being copied into a single buffer slice. Partial writes are
+// continued until all buffer slices are written or an error occurs.
+//
+// Finally, it returns the number of bytes written and any errors
+// encountered.
+func writeVectored(file *os.File, buffers [][]byte) (int, error) {
+	conn, err := file.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	var written int
+	var operationErr error
+	vectors := make([]syscall.Iovec, 0, maxVectors)
+	remaining := buffers
+	err = conn.Write(func(descriptor uintptr) bool {
+		for {
+			vectors = vectors[ : 0]
+			for _, buffer := range remaining {
+				if len(vectors) == maxVectors {
+					break
+				}
+				if len(buffer) == 0 {
+					continue
+				}
+				vector := syscall.Iovec { Base: &buffer[0] }
+				vector.SetLen(len(buffer))
+				vectors = append(vectors, vector)
+			}
+			if len(vectors) == 0 {
+				return true
+			}
+			size, _, errno := syscall.Syscall(syscall.SYS_WRITEV, descriptor,
+				uintptr(unsafe.Pointer(&vectors[0])), uintptr(len(vectors)))
+			switch errno {
+			case 0:
+			case syscall.EINTR:
+				continue
+			case syscall.EAGAIN:
+				// Wait until the file is writable if it supports polling.
+				return false
+			default:
+				operationErr = os.NewSyscallError("writev", errno)
+				return true
+			}
+			written += int(size)
+			remaining = skipBytes(remaining, int(size))
+		}
+	})
+	runtime.KeepAlive(buffers)
+	if err != nil {
+		return written, err
+	}
+	return written, operationErr
+}
+
+// skipBytes removes the given number of bytes from the beginning of the
+// given buffer slices, and then returns the remaining buffer slices. The
+// first remaining buffer slice of the given buffer slices is modified.
+func skipBytes(buffers [][]byte, size int) [][]byte {
+	for len(buffers) > 0 && size >= len(buffers[0]) {
+		size -= len(buffers[0])
+		buffers = buffers[1 : ]
+	}
+	if len(buffers) > 0 {
+		buffers[0] = buffers[0][size : ]
+	}
+	return buffers
+}
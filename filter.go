@@ -0,0 +1,168 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"strings"
+)
+
+// FilterExporter is the structure of the filter exporter instance.
+//
+// The filter exporter wraps an exporter and only exports the log entries
+// for which a predicate returns true to it, or, if the Exclude option is
+// enabled, only the log entries for which the predicate returns false.
+// This allows noisy subsystems to be excluded from specific exporters
+// without a hook that affects all exporters of the logger. For example:
+//
+//	exporter, _ := santa.NewFilterExporterOption().
+//		UseExporter(remote).
+//		UsePredicate(santa.MatchName("healthcheck")).
+//		UseExclude().Build()
+//
+// The functions starting with Match... create common predicates.
+type FilterExporter struct {
+	exporter Exporter
+	predicate func(*Entry) bool
+	exclude bool
+}
+
+// Export exports the given log entry to the wrapped exporter if it passes
+// the predicate. For details, please refer to the comment section of the
+// FilterExporter structure.
+//
+// Finally, any errors encountered are returned.
+func (e *FilterExporter) Export(entry *Entry) error {
+	if e.predicate(entry) == e.exclude {
+		return nil
+	}
+	return e.exporter.Export(entry)
+}
+
+// Sync calls the Sync function of the wrapped exporter. For details,
+// please refer to the Sync function of the Exporter interface.
+//
+// Finally, any errors encountered are returned.
+func (e *FilterExporter) Sync() error {
+	return e.exporter.Sync()
+}
+
+// Close calls the Close function of the wrapped exporter. For details,
+// please refer to the Close function of the Exporter interface.
+//
+// Finally, any errors encountered are returned.
+func (e *FilterExporter) Close() error {
+	return e.exporter.Close()
+}
+
+// MatchLabel returns a predicate that checks whether a log entry has a
+// label with the given key and value.
+func MatchLabel(key, value string) func(*Entry) bool {
+	label := NewLabel(key, value)
+	return func(entry *Entry) bool {
+		return hasLabel(entry.Labels.Labels(), label)
+	}
+}
+
+// MatchName returns a predicate that checks whether the name of a log
+// entry is one of the given names.
+func MatchName(names ...string) func(*Entry) bool {
+	return func(entry *Entry) bool {
+		for _, name := range names {
+			if name == entry.Name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchMessageContains returns a predicate that checks whether the message
+// text of a log entry contains the given text. Please note that the message
+// text of template messages is formatted for each check.
+func MatchMessageContains(text string) func(*Entry) bool {
+	return func(entry *Entry) bool {
+		if entry.Message == nil {
+			return false
+		}
+		return strings.Contains(messageText(entry.Message), text)
+	}
+}
+
+// FilterExporterOption is a structure that contains options for the filter
+// exporter.
+type FilterExporterOption struct {
+	// Exporter represents the wrapped exporter to which the log entries
+	// that pass the predicate are exported. This option is required.
+	Exporter Exporter
+
+	// Predicate represents the function that checks each log entry. This
+	// option is required.
+	Predicate func(*Entry) bool
+
+	// Exclude represents whether the log entries for which the predicate
+	// returns true are excluded instead of included. If not provided, the
+	// default value is false.
+	Exclude bool
+}
+
+// UseExporter uses the given exporter as the value of the Exporter option.
+// For details, please refer to the comment section of the Exporter option.
+// Then return to the option instance itself.
+func (o *FilterExporterOption) UseExporter(exporter Exporter) *FilterExporterOption {
+	o.Exporter = exporter
+	return o
+}
+
+// UsePredicate uses the given function as the value of the Predicate
+// option. For details, please refer to the comment section of the
+// Predicate option. Then return to the option instance itself.
+func (o *FilterExporterOption) UsePredicate(predicate func(*Entry) bool) *FilterExporterOption {
+	o.Predicate = predicate
+	return o
+}
+
+// UseExclude enables the Exclude option. For details, please refer to the
+// comment section of the Exclude option. Then return to the option
+// instance itself.
+func (o *FilterExporterOption) UseExclude() *FilterExporterOption {
+	o.Exclude = true
+	return o
+}
+
+// Build builds and returns a filter exporter instance.
+func (o *FilterExporterOption) Build() (*FilterExporter, error) {
+	if o.Exporter == nil || o.Predicate == nil {
+		return nil, ErrInvalidExporter
+	}
+	return &FilterExporter {
+		exporter: o.Exporter,
+		predicate: o.Predicate,
+		exclude: o.Exclude,
+	}, nil
+}
+
+// NewFilterExporterOption creates and returns an instance of the filter
+// exporter option with default optional values.
+func NewFilterExporterOption() *FilterExporterOption {
+	return &FilterExporterOption { }
+}
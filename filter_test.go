@@ -0,0 +1,89 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterExporterOption(t *testing.T) {
+	_, err := NewFilterExporterOption().Build()
+	assert.Equal(t, ErrInvalidExporter, err, "Unexpected build error")
+
+	option := NewFilterExporterOption().UseExporter(&testRecordExporter { }).
+		UsePredicate(MatchName("test")).UseExclude()
+	assert.True(t, option.Exclude, "Unexpected option value")
+
+	exporter, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.True(t, exporter.exclude, "Unexpected instance error")
+}
+
+func TestFilterExporter(t *testing.T) {
+	record := &testRecordExporter { }
+	exporter, err := NewFilterExporterOption().UseExporter(record).
+		UsePredicate(MatchLabel("module", "billing")).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	for _, module := range []string { "billing", "users" } {
+		err = exporter.Export(&Entry {
+			Labels: NewSerializedLabels(NewLabel("module", module)),
+		})
+		assert.NoError(t, err, "Unexpected export error")
+	}
+	assert.Len(t, record.entries, 1, "Unexpected export result")
+
+	record = &testRecordExporter { }
+	exporter, err = NewFilterExporterOption().UseExporter(record).
+		UsePredicate(MatchMessageContains("health")).UseExclude().Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	messages := []Message {
+		StringMessage("health check passed"),
+		TemplateMessage {
+			Template: "user %s logged in",
+			Args: []interface { } { "alice" },
+		},
+		nil,
+	}
+	for _, message := range messages {
+		err = exporter.Export(&Entry {
+			Message: message,
+		})
+		assert.NoError(t, err, "Unexpected export error")
+	}
+	assert.Len(t, record.entries, 2, "Unexpected export result")
+
+	assert.NoError(t, exporter.Sync(), "Unexpected sync error")
+	assert.NoError(t, exporter.Close(), "Unexpected close error")
+}
+
+func TestMatchName(t *testing.T) {
+	predicate := MatchName("audit", "security")
+	assert.True(t, predicate(&Entry { Name: "security" }),
+		"Unexpected match result")
+	assert.False(t, predicate(&Entry { Name: "http" }),
+		"Unexpected match result")
+}
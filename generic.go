@@ -0,0 +1,152 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build go1.18
+// +build go1.18
+
+package santa
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Signed is a constraint that permits any signed integer type.
+type Signed interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64
+}
+
+// Unsigned is a constraint that permits any unsigned integer type.
+type Unsigned interface {
+	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Float is a constraint that permits any floating-point type.
+type Float interface {
+	~float32 | ~float64
+}
+
+// Number is a constraint that permits any integer or floating-point type.
+type Number interface {
+	Signed | Unsigned | Float
+}
+
+// Ordered is a constraint that permits any type that supports the ordered
+// operators, which are the number types and the string types.
+type Ordered interface {
+	Number | ~string
+}
+
+// kindOf returns the kind of the underlying type of the given type
+// parameter, without allocating any value of the type.
+func kindOf[T any]() reflect.Kind {
+	return reflect.TypeOf((*T)(nil)).Elem().Kind()
+}
+
+// Num returns the value of a field with a given name and a given value of
+// any number type. Signed integers are mapped to the Int function, unsigned
+// integers are mapped to the Uint function, and floating-point numbers are
+// mapped to the Float32 or Float64 function according to their size. For
+// details, see the comments section of the Field structure.
+func Num[T Number](name string, value T) Field {
+	switch kindOf[T]() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return Int(name, int64(value))
+	case reflect.Float32:
+		return Float32(name, float32(value))
+	case reflect.Float64:
+		return Float64(name, float64(value))
+	}
+	return Uint(name, uint64(value))
+}
+
+// Slice returns the value of a field with a given name and a given slice
+// of any ordered type. The slice is mapped to the Ints, Uints, Float32s,
+// Float64s or Strings function in the same way as the Num function. If the
+// underlying type of the elements is exactly the element type of the mapped
+// function, the given slice is referenced directly instead of being copied.
+// For details, see the comments section of the Field structure. If the
+// given slice is nil, the value of the field is null.
+func Slice[T Ordered](name string, values []T) Field {
+	if values == nil {
+		return Nil(name)
+	}
+	// The underlying type of the elements is known from the kind, so the
+	// elements are reinterpreted through unsafe pointers instead of being
+	// converted, which is not allowed for the ordered constraint.
+	pointer := unsafe.Pointer(&values)
+	switch kind := kindOf[T](); kind {
+	case reflect.Int64:
+		return Ints(name, *(*[]int64)(pointer))
+	case reflect.Uint64:
+		return Uints(name, *(*[]uint64)(pointer))
+	case reflect.Float32:
+		return Float32s(name, *(*[]float32)(pointer))
+	case reflect.Float64:
+		return Float64s(name, *(*[]float64)(pointer))
+	case reflect.String:
+		return Strings(name, *(*[]string)(pointer))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		converted := make([]int64, len(values))
+		for index := range values {
+			converted[index] = signedAt(kind, unsafe.Pointer(&values[index]))
+		}
+		return Ints(name, converted)
+	default:
+		converted := make([]uint64, len(values))
+		for index := range values {
+			converted[index] = unsignedAt(kind, unsafe.Pointer(&values[index]))
+		}
+		return Uints(name, converted)
+	}
+}
+
+// signedAt returns the signed integer of the given kind at the given
+// pointer as an int64 value.
+func signedAt(kind reflect.Kind, pointer unsafe.Pointer) int64 {
+	switch kind {
+	case reflect.Int8:
+		return int64(*(*int8)(pointer))
+	case reflect.Int16:
+		return int64(*(*int16)(pointer))
+	case reflect.Int32:
+		return int64(*(*int32)(pointer))
+	}
+	return int64(*(*int)(pointer))
+}
+
+// unsignedAt returns the unsigned integer of the given kind at the given
+// pointer as an uint64 value.
+func unsignedAt(kind reflect.Kind, pointer unsafe.Pointer) uint64 {
+	switch kind {
+	case reflect.Uint8:
+		return uint64(*(*uint8)(pointer))
+	case reflect.Uint16:
+		return uint64(*(*uint16)(pointer))
+	case reflect.Uint32:
+		return uint64(*(*uint32)(pointer))
+	case reflect.Uintptr:
+		return uint64(*(*uintptr)(pointer))
+	}
+	return uint64(*(*uint)(pointer))
+}
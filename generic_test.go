@@ -0,0 +1,82 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build go1.18
+// +build go1.18
+
+package santa
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPort uint16
+
+type testName string
+
+func TestNum(t *testing.T) {
+	assert.Equal(t, Int("value", -1), Num("value", int8(-1)),
+		"Unexpected field")
+	assert.Equal(t, Int("value", 100), Num("value", 100),
+		"Unexpected field")
+	assert.Equal(t, Int("value", int64(time.Second)), Num("value",
+		time.Second), "Unexpected field")
+	assert.Equal(t, Uint("value", 8080), Num("value", testPort(8080)),
+		"Unexpected field")
+	assert.Equal(t, Uint("value", 1), Num("value", uintptr(1)),
+		"Unexpected field")
+	assert.Equal(t, Float32("value", 1.5), Num("value", float32(1.5)),
+		"Unexpected field")
+	assert.Equal(t, Float64("value", 1.5), Num("value", 1.5),
+		"Unexpected field")
+}
+
+func TestSlice(t *testing.T) {
+	assert.Equal(t, Nil("values"), Slice[int]("values", nil),
+		"Unexpected field")
+
+	fields := ElementObject {
+		Slice("ints", []int { -1, 2 }),
+		Slice("int8s", []int8 { -1, 2 }),
+		Slice("int64s", []int64 { -1, 2 }),
+		Slice("ports", []testPort { 80, 443 }),
+		Slice("uint64s", []uint64 { 1, 2 }),
+		Slice("float32s", []float32 { 1.5 }),
+		Slice("float64s", []float64 { 1.5 }),
+		Slice("names", []testName { "a", "b" }),
+		Slice("empty", []string { }),
+	}
+	assert.Equal(t, `{"ints": [-1, 2], "int8s": [-1, 2], `+
+		`"int64s": [-1, 2], "ports": [80, 443], "uint64s": [1, 2], `+
+		`"float32s": [1.5], "float64s": [1.5], "names": ["a", "b"], `+
+		`"empty": []}`, string(fields.SerializeJSON(nil)),
+		"Unexpected fields")
+
+	values := []int64 { 1 }
+	field := Slice("values", values)
+	values[0] = 2
+	assert.Equal(t, "[2]", string(field.SerializeJSON(nil)),
+		"Unexpected referenced slice")
+}
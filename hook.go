@@ -22,6 +22,15 @@
 
 package santa
 
+import (
+	"container/list"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
 // Hook is the public interface of Hook.
 //
 // Hook is an event callback mechanism. Any Hook type instance that
@@ -41,6 +50,11 @@ type Hook interface {
 	// the given log entry will be cancelled.
 	//
 	// Hook instances can modify log entries during this process.
+	//
+	// Please note that the log entry is only valid until the function
+	// returns. If the log entry needs to be retained (for example, to
+	// process it asynchronously), retain a copy created by the Clone
+	// function of the Entry structure.
 	Print(entry *Entry) error
 }
 
@@ -72,3 +86,95 @@ func NewSimpleHook(handler SimpleHookHandler) *SimpleHook {
 func (h *SimpleHook) Print(entry *Entry) error {
 	return h.handler(entry)
 }
+
+// processInfoCacheCapacity is the maximum number of merged label sets
+// cached by a process information hook.
+const processInfoCacheCapacity = 64
+
+// ProcessInfoHook is a structure that contains the labels of the process
+// information.
+//
+// The process information hook adds the labels of the process information
+// to each log entry, which includes the host name ("hostname"), process
+// identifier ("pid"), executable name ("executable") and runtime version
+// ("goVersion") of the process. The process information is gathered and
+// serialized once when the hook is created.
+//
+// The labels of the log entry are kept, and the label sets merged with the
+// labels of the process information are cached, so the labels of the
+// same logger are not serialized repeatedly. The least recently used label
+// set is evicted when the cache is full.
+type ProcessInfoHook struct {
+	labels SerializedLabels
+	mutex sync.Mutex
+	cache map[string]*list.Element
+	order list.List
+}
+
+// processInfoCacheEntry is a structure that contains a label set merged
+// with the labels of the process information and the serialized data of
+// the original label set, which is its key in the cache.
+type processInfoCacheEntry struct {
+	key string
+	labels SerializedLabels
+}
+
+// Print adds the labels of the process information to the given log
+// entry, and then returns nil.
+func (h *ProcessInfoHook) Print(entry *Entry) error {
+	if entry.Labels.Count() == 0 {
+		entry.Labels = h.labels
+		return nil
+	}
+	// The serialized data identifies the label set, and is copied into
+	// the key, so the cache does not retain the buffers of loggers.
+	key := entry.Labels.jsonBuffer
+	h.mutex.Lock()
+	if element, ok := h.cache[string(key)]; ok {
+		h.order.MoveToFront(element)
+		entry.Labels = element.Value.(*processInfoCacheEntry).labels
+		h.mutex.Unlock()
+		return nil
+	}
+	h.mutex.Unlock()
+	labels := entry.Labels.Append(h.labels.Labels()...)
+	h.mutex.Lock()
+	if _, ok := h.cache[string(key)]; !ok {
+		if h.order.Len() >= processInfoCacheCapacity {
+			oldest := h.order.Back()
+			h.order.Remove(oldest)
+			delete(h.cache, oldest.Value.(*processInfoCacheEntry).key)
+		}
+		cached := &processInfoCacheEntry {
+			key: string(key),
+			labels: labels,
+		}
+		h.cache[cached.key] = h.order.PushFront(cached)
+	}
+	h.mutex.Unlock()
+	entry.Labels = labels
+	return nil
+}
+
+// Labels returns the labels of the process information.
+func (h *ProcessInfoHook) Labels() Labels {
+	return h.labels.Labels()
+}
+
+// NewProcessInfoHook gathers the process information, and then creates
+// and returns a process information hook instance. If the host name
+// cannot be obtained, the host name label is omitted.
+func NewProcessInfoHook() *ProcessInfoHook {
+	labels := make(Labels, 0, 4)
+	if hostname, err := os.Hostname(); err == nil {
+		labels = append(labels, NewLabel("hostname", hostname))
+	}
+	labels = append(labels, NewLabel("pid", strconv.Itoa(os.Getpid())))
+	labels = append(labels, NewLabel("executable", filepath.Base(
+		os.Args[0])))
+	labels = append(labels, NewLabel("goVersion", runtime.Version()))
+	return &ProcessInfoHook {
+		labels: NewSerializedLabels(labels...),
+		cache: make(map[string]*list.Element, processInfoCacheCapacity),
+	}
+}
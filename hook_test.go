@@ -24,6 +24,9 @@ package santa
 
 import (
 	"errors"
+	"os"
+	"runtime"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -43,3 +46,65 @@ func TestSimpleHook(t *testing.T) {
 	assert.Equal(t, "Error", err.Error(), "Unexpected return value")
 	assert.Equal(t, true, succeed, "Hook handler is not called")
 }
+
+func TestProcessInfoHook(t *testing.T) {
+	hook := NewProcessInfoHook()
+	assert.NotNil(t, hook, "Unexpected create error")
+
+	labels := hook.Labels()
+	assert.Contains(t, labels, NewLabel("pid", strconv.Itoa(os.Getpid())),
+		"Unexpected process label")
+	assert.Contains(t, labels, NewLabel("goVersion", runtime.Version()),
+		"Unexpected process label")
+
+	entry := &Entry { }
+	assert.NoError(t, hook.Print(entry), "Unexpected print error")
+	assert.Equal(t, labels, entry.Labels.Labels(), "Unexpected labels")
+
+	serialized := NewSerializedLabels(NewLabel("instanceId", "1"))
+	for index := 0; index < 2; index++ {
+		entry = &Entry { Labels: serialized }
+		assert.NoError(t, hook.Print(entry), "Unexpected print error")
+		assert.Equal(t, len(labels) + 1, entry.Labels.Count(),
+			"Unexpected labels")
+		assert.Equal(t, NewLabel("instanceId", "1"),
+			entry.Labels.Labels()[0], "Unexpected labels")
+	}
+	assert.Len(t, hook.cache, 1, "Unexpected cached labels")
+}
+
+func TestProcessInfoHookCache(t *testing.T) {
+	hook := NewProcessInfoHook()
+	first := NewSerializedLabels(NewLabel("instanceId", "first"))
+	assert.NoError(t, hook.Print(&Entry { Labels: first }),
+		"Unexpected print error")
+
+	// The cache is bounded, and the recently used label sets are kept.
+	for index := 0; index < processInfoCacheCapacity * 4; index++ {
+		entry := &Entry {
+			Labels: NewSerializedLabels(NewLabel("instanceId",
+				strconv.Itoa(index))),
+		}
+		assert.NoError(t, hook.Print(entry), "Unexpected print error")
+		assert.Equal(t, NewLabel("instanceId", strconv.Itoa(index)),
+			entry.Labels.Labels()[0], "Unexpected labels")
+		assert.NoError(t, hook.Print(&Entry { Labels: first }),
+			"Unexpected print error")
+	}
+	assert.Len(t, hook.cache, processInfoCacheCapacity,
+		"Unexpected cached labels")
+	assert.Equal(t, processInfoCacheCapacity, hook.order.Len(),
+		"Unexpected cached labels")
+	assert.Contains(t, hook.cache, string(first.jsonBuffer),
+		"Unexpected evicted labels")
+
+	// Label sets with the same data share the cached label set.
+	entry := &Entry {
+		Labels: NewSerializedLabels(NewLabel("instanceId", "first")),
+	}
+	assert.NoError(t, hook.Print(entry), "Unexpected print error")
+	assert.Equal(t, NewLabel("instanceId", "first"),
+		entry.Labels.Labels()[0], "Unexpected labels")
+	assert.Len(t, hook.cache, processInfoCacheCapacity,
+		"Unexpected cached labels")
+}
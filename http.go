@@ -0,0 +1,116 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HTTPRequest returns the value of a field with a given name and a given
+// HTTP request, which is an object containing the method, URL, size, user
+// agent, remote IP address, referer and protocol of the request. The names
+// of the fields match the HttpRequest structure of the structured logging
+// of Google Cloud Platform, and empty values and unknown sizes are omitted.
+// For details, see the comments section of the Field structure. If the
+// given request is nil, the value of the field is null.
+//
+// The remote IP address is obtained from the RemoteAddr field of the
+// request, so it is the address of the proxy if the request is forwarded.
+func HTTPRequest(name string, request *http.Request) Field {
+	if request == nil {
+		return Nil(name)
+	}
+	return Object(name, appendHTTPRequest(make([]Field, 0, 7), request)...)
+}
+
+// HTTPResponse returns the value of a field with a given name, a given
+// HTTP response and a given latency, which is an object containing the
+// status, size, latency and protocol of the response, and the method and
+// URL of the request of the response (if any). The latency is encoded as
+// a string of seconds with the "s" suffix, for example "0.25s". For
+// details, see the comments section of the HTTPRequest function. If the
+// given response is nil, the value of the field is null.
+func HTTPResponse(name string, response *http.Response, latency time.Duration) Field {
+	if response == nil {
+		return Nil(name)
+	}
+	fields := make([]Field, 0, 6)
+	if response.Request != nil {
+		fields = append(fields,
+			String("requestMethod", response.Request.Method),
+			String("requestUrl", response.Request.URL.String()),
+		)
+	}
+	fields = append(fields, Int("status", int64(response.StatusCode)))
+	if response.ContentLength >= 0 {
+		fields = append(fields, String("responseSize", strconv.FormatInt(
+			response.ContentLength, 10)))
+	}
+	fields = append(fields,
+		String("latency", formatHTTPLatency(latency)),
+		String("protocol", response.Proto),
+	)
+	return Object(name, fields...)
+}
+
+// appendHTTPRequest appends the fields of the given HTTP request to the
+// given fields slice, and then returns the appended fields slice.
+func appendHTTPRequest(fields []Field, request *http.Request) []Field {
+	fields = append(fields,
+		String("requestMethod", request.Method),
+		String("requestUrl", request.URL.String()),
+	)
+	if request.ContentLength > 0 {
+		fields = append(fields, String("requestSize", strconv.FormatInt(
+			request.ContentLength, 10)))
+	}
+	if agent := request.UserAgent(); len(agent) > 0 {
+		fields = append(fields, String("userAgent", agent))
+	}
+	if address := httpRemoteIP(request); len(address) > 0 {
+		fields = append(fields, String("remoteIp", address))
+	}
+	if referer := request.Referer(); len(referer) > 0 {
+		fields = append(fields, String("referer", referer))
+	}
+	return append(fields, String("protocol", request.Proto))
+}
+
+// httpRemoteIP returns the IP address of the RemoteAddr field of the given
+// HTTP request without the port.
+func httpRemoteIP(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+	return host
+}
+
+// formatHTTPLatency formats the given latency as a string of seconds with
+// the "s" suffix.
+func formatHTTPLatency(latency time.Duration) string {
+	return strconv.FormatFloat(latency.Seconds(), 'f', -1, 64) + "s"
+}
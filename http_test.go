@@ -0,0 +1,93 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPRequest(t *testing.T) {
+	request := httptest.NewRequest("POST", "/users?id=1",
+		strings.NewReader("test"))
+	request.RemoteAddr = "192.0.2.1:1234"
+	request.Header.Set("User-Agent", "santa")
+	request.Header.Set("Referer", "https://example.com/")
+	fields := ElementObject {
+		HTTPRequest("request", request),
+	}
+	assert.Equal(t, `{"request": {"requestMethod": "POST", "requestUrl": ` +
+		`"/users?id=1", "requestSize": "4", "userAgent": "santa", ` +
+		`"remoteIp": "192.0.2.1", "referer": "https://example.com/", ` +
+		`"protocol": "HTTP/1.1"}}`, string(fields.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+
+	request = httptest.NewRequest("GET", "/", nil)
+	request.RemoteAddr = "unix"
+	fields = ElementObject {
+		HTTPRequest("request", request),
+	}
+	assert.Equal(t, `{"request": {"requestMethod": "GET", "requestUrl": ` +
+		`"/", "remoteIp": "unix", "protocol": "HTTP/1.1"}}`,
+		string(fields.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+
+	assert.Equal(t, TypeNil, HTTPRequest("request", nil).Type,
+		"Unexpected HTTP request field type")
+}
+
+func TestHTTPResponse(t *testing.T) {
+	response := &http.Response {
+		StatusCode: http.StatusOK,
+		Proto: "HTTP/1.1",
+		ContentLength: 128,
+		Request: httptest.NewRequest("GET", "/users", nil),
+	}
+	fields := ElementObject {
+		HTTPResponse("response", response, 1500 * time.Millisecond),
+	}
+	assert.Equal(t, `{"response": {"requestMethod": "GET", "requestUrl": ` +
+		`"/users", "status": 200, "responseSize": "128", "latency": ` +
+		`"1.5s", "protocol": "HTTP/1.1"}}`, string(fields.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+
+	response = &http.Response {
+		StatusCode: http.StatusNotFound,
+		Proto: "HTTP/2.0",
+		ContentLength: -1,
+	}
+	fields = ElementObject {
+		HTTPResponse("response", response, 0),
+	}
+	assert.Equal(t, `{"response": {"status": 404, "latency": "0s", ` +
+		`"protocol": "HTTP/2.0"}}`, string(fields.SerializeJSON(nil)),
+		"Unexpected JSON formatted append result")
+
+	assert.Equal(t, TypeNil, HTTPResponse("response", nil, 0).Type,
+		"Unexpected HTTP response field type")
+}
@@ -0,0 +1,363 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"encoding/binary"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// journalFieldLength is the maximum length of the name of a journal
+// field accepted by the systemd journal.
+const journalFieldLength = 64
+
+// JournalEncoder is the structure of the journal encoder instance.
+//
+// The journal encoder encodes log entries into the native protocol of
+// the systemd journal, in which each log entry is a set of fields in
+// the form of NAME=value separated by newlines. The level of the log
+// entry is encoded as the PRIORITY field, the message text as the
+// MESSAGE field, the name as the SYSLOG_IDENTIFIER field, and the
+// source location as the CODE_FILE, CODE_LINE and CODE_FUNC fields.
+// Each label and each top-level field of structured messages is
+// encoded as a separate journal field, so that they can be queried
+// with journalctl (for example, journalctl USER=alice).
+//
+// The names of labels and fields are converted to the journal field
+// name rules: letters are converted to uppercase, characters other
+// than letters, digits and underscores are replaced by underscores,
+// leading underscores are removed (they are reserved for trusted
+// fields), and names are truncated to 64 characters. Values that
+// contain newlines are encoded in the binary form of the protocol.
+//
+// The time of the log entry is not encoded, because the journal
+// records the time of reception of each log entry by itself.
+type JournalEncoder struct {
+	option EncoderOption
+}
+
+// Encode encodes a given log entry into a set of journal fields, then
+// appends to the given buffer slice, and finally returns the appended
+// buffer slice.
+func (e *JournalEncoder) Encode(buffer []byte, entry *Entry) ([]byte, error) {
+	if e.option.EncodeLevel {
+		buffer = append(buffer, "PRIORITY="...)
+		buffer = strconv.AppendInt(buffer, int64(journalPriority(
+			entry.Level)), 10)
+		buffer = append(buffer, '\n')
+	}
+	if e.option.EncodeName && len(entry.Name) > 0 {
+		buffer = appendJournalValue(buffer, "SYSLOG_IDENTIFIER",
+			entry.Name)
+	}
+	if e.option.SourceLocationEnabled(entry.Level) &&
+		entry.SourceLocation.Parsed {
+		buffer = appendJournalValue(buffer, "CODE_FILE",
+			entry.SourceLocation.File)
+		buffer = append(buffer, "CODE_LINE="...)
+		buffer = strconv.AppendInt(buffer, int64(
+			entry.SourceLocation.Line), 10)
+		buffer = append(buffer, '\n')
+		if function := entry.SourceLocation.FunctionName();
+			len(function) > 0 {
+			buffer = appendJournalValue(buffer, "CODE_FUNC", function)
+		}
+	}
+	if e.option.EncodeLabels {
+		for _, label := range entry.Labels.Labels() {
+			buffer = e.appendField(buffer, label.Key, Element {
+				Type: TypeString,
+				String: label.Value,
+			})
+		}
+	}
+	if entry.Sequence > 0 {
+		buffer = append(buffer, "SEQUENCE="...)
+		buffer = strconv.AppendUint(buffer, entry.Sequence, 10)
+		buffer = append(buffer, '\n')
+	}
+	switch message := entry.Message.(type) {
+	case nil:
+		return buffer, nil
+	case StructMessage:
+		buffer = e.appendFields(buffer, message.Fields)
+	case *StructMessage:
+		buffer = e.appendFields(buffer, message.Fields)
+	}
+	return appendJournalValue(buffer, "MESSAGE",
+		messageText(entry.Message)), nil
+}
+
+// Option returns the value of the basic options of the encoder, and the
+// application can optimize the actual behavior by checking the values
+// of the options.
+func (e *JournalEncoder) Option() EncoderOption {
+	return e.option
+}
+
+// appendFields appends the given fields of a structured message as
+// journal fields to the given buffer slice, and then returns the
+// appended buffer slice. The names of fields grouped into namespaces are
+// prefixed with the names of the namespaces.
+func (e *JournalEncoder) appendFields(buffer []byte, fields ElementObject) []byte {
+	prefix := ""
+	for _, field := range fields {
+		if field.Type == TypeNamespace {
+			prefix += field.Name + "_"
+			continue
+		}
+		buffer = e.appendField(buffer, prefix + field.Name, field.Element)
+	}
+	return buffer
+}
+
+// appendField appends the given element as a journal field with the
+// given name to the given buffer slice, and then returns the appended
+// buffer slice. String elements are encoded as they are, and elements
+// of other types are encoded as JSON values. If the name does not
+// contain any valid character or the element is omitted according to the
+// FloatPolicy option, the field is skipped.
+func (e *JournalEncoder) appendField(buffer []byte, name string,
+	element Element) []byte {
+	option := SerializerOption {
+		EncoderOption: e.option,
+	}
+	if element.omitted(&option) {
+		return buffer
+	}
+	offset := len(buffer)
+	buffer = appendJournalName(buffer, name)
+	if len(buffer) == offset {
+		return buffer
+	}
+	if element.Type == TypeRaw {
+		// Raw JSON values may contain newline characters.
+		return appendJournalData(buffer, string(element.SerializeJSONOption(
+			nil, &option)))
+	}
+	if element.Type != TypeString {
+		// JSON values never contain a raw newline character, so the
+		// simple form of the protocol can always be used.
+		buffer = append(buffer, '=')
+		buffer = element.SerializeJSONOption(buffer, &option)
+		return append(buffer, '\n')
+	}
+	value := element.String
+	if limit := e.option.MaxFieldBytes; limit > 0 && len(value) > limit {
+		value = value[ : truncateIndex(value, limit)] + "…(" +
+			strconv.Itoa(len(value)) + " bytes)"
+	}
+	return appendJournalData(buffer, value)
+}
+
+// journalPriority returns the syslog priority corresponding to the
+// given log level, which is used as the value of the PRIORITY field.
+func journalPriority(level Level) int {
+	switch level {
+	case LevelDebug:
+		return 7
+	case LevelInfo:
+		return 6
+	case LevelWarning:
+		return 4
+	case LevelError:
+		return 3
+	}
+	return 2
+}
+
+// appendJournalName appends the given name converted to a valid journal
+// field name to the given buffer slice, and then returns the appended
+// buffer slice. If the name does not contain any valid character, the
+// buffer slice is returned unchanged.
+func appendJournalName(buffer []byte, name string) []byte {
+	name = strings.TrimLeft(name, "_")
+	if len(name) == 0 {
+		return buffer
+	}
+	length := 0
+	if name[0] >= '0' && name[0] <= '9' {
+		// Field names must not start with a digit.
+		buffer = append(buffer, 'F')
+		length++
+	}
+	for index := 0; index < len(name) && length < journalFieldLength;
+		index++ {
+		char := name[index]
+		switch {
+		case char >= 'a' && char <= 'z':
+			char -= 'a' - 'A'
+		case char >= 'A' && char <= 'Z', char >= '0' && char <= '9':
+		default:
+			char = '_'
+		}
+		buffer = append(buffer, char)
+		length++
+	}
+	return buffer
+}
+
+// appendJournalValue appends the given value as a journal field with the
+// given valid field name to the given buffer slice, and then returns the
+// appended buffer slice.
+func appendJournalValue(buffer []byte, name string, value string) []byte {
+	return appendJournalData(append(buffer, name...), value)
+}
+
+// appendJournalData appends the given value of a journal field whose name
+// has been appended to the given buffer slice, and then returns the
+// appended buffer slice. Values that contain newlines are encoded in the
+// binary form, in which the name is followed by a newline, the length of
+// the value as a 64-bit little-endian integer, and the value itself.
+func appendJournalData(buffer []byte, value string) []byte {
+	if strings.IndexByte(value, '\n') < 0 {
+		buffer = append(buffer, '=')
+		buffer = append(buffer, value...)
+		return append(buffer, '\n')
+	}
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[ : ], uint64(len(value)))
+	buffer = append(buffer, '\n')
+	buffer = append(buffer, length[ : ]...)
+	buffer = append(buffer, value...)
+	return append(buffer, '\n')
+}
+
+// JournalEncoderOption is a structure containing options for the journal
+// encoder.
+type JournalEncoderOption struct {
+	EncoderOption
+}
+
+// UseEncoderOption uses the given encoder option as part of the journal
+// encoder option. The EncodeTime and UTC options have no effect. For
+// details, please refer to the comment section of the EncoderOption
+// structure. Then return to the option instance itself.
+func (o *JournalEncoderOption) UseEncoderOption(option EncoderOption) *JournalEncoderOption {
+	o.EncoderOption = option
+	return o
+}
+
+// Build builds and returns an instance of the journal encoder.
+func (o *JournalEncoderOption) Build() (*JournalEncoder, error) {
+	return &JournalEncoder {
+		option: o.EncoderOption,
+	}, nil
+}
+
+// NewJournalEncoderOption creates and returns a journal encoder option
+// instance with default optional values.
+func NewJournalEncoderOption() *JournalEncoderOption {
+	return &JournalEncoderOption {
+		EncoderOption: NewEncoderOption(),
+	}
+}
+
+// NewJournalEncoder creates and returns a journal encoder instance using
+// the default optional values.
+func NewJournalEncoder() (*JournalEncoder, error) {
+	return NewJournalEncoderOption().Build()
+}
+
+// JournalSyncer is the structure of the journal synchronizer instance.
+//
+// The journal synchronizer is based on the network synchronizer, and
+// sends each log entry as a separate datagram to the native protocol
+// socket of the systemd journal. It should be used together with the
+// journal encoder, for example:
+//
+//	encoder, _ := santa.NewJournalEncoder()
+//	syncer, _ := santa.NewJournalSyncer()
+//	exporter, _ := santa.NewStandardExporterOption().
+//		UseEncoder(encoder).UseSyncer(syncer).Build()
+//
+// Please note that the size of each datagram is limited by the socket
+// send buffer, and oversized log entries fail to be written. Use the
+// MaxEntryBytes option of the exporter or the MaxFieldBytes option of
+// the encoder to limit the size of log entries.
+type JournalSyncer struct {
+	*NetworkSyncer
+}
+
+// JournalSyncerOption is a structure that contains options for journal
+// synchronizers.
+type JournalSyncerOption struct {
+	// Address represents the path of the native protocol socket of the
+	// systemd journal. If not provided, the default value is
+	// /run/systemd/journal/socket.
+	Address string
+
+	// WriteTimeout represents the maximum time that each write of a log
+	// entry may take. For details, please refer to the comment section
+	// of the NetworkSyncerOption structure. If not provided, the default
+	// value is 5 seconds.
+	WriteTimeout time.Duration
+}
+
+// UseAddress uses the given path as the value of the option Address.
+// For details, please refer to the comment section of the Address option.
+// Then return to the option instance itself.
+func (o *JournalSyncerOption) UseAddress(address string) *JournalSyncerOption {
+	o.Address = address
+	return o
+}
+
+// UseWriteTimeout uses the given timeout as the value of the option
+// WriteTimeout. For details, please refer to the comment section of the
+// WriteTimeout option. Then return to the option instance itself.
+func (o *JournalSyncerOption) UseWriteTimeout(timeout time.Duration) *JournalSyncerOption {
+	o.WriteTimeout = timeout
+	return o
+}
+
+// Build builds and returns a journal synchronizer instance.
+func (o *JournalSyncerOption) Build() (*JournalSyncer, error) {
+	syncer, err := NewNetworkSyncerOption().
+		UseProtocol(ProtocolUnixgram).
+		UseAddress(o.Address).
+		UseWriteTimeout(o.WriteTimeout).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	return &JournalSyncer {
+		NetworkSyncer: syncer,
+	}, nil
+}
+
+// NewJournalSyncerOption creates and returns an option instance of the
+// journal synchronizer with default optional values.
+func NewJournalSyncerOption() *JournalSyncerOption {
+	return &JournalSyncerOption {
+		Address: "/run/systemd/journal/socket",
+		WriteTimeout: time.Second * 5,
+	}
+}
+
+// NewJournalSyncer creates and returns a journal synchronizer instance
+// using the default optional values.
+func NewJournalSyncer() (*JournalSyncer, error) {
+	return NewJournalSyncerOption().Build()
+}
@@ -0,0 +1,178 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"io/ioutil"
+	"math"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalEncoder(t *testing.T) {
+	encoder, err := NewJournalEncoder()
+	assert.NoError(t, err, "Unexpected create error")
+
+	entry := &Entry {
+		Level: LevelWarning,
+		Message: StructMessage {
+			Text: "Hello Test!",
+			Fields: ElementObject {
+				String("user-name", "alice"),
+				Int("2fa", 1),
+				String("_trusted", "no"),
+			},
+		},
+		Name: "test",
+		Labels: NewSerializedLabels(NewLabel("module", "santa")),
+	}
+	buffer, err := encoder.Encode(nil, entry)
+	assert.NoError(t, err, "Unexpected encode error")
+	assert.Equal(t, "PRIORITY=4\nSYSLOG_IDENTIFIER=test\nMODULE=santa\n" +
+		"USER_NAME=alice\nF2FA=1\nTRUSTED=no\nMESSAGE=Hello Test!\n",
+		string(buffer), "Unexpected encode result")
+
+	// Values containing newlines use the binary form.
+	entry = &Entry {
+		Level: LevelError,
+		Message: StringMessage("a\nb"),
+	}
+	buffer, err = encoder.Encode(nil, entry)
+	assert.NoError(t, err, "Unexpected encode error")
+	assert.Equal(t, "PRIORITY=3\nMESSAGE\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\n",
+		string(buffer), "Unexpected encode result")
+}
+
+func TestJournalEncoderFloatPolicy(t *testing.T) {
+	option := NewJournalEncoderOption()
+	option.FloatPolicy = FloatOmit
+	encoder, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	entry := &Entry {
+		Level: LevelInfo,
+		Message: StructMessage {
+			Fields: ElementObject {
+				Float64("nan", math.NaN()),
+				Float64("ratio", 0.5),
+			},
+		},
+	}
+	buffer, err := encoder.Encode(nil, entry)
+	assert.NoError(t, err, "Unexpected encode error")
+	assert.Equal(t, "PRIORITY=6\nRATIO=0.5\nMESSAGE=\n", string(buffer),
+		"Unexpected encode result")
+}
+
+func TestJournalEncoderNamespace(t *testing.T) {
+	encoder, err := NewJournalEncoder()
+	assert.NoError(t, err, "Unexpected create error")
+
+	entry := &Entry {
+		Level: LevelInfo,
+		Message: StructMessage {
+			Fields: ElementObject {
+				String("id", "1"),
+				Namespace("user"),
+				String("name", "alice"),
+			},
+		},
+	}
+	buffer, err := encoder.Encode(nil, entry)
+	assert.NoError(t, err, "Unexpected encode error")
+	assert.Equal(t, "PRIORITY=6\nID=1\nUSER_NAME=alice\nMESSAGE=\n",
+		string(buffer), "Unexpected encode result")
+}
+
+func TestJournalEncoderRawJSON(t *testing.T) {
+	encoder, err := NewJournalEncoder()
+	assert.NoError(t, err, "Unexpected create error")
+
+	entry := &Entry {
+		Level: LevelInfo,
+		Message: StructMessage {
+			Fields: ElementObject {
+				RawJSON("compact", []byte(`{"a":1}`)),
+				RawJSON("indented", []byte("{\n}")),
+			},
+		},
+	}
+	buffer, err := encoder.Encode(nil, entry)
+	assert.NoError(t, err, "Unexpected encode error")
+	assert.Equal(t, "PRIORITY=6\nCOMPACT={\"a\":1}\nINDENTED\n" +
+		"\x03\x00\x00\x00\x00\x00\x00\x00{\n}\nMESSAGE=\n",
+		string(buffer), "Unexpected encode result")
+}
+
+func TestJournalEncoderName(t *testing.T) {
+	name := appendJournalName(nil, "__")
+	assert.Empty(t, name, "Unexpected field name")
+
+	long := make([]byte, 100)
+	for index := range long {
+		long[index] = 'a'
+	}
+	name = appendJournalName(nil, string(long))
+	assert.Len(t, name, journalFieldLength, "Unexpected field name")
+}
+
+func TestJournalSyncer(t *testing.T) {
+	if !unixSocketsSupported {
+		t.Skip("Unix Domain Sockets are unsupported")
+	}
+	directory, err := ioutil.TempDir("", "santa")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	address := directory + "/socket"
+	listener, err := net.ListenUnixgram(ProtocolUnixgram, &net.UnixAddr {
+		Name: address,
+		Net: ProtocolUnixgram,
+	})
+	assert.NoError(t, err, "Unexpected listen error")
+	defer listener.Close()
+
+	syncer, err := NewJournalSyncerOption().UseAddress(address).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	encoder, err := NewJournalEncoder()
+	assert.NoError(t, err, "Unexpected create error")
+	exporter, err := NewStandardExporterOption().UseEncoder(encoder).
+		UseSyncer(syncer).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	err = exporter.Export(&Entry {
+		Level: LevelInfo,
+		Message: StringMessage("Hello Test!"),
+	})
+	assert.NoError(t, err, "Unexpected export error")
+
+	buffer := make([]byte, 1024)
+	size, err := listener.Read(buffer)
+	assert.NoError(t, err, "Unexpected read error")
+	assert.Equal(t, "PRIORITY=6\nMESSAGE=Hello Test!\n",
+		string(buffer[ : size]), "Unexpected datagram")
+	assert.NoError(t, exporter.Close(), "Unexpected close error")
+}
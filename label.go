@@ -103,6 +103,7 @@ func (l Labels) SerializeStandard(buffer []byte) []byte {
 // For details, please refer to the notes section of the Labels structure.
 type SerializedLabels struct {
 	count int
+	labels Labels
 	jsonBuffer []byte
 }
 
@@ -111,6 +112,20 @@ func (l SerializedLabels) Count() int {
 	return l.count
 }
 
+// Labels returns the labels before serialization. The returned labels
+// should not be modified, otherwise the behavior is undefined.
+func (l SerializedLabels) Labels() Labels {
+	return l.labels
+}
+
+// Append returns a new SerializedLabels value that contains the labels
+// and the given one or more labels. The labels are not modified.
+func (l SerializedLabels) Append(labels ...Label) SerializedLabels {
+	merged := make(Labels, 0, len(l.labels) + len(labels))
+	merged = append(merged, l.labels...)
+	return NewSerializedLabels(append(merged, labels...)...)
+}
+
 // SerializeJSON appends a set of serialized label JSON strings to the
 // given buffer slice, and then returns the appended buffer slice.
 func (l SerializedLabels) SerializeJSON(buffer []byte) []byte {
@@ -129,6 +144,7 @@ func (l SerializedLabels) SerializeStandard(buffer []byte) []byte {
 func NewSerializedLabels(labels ...Label) SerializedLabels {
 	return SerializedLabels {
 		count: len(labels),
+		labels: labels,
 		jsonBuffer: Labels(labels).SerializeJSON(make([]byte, 0, 256)),
 	}
 }
@@ -57,8 +57,21 @@ var (
 	// ErrInvalidLevel represents the log level is invalid. This is
 	// usually because the given log level is invalid.
 	ErrInvalidLevel = errors.New("invalid level")
+
+	// ErrInvalidSpan represents the log level span is invalid. This is
+	// usually because the starting level is after the end level.
+	ErrInvalidSpan = errors.New("invalid level span")
 )
 
+// Validate checks whether the log level is one of the defined log levels,
+// and returns an error wrapping ErrInvalidLevel if not.
+func (l Level) Validate() error {
+	if l > LevelFatal {
+		return fmt.Errorf("%w: %d", ErrInvalidLevel, l)
+	}
+	return nil
+}
+
 // Enabled checks whether the given log level is enabled.
 func (l Level) Enabled(level Level) bool {
 	return l <= level
@@ -147,6 +160,23 @@ type LevelSpan struct {
 	End Level
 }
 
+// Validate checks whether the levels of the span are valid and the
+// starting level is not after the end level, and returns an error wrapping
+// ErrInvalidLevel or ErrInvalidSpan if not.
+func (l LevelSpan) Validate() error {
+	if err := l.Start.Validate(); err != nil {
+		return err
+	}
+	if err := l.End.Validate(); err != nil {
+		return err
+	}
+	if l.Start > l.End {
+		return fmt.Errorf("%w: %s is after %s", ErrInvalidSpan,
+			l.Start.Format(), l.End.Format())
+	}
+	return nil
+}
+
 // Contains checks whether the given log level is within the span.
 func (l LevelSpan) Contains(level Level) bool {
 	return level >= l.Start && level <= l.End
@@ -23,6 +23,7 @@
 package santa
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -150,3 +151,18 @@ func TestLevelSpanContains(t *testing.T) {
 			sample.actual), "Unexpected result")
 	}
 }
+
+func TestLevelValidate(t *testing.T) {
+	assert.NoError(t, LevelFatal.Validate(), "Unexpected validate error")
+	assert.True(t, errors.Is(Level(5).Validate(), ErrInvalidLevel),
+		"Unexpected validate error")
+
+	assert.NoError(t, LevelSpan { Start: LevelInfo, End: LevelInfo }.
+		Validate(), "Unexpected validate error")
+	err := LevelSpan { Start: LevelError, End: LevelInfo }.Validate()
+	assert.True(t, errors.Is(err, ErrInvalidSpan), "Unexpected validate error")
+	assert.Equal(t, "invalid level span: ERROR is after INFO", err.Error(),
+		"Unexpected validate error")
+	assert.True(t, errors.Is(LevelSpan { End: Level(9) }.Validate(),
+		ErrInvalidLevel), "Unexpected validate error")
+}
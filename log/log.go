@@ -24,6 +24,8 @@ package log
 
 import (
 	"errors"
+	"sync/atomic"
+	"unsafe"
 
 	"github.com/nobody-night/santa"
 )
@@ -34,10 +36,11 @@ var (
 	// the application is initialized and shared globally.
 	pool santa.GlobalPool = santa.GetGlobalPool()
 
-	// logger is an instance of the standard logger, which is used as the
-	// default logger instance. The default logger instance is automatically
-	// created when the application is initialized and shared globally.
-	logger *santa.StandardLogger = nil
+	// logger is a pointer to the instance of the standard logger, which is
+	// used as the default logger instance. The default logger instance is
+	// automatically created when the application is initialized and shared
+	// globally. It must be accessed with the load function.
+	logger unsafe.Pointer = nil
 )
 
 // init initializes the default standard logger instance.
@@ -48,20 +51,29 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
-	logger = instance
+	logger = unsafe.Pointer(instance)
 }
 
-// Set sets the default logger to the given standard logger instance and
-// returns any errors encountered. This function will try to close the old
-// default logger instance.
+// load atomically loads and returns the default logger instance.
+func load() *santa.StandardLogger {
+	return (*santa.StandardLogger)(atomic.LoadPointer(&logger))
+}
+
+// Set atomically sets the default logger to the given standard logger
+// instance, and then closes the old default logger instance and returns
+// any errors encountered. The given logger is used even if closing the
+// old default logger fails.
 //
-// Please note that this API is not thread-safe.
+// This function is thread-safe, but log entries printed concurrently
+// with this function may be printed by the old default logger or fail
+// with the ErrClosed error.
 func Set(instance *santa.StandardLogger) error {
-	err := logger.Close()
+	old := (*santa.StandardLogger)(atomic.SwapPointer(&logger,
+		unsafe.Pointer(instance)))
+	err := old.Close()
 	if err != nil && !errors.Is(err, santa.ErrClosed) {
-		return err		
+		return err
 	}
-	logger = instance
 	return nil
 }
 
@@ -77,7 +89,7 @@ func Set(instance *santa.StandardLogger) error {
 // errors are encountered, the state of the application may change. The
 // best practice is to exit the application.
 func Close() error {
-	return logger.Close()
+	return load().Close()
 }
 
 // Sync writes the internal cache data of a specific synchronizer to a
@@ -88,7 +100,7 @@ func Close() error {
 //
 // Finally, any errors encountered are returned.
 func Sync() error {
-	return logger.Sync()
+	return load().Sync()
 }
 
 // Duplicate creates and returns a copy of the logger. If the logger is
@@ -97,34 +109,34 @@ func Sync() error {
 // Please note that the application must explicitly close each copy of
 // the logger, otherwise the logger may be leaked.
 func Duplicate() *santa.StandardLogger {
-	return logger.Duplicate()
+	return load().Duplicate()
 }
 
 // SetName sets the log entry name to the given name. For details, please
 // refer to the comment section of the Name field of the StandardOption
 // structure.
 //
-// Please note that this API is not thread-safe.
+// This function is thread-safe.
 func SetName(name string) {
-	logger.SetName(name)
+	load().SetName(name)
 }
 
 // SetLevel sets the lowest level of the log entry to the given level.
 // For details, please refer to the comment section of the Level field of
 // the StandardOption structure.
 //
-// Please note that this API is not thread-safe.
+// This function is thread-safe.
 func SetLevel(level santa.Level) {
-	logger.SetLevel(level)
+	load().SetLevel(level)
 }
 
 // SetSampler sets the sampler to the given sampler. For details, please
 // refer to the comment section of the Sampler field of the Option
 // structure.
 //
-// Please note that this API is not thread-safe.
+// This function is thread-safe.
 func SetSampler(sampler santa.Sampler) {
-	logger.SetSampler(sampler)
+	load().SetSampler(sampler)
 }
 
 // SetLabels sets the label to one or more given labels. For details,
@@ -134,27 +146,70 @@ func SetSampler(sampler santa.Sampler) {
 // It is worth noting that one or more labels previously set by the
 // logger will be discarded because labels need to be pre-serialized.
 //
-// Please note that this API is not thread-safe.
+// This function is thread-safe.
 func SetLabels(labels ...santa.Label) {
-	logger.SetLabels(labels...)
+	load().SetLabels(labels...)
 }
 
 // AddHooks adds one or more hooks to the hook chain. For details,
 // please refer to the comment section of the Hooks field of the Option
 // option.
 //
-// Please note that this API is not thread-safe.
+// This function is thread-safe.
 func AddHooks(hooks ...santa.Hook) {
-	logger.AddHooks(hooks...)
+	load().AddHooks(hooks...)
 }
 
 // ResetHooks resets the hook chain, and the hooks that have been added
 // will be removed. For details, please refer to the comment section of
 // the Hooks field of the Option option.
 //
-// Please note that this API is not thread-safe.
+// This function is thread-safe.
 func ResetHooks() {
-	logger.ResetHooks()
+	load().ResetHooks()
+}
+
+// Print outputs a string log message with a given log level and text,
+// and then returns any errors encountered.
+func Print(level santa.Level, text string) error {
+	return load().Output(2, level, santa.StringMessage(text))
+}
+
+// Debug outputs a string log message with a log level of DEBUG and a
+// given text, and then returns any errors encountered.
+func Debug(text string) error {
+	return load().Output(2, santa.LevelDebug, santa.StringMessage(text))
+}
+
+// Info outputs a string log message with a log level of INFO and a given
+// text, and then returns any errors encountered.
+func Info(text string) error {
+	return load().Output(2, santa.LevelInfo, santa.StringMessage(text))
+}
+
+// Warning outputs a string log message with a log level of WARNING and a
+// given text, and then returns any errors encountered.
+func Warning(text string) error {
+	return load().Output(2, santa.LevelWarning, santa.StringMessage(text))
+}
+
+// Error outputs a string log message with a log level of ERROR and a
+// given text, and then returns any errors encountered.
+func Error(text string) error {
+	return load().Output(2, santa.LevelError, santa.StringMessage(text))
+}
+
+// Fatal outputs a string log message with a log level of FATAL and a
+// given text, and then returns any errors encountered.
+func Fatal(text string) error {
+	return load().Output(2, santa.LevelFatal, santa.StringMessage(text))
+}
+
+// DPanic outputs a string log message with a log level of ERROR and a
+// given text, and then returns any errors encountered. If the default
+// logger is in development mode, it panics after the log entry is output.
+func DPanic(text string) error {
+	return load().OutputDPanic(2, santa.StringMessage(text))
 }
 
 // Prints outputs a structured log message with a given log level,
@@ -162,7 +217,7 @@ func ResetHooks() {
 // encountered.
 func Prints(level santa.Level, text string, fields ...santa.Field) error {
 	message := pool.Message.Structure.New(text, fields)
-	err := logger.Output(2, level, message)
+	err := load().Output(2, level, message)
 	pool.Message.Structure.Free(message)
 	return err
 }
@@ -172,7 +227,7 @@ func Prints(level santa.Level, text string, fields ...santa.Field) error {
 // encountered.
 func Debugs(text string, fields ...santa.Field) error {
 	message := pool.Message.Structure.New(text, fields)
-	err := logger.Output(2, santa.LevelDebug, message)
+	err := load().Output(2, santa.LevelDebug, message)
 	pool.Message.Structure.Free(message)
 	return err
 }
@@ -182,7 +237,7 @@ func Debugs(text string, fields ...santa.Field) error {
 // encountered.
 func Infos(text string, fields ...santa.Field) error {
 	message := pool.Message.Structure.New(text, fields)
-	err := logger.Output(2, santa.LevelInfo, message)
+	err := load().Output(2, santa.LevelInfo, message)
 	pool.Message.Structure.Free(message)
 	return err
 }
@@ -192,7 +247,7 @@ func Infos(text string, fields ...santa.Field) error {
 // encountered.
 func Warnings(text string, fields ...santa.Field) error {
 	message := pool.Message.Structure.New(text, fields)
-	err := logger.Output(2, santa.LevelWarning, message)
+	err := load().Output(2, santa.LevelWarning, message)
 	pool.Message.Structure.Free(message)
 	return err
 }
@@ -202,7 +257,7 @@ func Warnings(text string, fields ...santa.Field) error {
 // encountered.
 func Errors(text string, fields ...santa.Field) error {
 	message := pool.Message.Structure.New(text, fields)
-	err := logger.Output(2, santa.LevelError, message)
+	err := load().Output(2, santa.LevelError, message)
 	pool.Message.Structure.Free(message)
 	return err
 }
@@ -212,7 +267,97 @@ func Errors(text string, fields ...santa.Field) error {
 // encountered.
 func Fatals(text string, fields ...santa.Field) error {
 	message := pool.Message.Structure.New(text, fields)
-	err := logger.Output(2, santa.LevelFatal, message)
+	err := load().Output(2, santa.LevelFatal, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// DPanics outputs a structured log message with a log level of ERROR,
+// given description text and fields, and then returns any errors
+// encountered. If the default logger is in development mode, it panics
+// after the log entry is output.
+func DPanics(text string, fields ...santa.Field) error {
+	message := pool.Message.Structure.New(text, fields)
+	err := load().OutputDPanic(2, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// Printw outputs a structured log message with a given log level, given
+// description text and fields built from the given alternating keys and
+// values, and then returns any errors encountered. For details, please
+// refer to the comment section of the santa.KeysAndValues function.
+func Printw(level santa.Level, text string, keysAndValues ...interface { }) error {
+	message := pool.Message.Structure.New(text,
+		santa.KeysAndValues(keysAndValues...))
+	err := load().Output(2, level, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// Debugw outputs a structured log message with a log level of DEBUG,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func Debugw(text string, keysAndValues ...interface { }) error {
+	message := pool.Message.Structure.New(text,
+		santa.KeysAndValues(keysAndValues...))
+	err := load().Output(2, santa.LevelDebug, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// Infow outputs a structured log message with a log level of INFO,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func Infow(text string, keysAndValues ...interface { }) error {
+	message := pool.Message.Structure.New(text,
+		santa.KeysAndValues(keysAndValues...))
+	err := load().Output(2, santa.LevelInfo, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// Warningw outputs a structured log message with a log level of WARNING,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func Warningw(text string, keysAndValues ...interface { }) error {
+	message := pool.Message.Structure.New(text,
+		santa.KeysAndValues(keysAndValues...))
+	err := load().Output(2, santa.LevelWarning, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// Errorw outputs a structured log message with a log level of ERROR,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func Errorw(text string, keysAndValues ...interface { }) error {
+	message := pool.Message.Structure.New(text,
+		santa.KeysAndValues(keysAndValues...))
+	err := load().Output(2, santa.LevelError, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// Fatalw outputs a structured log message with a log level of FATAL,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func Fatalw(text string, keysAndValues ...interface { }) error {
+	message := pool.Message.Structure.New(text,
+		santa.KeysAndValues(keysAndValues...))
+	err := load().Output(2, santa.LevelFatal, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// DPanicw outputs a structured log message with a log level of ERROR,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered. If the default
+// logger is in development mode, it panics after the log entry is output.
+func DPanicw(text string, keysAndValues ...interface { }) error {
+	message := pool.Message.Structure.New(text,
+		santa.KeysAndValues(keysAndValues...))
+	err := load().OutputDPanic(2, message)
 	pool.Message.Structure.Free(message)
 	return err
 }
@@ -222,7 +367,7 @@ func Fatals(text string, fields ...santa.Field) error {
 // encountered.
 func Printf(level santa.Level, template string, args ...interface { }) error {
 	message := pool.Message.Template.New(template, args)
-	err := logger.Output(2, level, message)
+	err := load().Output(2, level, message)
 	pool.Message.Template.Free(message)
 	return err
 }
@@ -232,7 +377,7 @@ func Printf(level santa.Level, template string, args ...interface { }) error {
 // encountered.
 func Debugf(template string, args ...interface { }) error {
 	message := pool.Message.Template.New(template, args)
-	err := logger.Output(2, santa.LevelDebug, message)
+	err := load().Output(2, santa.LevelDebug, message)
 	pool.Message.Template.Free(message)
 	return err
 }
@@ -242,7 +387,7 @@ func Debugf(template string, args ...interface { }) error {
 // encountered.
 func Infof(template string, args ...interface { }) error {
 	message := pool.Message.Template.New(template, args)
-	err := logger.Output(2, santa.LevelInfo, message)
+	err := load().Output(2, santa.LevelInfo, message)
 	pool.Message.Template.Free(message)
 	return err
 }
@@ -252,7 +397,7 @@ func Infof(template string, args ...interface { }) error {
 // errors encountered.
 func Warningf(template string, args ...interface { }) error {
 	message := pool.Message.Template.New(template, args)
-	err := logger.Output(2, santa.LevelWarning, message)
+	err := load().Output(2, santa.LevelWarning, message)
 	pool.Message.Template.Free(message)
 	return err
 }
@@ -262,7 +407,7 @@ func Warningf(template string, args ...interface { }) error {
 // encountered.
 func Errorf(template string, args ...interface { }) error {
 	message := pool.Message.Template.New(template, args)
-	err := logger.Output(2, santa.LevelError, message)
+	err := load().Output(2, santa.LevelError, message)
 	pool.Message.Template.Free(message)
 	return err
 }
@@ -272,7 +417,178 @@ func Errorf(template string, args ...interface { }) error {
 // encountered.
 func Fatalf(template string, args ...interface { }) error {
 	message := pool.Message.Template.New(template, args)
-	err := logger.Output(2, santa.LevelFatal, message)
+	err := load().Output(2, santa.LevelFatal, message)
 	pool.Message.Template.Free(message)
 	return err
 }
+
+// DPanicf outputs a template log message with a log level of ERROR, a
+// given template string and one or more parameters, and then returns any
+// errors encountered. If the default logger is in development mode, it
+// panics after the log entry is output.
+func DPanicf(template string, args ...interface { }) error {
+	message := pool.Message.Template.New(template, args)
+	err := load().OutputDPanic(2, message)
+	pool.Message.Template.Free(message)
+	return err
+}
+
+// Logger is the structure of the scoped logger instance.
+//
+// The scoped logger outputs structured log messages with the default
+// logger, and adds one or more fields to each structured log message it
+// outputs. The default logger is loaded each time a log message is
+// output, so the scoped logger remains usable after the Set function is
+// called. The API provided by the scoped logger is thread-safe.
+type Logger struct {
+	fields []santa.Field
+}
+
+// With creates and returns a copy of the scoped logger that adds the
+// given fields after the fields of the scoped logger.
+func (l *Logger) With(fields ...santa.Field) *Logger {
+	merged := make([]santa.Field, 0, len(l.fields) + len(fields))
+	merged = append(merged, l.fields...)
+	return &Logger {
+		fields: append(merged, fields...),
+	}
+}
+
+// prints outputs a structured log message with a given log level, given
+// description text and fields, and then returns any errors encountered.
+func (l *Logger) prints(level santa.Level, text string, fields []santa.Field) error {
+	message := pool.Message.Structure.New(text, l.merge(fields))
+	err := load().Output(3, level, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// dpanics outputs a structured log message with given description text
+// and fields like the DPanics function, and then returns any errors
+// encountered.
+func (l *Logger) dpanics(text string, fields []santa.Field) error {
+	message := pool.Message.Structure.New(text, l.merge(fields))
+	err := load().OutputDPanic(3, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// merge returns the fields of the scoped logger followed by the given
+// fields.
+func (l *Logger) merge(fields []santa.Field) []santa.Field {
+	if len(l.fields) == 0 {
+		return fields
+	}
+	merged := make([]santa.Field, 0, len(l.fields) + len(fields))
+	merged = append(merged, l.fields...)
+	return append(merged, fields...)
+}
+
+// Prints outputs a structured log message with a given log level,
+// given description text and fields, and then returns any errors
+// encountered.
+func (l *Logger) Prints(level santa.Level, text string, fields ...santa.Field) error {
+	return l.prints(level, text, fields)
+}
+
+// Debugs outputs a structured log message with a log level of DEBUG,
+// given description text and fields, and then returns any errors
+// encountered.
+func (l *Logger) Debugs(text string, fields ...santa.Field) error {
+	return l.prints(santa.LevelDebug, text, fields)
+}
+
+// Infos outputs a structured log message with a log level of INFO,
+// given description text and fields, and then returns any errors
+// encountered.
+func (l *Logger) Infos(text string, fields ...santa.Field) error {
+	return l.prints(santa.LevelInfo, text, fields)
+}
+
+// Warnings outputs a structured log message with a log level of WARNING,
+// given description text and fields, and then returns any errors
+// encountered.
+func (l *Logger) Warnings(text string, fields ...santa.Field) error {
+	return l.prints(santa.LevelWarning, text, fields)
+}
+
+// Errors outputs a structured log message with a log level of ERROR,
+// given description text and fields, and then returns any errors
+// encountered.
+func (l *Logger) Errors(text string, fields ...santa.Field) error {
+	return l.prints(santa.LevelError, text, fields)
+}
+
+// Fatals outputs a structured log message with a log level of FATAL,
+// given description text and fields, and then returns any errors
+// encountered.
+func (l *Logger) Fatals(text string, fields ...santa.Field) error {
+	return l.prints(santa.LevelFatal, text, fields)
+}
+
+// Printw outputs a structured log message with a given log level, given
+// description text and fields built from the given alternating keys and
+// values, and then returns any errors encountered. For details, please
+// refer to the comment section of the santa.KeysAndValues function.
+func (l *Logger) Printw(level santa.Level, text string, keysAndValues ...interface { }) error {
+	return l.prints(level, text, santa.KeysAndValues(keysAndValues...))
+}
+
+// Debugw outputs a structured log message with a log level of DEBUG,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func (l *Logger) Debugw(text string, keysAndValues ...interface { }) error {
+	return l.prints(santa.LevelDebug, text, santa.KeysAndValues(keysAndValues...))
+}
+
+// Infow outputs a structured log message with a log level of INFO,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func (l *Logger) Infow(text string, keysAndValues ...interface { }) error {
+	return l.prints(santa.LevelInfo, text, santa.KeysAndValues(keysAndValues...))
+}
+
+// Warningw outputs a structured log message with a log level of WARNING,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func (l *Logger) Warningw(text string, keysAndValues ...interface { }) error {
+	return l.prints(santa.LevelWarning, text, santa.KeysAndValues(keysAndValues...))
+}
+
+// Errorw outputs a structured log message with a log level of ERROR,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func (l *Logger) Errorw(text string, keysAndValues ...interface { }) error {
+	return l.prints(santa.LevelError, text, santa.KeysAndValues(keysAndValues...))
+}
+
+// Fatalw outputs a structured log message with a log level of FATAL,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func (l *Logger) Fatalw(text string, keysAndValues ...interface { }) error {
+	return l.prints(santa.LevelFatal, text, santa.KeysAndValues(keysAndValues...))
+}
+
+// DPanics outputs a structured log message with a log level of ERROR,
+// given description text and fields, and then returns any errors
+// encountered. If the default logger is in development mode, it panics
+// after the log entry is output.
+func (l *Logger) DPanics(text string, fields ...santa.Field) error {
+	return l.dpanics(text, fields)
+}
+
+// DPanicw outputs a structured log message with a log level of ERROR,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered. If the default
+// logger is in development mode, it panics after the log entry is output.
+func (l *Logger) DPanicw(text string, keysAndValues ...interface { }) error {
+	return l.dpanics(text, santa.KeysAndValues(keysAndValues...))
+}
+
+// With creates and returns a scoped logger that outputs structured log
+// messages with the default logger and adds the given fields to each
+// structured log message. For details, please refer to the comment
+// section of the Logger structure.
+func With(fields ...santa.Field) *Logger {
+	return (&Logger { }).With(fields...)
+}
@@ -23,6 +23,7 @@
 package log
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/nobody-night/santa"
@@ -30,6 +31,8 @@ import (
 )
 
 func TestSet(t *testing.T) {
+	setTestLogger(t, newTestLogger(t, func(*santa.Entry) { }))
+
 	SetName("testing")
 	SetLevel(santa.LevelFatal)
 	SetSampler(nil)
@@ -38,9 +41,11 @@ func TestSet(t *testing.T) {
 		return nil
 	}))
 	ResetHooks()
-	
-	err := Duplicate().Close()
-	assert.NoError(t, err, "Unexpected close error")
+
+	duplicate := Duplicate()
+	if assert.NotNil(t, duplicate, "Unexpected duplicate result") {
+		assert.NoError(t, duplicate.Close(), "Unexpected close error")
+	}
 }
 
 func TestStructured(t *testing.T) {
@@ -48,8 +53,7 @@ func TestStructured(t *testing.T) {
 	assert.NoError(t, err, "Unexpected create error")
 	assert.NotNil(t, instance, "Unexpected return value")
 	
-	err = Set(instance)
-	assert.NoError(t, err, "Unexpected set error")
+	setTestLogger(t, instance)
 
 	err = Prints(santa.LevelFatal, "testing", santa.String("name", "testing"))
 	assert.NoError(t, err, "Unexpected print error")
@@ -69,6 +73,24 @@ func TestStructured(t *testing.T) {
 	err = Fatals("testing", santa.String("name", "testing"))
 	assert.NoError(t, err, "Unexpected print error")
 
+	err = Printw(santa.LevelFatal, "testing", "name", "testing")
+	assert.NoError(t, err, "Unexpected print error")
+
+	err = Debugw("testing", "name", "testing")
+	assert.NoError(t, err, "Unexpected print error")
+
+	err = Infow("testing", "name", "testing")
+	assert.NoError(t, err, "Unexpected print error")
+
+	err = Warningw("testing", "name", "testing")
+	assert.NoError(t, err, "Unexpected print error")
+
+	err = Errorw("testing", "name", "testing")
+	assert.NoError(t, err, "Unexpected print error")
+
+	err = Fatalw("testing", "name", "testing")
+	assert.NoError(t, err, "Unexpected print error")
+
 	err = Sync()
 	assert.NoError(t, err, "Unexpected sync error")
 
@@ -81,8 +103,7 @@ func TestTemplate(t *testing.T) {
 	assert.NoError(t, err, "Unexpected create error")
 	assert.NotNil(t, instance, "Unexpected return value")
 	
-	err = Set(instance)
-	assert.NoError(t, err, "Unexpected set error")
+	setTestLogger(t, instance)
 
 	err = Printf(santa.LevelFatal, "testing %s", "santa")
 	assert.NoError(t, err, "Unexpected print error")
@@ -108,3 +129,193 @@ func TestTemplate(t *testing.T) {
 	err = Close()
 	assert.NoError(t, err, "Unexpected close error")
 }
+
+func TestSourceLocation(t *testing.T) {
+	option := santa.NewStandardOption()
+	option.Encoding.UseStandard()
+	option.Outputting.UseDiscard()
+	option.ErrorOutputting.UseDiscard()
+	option.DisableSampling()
+
+	files := []string { }
+	option.Hooks = append(option.Hooks, santa.NewSimpleHook(
+		func(entry *santa.Entry) error {
+			files = append(files, entry.SourceLocation.File)
+			return nil
+		}))
+
+	instance, err := option.Build()
+	assert.NoError(t, err, "Unexpected create error")
+	setTestLogger(t, instance)
+
+	assert.NoError(t, Infos("testing"), "Unexpected print error")
+	assert.NoError(t, Prints(santa.LevelInfo, "testing"),
+		"Unexpected print error")
+	assert.NoError(t, Infof("testing"), "Unexpected print error")
+	assert.NoError(t, Printf(santa.LevelInfo, "testing"),
+		"Unexpected print error")
+
+	assert.Len(t, files, 4, "Unexpected log entries")
+	for _, file := range files {
+		assert.Contains(t, file, "log_test.go",
+			"Unexpected source location")
+	}
+}
+
+var _ santa.StructPrinter = (*Logger)(nil)
+
+func newTestLogger(t *testing.T, hook func(entry *santa.Entry)) *santa.StandardLogger {
+	option := santa.NewStandardOption()
+	option.Encoding.UseStandard()
+	option.Outputting.UseDiscard()
+	option.ErrorOutputting.UseDiscard()
+	option.DisableSampling()
+	option.Level = santa.LevelDebug
+	option.Hooks = append(option.Hooks, santa.NewSimpleHook(
+		func(entry *santa.Entry) error {
+			hook(entry)
+			return nil
+		}))
+
+	instance, err := option.Build()
+	assert.NoError(t, err, "Unexpected create error")
+	return instance
+}
+
+// setTestLogger sets the default logger to the given logger, and sets a
+// new logger when the test completes, so that the following tests never
+// use a closed default logger.
+func setTestLogger(t *testing.T, instance *santa.StandardLogger) {
+	t.Helper()
+	assert.NoError(t, Set(instance), "Unexpected set error")
+	t.Cleanup(func() {
+		_ = Set(newTestLogger(t, func(*santa.Entry) { }))
+	})
+}
+
+func TestString(t *testing.T) {
+	messages := []santa.Message { }
+	setTestLogger(t, newTestLogger(t, func(entry *santa.Entry) {
+		messages = append(messages, entry.Message)
+	}))
+
+	assert.NoError(t, Print(santa.LevelInfo, "print"),
+		"Unexpected print error")
+	assert.NoError(t, Debug("debug"), "Unexpected print error")
+	assert.NoError(t, Info("info"), "Unexpected print error")
+	assert.NoError(t, Warning("warning"), "Unexpected print error")
+	assert.NoError(t, Error("error"), "Unexpected print error")
+	assert.NoError(t, Fatal("fatal"), "Unexpected print error")
+
+	assert.Equal(t, []santa.Message { santa.StringMessage("print"),
+		santa.StringMessage("debug"), santa.StringMessage("info"),
+		santa.StringMessage("warning"), santa.StringMessage("error"),
+		santa.StringMessage("fatal") }, messages, "Unexpected log entries")
+}
+
+func TestWith(t *testing.T) {
+	fields := []string { }
+	files := []string { }
+	setTestLogger(t, newTestLogger(t, func(entry *santa.Entry) {
+		message := entry.Message.(*santa.StructMessage)
+		fields = append(fields, string(message.Fields.SerializeJSON(nil)))
+		files = append(files, entry.SourceLocation.File)
+	}))
+
+	scoped := With(santa.String("requestId", "1"))
+	assert.NoError(t, scoped.Infos("testing", santa.Int("age", 100)),
+		"Unexpected print error")
+	nested := scoped.With(santa.String("user", "santa"))
+	assert.NoError(t, nested.Prints(santa.LevelInfo, "testing"),
+		"Unexpected print error")
+	assert.NoError(t, nested.Debugs("testing"), "Unexpected print error")
+	assert.NoError(t, nested.Warnings("testing"), "Unexpected print error")
+	assert.NoError(t, nested.Errors("testing"), "Unexpected print error")
+	assert.NoError(t, nested.Fatals("testing"), "Unexpected print error")
+	assert.NoError(t, scoped.Infos("testing"), "Unexpected print error")
+	assert.NoError(t, nested.Printw(santa.LevelInfo, "testing", "age", 100),
+		"Unexpected print error")
+	assert.NoError(t, scoped.Debugw("testing"), "Unexpected print error")
+	assert.NoError(t, scoped.Infow("testing"), "Unexpected print error")
+	assert.NoError(t, scoped.Warningw("testing"), "Unexpected print error")
+	assert.NoError(t, scoped.Errorw("testing"), "Unexpected print error")
+	assert.NoError(t, scoped.Fatalw("testing"), "Unexpected print error")
+
+	assert.Equal(t, []string {
+		`{"requestId": "1", "age": 100}`,
+		`{"requestId": "1", "user": "santa"}`,
+		`{"requestId": "1", "user": "santa"}`,
+		`{"requestId": "1", "user": "santa"}`,
+		`{"requestId": "1", "user": "santa"}`,
+		`{"requestId": "1", "user": "santa"}`,
+		`{"requestId": "1"}`,
+		`{"requestId": "1", "user": "santa", "age": 100}`,
+		`{"requestId": "1"}`,
+		`{"requestId": "1"}`,
+		`{"requestId": "1"}`,
+		`{"requestId": "1"}`,
+		`{"requestId": "1"}`,
+	}, fields, "Unexpected log entries")
+	for _, file := range files {
+		assert.Contains(t, file, "log_test.go",
+			"Unexpected source location")
+	}
+}
+
+func TestDPanic(t *testing.T) {
+	levels := []santa.Level { }
+	files := []string { }
+	setTestLogger(t, newTestLogger(t, func(entry *santa.Entry) {
+		levels = append(levels, entry.Level)
+		files = append(files, entry.SourceLocation.File)
+	}))
+
+	scoped := With(santa.String("requestId", "1"))
+	assert.NoError(t, DPanic("testing"), "Unexpected print error")
+	assert.NoError(t, DPanics("testing"), "Unexpected print error")
+	assert.NoError(t, DPanicw("testing", "age", 100),
+		"Unexpected print error")
+	assert.NoError(t, DPanicf("testing %d", 100), "Unexpected print error")
+	assert.NoError(t, scoped.DPanics("testing"), "Unexpected print error")
+	assert.NoError(t, scoped.DPanicw("testing"), "Unexpected print error")
+
+	assert.Len(t, levels, 6, "Unexpected log entries")
+	for index := range levels {
+		assert.Equal(t, santa.LevelError, levels[index],
+			"Unexpected log entry level")
+		assert.Contains(t, files[index], "log_test.go",
+			"Unexpected source location")
+	}
+
+	option := santa.NewStandardOption().UseDevelopment()
+	option.Outputting.UseDiscard()
+	option.ErrorOutputting.UseDiscard()
+	instance, err := option.Build()
+	assert.NoError(t, err, "Unexpected create error")
+	setTestLogger(t, instance)
+	assert.Panics(t, func() { _ = DPanic("testing") },
+		"Unexpected print result")
+	assert.Panics(t, func() { _ = scoped.DPanics("testing") },
+		"Unexpected print result")
+}
+
+func TestSetConcurrent(t *testing.T) {
+	t.Cleanup(func() {
+		_ = Set(newTestLogger(t, func(*santa.Entry) { }))
+	})
+	var group sync.WaitGroup
+	for index := 0; index < 4; index++ {
+		group.Add(2)
+		go func() {
+			defer group.Done()
+			_ = Infos("testing")
+		}()
+		go func() {
+			defer group.Done()
+			assert.NoError(t, Set(newTestLogger(t, func(*santa.Entry) { })),
+				"Unexpected set error")
+		}()
+	}
+	group.Wait()
+	assert.NoError(t, Close(), "Unexpected close error")
+}
@@ -25,12 +25,15 @@ package santa
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"math/rand"
 	"os"
 	"runtime"
 	"sync"
 	"sync/atomic"
 	"time"
+	"unsafe"
 )
 
 var (
@@ -41,6 +44,42 @@ var (
 	// ErrClosed represents the instance has been closed. This is usually
 	// because the application attempts to close an instance multiple times.
 	ErrClosed = errors.New("instance has been closed")
+
+	// ErrInvalidJitter represents that the given jitter of the flushing
+	// option is invalid. The jitter must be between 0 and 1.
+	ErrInvalidJitter = errors.New("invalid flushing jitter")
+
+	// ErrInvalidCallerSkip represents that the given CallerSkip option of
+	// the logger is invalid, because it is negative.
+	ErrInvalidCallerSkip = errors.New("invalid caller skip")
+
+	// ErrInvalidClockResolution represents that the given ClockResolution
+	// option of the standard logger is invalid, because it is negative.
+	ErrInvalidClockResolution = errors.New("invalid clock resolution")
+
+	// ErrInvalidExporterIndex represents that the given Index of the
+	// flushing option of an exporter is not the index of an exporter of
+	// the Exporters option of the standard logger.
+	ErrInvalidExporterIndex = errors.New("invalid exporter index")
+
+	// ErrDevelopmentPanic represents that a DPanic... API was called while
+	// the logger is in development mode. It is wrapped by the value of
+	// the panic, which also contains the text of the log message.
+	ErrDevelopmentPanic = errors.New("development panic")
+)
+
+const (
+	// ProfileLabelName is the key of the pprof label containing the name
+	// of the logger when the Profiling option is enabled.
+	ProfileLabelName = "santa.name"
+
+	// ProfileLabelLevel is the key of the pprof label containing the level
+	// of the log entry when the Profiling option is enabled.
+	ProfileLabelLevel = "santa.level"
+
+	// TraceCategory is the category of the runtime/trace user log events
+	// emitted for FATAL log entries when the Profiling option is enabled.
+	TraceCategory = "santa"
 )
 
 // Logger is the structure of the logger instance.
@@ -56,14 +95,76 @@ var (
 //
 // The API provided by the logger is thread-safe.
 type Logger struct {
+	settings unsafe.Pointer
+	exporters []Exporter
+	clock Clock
+	sequence *uint64
+	errorHandler func(error)
+
+	addSource bool
+	sourceLevel Level
+	callerSkip int
+	bestEffort bool
+	profile bool
+	development bool
+
+	closed int32
+}
+
+// loggerSettings is a structure that contains the settings of the logger
+// that can be changed after the logger is built.
+//
+// The settings instance is immutable after it is published to the logger.
+// Any change creates a modified copy of the settings instance and then
+// atomically replaces the published instance, so the output API never
+// observes a partially changed settings instance.
+type loggerSettings struct {
 	name string
 	level Level
 	sampler Sampler
 	hooks []Hook
-	exporters []Exporter
 	labels SerializedLabels
+}
 
-	addSource bool
+// loadSettings atomically loads and returns the current settings instance
+// of the logger. The returned instance must not be modified.
+func (l *Logger) loadSettings() *loggerSettings {
+	return (*loggerSettings)(atomic.LoadPointer(&l.settings))
+}
+
+// updateSettings atomically replaces the settings of the logger with a
+// copy of the current settings modified by the given function. The given
+// function may be called more than once if the settings are changed
+// concurrently.
+func (l *Logger) updateSettings(update func(settings *loggerSettings)) {
+	for {
+		current := atomic.LoadPointer(&l.settings)
+		settings := *(*loggerSettings)(current)
+		update(&settings)
+		if atomic.CompareAndSwapPointer(&l.settings, current,
+			unsafe.Pointer(&settings)) {
+			return
+		}
+	}
+}
+
+// duplicate creates and returns a copy of the logger. The copy shares the
+// exporters of the logger, and changes to the settings of the copy do not
+// affect the logger.
+func (l *Logger) duplicate() Logger {
+	return Logger {
+		settings: atomic.LoadPointer(&l.settings),
+		exporters: l.exporters,
+		clock: l.clock,
+		sequence: l.sequence,
+		errorHandler: l.errorHandler,
+		addSource: l.addSource,
+		sourceLevel: l.sourceLevel,
+		callerSkip: l.callerSkip,
+		bestEffort: l.bestEffort,
+		profile: l.profile,
+		development: l.development,
+	}
 }
 
 // Output checks whether the log level is lower than the minimum log
@@ -74,45 +175,132 @@ type Logger struct {
 // entry exporters for processing, and any errors encountered are
 // returned.
 //
+// The given call depth is the number of stack frames to ascend to find
+// the source location of the log entry, where 1 identifies the caller
+// of Output. APIs that wrap Output should pass 2 to report the source
+// location of their callers, and each additional layer of wrapping
+// should increase the call depth by 1. The CallerSkip option of the
+// logger is added to the given call depth.
+//
 // Please note that this is a low-level API, and the high-level API
 // usually provided by the logger is used internally. Unless necessary,
 // applications should not use this API directly.
-func (l *Logger) Output(stacks int, level Level, message Message) error {
-	if !l.level.Enabled(level) {
+//
+// If the logger has been closed, no log entry is generated and ErrClosed
+// is returned.
+func (l *Logger) Output(callDepth int, level Level, message Message) error {
+	settings := l.loadSettings()
+	return l.output(callDepth + 1, level, message, settings.name,
+		settings.labels)
+}
+
+// output is the implementation of the Output function. The given name
+// and labels are used for the generated log entry instead of the name
+// and labels of the logger, which allows decorators to share the logger.
+// The given call depth is relative to the caller of this function, and
+// the CallerSkip option of the logger is added to it.
+func (l *Logger) output(callDepth int, level Level, message Message, name string,
+	labels SerializedLabels) error {
+	if atomic.LoadInt32(&l.closed) == 1 {
+		return ErrClosed
+	}
+	callDepth += l.callerSkip
+	settings := l.loadSettings()
+	if !settings.level.Enabled(level) {
 		return nil
 	}
 	if len(l.exporters) == 0 {
 		return nil
 	}
+	if l.profile {
+		return l.profileOutput(callDepth + 1, level, message, name, labels,
+			settings)
+	}
+	return l.write(callDepth + 1, level, message, name, labels, settings)
+}
 
+// dpanic outputs a log entry of the given message with a log level of
+// ERROR like the output function, and then panics with an error wrapping
+// ErrDevelopmentPanic if the Development option is enabled. The given
+// call depth is relative to the caller of this function.
+func (l *Logger) dpanic(callDepth int, message Message, name string,
+	labels SerializedLabels) error {
+	err := l.output(callDepth + 1, LevelError, message, name, labels)
+	if l.development {
+		panic(fmt.Errorf("%w: %s", ErrDevelopmentPanic,
+			messageText(message)))
+	}
+	return err
+}
+
+// OutputDPanic outputs a log entry of the given message with a log level
+// of ERROR like the Output function, and then returns any errors
+// encountered. If the Development option is enabled, it panics with an
+// error wrapping ErrDevelopmentPanic after the log entry is output. For
+// details of the call depth, please refer to the comment section of the
+// Output function.
+//
+// Please note that this is a low-level API, and the DPanic... APIs
+// provided by the loggers are used internally. Unless necessary,
+// applications should not use this API directly.
+func (l *Logger) OutputDPanic(callDepth int, message Message) error {
+	settings := l.loadSettings()
+	return l.dpanic(callDepth + 1, message, settings.name, settings.labels)
+}
+
+// write generates a log entry and passes it to the sampler, hooks and
+// exporters of the logger. The given call depth is relative to the caller
+// of this function.
+func (l *Logger) write(callDepth int, level Level, message Message, name string,
+	labels SerializedLabels, settings *loggerSettings) error {
 	entry := pool.Entry.New()
-	entry.Name = l.name
+	entry.Name = name
 	entry.Level = level
-	entry.Time = time.Now()
-	entry.Message = message
-	entry.Labels = l.labels
+	if l.clock == nil {
+		entry.Time = time.Now()
+	} else {
+		entry.Time = l.clock.Now()
+	}
+	entry.SetMessage(message)
+	entry.Labels = labels
+	entry.Sequence = 0
 
-	if l.sampler != nil && !l.sampler.Sample(entry) {
+	if settings.sampler != nil && !settings.sampler.Sample(entry) {
 		pool.Entry.Free(entry)
 		return nil
 	}
-	if l.addSource {
-		entry.SourceLocation = newEntrySourceLocation(
-			runtime.Caller(stacks))
+	entry.SourceLocation = EntrySourceLocation { }
+	if l.addSource && l.sourceLevel.Enabled(level) {
+		entry.SourceLocation = captureSourceLocation(callDepth)
 	}
 
-	for index := 0; index < len(l.hooks); index++ {
-		err := l.hooks[index].Print(entry)
+	for index := 0; index < len(settings.hooks); index++ {
+		err := settings.hooks[index].Print(entry)
 
 		if err != nil {
 			pool.Entry.Free(entry)
 			return err
 		}
 	}
+	// The sequence number is only assigned to the log entries accepted by
+	// the sampler and hooks, so that the sequence numbers of the exported
+	// log entries have no gaps.
+	if l.sequence != nil {
+		entry.Sequence = atomic.AddUint64(l.sequence, 1)
+	}
 	for index := 0; index < len(l.exporters); index++ {
 		err := l.exporters[index].Export(entry)
 
-		if err != nil {
+		if err == nil {
+			continue
+		}
+		if l.errorHandler != nil {
+			l.errorHandler(&ExporterError {
+				Exporter: l.exporters[index],
+				Err: err,
+			})
+		}
+		if !l.bestEffort {
 			pool.Entry.Free(entry)
 			return err
 		}
@@ -128,6 +316,15 @@ func (l *Logger) Print(level Level, message Message) error {
 	return l.Output(2, level, message)
 }
 
+// Decorator gets and returns a decorator instance of the logger from the
+// global pool. For details, please refer to the comment section of the
+// Decorator structure.
+func (l *Logger) Decorator() *Decorator {
+	instance := pool.decorator.base.Get().(*Decorator)
+	instance.reset(l)
+	return instance
+}
+
 // Option is a structure that contains options for the logger.
 //
 // Normally, all the logger option types of all logger types rely on the
@@ -165,6 +362,7 @@ type Option struct {
 	//
 	// Please note that this option slice will be reused during the build
 	// process, and any side effects of external modifications are undefined.
+	// To derive multiple loggers from the same option, use the Clone function.
 	Hooks []Hook
 
 	// Exporters represent a group of log entry exporters. Normally, each
@@ -186,6 +384,7 @@ type Option struct {
 	//
 	// Please note that this option slice will be reused during the build
 	// process, and any side effects of external modifications are undefined.
+	// To derive multiple loggers from the same option, use the Clone function.
 	Exporters []Exporter
 
 	// Labels represents one or more labels related to the logger. Each label
@@ -206,19 +405,131 @@ type Option struct {
 	// expensive performance overhead. If not provided, the default value
 	// is false.
 	DisableSourceLocation bool
+
+	// SourceLocationLevel represents the minimum level of the log entries
+	// whose source location is obtained, unless the DisableSourceLocation
+	// option is enabled. For example, if the value is LevelWarning, the
+	// source location is only obtained for WARNING, ERROR and FATAL log
+	// entries, which saves the overhead for high-volume DEBUG and INFO log
+	// entries. If not provided, the default value is LevelDebug.
+	SourceLocationLevel Level
+
+	// CallerSkip represents the number of additional stack frames to
+	// ascend when obtaining the source location of log entries. If the
+	// application wraps the output API of the logger in its own helper
+	// functions, the value should be the number of wrapping layers, so
+	// that the source location is the call site of the helper functions
+	// instead of the helper functions themselves. If not provided, the
+	// default value is 0.
+	CallerSkip int
+
+	// Clock represents the clock used to obtain the generation time of
+	// each log entry. If not provided, the current local time of the
+	// system is used. For details, please refer to the comment section
+	// of the Clock interface.
+	Clock Clock
+
+	// Sequence represents whether to generate a sequence number for each
+	// log entry. For details, please refer to the comment section of the
+	// Sequence field of the Entry structure. If not provided, the default
+	// value is false.
+	Sequence bool
+
+	// ErrorHandler represents the function called with each error returned
+	// by the exporters. The error is an instance of the ExporterError
+	// structure that contains the failing exporter. The function is called
+	// on the output path, so it should return quickly. If not provided,
+	// errors are only returned to the caller.
+	ErrorHandler func(error)
+
+	// BestEffort represents whether to continue exporting a log entry to
+	// the remaining exporters after an exporter fails. In best-effort mode
+	// the errors of exporters are only passed to the ErrorHandler option
+	// and are not returned to the caller, so that one failing exporter (for
+	// example, a network exporter) does not block other exporters or bubble
+	// errors into hot paths. If not provided, the default value is false.
+	BestEffort bool
+
+	// Profiling represents whether to output each log entry with pprof
+	// labels containing the name of the logger (ProfileLabelName) and the
+	// level of the log entry (ProfileLabelLevel), and to emit a runtime
+	// trace user log event (TraceCategory) for each FATAL log entry. This
+	// helps correlate logging hotspots with CPU profiles and execution
+	// traces. If not provided, the default value is false.
+	//
+	// Please note that the pprof labels of the calling goroutine are reset
+	// after each log entry is output, because the output API does not
+	// receive the context of the caller. When built with TinyGo, the option
+	// has no effect.
+	Profiling bool
+
+	// Development represents whether the logger is in development mode.
+	// The DPanic... APIs of the loggers output log entries with a log
+	// level of ERROR, and in development mode they panic after the log
+	// entry is output, so that programmer errors are caught early during
+	// development and tests, and only logged in production. If not
+	// provided, the default value is false.
+	Development bool
+}
+
+// Clone creates and returns a copy of the option instance. The copy does
+// not share the Hooks, Exporters and Labels option slices with the option
+// instance, but the hook and exporter instances themselves are shared.
+func (o *Option) Clone() *Option {
+	option := *o
+	option.Hooks = append([]Hook(nil), o.Hooks...)
+	option.Exporters = append([]Exporter(nil), o.Exporters...)
+	option.Labels = append(Labels(nil), o.Labels...)
+	return &option
+}
+
+// Validate checks whether the values of the options are valid, and returns
+// an error describing the first invalid value.
+func (o *Option) Validate() error {
+	if err := o.Level.Validate(); err != nil {
+		return err
+	}
+	if o.CallerSkip < 0 {
+		return ErrInvalidCallerSkip
+	}
+	return o.SourceLocationLevel.Validate()
 }
 
 // Build builds and returns an instance of the logger.
 func (o *Option) Build() (*Logger, error) {
-	return &Logger {
-		name: o.Name,
-		level: o.Level,
-		sampler: o.Sampler,
-		hooks: o.Hooks,
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	instance := &Logger {
+		settings: unsafe.Pointer(&loggerSettings {
+			name: o.Name,
+			level: o.Level,
+			sampler: o.Sampler,
+			hooks: o.Hooks,
+			labels: NewSerializedLabels(o.Labels...),
+		}),
 		exporters: o.Exporters,
-		labels: NewSerializedLabels(o.Labels...),
+		clock: o.Clock,
+		errorHandler: o.ErrorHandler,
 		addSource: !o.DisableSourceLocation,
-	}, nil
+		sourceLevel: o.SourceLocationLevel,
+		callerSkip: o.CallerSkip,
+		bestEffort: o.BestEffort,
+		profile: o.Profiling,
+		development: o.Development,
+	}
+	if o.Sequence {
+		instance.sequence = new(uint64)
+	}
+	return instance, nil
+}
+
+// MustBuild builds and returns an instance of the logger. If any errors
+// are encountered, it panics with an error describing the failure.
+func (o *Option) MustBuild() *Logger {
+	instance, err := o.Build()
+	mustBuild("logger", err)
+	return instance
 }
 
 // NewOption creates and returns a logger option instance with default
@@ -236,6 +547,22 @@ func New() (*Logger, error) {
 	return NewOption().Build()
 }
 
+// MustNew creates and returns a logger instance using the default optional
+// values. If any errors are encountered, it panics with an error
+// describing the failure.
+func MustNew() *Logger {
+	return NewOption().MustBuild()
+}
+
+// mustBuild panics with an error that wraps the given error and describes
+// the failure of building the given kind of logger, if the given error is
+// not nil.
+func mustBuild(kind string, err error) {
+	if err != nil {
+		panic(fmt.Errorf("santa: failed to build %s: %w", kind, err))
+	}
+}
+
 // StandardLogger is the structure of the standard logger instance.
 //
 // The standard logger is based on the logger. The standard logger
@@ -263,10 +590,10 @@ func New() (*Logger, error) {
 // interface.
 //
 // Unless explicitly stated, the API provided by the logger is
-// thread-safe. It’s worth noting that APIs that allow post-build
-// changes to logger instances are generally not thread-safe. If you
-// need to change the logger instance (including but not limited to:
-// minimum log entry level, etc.), use the Duplicate function to create
+// thread-safe, including the APIs that allow post-build changes to
+// logger instances (including but not limited to: minimum log entry
+// level, etc.). If you need to change the logger instance without
+// affecting other users of it, use the Duplicate function to create
 // a copy of the logger instance, and then make changes to the copy of
 // the logger instance.
 type StandardLogger struct {
@@ -276,8 +603,6 @@ type StandardLogger struct {
 	contextCancel context.CancelFunc
 	contextWaitGroup *sync.WaitGroup
 	contextReferences *int32
-
-	closed int32
 }
 
 // Debug outputs a given log message with a log level of DEBUG, and then
@@ -310,46 +635,141 @@ func (l *StandardLogger) Fatal(message Message) error {
 	return l.Output(2, LevelFatal, message)
 }
 
+// PrintString outputs a string log message with a given log level and a
+// given text, and then returns any errors encountered. It is equivalent
+// to calling the Output function with a StringMessage.
+func (l *StandardLogger) PrintString(level Level, text string) error {
+	return l.Output(2, level, StringMessage(text))
+}
+
+// DebugString outputs a string log message with a log level of DEBUG and a
+// given text, and then returns any errors encountered.
+func (l *StandardLogger) DebugString(text string) error {
+	return l.Output(2, LevelDebug, StringMessage(text))
+}
+
+// InfoString outputs a string log message with a log level of INFO and a
+// given text, and then returns any errors encountered.
+func (l *StandardLogger) InfoString(text string) error {
+	return l.Output(2, LevelInfo, StringMessage(text))
+}
+
+// WarningString outputs a string log message with a log level of WARNING and a
+// given text, and then returns any errors encountered.
+func (l *StandardLogger) WarningString(text string) error {
+	return l.Output(2, LevelWarning, StringMessage(text))
+}
+
+// ErrorString outputs a string log message with a log level of ERROR and a
+// given text, and then returns any errors encountered.
+func (l *StandardLogger) ErrorString(text string) error {
+	return l.Output(2, LevelError, StringMessage(text))
+}
+
+// FatalString outputs a string log message with a log level of FATAL and a
+// given text, and then returns any errors encountered.
+func (l *StandardLogger) FatalString(text string) error {
+	return l.Output(2, LevelFatal, StringMessage(text))
+}
+
+// DPanic outputs a given log message with a log level of ERROR, and then
+// returns any errors encountered. If the logger is in development mode,
+// it panics after the log entry is output. For details, please refer to
+// the comment section of the Development option.
+func (l *StandardLogger) DPanic(message Message) error {
+	return l.OutputDPanic(2, message)
+}
+
+// DPanicString outputs a string log message with a given text like the
+// DPanic function, and then returns any errors encountered.
+func (l *StandardLogger) DPanicString(text string) error {
+	return l.OutputDPanic(2, StringMessage(text))
+}
+
 // Duplicate creates and returns a copy of the logger. If the logger is
 // closed, it returns nil.
 //
 // Please note that the application must explicitly close each copy of
 // the logger, otherwise the logger may be leaked.
 func (l *StandardLogger) Duplicate() *StandardLogger {
-	if atomic.AddInt32(l.contextReferences, 1) == 1 {
-		// The logger has been shut down, and using the created copy
-		// may cause panic.
+	instance, ok := l.duplicate()
+	if !ok {
 		return nil
 	}
-	instance := *l
 	return &instance
 }
 
+// duplicate creates and returns a copy of the logger and increases the
+// reference count of the logger. If the logger or all copies of it have
+// been closed, the reference count is not changed and false is returned.
+func (l *StandardLogger) duplicate() (StandardLogger, bool) {
+	if l.IsClosed() {
+		return StandardLogger { }, false
+	}
+	for {
+		references := atomic.LoadInt32(l.contextReferences)
+		if references <= 0 {
+			// The logger has been shut down, and using the created copy
+			// may cause panic.
+			return StandardLogger { }, false
+		}
+		if atomic.CompareAndSwapInt32(l.contextReferences, references,
+			references + 1) {
+			break
+		}
+	}
+	return StandardLogger {
+		Logger: l.Logger.duplicate(),
+		context: l.context,
+		contextCancel: l.contextCancel,
+		contextWaitGroup: l.contextWaitGroup,
+		contextReferences: l.contextReferences,
+	}, true
+}
+
+// Decorator gets and returns a standard decorator instance of the logger
+// from the global pool. For details, please refer to the comment section
+// of the StandardDecorator structure.
+func (l *StandardLogger) Decorator() *StandardDecorator {
+	instance := pool.decorator.standard.Get().(*StandardDecorator)
+	instance.reset(&l.Logger)
+	return instance
+}
+
 // SetName sets the log entry name to the given name. For details, please
 // refer to the comment section of the Name field of the StandardOption
 // structure.
 //
-// Please note that this API is not thread-safe.
+// This function is thread-safe. The change only affects the logger
+// instance itself and not other copies of it.
 func (l *StandardLogger) SetName(name string) {
-	l.name = name
+	l.updateSettings(func(settings *loggerSettings) {
+		settings.name = name
+	})
 }
 
 // SetLevel sets the lowest level of the log entry to the given level.
 // For details, please refer to the comment section of the Level field of
 // the StandardOption structure.
 //
-// Please note that this API is not thread-safe.
+// This function is thread-safe. The change only affects the logger
+// instance itself and not other copies of it.
 func (l *StandardLogger) SetLevel(level Level) {
-	l.level = level
+	l.updateSettings(func(settings *loggerSettings) {
+		settings.level = level
+	})
 }
 
 // SetSampler sets the sampler to the given sampler. For details, please
 // refer to the comment section of the Sampler field of the Option
 // structure.
 //
-// Please note that this API is not thread-safe.
+// This function is thread-safe. The change only affects the logger
+// instance itself and not other copies of it.
 func (l *StandardLogger) SetSampler(sampler Sampler) {
-	l.sampler = sampler
+	l.updateSettings(func(settings *loggerSettings) {
+		settings.sampler = sampler
+	})
 }
 
 // SetLabels sets the label to one or more given labels. For details,
@@ -359,27 +779,41 @@ func (l *StandardLogger) SetSampler(sampler Sampler) {
 // It is worth noting that one or more labels previously set by the
 // logger will be discarded because labels need to be pre-serialized.
 //
-// Please note that this API is not thread-safe.
+// This function is thread-safe. The change only affects the logger
+// instance itself and not other copies of it.
 func (l *StandardLogger) SetLabels(labels ...Label) {
-	l.labels = NewSerializedLabels(labels...)
+	serialized := NewSerializedLabels(labels...)
+	l.updateSettings(func(settings *loggerSettings) {
+		settings.labels = serialized
+	})
 }
 
 // AddHooks adds one or more hooks to the hook chain. For details,
 // please refer to the comment section of the Hooks field of the Option
 // option.
 //
-// Please note that this API is not thread-safe.
+// This function is thread-safe. The change only affects the logger
+// instance itself and not other copies of it.
 func (l *StandardLogger) AddHooks(hooks ...Hook) {
-	l.hooks = append(l.hooks, hooks...)
+	l.updateSettings(func(settings *loggerSettings) {
+		// The hook chain of the current settings may be in use, so a new
+		// hook chain is always created.
+		chain := make([]Hook, 0, len(settings.hooks) + len(hooks))
+		chain = append(chain, settings.hooks...)
+		settings.hooks = append(chain, hooks...)
+	})
 }
 
 // ResetHooks resets the hook chain, and the hooks that have been added
 // will be removed. For details, please refer to the comment section of
 // the Hooks field of the Option option.
 //
-// Please note that this API is not thread-safe.
+// This function is thread-safe. The change only affects the logger
+// instance itself and not other copies of it.
 func (l *StandardLogger) ResetHooks() {
-	l.hooks = []Hook { }
+	l.updateSettings(func(settings *loggerSettings) {
+		settings.hooks = []Hook { }
+	})
 }
 
 // Sync writes the internal cache data of a specific synchronizer to a
@@ -388,8 +822,12 @@ func (l *StandardLogger) ResetHooks() {
 // persistent storage device. For details, please refer to the Sync
 // function of the Syncer interface.
 //
-// Finally, any errors encountered are returned.
+// Finally, any errors encountered are returned. If the logger has been
+// closed, ErrClosed is returned.
 func (l *StandardLogger) Sync() error {
+	if l.IsClosed() {
+		return ErrClosed
+	}
 	for index := 0; index < len(l.exporters); index++ {
 		err := l.exporters[index].Sync()
 
@@ -408,33 +846,60 @@ func (l *StandardLogger) Sync() error {
 // the reference count of the logger. If the logger's reference count is 0,
 // it will actually be closed.
 //
+// After this function is called, the output functions and the Sync
+// function of the logger return ErrClosed instead of using the closed
+// exporters. If the logger has already been closed, ErrClosed is returned.
+//
+// Every exporter is closed even if closing another exporter fails. If more
+// than one exporter fails, a MultiError instance containing all errors is
+// returned. This function can be safely called multiple times.
+//
 // Please note that this function is not guaranteed to succeed. If any
 // errors are encountered, the state of the application may change. The
 // best practice is to exit the application.
 func (l *StandardLogger) Close() error {
+	_, err := l.Shutdown(context.Background())
+	return err
+}
+
+// Shutdown closes all specific exporters like the Close function, but the
+// exporters that implement the Shutdowner interface (such as asynchronous
+// exporters) only wait for the appended log entries to be drained until
+// the given context is done, and the other exporters are waited for to be
+// closed until the given context is done. This allows the application to
+// exit in time, for example, within the termination grace period of a
+// container orchestration system.
+//
+// Finally, it returns the number of log entries abandoned by the exporters
+// and any errors encountered. If the context is done before an exporter is
+// closed, the error of the context is included.
+func (l *StandardLogger) Shutdown(ctx context.Context) (uint64, error) {
 	if !atomic.CompareAndSwapInt32(&l.closed, 0, 1) {
-		return ErrClosed
+		return 0, ErrClosed
 	}
 	references := atomic.AddInt32(l.contextReferences, -1)
 	switch {
 	case references > 0:
 		// The other logger copy is using the logger and cannot be closed
 		// now, otherwise it may cause panic.
-		return nil
+		return 0, nil
 	case references < 0:
 		// This is usually because the application tries to close the
 		// logger repeatedly.
-		return ErrClosed
+		return 0, ErrClosed
 	}
 	l.contextCancel()
 	l.contextWaitGroup.Wait()
+	// Each exporter is closed even if closing a previous exporter failed,
+	// so that no exporter is left open.
+	var abandoned uint64
+	var result error
 	for index := 0; index < len(l.exporters); index++ {
-		err := l.exporters[index].Close()
-		if err != nil {
-			return err
-		}
+		count, err := shutdownExporter(ctx, l.exporters[index])
+		abandoned += count
+		result = appendError(result, err)
 	}
-	return nil
+	return abandoned, result
 }
 
 // IsClosed checks whether the logger instance has been closed.
@@ -442,24 +907,28 @@ func (l *StandardLogger) IsClosed() bool {
 	return atomic.LoadInt32(&l.closed) == 1
 }
 
-// flushHandler calls the Sync function at a given time interval to
-// automatically refresh the internal cache and file system cache until
-// the context has been marked as complete and returns.
+// flushHandler calls the Sync function of the given exporter periodically
+// according to the given flushing option to automatically refresh the
+// internal cache and file system cache until the context has been marked
+// as complete and returns.
 //
 // This function should run in an independent coroutine context.
-func (l *StandardLogger) flushHandler(interval time.Duration) {
-	if interval < (time.Microsecond * 100) {
-		// The interval must not be less than 100 milliseconds.
-		interval = (time.Microsecond * 100)
-	}
+func (l *StandardLogger) flushHandler(exporter Exporter, option FlushingOption) {
 	defer l.contextWaitGroup.Done()
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	timer := time.NewTimer(option.period(random))
+	defer timer.Stop()
 	for {
 		select {
 		case <-l.context.Done():
 			return
-		case <-time.After(interval):
-			// Discard any errors encountered.
-			_ = l.Sync()
+		case <-timer.C:
+			// Errors cannot be returned to any caller, so they are
+			// reported to the diagnostics channel.
+			if err := exporter.Sync(); err != nil {
+				diagnose("automatic flushing failed: %v", err)
+			}
+			timer.Reset(option.period(random))
 		}
 	}
 }
@@ -469,6 +938,16 @@ const (
 	// details, please refer to the comment section of the TextSampler
 	// structure.
 	SamplerText = "text"
+
+	// SamplerField represents the type of sampler as field sampler. For
+	// details, please refer to the comment section of the FieldSampler
+	// structure.
+	SamplerField = "field"
+
+	// SamplerTrace represents the type of sampler as trace sampler. For
+	// details, please refer to the comment section of the TraceSampler
+	// structure.
+	SamplerTrace = "trace"
 )
 
 // SamplingOption is a structure that contains options for sampling log
@@ -515,6 +994,81 @@ func (o *SamplingOption) UseTextOption(option *TextSamplerOption) *SamplingOptio
 	return o
 }
 
+// UseField uses the field sampler (SamplerField constant) that groups log
+// entries by the given field as the value of the option Type. For details,
+// please refer to the comment section of the SamplerField constant. Then
+// return to the option instance itself.
+func (o *SamplingOption) UseField(name string) *SamplingOption {
+	o.Type = SamplerField
+	o.Option = NewFieldSamplerOption(name)
+	return o
+}
+
+// UseFieldOption uses the field sampler (SamplerField constant) as the
+// value of the option Type, and then uses the value of the given option as
+// the value of the option. For details, please refer to the comment section
+// of the SamplerField constant. Then return to the option instance itself.
+func (o *SamplingOption) UseFieldOption(option *FieldSamplerOption) *SamplingOption {
+	o.Type = SamplerField
+	o.Option = option
+	return o
+}
+
+// UseTrace uses the trace sampler (SamplerTrace constant) as the value of
+// the option Type. For details, please refer to the comment section of the
+// SamplerTrace constant. Then return to the option instance itself.
+func (o *SamplingOption) UseTrace() *SamplingOption {
+	o.Type = SamplerTrace
+	o.Option = NewTraceSamplerOption()
+	return o
+}
+
+// UseTraceOption uses the trace sampler (SamplerTrace constant) as the
+// value of the option Type, and then uses the value of the given option as
+// the value of the option. If the value of the given option is nil, the
+// default option is used. For details, please refer to the comment section
+// of the SamplerTrace constant. Then return to the option instance itself.
+func (o *SamplingOption) UseTraceOption(option *TraceSamplerOption) *SamplingOption {
+	o.Type = SamplerTrace
+	if option == nil {
+		option = NewTraceSamplerOption()
+	}
+	o.Option = option
+	return o
+}
+
+// Validate checks whether the values of the options are valid, and returns
+// an error describing the first invalid value.
+func (o *SamplingOption) Validate() error {
+	switch o.Type {
+	case "":
+		return nil
+	case SamplerText:
+		return o.Option.(*TextSamplerOption).Validate()
+	case SamplerField:
+		return o.Option.(*FieldSamplerOption).Validate()
+	case SamplerTrace:
+		return o.Option.(*TraceSamplerOption).Validate()
+	}
+	return ErrInvalidType
+}
+
+// Clone creates and returns a copy of the option instance. The copy does
+// not share the value of the option Option with the option instance, so
+// modifying either of them does not affect the other.
+func (o *SamplingOption) Clone() *SamplingOption {
+	option := *o
+	switch value := o.Option.(type) {
+	case *TextSamplerOption:
+		option.Option = value.Clone()
+	case *FieldSamplerOption:
+		option.Option = value.Clone()
+	case *TraceSamplerOption:
+		option.Option = value.Clone()
+	}
+	return &option
+}
+
 // Build builds and returns a sampler instance.
 func (o *SamplingOption) Build() (Sampler, error) {
 	if len(o.Type) == 0 {
@@ -523,6 +1077,10 @@ func (o *SamplingOption) Build() (Sampler, error) {
 	switch o.Type {
 	case SamplerText:
 		return o.Option.(*TextSamplerOption).Build()
+	case SamplerField:
+		return o.Option.(*FieldSamplerOption).Build()
+	case SamplerTrace:
+		return o.Option.(*TraceSamplerOption).Build()
 	default:
 		return nil, ErrInvalidType
 	}
@@ -554,7 +1112,7 @@ const (
 type EncodingOption struct {
 	// Type represents the type of encoder, and its options are defined
 	// by the constants beginning with Encoder... If the log entry message
-	// does not implement the formatter interface of a specific encoder
+	// does not implement the serializer interface of a specific encoder
 	// type, the encoder may not work. If not provided, the default value
 	// depends on the logger type.
 	Type string
@@ -573,6 +1131,25 @@ type EncodingOption struct {
 	// expensive performance overhead. If not provided, the default value
 	// is false.
 	DisableSourceLocation bool
+
+	// SourceLocationSpan represents the span of the levels of log entries
+	// whose source location is obtained and encoded. For details, please
+	// refer to the comment section of the SourceLocationSpan option of the
+	// EncoderOption structure. If not provided, the value of the encoder
+	// option is used.
+	SourceLocationSpan LevelSpan
+}
+
+// UseSourceLocationSpan uses the span of the given start and end levels as
+// the value of the option SourceLocationSpan. For details, please refer to
+// the comment section of the SourceLocationSpan option. Then return to the
+// option instance itself.
+func (o *EncodingOption) UseSourceLocationSpan(start, end Level) *EncodingOption {
+	o.SourceLocationSpan = LevelSpan {
+		Start: start,
+		End: end,
+	}
+	return o
 }
 
 // UseStandard uses the standard encoder (EncoderStandard constant) as the
@@ -625,16 +1202,36 @@ func (o *EncodingOption) UseJSONOption(option *JSONEncoderOption) *EncodingOptio
 	return o
 }
 
+// Clone creates and returns a copy of the option instance. The copy does
+// not share the value of the option Option with the option instance, so
+// modifying either of them does not affect the other.
+func (o *EncodingOption) Clone() *EncodingOption {
+	option := *o
+	switch value := o.Option.(type) {
+	case *StandardEncoderOption:
+		option.Option = value.Clone()
+	case *JSONEncoderOption:
+		option.Option = value.Clone()
+	}
+	return &option
+}
+
 // Build builds and returns a encoder instance.
 func (o *EncodingOption) Build() (Encoder, error) {
 	switch o.Type {
 	case EncoderStandard:
 		option := o.Option.(*StandardEncoderOption)
 		option.EncodeSourceLocation = !o.DisableSourceLocation
+		if o.SourceLocationSpan != (LevelSpan { }) {
+			option.SourceLocationSpan = o.SourceLocationSpan
+		}
 		return option.Build()
 	case EncoderJSON:
 		option := o.Option.(*JSONEncoderOption)
 		option.EncodeSourceLocation = !o.DisableSourceLocation
+		if o.SourceLocationSpan != (LevelSpan { }) {
+			option.SourceLocationSpan = o.SourceLocationSpan
+		}
 		return option.Build()
 	default:
 		return nil, ErrInvalidType
@@ -738,6 +1335,56 @@ func (o *OutputtingOption) UseDiscard() *OutputtingOption {
 	return o
 }
 
+// Validate checks whether the values of the options are valid, and returns
+// an error describing the first invalid value.
+func (o *OutputtingOption) Validate() error {
+	switch o.Type {
+	case SyncerStandard:
+		return o.Option.(*StandardSyncerOption).Validate()
+	case SyncerFile:
+		return o.Option.(*FileSyncerOption).Validate()
+	case SyncerNetwork:
+		if o.consoleFallback() {
+			return o.Option.(*NetworkSyncerOption).SyncerOption.Validate()
+		}
+		return o.Option.(*NetworkSyncerOption).Validate()
+	case SyncerDiscard:
+		return nil
+	}
+	return ErrInvalidType
+}
+
+// consoleFallback checks whether the network synchronizer of the option
+// uses a Unix protocol that is not supported on the current platform (such
+// as WebAssembly), in which case the standard error is used instead, so
+// that applications sharing the outputting options with such platforms
+// can still output log entries.
+func (o *OutputtingOption) consoleFallback() bool {
+	option, ok := o.Option.(*NetworkSyncerOption)
+	if !ok || unixSocketsSupported {
+		return false
+	}
+	return option.Protocol == ProtocolUnix ||
+		option.Protocol == ProtocolUnixgram
+}
+
+// Clone creates and returns a copy of the option instance. The copy does
+// not share the value of the option Option with the option instance, so
+// modifying either of them does not affect the other. Please note that
+// the instance of the writer of the standard synchronizer is shared.
+func (o *OutputtingOption) Clone() *OutputtingOption {
+	option := *o
+	switch value := o.Option.(type) {
+	case *StandardSyncerOption:
+		option.Option = value.Clone()
+	case *FileSyncerOption:
+		option.Option = value.Clone()
+	case *NetworkSyncerOption:
+		option.Option = value.Clone()
+	}
+	return &option
+}
+
 // Build builds and returns a syncer instance.
 func (o *OutputtingOption) Build() (Syncer, error) {
 	switch o.Type {
@@ -752,10 +1399,19 @@ func (o *OutputtingOption) Build() (Syncer, error) {
 		}
 		return o.Option.(*FileSyncerOption).Build()
 	case SyncerNetwork:
+		option := o.Option.(*NetworkSyncerOption)
 		if o.DisableCache {
-			o.Option.(*NetworkSyncerOption).UseCacheCapacity(0)
+			option.UseCacheCapacity(0)
 		}
-		return o.Option.(*NetworkSyncerOption).Build()
+		if o.consoleFallback() {
+			diagnose("%s network outputting is not supported on %s, " +
+				"falling back to the standard error", option.Protocol,
+				runtime.GOOS)
+			console := NewStandardSyncerOption().UseWriter(os.Stderr)
+			console.SyncerOption = option.SyncerOption
+			return console.Build()
+		}
+		return option.Build()
 	case SyncerDiscard:
 		return NewDiscardSyncer()
 	default:
@@ -799,8 +1455,22 @@ type FlushingOption struct {
 	// automatic flushing is performed, all log entry output operations
 	// on the same log will be blocked.
 	Interval time.Duration
+
+	// Jitter represents the ratio of the interval by which each automatic
+	// flushing is randomly advanced or delayed, which must be between 0
+	// and 1. For example, a jitter of 0.1 with an interval of 1 second
+	// flushes every 0.9 to 1.1 seconds. This prevents many loggers built
+	// at the same time from flushing in the same instant. If the value of
+	// this option is 0, the interval is exact. If not provided, the
+	// default value is 0.1.
+	Jitter float64
 }
 
+// minFlushingInterval represents the minimum interval of automatic
+// flushing. For details, please refer to the comment section of the
+// Interval option of the FlushingOption structure.
+const minFlushingInterval = time.Millisecond * 100
+
 // UseInterval uses the given interval as the value of the Interval option.
 // For details, please refer to the comment section of the Interval option.
 // Then return to the option instance itself.
@@ -809,14 +1479,60 @@ func (o *FlushingOption) UseInterval(interval time.Duration) *FlushingOption {
 	return o
 }
 
+// UseJitter uses the given jitter as the value of the Jitter option. For
+// details, please refer to the comment section of the Jitter option. Then
+// return to the option instance itself.
+func (o *FlushingOption) UseJitter(jitter float64) *FlushingOption {
+	o.Jitter = jitter
+	return o
+}
+
+// Validate checks whether the values of the options are valid, and returns
+// an error describing the first invalid value.
+func (o *FlushingOption) Validate() error {
+	if o.Jitter < 0 || o.Jitter > 1 {
+		return fmt.Errorf("%w: %v", ErrInvalidJitter, o.Jitter)
+	}
+	return nil
+}
+
+// period returns the time period until the next automatic flushing, which
+// is the interval not less than the minimum interval, randomly advanced or
+// delayed by the jitter using the given random source.
+func (o *FlushingOption) period(random *rand.Rand) time.Duration {
+	interval := o.Interval
+	if interval < minFlushingInterval {
+		interval = minFlushingInterval
+	}
+	if o.Jitter <= 0 {
+		return interval
+	}
+	offset := (random.Float64() * 2 - 1) * o.Jitter * float64(interval)
+	return interval + time.Duration(offset)
+}
+
 // NewFlushingOption creates and returns an instance of a flushing option
 // with default optional values.
 func NewFlushingOption() *FlushingOption {
 	return &FlushingOption {
 		Interval: time.Second,
+		Jitter: 0.1,
 	}
 }
 
+// ExporterFlushingOption is a structure that contains options for automatic
+// flushing of a specific exporter of the standard logger, which replace the
+// Flushing option of the standard logger for the exporter.
+type ExporterFlushingOption struct {
+	FlushingOption
+
+	// Index represents the index of the exporter in the Exporters option
+	// of the standard logger, to which the flushing option applies. The
+	// exporter is identified by its index rather than by its value, since
+	// the values of some exporters cannot be compared.
+	Index int
+}
+
 // StandardOption is a structure that contains options for the standard
 // logger.
 type StandardOption struct {
@@ -864,8 +1580,19 @@ type StandardOption struct {
 	// to the persistent storage device. For details, see the comment
 	// section of the FlushingOption structure. If not provided, the
 	// default value depends on the type of logger.
+	//
+	// Each exporter is flushed independently, so that a slow exporter does
+	// not delay the flushing of the other exporters.
 	Flushing FlushingOption
 
+	// ExporterFlushing represents the options for automatic flushing of
+	// specific exporters of the Exporters option, which replace the value
+	// of the Flushing option for the exporters. For example, an exporter
+	// that writes to a remote storage device can be flushed less often
+	// than the local exporters. If not provided, all exporters use the
+	// value of the Flushing option.
+	ExporterFlushing []ExporterFlushingOption
+
 	// Hooks represent a set of log entry hooks, and each log entry to be
 	// output will be passed to each log entry hook so that the log entry
 	// has the opportunity to process it before output. For example, one or
@@ -877,6 +1604,7 @@ type StandardOption struct {
 	//
 	// Please note that this option slice will be reused during the build
 	// process, and any side effects of external modifications are undefined.
+	// To derive multiple loggers from the same option, use the Clone function.
 	Hooks []Hook
 
 	// Labels represents one or more labels related to the logger. Each label
@@ -889,6 +1617,80 @@ type StandardOption struct {
 	// For details, please refer to the annotation section of the Label
 	// structure.
 	Labels Labels
+
+	// SourceLocationLevel represents the minimum level of the log entries
+	// whose source location is obtained, unless the source location is
+	// disabled by the Encoding option. For details, please refer to the
+	// comment section of the SourceLocationLevel option of the Option
+	// structure. If not provided, the default value is LevelDebug.
+	SourceLocationLevel Level
+
+	// CallerSkip represents the number of additional stack frames to
+	// ascend when obtaining the source location of log entries. For
+	// details, please refer to the comment section of the CallerSkip
+	// option of the Option structure. If not provided, the default value
+	// is 0.
+	CallerSkip int
+
+	// Clock represents the clock used to obtain the generation time of
+	// each log entry. If not provided, the current local time of the
+	// system is used. For details, please refer to the comment section
+	// of the Clock interface.
+	Clock Clock
+
+	// ClockResolution represents the resolution of the coarse clock used
+	// to obtain the generation time of each log entry. If the value is
+	// positive, the time of the clock of the Clock option is cached and
+	// refreshed by a background coroutine at the given resolution (such
+	// as 1 millisecond), which trades the precision of timestamps for
+	// reduced overhead in extreme throughput scenarios. For details,
+	// please refer to the comment section of the CoarseClock structure.
+	// If not provided, the default value is 0, which means that the clock
+	// is read for each log entry.
+	ClockResolution time.Duration
+
+	// Sequence represents whether to generate a sequence number for each
+	// log entry. For details, please refer to the comment section of the
+	// Sequence field of the Entry structure. If not provided, the default
+	// value is false.
+	Sequence bool
+
+	// ErrorHandler represents the function called with each error returned
+	// by the exporters. The error is an instance of the ExporterError
+	// structure that contains the failing exporter. The function is called
+	// on the output path, so it should return quickly. If not provided,
+	// errors are only returned to the caller.
+	ErrorHandler func(error)
+
+	// BestEffort represents whether to continue exporting a log entry to
+	// the remaining exporters after an exporter fails. In best-effort mode
+	// the errors of exporters are only passed to the ErrorHandler option
+	// and are not returned to the caller, so that one failing exporter (for
+	// example, a network exporter) does not block other exporters or bubble
+	// errors into hot paths. If not provided, the default value is false.
+	BestEffort bool
+
+	// Profiling represents whether to output each log entry with pprof
+	// labels and runtime trace events. For details, please refer to the
+	// comment section of the Profiling option of the Option structure. If
+	// not provided, the default value is false.
+	Profiling bool
+
+	// Development represents whether the logger is in development mode,
+	// in which the DPanic... APIs panic after the log entry is output. For
+	// details, please refer to the comment section of the Development
+	// option of the Option structure. If not provided, the default value
+	// is false.
+	Development bool
+
+	// Exporters represents additional log entry exporters, to which each
+	// log entry is exported after the exporters built from the Outputting
+	// and ErrorOutputting options. The additional exporters are closed
+	// together with the logger. If not provided, no additional exporters
+	// are used by default.
+	//
+	// For details, see the comment section of the Exporter interface.
+	Exporters []Exporter
 }
 
 // UseName uses the given name as the value of the option Name. For details,
@@ -923,6 +1725,87 @@ func (o *StandardOption) UseLabels(labels ...Label) *StandardOption {
 	return o
 }
 
+// UseClock uses the given clock as the value of the option Clock. For
+// details, please refer to the comment section of the Clock option. Then
+// return to the option instance itself.
+func (o *StandardOption) UseClock(clock Clock) *StandardOption {
+	o.Clock = clock
+	return o
+}
+
+// UseClockResolution uses the given resolution as the value of the option
+// ClockResolution. For details, please refer to the comment section of the
+// ClockResolution option. Then return to the option instance itself.
+func (o *StandardOption) UseClockResolution(resolution time.Duration) *StandardOption {
+	o.ClockResolution = resolution
+	return o
+}
+
+// UseSequence enables the option Sequence. For details, please refer to
+// the comment section of the Sequence option. Then return to the option
+// instance itself.
+func (o *StandardOption) UseSequence() *StandardOption {
+	o.Sequence = true
+	return o
+}
+
+// UseErrorHandler uses the given function as the value of the option
+// ErrorHandler. For details, please refer to the comment section of the
+// ErrorHandler option. Then return to the option instance itself.
+func (o *StandardOption) UseErrorHandler(handler func(error)) *StandardOption {
+	o.ErrorHandler = handler
+	return o
+}
+
+// UseSourceLocationLevel uses the given log level as the value of the
+// option SourceLocationLevel. For details, please refer to the comment
+// section of the SourceLocationLevel option. Then return to the option
+// instance itself.
+func (o *StandardOption) UseSourceLocationLevel(level Level) *StandardOption {
+	o.SourceLocationLevel = level
+	return o
+}
+
+// UseCallerSkip uses the given number of stack frames as the value of the
+// option CallerSkip. For details, please refer to the comment section of
+// the CallerSkip option. Then return to the option instance itself.
+func (o *StandardOption) UseCallerSkip(skip int) *StandardOption {
+	o.CallerSkip = skip
+	return o
+}
+
+// UseBestEffort enables the option BestEffort. For details, please refer
+// to the comment section of the BestEffort option. Then return to the
+// option instance itself.
+func (o *StandardOption) UseBestEffort() *StandardOption {
+	o.BestEffort = true
+	return o
+}
+
+// UseDevelopment enables the option Development. For details, please refer
+// to the comment section of the Development option. Then return to the
+// option instance itself.
+func (o *StandardOption) UseDevelopment() *StandardOption {
+	o.Development = true
+	return o
+}
+
+// UseProfiling enables the option Profiling. For details, please refer
+// to the comment section of the Profiling option. Then return to the
+// option instance itself.
+func (o *StandardOption) UseProfiling() *StandardOption {
+	o.Profiling = true
+	return o
+}
+
+// UseExporters appends the given one or more exporters to the o.Exporters
+// option slice, and then returns the option instance itself. For details,
+// please refer to the comment section of the o.Exporters option.
+func (o *StandardOption) UseExporters(exporters ...Exporter) *StandardOption {
+	o.Exporters = append(o.Exporters, exporters...)
+	return o
+}
+
 // UseSampling uses the given sampling option as the value of option Sampling.
 // For details, please refer to the comment section of the Sampling option.
 // Then return to the option instance itself.
@@ -980,6 +1863,20 @@ func (o *StandardOption) DisableSampling() *StandardOption {
 	return o
 }
 
+// UseExporterFlushing appends the given exporter to the o.Exporters option,
+// and uses the given flushing option as the option for automatic flushing
+// of the exporter. For details, please refer to the comment section of the
+// ExporterFlushing option. Then return to the option instance itself.
+func (o *StandardOption) UseExporterFlushing(exporter Exporter,
+	option *FlushingOption) *StandardOption {
+	o.Exporters = append(o.Exporters, exporter)
+	o.ExporterFlushing = append(o.ExporterFlushing, ExporterFlushingOption {
+		FlushingOption: *option,
+		Index: len(o.Exporters) - 1,
+	})
+	return o
+}
+
 // DisableFlushing Disables automatic flushing of cached log entry data.
 // For details, see Flushing option. Then return to the option instance
 // itself.
@@ -988,8 +1885,72 @@ func (o *StandardOption) DisableFlushing() *StandardOption {
 	return o
 }
 
+// Clone creates and returns a deep copy of the option instance, so that
+// multiple loggers can be derived from the same base option without side
+// effects. The copy does not share any option slice or sub-option with the
+// option instance, but the hook, exporter, clock and writer instances
+// themselves are shared.
+func (o *StandardOption) Clone() *StandardOption {
+	option := *o
+	option.Sampling = *o.Sampling.Clone()
+	option.Encoding = *o.Encoding.Clone()
+	option.Outputting = *o.Outputting.Clone()
+	option.ErrorOutputting = *o.ErrorOutputting.Clone()
+	option.Hooks = append([]Hook(nil), o.Hooks...)
+	option.Labels = append(Labels(nil), o.Labels...)
+	option.Exporters = append([]Exporter(nil), o.Exporters...)
+	option.ExporterFlushing = append([]ExporterFlushingOption(nil),
+		o.ExporterFlushing...)
+	return &option
+}
+
+// Validate checks whether the values of the options are valid, and returns
+// an error describing the first invalid value, so that misconfiguration
+// can be detected before the logger is built. The Build function calls
+// this function first.
+func (o *StandardOption) Validate() error {
+	if err := o.Level.Validate(); err != nil {
+		return err
+	}
+	if err := o.SourceLocationLevel.Validate(); err != nil {
+		return err
+	}
+	if o.CallerSkip < 0 {
+		return ErrInvalidCallerSkip
+	}
+	if o.ClockResolution < 0 {
+		return ErrInvalidClockResolution
+	}
+	if err := o.Sampling.Validate(); err != nil {
+		return err
+	}
+	if err := o.Outputting.Validate(); err != nil {
+		return err
+	}
+	if err := o.ErrorOutputting.Validate(); err != nil {
+		return err
+	}
+	if err := o.Flushing.Validate(); err != nil {
+		return err
+	}
+	for index := range o.ExporterFlushing {
+		option := &o.ExporterFlushing[index]
+		if option.Index < 0 || option.Index >= len(o.Exporters) {
+			return fmt.Errorf("%w: %d", ErrInvalidExporterIndex,
+				option.Index)
+		}
+		if err := option.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Build builds and returns a standard logger instance.
 func (o *StandardOption) Build() (*StandardLogger, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
 	sampler, err := o.Sampling.Build()
 	if err != nil {
 		return nil, err
@@ -1025,18 +1986,39 @@ func (o *StandardOption) Build() (*StandardLogger, error) {
 		return nil, err
 	}
 
+	clock := o.Clock
+	var coarseClock *CoarseClock
+	if o.ClockResolution > 0 {
+		coarseClock = NewCoarseClock(clock, o.ClockResolution)
+		clock = coarseClock
+	}
+
+	sourceLevel := o.SourceLocationLevel
+	if span := encoder.Option().SourceLocationSpan; span != (LevelSpan { }) &&
+		span.Start > sourceLevel {
+		sourceLevel = span.Start
+	}
+
 	logger, err := (&Option {
 		Name: o.Name,
 		Level: o.Level,
 		Sampler: sampler,
 		Hooks: o.Hooks,
-		Exporters: []Exporter {
+		Exporters: append([]Exporter {
 			exporter,
 			errorExporter,
-		},
+		}, o.Exporters...),
 		Labels: o.Labels,
 		DisableSourceLocation: (!encoder.Option().
 			EncodeSourceLocation),
+		SourceLocationLevel: sourceLevel,
+		CallerSkip: o.CallerSkip,
+		Clock: clock,
+		Sequence: o.Sequence,
+		ErrorHandler: o.ErrorHandler,
+		BestEffort: o.BestEffort,
+		Profiling: o.Profiling,
+		Development: o.Development,
 	}).Build()
 
 	if err != nil {
@@ -1060,13 +2042,46 @@ func (o *StandardOption) Build() (*StandardLogger, error) {
 	// repeated close logger.
 	atomic.AddInt32(instance.contextReferences, 1)
 
-	if o.Flushing.Interval > 0 {
+	if coarseClock != nil {
 		instance.contextWaitGroup.Add(1)
-		go instance.flushHandler(o.Flushing.Interval)
+		go func() {
+			defer instance.contextWaitGroup.Done()
+			coarseClock.Run(instance.context)
+		}()
+	}
+
+	// The exporters of the Exporters option follow the exporters built
+	// from the Outputting and ErrorOutputting options.
+	for index, exporter := range instance.exporters {
+		option := o.flushing(index - 2)
+		if option.Interval > 0 {
+			instance.contextWaitGroup.Add(1)
+			go instance.flushHandler(exporter, option)
+		}
 	}
 	return instance, nil
 }
 
+// flushing returns the option for automatic flushing of the exporter with
+// the given index in the Exporters option. For details, please refer to the
+// comment section of the ExporterFlushing option.
+func (o *StandardOption) flushing(index int) FlushingOption {
+	for position := range o.ExporterFlushing {
+		if o.ExporterFlushing[position].Index == index {
+			return o.ExporterFlushing[position].FlushingOption
+		}
+	}
+	return o.Flushing
+}
+
+// MustBuild builds and returns a standard logger instance. If any errors
+// are encountered, it panics with an error describing the failure.
+func (o *StandardOption) MustBuild() *StandardLogger {
+	instance, err := o.Build()
+	mustBuild("standard logger", err)
+	return instance
+}
+
 // NewStandardOption creates and returns an instance of the standard logger
 // option with default optional values.
 func NewStandardOption() *StandardOption {
@@ -1081,9 +2096,16 @@ func NewStandardOption() *StandardOption {
 }
 
 // NewStandard creates and returns a standard logger instance using the
-// default optional values.
-func NewStandard() (*StandardLogger, error) {
-	return NewStandardOption().Build()
+// default optional values changed by the given option functions.
+func NewStandard(options ...OptionFunc) (*StandardLogger, error) {
+	return NewStandardOption().Apply(options...).Build()
+}
+
+// MustNewStandard creates and returns a standard logger instance using the
+// default optional values changed by the given option functions. If any
+// errors are encountered, it panics with an error describing the failure.
+func MustNewStandard(options ...OptionFunc) *StandardLogger {
+	return NewStandardOption().Apply(options...).MustBuild()
 }
 
 // NewStandardBenchmark creates and returns an instance of a standard
@@ -23,9 +23,17 @@
 package santa
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"io/ioutil"
+	"math/rand"
 	"net"
 	"os"
+	"runtime"
+	"runtime/trace"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -47,12 +55,12 @@ func TestLoggerOption(t *testing.T) {
 	logger, err := option.Build()
 	assert.NoError(t, err, "Unexpected build error")
 
-	assert.Equal(t, 1, logger.labels.Count(), "Unexpected instance error")
+	assert.Equal(t, 1, logger.loadSettings().labels.Count(), "Unexpected instance error")
 	assert.Len(t, logger.exporters, 1, "Unexpected instance error")
 	assert.Equal(t, exporter, logger.exporters[0], "Unexpected instance error")
-	assert.Equal(t, option.Sampler, logger.sampler, "Unexpected instance error")
-	assert.Equal(t, option.Level, logger.level, "Unexpected instance error")
-	assert.Equal(t, option.Name, logger.name, "Unexpected instance error")
+	assert.Equal(t, option.Sampler, logger.loadSettings().sampler, "Unexpected instance error")
+	assert.Equal(t, option.Level, logger.loadSettings().level, "Unexpected instance error")
+	assert.Equal(t, option.Name, logger.loadSettings().name, "Unexpected instance error")
 }
 
 type testExporter struct {
@@ -164,6 +172,24 @@ func TestSamplingOption(t *testing.T) {
 
 	assert.IsType(t, &TextSampler { }, sampler,
 		"Unexpected instance error")
+
+	option.UseField("userID")
+	assert.Equal(t, SamplerField, option.Type, "Unexpected option value")
+
+	sampler, err = option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	assert.IsType(t, &FieldSampler { }, sampler,
+		"Unexpected instance error")
+
+	option.UseTraceOption(nil)
+	assert.Equal(t, SamplerTrace, option.Type, "Unexpected option value")
+
+	sampler, err = option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	assert.IsType(t, &TraceSampler { }, sampler,
+		"Unexpected instance error")
 }
 
 func TestOutputtingOption(t *testing.T) {
@@ -213,9 +239,10 @@ func TestOutputtingOption(t *testing.T) {
 
 	closed := make(chan byte, 1)
 
+	listener, err := net.Listen("tcp", "127.0.0.1:10001")
+	assert.NoError(t, err, "Unexpected listen 127.0.0.1:10001 error")
+
 	go func() {
-		listener, err := net.Listen("tcp", "127.0.0.1:10001")
-		assert.NoError(t, err, "Unexpected listen 127.0.0.1:10001 error")
 		defer listener.Close()
 		for {
 			connect, err := listener.Accept()
@@ -250,6 +277,114 @@ func TestFlushingOption(t *testing.T) {
 	option.UseInterval(time.Minute)
 
 	assert.Equal(t, time.Minute, option.Interval, "Unexpected option value")
+
+	random := rand.New(rand.NewSource(1))
+	option.UseInterval(time.Microsecond * 100).UseJitter(0)
+	assert.Equal(t, time.Millisecond * 100, option.period(random),
+		"Unexpected flushing period")
+
+	option.UseInterval(time.Second).UseJitter(0.1)
+	for index := 0; index < 100; index++ {
+		period := option.period(random)
+		assert.True(t, period >= time.Millisecond * 900 &&
+			period <= time.Millisecond * 1100, "Unexpected flushing period")
+	}
+
+	assert.NoError(t, option.Validate(), "Unexpected validate error")
+	option.UseJitter(1.5)
+	assert.True(t, errors.Is(option.Validate(), ErrInvalidJitter),
+		"Unexpected validate error")
+}
+
+type testSyncExporter struct {
+	testRecordExporter
+	syncs int32
+}
+
+func (e *testSyncExporter) Sync() error {
+	atomic.AddInt32(&e.syncs, 1)
+	return nil
+}
+
+func TestStandardLoggerExporterFlushing(t *testing.T) {
+	flushed := &testSyncExporter { }
+	unflushed := &testSyncExporter { }
+
+	logger, err := NewStandardOption().
+		DisableFlushing().
+		UseExporters(unflushed).
+		UseExporterFlushing(flushed, NewFlushingOption().
+			UseInterval(time.Millisecond * 100)).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	for index := 0; index < 100; index++ {
+		if atomic.LoadInt32(&flushed.syncs) > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+	assert.True(t, atomic.LoadInt32(&flushed.syncs) > 0,
+		"Unexpected flushing count")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&unflushed.syncs),
+		"Unexpected flushing count")
+}
+
+// testValueExporter is an exporter whose values cannot be compared, because
+// it is a structure that contains a slice.
+type testValueExporter struct {
+	labels []string
+	syncs *int32
+}
+
+func (e testValueExporter) Export(entry *Entry) error {
+	return nil
+}
+
+func (e testValueExporter) Sync() error {
+	atomic.AddInt32(e.syncs, 1)
+	return nil
+}
+
+func (e testValueExporter) Close() error {
+	return nil
+}
+
+func TestStandardLoggerExporterFlushingValues(t *testing.T) {
+	flushed := testValueExporter {
+		labels: []string { "flushed" },
+		syncs: new(int32),
+	}
+	unflushed := testValueExporter {
+		labels: []string { "unflushed" },
+		syncs: new(int32),
+	}
+
+	logger, err := NewStandardOption().
+		DisableFlushing().
+		UseExporters(unflushed).
+		UseExporterFlushing(flushed, NewFlushingOption().
+			UseInterval(time.Millisecond * 100)).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	for index := 0; index < 100; index++ {
+		if atomic.LoadInt32(flushed.syncs) > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+	assert.True(t, atomic.LoadInt32(flushed.syncs) > 0,
+		"Unexpected flushing count")
+	assert.Equal(t, int32(0), atomic.LoadInt32(unflushed.syncs),
+		"Unexpected flushing count")
+
+	option := NewStandardOption()
+	option.ExporterFlushing = []ExporterFlushingOption {
+		{ FlushingOption: *NewFlushingOption(), Index: 0 },
+	}
+	assert.True(t, errors.Is(option.Validate(), ErrInvalidExporterIndex),
+		"Unexpected validate error")
 }
 
 func TestStandardLoggerOption(t *testing.T) {
@@ -290,13 +425,13 @@ func TestStandardLoggerOption(t *testing.T) {
 	logger, err := option.Build()
 	assert.NoError(t, err, "Unexpected build error")
 
-	assert.NotNil(t, logger.sampler, "Unexpected instance error")
+	assert.NotNil(t, logger.loadSettings().sampler, "Unexpected instance error")
 	assert.Len(t, logger.exporters, 2, "Unexpected instance error")
 	assert.NotNil(t, logger.exporters[0], "Unexpected instance error")
 	assert.NotNil(t, logger.exporters[1], "Unexpected instance error")
 
-	assert.Equal(t, option.Level, logger.level, "Unexpected instance error")
-	assert.Equal(t, option.Name, logger.name, "Unexpected instance error")
+	assert.Equal(t, option.Level, logger.loadSettings().level, "Unexpected instance error")
+	assert.Equal(t, option.Name, logger.loadSettings().name, "Unexpected instance error")
 
 	option.DisableCache()
 	option.DisableFlushing()
@@ -364,22 +499,58 @@ func TestStandardLoggerPrint(t *testing.T) {
 	assert.NoError(t, logger.Close(), "Unexpected close error")
 }
 
+func TestStandardLoggerPrintString(t *testing.T) {
+	exporter := &testRecordExporter { }
+	option := NewStandardOption().UseExporters(exporter)
+	option.Outputting.UseDiscard()
+	option.ErrorOutputting.UseDiscard()
+
+	logger, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	assert.NoError(t, logger.DebugString("Hello Test!"),
+		"Unexpected print error")
+	assert.NoError(t, logger.InfoString("Hello Test!"),
+		"Unexpected print error")
+	assert.NoError(t, logger.WarningString("Hello Test!"),
+		"Unexpected print error")
+	assert.NoError(t, logger.ErrorString("Hello Test!"),
+		"Unexpected print error")
+	assert.NoError(t, logger.FatalString("Hello Test!"),
+		"Unexpected print error")
+	assert.NoError(t, logger.PrintString(LevelInfo, "Hello Test!"),
+		"Unexpected print error")
+
+	assert.Len(t, exporter.entries, 6, "Unexpected log entries")
+	for index, level := range []Level { LevelDebug, LevelInfo,
+		LevelWarning, LevelError, LevelFatal, LevelInfo } {
+		assert.Equal(t, level, exporter.entries[index].Level,
+			"Unexpected log entry")
+		assert.Equal(t, StringMessage("Hello Test!"),
+			exporter.entries[index].Message, "Unexpected log entry")
+		assert.Contains(t, exporter.entries[index].SourceLocation.File,
+			"logger_test.go", "Unexpected source location")
+	}
+
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+}
+
 func TestStandardLoggerSet(t *testing.T) {
 	logger, err := NewStandard()
 	assert.NoError(t, err, "Unexpected create error")
 	assert.NotNil(t, logger, "Unexpected nil value")
 
 	logger.SetName("testing")
-	assert.Equal(t, "testing", logger.name, "Unexpected instance error")
+	assert.Equal(t, "testing", logger.loadSettings().name, "Unexpected instance error")
 
 	logger.SetLevel(LevelFatal)
-	assert.Equal(t, LevelFatal, logger.level, "Unexpected instance error")
+	assert.Equal(t, LevelFatal, logger.loadSettings().level, "Unexpected instance error")
 
 	logger.SetSampler(nil)
-	assert.Equal(t, nil, logger.sampler, "Unexpected instance error")
+	assert.Equal(t, nil, logger.loadSettings().sampler, "Unexpected instance error")
 
 	logger.SetLabels(NewLabel("name", "testing"))
-	assert.Equal(t, 1, logger.labels.count, "Unexpected instance error")
+	assert.Equal(t, 1, logger.loadSettings().labels.count, "Unexpected instance error")
 
 	assert.NoError(t, logger.Close(), "Unexpected close error")
 }
@@ -393,13 +564,216 @@ func TestStandardLoggerDuplicate(t *testing.T) {
 	assert.NotNil(t, instance, "Unexpected nil value")
 
 	instance.SetName("testing")
-	assert.Equal(t, "testing", instance.name, "Unexpected instance error")
-	assert.Equal(t, "", logger.name, "Unexpected instance error")
+	assert.Equal(t, "testing", instance.loadSettings().name, "Unexpected instance error")
+	assert.Equal(t, "", logger.loadSettings().name, "Unexpected instance error")
 
 	assert.NoError(t, instance.Close(), "Unexpected close error")
 	assert.NoError(t, logger.Close(), "Unexpected close error")
 }
 
+func TestLoggerOutputCallDepth(t *testing.T) {
+	exporter := &testRecordExporter { }
+
+	option := NewOption()
+	option.Exporters = append(option.Exporters, exporter)
+
+	logger, err := option.Build()
+	assert.NoError(t, err, "Unexpected create error")
+
+	assert.NoError(t, logger.Output(1, LevelInfo, StringMessage(
+		"Hello Test!")), "Unexpected print error")
+	assert.NoError(t, logger.Print(LevelInfo, StringMessage(
+		"Hello Test!")), "Unexpected print error")
+
+	assert.Len(t, exporter.entries, 2, "Unexpected log entries")
+	for index := 0; index < len(exporter.entries); index++ {
+		assert.Contains(t, exporter.entries[index].SourceLocation.File,
+			"logger_test.go", "Unexpected source location")
+		assert.Contains(t, runtime.FuncForPC(exporter.entries[index].
+			SourceLocation.Proc).Name(), "TestLoggerOutputCallDepth",
+			"Unexpected source location")
+	}
+}
+
+func TestLoggerSourceLocationLevel(t *testing.T) {
+	exporter := &testRecordExporter { }
+
+	option := NewStandardOption().UseExporters(exporter).
+		UseSourceLocationLevel(LevelWarning)
+	option.Outputting.UseDiscard()
+	option.ErrorOutputting.UseDiscard()
+
+	logger, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	for index := 0; index < 2; index++ {
+		assert.NoError(t, logger.InfoString("Hello Test!"),
+			"Unexpected print error")
+		assert.NoError(t, logger.WarningString("Hello Test!"),
+			"Unexpected print error")
+	}
+
+	assert.Len(t, exporter.entries, 4, "Unexpected log entries")
+	for index := 0; index < len(exporter.entries); index += 2 {
+		assert.False(t, exporter.entries[index].SourceLocation.Parsed,
+			"Unexpected source location")
+		location := exporter.entries[index + 1].SourceLocation
+		assert.True(t, location.Parsed, "Unexpected source location")
+		assert.Contains(t, location.File, "logger_test.go",
+			"Unexpected source location")
+		assert.Contains(t, location.FunctionName(),
+			"TestLoggerSourceLocationLevel", "Unexpected source location")
+	}
+	assert.Equal(t, exporter.entries[1].SourceLocation,
+		exporter.entries[3].SourceLocation, "Unexpected source location")
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+
+	_, err = NewStandardOption().UseSourceLocationLevel(Level(10)).Build()
+	assert.True(t, errors.Is(err, ErrInvalidLevel), "Unexpected build error")
+}
+
+// testWrappedInfo is a helper function that wraps the output API of the
+// given structured logger, which is reported as the source location of
+// log entries unless the CallerSkip option skips it.
+func testWrappedInfo(logger *StructLogger) error {
+	return logger.Infos("Hello Test!")
+}
+
+func TestLoggerCallerSkip(t *testing.T) {
+	exporter := &testRecordExporter { }
+
+	standardOption := NewStandardOption().UseExporters(exporter)
+	standardOption.Outputting.UseDiscard()
+	standardOption.ErrorOutputting.UseDiscard()
+	standard, err := standardOption.Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	templateOption := NewTemplateOption().UseExporters(exporter)
+	templateOption.Outputting.UseDiscard()
+	templateOption.ErrorOutputting.UseDiscard()
+	template, err := templateOption.Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	structOption := NewStructOption().UseExporters(exporter)
+	structOption.Outputting.UseDiscard()
+	structOption.ErrorOutputting.UseDiscard()
+	structure, err := structOption.Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	assert.NoError(t, standard.InfoString("Hello Test!"),
+		"Unexpected print error")
+	assert.NoError(t, standard.Decorator().Info(StringMessage(
+		"Hello Test!")), "Unexpected print error")
+	assert.NoError(t, template.Infof("Hello %s!", "Test"),
+		"Unexpected print error")
+	assert.NoError(t, template.Decorator().Infof("Hello %s!", "Test"),
+		"Unexpected print error")
+	assert.NoError(t, structure.Infos("Hello Test!"),
+		"Unexpected print error")
+	assert.NoError(t, structure.Infow("Hello Test!"),
+		"Unexpected print error")
+	assert.NoError(t, structure.Decorator().Infos("Hello Test!"),
+		"Unexpected print error")
+	assert.NoError(t, structure.WithContext(context.Background()).
+		Infos("Hello Test!"), "Unexpected print error")
+
+	skipped, err := structOption.UseCallerSkip(1).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.NoError(t, testWrappedInfo(skipped), "Unexpected print error")
+
+	assert.Len(t, exporter.entries, 9, "Unexpected log entries")
+	for index := 0; index < len(exporter.entries); index++ {
+		location := exporter.entries[index].SourceLocation
+		assert.Contains(t, location.File, "logger_test.go",
+			"Unexpected source location")
+		assert.Equal(t, "github.com/nobody-night/santa.TestLoggerCallerSkip",
+			location.FunctionName(), "Unexpected source location")
+	}
+
+	assert.NoError(t, testWrappedInfo(structure), "Unexpected print error")
+	assert.Equal(t, "github.com/nobody-night/santa.testWrappedInfo",
+		exporter.entries[9].SourceLocation.FunctionName(),
+		"Unexpected source location")
+
+	_, err = NewStandardOption().UseCallerSkip(-1).Build()
+	assert.True(t, errors.Is(err, ErrInvalidCallerSkip),
+		"Unexpected build error")
+	_, err = (&Option { CallerSkip: -1 }).Build()
+	assert.True(t, errors.Is(err, ErrInvalidCallerSkip),
+		"Unexpected build error")
+
+	assert.NoError(t, standard.Close(), "Unexpected close error")
+	assert.NoError(t, template.Close(), "Unexpected close error")
+	assert.NoError(t, structure.Close(), "Unexpected close error")
+	assert.NoError(t, skipped.Close(), "Unexpected close error")
+}
+
+func TestStandardLoggerConcurrentSettings(t *testing.T) {
+	logger, err := NewStandardBenchmark(false, EncoderJSON)
+	assert.NoError(t, err, "Unexpected create error")
+
+	hook := NewSimpleHook(func(entry *Entry) error {
+		return nil
+	})
+
+	group := sync.WaitGroup { }
+	for index := 0; index < 4; index++ {
+		group.Add(2)
+		go func() {
+			defer group.Done()
+			for count := 0; count < 100; count++ {
+				logger.SetName("testing")
+				logger.SetLevel(LevelInfo)
+				logger.SetLabels(NewLabel("instanceId", "1"))
+				logger.AddHooks(hook)
+				logger.ResetHooks()
+			}
+		}()
+		go func() {
+			defer group.Done()
+			for count := 0; count < 100; count++ {
+				_ = logger.Info(StringMessage("Hello Test!"))
+			}
+		}()
+	}
+	group.Wait()
+
+	assert.Equal(t, "testing", logger.loadSettings().name,
+		"Unexpected instance error")
+	assert.Len(t, logger.loadSettings().hooks, 0,
+		"Unexpected instance error")
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+}
+
+func TestStandardLoggerReferences(t *testing.T) {
+	logger, err := NewStandard()
+	assert.NoError(t, err, "Unexpected create error")
+
+	instance := logger.Duplicate()
+	assert.NotNil(t, instance, "Unexpected nil value")
+	assert.Equal(t, int32(2), *logger.contextReferences,
+		"Unexpected reference count")
+
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+	assert.Equal(t, ErrClosed, logger.Close(), "Unexpected close error")
+	assert.Nil(t, logger.Duplicate(), "Unexpected duplicate result")
+	assert.False(t, instance.IsClosed(), "Unexpected closed state")
+
+	duplicate := instance.Duplicate()
+	assert.NotNil(t, duplicate, "Unexpected nil value")
+	assert.False(t, duplicate.IsClosed(), "Unexpected closed state")
+	assert.NoError(t, duplicate.Close(), "Unexpected close error")
+
+	assert.NoError(t, instance.Close(), "Unexpected close error")
+	assert.Equal(t, int32(0), *logger.contextReferences,
+		"Unexpected reference count")
+
+	// A failed duplicate must not revive the closed logger.
+	assert.Nil(t, instance.Duplicate(), "Unexpected duplicate result")
+	assert.Equal(t, int32(0), *logger.contextReferences,
+		"Unexpected reference count")
+}
+
 func TestStandardLoggerClosed(t *testing.T) {
 	logger, err := NewStandard()
 	assert.NoError(t, err, "Unexpected create error")
@@ -413,4 +787,441 @@ func TestStandardLoggerClosed(t *testing.T) {
 
 	closed = logger.IsClosed()
 	assert.Equal(t, true, closed, "Unexpected return value")
+
+	assert.Equal(t, ErrClosed, logger.Info(StringMessage("Hello Test!")),
+		"Unexpected print error")
+	assert.Equal(t, ErrClosed, logger.Sync(), "Unexpected sync error")
+	assert.Equal(t, ErrClosed, logger.Close(), "Unexpected close error")
+}
+
+func TestLoggerSequence(t *testing.T) {
+	exporter := &testRecordExporter { }
+
+	option := NewOption()
+	option.Sequence = true
+	option.Exporters = append(option.Exporters, exporter)
+
+	logger, err := option.Build()
+	assert.NoError(t, err, "Unexpected create error")
+
+	for index := 0; index < 3; index++ {
+		assert.NoError(t, logger.Print(LevelInfo, StringMessage(
+			"Hello Test!")), "Unexpected print error")
+	}
+
+	decorator := logger.Decorator()
+	assert.NoError(t, decorator.Print(LevelInfo, StringMessage(
+		"Hello Test!")), "Unexpected print error")
+	decorator.Free()
+
+	assert.Len(t, exporter.entries, 4, "Unexpected log entries")
+	for index := 0; index < len(exporter.entries); index++ {
+		assert.Equal(t, uint64(index + 1), exporter.entries[index].Sequence,
+			"Unexpected log entry sequence")
+	}
+
+	exporter.entries = nil
+	option.Sequence = false
+
+	logger, err = option.Build()
+	assert.NoError(t, err, "Unexpected create error")
+	assert.NoError(t, logger.Print(LevelInfo, StringMessage(
+		"Hello Test!")), "Unexpected print error")
+	assert.Equal(t, uint64(0), exporter.entries[0].Sequence,
+		"Unexpected log entry sequence")
+}
+
+// testAlternateSampler is a sampler that samples every other log entry.
+type testAlternateSampler struct {
+	count int
+}
+
+func (s *testAlternateSampler) Sample(entry *Entry) bool {
+	s.count++
+	return s.count % 2 == 1
+}
+
+func TestLoggerSequenceSampled(t *testing.T) {
+	exporter := &testRecordExporter { }
+
+	option := NewOption()
+	option.Sequence = true
+	option.Sampler = &testAlternateSampler { }
+	option.Hooks = append(option.Hooks, NewSimpleHook(
+		func(entry *Entry) error {
+			if entry.Level == LevelDebug {
+				return errors.New("Error")
+			}
+			return nil
+		}))
+	option.Exporters = append(option.Exporters, exporter)
+
+	logger, err := option.Build()
+	assert.NoError(t, err, "Unexpected create error")
+
+	for index := 0; index < 8; index++ {
+		level := LevelInfo
+		if index % 4 == 2 {
+			level = LevelDebug
+		}
+		_ = logger.Print(level, StringMessage("Hello Test!"))
+	}
+
+	// Half of the log entries are sampled out, and half of the remaining
+	// log entries are rejected by the hook.
+	assert.Len(t, exporter.entries, 2, "Unexpected log entries")
+	for index := 0; index < len(exporter.entries); index++ {
+		assert.Equal(t, uint64(index + 1), exporter.entries[index].Sequence,
+			"Unexpected log entry sequence")
+	}
+}
+
+type testErrorExporter struct {
+	testExporter
+	err error
+}
+
+func (e *testErrorExporter) Export(entry *Entry) error {
+	return e.err
+}
+
+func TestLoggerErrorHandler(t *testing.T) {
+	failing := &testErrorExporter { err: errors.New("Error") }
+	exporter := &testRecordExporter { }
+
+	var handled []error
+	option := NewOption()
+	option.Exporters = []Exporter { failing, exporter }
+	option.ErrorHandler = func(err error) {
+		handled = append(handled, err)
+	}
+
+	logger, err := option.Build()
+	assert.NoError(t, err, "Unexpected create error")
+
+	err = logger.Print(LevelInfo, StringMessage("Hello Test!"))
+	assert.Equal(t, failing.err, err, "Unexpected print error")
+	assert.Empty(t, exporter.entries, "Unexpected log entries")
+	assert.Len(t, handled, 1, "Unexpected handled errors")
+
+	var exporterError *ExporterError
+	assert.True(t, errors.As(handled[0], &exporterError),
+		"Unexpected handled error")
+	assert.Equal(t, failing, exporterError.Exporter,
+		"Unexpected handled error")
+	assert.True(t, errors.Is(handled[0], failing.err),
+		"Unexpected handled error")
+
+	handled = nil
+	option.BestEffort = true
+
+	logger, err = option.Build()
+	assert.NoError(t, err, "Unexpected create error")
+
+	err = logger.Print(LevelInfo, StringMessage("Hello Test!"))
+	assert.NoError(t, err, "Unexpected print error")
+	assert.Len(t, exporter.entries, 1, "Unexpected log entries")
+	assert.Len(t, handled, 1, "Unexpected handled errors")
+}
+
+func TestLoggerProfiling(t *testing.T) {
+	exporter := &testRecordExporter { }
+
+	option := NewOption()
+	option.Name = "test"
+	option.Exporters = append(option.Exporters, exporter)
+	option.Profiling = true
+
+	logger, err := option.Build()
+	assert.NoError(t, err, "Unexpected create error")
+	assert.True(t, logger.profile, "Unexpected instance error")
+
+	var buffer bytes.Buffer
+	if !assert.NoError(t, trace.Start(&buffer), "Unexpected trace error") {
+		return
+	}
+	assert.NoError(t, logger.Print(LevelInfo, StringMessage(
+		"Hello Info!")), "Unexpected print error")
+	assert.NoError(t, logger.Print(LevelFatal, StringMessage(
+		"Hello Fatal!")), "Unexpected print error")
+	trace.Stop()
+
+	assert.Contains(t, buffer.String(), "Hello Fatal!",
+		"Unexpected trace event")
+	assert.NotContains(t, buffer.String(), "Hello Info!",
+		"Unexpected trace event")
+
+	assert.Len(t, exporter.entries, 2, "Unexpected log entries")
+	for index := 0; index < len(exporter.entries); index++ {
+		assert.Contains(t, runtime.FuncForPC(exporter.entries[index].
+			SourceLocation.Proc).Name(), "TestLoggerProfiling",
+			"Unexpected source location")
+	}
+
+	standard, err := NewStandardOption().UseProfiling().Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.True(t, standard.profile, "Unexpected instance error")
+	assert.True(t, standard.Duplicate().profile, "Unexpected instance error")
+	assert.NoError(t, standard.Close(), "Unexpected close error")
+}
+
+func TestMustBuild(t *testing.T) {
+	assert.NotNil(t, MustNew(), "Unexpected logger instance")
+	logger := MustNewStandard()
+	assert.NotNil(t, logger, "Unexpected logger instance")
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+
+	option := NewStandardOption()
+	option.Outputting.Type = "invalid"
+	defer func() {
+		err, ok := recover().(error)
+		assert.True(t, ok, "Unexpected panic value")
+		assert.True(t, errors.Is(err, ErrInvalidType),
+			"Unexpected panic error")
+		assert.Equal(t, "santa: failed to build standard logger: " +
+			"invalid type", err.Error(), "Unexpected panic error")
+	}()
+	option.MustBuild()
+}
+
+func TestStandardOptionValidate(t *testing.T) {
+	assert.NoError(t, NewStandardOption().Validate(),
+		"Unexpected validate error")
+
+	option := NewStandardOption().UseLevel(Level(10))
+	_, err := option.Build()
+	assert.True(t, errors.Is(err, ErrInvalidLevel), "Unexpected build error")
+
+	option = NewStandardOption().UseSampling(NewSamplingOption().
+		UseTextOption(NewTextSamplerOption().UseSpan(LevelError,
+			LevelInfo)))
+	assert.True(t, errors.Is(option.Validate(), ErrInvalidSpan),
+		"Unexpected validate error")
+
+	option = NewStandardOption()
+	option.ErrorOutputting.Option.(*StandardSyncerOption).
+		UseCacheCapacity(-1)
+	assert.True(t, errors.Is(option.Validate(), ErrInvalidCacheCapacity),
+		"Unexpected validate error")
+
+	exporterOption := NewStandardExporterOption().UseSpan(LevelFatal,
+		LevelDebug)
+	_, err = exporterOption.Build()
+	assert.True(t, errors.Is(err, ErrInvalidSpan), "Unexpected build error")
+}
+
+func TestStandardOptionClone(t *testing.T) {
+	option := NewStandardOption().UseLabels(NewLabel("key", "value")).
+		UseHooks(NewSimpleHook(func(entry *Entry) error {
+			return nil
+		}))
+	clone := option.Clone()
+
+	clone.UseName("clone").UseLabels(NewLabel("other", "value"))
+	clone.Hooks[0] = nil
+	clone.Labels[0].Key = "changed"
+	clone.Sampling.Option.(*TextSamplerOption).UseTick(time.Minute)
+	clone.Encoding.Option.(*StandardEncoderOption).UseTimeLayout("")
+	clone.Outputting.Option.(*StandardSyncerOption).UseCacheCapacity(1)
+	clone.ErrorOutputting.UseDiscard()
+
+	assert.Equal(t, "", option.Name, "Unexpected option name")
+	assert.Equal(t, 1, len(option.Labels), "Unexpected option labels")
+	assert.Equal(t, "key", option.Labels[0].Key, "Unexpected option labels")
+	assert.NotNil(t, option.Hooks[0], "Unexpected option hooks")
+	assert.Equal(t, time.Second, option.Sampling.Option.(*TextSamplerOption).
+		Tick, "Unexpected sampling option")
+	assert.Equal(t, time.RFC3339Nano, option.Encoding.Option.(
+		*StandardEncoderOption).TimeLayout, "Unexpected encoding option")
+	assert.NotEqual(t, 1, option.Outputting.Option.(*StandardSyncerOption).
+		CacheCapacity, "Unexpected outputting option")
+	assert.Equal(t, SyncerStandard, option.ErrorOutputting.Type,
+		"Unexpected error outputting option")
+
+	structOption := NewStructOption()
+	structClone := structOption.Clone()
+	structClone.Encoding.Option.(*JSONEncoderOption).UseTimeLayout("clone")
+	assert.NotEqual(t, "clone", structOption.Encoding.Option.(
+		*JSONEncoderOption).TimeLayout, "Unexpected encoding option")
+}
+
+type testCloseExporter struct {
+	testRecordExporter
+	err error
+	closed int
+}
+
+func (e *testCloseExporter) Close() error {
+	e.closed++
+	return e.err
+}
+
+func TestStandardLoggerCloseErrors(t *testing.T) {
+	first := &testCloseExporter { err: errors.New("first") }
+	second := &testCloseExporter { }
+	third := &testCloseExporter { err: errors.New("third") }
+
+	logger, err := NewStandardOption().UseExporters(first, second, third).
+		Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	err = logger.Close()
+	assert.True(t, errors.Is(err, first.err), "Unexpected close error")
+	assert.True(t, errors.Is(err, third.err), "Unexpected close error")
+	assert.Equal(t, 1, first.closed, "Unexpected close count")
+	assert.Equal(t, 1, second.closed, "Unexpected close count")
+	assert.Equal(t, 1, third.closed, "Unexpected close count")
+
+	assert.Equal(t, ErrClosed, logger.Close(), "Unexpected close error")
+	assert.Equal(t, 1, first.closed, "Unexpected close count")
+}
+
+func TestStandardLoggerShutdown(t *testing.T) {
+	exporter := &testRecordExporter { }
+	async, err := NewAsyncExporterOption().UseExporter(exporter).
+		UseCapacity(16).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	logger, err := NewStandardOption().UseExporters(async).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.NoError(t, logger.Info(StringMessage("Hello Test!")),
+		"Unexpected print error")
+
+	abandoned, err := logger.Shutdown(context.Background())
+	assert.NoError(t, err, "Unexpected shutdown error")
+	assert.Equal(t, uint64(0), abandoned, "Unexpected abandoned count")
+	assert.Len(t, exporter.entries, 1, "Unexpected exported entries")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	blocked := make(chan struct { })
+	defer close(blocked)
+	logger, err = NewStandardOption().UseExporters(&testBlockCloseExporter {
+		blocked: blocked,
+	}).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	_, err = logger.Shutdown(ctx)
+	assert.True(t, errors.Is(err, context.Canceled),
+		"Unexpected shutdown error")
+}
+
+type testBlockCloseExporter struct {
+	testRecordExporter
+	blocked chan struct { }
+}
+
+func (e *testBlockCloseExporter) Close() error {
+	<-e.blocked
+	return nil
+}
+
+func TestLoggerSourceLocationSpan(t *testing.T) {
+	exporter := &testRecordExporter { }
+
+	option := NewStandardOption().UseExporters(exporter)
+	option.Encoding.UseSourceLocationSpan(LevelError, LevelFatal)
+	option.Outputting.UseDiscard()
+	option.ErrorOutputting.UseDiscard()
+
+	logger, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	assert.NoError(t, logger.InfoString("Hello Test!"),
+		"Unexpected print error")
+	assert.NoError(t, logger.ErrorString("Hello Test!"),
+		"Unexpected print error")
+
+	assert.Len(t, exporter.entries, 2, "Unexpected log entries")
+	assert.False(t, exporter.entries[0].SourceLocation.Parsed,
+		"Unexpected source location")
+	assert.True(t, exporter.entries[1].SourceLocation.Parsed,
+		"Unexpected source location")
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+}
+
+func TestLoggerDPanic(t *testing.T) {
+	for _, development := range []bool { false, true } {
+		exporter := &testRecordExporter { }
+
+		standardOption := NewStandardOption().UseExporters(exporter)
+		templateOption := NewTemplateOption().UseExporters(exporter)
+		structOption := NewStructOption().UseExporters(exporter)
+		for _, option := range []*StandardOption { standardOption,
+			&templateOption.StandardOption, &structOption.StandardOption } {
+			option.Development = development
+			option.Outputting.UseDiscard()
+			option.ErrorOutputting.UseDiscard()
+		}
+		standard, err := standardOption.Build()
+		assert.NoError(t, err, "Unexpected build error")
+		template, err := templateOption.Build()
+		assert.NoError(t, err, "Unexpected build error")
+		structured, err := structOption.Build()
+		assert.NoError(t, err, "Unexpected build error")
+
+		outputs := []func() error {
+			func() error {
+				return standard.DPanic(StringMessage("Hello Test!"))
+			},
+			func() error {
+				return standard.DPanicString("Hello Test!")
+			},
+			func() error {
+				return standard.Decorator().DPanicString("Hello Test!")
+			},
+			func() error {
+				return template.DPanicf("Hello %s!", "Test")
+			},
+			func() error {
+				return template.Decorator().DPanicf("Hello %s!", "Test")
+			},
+			func() error {
+				return structured.DPanics("Hello Test!")
+			},
+			func() error {
+				return structured.DPanicw("Hello Test!", "age", 100)
+			},
+			func() error {
+				return structured.Decorator().DPanics("Hello Test!")
+			},
+			func() error {
+				return structured.Decorator().DPanicw("Hello Test!")
+			},
+		}
+		for _, output := range outputs {
+			if !development {
+				assert.NoError(t, output(), "Unexpected print error")
+				continue
+			}
+			func() {
+				defer func() {
+					err, ok := recover().(error)
+					assert.True(t, ok, "Unexpected panic value")
+					assert.True(t, errors.Is(err, ErrDevelopmentPanic),
+						"Unexpected panic value")
+					assert.Contains(t, err.Error(), "Hello Test!",
+						"Unexpected panic value")
+				}()
+				_ = output()
+			}()
+		}
+
+		assert.Len(t, exporter.entries, len(outputs), "Unexpected log entries")
+		for _, entry := range exporter.entries {
+			assert.Equal(t, LevelError, entry.Level,
+				"Unexpected log entry level")
+			assert.Contains(t, entry.SourceLocation.File, "logger_test.go",
+				"Unexpected source location")
+		}
+		assert.NoError(t, standard.Close(), "Unexpected close error")
+		assert.NoError(t, template.Close(), "Unexpected close error")
+		assert.NoError(t, structured.Close(), "Unexpected close error")
+	}
+
+	assert.True(t, NewStandardOption().UseDevelopment().Development,
+		"Unexpected option value")
+	assert.True(t, NewTemplateOption().UseDevelopment().Development,
+		"Unexpected option value")
+	assert.True(t, NewStructOption().UseDevelopment().Development,
+		"Unexpected option value")
 }
@@ -29,6 +29,30 @@ import (
 // Message is the public interface for messages.
 type Message interface { }
 
+// MessageCloner is the public interface of the message cloner.
+//
+// Messages that reference mutable or pooled data should implement this
+// interface, so that a snapshot of the message can be retained after the
+// log entry has been exported. For details, please refer to the comment
+// section of the Clone function of the Entry structure.
+type MessageCloner interface {
+	// CloneMessage creates and returns a snapshot of the message that
+	// does not share any mutable data with the message.
+	CloneMessage() Message
+}
+
+// CloneMessage creates and returns a snapshot of the given message. If the
+// message implements the MessageCloner interface, the snapshot is created
+// by the message itself, otherwise the message is returned as is, because
+// the message is considered to be immutable.
+func CloneMessage(message Message) Message {
+	cloner, ok := message.(MessageCloner)
+	if !ok {
+		return message
+	}
+	return cloner.CloneMessage()
+}
+
 // StringMessage is the data type of the string log entry message.
 type StringMessage string
 
@@ -49,6 +73,20 @@ func (m StringMessage) SerializeJSON(buffer []byte) []byte {
 	return append(buffer, '"')
 }
 
+// FormatStandard is an alias of the SerializeStandard function.
+//
+// Deprecated: Use SerializeStandard instead.
+func (m StringMessage) FormatStandard(buffer []byte) []byte {
+	return m.SerializeStandard(buffer)
+}
+
+// FormatJSON is an alias of the SerializeJSON function.
+//
+// Deprecated: Use SerializeJSON instead.
+func (m StringMessage) FormatJSON(buffer []byte) []byte {
+	return m.SerializeJSON(buffer)
+}
+
 // SampleText returns the text sample string of the log entry message.
 func (m StringMessage) SampleText() string {
 	return string(m)
@@ -66,6 +104,48 @@ type TemplateMessage struct {
 	// message. The number and position of the parameters correspond
 	// to the template string.
 	Args []interface { }
+
+	// format represents the memoized formatted text of the message. It is
+	// only set for messages obtained from the template message pool.
+	format *templateFormat
+}
+
+// templateFormat is a structure that memoizes the formatted text of a
+// template message, so that a message encoded by multiple exporters is
+// formatted only once.
+type templateFormat struct {
+	text []byte
+	formatted bool
+}
+
+// Write appends the given data to the formatted text, which allows the
+// template to be formatted without allocating a string.
+func (f *templateFormat) Write(data []byte) (int, error) {
+	f.text = append(f.text, data...)
+	return len(data), nil
+}
+
+// appendText formats the template and appends the formatted text to the
+// given buffer slice, and then returns the appended buffer slice. If the
+// message is memoized, the template is formatted only on the first call.
+func (m TemplateMessage) appendText(buffer []byte) []byte {
+	if m.format == nil {
+		return append(buffer, fmt.Sprintf(m.Template, m.Args...)...)
+	}
+	if !m.format.formatted {
+		m.format.text = m.format.text[ : 0]
+		_, _ = fmt.Fprintf(m.format, m.Template, m.Args...)
+		m.format.formatted = true
+	}
+	return append(buffer, m.format.text...)
+}
+
+// text returns the formatted text of the message.
+func (m TemplateMessage) text() string {
+	if m.format == nil {
+		return fmt.Sprintf(m.Template, m.Args...)
+	}
+	return string(m.appendText(nil))
 }
 
 // SerializeStandard serializes the message into a standard log string and
@@ -73,7 +153,7 @@ type TemplateMessage struct {
 // slice.
 func (m TemplateMessage) SerializeStandard(buffer []byte) []byte {
 	buffer = append(buffer, '"')
-	buffer = append(buffer, fmt.Sprintf(m.Template, m.Args...)...)
+	buffer = m.appendText(buffer)
 	return append(buffer, '"')
 }
 
@@ -81,15 +161,43 @@ func (m TemplateMessage) SerializeStandard(buffer []byte) []byte {
 // to the given buffer slice, and then returns the appended buffer slice.
 func (m TemplateMessage) SerializeJSON(buffer []byte) []byte {
 	buffer = append(buffer, '"')
-	buffer = append(buffer, fmt.Sprintf(m.Template, m.Args...)...)
+	buffer = m.appendText(buffer)
 	return append(buffer, '"')
 }
 
-// SampleText returns the text sample string of the log entry message.
+// FormatStandard is an alias of the SerializeStandard function.
+//
+// Deprecated: Use SerializeStandard instead.
+func (m TemplateMessage) FormatStandard(buffer []byte) []byte {
+	return m.SerializeStandard(buffer)
+}
+
+// FormatJSON is an alias of the SerializeJSON function.
+//
+// Deprecated: Use SerializeJSON instead.
+func (m TemplateMessage) FormatJSON(buffer []byte) []byte {
+	return m.SerializeJSON(buffer)
+}
+
+// SampleText returns the text sample string of the log entry message. The
+// template is not formatted, so that messages discarded by samplers are
+// never formatted.
 func (m TemplateMessage) SampleText() string {
 	return m.Template
 }
 
+// CloneMessage creates and returns a snapshot of the message. The values
+// of the formatting parameters are copied, but the data they reference is
+// not.
+func (m TemplateMessage) CloneMessage() Message {
+	args := make([]interface { }, len(m.Args))
+	copy(args, m.Args)
+	return TemplateMessage {
+		Template: m.Template,
+		Args: args,
+	}
+}
+
 // StructMessage is a log entry message structure containing
 // multiple fields.
 type StructMessage struct {
@@ -110,23 +218,89 @@ func (m StructMessage) SerializeStandard(buffer []byte) []byte {
 	buffer = append(buffer, '"')
 	buffer = append(buffer, m.Text...)
 	buffer = append(buffer, `" `...)
-	return m.Fields.SerializeJSON(buffer)
+	return m.Fields.SerializeStandard(buffer)
+}
+
+// SerializeStandardOption serializes the message into a standard log
+// string using the given serializer options, and appends it to the given
+// buffer slice, and then returns the appended buffer slice.
+func (m StructMessage) SerializeStandardOption(buffer []byte, option *SerializerOption) []byte {
+	buffer = append(buffer, '"')
+	buffer = append(buffer, m.Text...)
+	buffer = append(buffer, `" `...)
+	return m.Fields.SerializeStandardOption(buffer, option)
 }
 
 // SerializeJSON serializes the message into a JSON string and appends it
 // to the given buffer slice, and then returns the appended buffer slice.
 func (m StructMessage) SerializeJSON(buffer []byte) []byte {
-	buffer = append(buffer, `{"text": "`...)
+	return m.SerializeJSONKeys(buffer, &defaultEncoderKeys)
+}
+
+// SerializeJSONKeys serializes the message into a JSON string using the
+// TextPayloadKey and JSONPayloadKey key names of the given encoder keys,
+// and appends it to the given buffer slice, and then returns the appended
+// buffer slice.
+func (m StructMessage) SerializeJSONKeys(buffer []byte, keys *EncoderKeys) []byte {
+	return m.serializeJSON(buffer, keys, nil)
+}
+
+// SerializeJSONOption serializes the message into a JSON string using the
+// key names and the field options of the given serializer options, and
+// appends it to the given buffer slice, and then returns the appended
+// buffer slice.
+func (m StructMessage) SerializeJSONOption(buffer []byte, option *SerializerOption) []byte {
+	return m.serializeJSON(buffer, &option.EncoderKeys, option)
+}
+
+// serializeJSON serializes the message into a JSON string using the given
+// key names. If the given serializer options are not nil, the fields are
+// serialized using them.
+func (m StructMessage) serializeJSON(buffer []byte, keys *EncoderKeys,
+	option *SerializerOption) []byte {
+	buffer = append(buffer, `{"`...)
+	buffer = append(buffer, keys.TextPayloadKey...)
+	buffer = append(buffer, `": "`...)
 	buffer = append(buffer, m.Text...)
 	if len(m.Fields) == 0 {
 		return append(buffer, `"}`...)
 	}
-	buffer = append(buffer, `", "payload": `...)
-	buffer = m.Fields.SerializeJSON(buffer)
+	buffer = append(buffer, `", "`...)
+	buffer = append(buffer, keys.JSONPayloadKey...)
+	buffer = append(buffer, `": `...)
+	if option == nil {
+		buffer = m.Fields.SerializeJSON(buffer)
+	} else {
+		buffer = m.Fields.SerializeJSONOption(buffer, option)
+	}
 	return append(buffer, '}')
 }
 
+// FormatStandard is an alias of the SerializeStandard function.
+//
+// Deprecated: Use SerializeStandard instead.
+func (m StructMessage) FormatStandard(buffer []byte) []byte {
+	return m.SerializeStandard(buffer)
+}
+
+// FormatJSON is an alias of the SerializeJSON function.
+//
+// Deprecated: Use SerializeJSON instead.
+func (m StructMessage) FormatJSON(buffer []byte) []byte {
+	return m.SerializeJSON(buffer)
+}
+
 // SampleText returns the text sample string of the log entry message.
 func (m StructMessage) SampleText() string {
 	return m.Text
 }
+
+// CloneMessage creates and returns a snapshot of the message. For details,
+// please refer to the comment section of the Clone function of the
+// ElementObject data type.
+func (m StructMessage) CloneMessage() Message {
+	return StructMessage {
+		Text: m.Text,
+		Fields: m.Fields.Clone(),
+	}
+}
@@ -70,6 +70,40 @@ func TestTemplateMessage(t *testing.T) {
 		"Unexpected sample result")
 }
 
+type testCountingStringer struct {
+	count int
+}
+
+func (s *testCountingStringer) String() string {
+	s.count++
+	return "Test"
+}
+
+func TestTemplateMessageMemoization(t *testing.T) {
+	stringer := &testCountingStringer { }
+	pool := NewTemplateMessagePool()
+	message := pool.New("Hello %s!", []interface { } { stringer })
+
+	buffer := message.SerializeStandard(nil)
+	assert.Equal(t, `"Hello Test!"`, string(buffer), "Unexpected format result")
+	buffer = message.SerializeJSON(buffer[ : 0])
+	assert.Equal(t, `"Hello Test!"`, string(buffer), "Unexpected format result")
+	assert.Equal(t, "Hello Test!", messageText(message), "Unexpected text result")
+	assert.Equal(t, 1, stringer.count, "Unexpected format count")
+
+	clone := CloneMessage(message)
+	pool.Free(message)
+	message = pool.New("Hello %s?", []interface { } { stringer })
+	buffer = message.SerializeJSON(buffer[ : 0])
+	assert.Equal(t, `"Hello Test?"`, string(buffer), "Unexpected format result")
+	assert.Equal(t, 2, stringer.count, "Unexpected format count")
+
+	buffer = clone.(TemplateMessage).SerializeJSON(buffer[ : 0])
+	assert.Equal(t, `"Hello Test!"`, string(buffer), "Unexpected format result")
+	assert.Equal(t, 3, stringer.count, "Unexpected format count")
+	pool.Free(message)
+}
+
 func TestStructMessage(t *testing.T) {
 	buffer := make([]byte, 0, 256)
 
@@ -99,3 +133,43 @@ func TestStructMessage(t *testing.T) {
 	assert.Equal(t, "Hello Test!", message.SampleText(),
 		"Unexpected sample result")
 }
+
+func TestCloneMessage(t *testing.T) {
+	args := []interface { } { "Test" }
+
+	message := CloneMessage(&TemplateMessage {
+		Template: "Hello %s!",
+		Args: args,
+	})
+
+	args[0] = "Santa"
+
+	assert.Equal(t, `"Hello Test!"`, string(message.(TemplateMessage).
+		SerializeJSON(nil)), "Unexpected clone result")
+
+	assert.Equal(t, nil, CloneMessage(nil), "Unexpected clone result")
+}
+
+func TestMessageFormatAliases(t *testing.T) {
+	for _, message := range []interface {
+		StandardSerializer
+		JSONSerializer
+		FormatStandard(buffer []byte) []byte
+		FormatJSON(buffer []byte) []byte
+	} {
+		StringMessage("Hello Test!"),
+		TemplateMessage {
+			Template: "Hello %s!",
+			Args: []interface { } { "Test" },
+		},
+		StructMessage {
+			Text: "Hello Test!",
+			Fields: ElementObject { String("name", "test") },
+		},
+	} {
+		assert.Equal(t, string(message.SerializeStandard(nil)), string(
+			message.FormatStandard(nil)), "Unexpected format result")
+		assert.Equal(t, string(message.SerializeJSON(nil)), string(
+			message.FormatJSON(nil)), "Unexpected format result")
+	}
+}
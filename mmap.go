@@ -0,0 +1,383 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrMmapUnsupported represents that memory-mapped files are not
+	// supported on the current platform.
+	ErrMmapUnsupported = errors.New("memory-mapped file unsupported")
+
+	// ErrInvalidChunkSize represents that the given chunk size of the
+	// memory-mapped file synchronizer is invalid. The chunk size must be
+	// greater than 0.
+	ErrInvalidChunkSize = errors.New("invalid chunk size")
+
+	// ErrInvalidFileName represents that the given file name is invalid,
+	// usually because the file name is not provided.
+	ErrInvalidFileName = errors.New("invalid file name")
+)
+
+// MmapSyncer is the structure of an instance of a memory-mapped file
+// synchronizer.
+//
+// The memory-mapped file synchronizer preallocates the file in chunks and
+// maps it into memory, so that each write is only a copy into the mapped
+// region without any system call, which provides extremely low latency for
+// local logging. When the mapped region is exhausted, the file is grown by
+// at least one chunk and mapped again. The data is written to the file by
+// the operating system, and the Sync function (which is also called
+// periodically) synchronizes the written data to the persistent storage
+// device with the msync system call.
+//
+// The unused part of the preallocated file is filled with zero bytes. When
+// the synchronizer is closed, the file is truncated to the length of the
+// written data. If the process crashes, the file keeps the preallocated
+// length, and the length of the written data is recovered when the file is
+// opened again by ignoring the trailing zero bytes, so log entry data must
+// not end with zero bytes.
+//
+// Memory-mapped files are supported on Linux, macOS, FreeBSD, OpenBSD and
+// DragonFly BSD. On the other platforms, building the synchronizer returns
+// ErrMmapUnsupported.
+//
+// The API provided by the memory-mapped file synchronizer is thread-safe.
+type MmapSyncer struct {
+	file *os.File
+	chunk int
+
+	mutex sync.Mutex
+	region []byte
+	length int
+	synced int
+	closed int32
+
+	contextCancel context.CancelFunc
+	contextWaitGroup *sync.WaitGroup
+}
+
+// Write copies the data of the given buffer slice into the mapped region,
+// growing the file if the mapped region is exhausted.
+//
+// Finally, it returns the number of bytes actually written and any errors
+// encountered. If the synchronizer has been closed, ErrClosed is returned.
+func (s *MmapSyncer) Write(buffer []byte) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if atomic.LoadInt32(&s.closed) == 1 {
+		return 0, ErrClosed
+	}
+	if s.length + len(buffer) > len(s.region) {
+		if err := s.grow(s.length + len(buffer)); err != nil {
+			return 0, err
+		}
+	}
+	copy(s.region[s.length : ], buffer)
+	s.length += len(buffer)
+	return len(buffer), nil
+}
+
+// grow grows the file to at least the given size in chunks and maps it
+// again. If the file cannot be grown, the current mapped region is kept.
+//
+// Please note that the caller must hold the mutex.
+func (s *MmapSyncer) grow(size int) error {
+	capacity := alignSize(size, s.chunk)
+	previous := len(s.region)
+	if err := unmapFile(s.region); err != nil {
+		return err
+	}
+	s.region = nil
+	err := s.file.Truncate(int64(capacity))
+	if err != nil {
+		capacity = previous
+	}
+	region, mapErr := mapFile(s.file, capacity)
+	if mapErr != nil {
+		return mapErr
+	}
+	s.region = region
+	return err
+}
+
+// Sync synchronizes the data written since the last synchronization to the
+// persistent storage device.
+//
+// Finally, any errors encountered are returned. If the synchronizer has
+// been closed, ErrClosed is returned.
+func (s *MmapSyncer) Sync() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if atomic.LoadInt32(&s.closed) == 1 {
+		return ErrClosed
+	}
+	return s.sync()
+}
+
+// sync is the implementation of the Sync function.
+//
+// Please note that the caller must hold the mutex.
+func (s *MmapSyncer) sync() error {
+	if s.synced == s.length || s.region == nil {
+		return nil
+	}
+	// The start address of the synchronized range must be aligned to the
+	// page size.
+	start := s.synced - s.synced % os.Getpagesize()
+	if err := syncRegion(s.region[start : s.length]); err != nil {
+		return err
+	}
+	s.synced = s.length
+	return nil
+}
+
+// syncHandler calls the Sync function at the given interval until the
+// given context has been marked as complete.
+//
+// This function should run in an independent coroutine context.
+func (s *MmapSyncer) syncHandler(ctx context.Context, interval time.Duration) {
+	defer s.contextWaitGroup.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Sync(); err != nil {
+				diagnose("synchronizing mapped file failed: %v", err)
+			}
+		}
+	}
+}
+
+// Close synchronizes the written data, unmaps the file, truncates it to
+// the length of the written data and closes it.
+//
+// Finally, any errors encountered are returned. If the synchronizer has
+// already been closed, ErrClosed is returned.
+func (s *MmapSyncer) Close() error {
+	if !atomic.CompareAndSwapInt32(&s.closed, 0, 1) {
+		return ErrClosed
+	}
+	s.contextCancel()
+	s.contextWaitGroup.Wait()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	err := s.sync()
+	if s.region != nil {
+		err = appendError(err, unmapFile(s.region))
+		s.region = nil
+	}
+	err = appendError(err, s.file.Truncate(int64(s.length)))
+	return appendError(err, s.file.Close())
+}
+
+// alignSize returns the smallest multiple of the given alignment that is
+// not less than the given size.
+func alignSize(size, alignment int) int {
+	return (size + alignment - 1) / alignment * alignment
+}
+
+// MmapSyncerOption is a structure containing memory-mapped file
+// synchronizer options.
+type MmapSyncerOption struct {
+	// FileName represents the path of the file. If the file exists, the
+	// data is appended to the file. This option must be provided.
+	FileName string
+
+	// FileMode represents the permission bits used when the file is
+	// created. If not provided, the default value is 0644.
+	FileMode os.FileMode
+
+	// MakeDirectories represents whether to create the missing parent
+	// directories of the file with the permission bits 0755. If not
+	// provided, the default value is false.
+	MakeDirectories bool
+
+	// ChunkSize represents the number of bytes by which the file is grown
+	// when the mapped region is exhausted, which is rounded up to a
+	// multiple of the page size. Larger chunks grow the file less often.
+	// If not provided, the default value is 4 MiB.
+	ChunkSize int
+
+	// SyncInterval represents the interval at which the written data is
+	// synchronized to the persistent storage device. If the value of this
+	// option is 0, the data is only synchronized when the Sync function is
+	// called or the synchronizer is closed. If not provided, the default
+	// value is 1 second.
+	SyncInterval time.Duration
+}
+
+// UseName uses the given name as the value of the option FileName. For
+// details, please refer to the comment section of the FileName option.
+// Then return to the option instance itself.
+func (o *MmapSyncerOption) UseName(name string) *MmapSyncerOption {
+	o.FileName = name
+	return o
+}
+
+// UseFileMode uses the given permission bits as the value of the option
+// FileMode. For details, please refer to the comment section of the
+// FileMode option. Then return to the option instance itself.
+func (o *MmapSyncerOption) UseFileMode(mode os.FileMode) *MmapSyncerOption {
+	o.FileMode = mode
+	return o
+}
+
+// UseMakeDirectories enables the option MakeDirectories. For details,
+// please refer to the comment section of the MakeDirectories option. Then
+// return to the option instance itself.
+func (o *MmapSyncerOption) UseMakeDirectories() *MmapSyncerOption {
+	o.MakeDirectories = true
+	return o
+}
+
+// UseChunkSize uses the given size as the value of the option ChunkSize.
+// For details, please refer to the comment section of the ChunkSize
+// option. Then return to the option instance itself.
+func (o *MmapSyncerOption) UseChunkSize(size int) *MmapSyncerOption {
+	o.ChunkSize = size
+	return o
+}
+
+// UseSyncInterval uses the given interval as the value of the option
+// SyncInterval. For details, please refer to the comment section of the
+// SyncInterval option. Then return to the option instance itself.
+func (o *MmapSyncerOption) UseSyncInterval(interval time.Duration) *MmapSyncerOption {
+	o.SyncInterval = interval
+	return o
+}
+
+// Validate checks whether the values of the options are valid, and returns
+// an error describing the first invalid value.
+func (o *MmapSyncerOption) Validate() error {
+	if len(o.FileName) == 0 {
+		return ErrInvalidFileName
+	}
+	if o.ChunkSize <= 0 {
+		return ErrInvalidChunkSize
+	}
+	return nil
+}
+
+// Build builds and returns a memory-mapped file synchronizer instance and
+// any errors encountered.
+func (o *MmapSyncerOption) Build() (*MmapSyncer, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	if !mmapSupported {
+		return nil, ErrMmapUnsupported
+	}
+	mode := o.FileMode
+	if mode == 0 {
+		mode = 0644
+	}
+	if o.MakeDirectories {
+		if err := os.MkdirAll(filepath.Dir(o.FileName), 0755); err != nil {
+			return nil, err
+		}
+	}
+	file, err := os.OpenFile(o.FileName, os.O_RDWR | os.O_CREATE, mode)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := newMmapSyncer(file, alignSize(o.ChunkSize,
+		os.Getpagesize()))
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	var ctx context.Context
+	ctx, instance.contextCancel = context.WithCancel(context.Background())
+	instance.contextWaitGroup = &sync.WaitGroup { }
+	if o.SyncInterval > 0 {
+		instance.contextWaitGroup.Add(1)
+		go instance.syncHandler(ctx, o.SyncInterval)
+	}
+	return instance, nil
+}
+
+// newMmapSyncer creates and returns a memory-mapped file synchronizer
+// instance of the given file, recovering the length of the written data
+// of the file, and then returns any errors encountered.
+func newMmapSyncer(file *os.File, chunk int) (*MmapSyncer, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := int(info.Size())
+	instance := &MmapSyncer {
+		file: file,
+		chunk: chunk,
+	}
+	if size > 0 {
+		region, err := mapFile(file, size)
+		if err != nil {
+			return nil, err
+		}
+		// The trailing zero bytes are the unused part of the file that
+		// was preallocated before the process crashed.
+		length := size
+		for length > 0 && region[length - 1] == 0 {
+			length--
+		}
+		instance.length = length
+		instance.synced = length
+		if err := unmapFile(region); err != nil {
+			return nil, err
+		}
+	}
+	capacity := alignSize(instance.length + 1, chunk)
+	if capacity < size {
+		capacity = alignSize(size, chunk)
+	}
+	if err := file.Truncate(int64(capacity)); err != nil {
+		return nil, err
+	}
+	if instance.region, err = mapFile(file, capacity); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// NewMmapSyncerOption creates and returns an instance of a memory-mapped
+// file synchronizer option with default optional values. The file name
+// must be provided before building.
+func NewMmapSyncerOption() *MmapSyncerOption {
+	return &MmapSyncerOption {
+		FileMode: 0644,
+		ChunkSize: 4 << 20,
+		SyncInterval: time.Second,
+	}
+}
@@ -0,0 +1,109 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newMmapTestSyncer(t *testing.T, name string) *MmapSyncer {
+	syncer, err := NewMmapSyncerOption().UseName(name).
+		UseChunkSize(os.Getpagesize()).UseSyncInterval(0).Build()
+	if err == ErrMmapUnsupported {
+		t.Skip("Memory-mapped files are unsupported")
+	}
+	assert.NoError(t, err, "Unexpected build error")
+	return syncer
+}
+
+func TestMmapSyncerOption(t *testing.T) {
+	_, err := NewMmapSyncerOption().Build()
+	assert.Equal(t, ErrInvalidFileName, err, "Unexpected build error")
+
+	_, err = NewMmapSyncerOption().UseName("santa.log").UseChunkSize(0).
+		Build()
+	assert.Equal(t, ErrInvalidChunkSize, err, "Unexpected build error")
+}
+
+func TestMmapSyncerWrite(t *testing.T) {
+	directory, err := ioutil.TempDir("", "santa")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	name := directory + "/santa.log"
+	syncer := newMmapTestSyncer(t, name)
+
+	// The data exceeds the chunk size, so the file is grown.
+	data := bytes.Repeat([]byte("Hello Test!\n"), os.Getpagesize() / 4)
+	size, err := syncer.Write(data)
+	assert.NoError(t, err, "Unexpected write error")
+	assert.Equal(t, len(data), size, "Unexpected write size")
+	assert.NoError(t, syncer.Sync(), "Unexpected sync error")
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+
+	_, err = syncer.Write(data)
+	assert.Equal(t, ErrClosed, err, "Unexpected write error")
+	assert.Equal(t, ErrClosed, syncer.Close(), "Unexpected close error")
+
+	content, err := ioutil.ReadFile(name)
+	assert.NoError(t, err, "Unexpected read error")
+	assert.Equal(t, data, content, "Unexpected file content")
+
+	// The data is appended to the existing file.
+	syncer = newMmapTestSyncer(t, name)
+	_, err = syncer.Write([]byte("Bye!\n"))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+
+	content, err = ioutil.ReadFile(name)
+	assert.NoError(t, err, "Unexpected read error")
+	assert.Equal(t, string(data) + "Bye!\n", string(content),
+		"Unexpected file content")
+}
+
+func TestMmapSyncerRecovery(t *testing.T) {
+	directory, err := ioutil.TempDir("", "santa")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	// The file of a crashed process keeps the preallocated zero bytes.
+	name := directory + "/santa.log"
+	crashed := append([]byte("Hello Test!\n"), make([]byte, 100)...)
+	assert.NoError(t, ioutil.WriteFile(name, crashed, 0644),
+		"Unexpected write error")
+
+	syncer := newMmapTestSyncer(t, name)
+	_, err = syncer.Write([]byte("Bye!\n"))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+
+	content, err := ioutil.ReadFile(name)
+	assert.NoError(t, err, "Unexpected read error")
+	assert.Equal(t, "Hello Test!\nBye!\n", string(content),
+		"Unexpected file content")
+}
@@ -0,0 +1,73 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build linux || darwin || freebsd || openbsd || dragonfly
+// +build linux darwin freebsd openbsd dragonfly
+
+package santa
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// mmapSupported represents whether memory-mapped files are supported on
+// the current platform.
+const mmapSupported = true
+
+// mapFile maps the given number of bytes of the given file into memory for
+// reading and writing, and then returns the mapped region and any errors
+// encountered. Writes to the mapped region are written to the file.
+func mapFile(file *os.File, size int) ([]byte, error) {
+	region, err := syscall.Mmap(int(file.Fd()), 0, size,
+		syscall.PROT_READ | syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, os.NewSyscallError("mmap", err)
+	}
+	return region, nil
+}
+
+// unmapFile unmaps the given mapped region, and then returns any errors
+// encountered.
+func unmapFile(region []byte) error {
+	if err := syscall.Munmap(region); err != nil {
+		return os.NewSyscallError("munmap", err)
+	}
+	return nil
+}
+
+// syncRegion synchronizes the given part of a mapped region to the
+// persistent storage device, and then returns any errors encountered. The
+// start address of the given part must be aligned to the page size.
+func syncRegion(region []byte) error {
+	if len(region) == 0 {
+		return nil
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_MSYNC,
+		uintptr(unsafe.Pointer(&region[0])), uintptr(len(region)),
+		syscall.MS_SYNC)
+	if errno != 0 {
+		return os.NewSyscallError("msync", errno)
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+var (
+	// ErrInvalidMulti represents that the given multi-format exporter
+	// option is invalid, usually because no format is provided or the
+	// encoder or synchronizer of a format is not provided.
+	ErrInvalidMulti = errors.New("invalid multi-format option")
+)
+
+// ExporterFormat is a structure that contains an output format of the
+// multi-format exporter.
+type ExporterFormat struct {
+	// Encoder represents the encoder used to encode log entries of the
+	// format. This option is required.
+	Encoder Encoder
+
+	// Syncer represents the synchronizer to which log entries encoded by
+	// the encoder are written. This option is required.
+	Syncer Syncer
+}
+
+// multiGroup is the structure of the formats of the multi-format exporter
+// that share the same encoder.
+type multiGroup struct {
+	encoder Encoder
+	syncers []Syncer
+}
+
+// MultiExporter is the structure of the multi-format exporter instance.
+//
+// The multi-format exporter encodes each log entry with multiple encoders
+// and writes each encoding to its own synchronizer, for example, the
+// human-readable standard encoding to the standard output and the JSON
+// encoding to a file. Compared to multiple standard exporters, the log
+// entry is checked against the level span only once, the encoding buffer
+// is shared by all formats, and formats that share the same encoder
+// instance are encoded only once.
+//
+// Each log entry is written to all formats even if some of them fail, and
+// the errors encountered are returned together.
+//
+// The API provided by the multi-format exporter is thread-safe if the
+// encoders and synchronizers are thread-safe.
+type MultiExporter struct {
+	span LevelSpan
+	groups []multiGroup
+	syncers []Syncer
+	closed int32
+}
+
+// Export encodes the given log entry with the encoder of each format and
+// writes the encoded data to the synchronizer of the format.
+//
+// Finally, any errors encountered are returned. If multiple errors are
+// encountered, they are returned as a MultiError. If the exporter has been
+// closed, ErrClosed is returned.
+func (e *MultiExporter) Export(entry *Entry) error {
+	if atomic.LoadInt32(&e.closed) == 1 {
+		return ErrClosed
+	}
+	if !e.span.Contains(entry.Level) {
+		return nil
+	}
+	var result error
+	pointer := pool.Buffer.Exporter.New()
+	buffer := (*pointer)[ : 0]
+	for index := range e.groups {
+		group := &e.groups[index]
+		encoded, err := group.encoder.Encode(buffer[ : 0], entry)
+		if err != nil {
+			result = appendError(result, err)
+			continue
+		}
+		if encoded == nil {
+			continue
+		}
+		for _, syncer := range group.syncers {
+			_, err := syncer.Write(encoded)
+			result = appendError(result, err)
+		}
+		// The encoder may have grown the buffer, so the grown buffer is
+		// used by the following formats and returned to the pool.
+		buffer = encoded[ : 0]
+	}
+	*pointer = buffer
+	pool.Buffer.Exporter.Free(pointer)
+	return result
+}
+
+// Sync synchronizes the synchronizer of each format once.
+//
+// Finally, any errors encountered are returned. If multiple errors are
+// encountered, they are returned as a MultiError. If the exporter has been
+// closed, ErrClosed is returned.
+func (e *MultiExporter) Sync() error {
+	if atomic.LoadInt32(&e.closed) == 1 {
+		return ErrClosed
+	}
+	var result error
+	for _, syncer := range e.syncers {
+		result = appendError(result, syncer.Sync())
+	}
+	return result
+}
+
+// Close closes the synchronizer of each format once.
+//
+// Finally, any errors encountered are returned. If multiple errors are
+// encountered, they are returned as a MultiError. If the exporter has
+// already been closed, ErrClosed is returned.
+func (e *MultiExporter) Close() error {
+	if !atomic.CompareAndSwapInt32(&e.closed, 0, 1) {
+		return ErrClosed
+	}
+	var result error
+	for _, syncer := range e.syncers {
+		result = appendError(result, syncer.Close())
+	}
+	return result
+}
+
+// MultiExporterOption is a structure that contains options for the
+// multi-format exporter.
+type MultiExporterOption struct {
+	// Span represents the log level span. If the level of a log entry is
+	// included in the log level span, the log entry will be processed,
+	// otherwise it will be discarded. If not provided, the default value
+	// is DEBUG level to FATAL level.
+	Span LevelSpan
+
+	// Formats represents the output formats of the exporter. For details,
+	// please refer to the comment section of the ExporterFormat structure.
+	// At least one format is required.
+	Formats []ExporterFormat
+}
+
+// UseSpan uses the given log level span as the value of the Span option.
+// For details, please refer to the comment section of the Span option.
+// Then return to the option instance itself.
+func (o *MultiExporterOption) UseSpan(start, end Level) *MultiExporterOption {
+	o.Span = LevelSpan {
+		Start: start,
+		End: end,
+	}
+	return o
+}
+
+// UseFormat appends a format with the given encoder and synchronizer to
+// the value of the Formats option. For details, please refer to the
+// comment section of the Formats option. Then return to the option
+// instance itself.
+func (o *MultiExporterOption) UseFormat(encoder Encoder, syncer Syncer) *MultiExporterOption {
+	o.Formats = append(o.Formats, ExporterFormat {
+		Encoder: encoder,
+		Syncer: syncer,
+	})
+	return o
+}
+
+// Validate checks whether the values of the options are valid, and returns
+// an error describing the first invalid value.
+func (o *MultiExporterOption) Validate() error {
+	if len(o.Formats) == 0 {
+		return ErrInvalidMulti
+	}
+	for _, format := range o.Formats {
+		if format.Encoder == nil || format.Syncer == nil {
+			return ErrInvalidMulti
+		}
+	}
+	return o.Span.Validate()
+}
+
+// Build builds and returns an instance of the multi-format exporter and
+// any errors encountered.
+func (o *MultiExporterOption) Build() (*MultiExporter, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	instance := &MultiExporter {
+		span: o.Span,
+	}
+	for _, format := range o.Formats {
+		instance.appendFormat(format)
+	}
+	return instance, nil
+}
+
+// appendFormat appends the given format to the group of its encoder, and
+// appends its synchronizer to the synchronizers that are synchronized and
+// closed by the exporter, unless it has already been appended.
+func (e *MultiExporter) appendFormat(format ExporterFormat) {
+	found := false
+	for index := range e.groups {
+		if e.groups[index].encoder == format.Encoder {
+			e.groups[index].syncers = append(e.groups[index].syncers,
+				format.Syncer)
+			found = true
+			break
+		}
+	}
+	if !found {
+		e.groups = append(e.groups, multiGroup {
+			encoder: format.Encoder,
+			syncers: []Syncer { format.Syncer },
+		})
+	}
+	for _, syncer := range e.syncers {
+		if syncer == format.Syncer {
+			return
+		}
+	}
+	e.syncers = append(e.syncers, format.Syncer)
+}
+
+// NewMultiExporterOption creates and returns a multi-format exporter
+// option instance with default option values. At least one format must be
+// provided before building.
+func NewMultiExporterOption() *MultiExporterOption {
+	return &MultiExporterOption {
+		Span: LevelSpan {
+			Start: LevelDebug,
+			End: LevelFatal,
+		},
+	}
+}
@@ -0,0 +1,91 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testCountEncoder is an encoder that counts the number of encodings.
+type testCountEncoder struct {
+	Encoder
+	encodes int
+}
+
+func (e *testCountEncoder) Encode(buffer []byte, entry *Entry) ([]byte, error) {
+	e.encodes++
+	return e.Encoder.Encode(buffer, entry)
+}
+
+func TestMultiExporterOption(t *testing.T) {
+	_, err := NewMultiExporterOption().Build()
+	assert.Equal(t, ErrInvalidMulti, err, "Unexpected build error")
+
+	_, err = NewMultiExporterOption().UseFormat(nil, nil).Build()
+	assert.Equal(t, ErrInvalidMulti, err, "Unexpected build error")
+}
+
+func TestMultiExporter(t *testing.T) {
+	standardEncoder, err := NewStandardEncoder()
+	assert.NoError(t, err, "Unexpected build error")
+	jsonEncoder, err := NewJSONEncoder()
+	assert.NoError(t, err, "Unexpected build error")
+	standard := &testCountEncoder { Encoder: standardEncoder }
+	json := &testCountEncoder { Encoder: jsonEncoder }
+
+	console, file, network := &testCountWriter { }, &testCountWriter { },
+		&testCountWriter { }
+	exporter, err := NewMultiExporterOption().
+		UseSpan(LevelInfo, LevelFatal).
+		UseFormat(standard, newBatchTestSyncer(t, console)).
+		UseFormat(json, newBatchTestSyncer(t, file)).
+		UseFormat(json, newBatchTestSyncer(t, network)).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	assert.NoError(t, exporter.Export(&Entry {
+		Level: LevelDebug,
+		Message: StringMessage("ignored"),
+	}), "Unexpected export error")
+	assert.NoError(t, exporter.Export(&Entry {
+		Level: LevelInfo,
+		Message: StringMessage("hello"),
+	}), "Unexpected export error")
+
+	// Formats that share the same encoder are encoded only once.
+	assert.Equal(t, 1, standard.encodes, "Unexpected encode count")
+	assert.Equal(t, 1, json.encodes, "Unexpected encode count")
+	assert.True(t, bytes.Contains(console.data, []byte("[INFO] \"hello\"")),
+		"Unexpected written data")
+	assert.True(t, bytes.Contains(file.data, []byte("\"hello\"")),
+		"Unexpected written data")
+	assert.Equal(t, file.data, network.data, "Unexpected written data")
+
+	assert.NoError(t, exporter.Sync(), "Unexpected sync error")
+	assert.NoError(t, exporter.Close(), "Unexpected close error")
+	assert.Equal(t, ErrClosed, exporter.Export(entry),
+		"Unexpected export error")
+	assert.Equal(t, ErrClosed, exporter.Close(), "Unexpected close error")
+}
@@ -0,0 +1,185 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+// mutateOperation is a data type that represents the kind of operation of
+// the mutation hook.
+type mutateOperation uint8
+
+const (
+	// mutateAdd represents adding or replacing a field.
+	mutateAdd mutateOperation = iota
+
+	// mutateRemove represents removing a field.
+	mutateRemove
+
+	// mutateRename represents renaming a field.
+	mutateRename
+)
+
+// mutation is a structure that contains an operation of the mutation hook.
+type mutation struct {
+	operation mutateOperation
+	name string
+	field Field
+}
+
+// MutateHook is a structure that contains the operations of mutation.
+//
+// The mutation hook adds, removes and renames the top-level fields of the
+// structured messages of log entries, which allows normalization pipelines
+// (for example, renaming legacy field names) to be built without writing
+// hooks manually. The operations are applied in the order in which they
+// are added, for example:
+//
+//	hook := santa.NewMutateHook().
+//		RenameKey("userName", "user").
+//		RemoveField("debugDump").
+//		AddField(santa.String("service", "billing"))
+//
+// The log entries of other message types are not modified. Like the
+// redaction hook, the mutation hook does not modify the messages and
+// fields given by the application, a mutated copy of the message is used
+// for the log entry instead.
+//
+// Please note that operations must be added before the hook is bound to
+// loggers, adding operations is not thread-safe.
+type MutateHook struct {
+	mutations []mutation
+	adds int
+}
+
+// AddField adds an operation that adds the given field. If a field with
+// the same name exists, its value is replaced. Then return to the hook
+// instance itself.
+func (h *MutateHook) AddField(field Field) *MutateHook {
+	h.mutations = append(h.mutations, mutation {
+		operation: mutateAdd,
+		field: field,
+	})
+	h.adds++
+	return h
+}
+
+// RemoveField adds an operation that removes the fields with the given
+// name. Then return to the hook instance itself.
+func (h *MutateHook) RemoveField(name string) *MutateHook {
+	h.mutations = append(h.mutations, mutation {
+		operation: mutateRemove,
+		name: name,
+	})
+	return h
+}
+
+// RenameKey adds an operation that renames the fields with the given name
+// to the given new name. If a field with the new name exists, it is
+// replaced by the renamed field. Then return to the hook instance itself.
+func (h *MutateHook) RenameKey(name, renamed string) *MutateHook {
+	h.mutations = append(h.mutations, mutation {
+		operation: mutateRename,
+		name: name,
+		field: Field {
+			Name: renamed,
+		},
+	})
+	return h
+}
+
+// Print mutates the structured message of the given log entry, and then
+// returns nil.
+func (h *MutateHook) Print(entry *Entry) error {
+	if len(h.mutations) == 0 {
+		return nil
+	}
+	switch message := entry.Message.(type) {
+	case *StructMessage:
+		entry.SetMessage(StructMessage {
+			Text: message.Text,
+			Fields: h.mutate(message.Fields),
+		})
+	case StructMessage:
+		entry.SetMessage(StructMessage {
+			Text: message.Text,
+			Fields: h.mutate(message.Fields),
+		})
+	}
+	return nil
+}
+
+// mutate applies the operations to a copy of the given fields, and then
+// returns the mutated copy.
+func (h *MutateHook) mutate(fields ElementObject) ElementObject {
+	mutated := make(ElementObject, len(fields), len(fields) + h.adds)
+	copy(mutated, fields)
+	for index := 0; index < len(h.mutations); index++ {
+		operation := &h.mutations[index]
+		switch operation.operation {
+		case mutateAdd:
+			mutated = removeFields(mutated, operation.field.Name)
+			mutated = append(mutated, operation.field)
+		case mutateRemove:
+			mutated = removeFields(mutated, operation.name)
+		case mutateRename:
+			if operation.name == operation.field.Name ||
+				!hasField(mutated, operation.name) {
+				continue
+			}
+			mutated = removeFields(mutated, operation.field.Name)
+			for position := range mutated {
+				if mutated[position].Name == operation.name {
+					mutated[position].Name = operation.field.Name
+				}
+			}
+		}
+	}
+	return mutated
+}
+
+// hasField checks whether the given fields contain a field with the given
+// name.
+func hasField(fields ElementObject, name string) bool {
+	for index := 0; index < len(fields); index++ {
+		if fields[index].Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// removeFields removes the fields with the given name from the given
+// fields in place, and then returns the remaining fields.
+func removeFields(fields ElementObject, name string) ElementObject {
+	remaining := fields[ : 0]
+	for index := 0; index < len(fields); index++ {
+		if fields[index].Name != name {
+			remaining = append(remaining, fields[index])
+		}
+	}
+	return remaining
+}
+
+// NewMutateHook creates and returns a mutation hook instance without any
+// operation.
+func NewMutateHook() *MutateHook {
+	return &MutateHook { }
+}
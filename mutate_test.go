@@ -0,0 +1,72 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutateHook(t *testing.T) {
+	hook := NewMutateHook().
+		RenameKey("userName", "user").
+		RemoveField("debugDump").
+		AddField(String("service", "billing")).
+		AddField(Int("version", 2))
+	assert.Len(t, hook.mutations, 4, "Unexpected instance error")
+
+	fields := ElementObject {
+		String("userName", "alice"),
+		String("user", "legacy"),
+		String("debugDump", "..."),
+		Int("version", 1),
+	}
+	entry := &Entry {
+		Message: &StructMessage {
+			Text: "Hello Test!",
+			Fields: fields,
+		},
+	}
+	assert.NoError(t, hook.Print(entry), "Unexpected print error")
+
+	assert.JSONEq(t, `{
+		"text": "Hello Test!",
+		"payload": {
+			"user": "alice",
+			"service": "billing",
+			"version": 2
+		}
+	}`, string(entry.Message.(JSONSerializer).SerializeJSON(nil)),
+		"Unexpected mutation result")
+
+	// The fields given by the application must not be modified.
+	assert.Equal(t, "userName", fields[0].Name, "Unexpected mutation result")
+	assert.Equal(t, "debugDump", fields[2].Name, "Unexpected mutation result")
+
+	// Log entries of other message types are not modified.
+	entry = &Entry { Message: StringMessage("Hello Test!") }
+	assert.NoError(t, hook.Print(entry), "Unexpected print error")
+	assert.Equal(t, StringMessage("Hello Test!"), entry.Message,
+		"Unexpected mutation result")
+}
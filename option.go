@@ -0,0 +1,138 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"io"
+)
+
+// OptionFunc is a function that changes the given standard logger option.
+// It is an alternative to the builder methods of the option structures
+// for simple setups, for example:
+//
+//	logger, err := santa.NewStandard(santa.WithLevel(santa.LevelInfo),
+//		santa.WithJSON(), santa.WithFile("/var/log/app.log"))
+type OptionFunc func(option *StandardOption)
+
+// Apply calls the given option functions with the option in order. Then
+// return to the option instance itself.
+func (o *StandardOption) Apply(options ...OptionFunc) *StandardOption {
+	for _, option := range options {
+		option(o)
+	}
+	return o
+}
+
+// WithName returns an option function that uses the given name as the
+// value of the Name option.
+func WithName(name string) OptionFunc {
+	return func(option *StandardOption) {
+		option.UseName(name)
+	}
+}
+
+// WithLevel returns an option function that uses the given level as the
+// value of the Level option.
+func WithLevel(level Level) OptionFunc {
+	return func(option *StandardOption) {
+		option.UseLevel(level)
+	}
+}
+
+// WithLabels returns an option function that uses the given labels as the
+// value of the Labels option.
+func WithLabels(labels ...Label) OptionFunc {
+	return func(option *StandardOption) {
+		option.UseLabels(labels...)
+	}
+}
+
+// WithHooks returns an option function that uses the given hooks as the
+// value of the Hooks option.
+func WithHooks(hooks ...Hook) OptionFunc {
+	return func(option *StandardOption) {
+		option.UseHooks(hooks...)
+	}
+}
+
+// WithExporters returns an option function that uses the given exporters
+// as the value of the Exporters option.
+func WithExporters(exporters ...Exporter) OptionFunc {
+	return func(option *StandardOption) {
+		option.UseExporters(exporters...)
+	}
+}
+
+// WithJSON returns an option function that uses the JSON encoder with
+// default optional values.
+func WithJSON() OptionFunc {
+	return func(option *StandardOption) {
+		option.Encoding.UseJSON()
+	}
+}
+
+// WithStandardEncoding returns an option function that uses the standard
+// encoder with default optional values.
+func WithStandardEncoding() OptionFunc {
+	return func(option *StandardOption) {
+		option.Encoding.UseStandard()
+	}
+}
+
+// WithFile returns an option function that outputs the log entries of all
+// levels to the file with the given name, including the log entries of
+// the ERROR and FATAL levels which are output to the standard error by
+// default.
+func WithFile(name string) OptionFunc {
+	return func(option *StandardOption) {
+		option.Outputting.UseFile(name)
+		option.ErrorOutputting.UseFile(name)
+	}
+}
+
+// WithWriter returns an option function that outputs the log entries of
+// all levels to the given writer.
+func WithWriter(writer io.Writer) OptionFunc {
+	return func(option *StandardOption) {
+		option.Outputting.UseStandard(writer)
+		option.ErrorOutputting.UseStandard(writer)
+	}
+}
+
+// WithoutSampling returns an option function that disables sampling. For
+// details, see the DisableSampling function of the StandardOption
+// structure.
+func WithoutSampling() OptionFunc {
+	return func(option *StandardOption) {
+		option.DisableSampling()
+	}
+}
+
+// WithoutCache returns an option function that disables the internal
+// cache of the synchronizers. For details, see the DisableCache function
+// of the StandardOption structure.
+func WithoutCache() OptionFunc {
+	return func(option *StandardOption) {
+		option.DisableCache()
+	}
+}
@@ -0,0 +1,94 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionFunc(t *testing.T) {
+	buffer := &bytes.Buffer { }
+	hook := NewSimpleHook(func(entry *Entry) error {
+		return nil
+	})
+	exporter := &testRecordExporter { }
+	logger, err := NewStandard(
+		WithName("santa"),
+		WithLevel(LevelInfo),
+		WithLabels(NewLabel("module", "test")),
+		WithHooks(hook),
+		WithExporters(exporter),
+		WithJSON(),
+		WithWriter(buffer),
+		WithoutSampling(),
+		WithoutCache(),
+	)
+	assert.NoError(t, err, "Unexpected build error")
+
+	assert.NoError(t, logger.Debug(StringMessage("Hello Debug!")),
+		"Unexpected print error")
+	assert.NoError(t, logger.Info(StringMessage("Hello Info!")),
+		"Unexpected print error")
+	assert.NoError(t, logger.Error(StringMessage("Hello Error!")),
+		"Unexpected print error")
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+
+	output := buffer.String()
+	assert.NotContains(t, output, "Hello Debug!", "Unexpected output")
+	assert.Contains(t, output, `"Hello Info!"`, "Unexpected output")
+	assert.Contains(t, output, `"Hello Error!"`, "Unexpected output")
+	assert.Contains(t, output, `"santa"`, "Unexpected output")
+	assert.Contains(t, output, `"module": "test"`, "Unexpected output")
+	assert.Len(t, exporter.entries, 2, "Unexpected log entries")
+
+	option := NewStandardOption().Apply(WithStandardEncoding())
+	assert.Equal(t, EncoderStandard, option.Encoding.Type,
+		"Unexpected option value")
+}
+
+func TestOptionFuncFile(t *testing.T) {
+	directory, err := ioutil.TempDir("", "santa")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	name := filepath.Join(directory, "santa.log")
+	logger, err := NewStruct(WithFile(name))
+	assert.NoError(t, err, "Unexpected build error")
+	assert.NoError(t, logger.Infos("Hello Info!"), "Unexpected print error")
+	assert.NoError(t, logger.Errors("Hello Error!"), "Unexpected print error")
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+
+	data, err := ioutil.ReadFile(name)
+	assert.NoError(t, err, "Unexpected read error")
+	assert.Contains(t, string(data), "Hello Info!", "Unexpected output")
+	assert.Contains(t, string(data), "Hello Error!", "Unexpected output")
+
+	template := MustNewTemplate(WithWriter(ioutil.Discard))
+	assert.NoError(t, template.Close(), "Unexpected close error")
+}
@@ -22,7 +22,18 @@
 
 package santa
 
-import "sync"
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrInvalidCapacity represents the given buffer capacity is invalid.
+	// The capacity must be greater than 0, and the retained capacity must
+	// not be less than the initial capacity.
+	ErrInvalidCapacity = errors.New("invalid buffer capacity")
+)
 
 // StructMessagePool is a structure that contains instances of
 // cached structured messages.
@@ -80,6 +91,7 @@ func (p *TemplateMessagePool) New(template string, args []interface { }) *Templa
 	message := p.pool.Get().(*TemplateMessage)
 	message.Template = template
 	message.Args = args
+	message.format.formatted = false
 	return message
 }
 
@@ -96,7 +108,9 @@ func NewTemplateMessagePool() *TemplateMessagePool {
 	return &TemplateMessagePool {
 		pool: &sync.Pool {
 			New: func() interface { } {
-				return &TemplateMessage { }
+				return &TemplateMessage {
+					format: &templateFormat { },
+				}
 			},
 		},
 	}
@@ -148,10 +162,20 @@ func NewEntryPool() *EntryPool {
 // contexts, which will significantly reduce the number of heap memory
 // allocations.
 //
+// The buffer instance may grow beyond its initial capacity while encoding
+// a large log entry, and the grown buffer is returned to the pool so that
+// subsequent large entries do not need to reallocate. However, buffers
+// whose capacity exceeds the retained capacity of the pool are discarded
+// when they are returned, so a few very large log entries will not cause
+// the pool to retain huge buffers.
+//
 // Note that any instance of exporter buffer should use this pool
 // allocation.
 type ExporterBufferPool struct {
+	capacity int64
+	retained int64
 	pool *sync.Pool
+	discarded int32
 }
 
 // New gets and returns a reusable exporter buffer instance from the
@@ -165,23 +189,68 @@ func (p *ExporterBufferPool) New() *[]byte {
 }
 
 // Free returns the given exporter buffer instance to the buffer pool.
-// After the refund, the exporter buffer instance is not allowed to be
-// used again, otherwise the behavior is undefined.
+// If the capacity of the buffer exceeds the retained capacity of the
+// pool, the buffer is discarded. After the refund, the exporter buffer
+// instance is not allowed to be used again, otherwise the behavior is
+// undefined.
 func (p *ExporterBufferPool) Free(buffer *[]byte) {
+	if int64(cap(*buffer)) > atomic.LoadInt64(&p.retained) {
+		// Discarding buffers repeatedly defeats the pool, so the first
+		// discarded buffer of each pool is reported as an anomaly.
+		if atomic.CompareAndSwapInt32(&p.discarded, 0, 1) {
+			diagnose("discarded exporter buffer of %d bytes exceeding the "+
+				"retained capacity of %d bytes, consider ConfigureGlobalPool",
+				cap(*buffer), p.RetainedCapacity())
+		}
+		return
+	}
 	p.pool.Put(buffer)
 }
 
-// NewExporterBufferPool creates and returns a log entry buffer pool
-// instance.
+// Capacity returns the initial capacity of the newly allocated buffer
+// instances of the pool.
+func (p *ExporterBufferPool) Capacity() int {
+	return int(atomic.LoadInt64(&p.capacity))
+}
+
+// RetainedCapacity returns the maximum capacity of buffer instances that
+// the pool will retain when they are returned.
+func (p *ExporterBufferPool) RetainedCapacity() int {
+	return int(atomic.LoadInt64(&p.retained))
+}
+
+// SetCapacity sets the initial capacity of the newly allocated buffer
+// instances of the pool and the maximum capacity of buffer instances
+// that the pool will retain, and then returns any errors encountered.
+// Buffer instances that have been cached in the pool are not affected.
+//
+// This function is thread-safe.
+func (p *ExporterBufferPool) SetCapacity(capacity int, retained int) error {
+	if capacity <= 0 || retained < capacity {
+		return ErrInvalidCapacity
+	}
+	atomic.StoreInt64(&p.capacity, int64(capacity))
+	atomic.StoreInt64(&p.retained, int64(retained))
+	return nil
+}
+
+// NewExporterBufferPool creates and returns a exporter buffer pool
+// instance. The initial capacity of each buffer is the given capacity,
+// and the retained capacity of the pool is 32 times of it. For details,
+// please refer to the comment section of the ExporterBufferPool
+// structure.
 func NewExporterBufferPool(capacity int) *ExporterBufferPool {
-	return &ExporterBufferPool {
-		pool: &sync.Pool {
-			New: func() interface { } {
-				buffer := make([]byte, 0, capacity)
-				return &buffer
-			},
+	instance := &ExporterBufferPool {
+		capacity: int64(capacity),
+		retained: int64(capacity) * 32,
+	}
+	instance.pool = &sync.Pool {
+		New: func() interface { } {
+			buffer := make([]byte, 0, instance.Capacity())
+			return &buffer
 		},
 	}
+	return instance
 }
 
 // GlobalPool is a structure that contains default instances of various
@@ -198,6 +267,13 @@ type GlobalPool struct {
 	Buffer struct {
 		Exporter *ExporterBufferPool
 	}
+
+	decorator struct {
+		base *sync.Pool
+		standard *sync.Pool
+		template *sync.Pool
+		structure *sync.Pool
+	}
 }
 
 // NewGlobalPool creates instances of various pools and returns the value
@@ -210,6 +286,26 @@ func NewGlobalPool() GlobalPool {
 	instance.Message.Template = NewTemplateMessagePool()
 	instance.Message.Structure = NewStructMessagePool()
 	instance.Buffer.Exporter = NewExporterBufferPool(2048)
+	instance.decorator.base = &sync.Pool {
+		New: func() interface { } {
+			return &Decorator { }
+		},
+	}
+	instance.decorator.standard = &sync.Pool {
+		New: func() interface { } {
+			return &StandardDecorator { }
+		},
+	}
+	instance.decorator.template = &sync.Pool {
+		New: func() interface { } {
+			return &TemplateDecorator { }
+		},
+	}
+	instance.decorator.structure = &sync.Pool {
+		New: func() interface { } {
+			return &StructDecorator { }
+		},
+	}
 	return instance
 }
 
@@ -222,3 +318,59 @@ var pool GlobalPool = NewGlobalPool()
 func GetGlobalPool() GlobalPool {
 	return pool
 }
+
+// GlobalPoolOption is a structure that contains options for the global
+// pool.
+type GlobalPoolOption struct {
+	// BufferCapacity represents the initial capacity of each exporter
+	// buffer instance allocated by the global pool. Applications that
+	// usually export large log entries can increase this value to avoid
+	// growing buffers. If not provided, the default value is 2048.
+	BufferCapacity int
+
+	// BufferRetainedCapacity represents the maximum capacity of exporter
+	// buffer instances that the global pool will retain after use. Buffers
+	// grown beyond this capacity are discarded. If not provided, the
+	// default value is 65536.
+	BufferRetainedCapacity int
+}
+
+// UseBufferCapacity uses the given initial capacity and retained capacity
+// as the values of the options BufferCapacity and BufferRetainedCapacity.
+// Then return to the option instance itself.
+func (o *GlobalPoolOption) UseBufferCapacity(capacity int, retained int) *GlobalPoolOption {
+	o.BufferCapacity = capacity
+	o.BufferRetainedCapacity = retained
+	return o
+}
+
+// Apply applies the optional values to the given global pool, and then
+// returns any errors encountered. Since the pools of the global pool are
+// shared by reference, the copies of the global pool obtained before are
+// also affected.
+//
+// This function is thread-safe.
+func (o *GlobalPoolOption) Apply(instance GlobalPool) error {
+	return instance.Buffer.Exporter.SetCapacity(o.BufferCapacity,
+		o.BufferRetainedCapacity)
+}
+
+// NewGlobalPoolOption creates and returns an optional instance of the
+// global pool using the default optional values.
+func NewGlobalPoolOption() *GlobalPoolOption {
+	return &GlobalPoolOption {
+		BufferCapacity: 2048,
+		BufferRetainedCapacity: 2048 * 32,
+	}
+}
+
+// ConfigureGlobalPool applies the given optional values to the default
+// global pool, and then returns any errors encountered. If the given
+// option is nil, the default option is used. For details, please refer
+// to the comment section of the GlobalPoolOption structure.
+func ConfigureGlobalPool(option *GlobalPoolOption) error {
+	if option == nil {
+		option = NewGlobalPoolOption()
+	}
+	return option.Apply(pool)
+}
@@ -60,7 +60,10 @@ func TestTemplateMessagePool(t *testing.T) {
 
 	assert.NotNil(t, message, "Unexpected new error")
 	assert.IsType(t, &TemplateMessage { }, message, "Unexpected new result")
-	assert.Equal(t, sample, *message, "Unexpected message value")
+	assert.Equal(t, sample.Template, message.Template,
+		"Unexpected message value")
+	assert.Equal(t, sample.Args, message.Args, "Unexpected message value")
+	assert.NotNil(t, message.format, "Unexpected message value")
 
 	pool.Free(message)
 }
@@ -88,3 +91,81 @@ func TestExporterBufferPool(t *testing.T) {
 
 	pool.Free(pointer)
 }
+
+func TestExporterBufferPoolRetained(t *testing.T) {
+	pool := NewExporterBufferPool(16)
+
+	pointer := pool.New()
+	*pointer = make([]byte, 0, 16 * 32)
+	pool.Free(pointer)
+
+	pointer = pool.New()
+	assert.LessOrEqual(t, cap(*pointer), 16 * 32,
+		"Unexpected buffer capacity")
+
+	*pointer = make([]byte, 0, 16 * 64)
+	pool.Free(pointer)
+
+	pointer = pool.New()
+	assert.Equal(t, 16, cap(*pointer), "Unexpected buffer capacity")
+	pool.Free(pointer)
+}
+
+func TestExporterBufferPoolCapacity(t *testing.T) {
+	pool := NewExporterBufferPool(16)
+	assert.Equal(t, 16, pool.Capacity(), "Unexpected pool capacity")
+	assert.Equal(t, 16 * 32, pool.RetainedCapacity(),
+		"Unexpected pool capacity")
+
+	assert.Equal(t, ErrInvalidCapacity, pool.SetCapacity(0, 16),
+		"Unexpected set error")
+	assert.Equal(t, ErrInvalidCapacity, pool.SetCapacity(32, 16),
+		"Unexpected set error")
+
+	assert.NoError(t, pool.SetCapacity(64, 128), "Unexpected set error")
+	assert.Equal(t, 64, pool.Capacity(), "Unexpected pool capacity")
+	assert.Equal(t, 128, pool.RetainedCapacity(),
+		"Unexpected pool capacity")
+}
+
+func TestConfigureGlobalPool(t *testing.T) {
+	option := NewGlobalPoolOption()
+	assert.Equal(t, 2048, option.BufferCapacity, "Unexpected option value")
+	assert.Equal(t, 2048 * 32, option.BufferRetainedCapacity,
+		"Unexpected option value")
+
+	option.UseBufferCapacity(4096, 4096 * 16)
+	assert.NoError(t, ConfigureGlobalPool(option),
+		"Unexpected configure error")
+
+	instance := GetGlobalPool()
+	assert.Equal(t, 4096, instance.Buffer.Exporter.Capacity(),
+		"Unexpected pool capacity")
+	assert.Equal(t, 4096 * 16, instance.Buffer.Exporter.RetainedCapacity(),
+		"Unexpected pool capacity")
+
+	option.UseBufferCapacity(0, 0)
+	assert.Equal(t, ErrInvalidCapacity, ConfigureGlobalPool(option),
+		"Unexpected configure error")
+
+	assert.NoError(t, ConfigureGlobalPool(nil), "Unexpected configure error")
+	assert.Equal(t, 2048, instance.Buffer.Exporter.Capacity(),
+		"Unexpected pool capacity")
+}
+
+func TestGlobalPool(t *testing.T) {
+	instance := NewGlobalPool()
+	assert.NotNil(t, instance.Entry, "Unexpected pool instance")
+	assert.NotNil(t, instance.Message.Structure, "Unexpected pool instance")
+	assert.NotNil(t, instance.Message.Template, "Unexpected pool instance")
+	assert.NotNil(t, instance.Buffer.Exporter, "Unexpected pool instance")
+
+	instance = GetGlobalPool()
+	assert.Same(t, pool.Entry, instance.Entry, "Unexpected pool instance")
+	assert.Same(t, pool.Message.Structure, instance.Message.Structure,
+		"Unexpected pool instance")
+	assert.Same(t, pool.Message.Template, instance.Message.Template,
+		"Unexpected pool instance")
+	assert.Same(t, pool.Buffer.Exporter, instance.Buffer.Exporter,
+		"Unexpected pool instance")
+}
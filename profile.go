@@ -0,0 +1,50 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build !tinygo
+// +build !tinygo
+
+package santa
+
+import (
+	"context"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// profileOutput runs the write function with pprof labels containing the
+// name of the logger and the level of the log entry, so that the CPU time
+// spent on logging can be attributed in CPU profiles. If a runtime trace is
+// being collected, a user log event is emitted for each FATAL log entry.
+// The given call depth is relative to the caller of this function.
+func (l *Logger) profileOutput(callDepth int, level Level, message Message, name string,
+	labels SerializedLabels, settings *loggerSettings) error {
+	var err error
+	pprof.Do(context.Background(), pprof.Labels(ProfileLabelName, name,
+		ProfileLabelLevel, level.String()), func(ctx context.Context) {
+		if level == LevelFatal && trace.IsEnabled() {
+			trace.Log(ctx, TraceCategory, messageText(message))
+		}
+		err = l.write(callDepth + 3, level, message, name, labels, settings)
+	})
+	return err
+}
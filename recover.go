@@ -0,0 +1,69 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// RecoverAndLog recovers the panic of the current goroutine, if any, and
+// outputs a FATAL log entry with the given structured logger, whose fields
+// are the panic value (named "panic") and the stack trace of the current
+// goroutine (named "stack"). The logger is flushed after the log entry is
+// output, so that the log entry is not lost if the application crashes.
+// If the parameter rethrow is true, it panics again with the recovered
+// value after the log entry is output.
+//
+// It must be called directly by a defer statement, otherwise the panic
+// cannot be recovered, for example:
+//
+//	defer santa.RecoverAndLog(logger, true)
+//
+// The source location of the log entry is the function that panicked.
+func RecoverAndLog(logger *StructLogger, rethrow bool) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	// The caller of this function is the panic function of the runtime,
+	// which is called by the function that panicked.
+	message := pool.Message.Structure.New("Recovered from panic",
+		PanicFields(recovered))
+	_ = logger.Output(3, LevelFatal, message)
+	pool.Message.Structure.Free(message)
+	_ = logger.Sync()
+	if rethrow {
+		panic(recovered)
+	}
+}
+
+// PanicFields returns the fields of the log entry of the given recovered
+// panic value, which are the panic value (named "panic") and the stack
+// trace of the current goroutine (named "stack").
+func PanicFields(recovered interface { }) []Field {
+	return []Field {
+		String("panic", fmt.Sprint(recovered)),
+		String("stack", string(debug.Stack())),
+	}
+}
@@ -0,0 +1,73 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testPanic is a helper function that panics with the given value, which
+// is reported as the source location of the recovered panic.
+func testPanic(value interface { }) {
+	panic(value)
+}
+
+func TestRecoverAndLog(t *testing.T) {
+	exporter := &testRecordExporter { }
+	option := NewStructOption().UseExporters(exporter)
+	option.Outputting.UseDiscard()
+	option.ErrorOutputting.UseDiscard()
+
+	logger, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	func() {
+		defer RecoverAndLog(logger, false)
+	}()
+	assert.Empty(t, exporter.entries, "Unexpected log entries")
+
+	func() {
+		defer RecoverAndLog(logger, false)
+		testPanic("Hello Test!")
+	}()
+	assert.Len(t, exporter.entries, 1, "Unexpected log entries")
+	entry := exporter.entries[0]
+	assert.Equal(t, LevelFatal, entry.Level, "Unexpected log entry level")
+	assert.Len(t, entry.Fields, 2, "Unexpected entry fields")
+	assert.Equal(t, String("panic", "Hello Test!"), entry.Fields[0],
+		"Unexpected entry fields")
+	assert.Equal(t, "stack", entry.Fields[1].Name, "Unexpected entry fields")
+	assert.Contains(t, entry.Fields[1].Element.String, "testPanic",
+		"Unexpected entry fields")
+	assert.Contains(t, entry.SourceLocation.FunctionName(), "testPanic",
+		"Unexpected source location")
+
+	assert.PanicsWithValue(t, "Bye!", func() {
+		defer RecoverAndLog(logger, true)
+		testPanic("Bye!")
+	}, "Unexpected panic value")
+	assert.Len(t, exporter.entries, 2, "Unexpected log entries")
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+}
@@ -0,0 +1,341 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+)
+
+// RedactionHook is a structure that contains the rules of redaction.
+//
+// The redaction hook masks or hashes sensitive values of log entries
+// before they are encoded, which helps applications to meet compliance
+// requirements. The values of the fields of structured messages whose
+// names match the given field names are redacted entirely, and the parts
+// of string values (including the text of messages and the values of
+// string and byte slice fields) that match the given patterns are
+// redacted.
+//
+// The redaction hook does not modify the messages and fields given by the
+// application. If a message needs to be redacted, a redacted copy of the
+// message is used for the log entry instead.
+type RedactionHook struct {
+	names map[string]struct { }
+	patterns []*regexp.Regexp
+	mask string
+	hash bool
+}
+
+// Print redacts the message of the given log entry, and then returns nil.
+func (h *RedactionHook) Print(entry *Entry) error {
+	switch message := entry.Message.(type) {
+	case *StructMessage:
+		if redacted, ok := h.redactStruct(*message); ok {
+			entry.SetMessage(redacted)
+		}
+	case StructMessage:
+		if redacted, ok := h.redactStruct(message); ok {
+			entry.SetMessage(redacted)
+		}
+	case StringMessage:
+		if text, ok := h.redactText(string(message)); ok {
+			entry.Message = StringMessage(text)
+		}
+	case *TemplateMessage:
+		if len(h.patterns) > 0 {
+			entry.Message = h.redactTemplate(*message)
+		}
+	case TemplateMessage:
+		if len(h.patterns) > 0 {
+			entry.Message = h.redactTemplate(message)
+		}
+	}
+	return nil
+}
+
+// redactStruct redacts the given structured message. If the message does
+// not need to be redacted, it returns false.
+func (h *RedactionHook) redactStruct(message StructMessage) (StructMessage, bool) {
+	text, textRedacted := h.redactText(message.Text)
+	fields, fieldsRedacted := h.redactFields(message.Fields)
+	if !textRedacted && !fieldsRedacted {
+		return message, false
+	}
+	return StructMessage {
+		Text: text,
+		Fields: fields,
+	}, true
+}
+
+// redactTemplate formats the given template message and redacts the
+// formatted text. The template message is formatted in advance because
+// the patterns need to match the formatted text.
+func (h *RedactionHook) redactTemplate(message TemplateMessage) Message {
+	text := message.text()
+	if redacted, ok := h.redactText(text); ok {
+		return StringMessage(redacted)
+	}
+	return StringMessage(text)
+}
+
+// redactFields redacts the given fields recursively. If any field needs
+// to be redacted, a redacted copy of the fields is returned, otherwise the
+// given fields and false are returned.
+func (h *RedactionHook) redactFields(fields ElementObject) (ElementObject, bool) {
+	var redacted ElementObject
+	for index := 0; index < len(fields); index++ {
+		field, ok := h.redactField(fields[index])
+		if !ok {
+			continue
+		}
+		if redacted == nil {
+			redacted = make(ElementObject, len(fields))
+			copy(redacted, fields)
+		}
+		redacted[index] = field
+	}
+	if redacted == nil {
+		return fields, false
+	}
+	return redacted, true
+}
+
+// redactField redacts the given field. If the field does not need to be
+// redacted, it returns false.
+func (h *RedactionHook) redactField(field Field) (Field, bool) {
+	if _, ok := h.names[field.Name]; ok {
+		switch field.Type {
+		case TypeString:
+			return String(field.Name, h.replace(field.String)), true
+		case TypeBytes:
+			return String(field.Name, h.replace(string(
+				field.Bytes()))), true
+		default:
+			return String(field.Name, h.replace(string(
+				field.SerializeStandard(nil)))), true
+		}
+	}
+	switch field.Type {
+	case TypeString:
+		if value, ok := h.redactText(field.String); ok {
+			return String(field.Name, value), true
+		}
+	case TypeBytes:
+		if value, ok := h.redactText(string(field.Bytes())); ok {
+			return String(field.Name, value), true
+		}
+	case TypeStrings:
+		values := field.Strings()
+		var redacted []string
+		for index := 0; index < len(values); index++ {
+			value, ok := h.redactText(values[index])
+			if !ok {
+				continue
+			}
+			if redacted == nil {
+				redacted = append([]string(nil), values...)
+			}
+			redacted[index] = value
+		}
+		if redacted != nil {
+			return Strings(field.Name, redacted), true
+		}
+	case TypeErrors, TypeBytesSlice:
+		// The members are converted to strings, because the redacted
+		// values cannot be stored as errors or byte slices.
+		values := fieldTexts(field.Element)
+		redacted := false
+		for index := 0; index < len(values); index++ {
+			if value, ok := h.redactText(values[index]); ok {
+				values[index] = value
+				redacted = true
+			}
+		}
+		if redacted {
+			return Strings(field.Name, values), true
+		}
+	case TypeObject:
+		if value, ok := h.redactFields(field.Object()); ok {
+			return Object(field.Name, value...), true
+		}
+	case TypeObjects:
+		values := field.Objects()
+		var redacted ElementObjects
+		for index := 0; index < len(values); index++ {
+			value, ok := h.redactFields(values[index])
+			if !ok {
+				continue
+			}
+			if redacted == nil {
+				redacted = append(ElementObjects(nil), values...)
+			}
+			redacted[index] = value
+		}
+		if redacted != nil {
+			return Objects(field.Name, redacted...), true
+		}
+	}
+	return field, false
+}
+
+// redactText replaces the parts of the given text that match any pattern.
+// If no part matches, it returns false.
+func (h *RedactionHook) redactText(text string) (string, bool) {
+	redacted := false
+	for index := 0; index < len(h.patterns); index++ {
+		if !h.patterns[index].MatchString(text) {
+			continue
+		}
+		text = h.patterns[index].ReplaceAllStringFunc(text, h.replace)
+		redacted = true
+	}
+	return text, redacted
+}
+
+// replace returns the replacement of the given sensitive value. If hashing
+// is enabled, the replacement is the SHA-256 digest of the value, which
+// allows the same values to be correlated without revealing them.
+func (h *RedactionHook) replace(value string) string {
+	if !h.hash {
+		return h.mask
+	}
+	digest := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(digest[ : ])
+}
+
+// Redaction patterns for commonly sensitive values. They can be used as
+// the values of the option Patterns of the RedactionHookOption structure.
+const (
+	// RedactEmail matches email addresses.
+	RedactEmail = `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`
+
+	// RedactBearerToken matches bearer tokens of authorization headers.
+	RedactBearerToken = `(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`
+
+	// RedactCreditCard matches credit card numbers of 13 to 19 digits,
+	// optionally separated by spaces or hyphens.
+	RedactCreditCard = `\b(?:\d[ -]?){12,18}\d\b`
+)
+
+// RedactionHookOption is a structure that contains options for the
+// redaction hook.
+type RedactionHookOption struct {
+	// Names represents the names of fields whose values are redacted
+	// entirely. The fields of nested objects are also matched. If not
+	// provided, no field is redacted by name.
+	Names []string
+
+	// Patterns represents the regular expressions that match sensitive
+	// parts of string values. For details, please refer to the syntax of
+	// the regexp package. If not provided, no pattern is used.
+	Patterns []string
+
+	// Mask represents the string that replaces the sensitive values. If
+	// not provided, the default value is "[REDACTED]".
+	Mask string
+
+	// Hash represents whether to replace the sensitive values with their
+	// SHA-256 digests instead of the mask. If not provided, the default
+	// value is false.
+	Hash bool
+}
+
+// UseNames appends the given one or more field names to the option Names.
+// Then return to the option instance itself.
+func (o *RedactionHookOption) UseNames(names ...string) *RedactionHookOption {
+	o.Names = append(o.Names, names...)
+	return o
+}
+
+// UsePatterns appends the given one or more regular expressions to the
+// option Patterns. Then return to the option instance itself.
+func (o *RedactionHookOption) UsePatterns(patterns ...string) *RedactionHookOption {
+	o.Patterns = append(o.Patterns, patterns...)
+	return o
+}
+
+// UseMask uses the given mask as the value of the option Mask. Then return
+// to the option instance itself.
+func (o *RedactionHookOption) UseMask(mask string) *RedactionHookOption {
+	o.Mask = mask
+	return o
+}
+
+// UseHash enables the option Hash. Then return to the option instance
+// itself.
+func (o *RedactionHookOption) UseHash() *RedactionHookOption {
+	o.Hash = true
+	return o
+}
+
+// Build builds and returns a redaction hook instance. If any pattern is
+// not a valid regular expression, the error of compiling it is returned.
+func (o *RedactionHookOption) Build() (*RedactionHook, error) {
+	instance := &RedactionHook {
+		names: make(map[string]struct { }, len(o.Names)),
+		patterns: make([]*regexp.Regexp, 0, len(o.Patterns)),
+		mask: o.Mask,
+		hash: o.Hash,
+	}
+	for index := 0; index < len(o.Names); index++ {
+		instance.names[o.Names[index]] = struct { } { }
+	}
+	for index := 0; index < len(o.Patterns); index++ {
+		pattern, err := regexp.Compile(o.Patterns[index])
+		if err != nil {
+			return nil, err
+		}
+		instance.patterns = append(instance.patterns, pattern)
+	}
+	return instance, nil
+}
+
+// NewRedactionHookOption creates and returns an optional instance of the
+// redaction hook using the default optional values.
+func NewRedactionHookOption() *RedactionHookOption {
+	return &RedactionHookOption {
+		Mask: "[REDACTED]",
+	}
+}
+
+// fieldTexts returns the texts of the members of the given error slice or
+// byte slices element. The texts of nil members are empty strings.
+func fieldTexts(element Element) []string {
+	var texts []string
+	if element.Type == TypeErrors {
+		for _, value := range element.Errors() {
+			if isNil(value) {
+				texts = append(texts, "")
+			} else {
+				texts = append(texts, value.Error())
+			}
+		}
+		return texts
+	}
+	for _, value := range element.BytesSlice() {
+		texts = append(texts, string(value))
+	}
+	return texts
+}
@@ -0,0 +1,133 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactionHookOption(t *testing.T) {
+	option := NewRedactionHookOption()
+	assert.Equal(t, "[REDACTED]", option.Mask, "Unexpected option value")
+
+	option.UseNames("password").UsePatterns(RedactEmail).UseMask("***").
+		UseHash()
+	assert.Equal(t, []string { "password" }, option.Names,
+		"Unexpected option value")
+	assert.Equal(t, []string { RedactEmail }, option.Patterns,
+		"Unexpected option value")
+	assert.Equal(t, "***", option.Mask, "Unexpected option value")
+	assert.True(t, option.Hash, "Unexpected option value")
+
+	_, err := NewRedactionHookOption().UsePatterns("(").Build()
+	assert.Error(t, err, "Unexpected build error")
+}
+
+func TestRedactionHook(t *testing.T) {
+	hook, err := NewRedactionHookOption().UseNames("password", "token").
+		UsePatterns(RedactEmail, RedactCreditCard).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	fields := []Field {
+		String("name", "test"),
+		String("password", "secret"),
+		String("contact", "mail me at santa@example.com"),
+		Object("payment", String("card", "4111 1111 1111 1111"),
+			Int("token", 12345)),
+	}
+	message := pool.Message.Structure.New("Hello santa@example.com!",
+		fields)
+
+	entry := &Entry { Message: message }
+	assert.NoError(t, hook.Print(entry), "Unexpected print error")
+
+	assert.JSONEq(t, `{
+		"text": "Hello [REDACTED]!",
+		"payload": {
+			"name": "test",
+			"password": "[REDACTED]",
+			"contact": "mail me at [REDACTED]",
+			"payment": {
+				"card": "[REDACTED]",
+				"token": "[REDACTED]"
+			}
+		}
+	}`, string(entry.Message.(JSONSerializer).SerializeJSON(nil)),
+		"Unexpected redaction result")
+
+	// The fields given by the application must not be modified.
+	assert.Equal(t, "secret", fields[1].String, "Unexpected field value")
+	assert.Equal(t, "Hello santa@example.com!", message.Text,
+		"Unexpected message value")
+	pool.Message.Structure.Free(message)
+
+	entry = &Entry { Message: StringMessage("Hello Test!") }
+	assert.NoError(t, hook.Print(entry), "Unexpected print error")
+	assert.Equal(t, StringMessage("Hello Test!"), entry.Message,
+		"Unexpected redaction result")
+
+	entry = &Entry { Message: &TemplateMessage {
+		Template: "Hello %s!",
+		Args: []interface { } { "santa@example.com" },
+	} }
+	assert.NoError(t, hook.Print(entry), "Unexpected print error")
+	assert.Equal(t, StringMessage("Hello [REDACTED]!"), entry.Message,
+		"Unexpected redaction result")
+}
+
+func TestRedactionHookSlices(t *testing.T) {
+	hook, err := NewRedactionHookOption().UsePatterns(RedactEmail).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	entry := &Entry { Message: StructMessage { Fields: ElementObject {
+		Errors("errors", []error { errors.New("no user santa@example.com"),
+			nil }),
+		BytesSlice("bytes", [][]byte { []byte("santa@example.com") }),
+		BytesSlice("plain", [][]byte { []byte("Hello") }),
+	} } }
+	assert.NoError(t, hook.Print(entry), "Unexpected print error")
+	assert.Equal(t, `{"errors": ["no user [REDACTED]", ""], `+
+		`"bytes": ["[REDACTED]"], "plain": ["Hello"]}`,
+		string(entry.Fields.SerializeJSON(nil)), "Unexpected redaction result")
+	assert.Equal(t, TypeBytesSlice, entry.Fields[2].Type,
+		"Unexpected redaction result")
+}
+
+func TestRedactionHookHash(t *testing.T) {
+	hook, err := NewRedactionHookOption().UseNames("password").UseHash().
+		Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	entry := &Entry { Message: StructMessage {
+		Text: "Hello Test!",
+		Fields: ElementObject { String("password", "secret") },
+	} }
+	assert.NoError(t, hook.Print(entry), "Unexpected print error")
+
+	assert.Equal(t, "sha256:2bb80d537b1da3e38bd30361aa855686bde0eacd7162" +
+		"fef6a25fe97bf527a25b", entry.Message.(StructMessage).Fields[0].
+		String, "Unexpected redaction result")
+}
@@ -0,0 +1,105 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build !tinygo
+// +build !tinygo
+
+package santa
+
+import (
+	"encoding/json"
+)
+
+// ReflectErrorPrefix is the prefix of the string value serialized for a
+// reflected field whose value cannot be serialized, followed by the error
+// returned by the encoding/json package.
+const ReflectErrorPrefix = "!reflect: "
+
+// reflectValue is the structure of the value of a reflected field, which
+// serializes the wrapped value using the encoding/json package.
+type reflectValue struct {
+	value interface { }
+}
+
+// reflectWriter is a writer that appends the written data to a buffer
+// slice, so that the JSON encoder writes directly into the buffer slice.
+type reflectWriter struct {
+	buffer []byte
+}
+
+// Write appends the given data to the buffer slice.
+func (w *reflectWriter) Write(data []byte) (int, error) {
+	w.buffer = append(w.buffer, data...)
+	return len(data), nil
+}
+
+// appendReflectJSON serializes the given value into a JSON value string
+// using the encoding/json package and appends it to the given buffer slice,
+// and then returns the appended buffer slice and any errors encountered.
+// If an error is returned, the given buffer slice is returned as is.
+func appendReflectJSON(buffer []byte, value interface { }) ([]byte, error) {
+	writer := &reflectWriter { buffer: buffer }
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return buffer, err
+	}
+	// The JSON encoder terminates each value with a newline.
+	return writer.buffer[ : len(writer.buffer) - 1], nil
+}
+
+// SerializeJSON serializes the wrapped value into a JSON value string and
+// appends it to the given buffer slice, and then returns the appended
+// buffer slice. If the value cannot be serialized, for example because it
+// contains a cycle, a JSON string containing the error is appended.
+func (v reflectValue) SerializeJSON(buffer []byte) []byte {
+	result, err := appendReflectJSON(buffer, v.value)
+	if err != nil {
+		data, _ := json.Marshal(ReflectErrorPrefix + err.Error())
+		return append(buffer, data...)
+	}
+	return result
+}
+
+// SerializeStandard serializes the wrapped value in the same way as the
+// SerializeJSON function.
+func (v reflectValue) SerializeStandard(buffer []byte) []byte {
+	return v.SerializeJSON(buffer)
+}
+
+// Reflect returns the value of a field with a given name and a given
+// value of any type, such as a structure or a map. The value is serialized
+// using the encoding/json package (including its struct tags and cycle
+// detection) each time the field is serialized, which is much slower than
+// the other field types, so it should only be used when performance is not
+// critical. If the value cannot be serialized, the value of the field is a
+// string beginning with ReflectErrorPrefix. Please refer to the comments
+// section of the Field structure for details.
+func Reflect(name string, value interface { }) Field {
+	return Field {
+		Element: Element {
+			Type: TypeValue,
+			Interface: reflectValue { value: value },
+		},
+		Name: name,
+	}
+}
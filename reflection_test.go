@@ -0,0 +1,87 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testReflectUser struct {
+	Name string `json:"name"`
+	Tags []string `json:"tags,omitempty"`
+	Next *testReflectUser `json:"next,omitempty"`
+	secret string
+}
+
+func TestReflect(t *testing.T) {
+	field := Reflect("user", &testReflectUser {
+		Name: "<test>",
+		Tags: []string { "a", "b" },
+		secret: "secret",
+	})
+	assert.Equal(t, TypeValue, field.Type, "Unexpected field type")
+	assert.Equal(t, `{"name":"<test>","tags":["a","b"]}`,
+		string(field.SerializeJSON(nil)), "Unexpected serialize result")
+	assert.Equal(t, `{"name":"<test>","tags":["a","b"]}`,
+		string(field.SerializeStandard(nil)), "Unexpected serialize result")
+
+	field = Reflect("map", map[string]int { "b": 2, "a": 1 })
+	assert.Equal(t, `prefix{"a":1,"b":2}`,
+		string(field.SerializeJSON([]byte("prefix"))),
+		"Unexpected serialize result")
+
+	field = Reflect("nil", nil)
+	assert.Equal(t, "null", string(field.SerializeJSON(nil)),
+		"Unexpected serialize result")
+
+	message := StructMessage {
+		Text: "Hello Test!",
+		Fields: ElementObject { Reflect("values", []int { 1, 2 }) },
+	}
+	assert.True(t, json.Valid(message.SerializeJSON(nil)),
+		"Unexpected serialize result")
+}
+
+func TestReflectCycle(t *testing.T) {
+	user := &testReflectUser { Name: "test" }
+	user.Next = user
+
+	buffer := Reflect("user", user).SerializeJSON([]byte("prefix"))
+	assert.True(t, strings.HasPrefix(string(buffer), `prefix"`+
+		ReflectErrorPrefix), "Unexpected serialize result")
+	assert.True(t, json.Valid(buffer[len("prefix") : ]),
+		"Unexpected serialize result")
+
+	values := map[string]interface { } { }
+	values["self"] = values
+	buffer = Reflect("values", values).SerializeJSON(nil)
+	assert.Contains(t, string(buffer), "cycle", "Unexpected serialize result")
+
+	buffer = Reflect("channel", make(chan int)).SerializeJSON(nil)
+	assert.Contains(t, string(buffer), ReflectErrorPrefix,
+		"Unexpected serialize result")
+}
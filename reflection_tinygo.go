@@ -0,0 +1,99 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build tinygo
+// +build tinygo
+
+package santa
+
+import "fmt"
+
+// ReflectErrorPrefix is the prefix of the string value serialized for a
+// reflected field whose value cannot be serialized. It is kept for API
+// compatibility, because the TinyGo implementation cannot fail.
+const ReflectErrorPrefix = "!reflect: "
+
+// reflectValue is the structure of the value of a reflected field, which
+// serializes the wrapped value as a string using the fmt package, because
+// the encoding/json package is too heavy for TinyGo.
+type reflectValue struct {
+	value interface { }
+}
+
+// appendReflectJSON formats the given value with the "%+v" verb of the fmt
+// package and appends it as an escaped JSON string to the given buffer
+// slice, and then returns the appended buffer slice and a nil error.
+func appendReflectJSON(buffer []byte, value interface { }) ([]byte, error) {
+	text := fmt.Sprintf("%+v", value)
+	buffer = append(buffer, '"')
+	for index := 0; index < len(text); index++ {
+		switch char := text[index]; {
+		case char == '"' || char == '\\':
+			buffer = append(buffer, '\\', char)
+		case char == '\n':
+			buffer = append(buffer, '\\', 'n')
+		case char == '\r':
+			buffer = append(buffer, '\\', 'r')
+		case char == '\t':
+			buffer = append(buffer, '\\', 't')
+		case char < 0x20:
+			buffer = append(buffer, '\\', 'u', '0', '0',
+				hexDigits[char >> 4], hexDigits[char & 0xF])
+		default:
+			// The bytes of multi-byte characters are appended as is.
+			buffer = append(buffer, char)
+		}
+	}
+	return append(buffer, '"'), nil
+}
+
+// hexDigits contains the lowercase hexadecimal digits.
+const hexDigits = "0123456789abcdef"
+
+// SerializeJSON serializes the wrapped value into a JSON string and
+// appends it to the given buffer slice, and then returns the appended
+// buffer slice.
+func (v reflectValue) SerializeJSON(buffer []byte) []byte {
+	buffer, _ = appendReflectJSON(buffer, v.value)
+	return buffer
+}
+
+// SerializeStandard serializes the wrapped value in the same way as the
+// SerializeJSON function.
+func (v reflectValue) SerializeStandard(buffer []byte) []byte {
+	return v.SerializeJSON(buffer)
+}
+
+// Reflect returns the value of a field with a given name and a given
+// value of any type, such as a structure or a map. Under TinyGo, the value
+// is serialized as a string formatted with the "%+v" verb of the fmt
+// package, instead of using the encoding/json package. Please refer to the
+// comments section of the Field structure for details.
+func Reflect(name string, value interface { }) Field {
+	return Field {
+		Element: Element {
+			Type: TypeValue,
+			Interface: reflectValue { value: value },
+		},
+		Name: name,
+	}
+}
@@ -0,0 +1,856 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// RollingDaily represents that the rolling synchronizer rolls to a new
+	// file at midnight.
+	RollingDaily RollingPeriod = iota
+
+	// RollingHourly represents that the rolling synchronizer rolls to a
+	// new file at the beginning of each hour.
+	RollingHourly
+)
+
+var (
+	// ErrInvalidRolling represents that the given rolling synchronizer
+	// option is invalid, usually because the file name pattern does not
+	// contain any date pattern or contains an unknown one, the rolling
+	// period is invalid, or the disk usage quota is invalid.
+	ErrInvalidRolling = errors.New("invalid rolling option")
+
+	// ErrArchiveMismatch represents that the SHA-256 checksum of a rolled
+	// archive does not match its manifest.
+	ErrArchiveMismatch = errors.New("archive checksum mismatch")
+)
+
+const (
+	// rollingArchiveSuffix is the suffix of the names of compressed files
+	// of previous periods.
+	rollingArchiveSuffix = ".gz"
+
+	// rollingManifestSuffix is the suffix of the names of the manifests of
+	// compressed files, which is appended to the name of the archive.
+	rollingManifestSuffix = ".manifest"
+)
+
+// RollingPeriod is a data type that represents the period after which the
+// rolling synchronizer rolls to a new file.
+type RollingPeriod uint8
+
+// next returns the time of the first boundary of the period after the
+// given time, in the location of the given time.
+func (p RollingPeriod) next(now time.Time) time.Time {
+	year, month, day := now.Date()
+	if p == RollingHourly {
+		return time.Date(year, month, day, now.Hour() + 1, 0, 0, 0,
+			now.Location())
+	}
+	return time.Date(year, month, day + 1, 0, 0, 0, 0, now.Location())
+}
+
+// parseRollingPattern checks whether the given file name pattern is valid,
+// and returns ErrInvalidRolling if it does not contain any date pattern or
+// contains an unknown one.
+func parseRollingPattern(pattern string) error {
+	patterns := 0
+	for index := 0; index < len(pattern); index++ {
+		if pattern[index] != '%' {
+			continue
+		}
+		if index++; index == len(pattern) {
+			return ErrInvalidRolling
+		}
+		switch pattern[index] {
+		case 'Y', 'm', 'd', 'H':
+			patterns++
+		case '%':
+		default:
+			return ErrInvalidRolling
+		}
+	}
+	if patterns == 0 {
+		return ErrInvalidRolling
+	}
+	return nil
+}
+
+// appendRollingName appends the file name generated from the given file
+// name pattern and time to the given buffer slice, and then returns the
+// appended buffer slice. The pattern must have been checked by the
+// parseRollingPattern function.
+func appendRollingName(buffer []byte, pattern string, now time.Time) []byte {
+	appendNumber := func(buffer []byte, number, width int) []byte {
+		for limit := 10; width > 1; width-- {
+			if number < limit {
+				buffer = append(buffer, '0')
+			}
+			limit *= 10
+		}
+		return strconv.AppendInt(buffer, int64(number), 10)
+	}
+	for index := 0; index < len(pattern); index++ {
+		if pattern[index] != '%' {
+			buffer = append(buffer, pattern[index])
+			continue
+		}
+		index++
+		switch pattern[index] {
+		case 'Y':
+			buffer = appendNumber(buffer, now.Year(), 4)
+		case 'm':
+			buffer = appendNumber(buffer, int(now.Month()), 2)
+		case 'd':
+			buffer = appendNumber(buffer, now.Day(), 2)
+		case 'H':
+			buffer = appendNumber(buffer, now.Hour(), 2)
+		case '%':
+			buffer = append(buffer, '%')
+		}
+	}
+	return buffer
+}
+
+// appendRollingGlob appends the glob pattern that matches the file names
+// generated from the given file name pattern to the given buffer slice,
+// and then returns the appended buffer slice. The pattern must have been
+// checked by the parseRollingPattern function.
+func appendRollingGlob(buffer []byte, pattern string) []byte {
+	for index := 0; index < len(pattern); index++ {
+		char := pattern[index]
+		if char == '%' {
+			index++
+			if pattern[index] != '%' {
+				buffer = append(buffer, '*')
+				continue
+			}
+		}
+		switch char {
+		case '*', '?', '[', ']', '\\':
+			if runtime.GOOS == "windows" {
+				// The backslash is the path separator on Windows, so
+				// metacharacters are escaped with character classes.
+				buffer = append(buffer, '[', char, ']')
+				continue
+			}
+			buffer = append(buffer, '\\')
+		}
+		buffer = append(buffer, char)
+	}
+	return buffer
+}
+
+// RollingManifest is the structure of the manifest of a compressed file of
+// a previous period of the rolling synchronizer. The manifest is written
+// in JSON format next to the archive, and its name is the name of the
+// archive with the ".manifest" suffix.
+//
+// External collectors can use the times in the manifest to retrieve the
+// archives of a time range without decompressing them, and verify the
+// integrity of archives using the VerifyRollingArchive function.
+type RollingManifest struct {
+	// File represents the base name of the archive.
+	File string `json:"file"`
+
+	// First represents the time of the first write to the file.
+	First time.Time `json:"first"`
+
+	// Last represents the time of the last write to the file.
+	Last time.Time `json:"last"`
+
+	// Size represents the number of bytes of the archive.
+	Size int64 `json:"size"`
+
+	// SHA256 represents the SHA-256 checksum of the archive in
+	// hexadecimal format.
+	SHA256 string `json:"sha256"`
+}
+
+// VerifyRollingArchive reads the manifest with the given name and checks
+// whether the SHA-256 checksum of the archive in the same directory
+// matches it. The manifest is returned, and ErrArchiveMismatch is returned
+// if the checksum does not match.
+func VerifyRollingArchive(manifestName string) (*RollingManifest, error) {
+	data, err := ioutil.ReadFile(manifestName)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &RollingManifest { }
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filepath.Join(filepath.Dir(manifestName),
+		manifest.File))
+	if err != nil {
+		return manifest, err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return manifest, err
+	}
+	if size != manifest.Size ||
+		hex.EncodeToString(hash.Sum(nil)) != manifest.SHA256 {
+		return manifest, ErrArchiveMismatch
+	}
+	return manifest, nil
+}
+
+// compressRollingFile compresses the file with the given name into an
+// archive with the ".gz" suffix, writes the manifest of the archive, and
+// then deletes the file. The archive and the manifest are written to
+// temporary files first and renamed, so that they are never observed
+// partially written.
+func compressRollingFile(name string, first, last time.Time,
+	mode os.FileMode) error {
+	source, err := os.Open(name)
+	if os.IsNotExist(err) {
+		// The file has been deleted to enforce the disk usage quota.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	archiveName := name + rollingArchiveSuffix
+	temporary, err := os.OpenFile(archiveName + ".tmp",
+		os.O_WRONLY | os.O_CREATE | os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	counter := &countWriter { writer: io.MultiWriter(temporary, hash) }
+	compressor := gzip.NewWriter(counter)
+	_, err = io.Copy(compressor, source)
+	if err == nil {
+		err = compressor.Close()
+	}
+	if err == nil {
+		err = temporary.Sync()
+	}
+	if closeErr := temporary.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temporary.Name(), archiveName)
+	}
+	if err != nil {
+		_ = os.Remove(temporary.Name())
+		return err
+	}
+
+	data, err := json.Marshal(&RollingManifest {
+		File: filepath.Base(archiveName),
+		First: first,
+		Last: last,
+		Size: counter.count,
+		SHA256: hex.EncodeToString(hash.Sum(nil)),
+	})
+	if err != nil {
+		return err
+	}
+	manifestName := archiveName + rollingManifestSuffix
+	err = ioutil.WriteFile(manifestName + ".tmp", append(data, '\n'), mode)
+	if err == nil {
+		err = os.Rename(manifestName + ".tmp", manifestName)
+	}
+	if err != nil {
+		_ = os.Remove(manifestName + ".tmp")
+		return err
+	}
+	return os.Remove(name)
+}
+
+// countWriter is the structure of a writer that counts the number of
+// bytes written to the wrapped writer.
+type countWriter struct {
+	writer io.Writer
+	count int64
+}
+
+// Write writes the given data to the wrapped writer and counts the number
+// of bytes written.
+func (w *countWriter) Write(buffer []byte) (int, error) {
+	size, err := w.writer.Write(buffer)
+	w.count += int64(size)
+	return size, err
+}
+
+// rollingArchive is the structure of a file of a previous period of the
+// rolling synchronizer.
+type rollingArchive struct {
+	name string
+	size int64
+	modified time.Time
+}
+
+// rollingWriter is the structure of the storage device of the rolling
+// synchronizer. It writes to the file of the current period, and rolls to
+// the file of the next period when it is written to after the boundary of
+// the current period.
+type rollingWriter struct {
+	pattern string
+	period RollingPeriod
+	clock Clock
+	mode os.FileMode
+	directoryMode os.FileMode
+	maxDiskUsage int64
+	diskUsageMargin int64
+	deleteHandler func(name string, size int64)
+	compress bool
+	compressWaitGroup sync.WaitGroup
+	compressMutex sync.Mutex
+	compressing map[string]struct { }
+	header Banner
+	footer Banner
+
+	mutex sync.Mutex
+	file *os.File
+	name string
+	next time.Time
+	first time.Time
+	last time.Time
+}
+
+// open opens the file of the period of the given time for appending,
+// creating the missing parent directories if required, writes the header
+// (if any) to it, and then writes the footer (if any) to the previous file
+// and closes it. If the file cannot be opened, the previous file is kept.
+//
+// Please note that the caller must hold the mutex.
+func (w *rollingWriter) open(now time.Time) error {
+	name := string(appendRollingName(nil, w.pattern, now))
+	w.next = w.period.next(now)
+	if w.file != nil && name == w.name {
+		return nil
+	}
+	if w.directoryMode != 0 {
+		err := os.MkdirAll(filepath.Dir(name), w.directoryMode)
+		if err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(name, os.O_WRONLY | os.O_CREATE | os.O_APPEND,
+		w.mode)
+	if err != nil {
+		return err
+	}
+	if w.header != nil {
+		if _, err := file.Write(w.header(nil)); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+	previous, previousName := w.file, w.name
+	first, last := w.first, w.last
+	w.file, w.name = file, name
+	w.first, w.last = time.Time { }, time.Time { }
+	// The previous file is closed and registered for compression before
+	// the quota is enforced, so that it is not deleted while it is still
+	// written or compressed.
+	var result error
+	if previous != nil {
+		if w.footer != nil {
+			if _, err := previous.Write(w.footer(nil)); err != nil {
+				diagnose("writing footer to %s failed: %v", previousName,
+					err)
+			}
+		}
+		result = previous.Close()
+		if result == nil && w.compress {
+			w.compressMutex.Lock()
+			if w.compressing == nil {
+				w.compressing = make(map[string]struct { })
+			}
+			w.compressing[previousName] = struct { } { }
+			w.compressMutex.Unlock()
+			w.compressWaitGroup.Add(1)
+			go w.compressHandler(previousName, first, last)
+		}
+	}
+	if w.maxDiskUsage > 0 {
+		w.enforceQuota()
+	}
+	return result
+}
+
+// compressHandler compresses the file of a previous period with the given
+// name, and then enforces the disk usage quota (if any) again, because the
+// file was excluded from the quota while it was compressed. It is called
+// in an independent coroutine context.
+func (w *rollingWriter) compressHandler(name string, first, last time.Time) {
+	defer w.compressWaitGroup.Done()
+	if err := compressRollingFile(name, first, last, w.mode); err != nil {
+		diagnose("compressing %s failed: %v", name, err)
+	}
+	w.compressMutex.Lock()
+	delete(w.compressing, name)
+	w.compressMutex.Unlock()
+	if w.maxDiskUsage > 0 {
+		w.mutex.Lock()
+		w.enforceQuota()
+		w.mutex.Unlock()
+	}
+}
+
+// isCompressing checks whether the file with the given name, or the file
+// from which the archive with the given name is compressed, is still being
+// compressed.
+func (w *rollingWriter) isCompressing(name string) bool {
+	w.compressMutex.Lock()
+	defer w.compressMutex.Unlock()
+	_, ok := w.compressing[strings.TrimSuffix(name, rollingArchiveSuffix)]
+	return ok
+}
+
+// enforceQuota deletes the oldest files of previous periods while the
+// total size of the files matching the file name pattern exceeds the disk
+// usage quota, until the total size is not greater than the quota minus
+// the safety margin. The file of the current period and the files that are
+// still being compressed (and their archives) are never deleted.
+//
+// Please note that the caller must hold the mutex.
+func (w *rollingWriter) enforceQuota() {
+	glob := string(appendRollingGlob(nil, w.pattern))
+	names, err := filepath.Glob(glob)
+	if err != nil {
+		diagnose("listing files of %s failed: %v", w.pattern, err)
+		return
+	}
+	archiveNames, _ := filepath.Glob(glob + rollingArchiveSuffix)
+	names = append(names, archiveNames...)
+	var usage int64
+	archives := make([]rollingArchive, 0, len(names))
+	for _, name := range names {
+		info, err := os.Stat(name)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		usage += info.Size()
+		if name != w.name && !w.isCompressing(name) {
+			archives = append(archives, rollingArchive {
+				name: name,
+				size: info.Size(),
+				modified: info.ModTime(),
+			})
+		}
+	}
+	if usage <= w.maxDiskUsage {
+		return
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		if archives[i].modified.Equal(archives[j].modified) {
+			return archives[i].name < archives[j].name
+		}
+		return archives[i].modified.Before(archives[j].modified)
+	})
+	target := w.maxDiskUsage - w.diskUsageMargin
+	for _, archive := range archives {
+		if usage <= target {
+			break
+		}
+		if err := os.Remove(archive.name); err != nil {
+			diagnose("deleting %s failed: %v", archive.name, err)
+			continue
+		}
+		usage -= archive.size
+		if strings.HasSuffix(archive.name, rollingArchiveSuffix) {
+			err := os.Remove(archive.name + rollingManifestSuffix)
+			if err != nil && !os.IsNotExist(err) {
+				diagnose("deleting %s failed: %v", archive.name +
+					rollingManifestSuffix, err)
+			}
+		}
+		if w.deleteHandler != nil {
+			w.deleteHandler(archive.name, archive.size)
+		}
+	}
+}
+
+// Write writes the given data to the file of the current period, rolling
+// to a new file first if the boundary of the current period has passed.
+func (w *rollingWriter) Write(buffer []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	now := w.clock.Now()
+	if !now.Before(w.next) {
+		if err := w.open(now); err != nil {
+			diagnose("rolling to a new file failed: %v", err)
+		}
+	}
+	if w.first.IsZero() {
+		w.first = now
+	}
+	w.last = now
+	return w.file.Write(buffer)
+}
+
+// Sync synchronizes the file of the current period to the persistent
+// storage device.
+func (w *rollingWriter) Sync() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.file.Sync()
+}
+
+// close writes the footer (if any) to the file of the current period and
+// closes it, and then waits for the files of previous periods to be
+// compressed.
+func (w *rollingWriter) close() error {
+	w.mutex.Lock()
+	var err error
+	if w.footer != nil {
+		_, err = w.file.Write(w.footer(nil))
+	}
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	w.mutex.Unlock()
+	w.compressWaitGroup.Wait()
+	return err
+}
+
+// RollingSyncer is the structure of the rolling synchronizer instance.
+//
+// The rolling synchronizer is based on the standard synchronizer and
+// writes to files whose names are generated from a file name pattern with
+// date patterns, such as "app-%Y-%m-%d.log", which is commonly required by
+// operations tooling. The following date patterns are supported:
+//
+//   %Y  The year, such as "2024".
+//   %m  The month, from "01" to "12".
+//   %d  The day of the month, from "01" to "31".
+//   %H  The hour, from "00" to "23".
+//   %%  A literal "%".
+//
+// The rolling synchronizer rolls to a new file at midnight or at the
+// beginning of each hour, depending on the rolling period, without any
+// size threshold. The time and its location are determined by the clock,
+// for example, the UTC clock rolls at midnight in UTC. The new file is
+// opened when data is written to the storage device after the boundary,
+// so data that is cached before the boundary and flushed after it is
+// written to the new file.
+//
+// The rolling synchronizer can optionally enforce a disk usage quota for
+// the files matching the file name pattern. Each time a new file is
+// opened, if the total size of these files exceeds the quota, the oldest
+// files of previous periods are deleted until the total size is not
+// greater than the quota minus the safety margin, and the delete handler
+// is notified of each deleted file. Since the quota is only enforced when
+// rolling, the file of the current period can grow beyond it.
+//
+// The rolling synchronizer can optionally compress the files of previous
+// periods in the background using gzip. The archive of a file has the
+// ".gz" suffix, and its manifest contains the times of the first and last
+// writes to the file and the SHA-256 checksum of the archive. For details,
+// please refer to the comment section of the RollingManifest structure.
+// Archives and their manifests are also subject to the disk usage quota.
+//
+// The header and footer of the rolling synchronizer are written to each
+// file, when the file is opened and when the synchronizer rolls away from
+// it or is closed.
+//
+// Please note that if the mutex is disabled, the API provided by
+// the synchronizer is not thread-safe.
+type RollingSyncer struct {
+	*StandardSyncer
+	writer *rollingWriter
+}
+
+// Name returns the name of the file of the current period.
+func (s *RollingSyncer) Name() string {
+	s.writer.mutex.Lock()
+	defer s.writer.mutex.Unlock()
+	return s.writer.name
+}
+
+// Close automatically flushes the internal cache once, closes the file of
+// the current period, and then waits for the files of previous periods to
+// be compressed.
+//
+// Finally, any errors encountered are returned. If the synchronizer has
+// already been closed, ErrClosed is returned.
+func (s *RollingSyncer) Close() error {
+	if !s.markClosed() {
+		return ErrClosed
+	}
+	s.close()
+	return s.writer.close()
+}
+
+// RollingSyncerOption is a structure containing rolling synchronizer
+// options.
+type RollingSyncerOption struct {
+	SyncerOption
+
+	// FileName represents the file name pattern from which the names of
+	// the files are generated. For details, please refer to the comment
+	// section of the RollingSyncer structure. This option is required.
+	FileName string
+
+	// Period represents the period after which the synchronizer rolls to
+	// a new file. The optional values are defined by the constants at the
+	// beginning of Rolling... If not provided, the default value is the
+	// RollingDaily constant.
+	Period RollingPeriod
+
+	// FileMode represents the permission bits used when a file does not
+	// exist and is created. If not provided, the default value is 0644.
+	FileMode os.FileMode
+
+	// MakeDirectories represents whether to create the missing parent
+	// directories of the files. If not provided, the default value is
+	// false.
+	MakeDirectories bool
+
+	// DirMode represents the permission bits used when parent directories
+	// are created. It only takes effect if the MakeDirectories option is
+	// enabled. If not provided, the default value is 0755.
+	DirMode os.FileMode
+
+	// Clock represents the clock used to determine the period. If not
+	// provided, the default value is the system clock.
+	Clock Clock
+
+	// MaxDiskUsage represents the maximum number of bytes of all files
+	// matching the file name pattern. For details, please refer to the
+	// comment section of the RollingSyncer structure. If not provided or
+	// the value is 0, the disk usage is not limited.
+	MaxDiskUsage int64
+
+	// DiskUsageMargin represents the number of bytes below the disk usage
+	// quota to which the total size of the files is reduced when the quota
+	// is exceeded, which avoids deleting a file each time a new file is
+	// opened. It must be less than the quota. If not provided, the default
+	// value is 0.
+	DiskUsageMargin int64
+
+	// DeleteHandler represents the function that is called with the name
+	// and size of each file deleted to enforce the disk usage quota. If
+	// not provided, deletions are not notified.
+	DeleteHandler func(name string, size int64)
+
+	// Compress represents whether to compress the files of previous
+	// periods and write their manifests. For details, please refer to the
+	// comment section of the RollingSyncer structure. If not provided, the
+	// default value is false.
+	Compress bool
+}
+
+// UseCacheCapacity uses the given capacity as the value of the option
+// CacheCapacity. For details, please refer to the comment section of
+// the CacheCapacity option. Then return to the option instance itself.
+func (o *RollingSyncerOption) UseCacheCapacity(capacity int) *RollingSyncerOption {
+	o.CacheCapacity = capacity
+	return o
+}
+
+// UseFlushTriggers uses the given number of bytes and maximum age as the
+// values of the options FlushBytes and FlushAge. For details, please refer
+// to the comment section of these options. Then return to the option
+// instance itself.
+func (o *RollingSyncerOption) UseFlushTriggers(size int, age time.Duration) *RollingSyncerOption {
+	o.FlushBytes = size
+	o.FlushAge = age
+	return o
+}
+
+// UseBanners uses the given banners as the values of the options Header
+// and Footer. For details, please refer to the comment section of these
+// options. Then return to the option instance itself.
+func (o *RollingSyncerOption) UseBanners(header, footer Banner) *RollingSyncerOption {
+	o.Header = header
+	o.Footer = footer
+	return o
+}
+
+// UseName uses the given file name pattern as the value of the option
+// FileName. For details, please refer to the comment section of the
+// FileName option. Then return to the option instance itself.
+func (o *RollingSyncerOption) UseName(pattern string) *RollingSyncerOption {
+	o.FileName = pattern
+	return o
+}
+
+// UsePeriod uses the given rolling period as the value of the option
+// Period. For details, please refer to the comment section of the Period
+// option. Then return to the option instance itself.
+func (o *RollingSyncerOption) UsePeriod(period RollingPeriod) *RollingSyncerOption {
+	o.Period = period
+	return o
+}
+
+// UseFileMode uses the given permission bits as the value of the option
+// FileMode. For details, please refer to the comment section of the
+// FileMode option. Then return to the option instance itself.
+func (o *RollingSyncerOption) UseFileMode(mode os.FileMode) *RollingSyncerOption {
+	o.FileMode = mode
+	return o
+}
+
+// UseMakeDirectories enables the option MakeDirectories and uses the given
+// permission bits as the value of the option DirMode. For details, please
+// refer to the comment section of these options. Then return to the option
+// instance itself.
+func (o *RollingSyncerOption) UseMakeDirectories(mode os.FileMode) *RollingSyncerOption {
+	o.MakeDirectories = true
+	o.DirMode = mode
+	return o
+}
+
+// UseClock uses the given clock as the value of the option Clock. For
+// details, please refer to the comment section of the Clock option. Then
+// return to the option instance itself.
+func (o *RollingSyncerOption) UseClock(clock Clock) *RollingSyncerOption {
+	o.Clock = clock
+	return o
+}
+
+// UseMaxDiskUsage uses the given numbers of bytes as the values of the
+// options MaxDiskUsage and DiskUsageMargin. For details, please refer to
+// the comment section of these options. Then return to the option instance
+// itself.
+func (o *RollingSyncerOption) UseMaxDiskUsage(quota, margin int64) *RollingSyncerOption {
+	o.MaxDiskUsage = quota
+	o.DiskUsageMargin = margin
+	return o
+}
+
+// UseDeleteHandler uses the given function as the value of the option
+// DeleteHandler. For details, please refer to the comment section of the
+// DeleteHandler option. Then return to the option instance itself.
+func (o *RollingSyncerOption) UseDeleteHandler(handler func(name string, size int64)) *RollingSyncerOption {
+	o.DeleteHandler = handler
+	return o
+}
+
+// UseCompress enables the option Compress. For details, please refer to
+// the comment section of the Compress option. Then return to the option
+// instance itself.
+func (o *RollingSyncerOption) UseCompress() *RollingSyncerOption {
+	o.Compress = true
+	return o
+}
+
+// Validate checks whether the values of the options are valid, and returns
+// an error describing the first invalid value.
+func (o *RollingSyncerOption) Validate() error {
+	if err := o.SyncerOption.Validate(); err != nil {
+		return err
+	}
+	if o.Period > RollingHourly || o.MaxDiskUsage < 0 ||
+		o.DiskUsageMargin < 0 || (o.MaxDiskUsage > 0 &&
+		o.DiskUsageMargin >= o.MaxDiskUsage) {
+		return ErrInvalidRolling
+	}
+	return parseRollingPattern(o.FileName)
+}
+
+// Clone creates and returns a copy of the option instance. Modifying the
+// copy does not affect the option instance, and vice versa.
+func (o *RollingSyncerOption) Clone() *RollingSyncerOption {
+	option := *o
+	return &option
+}
+
+// Build builds and returns an instance of the rolling synchronizer and
+// any errors encountered.
+func (o *RollingSyncerOption) Build() (*RollingSyncer, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	writer := &rollingWriter {
+		pattern: o.FileName,
+		period: o.Period,
+		clock: o.Clock,
+		mode: o.FileMode,
+		maxDiskUsage: o.MaxDiskUsage,
+		diskUsageMargin: o.DiskUsageMargin,
+		deleteHandler: o.DeleteHandler,
+		compress: o.Compress,
+		header: o.Header,
+		footer: o.Footer,
+	}
+	if writer.clock == nil {
+		writer.clock = SystemClock { }
+	}
+	if writer.mode == 0 {
+		writer.mode = 0644
+	}
+	if o.MakeDirectories {
+		writer.directoryMode = o.DirMode
+		if writer.directoryMode == 0 {
+			writer.directoryMode = 0755
+		}
+	}
+	if err := writer.open(writer.clock.Now()); err != nil {
+		return nil, err
+	}
+	option := NewStandardSyncerOption()
+	option.SyncerOption = o.SyncerOption
+	// The header and footer are written to each file by the writer.
+	option.Header, option.Footer = nil, nil
+	option.Writer = writer
+	syncer, err := option.Build()
+	if err != nil {
+		_ = writer.close()
+		return nil, err
+	}
+	return &RollingSyncer {
+		StandardSyncer: syncer,
+		writer: writer,
+	}, nil
+}
+
+// NewRollingSyncerOption creates and returns an instance of a rolling
+// synchronizer option with default optional values. The file name pattern
+// must be provided before building.
+func NewRollingSyncerOption() *RollingSyncerOption {
+	return &RollingSyncerOption {
+		SyncerOption: NewSyncerOption(),
+		Period: RollingDaily,
+		FileMode: 0644,
+		DirMode: 0755,
+		Clock: SystemClock { },
+	}
+}
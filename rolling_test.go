@@ -0,0 +1,309 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollingSyncerOption(t *testing.T) {
+	option := NewRollingSyncerOption()
+	assert.Equal(t, RollingDaily, option.Period, "Unexpected option value")
+
+	for _, pattern := range []string { "", "app.log", "app-%Q.log",
+		"app-%", "app-%%.log" } {
+		_, err := option.UseName(pattern).Build()
+		assert.Equal(t, ErrInvalidRolling, err, "Unexpected build error")
+	}
+
+	_, err := option.UseName("app-%Y.log").UsePeriod(RollingHourly + 1).
+		Build()
+	assert.Equal(t, ErrInvalidRolling, err, "Unexpected build error")
+}
+
+func TestAppendRollingName(t *testing.T) {
+	now := time.Date(2024, time.May, 1, 7, 30, 0, 0, time.UTC)
+	name := appendRollingName(nil, "app-%Y-%m-%d-%H-100%%.log", now)
+	assert.Equal(t, "app-2024-05-01-07-100%.log", string(name),
+		"Unexpected file name")
+}
+
+func TestRollingSyncer(t *testing.T) {
+	directory, err := ioutil.TempDir("", "santa")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	clock := &testStepClock {
+		now: time.Date(2024, time.May, 1, 23, 59, 0, 0, time.UTC),
+	}
+	syncer, err := NewRollingSyncerOption().UseCacheCapacity(0).
+		UseName(filepath.Join(directory, "logs", "app-%Y-%m-%d.log")).
+		UseMakeDirectories(0755).UseClock(clock).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.Equal(t, filepath.Join(directory, "logs", "app-2024-05-01.log"),
+		syncer.Name(), "Unexpected file name")
+
+	write := func(data string) {
+		_, err := syncer.Write([]byte(data))
+		assert.NoError(t, err, "Unexpected write error")
+	}
+	write("first\n")
+	clock.now = clock.now.Add(time.Second * 59)
+	write("second\n")
+
+	// The synchronizer rolls to a new file at midnight.
+	clock.now = clock.now.Add(time.Second)
+	write("third\n")
+	assert.Equal(t, filepath.Join(directory, "logs", "app-2024-05-02.log"),
+		syncer.Name(), "Unexpected file name")
+	assert.NoError(t, syncer.Sync(), "Unexpected sync error")
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+	assert.Equal(t, ErrClosed, syncer.Close(), "Unexpected close error")
+
+	read := func(name string) string {
+		data, err := ioutil.ReadFile(filepath.Join(directory, "logs", name))
+		assert.NoError(t, err, "Unexpected read error")
+		return string(data)
+	}
+	assert.Equal(t, "first\nsecond\n", read("app-2024-05-01.log"),
+		"Unexpected file data")
+	assert.Equal(t, "third\n", read("app-2024-05-02.log"),
+		"Unexpected file data")
+}
+
+func TestRollingSyncerHourly(t *testing.T) {
+	directory, err := ioutil.TempDir("", "santa")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	clock := &testStepClock {
+		now: time.Date(2024, time.May, 1, 7, 30, 0, 0, time.UTC),
+	}
+	syncer, err := NewRollingSyncerOption().
+		UseName(filepath.Join(directory, "app-%Y%m%d%H.log")).
+		UsePeriod(RollingHourly).UseClock(clock).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	_, err = syncer.Write([]byte("first\n"))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.NoError(t, syncer.Sync(), "Unexpected sync error")
+	clock.now = clock.now.Add(time.Minute * 30)
+	_, err = syncer.Write([]byte("second\n"))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+
+	names, err := filepath.Glob(filepath.Join(directory, "app-*.log"))
+	assert.NoError(t, err, "Unexpected glob error")
+	assert.Equal(t, []string {
+		filepath.Join(directory, "app-2024050107.log"),
+		filepath.Join(directory, "app-2024050108.log"),
+	}, names, "Unexpected file names")
+}
+
+func TestRollingSyncerDiskUsage(t *testing.T) {
+	directory, err := ioutil.TempDir("", "santa")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	// Files of previous periods, from the oldest to the newest.
+	for index, day := range []string { "01", "02", "03" } {
+		name := filepath.Join(directory, "app-2024-05-" + day + ".log")
+		err := ioutil.WriteFile(name, make([]byte, 100), 0644)
+		assert.NoError(t, err, "Unexpected write error")
+		modified := time.Unix(int64(index) * 86400, 0)
+		assert.NoError(t, os.Chtimes(name, modified, modified),
+			"Unexpected chtimes error")
+	}
+	other := filepath.Join(directory, "other.log")
+	assert.NoError(t, ioutil.WriteFile(other, make([]byte, 1000), 0644),
+		"Unexpected write error")
+
+	option := NewRollingSyncerOption()
+	_, err = option.UseName("app-%Y.log").UseMaxDiskUsage(100, 100).Build()
+	assert.Equal(t, ErrInvalidRolling, err, "Unexpected build error")
+
+	var deleted []string
+	clock := &testStepClock {
+		now: time.Date(2024, time.May, 4, 0, 0, 0, 0, time.UTC),
+	}
+	syncer, err := option.UseCacheCapacity(0).
+		UseName(filepath.Join(directory, "app-%Y-%m-%d.log")).
+		UseMaxDiskUsage(250, 50).UseClock(clock).
+		UseDeleteHandler(func(name string, size int64) {
+			assert.Equal(t, int64(100), size, "Unexpected file size")
+			deleted = append(deleted, filepath.Base(name))
+		}).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	// The quota is exceeded by 50 bytes, and the oldest files are deleted
+	// until the total size is not greater than 200 bytes.
+	assert.Equal(t, []string { "app-2024-05-01.log" }, deleted,
+		"Unexpected deleted files")
+
+	_, err = syncer.Write(make([]byte, 150))
+	assert.NoError(t, err, "Unexpected write error")
+	clock.now = clock.now.Add(time.Hour * 24)
+	_, err = syncer.Write(make([]byte, 10))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.Equal(t, []string { "app-2024-05-01.log", "app-2024-05-02.log",
+		"app-2024-05-03.log" }, deleted, "Unexpected deleted files")
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+
+	_, err = os.Stat(other)
+	assert.NoError(t, err, "Unexpected stat error")
+}
+
+func TestRollingSyncerCompress(t *testing.T) {
+	directory, err := ioutil.TempDir("", "santa")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	start := time.Date(2024, time.May, 1, 8, 0, 0, 0, time.UTC)
+	clock := &testStepClock { now: start }
+	syncer, err := NewRollingSyncerOption().UseCacheCapacity(0).
+		UseName(filepath.Join(directory, "app-%Y-%m-%d.log")).
+		UseCompress().UseClock(clock).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	write := func(data string) {
+		_, err := syncer.Write([]byte(data))
+		assert.NoError(t, err, "Unexpected write error")
+	}
+	write("first\n")
+	clock.now = clock.now.Add(time.Hour)
+	write("second\n")
+	clock.now = clock.now.Add(time.Hour * 24)
+	write("third\n")
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+
+	name := filepath.Join(directory, "app-2024-05-01.log")
+	_, err = os.Stat(name)
+	assert.True(t, os.IsNotExist(err), "Unexpected stat error")
+
+	manifest, err := VerifyRollingArchive(name + ".gz.manifest")
+	assert.NoError(t, err, "Unexpected verify error")
+	assert.Equal(t, "app-2024-05-01.log.gz", manifest.File,
+		"Unexpected manifest file")
+	assert.True(t, start.Equal(manifest.First), "Unexpected manifest time")
+	assert.True(t, start.Add(time.Hour).Equal(manifest.Last),
+		"Unexpected manifest time")
+
+	file, err := os.Open(name + ".gz")
+	assert.NoError(t, err, "Unexpected open error")
+	reader, err := gzip.NewReader(file)
+	assert.NoError(t, err, "Unexpected read error")
+	data, err := ioutil.ReadAll(reader)
+	assert.NoError(t, err, "Unexpected read error")
+	assert.NoError(t, file.Close(), "Unexpected close error")
+	assert.Equal(t, "first\nsecond\n", string(data), "Unexpected file data")
+
+	// The verification fails after the archive is modified.
+	archive, err := os.OpenFile(name + ".gz", os.O_WRONLY | os.O_APPEND, 0)
+	assert.NoError(t, err, "Unexpected open error")
+	_, err = archive.Write([]byte("tampered"))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.NoError(t, archive.Close(), "Unexpected close error")
+	_, err = VerifyRollingArchive(name + ".gz.manifest")
+	assert.Equal(t, ErrArchiveMismatch, err, "Unexpected verify error")
+}
+
+func TestRollingSyncerCompressQuota(t *testing.T) {
+	directory, err := ioutil.TempDir("", "santa")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	var mutex sync.Mutex
+	var deleted []string
+	clock := &testStepClock {
+		now: time.Date(2024, time.May, 1, 8, 0, 0, 0, time.UTC),
+	}
+	syncer, err := NewRollingSyncerOption().UseCacheCapacity(0).
+		UseName(filepath.Join(directory, "app-%Y-%m-%d.log")).
+		UseBanners(nil, TextBanner("# end\n")).UseCompress().
+		UseMaxDiskUsage(1, 0).UseClock(clock).
+		UseDeleteHandler(func(name string, size int64) {
+			mutex.Lock()
+			deleted = append(deleted, filepath.Base(name))
+			mutex.Unlock()
+		}).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	for index := 0; index < 3; index++ {
+		_, err = syncer.Write([]byte("Hello Test!\n"))
+		assert.NoError(t, err, "Unexpected write error")
+		clock.now = clock.now.Add(time.Hour * 24)
+	}
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+
+	// The files of previous periods are only deleted after they have been
+	// compressed, so only the archives are deleted.
+	sort.Strings(deleted)
+	assert.Equal(t, []string { "app-2024-05-01.log.gz",
+		"app-2024-05-02.log.gz" }, deleted, "Unexpected deleted files")
+	names, err := filepath.Glob(filepath.Join(directory, "*"))
+	assert.NoError(t, err, "Unexpected glob error")
+	assert.Equal(t, []string { filepath.Join(directory,
+		"app-2024-05-03.log") }, names, "Unexpected remaining files")
+}
+
+func TestRollingSyncerBanners(t *testing.T) {
+	directory, err := ioutil.TempDir("", "santa")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	clock := &testStepClock {
+		now: time.Date(2024, time.May, 1, 23, 0, 0, 0, time.UTC),
+	}
+	syncer, err := NewRollingSyncerOption().UseCacheCapacity(0).
+		UseName(filepath.Join(directory, "app-%Y-%m-%d.csv")).
+		UseBanners(TextBanner("level,message\n"), TextBanner("# end\n")).
+		UseClock(clock).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	_, err = syncer.Write([]byte("info,first\n"))
+	assert.NoError(t, err, "Unexpected write error")
+	clock.now = clock.now.Add(time.Hour)
+	_, err = syncer.Write([]byte("info,second\n"))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+
+	// The header and footer are written to each file.
+	for name, row := range map[string]string {
+		"app-2024-05-01.csv": "info,first\n",
+		"app-2024-05-02.csv": "info,second\n",
+	} {
+		data, err := ioutil.ReadFile(filepath.Join(directory, name))
+		assert.NoError(t, err, "Unexpected read error")
+		assert.Equal(t, "level,message\n" + row + "# end\n", string(data),
+			"Unexpected file data")
+	}
+}
@@ -0,0 +1,297 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"errors"
+)
+
+var (
+	// ErrInvalidRoute represents that the given route of the router
+	// exporter is invalid, usually because the exporter of the route is
+	// not provided or the level span of the route is empty.
+	ErrInvalidRoute = errors.New("invalid route")
+)
+
+// Route is a structure that contains a rule of the router exporter and
+// the exporter to which the matched log entries are dispatched.
+//
+// A log entry matches the route if its level is included in the level
+// span, its name is one of the names (if any), and it has all of the
+// labels with the same values (if any).
+type Route struct {
+	// Span represents the log level span of the route. If not provided,
+	// the default value is DEBUG level to FATAL level.
+	Span LevelSpan
+
+	// Names represents the names of log entries matched by the route. If
+	// not provided, log entries with any name are matched.
+	Names []string
+
+	// Labels represents the labels that log entries matched by the route
+	// must have with the same values. If not provided, log entries with
+	// any labels are matched.
+	Labels Labels
+
+	// Exporter represents the exporter to which the matched log entries
+	// are dispatched. This option is required.
+	Exporter Exporter
+}
+
+// Match checks whether the given log entry matches the route. For details,
+// please refer to the comment section of the Route structure.
+func (r *Route) Match(entry *Entry) bool {
+	if !r.Span.Contains(entry.Level) {
+		return false
+	}
+	if len(r.Names) > 0 {
+		matched := false
+		for _, name := range r.Names {
+			if name == entry.Name {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for _, label := range r.Labels {
+		if !hasLabel(entry.Labels.Labels(), label) {
+			return false
+		}
+	}
+	return true
+}
+
+// UseSpan uses the given start and end log levels as the value of the
+// Span option. For details, please refer to the comment section of the
+// Span option. Then return to the route instance itself.
+func (r *Route) UseSpan(start, end Level) *Route {
+	r.Span = LevelSpan {
+		Start: start,
+		End: end,
+	}
+	return r
+}
+
+// UseNames uses the given names as the value of the Names option. For
+// details, please refer to the comment section of the Names option. Then
+// return to the route instance itself.
+func (r *Route) UseNames(names ...string) *Route {
+	r.Names = names
+	return r
+}
+
+// UseLabels uses the given labels as the value of the Labels option. For
+// details, please refer to the comment section of the Labels option. Then
+// return to the route instance itself.
+func (r *Route) UseLabels(labels ...Label) *Route {
+	r.Labels = labels
+	return r
+}
+
+// NewRoute creates and returns a route instance that dispatches matched
+// log entries to the given exporter, with default optional values.
+func NewRoute(exporter Exporter) *Route {
+	return &Route {
+		Span: LevelSpan {
+			Start: LevelDebug,
+			End: LevelFatal,
+		},
+		Exporter: exporter,
+	}
+}
+
+// hasLabel checks whether the given labels contain a label with the same
+// key and value as the given label.
+func hasLabel(labels Labels, label Label) bool {
+	for index := 0; index < len(labels); index++ {
+		if labels[index] == label {
+			return true
+		}
+	}
+	return false
+}
+
+// RouterExporter is the structure of the router exporter instance.
+//
+// The router exporter dispatches each log entry to the exporters of the
+// routes that match it, which generalizes the common split of log levels
+// between the standard output and the standard error device. For example,
+// log entries of the "audit" logger can be dispatched to a file, log
+// entries with the label "module=billing" to a network location, and all
+// other log entries to the standard output.
+//
+// By default, each log entry is dispatched to the exporters of all matched
+// routes in order. If the FirstMatch option is enabled, each log entry is
+// only dispatched to the exporter of the first matched route. Log entries
+// that do not match any route are dispatched to the fallback exporter, if
+// provided.
+//
+// The router exporter does not check the level of log entries by itself,
+// and the exporters of the routes still apply their own checks.
+type RouterExporter struct {
+	routes []Route
+	fallback Exporter
+	firstMatch bool
+	exporters []Exporter
+}
+
+// Export dispatches the given log entry to the exporters of the routes
+// that match it. For details, please refer to the comment section of the
+// RouterExporter structure.
+//
+// Finally, the first error encountered is returned. The log entry is still
+// dispatched to the remaining exporters after an error.
+func (e *RouterExporter) Export(entry *Entry) error {
+	var result error
+	matched := false
+	for index := 0; index < len(e.routes); index++ {
+		route := &e.routes[index]
+		if !route.Match(entry) {
+			continue
+		}
+		matched = true
+		if err := route.Exporter.Export(entry); err != nil && result == nil {
+			result = err
+		}
+		if e.firstMatch {
+			break
+		}
+	}
+	if !matched && e.fallback != nil {
+		return e.fallback.Export(entry)
+	}
+	return result
+}
+
+// Sync calls the Sync function of each exporter of the routes and the
+// fallback exporter once.
+//
+// Finally, the first error encountered is returned.
+func (e *RouterExporter) Sync() error {
+	var result error
+	for _, exporter := range e.exporters {
+		if err := exporter.Sync(); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
+
+// Close calls the Close function of each exporter of the routes and the
+// fallback exporter once.
+//
+// Finally, the first error encountered is returned.
+func (e *RouterExporter) Close() error {
+	var result error
+	for _, exporter := range e.exporters {
+		if err := exporter.Close(); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
+
+// RouterExporterOption is a structure that contains options for the
+// router exporter.
+type RouterExporterOption struct {
+	// Routes represents the routes of the router exporter in order. For
+	// details, please refer to the comment section of the Route structure.
+	// If not provided, all log entries are dispatched to the fallback
+	// exporter.
+	Routes []Route
+
+	// Fallback represents the exporter to which log entries that do not
+	// match any route are dispatched. If not provided, these log entries
+	// are discarded.
+	Fallback Exporter
+
+	// FirstMatch represents whether each log entry is only dispatched to
+	// the exporter of the first matched route. If not provided, the
+	// default value is false.
+	FirstMatch bool
+}
+
+// UseRoute appends the given route to the value of the Routes option. For
+// details, please refer to the comment section of the Routes option. Then
+// return to the option instance itself.
+func (o *RouterExporterOption) UseRoute(route *Route) *RouterExporterOption {
+	o.Routes = append(o.Routes, *route)
+	return o
+}
+
+// UseFallback uses the given exporter as the value of the Fallback option.
+// For details, please refer to the comment section of the Fallback option.
+// Then return to the option instance itself.
+func (o *RouterExporterOption) UseFallback(exporter Exporter) *RouterExporterOption {
+	o.Fallback = exporter
+	return o
+}
+
+// UseFirstMatch enables the FirstMatch option. For details, please refer
+// to the comment section of the FirstMatch option. Then return to the
+// option instance itself.
+func (o *RouterExporterOption) UseFirstMatch() *RouterExporterOption {
+	o.FirstMatch = true
+	return o
+}
+
+// Build builds and returns a router exporter instance.
+func (o *RouterExporterOption) Build() (*RouterExporter, error) {
+	instance := &RouterExporter {
+		routes: make([]Route, len(o.Routes)),
+		fallback: o.Fallback,
+		firstMatch: o.FirstMatch,
+	}
+	copy(instance.routes, o.Routes)
+	for _, route := range instance.routes {
+		if route.Exporter == nil || route.Span.Start > route.Span.End {
+			return nil, ErrInvalidRoute
+		}
+		instance.appendExporter(route.Exporter)
+	}
+	if o.Fallback != nil {
+		instance.appendExporter(o.Fallback)
+	}
+	return instance, nil
+}
+
+// appendExporter appends the given exporter to the exporters that are
+// synchronized and closed by the router exporter, unless it has already
+// been appended by another route.
+func (e *RouterExporter) appendExporter(exporter Exporter) {
+	for _, appended := range e.exporters {
+		if appended == exporter {
+			return
+		}
+	}
+	e.exporters = append(e.exporters, exporter)
+}
+
+// NewRouterExporterOption creates and returns an instance of the router
+// exporter option with default optional values.
+func NewRouterExporterOption() *RouterExporterOption {
+	return &RouterExporterOption { }
+}
@@ -0,0 +1,95 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterExporterOption(t *testing.T) {
+	option := NewRouterExporterOption()
+	option.UseRoute(NewRoute(nil))
+
+	_, err := option.Build()
+	assert.Equal(t, ErrInvalidRoute, err, "Unexpected build error")
+
+	exporter := &testRecordExporter { }
+	option = NewRouterExporterOption()
+	option.UseRoute(NewRoute(exporter).UseSpan(LevelError, LevelInfo))
+
+	_, err = option.Build()
+	assert.Equal(t, ErrInvalidRoute, err, "Unexpected build error")
+
+	option = NewRouterExporterOption()
+	option.UseRoute(NewRoute(exporter).UseSpan(LevelError, LevelFatal))
+	option.UseRoute(NewRoute(exporter).UseNames("audit"))
+	option.UseFallback(exporter)
+	option.UseFirstMatch()
+
+	router, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.Len(t, router.routes, 2, "Unexpected instance error")
+	assert.Len(t, router.exporters, 1, "Unexpected instance error")
+	assert.True(t, router.firstMatch, "Unexpected instance error")
+}
+
+func TestRouterExporter(t *testing.T) {
+	failures := &testRecordExporter { }
+	audit := &testRecordExporter { }
+	billing := &testRecordExporter { }
+	fallback := &testRecordExporter { }
+
+	router, err := NewRouterExporterOption().
+		UseRoute(NewRoute(failures).UseSpan(LevelError, LevelFatal)).
+		UseRoute(NewRoute(audit).UseNames("audit", "security")).
+		UseRoute(NewRoute(billing).UseLabels(NewLabel("module", "billing"))).
+		UseFallback(fallback).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	entries := []*Entry {
+		{ Level: LevelInfo, Name: "audit" },
+		{ Level: LevelError, Name: "security" },
+		{ Level: LevelInfo, Labels: NewSerializedLabels(
+			NewLabel("module", "billing")) },
+		{ Level: LevelInfo, Labels: NewSerializedLabels(
+			NewLabel("module", "users")) },
+	}
+	for _, entry := range entries {
+		assert.NoError(t, router.Export(entry), "Unexpected export error")
+	}
+	assert.Len(t, failures.entries, 1, "Unexpected export result")
+	assert.Len(t, audit.entries, 2, "Unexpected export result")
+	assert.Len(t, billing.entries, 1, "Unexpected export result")
+	assert.Len(t, fallback.entries, 1, "Unexpected export result")
+
+	// Only the first matched route is used.
+	router.firstMatch = true
+	assert.NoError(t, router.Export(entries[1]), "Unexpected export error")
+	assert.Len(t, failures.entries, 2, "Unexpected export result")
+	assert.Len(t, audit.entries, 2, "Unexpected export result")
+
+	assert.NoError(t, router.Sync(), "Unexpected sync error")
+	assert.NoError(t, router.Close(), "Unexpected close error")
+}
@@ -23,10 +23,67 @@
 package santa
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
 	"sync/atomic"
 	"time"
 )
 
+var (
+	// ErrInvalidTick represents that the sampling cycle time of a sampler
+	// is not positive.
+	ErrInvalidTick = errors.New("invalid sampling tick")
+
+	// ErrInvalidCounters represents that the number of counters of a
+	// sampler is zero.
+	ErrInvalidCounters = errors.New("invalid number of sampling counters")
+)
+
+// validateSampling checks whether the given log level span and sampling
+// cycle time of a sampler are valid, and returns an error describing the
+// first invalid value.
+func validateSampling(span LevelSpan, tick time.Duration) error {
+	if err := span.Validate(); err != nil {
+		return err
+	}
+	if tick <= 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidTick, tick)
+	}
+	return nil
+}
+
+// levelSet is a data type that represents a set of log levels, in which
+// each bit represents a log level.
+type levelSet uint8
+
+// newLevelSet creates and returns a set of the given log levels. If the
+// given slice is nil, the set of ERROR and FATAL levels is returned.
+func newLevelSet(levels []Level) levelSet {
+	if levels == nil {
+		levels = []Level { LevelError, LevelFatal }
+	}
+	var set levelSet
+	for _, level := range levels {
+		set |= 1 << level
+	}
+	return set
+}
+
+// contains checks whether the given log level is included in the set.
+func (s levelSet) contains(level Level) bool {
+	return s & (1 << level) != 0
+}
+
+// cloneLevels creates and returns a copy of the given log levels. A nil
+// slice remains nil, because it means that the default levels are used.
+func cloneLevels(levels []Level) []Level {
+	if levels == nil {
+		return nil
+	}
+	return append(make([]Level, 0, len(levels)), levels...)
+}
+
 // Sampler is the public interface of the sampler.
 //
 // The sampler is a log entry sampler, which usually collects part of all
@@ -45,7 +102,9 @@ type Sampler interface {
 	Sample(entry *Entry) bool
 }
 
-type textSamplerCounter struct {
+// samplerCounter is the structure of a counter of the repeated log entries
+// of a sampler.
+type samplerCounter struct {
 	// count represents the value of the counter.
 	count uint64
 
@@ -75,10 +134,11 @@ type textSamplerCounter struct {
 // resources will be used.
 type TextSampler struct {
 	span LevelSpan
+	never levelSet
 	tick int64
 	first uint64
 	thereafter uint64
-	counters []textSamplerCounter
+	counters []samplerCounter
 }
 
 // TextSampleParser is the public interface of the text sample parser.
@@ -93,7 +153,7 @@ type TextSampleParser interface {
 
 // hash64 uses the FNV64-A algorithm to calculate and returns the Hash value
 // of the given text.
-func (*TextSampler) hash64(text string) uint64 {
+func hash64(text string) uint64 {
 	result := uint64(14695981039346656037)
 	for index := 0; index < len(text); index++ {
 		result ^= uint64(text[index])
@@ -102,10 +162,21 @@ func (*TextSampler) hash64(text string) uint64 {
 	return result
 }
 
+// hash64Bytes uses the FNV64-A algorithm to calculate and returns the Hash
+// value of the given buffer slice.
+func hash64Bytes(buffer []byte) uint64 {
+	result := uint64(14695981039346656037)
+	for index := 0; index < len(buffer); index++ {
+		result ^= uint64(buffer[index])
+		result *= 1099511628211
+	}
+	return result
+}
+
 // Sample checks whether a given log entry needs to be sampled. It returns
 // true if needed, otherwise it returns false.
 func (s *TextSampler) Sample(entry *Entry) bool {
-	if !s.span.Contains(entry.Level) {
+	if s.never.contains(entry.Level) || !s.span.Contains(entry.Level) {
 		return true
 	}
 	parser, ok := entry.Message.(TextSampleParser)
@@ -113,36 +184,45 @@ func (s *TextSampler) Sample(entry *Entry) bool {
 		return true
 	}
 
-	index := s.hash64(parser.SampleText()) % uint64(len(s.counters))
-	count := atomic.LoadUint64(&s.counters[index].count)
-	clock := entry.Time.UnixNano()
-	after := atomic.LoadInt64(&s.counters[index].after)
+	index := hash64(parser.SampleText()) % uint64(len(s.counters))
+	return sampleCounter(&s.counters[index], entry.Time.UnixNano(), s.tick,
+		s.first, s.thereafter)
+}
+
+// sampleCounter counts a log entry with the given time using the given
+// counter, and checks whether the log entry needs to be sampled. The
+// sampling policy is to allow the same log entry to be output <first>
+// times in a sampling period of <tick>, and then output it once after
+// every <thereafter> interval.
+func sampleCounter(counter *samplerCounter, clock int64, tick int64,
+	first uint64, thereafter uint64) bool {
+	count := atomic.LoadUint64(&counter.count)
+	after := atomic.LoadInt64(&counter.after)
 	
 	// If it has been more than or equal to one sampling period since the
 	// last time the counter was reset, the counter is reset.
 	if after <= clock {
 		// Update the next reset time to the counter. If the update fails,
 		// it is considered that another hyperthread is competing.
-		atomic.CompareAndSwapInt64(&s.counters[index].after, after,
-			clock + s.tick)
+		atomic.CompareAndSwapInt64(&counter.after, after, clock + tick)
 		
 		// If the instant counter count is greater than 0, it is reset to 1.
 		if count > 0 {
 			// Using subtraction to reset the counter value can avoid
 			// incorrect value overwriting when multiple hyperthreads
 			// compete.
-			atomic.AddUint64(&s.counters[index].count, -count + 1)
+			atomic.AddUint64(&counter.count, -count + 1)
 		}
 
 		return true
 	}
 
-	count = atomic.AddUint64(&s.counters[index].count, 1)
+	count = atomic.AddUint64(&counter.count, 1)
 
-	// If the same log entry has been repeatedly printed <s.first> times in
-	// a sampling period, and the condition of printing once after the interval
-	// <s.thereafter> times is not met, it will be discarded.
-	if count > s.first && (count - s.first) % s.thereafter != 0 {
+	// If the same log entry has been repeatedly printed <first> times in
+	// a sampling period, and the condition of printing once after the
+	// interval <thereafter> times is not met, it will be discarded.
+	if count > first && (count - first) % thereafter != 0 {
 		return false
 	}
 
@@ -158,6 +238,15 @@ type TextSamplerOption struct {
 	// If this option is not set, the default is INFO to WARNING.
 	Span LevelSpan
 
+	// NeverSampleLevels represents the log levels whose log entries are
+	// never discarded by the sampler, regardless of the other options, so
+	// that critical log entries cannot be dropped by a configuration
+	// mistake. To allow log entries of all levels to be discarded, use an
+	// empty slice.
+	//
+	// If this option is not set, the default is ERROR and FATAL.
+	NeverSampleLevels []Level
+
 	// Tick represents the sampling cycle time, and the sampling counter
 	// is reset every other cycle.
 	//
@@ -185,18 +274,41 @@ type TextSamplerOption struct {
 	Counters uint64
 }
 
+// Validate checks whether the values of the options are valid, and returns
+// an error describing the first invalid value.
+func (o *TextSamplerOption) Validate() error {
+	if err := validateSampling(o.Span, o.Tick); err != nil {
+		return err
+	}
+	if o.Counters == 0 {
+		return ErrInvalidCounters
+	}
+	return nil
+}
+
+// Clone creates and returns a copy of the option instance. The copy does
+// not share the NeverSampleLevels option slice with the option instance,
+// so modifying either of them does not affect the other.
+func (o *TextSamplerOption) Clone() *TextSamplerOption {
+	option := *o
+	option.NeverSampleLevels = cloneLevels(o.NeverSampleLevels)
+	return &option
+}
+
 // Build builds and returns a text sampler instance using the option value.
-//
-// Please note that this function does not check the validity of the option
-// value, please use the NewTextSamplerOption function to create an option
-// instance.
+// If the option value is invalid, it returns the error of the Validate
+// function.
 func (o *TextSamplerOption) Build() (*TextSampler, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
 	return &TextSampler {
 		span: o.Span,
+		never: newLevelSet(o.NeverSampleLevels),
 		tick: int64(o.Tick),
 		first: o.First,
 		thereafter: o.Thereafter,
-		counters: make([]textSamplerCounter, o.Counters),
+		counters: make([]samplerCounter, o.Counters),
 	}, nil
 }
 
@@ -209,6 +321,17 @@ func (o *TextSamplerOption) UseSpan(start, end Level) *TextSamplerOption {
 	return o
 }
 
+// UseNeverSampleLevels sets the NeverSampleLevels option using the given
+// log levels. If no log level is given, log entries of all levels may be
+// discarded.
+func (o *TextSamplerOption) UseNeverSampleLevels(levels ...Level) *TextSamplerOption {
+	if levels == nil {
+		levels = []Level { }
+	}
+	o.NeverSampleLevels = levels
+	return o
+}
+
 // UseTick sets the Tick option using the given sampling period value.
 func (o *TextSamplerOption) UseTick(tick time.Duration) *TextSamplerOption {
 	o.Tick = tick
@@ -237,6 +360,7 @@ func NewTextSamplerOption() *TextSamplerOption {
 			Start: LevelInfo,
 			End: LevelWarning,
 		},
+		NeverSampleLevels: []Level { LevelError, LevelFatal },
 		Tick: time.Second,
 		First: 100,
 		Thereafter: 100,
@@ -249,3 +373,861 @@ func NewTextSamplerOption() *TextSamplerOption {
 func NewTextSampler() (*TextSampler, error) {
 	return NewTextSamplerOption().Build()
 }
+
+// FieldSampler is the structure of the field sampler instance.
+//
+// The field sampler groups log entries by the value of a given field, and
+// applies the sampling policy of the text sampler to each group, so that
+// one hot group (for example, one tenant or one route) cannot consume the
+// entire log budget. The value is taken from the top-level field with the
+// given name of structured messages, or else from the label with the given
+// key. Log entries that have neither of them are always sampled.
+//
+// Like the text sampler, groups are tracked by a fixed number of counters,
+// so different groups may share a counter. For details, please refer to
+// the comment section of the TextSampler structure.
+type FieldSampler struct {
+	span LevelSpan
+	never levelSet
+	name string
+	tick int64
+	first uint64
+	thereafter uint64
+	counters []samplerCounter
+}
+
+// Sample checks whether a given log entry needs to be sampled. It returns
+// true if needed, otherwise it returns false.
+func (s *FieldSampler) Sample(entry *Entry) bool {
+	if s.never.contains(entry.Level) || !s.span.Contains(entry.Level) {
+		return true
+	}
+	hash, ok := s.hash(entry)
+	if !ok {
+		return true
+	}
+	index := hash % uint64(len(s.counters))
+	return sampleCounter(&s.counters[index], entry.Time.UnixNano(), s.tick,
+		s.first, s.thereafter)
+}
+
+// hash returns the Hash value of the value of the grouping field of the
+// given log entry. If the log entry does not have the grouping field, it
+// returns false.
+func (s *FieldSampler) hash(entry *Entry) (uint64, bool) {
+	element, ok := entryElement(entry, s.name)
+	if !ok {
+		return 0, false
+	}
+	if element.Type == TypeString {
+		return hash64(element.String), true
+	}
+	var buffer [64]byte
+	return hash64Bytes(element.SerializeJSON(buffer[ : 0])), true
+}
+
+// entryElement returns the value of the top-level field with the given
+// name of the structured message of the given log entry, or else the value
+// of the label with the given key as a string element. If the log entry has
+// neither of them, it returns false.
+func entryElement(entry *Entry, name string) (Element, bool) {
+	fields := entry.Fields
+	if fields == nil {
+		fields, _ = messageFields(entry.Message)
+	}
+	for index := 0; index < len(fields); index++ {
+		if fields[index].Name == name {
+			return fields[index].Element, true
+		}
+	}
+	for _, label := range entry.Labels.Labels() {
+		if label.Key == name {
+			return Element {
+				Type: TypeString,
+				String: label.Value,
+			}, true
+		}
+	}
+	return Element { }, false
+}
+
+// FieldSamplerOption is a structure containing field sampler options.
+type FieldSamplerOption struct {
+	// Span represents the log level span for which sampling strategy
+	// needs to be applied. If the level of the log entry is not included
+	// in the span, the output is sampled.
+	//
+	// If this option is not set, the default is INFO to WARNING.
+	Span LevelSpan
+
+	// NeverSampleLevels represents the log levels whose log entries are
+	// never discarded by the sampler, regardless of the other options, so
+	// that critical log entries cannot be dropped by a configuration
+	// mistake. To allow log entries of all levels to be discarded, use an
+	// empty slice.
+	//
+	// If this option is not set, the default is ERROR and FATAL.
+	NeverSampleLevels []Level
+
+	// Field represents the name of the field (or the key of the label)
+	// whose value groups log entries, for example, "userID" or "route".
+	//
+	// This option must be provided.
+	Field string
+
+	// Tick represents the sampling cycle time, and the sampling counter
+	// is reset every other cycle.
+	//
+	// If this option is not set, the default is 1 second.
+	Tick time.Duration
+
+	// First represents how many log entries of the same group should be
+	// allowed to be output in a cycle before discarding them.
+	//
+	// If this option is not provided, the default is 100 times.
+	First uint64
+
+	// Thereafter represents how many log entries of the same group are
+	// discarded, it should be allowed to be output once.
+	//
+	// If this option is not provided, the default is 100 times.
+	Thereafter uint64
+
+	// Counters represents the number of counters used to track groups.
+	// More counters means that groups share counters less often, but
+	// will also consume more memory resources.
+	//
+	// If this option is not provided, the default is 1024 times.
+	Counters uint64
+}
+
+// Validate checks whether the values of the options are valid, and returns
+// an error describing the first invalid value.
+func (o *FieldSamplerOption) Validate() error {
+	if err := validateSampling(o.Span, o.Tick); err != nil {
+		return err
+	}
+	if o.Counters == 0 {
+		return ErrInvalidCounters
+	}
+	return nil
+}
+
+// Clone creates and returns a copy of the option instance. The copy does
+// not share the NeverSampleLevels option slice with the option instance,
+// so modifying either of them does not affect the other.
+func (o *FieldSamplerOption) Clone() *FieldSamplerOption {
+	option := *o
+	option.NeverSampleLevels = cloneLevels(o.NeverSampleLevels)
+	return &option
+}
+
+// Build builds and returns a field sampler instance using the option value.
+// If the option value is invalid, it returns the error of the Validate
+// function.
+func (o *FieldSamplerOption) Build() (*FieldSampler, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	return &FieldSampler {
+		span: o.Span,
+		never: newLevelSet(o.NeverSampleLevels),
+		name: o.Field,
+		tick: int64(o.Tick),
+		first: o.First,
+		thereafter: o.Thereafter,
+		counters: make([]samplerCounter, o.Counters),
+	}, nil
+}
+
+// UseSpan sets the Span option using the given log level span.
+func (o *FieldSamplerOption) UseSpan(start, end Level) *FieldSamplerOption {
+	o.Span = LevelSpan {
+		Start: start,
+		End: end,
+	}
+	return o
+}
+
+// UseNeverSampleLevels sets the NeverSampleLevels option using the given
+// log levels. If no log level is given, log entries of all levels may be
+// discarded.
+func (o *FieldSamplerOption) UseNeverSampleLevels(levels ...Level) *FieldSamplerOption {
+	if levels == nil {
+		levels = []Level { }
+	}
+	o.NeverSampleLevels = levels
+	return o
+}
+
+// UseField sets the Field option using the given field name.
+func (o *FieldSamplerOption) UseField(name string) *FieldSamplerOption {
+	o.Field = name
+	return o
+}
+
+// UseTick sets the Tick option using the given sampling period value.
+func (o *FieldSamplerOption) UseTick(tick time.Duration) *FieldSamplerOption {
+	o.Tick = tick
+	return o
+}
+
+// UseFirst sets the options First and Then using the given value.
+func (o *FieldSamplerOption) UseFirst(first, thereafter uint64) *FieldSamplerOption {
+	o.First = first
+	o.Thereafter = thereafter
+	return o
+}
+
+// UseCounters sets the Counters option using the given number of
+// sampling counters.
+func (o *FieldSamplerOption) UseCounters(counters uint64) *FieldSamplerOption {
+	o.Counters = counters
+	return o
+}
+
+// NewFieldSamplerOption creates and returns a field sampler option instance
+// with default option values, which groups log entries by the given field.
+func NewFieldSamplerOption(field string) *FieldSamplerOption {
+	return &FieldSamplerOption {
+		Span: LevelSpan {
+			Start: LevelInfo,
+			End: LevelWarning,
+		},
+		NeverSampleLevels: []Level { LevelError, LevelFatal },
+		Field: field,
+		Tick: time.Second,
+		First: 100,
+		Thereafter: 100,
+		Counters: 1024,
+	}
+}
+
+// NewFieldSampler creates and returns a field sampler instance that groups
+// log entries by the given field, using default option values.
+func NewFieldSampler(field string) (*FieldSampler, error) {
+	return NewFieldSamplerOption(field).Build()
+}
+
+// TraceSampler is the structure of the trace sampler instance.
+//
+// The trace sampler keeps the log entries consistent with the sampling of
+// distributed traces (for example, OpenTelemetry traces): all log entries
+// that belong to sampled traces are sampled, and the log entries of other
+// traces are discarded or downsampled. The trace of a log entry is
+// identified by the value of the trace ID field, which is taken from the
+// top-level field of structured messages or else from the label with the
+// same key.
+//
+// Whether a trace is sampled is determined by the sampled field if the log
+// entry has it, which can be a boolean, or the trace flags as an integer or
+// hexadecimal string whose lowest bit is the sampled flag. Otherwise, it is
+// determined from the trace ID in the same way as the trace ID ratio based
+// sampler of OpenTelemetry: the trace is sampled if the last 8 bytes of the
+// trace ID, shifted right by 1 bit, are less than the ratio multiplied by
+// 2^63. Log entries without a trace ID are sampled unless the DropUntraced
+// option is enabled.
+//
+// Log entries of traces that are not sampled are downsampled by trace, so
+// either all or none of the log entries of each trace are kept.
+type TraceSampler struct {
+	span LevelSpan
+	never levelSet
+	traceField string
+	sampledField string
+	ratioBound uint64
+	unsampledBound uint64
+	dropUntraced bool
+}
+
+// Sample checks whether a given log entry needs to be sampled. It returns
+// true if needed, otherwise it returns false.
+func (s *TraceSampler) Sample(entry *Entry) bool {
+	if s.never.contains(entry.Level) || !s.span.Contains(entry.Level) {
+		return true
+	}
+	element, ok := entryElement(entry, s.traceField)
+	if !ok || element.Type != TypeString || len(element.String) == 0 {
+		return !s.dropUntraced
+	}
+	trace := element.String
+	if sampled, ok := s.sampled(entry); ok {
+		if sampled {
+			return true
+		}
+	} else if traceRatioValue(trace) < s.ratioBound {
+		return true
+	}
+	// The hash of the trace ID is independent of the value used by the
+	// ratio, so the downsampled traces are not biased.
+	return hash64(trace) >> 1 < s.unsampledBound
+}
+
+// sampled returns the sampled flag of the trace of the given log entry
+// from the sampled field. If the log entry does not have the sampled field,
+// it returns false as the second value.
+func (s *TraceSampler) sampled(entry *Entry) (bool, bool) {
+	if len(s.sampledField) == 0 {
+		return false, false
+	}
+	element, ok := entryElement(entry, s.sampledField)
+	if !ok {
+		return false, false
+	}
+	switch element.Type {
+	case TypeBoolean, TypeInt, TypeUint:
+		return element.Number & 1 == 1, true
+	case TypeString:
+		flags, err := strconv.ParseUint(element.String, 16, 8)
+		if err != nil {
+			return element.String == "true", true
+		}
+		return flags & 1 == 1, true
+	}
+	return false, false
+}
+
+// traceRatioValue returns the value of the given trace ID that is compared
+// with the bound of the ratio. For details, please refer to the comment
+// section of the TraceSampler structure. If the trace ID is not a
+// hexadecimal string of at least 16 characters, its hash is used instead.
+func traceRatioValue(trace string) uint64 {
+	if len(trace) >= 16 {
+		value, err := strconv.ParseUint(trace[len(trace) - 16 : ], 16, 64)
+		if err == nil {
+			return value >> 1
+		}
+	}
+	return hash64(trace) >> 1
+}
+
+// traceRatioBound returns the bound of the given ratio. For details, please
+// refer to the comment section of the TraceSampler structure.
+func traceRatioBound(ratio float64) uint64 {
+	if ratio >= 1 {
+		return 1 << 63
+	}
+	if ratio <= 0 {
+		return 0
+	}
+	return uint64(ratio * (1 << 63))
+}
+
+// TraceSamplerOption is a structure containing trace sampler options.
+type TraceSamplerOption struct {
+	// Span represents the log level span for which sampling strategy
+	// needs to be applied. If the level of the log entry is not included
+	// in the span, the output is sampled.
+	//
+	// If this option is not set, the default is DEBUG to WARNING.
+	Span LevelSpan
+
+	// NeverSampleLevels represents the log levels whose log entries are
+	// never discarded by the sampler, regardless of the other options, so
+	// that critical log entries cannot be dropped by a configuration
+	// mistake. To allow log entries of all levels to be discarded, use an
+	// empty slice.
+	//
+	// If this option is not set, the default is ERROR and FATAL.
+	NeverSampleLevels []Level
+
+	// TraceField represents the name of the field (or the key of the
+	// label) whose value is the trace ID.
+	//
+	// If this option is not set, the default is "traceID".
+	TraceField string
+
+	// SampledField represents the name of the field (or the key of the
+	// label) whose value is the sampled flag or the trace flags. If the
+	// value is empty, the sampled flag is not used.
+	//
+	// If this option is not set, the default is "traceSampled".
+	SampledField string
+
+	// Ratio represents the ratio of traces that are sampled, which is
+	// used if the log entry does not have the sampled field. It should be
+	// the same as the ratio of the trace sampler of the tracing system.
+	//
+	// If this option is not set, the default is 1, which samples all
+	// traces.
+	Ratio float64
+
+	// UnsampledRatio represents the ratio of traces that are not sampled
+	// whose log entries are still sampled.
+	//
+	// If this option is not set, the default is 0, which discards the log
+	// entries of all traces that are not sampled.
+	UnsampledRatio float64
+
+	// DropUntraced represents whether to discard log entries without a
+	// trace ID.
+	//
+	// If this option is not set, the default is false.
+	DropUntraced bool
+}
+
+// Validate checks whether the values of the options are valid, and returns
+// an error describing the first invalid value.
+func (o *TraceSamplerOption) Validate() error {
+	return o.Span.Validate()
+}
+
+// Clone creates and returns a copy of the option instance. The copy does
+// not share the NeverSampleLevels option slice with the option instance,
+// so modifying either of them does not affect the other.
+func (o *TraceSamplerOption) Clone() *TraceSamplerOption {
+	option := *o
+	option.NeverSampleLevels = cloneLevels(o.NeverSampleLevels)
+	return &option
+}
+
+// Build builds and returns a trace sampler instance using the option value.
+// If the option value is invalid, it returns the error of the Validate
+// function.
+func (o *TraceSamplerOption) Build() (*TraceSampler, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	return &TraceSampler {
+		span: o.Span,
+		never: newLevelSet(o.NeverSampleLevels),
+		traceField: o.TraceField,
+		sampledField: o.SampledField,
+		ratioBound: traceRatioBound(o.Ratio),
+		unsampledBound: traceRatioBound(o.UnsampledRatio),
+		dropUntraced: o.DropUntraced,
+	}, nil
+}
+
+// UseSpan sets the Span option using the given log level span.
+func (o *TraceSamplerOption) UseSpan(start, end Level) *TraceSamplerOption {
+	o.Span = LevelSpan {
+		Start: start,
+		End: end,
+	}
+	return o
+}
+
+// UseNeverSampleLevels sets the NeverSampleLevels option using the given
+// log levels. If no log level is given, log entries of all levels may be
+// discarded.
+func (o *TraceSamplerOption) UseNeverSampleLevels(levels ...Level) *TraceSamplerOption {
+	if levels == nil {
+		levels = []Level { }
+	}
+	o.NeverSampleLevels = levels
+	return o
+}
+
+// UseFields sets the options TraceField and SampledField using the given
+// field names.
+func (o *TraceSamplerOption) UseFields(trace, sampled string) *TraceSamplerOption {
+	o.TraceField = trace
+	o.SampledField = sampled
+	return o
+}
+
+// UseRatio sets the options Ratio and UnsampledRatio using the given
+// ratios.
+func (o *TraceSamplerOption) UseRatio(ratio, unsampled float64) *TraceSamplerOption {
+	o.Ratio = ratio
+	o.UnsampledRatio = unsampled
+	return o
+}
+
+// UseDropUntraced enables the DropUntraced option.
+func (o *TraceSamplerOption) UseDropUntraced() *TraceSamplerOption {
+	o.DropUntraced = true
+	return o
+}
+
+// NewTraceSamplerOption creates and returns a trace sampler option instance
+// with default option values.
+func NewTraceSamplerOption() *TraceSamplerOption {
+	return &TraceSamplerOption {
+		Span: LevelSpan {
+			Start: LevelDebug,
+			End: LevelWarning,
+		},
+		NeverSampleLevels: []Level { LevelError, LevelFatal },
+		TraceField: "traceID",
+		SampledField: "traceSampled",
+		Ratio: 1,
+	}
+}
+
+// NewTraceSampler creates and returns a trace sampler instance using default
+// option values.
+func NewTraceSampler() (*TraceSampler, error) {
+	return NewTraceSamplerOption().Build()
+}
+
+// CompositeSampler is the structure of the composite sampler instance.
+//
+// The composite sampler combines one or more samplers, and a log entry is
+// only sampled if all of the samplers sample it. The samplers are checked
+// in order, and the remaining samplers are not checked once a sampler
+// discards the log entry.
+type CompositeSampler struct {
+	never levelSet
+	samplers []Sampler
+}
+
+// Sample checks whether a given log entry needs to be sampled. It returns
+// true if needed, otherwise it returns false.
+func (s *CompositeSampler) Sample(entry *Entry) bool {
+	if s.never.contains(entry.Level) {
+		return true
+	}
+	for _, sampler := range s.samplers {
+		if !sampler.Sample(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// CompositeSamplerOption is a structure containing composite sampler
+// options.
+type CompositeSamplerOption struct {
+	// NeverSampleLevels represents the log levels whose log entries are
+	// never discarded by the composite sampler, regardless of the samplers
+	// that it combines. To allow log entries of all levels to be
+	// discarded, use an empty slice.
+	//
+	// If this option is not set, the default is ERROR and FATAL.
+	NeverSampleLevels []Level
+
+	// Samplers represents the samplers that are combined.
+	//
+	// If this option is not set, all log entries are sampled.
+	Samplers []Sampler
+}
+
+// Build builds and returns a composite sampler instance using the option
+// value.
+func (o *CompositeSamplerOption) Build() (*CompositeSampler, error) {
+	return &CompositeSampler {
+		never: newLevelSet(o.NeverSampleLevels),
+		samplers: append([]Sampler(nil), o.Samplers...),
+	}, nil
+}
+
+// UseNeverSampleLevels sets the NeverSampleLevels option using the given
+// log levels. If no log level is given, log entries of all levels may be
+// discarded.
+func (o *CompositeSamplerOption) UseNeverSampleLevels(levels ...Level) *CompositeSamplerOption {
+	if levels == nil {
+		levels = []Level { }
+	}
+	o.NeverSampleLevels = levels
+	return o
+}
+
+// UseSamplers appends the given one or more samplers to the option
+// Samplers.
+func (o *CompositeSamplerOption) UseSamplers(samplers ...Sampler) *CompositeSamplerOption {
+	o.Samplers = append(o.Samplers, samplers...)
+	return o
+}
+
+// NewCompositeSamplerOption creates and returns a composite sampler option
+// instance with default option values.
+func NewCompositeSamplerOption() *CompositeSamplerOption {
+	return &CompositeSamplerOption {
+		NeverSampleLevels: []Level { LevelError, LevelFatal },
+	}
+}
+
+// NewCompositeSampler creates and returns a composite sampler instance that
+// combines the given samplers, using default option values.
+func NewCompositeSampler(samplers ...Sampler) (*CompositeSampler, error) {
+	return NewCompositeSamplerOption().UseSamplers(samplers...).Build()
+}
+
+// Saturation is the public interface of components whose saturation can
+// be monitored by the adaptive sampler, for example, the queue of the
+// asynchronous exporter.
+type Saturation interface {
+	// Saturation returns the current saturation of the component, where
+	// 0 means idle and 1 means fully saturated.
+	Saturation() float64
+}
+
+// AdaptiveSampler is the structure of the adaptive sampler instance.
+//
+// The adaptive sampler monitors the backpressure of the outputting of log
+// entries and dynamically tightens sampling when it is saturated. The
+// backpressure is measured by the write latency of synchronizers wrapped
+// by the Measure function, and the saturation of the given components
+// (for example, asynchronous exporters).
+//
+// Sampling is adjusted once per tick: while the moving average of the
+// write latency exceeds the target latency or any component exceeds the
+// target saturation, the thinning factor is doubled (up to the maximum
+// thinning factor), otherwise it is halved until full logging is restored.
+// With a thinning factor of N, one of every N log entries whose level is
+// included in the span is sampled, and other log entries are discarded.
+//
+// The API provided by the adaptive sampler is thread-safe.
+type AdaptiveSampler struct {
+	span LevelSpan
+	never levelSet
+	tick int64
+	targetLatency int64
+	targetSaturation float64
+	maxThinning uint64
+	saturations []Saturation
+
+	latency int64
+	thinning uint64
+	count uint64
+	after int64
+}
+
+// Sample checks whether a given log entry needs to be sampled. It returns
+// true if needed, otherwise it returns false.
+func (s *AdaptiveSampler) Sample(entry *Entry) bool {
+	if s.never.contains(entry.Level) || !s.span.Contains(entry.Level) {
+		return true
+	}
+	clock := entry.Time.UnixNano()
+	after := atomic.LoadInt64(&s.after)
+	if after <= clock && atomic.CompareAndSwapInt64(&s.after, after,
+		clock + s.tick) {
+		s.adjust()
+	}
+	thinning := atomic.LoadUint64(&s.thinning)
+	if thinning <= 1 {
+		return true
+	}
+	return atomic.AddUint64(&s.count, 1) % thinning == 0
+}
+
+// adjust doubles or halves the thinning factor according to the current
+// backpressure. For details, please refer to the comment section of the
+// AdaptiveSampler structure.
+func (s *AdaptiveSampler) adjust() {
+	thinning := atomic.LoadUint64(&s.thinning)
+	if s.pressured() {
+		if thinning * 2 <= s.maxThinning {
+			atomic.StoreUint64(&s.thinning, thinning * 2)
+		}
+		return
+	}
+	if thinning > 1 {
+		atomic.StoreUint64(&s.thinning, thinning / 2)
+	}
+}
+
+// pressured checks whether the outputting of log entries is currently
+// saturated.
+func (s *AdaptiveSampler) pressured() bool {
+	if atomic.LoadInt64(&s.latency) > s.targetLatency {
+		return true
+	}
+	for _, saturation := range s.saturations {
+		if saturation.Saturation() > s.targetSaturation {
+			return true
+		}
+	}
+	return false
+}
+
+// Observe updates the moving average of the write latency with the given
+// latency of a write. It is called by the synchronizers wrapped by the
+// Measure function, and can also be called by the application to report
+// the latency of other outputs.
+func (s *AdaptiveSampler) Observe(latency time.Duration) {
+	for {
+		average := atomic.LoadInt64(&s.latency)
+		// The moving average gives each write a weight of 1/8.
+		updated := average + (int64(latency) - average) / 8
+		if atomic.CompareAndSwapInt64(&s.latency, average, updated) {
+			return
+		}
+	}
+}
+
+// Thinning returns the current thinning factor of the adaptive sampler.
+// For details, please refer to the comment section of the AdaptiveSampler
+// structure.
+func (s *AdaptiveSampler) Thinning() uint64 {
+	return atomic.LoadUint64(&s.thinning)
+}
+
+// Measure wraps the given synchronizer so that the latency of each write
+// is observed by the adaptive sampler, and then returns the wrapped
+// synchronizer.
+func (s *AdaptiveSampler) Measure(syncer Syncer) Syncer {
+	return &measuredSyncer {
+		Syncer: syncer,
+		sampler: s,
+	}
+}
+
+// measuredSyncer is the structure of a synchronizer whose write latency
+// is observed by an adaptive sampler.
+type measuredSyncer struct {
+	Syncer
+	sampler *AdaptiveSampler
+}
+
+// Write writes the data of a given buffer slice to the wrapped
+// synchronizer, and observes the latency of the write.
+//
+// Finally, it returns the number of bytes actually written and any
+// errors encountered.
+func (s *measuredSyncer) Write(buffer []byte) (int, error) {
+	start := time.Now()
+	size, err := s.Syncer.Write(buffer)
+	s.sampler.Observe(time.Since(start))
+	return size, err
+}
+
+// AdaptiveSamplerOption is a structure containing adaptive sampler options.
+type AdaptiveSamplerOption struct {
+	// Span represents the log level span for which sampling strategy
+	// needs to be applied. If the level of the log entry is not included
+	// in the span, the output is sampled.
+	//
+	// If this option is not set, the default is DEBUG to WARNING.
+	Span LevelSpan
+
+	// NeverSampleLevels represents the log levels whose log entries are
+	// never discarded by the sampler, regardless of the other options, so
+	// that critical log entries cannot be dropped by a configuration
+	// mistake. To allow log entries of all levels to be discarded, use an
+	// empty slice.
+	//
+	// If this option is not set, the default is ERROR and FATAL.
+	NeverSampleLevels []Level
+
+	// Tick represents the period at which the thinning factor is
+	// adjusted.
+	//
+	// If this option is not set, the default is 1 second.
+	Tick time.Duration
+
+	// TargetLatency represents the moving average of the write latency
+	// above which the outputting is considered saturated.
+	//
+	// If this option is not set, the default is 10 milliseconds.
+	TargetLatency time.Duration
+
+	// TargetSaturation represents the saturation of any component above
+	// which the outputting is considered saturated.
+	//
+	// If this option is not set, the default is 0.8.
+	TargetSaturation float64
+
+	// MaxThinning represents the maximum thinning factor, which means that
+	// at least one of every MaxThinning log entries is sampled, even if
+	// the outputting is saturated.
+	//
+	// If this option is not set, the default is 64.
+	MaxThinning uint64
+
+	// Saturations represents the components whose saturation is monitored.
+	// For details, please refer to the comment section of the Saturation
+	// interface.
+	//
+	// If this option is not set, no component is monitored.
+	Saturations []Saturation
+}
+
+// Validate checks whether the values of the options are valid, and returns
+// an error describing the first invalid value.
+func (o *AdaptiveSamplerOption) Validate() error {
+	return validateSampling(o.Span, o.Tick)
+}
+
+// Build builds and returns an adaptive sampler instance using the option
+// value. If the option value is invalid, it returns the error of the
+// Validate function.
+func (o *AdaptiveSamplerOption) Build() (*AdaptiveSampler, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	return &AdaptiveSampler {
+		span: o.Span,
+		never: newLevelSet(o.NeverSampleLevels),
+		tick: int64(o.Tick),
+		targetLatency: int64(o.TargetLatency),
+		targetSaturation: o.TargetSaturation,
+		maxThinning: o.MaxThinning,
+		saturations: o.Saturations,
+		thinning: 1,
+	}, nil
+}
+
+// UseSpan sets the Span option using the given log level span.
+func (o *AdaptiveSamplerOption) UseSpan(start, end Level) *AdaptiveSamplerOption {
+	o.Span = LevelSpan {
+		Start: start,
+		End: end,
+	}
+	return o
+}
+
+// UseNeverSampleLevels sets the NeverSampleLevels option using the given
+// log levels. If no log level is given, log entries of all levels may be
+// discarded.
+func (o *AdaptiveSamplerOption) UseNeverSampleLevels(levels ...Level) *AdaptiveSamplerOption {
+	if levels == nil {
+		levels = []Level { }
+	}
+	o.NeverSampleLevels = levels
+	return o
+}
+
+// UseTick sets the Tick option using the given adjustment period value.
+func (o *AdaptiveSamplerOption) UseTick(tick time.Duration) *AdaptiveSamplerOption {
+	o.Tick = tick
+	return o
+}
+
+// UseTargets sets the options TargetLatency and TargetSaturation using the
+// given values.
+func (o *AdaptiveSamplerOption) UseTargets(latency time.Duration,
+	saturation float64) *AdaptiveSamplerOption {
+	o.TargetLatency = latency
+	o.TargetSaturation = saturation
+	return o
+}
+
+// UseMaxThinning sets the MaxThinning option using the given maximum
+// thinning factor.
+func (o *AdaptiveSamplerOption) UseMaxThinning(thinning uint64) *AdaptiveSamplerOption {
+	o.MaxThinning = thinning
+	return o
+}
+
+// UseSaturations appends the given one or more components to the option
+// Saturations.
+func (o *AdaptiveSamplerOption) UseSaturations(saturations ...Saturation) *AdaptiveSamplerOption {
+	o.Saturations = append(o.Saturations, saturations...)
+	return o
+}
+
+// NewAdaptiveSamplerOption creates and returns an adaptive sampler option
+// instance with default option values.
+func NewAdaptiveSamplerOption() *AdaptiveSamplerOption {
+	return &AdaptiveSamplerOption {
+		Span: LevelSpan {
+			Start: LevelDebug,
+			End: LevelWarning,
+		},
+		NeverSampleLevels: []Level { LevelError, LevelFatal },
+		Tick: time.Second,
+		TargetLatency: time.Millisecond * 10,
+		TargetSaturation: 0.8,
+		MaxThinning: 64,
+	}
+}
+
+// NewAdaptiveSampler creates and returns an adaptive sampler instance using
+// default option values.
+func NewAdaptiveSampler() (*AdaptiveSampler, error) {
+	return NewAdaptiveSamplerOption().Build()
+}
@@ -23,6 +23,7 @@
 package santa
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -103,3 +104,275 @@ func TestTextSamplerSample(t *testing.T) {
 		}
 	}
 }
+
+type testSaturation struct {
+	value float64
+}
+
+func (s *testSaturation) Saturation() float64 {
+	return s.value
+}
+
+func TestAdaptiveSamplerOption(t *testing.T) {
+	saturation := &testSaturation { }
+	option := NewAdaptiveSamplerOption()
+
+	option.UseSpan(LevelInfo, LevelInfo)
+	option.UseTick(time.Second * 2)
+	option.UseTargets(time.Millisecond, 0.5)
+	option.UseMaxThinning(8)
+	option.UseSaturations(saturation)
+
+	assert.Equal(t, LevelInfo, option.Span.End, "Unexpected option value")
+	assert.Equal(t, time.Second * 2, option.Tick, "Unexpected option value")
+	assert.Equal(t, time.Millisecond, option.TargetLatency,
+		"Unexpected option value")
+	assert.Equal(t, 0.5, option.TargetSaturation, "Unexpected option value")
+	assert.Equal(t, uint64(8), option.MaxThinning, "Unexpected option value")
+	assert.Len(t, option.Saturations, 1, "Unexpected option value")
+
+	sampler, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.Equal(t, uint64(1), sampler.Thinning(), "Unexpected instance error")
+}
+
+func TestAdaptiveSamplerSample(t *testing.T) {
+	saturation := &testSaturation { }
+	sampler, err := NewAdaptiveSamplerOption().UseMaxThinning(4).
+		UseSaturations(saturation).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	now := time.Unix(0, 0)
+	sample := func() int {
+		sampled := 0
+		for index := 0; index < 8; index++ {
+			if sampler.Sample(&Entry { Time: now, Level: LevelInfo }) {
+				sampled++
+			}
+		}
+		now = now.Add(time.Second)
+		return sampled
+	}
+	assert.Equal(t, 8, sample(), "Unexpected sample result")
+
+	// The sampling is tightened while the outputting is saturated.
+	saturation.value = 1
+	assert.Equal(t, 4, sample(), "Unexpected sample result")
+	assert.Equal(t, 2, sample(), "Unexpected sample result")
+	assert.Equal(t, 2, sample(), "Unexpected sample result")
+	assert.Equal(t, uint64(4), sampler.Thinning(), "Unexpected thinning")
+	assert.True(t, sampler.Sample(&Entry { Time: now, Level: LevelError }),
+		"Unexpected sample result")
+
+	// Full logging is restored when the pressure subsides.
+	saturation.value = 0
+	sample()
+	sample()
+	assert.Equal(t, uint64(1), sampler.Thinning(), "Unexpected thinning")
+
+	// The write latency of measured synchronizers is observed.
+	for index := 0; index < 64; index++ {
+		sampler.Observe(time.Second)
+	}
+	sample()
+	assert.Equal(t, uint64(2), sampler.Thinning(), "Unexpected thinning")
+
+	syncer := sampler.Measure(&testWriteSyncer { })
+	for index := 0; index < 256; index++ {
+		_, err = syncer.Write([]byte("Hello Test!"))
+		assert.NoError(t, err, "Unexpected write error")
+	}
+	sample()
+	assert.Equal(t, uint64(1), sampler.Thinning(), "Unexpected thinning")
+}
+
+func TestFieldSamplerSample(t *testing.T) {
+	sampler, err := NewFieldSamplerOption("userID").UseFirst(2, 3).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	now := time.Now()
+	sample := func(user string) bool {
+		return sampler.Sample(&Entry {
+			Time: now,
+			Level: LevelInfo,
+			Message: StructMessage {
+				Text: "Hello Test!",
+				Fields: ElementObject { String("userID", user) },
+			},
+		})
+	}
+	// The hot group is limited, other groups are not affected.
+	sampled := 0
+	for index := 0; index < 10; index++ {
+		if sample("alice") {
+			sampled++
+		}
+	}
+	assert.Equal(t, 5, sampled, "Unexpected sample result")
+	assert.True(t, sample("bob"), "Unexpected sample result")
+
+	// Labels are used if the message does not have the field.
+	for index := 0; index < 10; index++ {
+		sampler.Sample(&Entry {
+			Time: now,
+			Level: LevelInfo,
+			Labels: NewSerializedLabels(NewLabel("userID", "carol")),
+		})
+	}
+	assert.False(t, sampler.Sample(&Entry {
+		Time: now,
+		Level: LevelInfo,
+		Labels: NewSerializedLabels(NewLabel("userID", "carol")),
+	}), "Unexpected sample result")
+
+	// Log entries without the field are always sampled.
+	for index := 0; index < 10; index++ {
+		assert.True(t, sampler.Sample(&Entry { Time: now, Level: LevelInfo }),
+			"Unexpected sample result")
+	}
+}
+
+func TestTraceSamplerSample(t *testing.T) {
+	sampler, err := NewTraceSamplerOption().UseRatio(0.5, 0).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	entry := func(level Level, fields ...Field) *Entry {
+		return &Entry {
+			Level: level,
+			Message: StructMessage {
+				Text: "Hello Test!",
+				Fields: fields,
+			},
+		}
+	}
+	// The last 8 bytes of the trace IDs are below and above the bound.
+	low := "4bf92f3577b34da60000000000000001"
+	high := "4bf92f3577b34da6ffffffffffffffff"
+
+	assert.True(t, sampler.Sample(entry(LevelInfo, String("traceID", low))),
+		"Unexpected sample result")
+	assert.False(t, sampler.Sample(entry(LevelInfo,
+		String("traceID", high))), "Unexpected sample result")
+	assert.True(t, sampler.Sample(entry(LevelError,
+		String("traceID", high))), "Unexpected sample result")
+
+	// The sampled field takes precedence over the ratio.
+	assert.True(t, sampler.Sample(entry(LevelInfo, String("traceID", high),
+		String("traceSampled", "01"))), "Unexpected sample result")
+	assert.False(t, sampler.Sample(entry(LevelInfo, String("traceID", low),
+		Boolean("traceSampled", false))), "Unexpected sample result")
+
+	// Log entries without a trace ID are sampled unless dropped.
+	assert.True(t, sampler.Sample(entry(LevelInfo)),
+		"Unexpected sample result")
+	sampler, err = NewTraceSamplerOption().UseDropUntraced().
+		UseRatio(0, 1).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.False(t, sampler.Sample(entry(LevelInfo)),
+		"Unexpected sample result")
+	assert.True(t, sampler.Sample(entry(LevelInfo, String("traceID", high))),
+		"Unexpected sample result")
+}
+
+type testDiscardSampler struct { }
+
+func (testDiscardSampler) Sample(entry *Entry) bool {
+	return false
+}
+
+func TestSamplerNeverSampleLevels(t *testing.T) {
+	option := NewTextSamplerOption().UseSpan(LevelInfo, LevelFatal).
+		UseFirst(1, 1000)
+	assert.Equal(t, []Level { LevelError, LevelFatal },
+		option.NeverSampleLevels, "Unexpected option value")
+
+	sample := func(sampler Sampler, level Level) int {
+		sampled := 0
+		for index := 0; index < 10; index++ {
+			if sampler.Sample(&Entry {
+				Time: time.Unix(0, 0),
+				Level: level,
+				Message: StringMessage("Hello Test!"),
+			}) {
+				sampled++
+			}
+		}
+		return sampled
+	}
+	sampler, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.Equal(t, 10, sample(sampler, LevelError), "Unexpected sample result")
+	assert.Equal(t, 2, sample(sampler, LevelWarning),
+		"Unexpected sample result")
+
+	sampler, err = option.UseNeverSampleLevels().Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.Equal(t, 2, sample(sampler, LevelError), "Unexpected sample result")
+
+	// A nil option value uses the default levels.
+	option.NeverSampleLevels = nil
+	sampler, err = option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.Equal(t, 10, sample(sampler, LevelFatal), "Unexpected sample result")
+}
+
+func TestCompositeSamplerSample(t *testing.T) {
+	sampler, err := NewCompositeSampler(&TextSampler { },
+		testDiscardSampler { })
+	assert.NoError(t, err, "Unexpected create error")
+
+	assert.False(t, sampler.Sample(&Entry { Level: LevelInfo }),
+		"Unexpected sample result")
+	assert.True(t, sampler.Sample(&Entry { Level: LevelError }),
+		"Unexpected sample result")
+
+	sampler, err = NewCompositeSamplerOption().UseNeverSampleLevels().
+		UseSamplers(testDiscardSampler { }).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.False(t, sampler.Sample(&Entry { Level: LevelFatal }),
+		"Unexpected sample result")
+
+	sampler, err = NewCompositeSampler()
+	assert.NoError(t, err, "Unexpected create error")
+	assert.True(t, sampler.Sample(&Entry { Level: LevelDebug }),
+		"Unexpected sample result")
+}
+
+func TestSamplerOptionValidate(t *testing.T) {
+	assert.NoError(t, NewTextSamplerOption().Validate(),
+		"Unexpected validate error")
+	_, err := NewTextSamplerOption().UseTick(0).Build()
+	assert.True(t, errors.Is(err, ErrInvalidTick), "Unexpected build error")
+	_, err = NewTextSamplerOption().UseCounters(0).Build()
+	assert.Equal(t, ErrInvalidCounters, err, "Unexpected build error")
+	_, err = NewTextSamplerOption().UseSpan(LevelWarning, LevelDebug).Build()
+	assert.True(t, errors.Is(err, ErrInvalidSpan), "Unexpected build error")
+
+	option := NewFieldSamplerOption("user")
+	option.Counters = 0
+	_, err = option.Build()
+	assert.Equal(t, ErrInvalidCounters, err, "Unexpected build error")
+
+	_, err = NewTraceSamplerOption().UseSpan(LevelFatal, LevelDebug).Build()
+	assert.True(t, errors.Is(err, ErrInvalidSpan), "Unexpected build error")
+
+	adaptive := NewAdaptiveSamplerOption()
+	adaptive.Tick = -time.Second
+	_, err = adaptive.Build()
+	assert.True(t, errors.Is(err, ErrInvalidTick), "Unexpected build error")
+}
+
+func TestSamplerOptionClone(t *testing.T) {
+	option := NewTextSamplerOption()
+	clone := option.Clone()
+	clone.NeverSampleLevels[0] = LevelDebug
+	assert.Equal(t, LevelError, option.NeverSampleLevels[0],
+		"Unexpected never sample levels")
+
+	option.NeverSampleLevels = nil
+	assert.Nil(t, option.Clone().NeverSampleLevels,
+		"Unexpected never sample levels")
+	option.UseNeverSampleLevels()
+	assert.NotNil(t, option.Clone().NeverSampleLevels,
+		"Unexpected never sample levels")
+}
@@ -0,0 +1,100 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package santaecho provides middleware for the Echo web framework that
+// replace its logger and recovery middleware with a structured logger.
+// For example:
+//
+//	logger, _ := santa.NewStruct()
+//	e := echo.New()
+//	e.Use(santaecho.Logger(logger), santaecho.Recovery(logger))
+//
+// The log entries have the same fields as the access log middleware of
+// the santahttp package. This package is a separate module, so that the
+// santa module does not depend on Echo.
+package santaecho
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nobody-night/santa"
+	"github.com/nobody-night/santa/santahttp"
+)
+
+// Logger returns a middleware that prints a structured log entry for each
+// HTTP request with the given logger, after the subsequent handlers are
+// called. If the handlers return an error, the error is handled by the
+// error handler of Echo before printing, so that the response status is
+// known, and the error is printed as the value of the error field. The
+// route is the path of the matched route, and the level is determined by
+// the santahttp.StatusLevel function.
+func Logger(logger *santa.StructLogger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+			request := c.Request()
+			response := c.Response()
+			fields := santahttp.AccessFields(request, response.Status,
+				response.Size, time.Since(start), c.Path(),
+				santahttp.TraceID(request))
+			if err != nil {
+				fields = append(fields, santa.Error("error", err))
+			}
+			_ = logger.Prints(santahttp.StatusLevel(response.Status),
+				"HTTP request handled.", fields...)
+			return err
+		}
+	}
+}
+
+// Recovery returns a middleware that recovers from the panics of the
+// subsequent handlers, prints the panic value and stack trace with the
+// given logger at the ERROR level, and then returns an error with the
+// status 500. The http.ErrAbortHandler panics are not printed or
+// recovered.
+func Recovery(logger *santa.StructLogger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+				_ = logger.Errors("HTTP handler panicked.",
+					santahttp.PanicFields(c.Request(), recovered)...)
+				err = echo.NewHTTPError(http.StatusInternalServerError).
+					SetInternal(fmt.Errorf("panic: %v", recovered))
+			}()
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santaecho
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nobody-night/santa"
+	"github.com/nobody-night/santa/santatest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEcho(t *testing.T) (*echo.Echo, *santatest.ObserverExporter) {
+	logger, observer := santatest.NewTestLogger(t)
+	e := echo.New()
+	e.Use(Logger(logger), Recovery(logger))
+	e.GET("/users/:id", func(c echo.Context) error {
+		return c.String(http.StatusOK, "user")
+	})
+	e.GET("/missing", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusNotFound)
+	})
+	e.GET("/panic", func(c echo.Context) error {
+		panic("test")
+	})
+	e.GET("/abort", func(c echo.Context) error {
+		panic(http.ErrAbortHandler)
+	})
+	return e, observer
+}
+
+func TestLogger(t *testing.T) {
+	e, observer := newTestEcho(t)
+	request := httptest.NewRequest("GET", "/users/1", nil)
+	request.Header.Set("X-Request-Id", "d4b0")
+	recorder := httptest.NewRecorder()
+	e.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code, "Unexpected response status")
+
+	entries := observer.TakeAll()
+	assert.Equal(t, []string { "HTTP request handled." }, entries.Messages(),
+		"Unexpected record result")
+	assert.Equal(t, santa.LevelInfo, entries[0].Level,
+		"Unexpected record result")
+	assert.Equal(t, 1, entries.FilterField(santa.Int("status", 200)).
+		FilterField(santa.Int("responseSize", 4)).
+		FilterField(santa.String("route", "/users/:id")).
+		FilterField(santa.String("traceId", "d4b0")).Len(),
+		"Unexpected record result")
+
+	recorder = httptest.NewRecorder()
+	e.ServeHTTP(recorder, httptest.NewRequest("GET", "/missing", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code,
+		"Unexpected response status")
+	entries = observer.TakeAll()
+	assert.Equal(t, santa.LevelWarning, entries[0].Level,
+		"Unexpected record result")
+	assert.Equal(t, 1, entries.FilterField(santa.Int("status", 404)).Len(),
+		"Unexpected record result")
+}
+
+func TestRecovery(t *testing.T) {
+	e, observer := newTestEcho(t)
+	recorder := httptest.NewRecorder()
+	e.ServeHTTP(recorder, httptest.NewRequest("GET", "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code,
+		"Unexpected response status")
+
+	entries := observer.TakeAll()
+	assert.Equal(t, []string { "HTTP handler panicked.",
+		"HTTP request handled." }, entries.Messages(),
+		"Unexpected record result")
+	assert.Equal(t, 2, entries.FilterLevel(santa.LevelError).Len(),
+		"Unexpected record result")
+	assert.Equal(t, 1, entries.FilterField(santa.String("panic", "test")).
+		Len(), "Unexpected record result")
+	fields := santatest.MessageFields(entries[0].Message)
+	assert.Equal(t, "stack", fields[1].Name, "Unexpected record result")
+	assert.True(t, strings.Contains(fields[1].String, "santaecho"),
+		"Unexpected record result")
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		e.ServeHTTP(httptest.NewRecorder(),
+			httptest.NewRequest("GET", "/abort", nil))
+	}, "Unexpected recovery result")
+	assert.Equal(t, 0, observer.Len(), "Unexpected record result")
+}
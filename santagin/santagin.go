@@ -0,0 +1,90 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package santagin provides middleware for the Gin web framework that
+// replace its default logger and recovery middleware with a structured
+// logger. For example:
+//
+//	logger, _ := santa.NewStruct()
+//	engine := gin.New()
+//	engine.Use(santagin.Logger(logger), santagin.Recovery(logger))
+//
+// The log entries have the same fields as the access log middleware of
+// the santahttp package. This package is a separate module, so that the
+// santa module does not depend on Gin.
+package santagin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nobody-night/santa"
+	"github.com/nobody-night/santa/santahttp"
+)
+
+// Logger returns a middleware that prints a structured log entry for each
+// HTTP request with the given logger, after the subsequent handlers are
+// called. The route is the full path of the matched route, and the level
+// is determined by the santahttp.StatusLevel function. The private errors
+// of the Gin context are printed as the value of the errors field.
+func Logger(logger *santa.StructLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		status := c.Writer.Status()
+		size := int64(c.Writer.Size())
+		if size < 0 {
+			size = 0
+		}
+		fields := santahttp.AccessFields(c.Request, status, size,
+			time.Since(start), c.FullPath(), santahttp.TraceID(c.Request))
+		if errors := c.Errors.ByType(gin.ErrorTypePrivate); len(errors) > 0 {
+			fields = append(fields, santa.Strings("errors", errors.Errors()))
+		}
+		_ = logger.Prints(santahttp.StatusLevel(status),
+			"HTTP request handled.", fields...)
+	}
+}
+
+// Recovery returns a middleware that recovers from the panics of the
+// subsequent handlers, prints the panic value and stack trace with the
+// given logger at the ERROR level, and then aborts the request with the
+// status 500. The http.ErrAbortHandler panics are not printed or
+// recovered.
+func Recovery(logger *santa.StructLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			_ = logger.Errors("HTTP handler panicked.",
+				santahttp.PanicFields(c.Request, recovered)...)
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,103 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santagin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nobody-night/santa"
+	"github.com/nobody-night/santa/santatest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEngine(t *testing.T) (*gin.Engine, *santatest.ObserverExporter) {
+	gin.SetMode(gin.TestMode)
+	logger, observer := santatest.NewTestLogger(t)
+	engine := gin.New()
+	engine.Use(Logger(logger), Recovery(logger))
+	engine.GET("/users/:id", func(c *gin.Context) {
+		_ = c.Error(errors.New("test"))
+		c.String(http.StatusOK, "user")
+	})
+	engine.GET("/panic", func(c *gin.Context) {
+		panic("test")
+	})
+	engine.GET("/abort", func(c *gin.Context) {
+		panic(http.ErrAbortHandler)
+	})
+	return engine, observer
+}
+
+func TestLogger(t *testing.T) {
+	engine, observer := newTestEngine(t)
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest("GET", "/users/1", nil)
+	request.Header.Set("X-Request-Id", "d4b0")
+	engine.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code, "Unexpected response status")
+
+	entries := observer.All()
+	assert.Equal(t, []string { "HTTP request handled." }, entries.Messages(),
+		"Unexpected record result")
+	assert.Equal(t, santa.LevelInfo, entries[0].Level,
+		"Unexpected record result")
+	assert.Equal(t, 1, entries.FilterField(santa.Int("status", 200)).
+		FilterField(santa.Int("responseSize", 4)).
+		FilterField(santa.String("route", "/users/:id")).
+		FilterField(santa.String("traceId", "d4b0")).
+		FilterField(santa.Strings("errors", []string { "test" })).Len(),
+		"Unexpected record result")
+}
+
+func TestRecovery(t *testing.T) {
+	engine, observer := newTestEngine(t)
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest("GET", "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code,
+		"Unexpected response status")
+
+	entries := observer.TakeAll()
+	assert.Equal(t, []string { "HTTP handler panicked.",
+		"HTTP request handled." }, entries.Messages(),
+		"Unexpected record result")
+	assert.Equal(t, 2, entries.FilterLevel(santa.LevelError).Len(),
+		"Unexpected record result")
+	fields := santatest.MessageFields(entries[0].Message)
+	assert.Equal(t, 1, entries.FilterField(santa.String("panic", "test")).
+		Len(), "Unexpected record result")
+	assert.Equal(t, "stack", fields[1].Name, "Unexpected record result")
+	assert.True(t, strings.Contains(fields[1].String, "santagin"),
+		"Unexpected record result")
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		engine.ServeHTTP(httptest.NewRecorder(),
+			httptest.NewRequest("GET", "/abort", nil))
+	}, "Unexpected recovery result")
+	assert.Equal(t, 0, observer.All().FilterMessage(
+		"HTTP handler panicked.").Len(), "Unexpected record result")
+}
@@ -0,0 +1,20 @@
+module github.com/nobody-night/santa/santagorm
+
+go 1.18
+
+replace github.com/nobody-night/santa => ../
+
+require (
+	github.com/nobody-night/santa v0.0.0
+	github.com/stretchr/testify v1.7.1
+	gorm.io/gorm v1.31.2
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/jinzhu/now v1.1.5 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/text v0.20.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,201 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package santagorm provides an adapter that implements the logger
+// interface of GORM with a structured logger. For example:
+//
+//	logger, _ := santa.NewStruct()
+//	db, _ := gorm.Open(dialector, &gorm.Config {
+//		Logger: santagorm.New(logger),
+//	})
+//
+// The log entries of SQL queries are printed with the query logger of
+// the santasql package. This package is a separate module, so that the
+// santa module does not depend on GORM.
+package santagorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nobody-night/santa"
+	"github.com/nobody-night/santa/santasql"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+var (
+	// ErrNilLogger is an error indicating that the logger of the adapter
+	// is not provided.
+	ErrNilLogger = errors.New("the logger is nil")
+)
+
+// Option is a structure that contains options for the adapter.
+type Option struct {
+	// Logger is the structured logger used to print the log entries. It
+	// must be provided.
+	Logger *santa.StructLogger
+
+	// SlowThreshold is the minimum duration of slow queries. If the value
+	// is negative, no query is considered slow.
+	//
+	// If not provided, the default value is 200 milliseconds.
+	SlowThreshold time.Duration
+
+	// LogLevel is the log level of GORM. At the Error level, only failed
+	// queries are printed; at the Warn level, slow queries are also
+	// printed; and at the Info level, all queries are printed.
+	//
+	// If not provided, the default value is gormlogger.Warn.
+	LogLevel gormlogger.LogLevel
+
+	// IgnoreRecordNotFound indicates whether to ignore the
+	// gorm.ErrRecordNotFound error of queries.
+	//
+	// If not provided, the default value is false.
+	IgnoreRecordNotFound bool
+}
+
+// UseSlowThreshold uses the given duration as the minimum duration of
+// slow queries. Then return to the option instance itself.
+func (o *Option) UseSlowThreshold(threshold time.Duration) *Option {
+	o.SlowThreshold = threshold
+	return o
+}
+
+// UseLogLevel uses the given log level of GORM. Then return to the option
+// instance itself.
+func (o *Option) UseLogLevel(level gormlogger.LogLevel) *Option {
+	o.LogLevel = level
+	return o
+}
+
+// UseIgnoreRecordNotFound ignores the gorm.ErrRecordNotFound error of
+// queries. Then return to the option instance itself.
+func (o *Option) UseIgnoreRecordNotFound() *Option {
+	o.IgnoreRecordNotFound = true
+	return o
+}
+
+// Build builds and returns an adapter instance. If the logger is not
+// provided, it returns ErrNilLogger.
+func (o *Option) Build() (*Logger, error) {
+	if o.Logger == nil {
+		return nil, ErrNilLogger
+	}
+	query, err := santasql.NewOption(o.Logger).
+		UseSlowThreshold(o.SlowThreshold).
+		UseLevel(santa.LevelInfo).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	return &Logger {
+		logger: o.Logger,
+		query: query,
+		level: o.LogLevel,
+		ignoreNotFound: o.IgnoreRecordNotFound,
+	}, nil
+}
+
+// NewOption creates and returns an option instance with the given logger
+// and default values.
+func NewOption(logger *santa.StructLogger) *Option {
+	return &Option {
+		Logger: logger,
+		SlowThreshold: 200 * time.Millisecond,
+		LogLevel: gormlogger.Warn,
+	}
+}
+
+// New creates and returns an adapter instance with the given logger and
+// default options. If the given logger is nil, it panics.
+func New(logger *santa.StructLogger) *Logger {
+	adapter, err := NewOption(logger).Build()
+	if err != nil {
+		panic("santagorm: " + err.Error())
+	}
+	return adapter
+}
+
+// Logger is the structure of the adapter that implements the
+// gormlogger.Interface interface with a structured logger.
+type Logger struct {
+	logger *santa.StructLogger
+	query *santasql.QueryLogger
+	level gormlogger.LogLevel
+	ignoreNotFound bool
+}
+
+// LogMode returns a copy of the adapter with the given log level.
+func (l *Logger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	instance := *l
+	instance.level = level
+	return &instance
+}
+
+// Info prints the given template and arguments at the INFO level in the
+// manner of fmt.Sprintf, if the log level is Info.
+func (l *Logger) Info(ctx context.Context, template string, args ...interface { }) {
+	if l.level >= gormlogger.Info {
+		_ = l.logger.Infos(fmt.Sprintf(template, args...))
+	}
+}
+
+// Warn prints the given template and arguments at the WARNING level in
+// the manner of fmt.Sprintf, if the log level is Warn or higher.
+func (l *Logger) Warn(ctx context.Context, template string, args ...interface { }) {
+	if l.level >= gormlogger.Warn {
+		_ = l.logger.Warnings(fmt.Sprintf(template, args...))
+	}
+}
+
+// Error prints the given template and arguments at the ERROR level in
+// the manner of fmt.Sprintf, if the log level is Error or higher.
+func (l *Logger) Error(ctx context.Context, template string, args ...interface { }) {
+	if l.level >= gormlogger.Error {
+		_ = l.logger.Errors(fmt.Sprintf(template, args...))
+	}
+}
+
+// Trace prints the log entry of a SQL query that began at the given time,
+// according to the log level. The given function returns the query text
+// and the number of affected rows.
+func (l *Logger) Trace(ctx context.Context, begin time.Time,
+	fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+	if l.ignoreNotFound && errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	duration := time.Since(begin)
+	switch {
+	case err != nil && l.level >= gormlogger.Error,
+		l.query.Slow(duration) && l.level >= gormlogger.Warn,
+		l.level >= gormlogger.Info:
+		query, rows := fc()
+		l.query.Print(query, duration, rows, err)
+	}
+}
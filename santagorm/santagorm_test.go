@@ -0,0 +1,103 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santagorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nobody-night/santa"
+	"github.com/nobody-night/santa/santatest"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+var _ gormlogger.Interface = (*Logger)(nil)
+
+func testQuery() (string, int64) {
+	return "SELECT * FROM users", 2
+}
+
+func TestLogger(t *testing.T) {
+	_, err := NewOption(nil).Build()
+	assert.Equal(t, ErrNilLogger, err, "Unexpected build error")
+	assert.Panics(t, func() {
+		New(nil)
+	}, "Unexpected adapter result")
+
+	logger, observer := santatest.NewTestLogger(t)
+	adapter := New(logger)
+	ctx := context.Background()
+
+	adapter.Info(ctx, "info %d", 1)
+	adapter.Warn(ctx, "warn %d", 1)
+	adapter.Error(ctx, "error %d", 1)
+	adapter.Trace(ctx, time.Now(), testQuery, nil)
+	adapter.Trace(ctx, time.Now().Add(-time.Second), testQuery, nil)
+	adapter.Trace(ctx, time.Now(), testQuery, gorm.ErrRecordNotFound)
+
+	entries := observer.TakeAll()
+	assert.Equal(t, []string { "warn 1", "error 1",
+		"Slow SQL query executed.", "SQL query failed." }, entries.Messages(),
+		"Unexpected record result")
+	assert.Equal(t, 2, entries.FilterField(santa.String("query",
+		"SELECT * FROM users")).FilterField(santa.Int("rows", 2)).Len(),
+		"Unexpected record result")
+
+	adapter.LogMode(gormlogger.Silent).Error(ctx, "error")
+	adapter.LogMode(gormlogger.Silent).Trace(ctx, time.Now(), testQuery,
+		errors.New("test"))
+	assert.Equal(t, 0, observer.Len(), "Unexpected record result")
+
+	info := adapter.LogMode(gormlogger.Info)
+	info.Info(ctx, "info")
+	info.Trace(ctx, time.Now(), testQuery, nil)
+	entries = observer.TakeAll()
+	assert.Equal(t, []string { "info", "SQL query executed." },
+		entries.Messages(), "Unexpected record result")
+	assert.Equal(t, 2, entries.FilterLevel(santa.LevelInfo).Len(),
+		"Unexpected record result")
+}
+
+func TestLoggerOption(t *testing.T) {
+	logger, observer := santatest.NewTestLogger(t)
+	adapter, err := NewOption(logger).
+		UseSlowThreshold(-1).
+		UseLogLevel(gormlogger.Error).
+		UseIgnoreRecordNotFound().
+		Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	ctx := context.Background()
+	adapter.Warn(ctx, "warn")
+	adapter.Trace(ctx, time.Now().Add(-time.Hour), testQuery, nil)
+	adapter.Trace(ctx, time.Now(), testQuery, gorm.ErrRecordNotFound)
+	assert.Equal(t, 0, observer.Len(), "Unexpected record result")
+
+	adapter.Trace(ctx, time.Now(), testQuery, errors.New("test"))
+	assert.Equal(t, []string { "SQL query failed." },
+		observer.All().Messages(), "Unexpected record result")
+}
@@ -0,0 +1,159 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santagrpc
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nobody-night/santa"
+)
+
+// exit is the function called by the Fatal methods of the LoggerV2
+// adapter, which can be replaced in unit tests.
+var exit = os.Exit
+
+// LoggerV2 is the structure of the adapter that implements the
+// grpclog.LoggerV2 interface with a structured logger.
+//
+// The log entries of the gRPC runtime are printed as structured messages
+// without fields, and the Fatal methods synchronize the logger and then
+// exit the application with the status code 1.
+type LoggerV2 struct {
+	logger *santa.StructLogger
+	verbosity int
+}
+
+// print prints a log entry with the given level and message text.
+func (l *LoggerV2) print(level santa.Level, text string) {
+	_ = l.logger.Prints(level, text)
+}
+
+// fatal prints a log entry with the FATAL level and the given message
+// text, synchronizes the logger, and then exits the application.
+func (l *LoggerV2) fatal(text string) {
+	_ = l.logger.Fatals(text)
+	_ = l.logger.Sync()
+	exit(1)
+}
+
+// Info prints the given arguments at the INFO level in the manner of
+// fmt.Sprint.
+func (l *LoggerV2) Info(args ...interface { }) {
+	l.print(santa.LevelInfo, fmt.Sprint(args...))
+}
+
+// Infoln prints the given arguments at the INFO level in the manner of
+// fmt.Sprintln, without the trailing newline.
+func (l *LoggerV2) Infoln(args ...interface { }) {
+	l.print(santa.LevelInfo, sprintln(args))
+}
+
+// Infof prints the given template and arguments at the INFO level in the
+// manner of fmt.Sprintf.
+func (l *LoggerV2) Infof(template string, args ...interface { }) {
+	l.print(santa.LevelInfo, fmt.Sprintf(template, args...))
+}
+
+// Warning prints the given arguments at the WARNING level in the manner
+// of fmt.Sprint.
+func (l *LoggerV2) Warning(args ...interface { }) {
+	l.print(santa.LevelWarning, fmt.Sprint(args...))
+}
+
+// Warningln prints the given arguments at the WARNING level in the manner
+// of fmt.Sprintln, without the trailing newline.
+func (l *LoggerV2) Warningln(args ...interface { }) {
+	l.print(santa.LevelWarning, sprintln(args))
+}
+
+// Warningf prints the given template and arguments at the WARNING level
+// in the manner of fmt.Sprintf.
+func (l *LoggerV2) Warningf(template string, args ...interface { }) {
+	l.print(santa.LevelWarning, fmt.Sprintf(template, args...))
+}
+
+// Error prints the given arguments at the ERROR level in the manner of
+// fmt.Sprint.
+func (l *LoggerV2) Error(args ...interface { }) {
+	l.print(santa.LevelError, fmt.Sprint(args...))
+}
+
+// Errorln prints the given arguments at the ERROR level in the manner of
+// fmt.Sprintln, without the trailing newline.
+func (l *LoggerV2) Errorln(args ...interface { }) {
+	l.print(santa.LevelError, sprintln(args))
+}
+
+// Errorf prints the given template and arguments at the ERROR level in
+// the manner of fmt.Sprintf.
+func (l *LoggerV2) Errorf(template string, args ...interface { }) {
+	l.print(santa.LevelError, fmt.Sprintf(template, args...))
+}
+
+// Fatal prints the given arguments at the FATAL level in the manner of
+// fmt.Sprint, and then exits the application.
+func (l *LoggerV2) Fatal(args ...interface { }) {
+	l.fatal(fmt.Sprint(args...))
+}
+
+// Fatalln prints the given arguments at the FATAL level in the manner of
+// fmt.Sprintln without the trailing newline, and then exits the
+// application.
+func (l *LoggerV2) Fatalln(args ...interface { }) {
+	l.fatal(sprintln(args))
+}
+
+// Fatalf prints the given template and arguments at the FATAL level in
+// the manner of fmt.Sprintf, and then exits the application.
+func (l *LoggerV2) Fatalf(template string, args ...interface { }) {
+	l.fatal(fmt.Sprintf(template, args...))
+}
+
+// V returns true if the given verbosity level is less than or equal to
+// the verbosity level of the adapter.
+func (l *LoggerV2) V(level int) bool {
+	return level <= l.verbosity
+}
+
+// NewLoggerV2 creates and returns a LoggerV2 adapter instance with the
+// given logger and the verbosity level 0.
+func NewLoggerV2(logger *santa.StructLogger) *LoggerV2 {
+	return NewLoggerV2Verbosity(logger, 0)
+}
+
+// NewLoggerV2Verbosity creates and returns a LoggerV2 adapter instance
+// with the given logger and verbosity level.
+func NewLoggerV2Verbosity(logger *santa.StructLogger, verbosity int) *LoggerV2 {
+	return &LoggerV2 {
+		logger: logger,
+		verbosity: verbosity,
+	}
+}
+
+// sprintln formats the given arguments in the manner of fmt.Sprintln, and
+// then returns the result without the trailing newline.
+func sprintln(args []interface { }) string {
+	text := fmt.Sprintln(args...)
+	return text[ : len(text) - 1]
+}
@@ -0,0 +1,84 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santagrpc
+
+import (
+	"os"
+	"testing"
+
+	"github.com/nobody-night/santa"
+	"github.com/nobody-night/santa/santatest"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/grpclog"
+)
+
+var _ grpclog.LoggerV2 = (*LoggerV2)(nil)
+
+func TestLoggerV2(t *testing.T) {
+	logger, observer := santatest.NewTestLogger(t)
+	adapter := NewLoggerV2(logger)
+
+	adapter.Info("a", "b")
+	adapter.Infoln("a", "b")
+	adapter.Infof("%s-%d", "a", 1)
+	adapter.Warning("warning")
+	adapter.Warningln("warning")
+	adapter.Warningf("%s", "warning")
+	adapter.Error("error")
+	adapter.Errorln("error")
+	adapter.Errorf("%s", "error")
+
+	entries := observer.TakeAll()
+	assert.Equal(t, []string { "ab", "a b", "a-1", "warning", "warning",
+		"warning", "error", "error", "error" }, entries.Messages(),
+		"Unexpected record result")
+	assert.Equal(t, 3, entries.FilterLevel(santa.LevelWarning).Len(),
+		"Unexpected record result")
+	assert.Equal(t, 3, entries.FilterLevel(santa.LevelError).Len(),
+		"Unexpected record result")
+
+	assert.True(t, adapter.V(0), "Unexpected verbosity result")
+	assert.False(t, adapter.V(1), "Unexpected verbosity result")
+	assert.True(t, NewLoggerV2Verbosity(logger, 2).V(2),
+		"Unexpected verbosity result")
+}
+
+func TestLoggerV2Fatal(t *testing.T) {
+	codes := make([]int, 0, 3)
+	exit = func(code int) {
+		codes = append(codes, code)
+	}
+	defer func() {
+		exit = os.Exit
+	}()
+
+	logger, observer := santatest.NewTestLogger(t)
+	adapter := NewLoggerV2(logger)
+	adapter.Fatal("fatal")
+	adapter.Fatalln("fatal")
+	adapter.Fatalf("%s", "fatal")
+
+	assert.Equal(t, []int { 1, 1, 1 }, codes, "Unexpected exit result")
+	assert.Equal(t, 3, observer.All().FilterLevel(santa.LevelFatal).
+		FilterMessage("fatal").Len(), "Unexpected record result")
+}
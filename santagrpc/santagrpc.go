@@ -0,0 +1,230 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package santagrpc provides gRPC server interceptors that print a
+// structured log entry for each RPC, and an adapter that implements the
+// grpclog.LoggerV2 interface for the logging of the gRPC runtime. For
+// example:
+//
+//	logger, _ := santa.NewStruct()
+//	interceptor := santagrpc.NewInterceptor(logger)
+//	server := grpc.NewServer(
+//		grpc.UnaryInterceptor(interceptor.Unary),
+//		grpc.StreamInterceptor(interceptor.Stream),
+//	)
+//	grpclog.SetLoggerV2(santagrpc.NewLoggerV2(logger))
+//
+// This package is a separate module, so that the santa module does not
+// depend on gRPC.
+package santagrpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nobody-night/santa"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrNilLogger is an error indicating that the logger of the
+	// interceptor is not provided.
+	ErrNilLogger = errors.New("the logger is nil")
+)
+
+// Option is a structure that contains options for the interceptor.
+type Option struct {
+	// Logger is the structured logger used to print the log entries of
+	// RPCs. It must be provided.
+	Logger *santa.StructLogger
+
+	// Message is the text of the message of the log entries of RPCs.
+	//
+	// If not provided, the default value is "gRPC call handled.".
+	Message string
+
+	// Level returns the level of the log entry of a RPC with the given
+	// status code.
+	//
+	// If not provided, the default value is the CodeLevel function.
+	Level func(code codes.Code) santa.Level
+
+	// MetadataLabels is the keys of the incoming metadata to be added as
+	// labels of the log entries of RPCs. If a key has multiple values,
+	// only the first value is used. The keys are case-insensitive.
+	//
+	// If not provided, the default value is empty.
+	MetadataLabels []string
+}
+
+// UseMessage uses the given message text for the log entries of RPCs.
+// Then return to the option instance itself.
+func (o *Option) UseMessage(message string) *Option {
+	o.Message = message
+	return o
+}
+
+// UseLevel uses the given function to determine the level of the log
+// entries of RPCs. Then return to the option instance itself.
+func (o *Option) UseLevel(level func(codes.Code) santa.Level) *Option {
+	o.Level = level
+	return o
+}
+
+// UseMetadataLabels uses the given keys of the incoming metadata as the
+// labels of the log entries of RPCs. Then return to the option instance
+// itself.
+func (o *Option) UseMetadataLabels(keys ...string) *Option {
+	o.MetadataLabels = keys
+	return o
+}
+
+// Build builds and returns an interceptor instance. If the logger is not
+// provided, it returns ErrNilLogger.
+func (o *Option) Build() (*Interceptor, error) {
+	if o.Logger == nil {
+		return nil, ErrNilLogger
+	}
+	interceptor := &Interceptor {
+		logger: o.Logger,
+		message: o.Message,
+		level: o.Level,
+		labels: o.MetadataLabels,
+	}
+	if interceptor.message == "" {
+		interceptor.message = "gRPC call handled."
+	}
+	if interceptor.level == nil {
+		interceptor.level = CodeLevel
+	}
+	return interceptor, nil
+}
+
+// NewOption creates and returns an option instance with the given logger
+// and default values.
+func NewOption(logger *santa.StructLogger) *Option {
+	return &Option {
+		Logger: logger,
+	}
+}
+
+// NewInterceptor creates and returns an interceptor instance with the
+// given logger and default options. If the given logger is nil, it
+// panics.
+func NewInterceptor(logger *santa.StructLogger) *Interceptor {
+	interceptor, err := NewOption(logger).Build()
+	if err != nil {
+		panic("santagrpc: " + err.Error())
+	}
+	return interceptor
+}
+
+// CodeLevel returns the level for the given status code. Codes caused by
+// the clients are at the info level, codes caused by the availability of
+// the server are at the warning level, and codes caused by the failures
+// of the server are at the error level.
+func CodeLevel(code codes.Code) santa.Level {
+	switch code {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound,
+		codes.AlreadyExists, codes.Unauthenticated:
+		return santa.LevelInfo
+	case codes.DeadlineExceeded, codes.PermissionDenied,
+		codes.ResourceExhausted, codes.FailedPrecondition, codes.Aborted,
+		codes.OutOfRange, codes.Unavailable:
+		return santa.LevelWarning
+	}
+	return santa.LevelError
+}
+
+// Interceptor is the structure of the interceptor instance.
+//
+// The Unary and Stream methods of the interceptor can be used as the
+// unary and stream server interceptors of gRPC servers. They print a log
+// entry for each RPC, which contains the full method name, status code,
+// latency in seconds, peer address and error of the RPC.
+type Interceptor struct {
+	logger *santa.StructLogger
+	message string
+	level func(codes.Code) santa.Level
+	labels []string
+}
+
+// Unary is a gRPC unary server interceptor that calls the given handler
+// and prints the log entry of the RPC.
+func (i *Interceptor) Unary(ctx context.Context, request interface { },
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface { }, error) {
+	start := time.Now()
+	response, err := handler(ctx, request)
+	i.print(ctx, info.FullMethod, false, time.Since(start), err)
+	return response, err
+}
+
+// Stream is a gRPC stream server interceptor that calls the given handler
+// and prints the log entry of the RPC.
+func (i *Interceptor) Stream(server interface { }, stream grpc.ServerStream,
+	info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(server, stream)
+	i.print(stream.Context(), info.FullMethod, true, time.Since(start), err)
+	return err
+}
+
+// print prints the log entry of a RPC with the given context, full method
+// name, latency and error.
+func (i *Interceptor) print(ctx context.Context, method string, stream bool,
+	latency time.Duration, err error) {
+	code := status.Code(err)
+	fields := make([]santa.Field, 0, 6)
+	fields = append(fields,
+		santa.String("method", method),
+		santa.Boolean("stream", stream),
+		santa.String("code", code.String()),
+		santa.Float64("latency", latency.Seconds()),
+	)
+	if remote, ok := peer.FromContext(ctx); ok && remote.Addr != nil {
+		fields = append(fields, santa.String("peer", remote.Addr.String()))
+	}
+	if err != nil {
+		fields = append(fields, santa.Error("error", err))
+	}
+
+	level := i.level(code)
+	if len(i.labels) == 0 {
+		_ = i.logger.Prints(level, i.message, fields...)
+		return
+	}
+	decorator := i.logger.Decorator()
+	if incoming, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, key := range i.labels {
+			if values := incoming.Get(key); len(values) > 0 {
+				decorator.AddLabels(santa.NewLabel(key, values[0]))
+			}
+		}
+	}
+	_ = decorator.Prints(level, i.message, fields...)
+	decorator.Free()
+}
@@ -0,0 +1,160 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santagrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/nobody-night/santa"
+	"github.com/nobody-night/santa/santatest"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestInterceptorUnary(t *testing.T) {
+	logger, observer := santatest.NewTestLogger(t)
+	interceptor, err := NewOption(logger).
+		UseMetadataLabels("x-request-id", "x-missing").
+		Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer {
+		Addr: &net.TCPAddr { IP: net.IPv4(192, 0, 2, 1), Port: 1234 },
+	})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("X-Request-Id",
+		"d4b0"))
+	info := &grpc.UnaryServerInfo {
+		FullMethod: "/santa.Users/Get",
+	}
+	response, err := interceptor.Unary(ctx, "request", info, func(
+		ctx context.Context, request interface { }) (interface { }, error) {
+		return "response", nil
+	})
+	assert.NoError(t, err, "Unexpected handler error")
+	assert.Equal(t, "response", response, "Unexpected handler response")
+
+	entries := observer.TakeAll()
+	assert.Equal(t, []string { "gRPC call handled." }, entries.Messages(),
+		"Unexpected record result")
+	assert.Equal(t, santa.LevelInfo, entries[0].Level,
+		"Unexpected record result")
+	assert.Equal(t, 1, entries.
+		FilterField(santa.String("method", "/santa.Users/Get")).
+		FilterField(santa.String("code", "OK")).
+		FilterField(santa.String("peer", "192.0.2.1:1234")).
+		FilterLabel("x-request-id", "d4b0").Len(),
+		"Unexpected record result")
+	assert.Equal(t, 1, entries[0].Labels.Count(), "Unexpected record result")
+
+	expected := status.Error(codes.Internal, "test")
+	_, err = interceptor.Unary(context.Background(), nil, info, func(
+		ctx context.Context, request interface { }) (interface { }, error) {
+		return nil, expected
+	})
+	assert.Equal(t, expected, err, "Unexpected handler error")
+	entries = observer.TakeAll()
+	assert.Equal(t, santa.LevelError, entries[0].Level,
+		"Unexpected record result")
+	assert.Equal(t, 1, entries.FilterField(santa.String("code", "Internal")).
+		FilterField(santa.Error("error", expected)).Len(),
+		"Unexpected record result")
+}
+
+func TestInterceptorStream(t *testing.T) {
+	logger, observer := santatest.NewTestLogger(t)
+	interceptor := NewInterceptor(logger)
+
+	stream := testServerStream {
+		ctx: context.Background(),
+	}
+	info := &grpc.StreamServerInfo {
+		FullMethod: "/santa.Users/Watch",
+	}
+	expected := errors.New("test")
+	err := interceptor.Stream(nil, stream, info, func(server interface { },
+		stream grpc.ServerStream) error {
+		return expected
+	})
+	assert.Equal(t, expected, err, "Unexpected handler error")
+
+	entries := observer.All()
+	assert.Equal(t, santa.LevelError, entries[0].Level,
+		"Unexpected record result")
+	assert.Equal(t, 1, entries.
+		FilterField(santa.String("method", "/santa.Users/Watch")).
+		FilterField(santa.Boolean("stream", true)).
+		FilterField(santa.String("code", "Unknown")).Len(),
+		"Unexpected record result")
+}
+
+func TestInterceptorOption(t *testing.T) {
+	_, err := NewOption(nil).Build()
+	assert.Equal(t, ErrNilLogger, err, "Unexpected build error")
+	assert.Panics(t, func() {
+		NewInterceptor(nil)
+	}, "Unexpected interceptor result")
+
+	logger, observer := santatest.NewTestLogger(t)
+	interceptor, err := NewOption(logger).
+		UseMessage("RPC.").
+		UseLevel(func(codes.Code) santa.Level {
+			return santa.LevelDebug
+		}).
+		Build()
+	assert.NoError(t, err, "Unexpected build error")
+	_, _ = interceptor.Unary(context.Background(), nil,
+		&grpc.UnaryServerInfo { }, func(ctx context.Context,
+			request interface { }) (interface { }, error) {
+			return nil, nil
+		})
+	entries := observer.All()
+	assert.Equal(t, []string { "RPC." }, entries.Messages(),
+		"Unexpected record result")
+	assert.Equal(t, santa.LevelDebug, entries[0].Level,
+		"Unexpected record result")
+}
+
+func TestCodeLevel(t *testing.T) {
+	assert.Equal(t, santa.LevelInfo, CodeLevel(codes.NotFound),
+		"Unexpected code level")
+	assert.Equal(t, santa.LevelWarning, CodeLevel(codes.Unavailable),
+		"Unexpected code level")
+	assert.Equal(t, santa.LevelError, CodeLevel(codes.DataLoss),
+		"Unexpected code level")
+}
@@ -0,0 +1,381 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package santahttp provides an access log middleware for the handlers of
+// the net/http package, which prints a structured log entry for each HTTP
+// request and recovers from the panics of the handlers. For example:
+//
+//	logger, _ := santa.NewStruct()
+//	handler := santahttp.Middleware(logger)(mux)
+//	http.ListenAndServe(":8080", handler)
+//
+// The context of each HTTP request passed to the handlers stores a
+// decorator of the logger, which adds the traceId field to the log
+// messages if the request has a trace ID. The handlers can obtain it with
+// the santa.FromContext function:
+//
+//	santa.FromContext(request.Context()).Infos("User updated.")
+package santahttp
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nobody-night/santa"
+)
+
+var (
+	// ErrNilLogger is an error indicating that the logger of the
+	// middleware is not provided.
+	ErrNilLogger = errors.New("the logger is nil")
+)
+
+// Option is a structure that contains options for the access log
+// middleware.
+type Option struct {
+	// Logger is the structured logger used to print the access log
+	// entries and the panics of the handlers. It must be provided.
+	Logger *santa.StructLogger
+
+	// Message is the text of the message of the access log entries.
+	//
+	// If not provided, the default value is "HTTP request handled.".
+	Message string
+
+	// Route returns the route of the given HTTP request, such as the
+	// pattern matched by the router. If the route is not empty, it is
+	// printed as the value of the route field.
+	//
+	// If not provided, the route field is not printed.
+	Route func(request *http.Request) string
+
+	// TraceID returns the trace ID of the given HTTP request. If the
+	// trace ID is not empty, it is printed as the value of the traceId
+	// field.
+	//
+	// If not provided, the default value is the TraceID function.
+	TraceID func(request *http.Request) string
+
+	// Level returns the level of the access log entry of a HTTP request
+	// with the given response status.
+	//
+	// If not provided, the default value is the StatusLevel function.
+	Level func(status int) santa.Level
+
+	// NoRecovery indicates whether to not recover from the panics of the
+	// handlers. Unless disabled, the panic value and stack trace are
+	// printed in a log entry at the error level, and a response with the
+	// status 500 is written if the handler has not written the response
+	// header. The http.ErrAbortHandler panics are not printed or
+	// recovered.
+	//
+	// If not provided, the default value is false, so the panics are
+	// recovered.
+	NoRecovery bool
+}
+
+// UseMessage uses the given message text for the access log entries.
+// Then return to the option instance itself.
+func (o *Option) UseMessage(message string) *Option {
+	o.Message = message
+	return o
+}
+
+// UseRoute uses the given function to obtain the route of HTTP requests.
+// Then return to the option instance itself.
+func (o *Option) UseRoute(route func(*http.Request) string) *Option {
+	o.Route = route
+	return o
+}
+
+// UseTraceID uses the given function to obtain the trace ID of HTTP
+// requests. Then return to the option instance itself.
+func (o *Option) UseTraceID(traceID func(*http.Request) string) *Option {
+	o.TraceID = traceID
+	return o
+}
+
+// UseLevel uses the given function to determine the level of the access
+// log entries. Then return to the option instance itself.
+func (o *Option) UseLevel(level func(int) santa.Level) *Option {
+	o.Level = level
+	return o
+}
+
+// DisableRecovery disables recovering from the panics of the handlers.
+// Then return to the option instance itself.
+func (o *Option) DisableRecovery() *Option {
+	o.NoRecovery = true
+	return o
+}
+
+// Build builds and returns a middleware instance. If the logger is not
+// provided, it returns ErrNilLogger.
+func (o *Option) Build() (func(http.Handler) http.Handler, error) {
+	if o.Logger == nil {
+		return nil, ErrNilLogger
+	}
+	m := &middleware {
+		logger: o.Logger,
+		message: o.Message,
+		route: o.Route,
+		traceID: o.TraceID,
+		level: o.Level,
+		recovery: !o.NoRecovery,
+	}
+	if m.message == "" {
+		m.message = "HTTP request handled."
+	}
+	if m.traceID == nil {
+		m.traceID = TraceID
+	}
+	if m.level == nil {
+		m.level = StatusLevel
+	}
+	return m.wrap, nil
+}
+
+// NewOption creates and returns an option instance with the given logger
+// and default values.
+func NewOption(logger *santa.StructLogger) *Option {
+	return &Option {
+		Logger: logger,
+	}
+}
+
+// Middleware creates and returns a middleware instance with the given
+// logger and default options. If the given logger is nil, it panics.
+func Middleware(logger *santa.StructLogger) func(http.Handler) http.Handler {
+	m, err := NewOption(logger).Build()
+	if err != nil {
+		panic("santahttp: " + err.Error())
+	}
+	return m
+}
+
+// StatusLevel returns the error level for the given response status of
+// 5xx, the warning level for 4xx, and the info level for others.
+func StatusLevel(status int) santa.Level {
+	switch {
+	case status >= 500:
+		return santa.LevelError
+	case status >= 400:
+		return santa.LevelWarning
+	}
+	return santa.LevelInfo
+}
+
+// TraceID returns the trace ID of the given HTTP request, which is
+// obtained from the W3C traceparent header, the X-Cloud-Trace-Context
+// header or the X-Request-Id header in order. If none of them is present,
+// it returns an empty string.
+func TraceID(request *http.Request) string {
+	if parent := request.Header.Get("traceparent"); len(parent) > 0 {
+		parts := strings.Split(parent, "-")
+		if len(parts) == 4 && len(parts[1]) == 32 {
+			return parts[1]
+		}
+	}
+	if context := request.Header.Get("X-Cloud-Trace-Context"); len(context) > 0 {
+		if index := strings.IndexByte(context, '/'); index >= 0 {
+			return context[ : index]
+		}
+		return context
+	}
+	return request.Header.Get("X-Request-Id")
+}
+
+// AccessFields returns the fields of the access log entry of the given
+// HTTP request, which are the request, the given response status,
+// response size, latency in seconds, route and trace ID. The route and
+// trace ID are omitted if they are empty. This function is provided for
+// the adapters of web frameworks.
+func AccessFields(request *http.Request, status int, size int64,
+	latency time.Duration, route, traceID string) []santa.Field {
+	fields := make([]santa.Field, 0, 6)
+	fields = append(fields,
+		santa.HTTPRequest("httpRequest", request),
+		santa.Int("status", int64(status)),
+		santa.Int("responseSize", size),
+		santa.Float64("latency", latency.Seconds()),
+	)
+	if len(route) > 0 {
+		fields = append(fields, santa.String("route", route))
+	}
+	if len(traceID) > 0 {
+		fields = append(fields, santa.String("traceId", traceID))
+	}
+	return fields
+}
+
+// PanicFields returns the fields of the log entry of the given panic
+// value recovered from the handler of the given HTTP request, which are
+// the panic value, the stack trace of the current goroutine and the
+// request. This function is provided for the adapters of web frameworks.
+func PanicFields(request *http.Request, recovered interface { }) []santa.Field {
+	return append(santa.PanicFields(recovered),
+		santa.HTTPRequest("httpRequest", request))
+}
+
+// middleware is the structure of the access log middleware instance.
+type middleware struct {
+	logger *santa.StructLogger
+	message string
+	route func(*http.Request) string
+	traceID func(*http.Request) string
+	level func(int) santa.Level
+	recovery bool
+}
+
+// wrap returns a handler that serves HTTP requests with the given handler
+// and prints the access log entries. The context of the requests stores a
+// decorator of the logger with the trace ID field, and the span context of
+// the traceparent header if the context does not have one.
+func (m *middleware) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		start := time.Now()
+		writer := &responseWriter {
+			ResponseWriter: w,
+		}
+		traceID := m.traceID(request)
+		decorator := m.logger.Decorator()
+		if len(traceID) > 0 {
+			decorator.AddFields(santa.String("traceId", traceID))
+		}
+		ctx := santa.NewContext(request.Context(), decorator)
+		if _, ok := santa.SpanContextFromContext(ctx); !ok {
+			// The span context of the traceparent header allows the
+			// handlers to join log entries with the trace.
+			span, err := santa.ParseTraceParent(
+				request.Header.Get("traceparent"))
+			if err == nil {
+				ctx = santa.ContextWithSpanContext(ctx, span)
+			}
+		}
+		request = request.WithContext(ctx)
+		completed := false
+		defer func() {
+			if m.recovery {
+				if recovered := recover(); recovered != nil {
+					latency := time.Since(start)
+					defer m.print(writer, request, traceID, latency)
+					m.handlePanic(writer, request, recovered)
+					return
+				}
+			}
+			if !completed && writer.status == 0 {
+				writer.status = http.StatusInternalServerError
+			}
+			m.print(writer, request, traceID, time.Since(start))
+		}()
+		next.ServeHTTP(writer, request)
+		completed = true
+	})
+}
+
+// handlePanic writes a response with the status 500 if the response header
+// has not been written, and then prints the given panic value recovered
+// from the handler of the given HTTP request. If the panic value is
+// http.ErrAbortHandler, it panics again with the value.
+func (m *middleware) handlePanic(writer *responseWriter, request *http.Request,
+	recovered interface { }) {
+	if recovered == http.ErrAbortHandler {
+		if writer.status == 0 {
+			writer.status = http.StatusInternalServerError
+		}
+		panic(recovered)
+	}
+	if writer.status == 0 {
+		writer.WriteHeader(http.StatusInternalServerError)
+	}
+	_ = m.logger.Errors("HTTP handler panicked.", PanicFields(request,
+		recovered)...)
+}
+
+// print prints the access log entry of the given HTTP request.
+func (m *middleware) print(writer *responseWriter, request *http.Request,
+	traceID string, latency time.Duration) {
+	status := writer.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	route := ""
+	if m.route != nil {
+		route = m.route(request)
+	}
+	fields := AccessFields(request, status, writer.size, latency, route,
+		traceID)
+	_ = m.logger.Prints(m.level(status), m.message, fields...)
+}
+
+// responseWriter is the structure of the response writer that records the
+// status and size of the response.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	size int64
+}
+
+// WriteHeader records the given status and writes the response header.
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write writes the given data to the response body and records its size.
+func (w *responseWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(data)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush flushes the buffered data of the response if the underlying
+// response writer implements the http.Flusher interface.
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hijacks the connection of the response if the underlying
+// response writer implements the http.Hijacker interface, otherwise it
+// returns http.ErrNotSupported.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+// Unwrap returns the underlying response writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
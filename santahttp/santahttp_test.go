@@ -0,0 +1,188 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santahttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nobody-night/santa"
+	"github.com/nobody-night/santa/santatest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	logger, observer := santatest.NewTestLogger(t)
+	handler := Middleware(logger)(http.HandlerFunc(func(
+		w http.ResponseWriter, r *http.Request) {
+		_ = santa.FromContext(r.Context()).Infos("Hello Test!")
+		span, ok := santa.SpanContextFromContext(r.Context())
+		assert.True(t, ok, "Unexpected span context")
+		assert.Equal(t, byte(1), span.TraceFlags, "Unexpected span context")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("not found"))
+	}))
+
+	request := httptest.NewRequest("GET", "/users", nil)
+	request.Header.Set("traceparent",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusNotFound, recorder.Code,
+		"Unexpected response status")
+
+	entries := observer.All()
+	assert.Equal(t, []string { "Hello Test!", "HTTP request handled." },
+		entries.Messages(), "Unexpected record result")
+	assert.Equal(t, santa.LevelWarning, entries[1].Level,
+		"Unexpected record result")
+	assert.Equal(t, 2, entries.FilterField(santa.String("traceId",
+		"4bf92f3577b34da6a3ce929d0e0e4736")).Len(),
+		"Unexpected record result")
+	assert.Equal(t, 1, entries.FilterField(santa.Int("status", 404)).
+		FilterField(santa.Int("responseSize", 9)).
+		FilterField(santa.String("traceId",
+			"4bf92f3577b34da6a3ce929d0e0e4736")).Len(),
+		"Unexpected record result")
+}
+
+func TestMiddlewareOption(t *testing.T) {
+	_, err := NewOption(nil).Build()
+	assert.Equal(t, ErrNilLogger, err, "Unexpected build error")
+	assert.Panics(t, func() {
+		Middleware(nil)
+	}, "Unexpected middleware result")
+
+	logger, observer := santatest.NewTestLogger(t)
+	middleware, err := NewOption(logger).
+		UseMessage("Request.").
+		UseRoute(func(*http.Request) string {
+			return "/users/:id"
+		}).
+		UseTraceID(func(*http.Request) string {
+			return ""
+		}).
+		UseLevel(func(int) santa.Level {
+			return santa.LevelDebug
+		}).
+		Build()
+	assert.NoError(t, err, "Unexpected build error")
+	handler := middleware(http.HandlerFunc(func(
+		w http.ResponseWriter, r *http.Request) { }))
+	handler.ServeHTTP(httptest.NewRecorder(),
+		httptest.NewRequest("GET", "/users/1", nil))
+
+	entries := observer.All()
+	assert.Equal(t, []string { "Request." }, entries.Messages(),
+		"Unexpected record result")
+	assert.Equal(t, santa.LevelDebug, entries[0].Level,
+		"Unexpected record result")
+	assert.Equal(t, 1, entries.FilterField(santa.Int("status", 200)).
+		FilterField(santa.String("route", "/users/:id")).Len(),
+		"Unexpected record result")
+	for _, field := range santatest.MessageFields(entries[0].Message) {
+		assert.NotEqual(t, "traceId", field.Name, "Unexpected record result")
+	}
+}
+
+func TestMiddlewareRecovery(t *testing.T) {
+	logger, observer := santatest.NewTestLogger(t)
+	handler := Middleware(logger)(http.HandlerFunc(func(
+		w http.ResponseWriter, r *http.Request) {
+		panic("test")
+	}))
+
+	recorder := httptest.NewRecorder()
+	assert.NotPanics(t, func() {
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	}, "Unexpected recovery result")
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code,
+		"Unexpected response status")
+
+	entries := observer.All()
+	assert.Equal(t, []string { "HTTP handler panicked.",
+		"HTTP request handled." }, entries.Messages(),
+		"Unexpected record result")
+	assert.Equal(t, 1, entries.FilterField(santa.String("panic", "test")).
+		Len(), "Unexpected record result")
+	assert.Equal(t, 2, entries.FilterLevel(santa.LevelError).Len(),
+		"Unexpected record result")
+
+	handler = Middleware(logger)(http.HandlerFunc(func(
+		w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(),
+			httptest.NewRequest("GET", "/", nil))
+	}, "Unexpected recovery result")
+	assert.Equal(t, 1, observer.TakeAll()[2 : ].FilterField(
+		santa.Int("status", 500)).Len(), "Unexpected record result")
+}
+
+func TestOptionRecovery(t *testing.T) {
+	logger, _ := santatest.NewTestLogger(t)
+	panicking := http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		panic("test")
+	})
+
+	// The panics are recovered unless recovery is disabled explicitly.
+	middleware, err := (&Option { Logger: logger }).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.NotPanics(t, func() {
+		middleware(panicking).ServeHTTP(httptest.NewRecorder(),
+			httptest.NewRequest("GET", "/", nil))
+	}, "Unexpected recovery result")
+
+	middleware, err = NewOption(logger).DisableRecovery().Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.Panics(t, func() {
+		middleware(panicking).ServeHTTP(httptest.NewRecorder(),
+			httptest.NewRequest("GET", "/", nil))
+	}, "Unexpected recovery result")
+}
+
+func TestTraceID(t *testing.T) {
+	request := httptest.NewRequest("GET", "/", nil)
+	assert.Equal(t, "", TraceID(request), "Unexpected trace ID")
+	request.Header.Set("X-Request-Id", "d4b0")
+	assert.Equal(t, "d4b0", TraceID(request), "Unexpected trace ID")
+	request.Header.Set("X-Cloud-Trace-Context", "105445aa7843bc8bf206b1200" +
+		"0000000/1;o=1")
+	assert.Equal(t, "105445aa7843bc8bf206b12000000000", TraceID(request),
+		"Unexpected trace ID")
+}
+
+func TestResponseWriter(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	writer := &responseWriter {
+		ResponseWriter: recorder,
+	}
+	writer.Flush()
+	assert.True(t, recorder.Flushed, "Unexpected flush result")
+	_, _, err := writer.Hijack()
+	assert.Equal(t, http.ErrNotSupported, err, "Unexpected hijack error")
+	assert.Equal(t, recorder, writer.Unwrap(), "Unexpected unwrap result")
+}
@@ -0,0 +1,239 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package santasql provides a query logger that prints a structured log
+// entry for each SQL query, and a wrapper of the sql.DB structure that
+// logs the queries executed through it. For example:
+//
+//	logger, _ := santa.NewStruct()
+//	db, _ := sql.Open("postgres", dsn)
+//	wrapped := santasql.Wrap(db, santasql.NewQueryLogger(logger))
+//	rows, err := wrapped.QueryContext(ctx, "SELECT * FROM users")
+//
+// The log entry of a query contains the query text, the duration in
+// seconds, the number of affected rows (if known) and the error of the
+// query. The arguments of the queries are not printed, as they may
+// contain sensitive data.
+package santasql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/nobody-night/santa"
+)
+
+var (
+	// ErrNilLogger is an error indicating that the logger of the query
+	// logger is not provided.
+	ErrNilLogger = errors.New("the logger is nil")
+)
+
+// Option is a structure that contains options for the query logger.
+type Option struct {
+	// Logger is the structured logger used to print the log entries of
+	// queries. It must be provided.
+	Logger *santa.StructLogger
+
+	// SlowThreshold is the minimum duration of slow queries. Slow
+	// queries are printed at the WARNING level. If the value is negative,
+	// no query is considered slow.
+	//
+	// If not provided, the default value is 200 milliseconds.
+	SlowThreshold time.Duration
+
+	// Level is the level of the log entries of queries that are neither
+	// slow nor failed. Failed queries are printed at the ERROR level.
+	//
+	// If not provided, the default value is LevelDebug.
+	Level santa.Level
+}
+
+// UseSlowThreshold uses the given duration as the minimum duration of
+// slow queries. Then return to the option instance itself.
+func (o *Option) UseSlowThreshold(threshold time.Duration) *Option {
+	o.SlowThreshold = threshold
+	return o
+}
+
+// UseLevel uses the given level for the log entries of queries that are
+// neither slow nor failed. Then return to the option instance itself.
+func (o *Option) UseLevel(level santa.Level) *Option {
+	o.Level = level
+	return o
+}
+
+// Build builds and returns a query logger instance. If the logger is not
+// provided, it returns ErrNilLogger.
+func (o *Option) Build() (*QueryLogger, error) {
+	if o.Logger == nil {
+		return nil, ErrNilLogger
+	}
+	return &QueryLogger {
+		logger: o.Logger,
+		threshold: o.SlowThreshold,
+		level: o.Level,
+	}, nil
+}
+
+// NewOption creates and returns an option instance with the given logger
+// and default values.
+func NewOption(logger *santa.StructLogger) *Option {
+	return &Option {
+		Logger: logger,
+		SlowThreshold: 200 * time.Millisecond,
+		Level: santa.LevelDebug,
+	}
+}
+
+// QueryLogger is the structure of the query logger instance.
+//
+// The API provided by the query logger is thread-safe.
+type QueryLogger struct {
+	logger *santa.StructLogger
+	threshold time.Duration
+	level santa.Level
+}
+
+// Slow returns true if a query with the given duration is a slow query.
+func (l *QueryLogger) Slow(duration time.Duration) bool {
+	return l.threshold >= 0 && duration >= l.threshold
+}
+
+// Print prints the log entry of a query with the given query text,
+// duration, number of affected rows and error. If the number of affected
+// rows is negative, it is not printed. Failed queries are printed at the
+// ERROR level, slow queries are printed at the WARNING level, and other
+// queries are printed at the level of the query logger.
+func (l *QueryLogger) Print(query string, duration time.Duration, rows int64,
+	err error) {
+	fields := make([]santa.Field, 0, 5)
+	fields = append(fields,
+		santa.String("query", query),
+		santa.Float64("duration", duration.Seconds()),
+	)
+	if rows >= 0 {
+		fields = append(fields, santa.Int("rows", rows))
+	}
+	switch {
+	case err != nil:
+		fields = append(fields, santa.Error("error", err))
+		_ = l.logger.Errors("SQL query failed.", fields...)
+	case l.Slow(duration):
+		fields = append(fields, santa.Float64("slowThreshold",
+			l.threshold.Seconds()))
+		_ = l.logger.Warnings("Slow SQL query executed.", fields...)
+	default:
+		_ = l.logger.Prints(l.level, "SQL query executed.", fields...)
+	}
+}
+
+// NewQueryLogger creates and returns a query logger instance with the
+// given logger and default options. If the given logger is nil, it
+// panics.
+func NewQueryLogger(logger *santa.StructLogger) *QueryLogger {
+	queryLogger, err := NewOption(logger).Build()
+	if err != nil {
+		panic("santasql: " + err.Error())
+	}
+	return queryLogger
+}
+
+// DB is the structure of the wrapper of the sql.DB structure.
+//
+// The query methods of the wrapper print the log entries of the queries
+// with the query logger. The other methods, including the methods of the
+// transactions and prepared statements, are provided by the wrapped
+// sql.DB structure and do not print log entries.
+type DB struct {
+	*sql.DB
+	logger *QueryLogger
+}
+
+// ExecContext executes the given query with the given context and
+// arguments, prints the log entry of the query, and then returns the
+// result and any errors encountered.
+func (d *DB) ExecContext(ctx context.Context, query string,
+	args ...interface { }) (sql.Result, error) {
+	start := time.Now()
+	result, err := d.DB.ExecContext(ctx, query, args...)
+	rows := int64(-1)
+	if err == nil {
+		if affected, err := result.RowsAffected(); err == nil {
+			rows = affected
+		}
+	}
+	d.logger.Print(query, time.Since(start), rows, err)
+	return result, err
+}
+
+// Exec executes the given query with the given arguments, prints the log
+// entry of the query, and then returns the result and any errors
+// encountered.
+func (d *DB) Exec(query string, args ...interface { }) (sql.Result, error) {
+	return d.ExecContext(context.Background(), query, args...)
+}
+
+// QueryContext executes the given query with the given context and
+// arguments, prints the log entry of the query, and then returns the
+// rows and any errors encountered. The number of rows is not printed.
+func (d *DB) QueryContext(ctx context.Context, query string,
+	args ...interface { }) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := d.DB.QueryContext(ctx, query, args...)
+	d.logger.Print(query, time.Since(start), -1, err)
+	return rows, err
+}
+
+// Query executes the given query with the given arguments, prints the
+// log entry of the query, and then returns the rows and any errors
+// encountered. The number of rows is not printed.
+func (d *DB) Query(query string, args ...interface { }) (*sql.Rows, error) {
+	return d.QueryContext(context.Background(), query, args...)
+}
+
+// QueryRowContext executes the given query with the given context and
+// arguments, prints the log entry of the query, and then returns the row.
+func (d *DB) QueryRowContext(ctx context.Context, query string,
+	args ...interface { }) *sql.Row {
+	start := time.Now()
+	row := d.DB.QueryRowContext(ctx, query, args...)
+	d.logger.Print(query, time.Since(start), -1, row.Err())
+	return row
+}
+
+// QueryRow executes the given query with the given arguments, prints the
+// log entry of the query, and then returns the row.
+func (d *DB) QueryRow(query string, args ...interface { }) *sql.Row {
+	return d.QueryRowContext(context.Background(), query, args...)
+}
+
+// Wrap creates and returns a wrapper of the given sql.DB instance, which
+// prints the log entries of the queries with the given query logger.
+func Wrap(db *sql.DB, logger *QueryLogger) *DB {
+	return &DB {
+		DB: db,
+		logger: logger,
+	}
+}
@@ -0,0 +1,186 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santasql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/nobody-night/santa"
+	"github.com/nobody-night/santa/santatest"
+	"github.com/stretchr/testify/assert"
+)
+
+var errTestQuery = errors.New("test query error")
+
+type testDriver struct { }
+
+func (testDriver) Open(name string) (driver.Conn, error) {
+	return testConn { }, nil
+}
+
+type testConn struct { }
+
+func (testConn) Prepare(query string) (driver.Stmt, error) {
+	return testStmt { query: query }, nil
+}
+
+func (testConn) Close() error {
+	return nil
+}
+
+func (testConn) Begin() (driver.Tx, error) {
+	return nil, errTestQuery
+}
+
+type testStmt struct {
+	query string
+}
+
+func (testStmt) Close() error {
+	return nil
+}
+
+func (testStmt) NumInput() int {
+	return -1
+}
+
+func (s testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.query == "FAIL" {
+		return nil, errTestQuery
+	}
+	return driver.RowsAffected(3), nil
+}
+
+func (s testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if s.query == "FAIL" {
+		return nil, errTestQuery
+	}
+	return &testRows { }, nil
+}
+
+type testRows struct {
+	done bool
+}
+
+func (*testRows) Columns() []string {
+	return []string { "id" }
+}
+
+func (*testRows) Close() error {
+	return nil
+}
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+func init() {
+	sql.Register("santasql-test", testDriver { })
+}
+
+func TestQueryLogger(t *testing.T) {
+	_, err := NewOption(nil).Build()
+	assert.Equal(t, ErrNilLogger, err, "Unexpected build error")
+	assert.Panics(t, func() {
+		NewQueryLogger(nil)
+	}, "Unexpected query logger result")
+
+	logger, observer := santatest.NewTestLogger(t)
+	queryLogger := NewQueryLogger(logger)
+	assert.False(t, queryLogger.Slow(time.Millisecond),
+		"Unexpected slow query result")
+	assert.True(t, queryLogger.Slow(time.Second),
+		"Unexpected slow query result")
+
+	queryLogger.Print("SELECT 1", time.Millisecond, -1, nil)
+	queryLogger.Print("SELECT 2", time.Second, 1, nil)
+	queryLogger.Print("SELECT 3", time.Second, -1, errTestQuery)
+
+	entries := observer.All()
+	assert.Equal(t, []string { "SQL query executed.",
+		"Slow SQL query executed.", "SQL query failed." }, entries.Messages(),
+		"Unexpected record result")
+	assert.Equal(t, []santa.Level { santa.LevelDebug, santa.LevelWarning,
+		santa.LevelError }, []santa.Level { entries[0].Level,
+		entries[1].Level, entries[2].Level }, "Unexpected record result")
+	assert.Equal(t, 1, entries.FilterField(santa.String("query", "SELECT 2")).
+		FilterField(santa.Int("rows", 1)).
+		FilterField(santa.Float64("slowThreshold", 0.2)).Len(),
+		"Unexpected record result")
+	assert.Equal(t, 1, entries.FilterField(santa.Error("error",
+		errTestQuery)).Len(), "Unexpected record result")
+
+	queryLogger, err = NewOption(logger).
+		UseSlowThreshold(-1).
+		UseLevel(santa.LevelInfo).
+		Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.False(t, queryLogger.Slow(time.Hour),
+		"Unexpected slow query result")
+	queryLogger.Print("SELECT 4", time.Hour, -1, nil)
+	assert.Equal(t, 1, observer.All().FilterLevel(santa.LevelInfo).
+		FilterMessage("SQL query executed.").Len(),
+		"Unexpected record result")
+}
+
+func TestDB(t *testing.T) {
+	logger, observer := santatest.NewTestLogger(t)
+	db, err := sql.Open("santasql-test", "")
+	assert.NoError(t, err, "Unexpected open error")
+	defer db.Close()
+	wrapped := Wrap(db, NewQueryLogger(logger))
+
+	_, err = wrapped.Exec("UPDATE users")
+	assert.NoError(t, err, "Unexpected exec error")
+	_, err = wrapped.Exec("FAIL")
+	assert.Equal(t, errTestQuery, err, "Unexpected exec error")
+	rows, err := wrapped.Query("SELECT id FROM users")
+	assert.NoError(t, err, "Unexpected query error")
+	assert.NoError(t, rows.Close(), "Unexpected close error")
+	var id int64
+	assert.NoError(t, wrapped.QueryRow("SELECT id FROM users").Scan(&id),
+		"Unexpected scan error")
+	assert.Equal(t, int64(1), id, "Unexpected scan result")
+	assert.Equal(t, errTestQuery, wrapped.QueryRow("FAIL").Err(),
+		"Unexpected query error")
+
+	entries := observer.All()
+	assert.Equal(t, 5, entries.Len(), "Unexpected record result")
+	assert.Equal(t, 1, entries.FilterField(santa.String("query",
+		"UPDATE users")).FilterField(santa.Int("rows", 3)).Len(),
+		"Unexpected record result")
+	assert.Equal(t, 2, entries.FilterMessage("SQL query failed.").Len(),
+		"Unexpected record result")
+	assert.Equal(t, 2, entries.FilterField(santa.String("query",
+		"SELECT id FROM users")).Len(), "Unexpected record result")
+}
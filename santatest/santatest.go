@@ -0,0 +1,250 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package santatest provides utilities for asserting on the log entries
+// emitted by applications in unit tests.
+//
+// The observer exporter records log entries in memory, and the recorded
+// log entries can be queried with the functions starting with Filter...
+// For example:
+//
+//	func TestLogin(t *testing.T) {
+//		logger, observer := santatest.NewTestLogger(t)
+//		login(logger, "alice")
+//		entries := observer.All().FilterLevel(santa.LevelInfo).
+//			FilterField(santa.String("user", "alice"))
+//		if entries.Len() != 1 {
+//			t.Fatalf("expected 1 log entry, got %d", entries.Len())
+//		}
+//	}
+package santatest
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nobody-night/santa"
+)
+
+// Entries is a slice of recorded log entries.
+type Entries []*santa.Entry
+
+// Len returns the number of recorded log entries.
+func (e Entries) Len() int {
+	return len(e)
+}
+
+// Messages returns the message texts of the recorded log entries in
+// order.
+func (e Entries) Messages() []string {
+	messages := make([]string, len(e))
+	for index, entry := range e {
+		messages[index] = MessageText(entry.Message)
+	}
+	return messages
+}
+
+// Filter returns the recorded log entries for which the given predicate
+// returns true.
+func (e Entries) Filter(predicate func(*santa.Entry) bool) Entries {
+	var filtered Entries
+	for _, entry := range e {
+		if predicate(entry) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// FilterLevel returns the recorded log entries with the given level.
+func (e Entries) FilterLevel(level santa.Level) Entries {
+	return e.Filter(func(entry *santa.Entry) bool {
+		return entry.Level == level
+	})
+}
+
+// FilterMessage returns the recorded log entries whose message text is
+// equal to the given text.
+func (e Entries) FilterMessage(text string) Entries {
+	return e.Filter(func(entry *santa.Entry) bool {
+		return MessageText(entry.Message) == text
+	})
+}
+
+// FilterMessageContains returns the recorded log entries whose message
+// text contains the given text.
+func (e Entries) FilterMessageContains(text string) Entries {
+	return e.Filter(func(entry *santa.Entry) bool {
+		return strings.Contains(MessageText(entry.Message), text)
+	})
+}
+
+// FilterField returns the recorded log entries whose structured message
+// has a top-level field with the same name and value as the given field.
+// The values are compared by their JSON serialization.
+func (e Entries) FilterField(field santa.Field) Entries {
+	expected := field.SerializeJSON(nil)
+	return e.Filter(func(entry *santa.Entry) bool {
+		for _, actual := range MessageFields(entry.Message) {
+			if actual.Name == field.Name && bytes.Equal(expected,
+				actual.SerializeJSON(nil)) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// FilterLabel returns the recorded log entries that have a label with the
+// given key and value.
+func (e Entries) FilterLabel(key, value string) Entries {
+	return e.Filter(func(entry *santa.Entry) bool {
+		for _, label := range entry.Labels.Labels() {
+			if label.Key == key && label.Value == value {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// MessageText returns the text of the given log entry message. The text of
+// template messages is formatted, and the text of structured messages does
+// not include their fields.
+func MessageText(message santa.Message) string {
+	switch message := message.(type) {
+	case nil:
+		return ""
+	case santa.StringMessage:
+		return string(message)
+	case *santa.StringMessage:
+		return string(*message)
+	case santa.TemplateMessage:
+		return fmt.Sprintf(message.Template, message.Args...)
+	case *santa.TemplateMessage:
+		return fmt.Sprintf(message.Template, message.Args...)
+	case santa.StructMessage:
+		return message.Text
+	case *santa.StructMessage:
+		return message.Text
+	case fmt.Stringer:
+		return message.String()
+	}
+	return fmt.Sprint(message)
+}
+
+// MessageFields returns the top-level fields of the given log entry
+// message. If the message is not a structured message, it returns nil.
+func MessageFields(message santa.Message) santa.ElementObject {
+	switch message := message.(type) {
+	case santa.StructMessage:
+		return message.Fields
+	case *santa.StructMessage:
+		return message.Fields
+	}
+	return nil
+}
+
+// ObserverExporter is the structure of the observer exporter instance.
+//
+// The observer exporter records a copy of each exported log entry in
+// memory, so that tests can assert on them. The API provided by the
+// observer exporter is thread-safe.
+type ObserverExporter struct {
+	mutex sync.Mutex
+	entries Entries
+}
+
+// Export records a copy of the given log entry, and then returns nil.
+func (e *ObserverExporter) Export(entry *santa.Entry) error {
+	instance := entry.Clone()
+	e.mutex.Lock()
+	e.entries = append(e.entries, instance)
+	e.mutex.Unlock()
+	return nil
+}
+
+// Sync does nothing and returns nil.
+func (e *ObserverExporter) Sync() error {
+	return nil
+}
+
+// Close does nothing and returns nil. The recorded log entries are kept.
+func (e *ObserverExporter) Close() error {
+	return nil
+}
+
+// All returns a snapshot of all recorded log entries in order.
+func (e *ObserverExporter) All() Entries {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return append(Entries(nil), e.entries...)
+}
+
+// Len returns the number of recorded log entries.
+func (e *ObserverExporter) Len() int {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return len(e.entries)
+}
+
+// TakeAll returns all recorded log entries in order, and then clears the
+// recorded log entries.
+func (e *ObserverExporter) TakeAll() Entries {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	entries := e.entries
+	e.entries = nil
+	return entries
+}
+
+// NewObserverExporter creates and returns an observer exporter instance
+// without any recorded log entry.
+func NewObserverExporter() *ObserverExporter {
+	return &ObserverExporter { }
+}
+
+// NewTestLogger creates and returns a structured logger whose log entries
+// of all levels are only recorded by the returned observer exporter. The
+// logger does not sample log entries, and it is closed automatically when
+// the given test finishes.
+func NewTestLogger(t testing.TB) (*santa.StructLogger, *ObserverExporter) {
+	observer := NewObserverExporter()
+	option := santa.NewStructOption().
+		UseLevel(santa.LevelDebug).
+		UseExporters(observer).
+		DisableSampling().
+		DisableFlushing()
+	option.Outputting.UseDiscard()
+	option.ErrorOutputting.UseDiscard()
+	logger, err := option.Build()
+	if err != nil {
+		t.Fatalf("santatest: building logger failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = logger.Close()
+	})
+	return logger, observer
+}
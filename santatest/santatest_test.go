@@ -0,0 +1,78 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santatest
+
+import (
+	"testing"
+
+	"github.com/nobody-night/santa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTestLogger(t *testing.T) {
+	logger, observer := NewTestLogger(t)
+
+	assert.NoError(t, logger.Debugs("Hello Test!"), "Unexpected print error")
+	assert.NoError(t, logger.Infos("user logged in",
+		santa.String("user", "alice"), santa.Int("attempts", 2)),
+		"Unexpected print error")
+	assert.NoError(t, logger.Errors("user logged in",
+		santa.String("user", "bob")), "Unexpected print error")
+	assert.Equal(t, 3, observer.Len(), "Unexpected record result")
+
+	entries := observer.All()
+	assert.Equal(t, []string { "Hello Test!", "user logged in",
+		"user logged in" }, entries.Messages(), "Unexpected record result")
+	assert.Equal(t, 1, entries.FilterLevel(santa.LevelError).Len(),
+		"Unexpected filter result")
+	assert.Equal(t, 2, entries.FilterMessage("user logged in").Len(),
+		"Unexpected filter result")
+	assert.Equal(t, 2, entries.FilterMessageContains("logged").Len(),
+		"Unexpected filter result")
+	assert.Equal(t, 1, entries.FilterField(santa.Int("attempts", 2)).Len(),
+		"Unexpected filter result")
+	assert.Equal(t, 0, entries.FilterField(santa.Int("attempts", 3)).Len(),
+		"Unexpected filter result")
+	assert.Equal(t, santa.LevelInfo, entries.
+		FilterField(santa.String("user", "alice"))[0].Level,
+		"Unexpected filter result")
+
+	assert.Len(t, observer.TakeAll(), 3, "Unexpected record result")
+	assert.Equal(t, 0, observer.Len(), "Unexpected record result")
+}
+
+func TestEntriesFilterLabel(t *testing.T) {
+	observer := NewObserverExporter()
+	for _, module := range []string { "billing", "users" } {
+		err := observer.Export(&santa.Entry {
+			Message: santa.StringMessage("Hello Test!"),
+			Labels: santa.NewSerializedLabels(santa.NewLabel("module",
+				module)),
+		})
+		assert.NoError(t, err, "Unexpected export error")
+	}
+	assert.Equal(t, 1, observer.All().FilterLabel("module", "users").Len(),
+		"Unexpected filter result")
+	assert.NoError(t, observer.Sync(), "Unexpected sync error")
+	assert.NoError(t, observer.Close(), "Unexpected close error")
+}
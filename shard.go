@@ -0,0 +1,458 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrInvalidShard represents that the given shard exporter option is
+	// invalid, usually because the file name template is empty, contains
+	// an unclosed or unknown placeholder, or the idle timeout is not
+	// greater than 0.
+	ErrInvalidShard = errors.New("invalid shard option")
+)
+
+// shardSegment is the structure of a parsed segment of the file name
+// template of the shard exporter. A segment is either literal text or a
+// placeholder.
+type shardSegment struct {
+	kind int
+	text string
+}
+
+const (
+	// shardLiteral represents a segment of literal text.
+	shardLiteral = iota
+
+	// shardLevel represents the "{level}" placeholder.
+	shardLevel
+
+	// shardName represents the "{name}" placeholder.
+	shardName
+
+	// shardLabel represents the "{label:key}" placeholder, and the text
+	// of the segment is the key name of the label.
+	shardLabel
+)
+
+// parseShardTemplate parses the given file name template into segments,
+// and returns ErrInvalidShard if the template contains an unclosed or
+// unknown placeholder.
+func parseShardTemplate(template string) ([]shardSegment, error) {
+	var segments []shardSegment
+	for len(template) > 0 {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			segments = append(segments, shardSegment { shardLiteral, template })
+			break
+		}
+		if start > 0 {
+			segments = append(segments,
+				shardSegment { shardLiteral, template[ : start] })
+		}
+		end := strings.IndexByte(template[start : ], '}')
+		if end < 0 {
+			return nil, ErrInvalidShard
+		}
+		placeholder := template[start + 1 : start + end]
+		switch {
+		case placeholder == "level":
+			segments = append(segments, shardSegment { shardLevel, "" })
+		case placeholder == "name":
+			segments = append(segments, shardSegment { shardName, "" })
+		case strings.HasPrefix(placeholder, "label:") &&
+			len(placeholder) > len("label:"):
+			segments = append(segments, shardSegment {
+				shardLabel, placeholder[len("label:") : ],
+			})
+		default:
+			return nil, ErrInvalidShard
+		}
+		template = template[start + end + 1 : ]
+	}
+	return segments, nil
+}
+
+// appendShardValue appends the given placeholder value to the given
+// buffer slice, and then returns the appended buffer slice. Path
+// separators and other unsafe characters are replaced with underscores
+// so that values can not escape the directory of the template, and
+// empty values are replaced with the given missing value.
+func appendShardValue(buffer []byte, value, missing string) []byte {
+	switch value {
+	case "":
+		value = missing
+	case ".", "..":
+		return append(buffer, '_')
+	}
+	for index := 0; index < len(value); index++ {
+		char := value[index]
+		switch {
+		case char == '/' || char == '\\' || char == ':' || char < 0x20:
+			buffer = append(buffer, '_')
+		default:
+			buffer = append(buffer, char)
+		}
+	}
+	return buffer
+}
+
+// shardFile is the structure of an open file of the shard exporter.
+type shardFile struct {
+	exporter *StandardExporter
+	used time.Time
+}
+
+// ShardExporter is the structure of the shard exporter instance.
+//
+// The shard exporter writes log entries to multiple files, whose names
+// are derived from a template and the log entry, such as "app-{level}.log"
+// or "tenants/{label:tenant}.log". The following placeholders are
+// supported in the template:
+//
+//   {level}      The name of the log level, such as "info".
+//   {name}       The name of the log entry.
+//   {label:key}  The value of the label with the given key name.
+//
+// Placeholder values that are empty or missing are replaced with the
+// value of the Missing option, and path separators in the values are
+// replaced with underscores.
+//
+// Files are opened lazily when the first log entry for them is exported,
+// and files that have not been written to within the idle timeout are
+// closed when the exporter is synchronized, which the automatic flushing
+// of the logger does periodically. A closed file is opened again by the
+// next log entry for it.
+//
+// The API provided by the shard exporter is thread-safe.
+type ShardExporter struct {
+	span LevelSpan
+	encoder Encoder
+	fileOption FileSyncerOption
+	segments []shardSegment
+	missing string
+	idleTimeout time.Duration
+	clock Clock
+
+	mutex sync.Mutex
+	files map[string]*shardFile
+	scratch []byte
+	closed int32
+}
+
+// Export encodes the given log entry and writes it to the file whose name
+// is derived from the template and the log entry, opening the file if
+// necessary.
+//
+// Finally, any errors encountered are returned.
+func (e *ShardExporter) Export(entry *Entry) error {
+	if atomic.LoadInt32(&e.closed) != 0 {
+		return ErrClosed
+	}
+	if !e.span.Contains(entry.Level) {
+		return nil
+	}
+	now := e.clock.Now()
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.files == nil {
+		return ErrClosed
+	}
+	e.scratch = e.fileName(e.scratch[ : 0], entry)
+	file, ok := e.files[string(e.scratch)]
+	if !ok {
+		var err error
+		if file, err = e.open(string(e.scratch)); err != nil {
+			return err
+		}
+	}
+	file.used = now
+	return file.exporter.Export(entry)
+}
+
+// fileName appends the file name of the given log entry to the given
+// buffer slice, and then returns the appended buffer slice.
+func (e *ShardExporter) fileName(buffer []byte, entry *Entry) []byte {
+	for _, segment := range e.segments {
+		switch segment.kind {
+		case shardLiteral:
+			buffer = append(buffer, segment.text...)
+		case shardLevel:
+			buffer = appendShardValue(buffer, entry.Level.String(),
+				e.missing)
+		case shardName:
+			buffer = appendShardValue(buffer, entry.Name, e.missing)
+		case shardLabel:
+			buffer = appendShardValue(buffer,
+				shardLabelValue(entry.Labels.Labels(), segment.text),
+				e.missing)
+		}
+	}
+	return buffer
+}
+
+// shardLabelValue returns the value of the first label with the given
+// key name, or an empty string if there is no such label.
+func shardLabelValue(labels Labels, key string) string {
+	for index := 0; index < len(labels); index++ {
+		if labels[index].Key == key {
+			return labels[index].Value
+		}
+	}
+	return ""
+}
+
+// open opens the file with the given name and adds it to the open files.
+//
+// Please note that the caller must hold the mutex.
+func (e *ShardExporter) open(name string) (*shardFile, error) {
+	option := e.fileOption.Clone()
+	option.FileName = name
+	syncer, err := option.Build()
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := NewStandardExporterOption().
+		UseSpan(e.span.Start, e.span.End).UseEncoder(e.encoder).UseSyncer(syncer).Build()
+	if err != nil {
+		syncer.Close()
+		return nil, err
+	}
+	file := &shardFile {
+		exporter: exporter,
+	}
+	e.files[name] = file
+	return file, nil
+}
+
+// Files returns the number of files that are currently open.
+func (e *ShardExporter) Files() int {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return len(e.files)
+}
+
+// Sync synchronizes the open files, and closes the files that have not
+// been written to within the idle timeout.
+//
+// Finally, any errors encountered are returned. If multiple errors are
+// encountered, they are returned as a MultiError.
+func (e *ShardExporter) Sync() error {
+	if atomic.LoadInt32(&e.closed) != 0 {
+		return ErrClosed
+	}
+	now := e.clock.Now()
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	var result error
+	for name, file := range e.files {
+		if now.Sub(file.used) >= e.idleTimeout {
+			delete(e.files, name)
+			result = appendError(result, file.exporter.Close())
+			continue
+		}
+		result = appendError(result, file.exporter.Sync())
+	}
+	return result
+}
+
+// Close closes all open files. After closing, the shard exporter can no
+// longer be used.
+//
+// Finally, any errors encountered are returned. If multiple errors are
+// encountered, they are returned as a MultiError.
+func (e *ShardExporter) Close() error {
+	if !atomic.CompareAndSwapInt32(&e.closed, 0, 1) {
+		return ErrClosed
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	var result error
+	for _, file := range e.files {
+		result = appendError(result, file.exporter.Close())
+	}
+	e.files = nil
+	return result
+}
+
+// ShardExporterOption is a structure that contains options for the shard
+// exporter.
+type ShardExporterOption struct {
+	// Template represents the template of the file names. For details,
+	// please refer to the comment section of the ShardExporter structure.
+	// This option is required.
+	Template string
+
+	// Span represents the log level span. If the level of a log entry is
+	// included in the log level span, the log entry will be processed,
+	// otherwise it will be discarded. If not provided, the default value
+	// is DEBUG level to FATAL level.
+	Span LevelSpan
+
+	// Encoder represents the encoder used to encode log entries. If not
+	// provided, the default value is the standard encoder.
+	Encoder Encoder
+
+	// File represents the option of the file synchronizer of each file.
+	// The FileName option is replaced with the name derived from the
+	// template. If not provided, the default value is the default file
+	// synchronizer option with the MakeDirectories option enabled.
+	File *FileSyncerOption
+
+	// Missing represents the value used for placeholders whose values are
+	// empty or missing. If not provided, the default value is "unknown".
+	Missing string
+
+	// IdleTimeout represents the time after the last write after which a
+	// file is closed. If not provided, the default value is 5 minutes.
+	IdleTimeout time.Duration
+
+	// Clock represents the clock used to determine whether files are idle.
+	// If not provided, the default value is the system clock.
+	Clock Clock
+}
+
+// UseTemplate uses the given template as the value of the Template
+// option. For details, please refer to the comment section of the
+// Template option. Then return to the option instance itself.
+func (o *ShardExporterOption) UseTemplate(template string) *ShardExporterOption {
+	o.Template = template
+	return o
+}
+
+// UseSpan uses the given log level span as the value of the Span option.
+// For details, please refer to the comment section of the Span option.
+// Then return to the option instance itself.
+func (o *ShardExporterOption) UseSpan(start, end Level) *ShardExporterOption {
+	o.Span = LevelSpan {
+		Start: start,
+		End: end,
+	}
+	return o
+}
+
+// UseEncoder uses the given encoder as the value of the Encoder option.
+// For details, please refer to the comment section of the Encoder option.
+// Then return to the option instance itself.
+func (o *ShardExporterOption) UseEncoder(encoder Encoder) *ShardExporterOption {
+	o.Encoder = encoder
+	return o
+}
+
+// UseFile uses the given file synchronizer option as the value of the
+// File option. For details, please refer to the comment section of the
+// File option. Then return to the option instance itself.
+func (o *ShardExporterOption) UseFile(option *FileSyncerOption) *ShardExporterOption {
+	o.File = option
+	return o
+}
+
+// UseMissing uses the given value as the value of the Missing option. For
+// details, please refer to the comment section of the Missing option.
+// Then return to the option instance itself.
+func (o *ShardExporterOption) UseMissing(missing string) *ShardExporterOption {
+	o.Missing = missing
+	return o
+}
+
+// UseIdleTimeout uses the given duration as the value of the IdleTimeout
+// option. For details, please refer to the comment section of the
+// IdleTimeout option. Then return to the option instance itself.
+func (o *ShardExporterOption) UseIdleTimeout(timeout time.Duration) *ShardExporterOption {
+	o.IdleTimeout = timeout
+	return o
+}
+
+// UseClock uses the given clock as the value of the Clock option. For
+// details, please refer to the comment section of the Clock option. Then
+// return to the option instance itself.
+func (o *ShardExporterOption) UseClock(clock Clock) *ShardExporterOption {
+	o.Clock = clock
+	return o
+}
+
+// Validate checks whether the values of the options are valid, and returns
+// an error describing the first invalid value.
+func (o *ShardExporterOption) Validate() error {
+	if len(o.Template) == 0 || o.IdleTimeout <= 0 {
+		return ErrInvalidShard
+	}
+	if _, err := parseShardTemplate(o.Template); err != nil {
+		return err
+	}
+	return o.Span.Validate()
+}
+
+// Build builds and returns an instance of the shard exporter and any
+// errors encountered.
+func (o *ShardExporterOption) Build() (*ShardExporter, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	segments, _ := parseShardTemplate(o.Template)
+	instance := &ShardExporter {
+		span: o.Span,
+		encoder: o.Encoder,
+		segments: segments,
+		missing: o.Missing,
+		idleTimeout: o.IdleTimeout,
+		clock: o.Clock,
+		files: make(map[string]*shardFile),
+	}
+	if instance.encoder == nil {
+		instance.encoder, _ = NewStandardEncoder()
+	}
+	if o.File != nil {
+		instance.fileOption = *o.File.Clone()
+	} else {
+		instance.fileOption = *NewFileSyncerOption().UseMakeDirectories(0755)
+	}
+	if len(instance.missing) == 0 {
+		instance.missing = "unknown"
+	}
+	if instance.clock == nil {
+		instance.clock = SystemClock { }
+	}
+	return instance, nil
+}
+
+// NewShardExporterOption creates and returns a shard exporter option
+// instance with default option values. The template must be provided
+// before building.
+func NewShardExporterOption() *ShardExporterOption {
+	return &ShardExporterOption {
+		Span: LevelSpan {
+			Start: LevelDebug,
+			End: LevelFatal,
+		},
+		Missing: "unknown",
+		IdleTimeout: time.Minute * 5,
+		Clock: SystemClock { },
+	}
+}
@@ -0,0 +1,111 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardExporterOption(t *testing.T) {
+	option := NewShardExporterOption()
+	assert.Equal(t, time.Minute * 5, option.IdleTimeout,
+		"Unexpected option value")
+	assert.Equal(t, "unknown", option.Missing, "Unexpected option value")
+
+	_, err := option.Build()
+	assert.Equal(t, ErrInvalidShard, err, "Unexpected build error")
+
+	for _, template := range []string { "app-{level", "app-{tenant}.log",
+		"app-{label:}.log" } {
+		_, err = option.UseTemplate(template).Build()
+		assert.Equal(t, ErrInvalidShard, err, "Unexpected build error")
+	}
+
+	_, err = option.UseTemplate("app.log").UseIdleTimeout(0).Build()
+	assert.Equal(t, ErrInvalidShard, err, "Unexpected build error")
+}
+
+func TestShardExporter(t *testing.T) {
+	directory, err := ioutil.TempDir("", "santa")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	clock := &testStepClock { now: time.Unix(0, 0) }
+	exporter, err := NewShardExporterOption().
+		UseTemplate(filepath.Join(directory,
+			"{label:tenant}", "{name}-{level}.log")).
+		UseIdleTimeout(time.Minute).UseClock(clock).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	export := func(tenant, name string, level Level) {
+		err := exporter.Export(&Entry {
+			Level: level,
+			Name: name,
+			Message: StringMessage("message"),
+			Labels: NewSerializedLabels(NewLabel("tenant", tenant)),
+		})
+		assert.NoError(t, err, "Unexpected export error")
+	}
+	export("alpha", "app", LevelInfo)
+	export("alpha", "app", LevelInfo)
+	export("alpha", "app", LevelError)
+	export("beta", "", LevelInfo)
+	export("../escape", "app", LevelInfo)
+	assert.Equal(t, 4, exporter.Files(), "Unexpected number of files")
+
+	// Files that have not been written to within the idle timeout are
+	// closed when the exporter is synchronized.
+	clock.now = clock.now.Add(time.Second * 30)
+	export("beta", "", LevelInfo)
+	clock.now = clock.now.Add(time.Second * 45)
+	assert.NoError(t, exporter.Sync(), "Unexpected sync error")
+	assert.Equal(t, 1, exporter.Files(), "Unexpected number of files")
+
+	// A closed file is opened again by the next log entry for it.
+	export("alpha", "app", LevelInfo)
+	assert.Equal(t, 2, exporter.Files(), "Unexpected number of files")
+	assert.NoError(t, exporter.Close(), "Unexpected close error")
+	assert.Equal(t, ErrClosed, exporter.Close(), "Unexpected close error")
+	assert.Equal(t, ErrClosed, exporter.Export(entry),
+		"Unexpected export error")
+
+	lines := func(elements ...string) int {
+		data, err := ioutil.ReadFile(filepath.Join(
+			append([]string { directory }, elements...)...))
+		assert.NoError(t, err, "Unexpected read error")
+		return strings.Count(string(data), "\n")
+	}
+	assert.Equal(t, 3, lines("alpha", "app-info.log"), "Unexpected lines")
+	assert.Equal(t, 1, lines("alpha", "app-error.log"), "Unexpected lines")
+	assert.Equal(t, 2, lines("beta", "unknown-info.log"),
+		"Unexpected lines")
+	assert.Equal(t, 1, lines(".._escape", "app-info.log"),
+		"Unexpected lines")
+}
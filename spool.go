@@ -0,0 +1,564 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidSpool represents that the given spool synchronizer option
+	// is invalid. This is usually because the downstream synchronizer is
+	// not provided, or the segment size or shipping interval is not
+	// greater than 0.
+	ErrInvalidSpool = errors.New("invalid spool option")
+
+	// ErrCorruptedSpool represents that a record of a segment file of the
+	// spool synchronizer is corrupted.
+	ErrCorruptedSpool = errors.New("corrupted spool record")
+)
+
+const (
+	// spoolSegmentExt represents the file name extension of the segment
+	// files of the spool synchronizer.
+	spoolSegmentExt = ".wal"
+
+	// spoolOffsetName represents the name of the file that stores the
+	// shipping position of the spool synchronizer.
+	spoolOffsetName = "offset"
+
+	// spoolRecordMax represents the maximum number of bytes of the data
+	// of a record, which is used to detect corrupted records.
+	spoolRecordMax = 1 << 30
+)
+
+// spoolFile is the interface of the segment files of the spool
+// synchronizer, which is implemented by *os.File.
+type spoolFile interface {
+	io.WriteCloser
+	Sync() error
+	Truncate(size int64) error
+}
+
+// SpoolSyncer is the structure of the spool synchronizer instance.
+//
+// The spool synchronizer is a disk-backed write-ahead buffer. Written data
+// is appended as records to segment files in a local directory, and then
+// shipped to a downstream synchronizer (for example, a network
+// synchronizer) asynchronously. When the size of the current segment file
+// reaches the segment size, a new segment file is used, and segment files
+// that have been shipped completely are removed.
+//
+// The shipping position is persisted after the downstream synchronizer has
+// been synchronized, so that the data that has not been shipped survives
+// process restarts and is replayed when a spool synchronizer is built on
+// the same directory. Records may be shipped more than once if the process
+// exits after shipping and before persisting the position.
+//
+// The API provided by the spool synchronizer is thread-safe.
+type SpoolSyncer struct {
+	directory string
+	segmentBytes int64
+	downstream Syncer
+
+	mutex sync.Mutex
+	closed bool
+	file spoolFile
+	segment uint64
+	size int64
+	record []byte
+
+	shipSegment uint64
+	shipOffset int64
+	shipFailing bool
+
+	context context.Context
+	contextCancel context.CancelFunc
+	contextWaitGroup *sync.WaitGroup
+	signal chan struct { }
+}
+
+// Write appends the data of a given buffer slice as a record to the
+// current segment file. The data is shipped to the downstream synchronizer
+// asynchronously.
+//
+// Finally, it returns the number of bytes actually written and any
+// errors encountered.
+func (s *SpoolSyncer) Write(buffer []byte) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return 0, ErrClosed
+	}
+	if s.file == nil {
+		// The last rotation failed to create the next segment file.
+		if err := s.open(); err != nil {
+			return 0, err
+		}
+	}
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[ : ], uint32(len(buffer)))
+	s.record = append(s.record[ : 0], header[ : ]...)
+	s.record = append(s.record, buffer...)
+	// The record is written in a single call, so that the shipping
+	// coroutine never observes a record without its data.
+	size, err := s.file.Write(s.record)
+	if err != nil {
+		if size > 0 {
+			s.tear()
+		}
+		return 0, err
+	}
+	s.size += int64(size)
+	if s.size >= s.segmentBytes {
+		if err = s.rotate(); err != nil {
+			return len(buffer), err
+		}
+	}
+	return len(buffer), nil
+}
+
+// tear removes the incomplete record written by a failed write from the
+// end of the current segment file, so that the next record does not
+// follow it. If the file cannot be truncated, the next segment file is
+// used instead, because the shipping coroutine ignores an incomplete
+// record at the end of a segment file. The caller must hold the mutex.
+func (s *SpoolSyncer) tear() {
+	if err := s.file.Truncate(s.size); err != nil {
+		_ = s.rotate()
+	}
+}
+
+// rotate closes the current segment file and creates the next one, and
+// then returns any errors encountered. If the next segment file cannot be
+// created, there is no current segment file until the next write creates
+// it. The caller must hold the mutex.
+func (s *SpoolSyncer) rotate() error {
+	err := s.file.Close()
+	s.file = nil
+	if err != nil {
+		return err
+	}
+	return s.open()
+}
+
+// open creates the segment file following the current one and uses it as
+// the current segment file, and then returns any errors encountered. The
+// caller must hold the mutex.
+func (s *SpoolSyncer) open() error {
+	file, err := s.create(s.segment + 1)
+	if err != nil {
+		return err
+	}
+	s.file = file
+	s.segment++
+	s.size = 0
+	return nil
+}
+
+// create creates and returns the segment file with the given identifier
+// and any errors encountered.
+func (s *SpoolSyncer) create(segment uint64) (spoolFile, error) {
+	file, err := os.OpenFile(s.path(segment), os.O_CREATE | os.O_WRONLY |
+		os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// path returns the path of the segment file with the given identifier.
+func (s *SpoolSyncer) path(segment uint64) string {
+	return filepath.Join(s.directory, fmt.Sprintf("%020d%s", segment,
+		spoolSegmentExt))
+}
+
+// Sync writes the data of the current segment file to the persistent
+// storage device, and then wakes up the shipping coroutine.
+//
+// Finally, any errors encountered are returned.
+func (s *SpoolSyncer) Sync() error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return ErrClosed
+	}
+	var err error
+	if s.file != nil {
+		err = s.file.Sync()
+	}
+	s.mutex.Unlock()
+	select {
+	case s.signal <- struct { } { }:
+	default:
+	}
+	return err
+}
+
+// Close stops the shipping coroutine, closes the current segment file,
+// ships the remaining records once, and then closes the downstream
+// synchronizer. Records that cannot be shipped are kept in the segment
+// files and replayed by the next spool synchronizer of the directory. The
+// remaining records are shipped and the downstream synchronizer is closed
+// even if the current segment file cannot be closed.
+//
+// Finally, the first error encountered is returned.
+func (s *SpoolSyncer) Close() error {
+	s.contextCancel()
+	s.contextWaitGroup.Wait()
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return ErrClosed
+	}
+	s.closed = true
+	var err error
+	if s.file != nil {
+		err = s.file.Sync()
+		if closeErr := s.file.Close(); err == nil {
+			err = closeErr
+		}
+		s.file = nil
+	}
+	s.mutex.Unlock()
+	if shipErr := s.ship(); err == nil {
+		err = shipErr
+	}
+	if closeErr := s.downstream.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// shipHandler ships the records at a given time interval or when woken up
+// by the Sync function, until the context has been marked as complete and
+// returns.
+//
+// This function should run in an independent coroutine context.
+func (s *SpoolSyncer) shipHandler(interval time.Duration) {
+	defer s.contextWaitGroup.Done()
+	for {
+		select {
+		case <-s.context.Done():
+			return
+		case <-time.After(interval):
+		case <-s.signal:
+		}
+		// Errors cannot be returned to any caller, so only the first
+		// error of each consecutive series is reported to the
+		// diagnostics channel.
+		err := s.ship()
+		if err != nil && !s.shipFailing {
+			diagnose("spool syncer shipping failed: %v", err)
+		}
+		s.shipFailing = err != nil
+	}
+}
+
+// ship writes the records that have not been shipped to the downstream
+// synchronizer in order, removes the segment files that have been shipped
+// completely, and persists the shipping position, and then returns any
+// errors encountered.
+//
+// The function must only be called by the shipping coroutine, or after the
+// shipping coroutine has returned.
+func (s *SpoolSyncer) ship() error {
+	for {
+		s.mutex.Lock()
+		active := s.segment
+		end := s.size
+		complete := s.file == nil
+		s.mutex.Unlock()
+		// Segment files other than the active one are complete, so they
+		// are read to the end of the file. The active segment file is
+		// also complete if it has been closed.
+		if s.shipSegment != active || complete {
+			end = -1
+		}
+		offset, err := s.shipFile(s.shipSegment, s.shipOffset, end)
+		shipped := offset != s.shipOffset
+		s.shipOffset = offset
+		if err != nil {
+			if shipped {
+				if syncErr := s.downstream.Sync(); syncErr == nil {
+					_ = s.saveOffset()
+				}
+			}
+			return err
+		}
+		if s.shipSegment >= active {
+			if !shipped {
+				return nil
+			}
+			if err = s.downstream.Sync(); err != nil {
+				return err
+			}
+			return s.saveOffset()
+		}
+		if err = s.downstream.Sync(); err != nil {
+			return err
+		}
+		err = os.Remove(s.path(s.shipSegment))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		s.shipSegment++
+		s.shipOffset = 0
+		if err = s.saveOffset(); err != nil {
+			return err
+		}
+	}
+}
+
+// shipFile writes the records of the segment file with the given
+// identifier from the given offset to the downstream synchronizer. If the
+// given end offset is not less than 0, the records after it are not
+// shipped. An incomplete record at the end of the file is ignored.
+//
+// Finally, it returns the offset after the last shipped record and any
+// errors encountered.
+func (s *SpoolSyncer) shipFile(segment uint64, offset, end int64) (int64, error) {
+	file, err := os.Open(s.path(segment))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return offset, nil
+		}
+		return offset, err
+	}
+	defer file.Close()
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		return offset, err
+	}
+	reader := bufio.NewReader(file)
+	var header [4]byte
+	var data []byte
+	for end < 0 || offset < end {
+		if _, err = io.ReadFull(reader, header[ : ]); err != nil {
+			break
+		}
+		length := binary.BigEndian.Uint32(header[ : ])
+		if length > spoolRecordMax {
+			return offset, ErrCorruptedSpool
+		}
+		if cap(data) < int(length) {
+			data = make([]byte, length)
+		}
+		data = data[ : length]
+		if _, err = io.ReadFull(reader, data); err != nil {
+			break
+		}
+		if _, err = s.downstream.Write(data); err != nil {
+			return offset, err
+		}
+		offset += int64(len(header) + len(data))
+	}
+	return offset, nil
+}
+
+// saveOffset persists the shipping position to the offset file of the
+// directory, and then returns any errors encountered. The file is
+// replaced atomically.
+func (s *SpoolSyncer) saveOffset() error {
+	name := filepath.Join(s.directory, spoolOffsetName)
+	content := strconv.FormatUint(s.shipSegment, 10) + " " +
+		strconv.FormatInt(s.shipOffset, 10) + "\n"
+	if err := ioutil.WriteFile(name + ".tmp", []byte(content),
+		0644); err != nil {
+		return err
+	}
+	return os.Rename(name + ".tmp", name)
+}
+
+// loadSpool returns the identifiers of the segment files of the given
+// directory in ascending order and the persisted shipping position, and
+// any errors encountered. If the shipping position has not been persisted,
+// the first segment file and offset 0 are returned.
+func loadSpool(directory string) ([]uint64, uint64, int64, error) {
+	files, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	var segments []uint64
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, spoolSegmentExt) {
+			continue
+		}
+		segment, err := strconv.ParseUint(strings.TrimSuffix(name,
+			spoolSegmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	// ioutil.ReadDir sorts files by name, and the identifiers of segment
+	// files are zero-padded, so the segments are sorted.
+	var segment uint64
+	var offset int64
+	if len(segments) > 0 {
+		segment = segments[0]
+	}
+	content, err := ioutil.ReadFile(filepath.Join(directory,
+		spoolOffsetName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return segments, segment, 0, nil
+		}
+		return nil, 0, 0, err
+	}
+	var savedSegment uint64
+	if _, err = fmt.Sscan(string(content), &savedSegment,
+		&offset); err != nil {
+		return nil, 0, 0, ErrCorruptedSpool
+	}
+	if savedSegment < segment {
+		return segments, segment, 0, nil
+	}
+	return segments, savedSegment, offset, nil
+}
+
+// SpoolSyncerOption is a structure containing spool synchronizer options.
+type SpoolSyncerOption struct {
+	// Directory represents the local directory where the segment files
+	// and the shipping position are stored. The directory is created if
+	// it does not exist. Only one spool synchronizer may use a directory
+	// at the same time. If not provided, the default value is the
+	// santa-spool directory in the temporary directory of the system.
+	Directory string
+
+	// Downstream represents the synchronizer that the records are shipped
+	// to. The spool synchronizer takes ownership of it and closes it when
+	// the spool synchronizer is closed. It must be provided.
+	Downstream Syncer
+
+	// SegmentBytes represents the number of bytes after which a new
+	// segment file is used. If not provided, the default value is 16 MiB.
+	SegmentBytes int64
+
+	// ShipInterval represents the interval at which the records are
+	// shipped to the downstream synchronizer. If not provided, the default
+	// value is 1 second.
+	ShipInterval time.Duration
+}
+
+// UseDirectory uses the given directory as the value of the option
+// Directory. For details, please refer to the comment section of the
+// Directory option. Then return to the option instance itself.
+func (o *SpoolSyncerOption) UseDirectory(directory string) *SpoolSyncerOption {
+	o.Directory = directory
+	return o
+}
+
+// UseDownstream uses the given synchronizer as the value of the option
+// Downstream. For details, please refer to the comment section of the
+// Downstream option. Then return to the option instance itself.
+func (o *SpoolSyncerOption) UseDownstream(downstream Syncer) *SpoolSyncerOption {
+	o.Downstream = downstream
+	return o
+}
+
+// UseSegmentBytes uses the given size as the value of the option
+// SegmentBytes. For details, please refer to the comment section of the
+// SegmentBytes option. Then return to the option instance itself.
+func (o *SpoolSyncerOption) UseSegmentBytes(size int64) *SpoolSyncerOption {
+	o.SegmentBytes = size
+	return o
+}
+
+// UseShipInterval uses the given interval as the value of the option
+// ShipInterval. For details, please refer to the comment section of the
+// ShipInterval option. Then return to the option instance itself.
+func (o *SpoolSyncerOption) UseShipInterval(interval time.Duration) *SpoolSyncerOption {
+	o.ShipInterval = interval
+	return o
+}
+
+// Build builds and returns an instance of the spool synchronizer and any
+// errors encountered. The records of the directory that have not been
+// shipped are replayed to the downstream synchronizer.
+func (o *SpoolSyncerOption) Build() (*SpoolSyncer, error) {
+	if o.Downstream == nil || o.SegmentBytes <= 0 || o.ShipInterval <= 0 {
+		return nil, ErrInvalidSpool
+	}
+	if err := os.MkdirAll(o.Directory, 0755); err != nil {
+		return nil, err
+	}
+	segments, shipSegment, shipOffset, err := loadSpool(o.Directory)
+	if err != nil {
+		return nil, err
+	}
+	// Existing segment files are never appended, because their last
+	// record may be incomplete.
+	var segment uint64 = 1
+	if len(segments) > 0 {
+		segment = segments[len(segments) - 1] + 1
+	}
+	if shipSegment == 0 {
+		shipSegment = segment
+	}
+	context, contextCancel := context.WithCancel(
+		context.Background())
+	instance := &SpoolSyncer {
+		directory: o.Directory,
+		segmentBytes: o.SegmentBytes,
+		downstream: o.Downstream,
+		segment: segment,
+		shipSegment: shipSegment,
+		shipOffset: shipOffset,
+		context: context,
+		contextCancel: contextCancel,
+		contextWaitGroup: &sync.WaitGroup { },
+		signal: make(chan struct { }, 1),
+	}
+	instance.file, err = instance.create(segment)
+	if err != nil {
+		contextCancel()
+		return nil, err
+	}
+	instance.contextWaitGroup.Add(1)
+	go instance.shipHandler(o.ShipInterval)
+	return instance, nil
+}
+
+// NewSpoolSyncerOption creates and returns a spool synchronizer option
+// instance with default option values. The downstream synchronizer must
+// be provided before building.
+func NewSpoolSyncerOption() *SpoolSyncerOption {
+	return &SpoolSyncerOption {
+		Directory: filepath.Join(os.TempDir(), "santa-spool"),
+		SegmentBytes: 16 << 20,
+		ShipInterval: time.Second,
+	}
+}
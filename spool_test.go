@@ -0,0 +1,256 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpoolSyncerOption(t *testing.T) {
+	option := NewSpoolSyncerOption()
+
+	_, err := option.Build()
+	assert.Equal(t, ErrInvalidSpool, err, "Unexpected build error")
+
+	downstream := &testWriteSyncer { }
+	option.UseDirectory("spool")
+	option.UseDownstream(downstream)
+	option.UseSegmentBytes(1024)
+	option.UseShipInterval(time.Minute)
+
+	assert.Equal(t, "spool", option.Directory, "Unexpected option value")
+	assert.Equal(t, downstream, option.Downstream, "Unexpected option value")
+	assert.Equal(t, int64(1024), option.SegmentBytes,
+		"Unexpected option value")
+	assert.Equal(t, time.Minute, option.ShipInterval,
+		"Unexpected option value")
+}
+
+func TestSpoolSyncerReplay(t *testing.T) {
+	SetDiagnosticsWriter(&bytes.Buffer { })
+	defer SetDiagnosticsWriter(os.Stderr)
+
+	directory, err := ioutil.TempDir("", "santa-spool")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	// The downstream syncer fails, so all records are kept in the
+	// segment files after the syncer is closed.
+	failing := &testFailingSyncer { err: errors.New("Error") }
+	option := NewSpoolSyncerOption().
+		UseDirectory(directory).
+		UseDownstream(failing).
+		UseSegmentBytes(32).
+		UseShipInterval(time.Hour)
+
+	syncer, err := option.Build()
+	assert.NoError(t, err, "Unexpected create error")
+
+	for index := 0; index < 10; index++ {
+		_, err = syncer.Write([]byte("Hello Test " +
+			strconv.Itoa(index) + "!"))
+		assert.NoError(t, err, "Unexpected write error")
+	}
+	assert.Equal(t, failing.err, syncer.Close(), "Unexpected close error")
+
+	_, err = syncer.Write([]byte("Hello Test!"))
+	assert.Equal(t, ErrClosed, err, "Unexpected write error")
+
+	segments, err := filepath.Glob(filepath.Join(directory, "*.wal"))
+	assert.NoError(t, err, "Unexpected glob error")
+	assert.True(t, len(segments) > 1, "Unexpected segment count")
+
+	// The records are replayed in order by the next syncer of the
+	// directory, and the shipped segment files are removed.
+	downstream := &testWriteSyncer { }
+	syncer, err = option.UseDownstream(downstream).Build()
+	assert.NoError(t, err, "Unexpected create error")
+
+	_, err = syncer.Write([]byte("Hello Test 10!"))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+
+	assert.Len(t, downstream.writes, 11, "Unexpected shipped records")
+	for index := 0; index < len(downstream.writes); index++ {
+		assert.Equal(t, "Hello Test " + strconv.Itoa(index) + "!",
+			downstream.writes[index], "Unexpected shipped record")
+	}
+
+	segments, err = filepath.Glob(filepath.Join(directory, "*.wal"))
+	assert.NoError(t, err, "Unexpected glob error")
+	assert.Len(t, segments, 1, "Unexpected segment count")
+
+	// Nothing is shipped again by the next syncer of the directory.
+	downstream = &testWriteSyncer { }
+	syncer, err = option.UseDownstream(downstream).Build()
+	assert.NoError(t, err, "Unexpected create error")
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+	assert.Empty(t, downstream.writes, "Unexpected shipped records")
+}
+
+// testCloseSyncer is a synchronizer that records written data and whether
+// it has been closed.
+type testCloseSyncer struct {
+	testWriteSyncer
+	closed bool
+}
+
+func (s *testCloseSyncer) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestSpoolSyncerRotateFailure(t *testing.T) {
+	directory, err := ioutil.TempDir("", "santa-spool")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	downstream := &testCloseSyncer { }
+	syncer, err := NewSpoolSyncerOption().
+		UseDirectory(directory).
+		UseDownstream(downstream).
+		UseSegmentBytes(16).
+		UseShipInterval(time.Hour).Build()
+	assert.NoError(t, err, "Unexpected create error")
+
+	// The next segment file cannot be created while the directory is
+	// moved away.
+	moved := directory + ".moved"
+	assert.NoError(t, os.Rename(directory, moved), "Unexpected rename error")
+	_, err = syncer.Write([]byte("Hello Test 0!"))
+	assert.Error(t, err, "Expected rotate error")
+	assert.NoError(t, syncer.Sync(), "Unexpected sync error")
+	assert.NoError(t, os.Rename(moved, directory), "Unexpected rename error")
+
+	// The next write creates the next segment file.
+	_, err = syncer.Write([]byte("Hello Test 1!"))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+	assert.True(t, downstream.closed, "Unexpected downstream state")
+	assert.Equal(t, []string { "Hello Test 0!", "Hello Test 1!" },
+		downstream.writes, "Unexpected shipped records")
+	assert.Equal(t, ErrClosed, syncer.Close(), "Unexpected close error")
+}
+
+func TestSpoolSyncerCloseRotateFailure(t *testing.T) {
+	SetDiagnosticsWriter(&bytes.Buffer { })
+	defer SetDiagnosticsWriter(os.Stderr)
+
+	directory, err := ioutil.TempDir("", "santa-spool")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	downstream := &testCloseSyncer { }
+	syncer, err := NewSpoolSyncerOption().
+		UseDirectory(directory).
+		UseDownstream(downstream).
+		UseSegmentBytes(16).
+		UseShipInterval(time.Hour).Build()
+	assert.NoError(t, err, "Unexpected create error")
+
+	moved := directory + ".moved"
+	assert.NoError(t, os.Rename(directory, moved), "Unexpected rename error")
+	_, err = syncer.Write([]byte("Hello Test 0!"))
+	assert.Error(t, err, "Expected rotate error")
+	assert.NoError(t, os.Rename(moved, directory), "Unexpected rename error")
+
+	// The syncer is closed after a failed rotation, so the remaining
+	// records are shipped and the downstream syncer is closed.
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+	assert.True(t, downstream.closed, "Unexpected downstream state")
+	assert.Equal(t, []string { "Hello Test 0!" }, downstream.writes,
+		"Unexpected shipped records")
+
+	_, err = syncer.Write([]byte("Hello Test!"))
+	assert.Equal(t, ErrClosed, err, "Unexpected write error")
+	assert.Equal(t, ErrClosed, syncer.Sync(), "Unexpected sync error")
+}
+
+// testTornFile is a segment file whose next write writes only part of the
+// data and fails, and whose truncation fails if the broken flag is set.
+type testTornFile struct {
+	spoolFile
+	tearing bool
+	broken bool
+}
+
+func (f *testTornFile) Write(buffer []byte) (int, error) {
+	if !f.tearing {
+		return f.spoolFile.Write(buffer)
+	}
+	f.tearing = false
+	size, _ := f.spoolFile.Write(buffer[ : len(buffer) / 2])
+	return size, errors.New("Error")
+}
+
+func (f *testTornFile) Truncate(size int64) error {
+	if f.broken {
+		return errors.New("Error")
+	}
+	return f.spoolFile.Truncate(size)
+}
+
+func TestSpoolSyncerTornWrite(t *testing.T) {
+	for _, broken := range []bool { false, true } {
+		directory, err := ioutil.TempDir("", "santa-spool")
+		assert.NoError(t, err, "Unexpected create error")
+		defer os.RemoveAll(directory)
+
+		downstream := &testWriteSyncer { }
+		syncer, err := NewSpoolSyncerOption().
+			UseDirectory(directory).
+			UseDownstream(downstream).
+			UseSegmentBytes(1024).
+			UseShipInterval(time.Hour).Build()
+		assert.NoError(t, err, "Unexpected create error")
+
+		_, err = syncer.Write([]byte("Hello Test 0!"))
+		assert.NoError(t, err, "Unexpected write error")
+
+		syncer.mutex.Lock()
+		syncer.file = &testTornFile {
+			spoolFile: syncer.file,
+			tearing: true,
+			broken: broken,
+		}
+		syncer.mutex.Unlock()
+
+		// The torn record is not followed by the next records.
+		_, err = syncer.Write([]byte("Hello Test 1!"))
+		assert.Error(t, err, "Expected write error")
+		_, err = syncer.Write([]byte("Hello Test 2!"))
+		assert.NoError(t, err, "Unexpected write error")
+		assert.NoError(t, syncer.Close(), "Unexpected close error")
+		assert.Equal(t, []string { "Hello Test 0!", "Hello Test 2!" },
+			downstream.writes, "Unexpected shipped records")
+	}
+}
@@ -22,7 +22,10 @@
 
 package santa
 
-import "sync/atomic"
+import (
+	"context"
+	"time"
+)
 
 // StructLogger is the structure of a structured logger instance.
 //
@@ -51,10 +54,10 @@ import "sync/atomic"
 // details, please refer to the comment section of the Syncer interface.
 //
 // Unless explicitly stated, the API provided by the logger is
-// thread-safe. It’s worth noting that APIs that allow post-build
-// changes to logger instances are generally not thread-safe. If you
-// need to change the logger instance (including but not limited to:
-// minimum log entry level, etc.), use the Duplicate function to create
+// thread-safe, including the APIs that allow post-build changes to
+// logger instances (including but not limited to: minimum log entry
+// level, etc.). If you need to change the logger instance without
+// affecting other users of it, use the Duplicate function to create
 // a copy of the logger instance, and then make changes to the copy of
 // the logger instance.
 type StructLogger struct {
@@ -121,19 +124,126 @@ func (l *StructLogger) Fatals(text string, fields ...Field) error {
 	return err
 }
 
+// Printw outputs a structured log message with a given log level, given
+// description text and fields built from the given alternating keys and
+// values, and then returns any errors encountered. For details, please
+// refer to the comment section of the KeysAndValues function.
+func (l *StructLogger) Printw(level Level, text string, keysAndValues ...interface { }) error {
+	message := pool.Message.Structure.New(text, KeysAndValues(keysAndValues...))
+	err := l.Output(2, level, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// Debugw outputs a structured log message with a log level of DEBUG,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func (l *StructLogger) Debugw(text string, keysAndValues ...interface { }) error {
+	message := pool.Message.Structure.New(text, KeysAndValues(keysAndValues...))
+	err := l.Output(2, LevelDebug, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// Infow outputs a structured log message with a log level of INFO,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func (l *StructLogger) Infow(text string, keysAndValues ...interface { }) error {
+	message := pool.Message.Structure.New(text, KeysAndValues(keysAndValues...))
+	err := l.Output(2, LevelInfo, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// Warningw outputs a structured log message with a log level of WARNING,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func (l *StructLogger) Warningw(text string, keysAndValues ...interface { }) error {
+	message := pool.Message.Structure.New(text, KeysAndValues(keysAndValues...))
+	err := l.Output(2, LevelWarning, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// Errorw outputs a structured log message with a log level of ERROR,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func (l *StructLogger) Errorw(text string, keysAndValues ...interface { }) error {
+	message := pool.Message.Structure.New(text, KeysAndValues(keysAndValues...))
+	err := l.Output(2, LevelError, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// Fatalw outputs a structured log message with a log level of FATAL,
+// given description text and fields built from the given alternating keys
+// and values, and then returns any errors encountered.
+func (l *StructLogger) Fatalw(text string, keysAndValues ...interface { }) error {
+	message := pool.Message.Structure.New(text, KeysAndValues(keysAndValues...))
+	err := l.Output(2, LevelFatal, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// DPanics outputs a structured log message with a log level of ERROR,
+// given description text and fields, and then returns any errors
+// encountered. If the logger is in development mode, it panics after the
+// log entry is output. For details, please refer to the comment section
+// of the Development option.
+func (l *StructLogger) DPanics(text string, fields ...Field) error {
+	message := pool.Message.Structure.New(text, fields)
+	err := l.OutputDPanic(2, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
+// DPanicw outputs a structured log message with given description text and
+// fields built from the given alternating keys and values like the DPanics
+// function, and then returns any errors encountered.
+func (l *StructLogger) DPanicw(text string, keysAndValues ...interface { }) error {
+	message := pool.Message.Structure.New(text, KeysAndValues(keysAndValues...))
+	err := l.OutputDPanic(2, message)
+	pool.Message.Structure.Free(message)
+	return err
+}
+
 // Duplicate creates and returns a copy of the logger. If the logger is
 // closed, it returns nil.
 //
 // Please note that the application must explicitly close each copy of
 // the logger, otherwise the logger may be leaked.
 func (l *StructLogger) Duplicate() *StructLogger {
-	if atomic.AddInt32(l.contextReferences, 1) == 1 {
-		// The logger has been shut down, and using the created copy
-		// may cause panic.
+	instance, ok := l.duplicate()
+	if !ok {
 		return nil
 	}
-	instance := *l
-	return &instance
+	return &StructLogger {
+		StandardLogger: instance,
+	}
+}
+
+// Decorator gets and returns a structured decorator instance of the
+// logger from the global pool. For details, please refer to the comment
+// section of the StructDecorator structure.
+func (l *StructLogger) Decorator() *StructDecorator {
+	instance := pool.decorator.structure.Get().(*StructDecorator)
+	instance.reset(&l.Logger)
+	return instance
+}
+
+// WithContext gets and returns a structured decorator instance of the
+// logger from the global pool, to which the trace_id, span_id and
+// trace_flags fields of the span context of the given context are added,
+// so that the log entries can be joined with distributed traces. If the
+// given context does not have a valid span context, no field is added.
+// For details, please refer to the comment section of the StructDecorator
+// structure.
+func (l *StructLogger) WithContext(ctx context.Context) *StructDecorator {
+	decorator := l.Decorator()
+	if span, ok := SpanContextFromContext(ctx); ok {
+		decorator.AddFields(span.Fields()...)
+	}
+	return decorator
 }
 
 // StructOption is a structure that contains options for structured
@@ -174,6 +284,87 @@ func (o *StructOption) UseLabels(labels ...Label) *StructOption {
 	return o
 }
 
+// UseClock uses the given clock as the value of the option Clock. For
+// details, please refer to the comment section of the Clock option. Then
+// return to the option instance itself.
+func (o *StructOption) UseClock(clock Clock) *StructOption {
+	o.Clock = clock
+	return o
+}
+
+// UseClockResolution uses the given resolution as the value of the option
+// ClockResolution. For details, please refer to the comment section of the
+// ClockResolution option. Then return to the option instance itself.
+func (o *StructOption) UseClockResolution(resolution time.Duration) *StructOption {
+	o.ClockResolution = resolution
+	return o
+}
+
+// UseSequence enables the option Sequence. For details, please refer to
+// the comment section of the Sequence option. Then return to the option
+// instance itself.
+func (o *StructOption) UseSequence() *StructOption {
+	o.Sequence = true
+	return o
+}
+
+// UseErrorHandler uses the given function as the value of the option
+// ErrorHandler. For details, please refer to the comment section of the
+// ErrorHandler option. Then return to the option instance itself.
+func (o *StructOption) UseErrorHandler(handler func(error)) *StructOption {
+	o.ErrorHandler = handler
+	return o
+}
+
+// UseSourceLocationLevel uses the given log level as the value of the
+// option SourceLocationLevel. For details, please refer to the comment
+// section of the SourceLocationLevel option. Then return to the option
+// instance itself.
+func (o *StructOption) UseSourceLocationLevel(level Level) *StructOption {
+	o.SourceLocationLevel = level
+	return o
+}
+
+// UseCallerSkip uses the given number of stack frames as the value of the
+// option CallerSkip. For details, please refer to the comment section of
+// the CallerSkip option. Then return to the option instance itself.
+func (o *StructOption) UseCallerSkip(skip int) *StructOption {
+	o.CallerSkip = skip
+	return o
+}
+
+// UseBestEffort enables the option BestEffort. For details, please refer
+// to the comment section of the BestEffort option. Then return to the
+// option instance itself.
+func (o *StructOption) UseBestEffort() *StructOption {
+	o.BestEffort = true
+	return o
+}
+
+// UseDevelopment enables the option Development. For details, please refer
+// to the comment section of the Development option. Then return to the
+// option instance itself.
+func (o *StructOption) UseDevelopment() *StructOption {
+	o.Development = true
+	return o
+}
+
+// UseProfiling enables the option Profiling. For details, please refer
+// to the comment section of the Profiling option. Then return to the
+// option instance itself.
+func (o *StructOption) UseProfiling() *StructOption {
+	o.Profiling = true
+	return o
+}
+
+// UseExporters appends the given one or more exporters to the o.Exporters
+// option slice, and then returns the option instance itself. For details,
+// please refer to the comment section of the o.Exporters option.
+func (o *StructOption) UseExporters(exporters ...Exporter) *StructOption {
+	o.Exporters = append(o.Exporters, exporters...)
+	return o
+}
+
 // UseSampling uses the given sampling option as the value of option Sampling.
 // For details, please refer to the comment section of the Sampling option.
 // Then return to the option instance itself.
@@ -214,6 +405,16 @@ func (o *StructOption) UseFlushing(option *FlushingOption) *StructOption {
 	return o
 }
 
+// UseExporterFlushing appends the given exporter to the Exporters option,
+// and uses the given flushing option as the option for automatic flushing
+// of the exporter. For details, see the comment section of the
+// ExporterFlushing option. Then return to the option instance itself.
+func (o *StructOption) UseExporterFlushing(exporter Exporter,
+	option *FlushingOption) *StructOption {
+	o.StandardOption.UseExporterFlushing(exporter, option)
+	return o
+}
+
 // DisableCache Disable the internal cache of output and error output. For
 // details, please refer to the DisableCache option of the OutputtingOption
 // structure. Then return to the option instance itself.
@@ -239,6 +440,15 @@ func (o *StructOption) DisableFlushing() *StructOption {
 	return o
 }
 
+// Clone creates and returns a deep copy of the option instance. For
+// details, please refer to the comment section of the StandardOption.Clone
+// function.
+func (o *StructOption) Clone() *StructOption {
+	return &StructOption {
+		StandardOption: *o.StandardOption.Clone(),
+	}
+}
+
 // Build builds and returns a structured logger instance.
 func (o *StructOption) Build() (*StructLogger, error) {
 	logger, err := o.StandardOption.Build()
@@ -250,6 +460,14 @@ func (o *StructOption) Build() (*StructLogger, error) {
 	}, nil
 }
 
+// MustBuild builds and returns a structured logger instance. If any errors
+// are encountered, it panics with an error describing the failure.
+func (o *StructOption) MustBuild() *StructLogger {
+	instance, err := o.Build()
+	mustBuild("structured logger", err)
+	return instance
+}
+
 // NewStructOption creates an instance of a structured logger option with
 // default optional values.
 func NewStructOption() *StructOption {
@@ -261,9 +479,20 @@ func NewStructOption() *StructOption {
 }
 
 // NewStruct creates and returns a structured logger instance using default
-// optional values.
-func NewStruct() (*StructLogger, error) {
-	return NewStructOption().Build()
+// optional values changed by the given option functions.
+func NewStruct(options ...OptionFunc) (*StructLogger, error) {
+	option := NewStructOption()
+	option.Apply(options...)
+	return option.Build()
+}
+
+// MustNewStruct creates and returns a structured logger instance using
+// default optional values changed by the given option functions. If any
+// errors are encountered, it panics with an error describing the failure.
+func MustNewStruct(options ...OptionFunc) *StructLogger {
+	option := NewStructOption()
+	option.Apply(options...)
+	return option.MustBuild()
 }
 
 // NewStructBenchmark creates and returns an instance of a structured logger
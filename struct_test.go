@@ -69,13 +69,13 @@ func TestStructLoggerOption(t *testing.T) {
 	logger, err := option.Build()
 	assert.NoError(t, err, "Unexpected build error")
 
-	assert.NotNil(t, logger.sampler, "Unexpected instance error")
+	assert.NotNil(t, logger.loadSettings().sampler, "Unexpected instance error")
 	assert.Len(t, logger.exporters, 2, "Unexpected instance error")
 	assert.NotNil(t, logger.exporters[0], "Unexpected instance error")
 	assert.NotNil(t, logger.exporters[1], "Unexpected instance error")
 
-	assert.Equal(t, option.Level, logger.level, "Unexpected instance error")
-	assert.Equal(t, option.Name, logger.name, "Unexpected instance error")
+	assert.Equal(t, option.Level, logger.loadSettings().level, "Unexpected instance error")
+	assert.Equal(t, option.Name, logger.loadSettings().name, "Unexpected instance error")
 
 	option.DisableCache()
 	option.DisableFlushing()
@@ -96,6 +96,55 @@ func TestStructLoggerOption(t *testing.T) {
 	assert.NoError(t, logger.Close(), "Unexpected close error")
 }
 
+func TestStructLoggerExporters(t *testing.T) {
+	exporter := &testRecordExporter { }
+	option := NewStructOption().UseExporters(exporter)
+	option.Outputting.UseDiscard()
+	option.ErrorOutputting.UseDiscard()
+
+	logger, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.Len(t, logger.exporters, 3, "Unexpected instance error")
+
+	assert.NoError(t, logger.Errors("Hello Test!"), "Unexpected print error")
+	assert.Len(t, exporter.entries, 1, "Unexpected export result")
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+}
+
+func TestStructLoggerFields(t *testing.T) {
+	exporter := &testRecordExporter { }
+	option := NewStructOption().UseExporters(exporter)
+	option.Outputting.UseDiscard()
+	option.ErrorOutputting.UseDiscard()
+
+	logger, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	var fields ElementObject
+	logger.AddHooks(NewSimpleHook(func(entry *Entry) error {
+		fields = entry.Fields.Clone()
+		return nil
+	}))
+
+	assert.NoError(t, logger.Infos("Hello Test!", String("name", "test")),
+		"Unexpected print error")
+	assert.Equal(t, ElementObject { String("name", "test") }, fields,
+		"Unexpected entry fields")
+	assert.Equal(t, ElementObject { String("name", "test") },
+		exporter.entries[0].Fields, "Unexpected entry fields")
+
+	assert.NoError(t, logger.Infow("Hello Test!", "age", 100),
+		"Unexpected print error")
+	assert.Equal(t, ElementObject { Int("age", 100) }, fields,
+		"Unexpected entry fields")
+	assert.Contains(t, exporter.entries[1].SourceLocation.File,
+		"struct_test.go", "Unexpected source location")
+
+	assert.NoError(t, logger.Info("Hello Test!"), "Unexpected print error")
+	assert.Nil(t, fields, "Unexpected entry fields")
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+}
+
 func TestStructLoggerBenchmark(t *testing.T) {
 	logger, err := NewStructBenchmark(true, EncoderJSON)
 	assert.NoError(t, err, "Unexpected create error")
@@ -149,6 +198,24 @@ func TestStructLoggerPrint(t *testing.T) {
 		"name", "test"), Int("age", 100))
 	assert.NoError(t, err, "Unexpected print error")
 
+	err = logger.Debugw("Hello Test!", "name", "test", "age", 100)
+	assert.NoError(t, err, "Unexpected print error")
+
+	err = logger.Infow("Hello Test!", "name", "test", "age", 100)
+	assert.NoError(t, err, "Unexpected print error")
+
+	err = logger.Warningw("Hello Test!", "name", "test", "age", 100)
+	assert.NoError(t, err, "Unexpected print error")
+
+	err = logger.Errorw("Hello Test!", "name", "test", "age", 100)
+	assert.NoError(t, err, "Unexpected print error")
+
+	err = logger.Fatalw("Hello Test!", "name", "test", "age", 100)
+	assert.NoError(t, err, "Unexpected print error")
+
+	err = logger.Printw(LevelWarning, "Hello Test!", "name", "test")
+	assert.NoError(t, err, "Unexpected print error")
+
 	assert.NoError(t, logger.Close(), "Unexpected close error")
 }
 
@@ -161,9 +228,22 @@ func TestStructLoggerDuplicate(t *testing.T) {
 	assert.NotNil(t, instance, "Unexpected nil value")
 
 	instance.SetName("testing")
-	assert.Equal(t, "testing", instance.name, "Unexpected instance error")
-	assert.Equal(t, "", logger.name, "Unexpected instance error")
+	assert.Equal(t, "testing", instance.loadSettings().name, "Unexpected instance error")
+	assert.Equal(t, "", logger.loadSettings().name, "Unexpected instance error")
 
 	assert.NoError(t, instance.Close(), "Unexpected close error")
 	assert.NoError(t, logger.Close(), "Unexpected close error")
 }
+
+func TestStructLoggerMustBuild(t *testing.T) {
+	logger := MustNewStruct()
+	assert.NotNil(t, logger, "Unexpected logger instance")
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+
+	option := NewStructOption()
+	option.Outputting.Type = "invalid"
+	assert.PanicsWithError(t, "santa: failed to build structured logger: " +
+		"invalid type", func() {
+		option.MustBuild()
+	}, "Unexpected panic error")
+}
@@ -25,14 +25,18 @@ package santa
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"runtime"
-	"strings"
+	"strconv"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 )
 
@@ -93,6 +97,52 @@ type Syncer interface {
 	Close() error
 }
 
+var (
+	// ErrInvalidCacheCapacity represents that the capacity of the internal
+	// cache of a synchronizer is negative.
+	ErrInvalidCacheCapacity = errors.New("invalid cache capacity")
+
+	// ErrInvalidCacheShards represents that the number of shards of the
+	// internal cache of a synchronizer is negative.
+	ErrInvalidCacheShards = errors.New("invalid number of cache shards")
+)
+
+// Banner is the type of function that appends a header or footer written
+// by synchronizers to the given buffer slice, and then returns the
+// appended buffer slice.
+//
+// Headers and footers make the written data well-formed batch files, for
+// example, files with a CSV header row, or files that start and end with
+// lines that contain process metadata.
+type Banner func(buffer []byte) []byte
+
+// TextBanner creates and returns a banner that appends the given text,
+// such as the header row of CSV files.
+func TextBanner(text string) Banner {
+	return func(buffer []byte) []byte {
+		return append(buffer, text...)
+	}
+}
+
+// ProcessBanner creates and returns a banner that appends a line with the
+// given event, the current time and the metadata of the process, such as
+// "log started at 2024-05-01T08:00:00Z (pid 42, host web-1, program app)".
+func ProcessBanner(event string) Banner {
+	return func(buffer []byte) []byte {
+		host, _ := os.Hostname()
+		buffer = append(buffer, event...)
+		buffer = append(buffer, " at "...)
+		buffer = time.Now().AppendFormat(buffer, time.RFC3339Nano)
+		buffer = append(buffer, " (pid "...)
+		buffer = strconv.AppendInt(buffer, int64(os.Getpid()), 10)
+		buffer = append(buffer, ", host "...)
+		buffer = append(buffer, host...)
+		buffer = append(buffer, ", program "...)
+		buffer = append(buffer, filepath.Base(os.Args[0])...)
+		return append(buffer, ")\n"...)
+	}
+}
+
 // SyncerOption is a structure containing basic synchronizer options.
 //
 // The synchronizer options include basic synchronizer options. Normally,
@@ -104,8 +154,60 @@ type SyncerOption struct {
 	// than or equal to 1,024 bytes.
 	//
 	// If not provided, the default value is 32 KB * the number of
-	// logical processors.
+	// logical processors. Please note that the standard synchronizer
+	// allocates two swap buffers of the cache capacity.
 	CacheCapacity int
+
+	// CacheShards represents the number of shards of the internal cache.
+	// Each shard has its own swap buffers and spin lock, and writers
+	// prefer the shard of their logical processor, which reduces the
+	// contention on machines with many logical processors. The cache
+	// capacity and the FlushBytes option are divided evenly among the
+	// shards, and the Sync function flushes the shards in order.
+	//
+	// Please note that if there is more than one shard, the order of data
+	// written by different writes is not guaranteed, even if they are
+	// written by the same coroutine. If not provided, the default value
+	// is 1.
+	CacheShards int
+
+	// FlushBytes represents the number of buffered bytes of the internal
+	// cache after which the internal cache is flushed. If not provided or
+	// the value is 0, the internal cache is flushed only when its capacity
+	// is saturated.
+	FlushBytes int
+
+	// FlushAge represents the maximum time that the oldest buffered byte
+	// of the internal cache may wait before the internal cache is flushed.
+	// A timer is armed when data is written to the empty internal cache,
+	// so the flushing does not depend on subsequent writes or on the
+	// automatic flushing of the logger. If not provided or the value is 0,
+	// the internal cache is not flushed based on time.
+	FlushAge time.Duration
+
+	// Header represents the banner that is written when the synchronizer
+	// is built, before any log entry data. If not provided, no header is
+	// written.
+	Header Banner
+
+	// Footer represents the banner that is written when the synchronizer
+	// is closed, after all log entry data. If not provided, no footer is
+	// written.
+	Footer Banner
+}
+
+// Validate checks whether the values of the options are valid, and returns
+// an error wrapping ErrInvalidCacheCapacity or ErrInvalidCacheShards if
+// not. Positive cache capacities less than 1,024 bytes are valid, they are
+// raised to 1,024 bytes when the synchronizer is built.
+func (o SyncerOption) Validate() error {
+	if o.CacheCapacity < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidCacheCapacity, o.CacheCapacity)
+	}
+	if o.CacheShards < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidCacheShards, o.CacheShards)
+	}
+	return nil
 }
 
 // NewSyncerOption returns the value of a synchronizer option with the
@@ -113,6 +215,7 @@ type SyncerOption struct {
 func NewSyncerOption() SyncerOption {
 	return SyncerOption {
 		CacheCapacity: (1024 * 32) * runtime.NumCPU(),
+		CacheShards: 1,
 	}
 }
 
@@ -123,37 +226,213 @@ func NewSyncerOption() SyncerOption {
 // data type of the instance when it is closed, and it does not close the
 // instance (if supported).
 //
+// If the internal cache is enabled, the standard synchronizer uses two
+// swap buffers of the cache capacity. Data is written to the active buffer
+// while holding a spin lock for a very short time. When the active buffer
+// is flushed, it is sealed and swapped with the spare buffer, and the
+// sealed buffer is written to the storage device without holding the spin
+// lock, so that writes continue into the other buffer.
+//
+// The internal cache can be divided into multiple shards, each of which
+// has its own swap buffers and spin lock. For details, please refer to the
+// comment section of the CacheShards option.
+//
 // Please note that if the mutex is disabled, the API provided by
 // the synchronizer is not thread-safe.
 type StandardSyncer struct {
 	writer io.Writer
-	buffer []byte
+	shards []syncerShard
+	shardHints *sync.Pool
 	capacity int
+	shardCapacity int
+	cached bool
+	vectored bool
+	writerMutex *sync.Mutex
+
+	flushBytes int
+	flushAge time.Duration
+	footer Banner
+	timer *time.Timer
+	timerArmed int32
+
+	syncMutex *sync.Mutex
+	syncCond *sync.Cond
+	syncRunning bool
+	syncNext *syncerFlight
+	syncs uint64
+	coalescedSyncs uint64
+	lastSync int64
+
+	closed int32
+}
+
+// syncerFlight is the structure of a flush shared by the overlapping calls
+// of the Sync function of the standard synchronizer.
+type syncerFlight struct {
+	done chan struct { }
+	err error
+}
+
+// SyncerStats is a structure that contains the statistics of the Sync
+// function of a standard synchronizer.
+type SyncerStats struct {
+	// Syncs represents the number of flushes performed by the Sync
+	// function.
+	Syncs uint64
+
+	// CoalescedSyncs represents the number of calls of the Sync function
+	// that shared the flush of another call instead of performing their
+	// own flush.
+	CoalescedSyncs uint64
+
+	// LastSync represents the time when the last flush performed by the
+	// Sync function completed. If no flush has been performed, it is the
+	// zero time.
+	LastSync time.Time
+}
+
+// syncerShard is the structure of a shard of the internal cache of the
+// standard synchronizer.
+type syncerShard struct {
 	mutex *SpinLock
+	buffer []byte
+	spare []byte
+}
+
+// shard returns the shard of the internal cache that the current logical
+// processor prefers. The preference is kept by a sync.Pool instance, whose
+// cached objects are local to logical processors.
+func (s *StandardSyncer) shard() *syncerShard {
+	if len(s.shards) == 1 {
+		return &s.shards[0]
+	}
+	hint := s.shardHints.Get().(*int)
+	shard := &s.shards[*hint]
+	s.shardHints.Put(hint)
+	return shard
+}
+
+// append appends the data of a given buffer slice to the active buffer of
+// the given shard, and arms the timer of the maximum age if the active
+// buffer was empty.
+//
+// Please note that the caller must hold the spin lock of the shard.
+func (s *StandardSyncer) append(shard *syncerShard, buffer []byte) {
+	if s.timer != nil && len(shard.buffer) == 0 &&
+		atomic.CompareAndSwapInt32(&s.timerArmed, 0, 1) {
+		s.timer.Reset(s.flushAge)
+	}
+	shard.buffer = append(shard.buffer, buffer...)
 }
 
-// flush writes the data stored in the internal cache to a specific storage
-// device, and then returns the actual number of bytes written and any
+// flush seals the active buffer of the given shard and swaps it with the
+// spare buffer, and then writes the sealed buffer to a specific storage
+// device without holding the spin lock. If the sealed buffer is written
+// and the given extra buffer slice is not nil and fits the shard, it is
+// appended to the active buffer, and true is returned. Data that has not
+// been written because of an error is kept in the shard in order.
+//
+// Finally, it returns whether the extra buffer has been cached and any
 // errors encountered.
 //
-// Please note that this function is not thread-safe.
-func (s *StandardSyncer) flush() (int, error) {
-	suspended := s.mutex != nil && s.mutex.Suspend()
-	size, err := s.writer.Write(s.buffer)
+// Please note that the caller must hold the writer mutex, but must not
+// hold the spin lock of the shard.
+func (s *StandardSyncer) flush(shard *syncerShard, extra []byte) (bool, error) {
+	shard.mutex.Lock()
+	if extra != nil && len(shard.buffer) + len(extra) < s.shardCapacity {
+		// Another writer has flushed the active buffer while the caller
+		// was waiting for the writer mutex.
+		s.append(shard, extra)
+		shard.mutex.Unlock()
+		return true, nil
+	}
+	sealed := shard.buffer
+	shard.buffer = shard.spare[ : 0]
+	shard.spare = nil
+	shard.mutex.Unlock()
+
+	var size int
+	var err error
+	if len(sealed) > 0 {
+		size, err = s.writer.Write(sealed)
+	}
+
+	shard.mutex.Lock()
 	if err != nil {
-		if size > 0 {
-			s.buffer = append(s.buffer[ : 0], s.buffer[size : ]...)
-		}
-		if suspended {
-			s.mutex.Resume()
+		// The data that has not been written precedes the data written
+		// to the active buffer in the meantime. The extra buffer is not
+		// cached, so that the internal cache does not grow while the
+		// storage device is failing.
+		remaining := append(sealed[ : 0], sealed[size : ]...)
+		shard.spare = shard.buffer[ : 0]
+		shard.buffer = append(remaining, shard.buffer...)
+		shard.mutex.Unlock()
+		return false, err
+	}
+	shard.spare = sealed[ : 0]
+	cached := extra != nil && len(shard.buffer) + len(extra) < s.shardCapacity
+	if cached {
+		s.append(shard, extra)
+	}
+	shard.mutex.Unlock()
+	return cached, nil
+}
+
+// flushVectored seals the active buffer of the given shard like the flush
+// function, and then writes the sealed buffer and the given extra buffer
+// slice to the file with a single vectored write, so that the extra buffer
+// slice is not copied into the internal cache. If the extra buffer slice
+// fits the shard because another writer has flushed the active buffer in
+// the meantime, it is appended to the active buffer instead.
+//
+// Finally, it returns the number of bytes of the extra buffer slice that
+// have been written or cached and any errors encountered.
+//
+// Please note that the caller must hold the writer mutex, but must not
+// hold the spin lock of the shard, and the writer must be a file.
+func (s *StandardSyncer) flushVectored(shard *syncerShard, extra []byte) (int, error) {
+	shard.mutex.Lock()
+	if len(shard.buffer) + len(extra) < s.shardCapacity {
+		s.append(shard, extra)
+		shard.mutex.Unlock()
+		return len(extra), nil
+	}
+	sealed := shard.buffer
+	shard.buffer = shard.spare[ : 0]
+	shard.spare = nil
+	shard.mutex.Unlock()
+
+	size, err := writeVectored(s.writer.(*os.File), [][]byte { sealed, extra })
+
+	shard.mutex.Lock()
+	if size < len(sealed) {
+		// The data that has not been written is kept in the shard in
+		// order, and the extra buffer slice is not written.
+		remaining := append(sealed[ : 0], sealed[size : ]...)
+		shard.spare = shard.buffer[ : 0]
+		shard.buffer = append(remaining, shard.buffer...)
+		shard.mutex.Unlock()
+		if err == nil {
+			err = io.ErrShortWrite
 		}
-		return size, err
+		return 0, err
 	}
-	s.buffer = s.buffer[ : 0]
-	if suspended {
-		s.mutex.Resume()
+	shard.spare = sealed[ : 0]
+	shard.mutex.Unlock()
+	return size - len(sealed), err
+}
+
+// flushShards flushes all shards of the internal cache in order, and then
+// returns the first error encountered.
+//
+// Please note that the caller must hold the writer mutex.
+func (s *StandardSyncer) flushShards() error {
+	for index := 0; index < len(s.shards); index++ {
+		if _, err := s.flush(&s.shards[index], nil); err != nil {
+			return err
+		}
 	}
-	return size, nil
+	return nil
 }
 
 // Write writes the data of a given buffer slice to a specific storage
@@ -164,81 +443,273 @@ func (s *StandardSyncer) flush() (int, error) {
 // Finally, it returns the number of bytes actually written and any
 // errors encountered.
 func (s *StandardSyncer) Write(buffer []byte) (int, error) {
-	if s.mutex != nil {
-		s.mutex.Lock()
-	}
-	if s.buffer != nil {
-		size := len(s.buffer) + len(buffer)
-		if size >= s.capacity {
-			_, err := s.flush()
-			if err != nil {
-				if s.mutex != nil {
-					s.mutex.Unlock()
-				}
-				return 0, err
-			}
-			size = len(buffer)
+	if s.isClosed() {
+		return 0, ErrClosed
+	}
+	if !s.cached {
+		if s.writerMutex != nil {
+			s.writerMutex.Lock()
+		}
+		size, err := s.writer.Write(buffer)
+		if s.writerMutex != nil {
+			s.writerMutex.Unlock()
 		}
-		if size < s.capacity {
-			s.buffer = append(s.buffer, buffer...)
-			if s.mutex != nil {
-				s.mutex.Unlock()
+		return size, err
+	}
+	shard := s.shard()
+	shard.mutex.Lock()
+	if len(shard.buffer) + len(buffer) < s.shardCapacity {
+		s.append(shard, buffer)
+		threshold := s.flushBytes > 0 && len(shard.buffer) >= s.flushBytes
+		shard.mutex.Unlock()
+		if threshold {
+			// The data has been cached, so the flushing error is not
+			// returned, and the data that has not been written is kept
+			// in the internal cache for the next flushing.
+			s.writerMutex.Lock()
+			if _, err := s.flush(shard, nil); err != nil {
+				diagnose("flushing on threshold failed: %v", err)
 			}
-			return len(buffer), nil
+			s.writerMutex.Unlock()
 		}
+		return len(buffer), nil
 	}
-	if s.mutex != nil {
-		s.mutex.Suspend()
+	shard.mutex.Unlock()
+
+	s.writerMutex.Lock()
+	if s.vectored {
+		size, err := s.flushVectored(shard, buffer)
+		s.writerMutex.Unlock()
+		return size, err
 	}
-	size, err := s.writer.Write(buffer)
-	if s.mutex != nil {
-		s.mutex.UnlockAndResume()
+	cached, err := s.flush(shard, buffer)
+	if err != nil {
+		s.writerMutex.Unlock()
+		return 0, err
 	}
+	if cached {
+		s.writerMutex.Unlock()
+		return len(buffer), nil
+	}
+	// The data does not fit the internal cache, so it is written to the
+	// storage device directly after the sealed buffer.
+	size, err := s.writer.Write(buffer)
+	s.writerMutex.Unlock()
 	return size, err
 }
 
+// ageHandler flushes the internal cache after the oldest buffered byte
+// has reached the maximum age. It is called by the timer of the
+// synchronizer in an independent coroutine context.
+func (s *StandardSyncer) ageHandler() {
+	s.writerMutex.Lock()
+	atomic.StoreInt32(&s.timerArmed, 0)
+	if s.isClosed() {
+		// The internal cache has been flushed by the Close function.
+		s.writerMutex.Unlock()
+		return
+	}
+	if err := s.flushShards(); err != nil {
+		diagnose("flushing on age failed: %v", err)
+	}
+	s.writerMutex.Unlock()
+}
+
 // Sync writes the internally cached data to a specific storage device.
 // If the specific storage device is based on the file system, write the
 // data cached by the file system to the persistent storage device.
 //
-// Finally, any errors encountered are returned.
+// Overlapping calls are coalesced: if a flush is in progress, the calls
+// made in the meantime wait for it to complete and then share a single
+// following flush, which covers all data written before any of them was
+// made. This avoids serializing the file system synchronization of the
+// automatic flushing and manual calls.
+//
+// Finally, any errors encountered are returned. If the synchronizer has
+// been closed, ErrClosed is returned.
 func (s *StandardSyncer) Sync() error {
-	if s.mutex != nil {
-		s.mutex.LockAndSuspend()
+	if s.isClosed() {
+		return ErrClosed
+	}
+	if s.syncMutex == nil {
+		// The synchronizer is not thread-safe, so calls cannot overlap.
+		err := s.sync()
+		s.synced()
+		return err
+	}
+	s.syncMutex.Lock()
+	if flight := s.syncNext; flight != nil {
+		// Another call is waiting to perform the next flush, which also
+		// covers the data written before this call.
+		s.syncMutex.Unlock()
+		atomic.AddUint64(&s.coalescedSyncs, 1)
+		<-flight.done
+		return flight.err
 	}
-	if len(s.buffer) > 0 {
-		_, err := s.flush()
+	flight := &syncerFlight {
+		done: make(chan struct { }),
+	}
+	if s.syncRunning {
+		// The flush in progress may not cover the data written before
+		// this call, so the next flush is performed after it.
+		s.syncNext = flight
+		for s.syncRunning {
+			s.syncCond.Wait()
+		}
+		s.syncNext = nil
+	}
+	s.syncRunning = true
+	s.syncMutex.Unlock()
+
+	flight.err = s.sync()
+	s.synced()
+	close(flight.done)
+
+	s.syncMutex.Lock()
+	s.syncRunning = false
+	s.syncCond.Broadcast()
+	s.syncMutex.Unlock()
+	return flight.err
+}
+
+// synced records a completed flush of the Sync function in the statistics
+// of the synchronizer.
+func (s *StandardSyncer) synced() {
+	atomic.AddUint64(&s.syncs, 1)
+	atomic.StoreInt64(&s.lastSync, time.Now().UnixNano())
+}
+
+// Stats returns the statistics of the Sync function of the synchronizer.
+// For details, please refer to the comment section of the SyncerStats
+// structure.
+//
+// This function is thread-safe.
+func (s *StandardSyncer) Stats() SyncerStats {
+	stats := SyncerStats {
+		Syncs: atomic.LoadUint64(&s.syncs),
+		CoalescedSyncs: atomic.LoadUint64(&s.coalescedSyncs),
+	}
+	if last := atomic.LoadInt64(&s.lastSync); last != 0 {
+		stats.LastSync = time.Unix(0, last)
+	}
+	return stats
+}
+
+// sync is the implementation of the Sync function, which does not check
+// whether the synchronizer has been closed.
+func (s *StandardSyncer) sync() error {
+	if s.writerMutex != nil {
+		s.writerMutex.Lock()
+	}
+	if s.cached {
+		err := s.flushShards()
 		if err != nil {
-			if s.mutex != nil {
-				s.mutex.UnlockAndResume()
-			}
+			s.writerMutex.Unlock()
 			return err
 		}
 	}
-	handle, ok := s.writer.(*os.File)
+	handle, ok := s.writer.(syncWriter)
 	if !ok {
-		if s.mutex != nil {
-			s.mutex.UnlockAndResume()
+		if s.writerMutex != nil {
+			s.writerMutex.Unlock()
 		}
 		return nil
 	}
 	err := handle.Sync()
-	if s.mutex != nil {
-		s.mutex.UnlockAndResume()
+	if s.writerMutex != nil {
+		s.writerMutex.Unlock()
+	}
+	if err != nil && isUnsyncableError(err, isRegularFile(handle)) {
+		// Some files (including but not limited to: pipes, terminals
+		// and character devices) do not support synchronization, and
+		// there is no cached data that needs to be written.
+		return nil
 	}
 	return err
 }
 
+// isRegularFile checks whether the given writer is a regular file. If
+// the writer does not provide file information, it is treated as a
+// regular file, so that its synchronization errors are not ignored.
+func isRegularFile(writer syncWriter) bool {
+	file, ok := writer.(interface { Stat() (os.FileInfo, error) })
+	if !ok {
+		return true
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return true
+	}
+	return info.Mode().IsRegular()
+}
+
+// syncWriter is the interface of storage devices whose written data can
+// be synchronized to the persistent storage device, such as files.
+type syncWriter interface {
+	io.Writer
+	Sync() error
+}
+
 // Close automatically flushes the internal cache once, and then releases
 // any kernel objects that have been opened (including but not limited to:
 // file handles, etc.).
 //
-// Finally, any errors encountered are returned.
+// Finally, any errors encountered are returned. If the synchronizer has
+// already been closed, ErrClosed is returned, and the Write and Sync
+// functions also return ErrClosed after the synchronizer is closed.
 func (s *StandardSyncer) Close() error {
-	_ = s.Sync()
+	if !s.markClosed() {
+		return ErrClosed
+	}
+	s.close()
 	return nil
 }
 
+// markClosed marks the synchronizer as closed, and then returns whether
+// it was marked by this call. False is returned if the synchronizer has
+// already been closed.
+func (s *StandardSyncer) markClosed() bool {
+	return atomic.CompareAndSwapInt32(&s.closed, 0, 1)
+}
+
+// isClosed checks whether the synchronizer has been closed.
+func (s *StandardSyncer) isClosed() bool {
+	return atomic.LoadInt32(&s.closed) == 1
+}
+
+// close stops the timer of the maximum age, writes the footer (if any) and
+// flushes the internal cache once. The caller must have marked the
+// synchronizer as closed.
+func (s *StandardSyncer) close() {
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	if s.footer != nil {
+		if err := s.writeFooter(); err != nil {
+			diagnose("writing footer failed: %v", err)
+		}
+	}
+	if err := s.sync(); err != nil {
+		diagnose("flushing on close failed: %v", err)
+	}
+}
+
+// writeFooter flushes the internal cache and then writes the footer to the
+// storage device, so that the footer follows all log entry data.
+func (s *StandardSyncer) writeFooter() error {
+	if s.writerMutex != nil {
+		s.writerMutex.Lock()
+		defer s.writerMutex.Unlock()
+	}
+	if s.cached {
+		if err := s.flushShards(); err != nil {
+			return err
+		}
+	}
+	_, err := s.writer.Write(s.footer(nil))
+	return err
+}
+
 // StandardSyncerOption is a structure containing standard synchronizer
 // options.
 type StandardSyncerOption struct {
@@ -266,6 +737,33 @@ func (o *StandardSyncerOption) UseCacheCapacity(capacity int) *StandardSyncerOpt
 	return o
 }
 
+// UseCacheShards uses the given number of shards as the value of the
+// option CacheShards. For details, please refer to the comment section of
+// the CacheShards option. Then return to the option instance itself.
+func (o *StandardSyncerOption) UseCacheShards(shards int) *StandardSyncerOption {
+	o.CacheShards = shards
+	return o
+}
+
+// UseFlushTriggers uses the given number of bytes and maximum age as the
+// values of the options FlushBytes and FlushAge. For details, please refer
+// to the comment section of these options. Then return to the option
+// instance itself.
+func (o *StandardSyncerOption) UseFlushTriggers(size int, age time.Duration) *StandardSyncerOption {
+	o.FlushBytes = size
+	o.FlushAge = age
+	return o
+}
+
+// UseBanners uses the given banners as the values of the options Header
+// and Footer. For details, please refer to the comment section of these
+// options. Then return to the option instance itself.
+func (o *StandardSyncerOption) UseBanners(header, footer Banner) *StandardSyncerOption {
+	o.Header = header
+	o.Footer = footer
+	return o
+}
+
 // UseWriter uses the given writer as the value of the option Writer.
 // If the value of the given writer is nil, ioutil.Discard is used.
 // For details, please refer to the comment section of the Writer option.
@@ -278,25 +776,81 @@ func (o *StandardSyncerOption) UseWriter(writer io.Writer) *StandardSyncerOption
 	return o
 }
 
+// Clone creates and returns a copy of the option instance. Please note
+// that the copy shares the instance of the option Writer with the option
+// instance.
+func (o *StandardSyncerOption) Clone() *StandardSyncerOption {
+	option := *o
+	return &option
+}
+
 // Build builds and returns a standard synchronizer instance.
 func (o *StandardSyncerOption) Build() (*StandardSyncer, error) {
-	var buffer []byte
-	var mutex *SpinLock
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	instance := &StandardSyncer {
+		writer: o.Writer,
+		capacity: o.CacheCapacity,
+		flushAge: o.FlushAge,
+		footer: o.Footer,
+	}
 	if !o.DisableMutex {
 		if o.CacheCapacity < 1024 && o.CacheCapacity > 0 {
 			o.CacheCapacity = 1024
+			instance.capacity = o.CacheCapacity
 		}
 		if o.CacheCapacity > 0 {
-			buffer = make([]byte, 0, o.CacheCapacity)
+			instance.buildShards(o.CacheShards, o.FlushBytes)
 		}
-		mutex = NewSpinLock()
+		instance.writerMutex = &sync.Mutex { }
+		instance.syncMutex = &sync.Mutex { }
+		instance.syncCond = sync.NewCond(instance.syncMutex)
 	}
-	return &StandardSyncer {
-		writer: o.Writer,
-		buffer: buffer,
-		capacity: o.CacheCapacity,
-		mutex: mutex,
-	}, nil
+	if instance.cached && o.FlushAge > 0 {
+		instance.timer = time.AfterFunc(o.FlushAge, instance.ageHandler)
+		instance.timer.Stop()
+	}
+	if o.Header != nil {
+		if _, err := instance.Write(o.Header(nil)); err != nil {
+			return nil, err
+		}
+	}
+	return instance, nil
+}
+
+// buildShards divides the internal cache into the given number of shards
+// and divides the capacity and the given number of buffered bytes that
+// trigger flushing among them. Each shard has at least 1,024 bytes of
+// capacity.
+func (s *StandardSyncer) buildShards(count int, flushBytes int) {
+	if count <= 0 {
+		count = 1
+	}
+	s.shardCapacity = s.capacity / count
+	if s.shardCapacity < 1024 {
+		s.shardCapacity = 1024
+	}
+	s.flushBytes = flushBytes / count
+	if flushBytes > 0 && s.flushBytes == 0 {
+		s.flushBytes = 1
+	}
+	s.shards = make([]syncerShard, count)
+	for index := 0; index < count; index++ {
+		s.shards[index] = syncerShard {
+			mutex: NewSpinLock(),
+			buffer: make([]byte, 0, s.shardCapacity),
+			spare: make([]byte, 0, s.shardCapacity),
+		}
+	}
+	var counter uint32
+	s.shardHints = &sync.Pool {
+		New: func() interface { } {
+			index := int(atomic.AddUint32(&counter, 1) % uint32(count))
+			return &index
+		},
+	}
+	s.cached = true
 }
 
 // NewStandardSyncerOption creates and returns a standard synchronizer
@@ -314,24 +868,132 @@ func NewStandardSyncer() (*StandardSyncer, error) {
 	return NewStandardSyncerOption().Build()
 }
 
+const (
+	// FileSyncNone represents that the file synchronizer relies on the
+	// Sync function and the operating system to write the data cached by
+	// the file system to the persistent storage device.
+	FileSyncNone FileSyncMode = iota
+
+	// FileSyncData represents that the file is opened with the O_DSYNC
+	// flag, so that each write returns after the data (and the metadata
+	// required to read it) has been written to the persistent storage
+	// device. On platforms without the O_DSYNC flag, the O_SYNC flag is
+	// used instead.
+	FileSyncData
+
+	// FileSyncFull represents that the file is opened with the O_SYNC
+	// flag, so that each write returns after the data and all metadata
+	// have been written to the persistent storage device.
+	FileSyncFull
+)
+
+var (
+	// ErrInvalidFileSyncMode represents that the synchronization mode of
+	// the file synchronizer is invalid.
+	ErrInvalidFileSyncMode = errors.New("invalid file sync mode")
+)
+
+// FileSyncMode is a data type that represents how writes of the file
+// synchronizer are synchronized to the persistent storage device.
+//
+// Synchronized writes are significantly slower, and are intended for
+// durability-critical logs such as audit logs. In this case, the internal
+// cache should usually be disabled as well, otherwise log entry data
+// still stays in the internal cache until it is flushed.
+type FileSyncMode uint8
+
 // FileSyncer is the structure of the file synchronizer instance.
 //
 // The file synchronizer is based on the standard synchronizer and
 // uses a file on the local hard disk as a specific storage device.
 //
+// External log rotation tools (for example, logrotate without the
+// copytruncate option) rename the file and then notify the application
+// to reopen it. The Reopen function of the file synchronizer closes the
+// file and reopens the file path, and the synchronizer can optionally
+// reopen the file when the process receives the SIGHUP signal.
+//
 // Please note that if the mutex is disabled, the API provided by
 // the synchronizer is not thread-safe.
 type FileSyncer struct {
 	*StandardSyncer
+	name string
+	flag int
+	mode os.FileMode
+	directoryMode os.FileMode
+	signals chan os.Signal
+	signalDone chan struct { }
+}
+
+// open opens the file path of the file synchronizer for appending with
+// the flag and mode of the synchronizer, creating the missing parent
+// directories if required, and then returns the file handle and any errors encountered.
+func (s *FileSyncer) open() (*os.File, error) {
+	if s.directoryMode != 0 {
+		err := os.MkdirAll(filepath.Dir(s.name), s.directoryMode)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return os.OpenFile(s.name, s.flag, s.mode)
+}
+
+// Reopen flushes the internal cache once, closes the file and reopens the
+// file path, so that subsequent log entry data is written to the file that
+// currently has the path. If the file path cannot be opened, the current
+// file is kept.
+//
+// Finally, any errors encountered are returned.
+func (s *FileSyncer) Reopen() error {
+	if s.writerMutex != nil {
+		s.writerMutex.Lock()
+		defer s.writerMutex.Unlock()
+	}
+	if s.isClosed() {
+		return ErrClosed
+	}
+	if s.cached {
+		if err := s.flushShards(); err != nil {
+			return err
+		}
+	}
+	handle, err := s.open()
+	if err != nil {
+		return err
+	}
+	previous := s.writer.(*os.File)
+	s.writer = handle
+	return previous.Close()
+}
+
+// signalHandler reopens the file each time the process receives the
+// SIGHUP signal until the signal channel is closed. It is called in an
+// independent coroutine context.
+func (s *FileSyncer) signalHandler() {
+	for range s.signals {
+		if err := s.Reopen(); err != nil {
+			diagnose("reopening %s failed: %v", s.name, err)
+		}
+	}
+	close(s.signalDone)
 }
 
 // Close automatically flushes the internal cache once, and then releases
 // any kernel objects that have been opened (including but not limited to:
 // file handles, etc.).
 //
-// Finally, any errors encountered are returned.
+// Finally, any errors encountered are returned. If the synchronizer has
+// already been closed, ErrClosed is returned.
 func (s *FileSyncer) Close() error {
-	_ = s.StandardSyncer.Close()
+	if !s.markClosed() {
+		return ErrClosed
+	}
+	if s.signals != nil {
+		signal.Stop(s.signals)
+		close(s.signals)
+		<-s.signalDone
+	}
+	s.close()
 	return s.writer.(*os.File).Close()
 }
 
@@ -343,6 +1005,45 @@ type FileSyncerOption struct {
 	// as a specific storage device. If not provided, the default value is
 	// os.DevNull.
 	FileName string
+
+	// FileMode represents the permission bits used when the file does not
+	// exist and is created. The permission bits are further restricted by
+	// the umask of the process. If not provided, the default value is
+	// 0644.
+	FileMode os.FileMode
+
+	// MakeDirectories represents whether to create the missing parent
+	// directories of the file (including when the file is reopened). If
+	// not provided, the default value is false.
+	MakeDirectories bool
+
+	// DirMode represents the permission bits used when parent directories
+	// are created. It only takes effect if the MakeDirectories option is
+	// enabled. If not provided, the default value is 0755.
+	DirMode os.FileMode
+
+	// SyncMode represents how writes to the file are synchronized to the
+	// persistent storage device. The optional values are defined by the
+	// constants at the beginning of FileSync... If not provided, the
+	// default value is the FileSyncNone constant.
+	SyncMode FileSyncMode
+
+	// ReopenOnHangup represents whether to reopen the file each time the
+	// process receives the SIGHUP signal, which is the common way for
+	// external log rotation tools to notify the application. The signal
+	// is no longer handled after the synchronizer is closed. On platforms
+	// without the SIGHUP signal (such as WebAssembly), the option has no
+	// effect. If not provided, the default value is false.
+	ReopenOnHangup bool
+
+	// VectoredWrites represents whether to write a log entry that does not
+	// fit the internal cache together with the cached data using a single
+	// vectored write (the writev system call, if supported), instead of
+	// flushing the cached data and then writing the log entry with two
+	// writes. This avoids copying large log entries and reduces the number
+	// of system calls. It only takes effect if the internal cache is
+	// enabled. If not provided, the default value is false.
+	VectoredWrites bool
 }
 
 // UseCacheCapacity uses the given capacity as the value of the option
@@ -353,6 +1054,33 @@ func (o *FileSyncerOption) UseCacheCapacity(capacity int) *FileSyncerOption {
 	return o
 }
 
+// UseCacheShards uses the given number of shards as the value of the
+// option CacheShards. For details, please refer to the comment section of
+// the CacheShards option. Then return to the option instance itself.
+func (o *FileSyncerOption) UseCacheShards(shards int) *FileSyncerOption {
+	o.CacheShards = shards
+	return o
+}
+
+// UseFlushTriggers uses the given number of bytes and maximum age as the
+// values of the options FlushBytes and FlushAge. For details, please refer
+// to the comment section of these options. Then return to the option
+// instance itself.
+func (o *FileSyncerOption) UseFlushTriggers(size int, age time.Duration) *FileSyncerOption {
+	o.FlushBytes = size
+	o.FlushAge = age
+	return o
+}
+
+// UseBanners uses the given banners as the values of the options Header
+// and Footer. For details, please refer to the comment section of these
+// options. Then return to the option instance itself.
+func (o *FileSyncerOption) UseBanners(header, footer Banner) *FileSyncerOption {
+	o.Header = header
+	o.Footer = footer
+	return o
+}
+
 // UseName uses the given name as the value of the option FileName. For
 // details, please refer to the comment section of the FileName option.
 func (o *FileSyncerOption) UseName(name string) *FileSyncerOption {
@@ -360,27 +1088,118 @@ func (o *FileSyncerOption) UseName(name string) *FileSyncerOption {
 	return o
 }
 
+// UseFileMode uses the given permission bits as the value of the option
+// FileMode. For details, please refer to the comment section of the
+// FileMode option. Then return to the option instance itself.
+func (o *FileSyncerOption) UseFileMode(mode os.FileMode) *FileSyncerOption {
+	o.FileMode = mode
+	return o
+}
+
+// UseMakeDirectories enables the option MakeDirectories and uses the given
+// permission bits as the value of the option DirMode. For details, please
+// refer to the comment section of these options. Then return to the option
+// instance itself.
+func (o *FileSyncerOption) UseMakeDirectories(mode os.FileMode) *FileSyncerOption {
+	o.MakeDirectories = true
+	o.DirMode = mode
+	return o
+}
+
+// UseSyncMode uses the given synchronization mode as the value of the
+// option SyncMode. For details, please refer to the comment section of the
+// SyncMode option. Then return to the option instance itself.
+func (o *FileSyncerOption) UseSyncMode(mode FileSyncMode) *FileSyncerOption {
+	o.SyncMode = mode
+	return o
+}
+
+// UseReopenOnHangup enables the option ReopenOnHangup. For details, please
+// refer to the comment section of the ReopenOnHangup option. Then return
+// to the option instance itself.
+func (o *FileSyncerOption) UseReopenOnHangup() *FileSyncerOption {
+	o.ReopenOnHangup = true
+	return o
+}
+
+// UseVectoredWrites enables the option VectoredWrites. For details, please
+// refer to the comment section of the VectoredWrites option. Then return to
+// the option instance itself.
+func (o *FileSyncerOption) UseVectoredWrites() *FileSyncerOption {
+	o.VectoredWrites = true
+	return o
+}
+
+// Validate checks whether the values of the options are valid, and returns
+// an error describing the first invalid value.
+func (o *FileSyncerOption) Validate() error {
+	if err := o.SyncerOption.Validate(); err != nil {
+		return err
+	}
+	if o.SyncMode > FileSyncFull {
+		return ErrInvalidFileSyncMode
+	}
+	return nil
+}
+
+// Clone creates and returns a copy of the option instance. Modifying the
+// copy does not affect the option instance, and vice versa.
+func (o *FileSyncerOption) Clone() *FileSyncerOption {
+	option := *o
+	return &option
+}
+
 // Build builds and returns a file synchronizer instance.
 func (o *FileSyncerOption) Build() (*FileSyncer, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
 	if len(o.FileName) == 0 {
 		o.FileName = os.DevNull
 	}
-	handle, err := os.OpenFile(o.FileName, os.O_RDWR |
-		os.O_CREATE | os.O_APPEND, os.ModeAppend)
+	instance := &FileSyncer {
+		name: o.FileName,
+		flag: os.O_RDWR | os.O_CREATE | os.O_APPEND,
+		mode: o.FileMode,
+	}
+	switch o.SyncMode {
+	case FileSyncNone:
+	case FileSyncData:
+		instance.flag |= openDataSync
+	case FileSyncFull:
+		instance.flag |= os.O_SYNC
+	default:
+		return nil, ErrInvalidFileSyncMode
+	}
+	if instance.mode == 0 {
+		instance.mode = 0644
+	}
+	if o.MakeDirectories {
+		instance.directoryMode = o.DirMode
+		if instance.directoryMode == 0 {
+			instance.directoryMode = 0755
+		}
+	}
+	handle, err := instance.open()
 	if err != nil {
 		return nil, err
 	}
 	option := NewStandardSyncerOption()
 	option.SyncerOption = o.SyncerOption
 	option.Writer = handle
-	syncer, err := option.Build()
+	instance.StandardSyncer, err = option.Build()
 	if err != nil {
 		_ = handle.Close()
 		return nil, err
 	}
-	return &FileSyncer {
-		StandardSyncer: syncer,
-	}, nil
+	instance.vectored = o.VectoredWrites && instance.cached
+	if o.ReopenOnHangup && len(hangupSignals) > 0 {
+		instance.signals = make(chan os.Signal, 1)
+		instance.signalDone = make(chan struct { })
+		signal.Notify(instance.signals, hangupSignals...)
+		go instance.signalHandler()
+	}
+	return instance, nil
 }
 
 // NewFileSyncerOption creates and returns an instance of a file
@@ -389,6 +1208,8 @@ func NewFileSyncerOption() *FileSyncerOption {
 	return &FileSyncerOption {
 		SyncerOption: NewSyncerOption(),
 		FileName: os.DevNull,
+		FileMode: 0644,
+		DirMode: 0755,
 	}
 }
 
@@ -404,6 +1225,12 @@ func NewFileSyncer() (*FileSyncer, error) {
 // The network synchronizer is based on the standard synchronizer
 // and uses TCP/IP or Unix streams as a specific storage device.
 //
+// The network synchronizer can maintain multiple parallel connections to
+// the other end of the network, and flushes are distributed across the
+// connected connections in turn. This increases the throughput to peers
+// that limit the rate of each connection. Each connection is reconnected
+// independently, and its health can be obtained by the Health function.
+//
 // Please note that if the mutex is disabled, the API provided by
 // the synchronizer is not thread-safe.
 type NetworkSyncer struct {
@@ -411,31 +1238,191 @@ type NetworkSyncer struct {
 
 	protocol string
 	address string
+	balancer *networkBalancer
 
 	context context.Context
 	contextCancel context.CancelFunc
 	contextWaitGroup *sync.WaitGroup
+}
+
+// NetworkConnectionHealth is a structure that contains the health of a
+// connection of the network synchronizer.
+type NetworkConnectionHealth struct {
+	// Connected represents whether the connection is connected. If not,
+	// the connection is being re-established.
+	Connected bool
+
+	// Writes represents the number of writes (flushes) of the connection.
+	Writes uint64
+
+	// Failures represents the number of failed writes of the connection.
+	Failures uint64
+
+	// Reconnects represents the number of times that the connection has
+	// been re-established.
+	Reconnects uint64
+}
+
+// networkBalancer is the structure of the writer of the network
+// synchronizer. It distributes writes across the connected connections
+// in turn.
+type networkBalancer struct {
+	connections []*networkConnection
+	next uint32
+}
+
+// Write writes the data of a given buffer slice to the next connected
+// connection, and then returns the number of bytes written and any errors
+// encountered. If no connection is connected, the data is written to the
+// next connection, which usually fails immediately.
+func (b *networkBalancer) Write(buffer []byte) (int, error) {
+	count := uint32(len(b.connections))
+	if count == 1 {
+		return b.connections[0].Write(buffer)
+	}
+	start := atomic.AddUint32(&b.next, 1)
+	for index := uint32(0); index < count; index++ {
+		connection := b.connections[(start + index) % count]
+		if atomic.LoadInt32(&connection.disconnected) == 0 {
+			return connection.Write(buffer)
+		}
+	}
+	return b.connections[start % count].Write(buffer)
+}
 
+// close closes all connections, and then returns the first error
+// encountered.
+func (b *networkBalancer) close() error {
+	var err error
+	for _, connection := range b.connections {
+		if closeErr := connection.close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// networkConnection is the structure of a connection of the network
+// synchronizer. It sets the write deadline of each flush, and starts the
+// reconnection when a write fails because of disconnection.
+type networkConnection struct {
+	writes uint64
+	failures uint64
+	reconnects uint64
 	disconnected int32
+
+	mutex sync.Mutex
+	conn net.Conn
+	timeout time.Duration
+	syncer *NetworkSyncer
+}
+
+// Write writes the data of a given buffer slice to the connection before
+// the write deadline, and then returns the number of bytes written and any
+// errors encountered.
+func (c *networkConnection) Write(buffer []byte) (int, error) {
+	atomic.AddUint64(&c.writes, 1)
+	c.mutex.Lock()
+	conn := c.conn
+	if c.timeout > 0 {
+		_ = conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	}
+	size, err := conn.Write(buffer)
+	c.mutex.Unlock()
+	if err != nil {
+		atomic.AddUint64(&c.failures, 1)
+		if isDisconnectError(err) {
+			c.disconnect()
+		}
+	}
+	return size, err
 }
 
-func (s *NetworkSyncer) reconnect() {
+// replace replaces the connection with the given connection, and then
+// closes the replaced connection.
+func (c *networkConnection) replace(conn net.Conn) {
+	c.mutex.Lock()
+	replaced := c.conn
+	c.conn = conn
+	c.mutex.Unlock()
+	_ = replaced.Close()
+}
+
+// close closes the connection, and then returns any errors encountered.
+func (c *networkConnection) close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.conn.Close()
+}
+
+// health returns the health of the connection.
+func (c *networkConnection) health() NetworkConnectionHealth {
+	return NetworkConnectionHealth {
+		Connected: atomic.LoadInt32(&c.disconnected) == 0,
+		Writes: atomic.LoadUint64(&c.writes),
+		Failures: atomic.LoadUint64(&c.failures),
+		Reconnects: atomic.LoadUint64(&c.reconnects),
+	}
+}
+
+// isDisconnectError checks whether the given error returned by a write of
+// a connection means that the connection is no longer usable, including
+// but not limited to: the connection has been closed, reset or aborted,
+// the peer has closed its end, and the write deadline has been exceeded.
+func isDisconnectError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		// The remaining data of a timed-out write is unknown, so the
+		// connection cannot be used anymore.
+		return true
+	}
+	return errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, io.ErrClosedPipe) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ENOTCONN) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// disconnect closes the connection and starts the reconnection coroutine,
+// unless the reconnection is already in progress.
+func (c *networkConnection) disconnect() {
+	if !atomic.CompareAndSwapInt32(&c.disconnected, 0, 1) {
+		return
+	}
+	// Closing the connection makes the writes during the reconnection
+	// fail immediately instead of waiting for the write deadline.
+	_ = c.close()
+	c.syncer.contextWaitGroup.Add(1)
+	go c.reconnect()
+}
+
+func (c *networkConnection) reconnect() {
+	s := c.syncer
 	defer s.contextWaitGroup.Done()
 	dialer := &net.Dialer {
 		Timeout: time.Second * 5,
 	}
-	for {
+	diagnose("network connection to %s://%s lost, reconnecting",
+		s.protocol, s.address)
+	for attempt := 1; ; attempt++ {
 		connect, err := dialer.DialContext(s.context, s.protocol, s.address)
 		if err != nil {
 			// If the synchronizer is closing, give up the reconnection
 			// and return. To avoid calling the function again, the value
-			// of `s.disconnected` is not reset.
+			// of `c.disconnected` is not reset.
 			if s.context.Err() != nil {
 				return
 			}
 
 			// Reconnection failed, try again after an interval of 1
-			// second.
+			// second. Only the first failure is reported, to avoid
+			// flooding the diagnostics channel.
+			if attempt == 1 {
+				diagnose("reconnecting to %s://%s failed, retrying: %v",
+					s.protocol, s.address, err)
+			}
 			select {
 			case <-time.After(1 * time.Second):
 				continue
@@ -443,11 +1430,26 @@ func (s *NetworkSyncer) reconnect() {
 				return
 			}
 		}
-		_ = s.writer.(net.Conn).Close()
-		s.writer = connect
+		c.replace(connect)
+		atomic.AddUint64(&c.reconnects, 1)
+		diagnose("reconnected to %s://%s after %d attempts", s.protocol,
+			s.address, attempt)
 		break
 	}
-	atomic.CompareAndSwapInt32(&s.disconnected, 1, 0)
+	atomic.CompareAndSwapInt32(&c.disconnected, 1, 0)
+}
+
+// Health returns the health of each connection of the network
+// synchronizer. For details, please refer to the comment section of the
+// NetworkConnectionHealth structure.
+//
+// This function is thread-safe.
+func (s *NetworkSyncer) Health() []NetworkConnectionHealth {
+	health := make([]NetworkConnectionHealth, len(s.balancer.connections))
+	for index, connection := range s.balancer.connections {
+		health[index] = connection.health()
+	}
+	return health
 }
 
 // Write writes the data of a given buffer slice to a specific storage
@@ -455,34 +1457,30 @@ func (s *NetworkSyncer) reconnect() {
 // written first. If the capacity of the internal cache is saturated,
 // it is automatically flushed once.
 //
+// If a write fails because the connection to the other end of the
+// network has been interrupted or the write deadline has been exceeded,
+// the connection is re-established in an independent coroutine.
+//
 // Finally, it returns the number of bytes actually written and any
 // errors encountered.
 func (s *NetworkSyncer) Write(buffer []byte) (int, error) {
-	size, err := s.StandardSyncer.Write(buffer)
-	if err != nil {
-		if strings.Contains(err.Error(), "use of closed network connection") {
-			// The connection to the other end of the network may have
-			// been interrupted unexpectedly, try to re-establish the
-			// connection.
-			if atomic.CompareAndSwapInt32(&s.disconnected, 0, 1) {
-				s.contextWaitGroup.Add(1)
-				go s.reconnect()
-			}
-		}
-	}
-	return size, err
+	return s.StandardSyncer.Write(buffer)
 }
 
 // Close automatically flushes the internal cache once, and then releases
 // any kernel objects that have been opened (including but not limited to:
 // network handles, etc.).
 //
-// Finally, any errors encountered are returned.
+// Finally, any errors encountered are returned. If the synchronizer has
+// already been closed, ErrClosed is returned.
 func (s *NetworkSyncer) Close() error {
+	if !s.markClosed() {
+		return ErrClosed
+	}
 	s.contextCancel()
 	s.contextWaitGroup.Wait()
-	_ = s.StandardSyncer.Close()
-	return s.StandardSyncer.writer.(net.Conn).Close()
+	s.close()
+	return s.balancer.close()
 }
 
 const (
@@ -495,6 +1493,15 @@ const (
 	// network synchronizer is Unix Domain Socket. For details, please
 	// refer to the comment section of the NetworkSyncer structure.
 	ProtocolUnix = "unix"
+
+	// ProtocolUnixgram represents that the communication protocol of the
+	// network synchronizer is Unix Domain Socket in datagram mode, which
+	// is used by journald-style listeners. Each log entry is sent as a
+	// separate datagram, so the internal cache of the synchronizer is
+	// always disabled. Datagrams larger than the limit of the system are
+	// rejected, so consider limiting the size of log entries with the
+	// MaxEntryBytes option of the exporter.
+	ProtocolUnixgram = "unixgram"
 )
 
 var (
@@ -502,6 +1509,11 @@ var (
 	// or unsupported. This is usually because the value of the given
 	// network protocol type is invalid.
 	ErrInvalidProtocol = errors.New("invalid network protocol")
+
+	// ErrInvalidConnections represents that the given number of
+	// connections of the network synchronizer is invalid. The number
+	// must not be less than 0.
+	ErrInvalidConnections = errors.New("invalid number of connections")
 )
 
 // NetworkSyncerOption is a structure containing network synchronizer
@@ -523,9 +1535,26 @@ type NetworkSyncerOption struct {
 	// protocol to establish a connection. The address format depends on
 	// the value of the Protocol option.
 	//
+	// For Unix Domain Socket protocols on Linux, addresses starting with
+	// @ (for example, @santa) are in the abstract namespace, which does
+	// not require a socket file in the file system.
+	//
 	// If not provided, the default value is /var/run/santa.sock. It is
 	// worth noting that the default value is invalid for Windows.
 	Address string
+
+	// WriteTimeout represents the maximum time that each write (flush) of
+	// the connection may take, so that a stalled peer cannot block the
+	// output APIs indefinitely. A timed-out write interrupts the
+	// connection and triggers the reconnection. If the value is 0, writes
+	// have no deadline. If not provided, the default value is 5 seconds.
+	WriteTimeout time.Duration
+	// Connections represents the number of parallel connections that the
+	// network synchronizer maintains. Flushes are distributed across the
+	// connected connections in turn. Please note that the order of data
+	// is only guaranteed within each connection. If not provided, the
+	// default value is 1.
+	Connections int
 }
 
 // UseCacheCapacity uses the given capacity as the value of the option
@@ -536,6 +1565,33 @@ func (o *NetworkSyncerOption) UseCacheCapacity(capacity int) *NetworkSyncerOptio
 	return o
 }
 
+// UseCacheShards uses the given number of shards as the value of the
+// option CacheShards. For details, please refer to the comment section of
+// the CacheShards option. Then return to the option instance itself.
+func (o *NetworkSyncerOption) UseCacheShards(shards int) *NetworkSyncerOption {
+	o.CacheShards = shards
+	return o
+}
+
+// UseFlushTriggers uses the given number of bytes and maximum age as the
+// values of the options FlushBytes and FlushAge. For details, please refer
+// to the comment section of these options. Then return to the option
+// instance itself.
+func (o *NetworkSyncerOption) UseFlushTriggers(size int, age time.Duration) *NetworkSyncerOption {
+	o.FlushBytes = size
+	o.FlushAge = age
+	return o
+}
+
+// UseBanners uses the given banners as the values of the options Header
+// and Footer. For details, please refer to the comment section of these
+// options. Then return to the option instance itself.
+func (o *NetworkSyncerOption) UseBanners(header, footer Banner) *NetworkSyncerOption {
+	o.Header = header
+	o.Footer = footer
+	return o
+}
+
 // UseProtocol uses the given protocol as the value of the option Protocol.
 // Please refer to the comment section of the Protocol option for details.
 // Then return to the option instance itself.
@@ -552,40 +1608,104 @@ func (o *NetworkSyncerOption) UseAddress(address string) *NetworkSyncerOption {
 	return o
 }
 
+// UseWriteTimeout uses the given timeout as the value of the option
+// WriteTimeout. For details, please refer to the comment section of the
+// WriteTimeout option. Then return to the option instance itself.
+func (o *NetworkSyncerOption) UseWriteTimeout(timeout time.Duration) *NetworkSyncerOption {
+	o.WriteTimeout = timeout
+	return o
+}
+
+// UseConnections uses the given number of connections as the value of the
+// option Connections. For details, please refer to the comment section of
+// the Connections option. Then return to the option instance itself.
+func (o *NetworkSyncerOption) UseConnections(connections int) *NetworkSyncerOption {
+	o.Connections = connections
+	return o
+}
+
+// Validate checks whether the values of the options are valid, and returns
+// an error describing the first invalid value.
+func (o *NetworkSyncerOption) Validate() error {
+	if err := o.SyncerOption.Validate(); err != nil {
+		return err
+	}
+	switch o.Protocol {
+	case ProtocolTCP:
+	case ProtocolUnix, ProtocolUnixgram:
+		if !unixSocketsSupported {
+			return fmt.Errorf("%w: %s is not supported on %s",
+				ErrInvalidProtocol, o.Protocol, runtime.GOOS)
+		}
+	default:
+		return ErrInvalidProtocol
+	}
+	if o.Connections < 0 {
+		return ErrInvalidConnections
+	}
+	return nil
+}
+
+// Clone creates and returns a copy of the option instance. Modifying the
+// copy does not affect the option instance, and vice versa.
+func (o *NetworkSyncerOption) Clone() *NetworkSyncerOption {
+	option := *o
+	return &option
+}
+
 // Build builds and returns an instance of the network synchronizer and
 // any errors encountered.
 func (o *NetworkSyncerOption) Build() (*NetworkSyncer, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	syncerOption := o.SyncerOption
 	switch o.Protocol {
 	case ProtocolTCP:
 	case ProtocolUnix:
-	default:
-		return nil, ErrInvalidProtocol
+	case ProtocolUnixgram:
+		// Cached log entries would be merged into one datagram.
+		syncerOption.CacheCapacity = 0
 	}
 
-	connect, err := net.Dial(o.Protocol, o.Address)
-	if err != nil {
-		return nil, err
+	balancer := &networkBalancer { }
+	for index := 0; index < o.Connections || index == 0; index++ {
+		connect, err := net.Dial(o.Protocol, o.Address)
+		if err != nil {
+			_ = balancer.close()
+			return nil, err
+		}
+		balancer.connections = append(balancer.connections,
+			&networkConnection {
+				conn: connect,
+				timeout: o.WriteTimeout,
+			})
 	}
 	option := NewStandardSyncerOption()
-	option.SyncerOption = o.SyncerOption
-	option.Writer = connect
+	option.SyncerOption = syncerOption
+	option.Writer = balancer
 	syncer, err := option.Build()
 	if err != nil {
-		_ = connect.Close()
+		_ = balancer.close()
 		return nil, err
 	}
 	context, contextCancel := context.WithCancel(
 		context.Background())
-	return &NetworkSyncer {
+	instance := &NetworkSyncer {
 		StandardSyncer: syncer,
 
 		protocol: o.Protocol,
 		address: o.Address,
+		balancer: balancer,
 
 		context: context,
 		contextCancel: contextCancel,
 		contextWaitGroup: &sync.WaitGroup { },
-	}, nil
+	}
+	for _, connection := range balancer.connections {
+		connection.syncer = instance
+	}
+	return instance, nil
 }
 
 // NewNetworkSyncerOption creates and returns a network synchronizer
@@ -595,6 +1715,8 @@ func NewNetworkSyncerOption() *NetworkSyncerOption {
 		SyncerOption: NewSyncerOption(),
 		Protocol: ProtocolUnix,
 		Address: "/var/run/santa.sock",
+		WriteTimeout: time.Second * 5,
+		Connections: 1,
 	}
 }
 
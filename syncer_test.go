@@ -23,9 +23,17 @@
 package santa
 
 import (
+	"errors"
+	"io"
+	"io/ioutil"
 	"net"
 	"os"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
@@ -74,12 +82,52 @@ func TestFileSyncerOption(t *testing.T) {
 	assert.NoError(t, syncer.Close(), "Unexpected close error")
 }
 
+func TestFileSyncerPermissions(t *testing.T) {
+	directory, err := ioutil.TempDir("", "santa")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	name := directory + "/audit/santa.log"
+	_, err = NewFileSyncerOption().UseName(name).Build()
+	assert.Error(t, err, "Unexpected build result")
+
+	_, err = NewFileSyncerOption().UseName(name).
+		UseSyncMode(FileSyncFull + 1).Build()
+	assert.Equal(t, ErrInvalidFileSyncMode, err, "Unexpected build error")
+
+	option := NewFileSyncerOption().UseName(name).UseCacheCapacity(0).
+		UseFileMode(0600).UseMakeDirectories(0700).UseSyncMode(FileSyncData)
+	assert.Equal(t, os.FileMode(0600), option.FileMode,
+		"Unexpected option value")
+	assert.True(t, option.MakeDirectories, "Unexpected option value")
+
+	syncer, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.NotZero(t, syncer.flag & openDataSync, "Unexpected instance error")
+
+	_, err = syncer.Write([]byte("Hello Test!"))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(name)
+		assert.NoError(t, err, "Unexpected stat error")
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm(),
+			"Unexpected file mode")
+		info, err = os.Stat(directory + "/audit")
+		assert.NoError(t, err, "Unexpected stat error")
+		assert.Equal(t, os.FileMode(0700), info.Mode().Perm(),
+			"Unexpected directory mode")
+	}
+}
+
 func TestNetworkSyncerOption(t *testing.T) {
 	closed := make(chan byte, 1)
 
+	listener, err := net.Listen("tcp", "127.0.0.1:10001")
+	assert.NoError(t, err, "Unexpected listen 127.0.0.1:10001 error")
+
 	go func() {
-		listener, err := net.Listen("tcp", "127.0.0.1:10001")
-		assert.NoError(t, err, "Unexpected listen 127.0.0.1:10001 error")
 		defer listener.Close()
 		for {
 			connect, err := listener.Accept()
@@ -133,6 +181,100 @@ func TestStandardSyncerWrite(t *testing.T) {
 	assert.NoError(t, syncer.Close(), "Unexpected close error")
 }
 
+// testBlockWriter is a writer whose first write blocks until the release
+// channel is closed.
+type testBlockWriter struct {
+	writes int32
+	started chan struct { }
+	release chan struct { }
+}
+
+func (w *testBlockWriter) Write(buffer []byte) (int, error) {
+	if atomic.AddInt32(&w.writes, 1) == 1 {
+		close(w.started)
+		<-w.release
+	}
+	return len(buffer), nil
+}
+
+func TestStandardSyncerSyncCoalescing(t *testing.T) {
+	writer := &testBlockWriter {
+		started: make(chan struct { }),
+		release: make(chan struct { }),
+	}
+	syncer, err := NewStandardSyncerOption().UseWriter(writer).Build()
+	assert.NoError(t, err, "Unexpected create error")
+	assert.True(t, syncer.Stats().LastSync.IsZero(), "Unexpected last sync")
+
+	_, err = syncer.Write([]byte("Hello Test!"))
+	assert.NoError(t, err, "Unexpected write error")
+
+	group := sync.WaitGroup { }
+	group.Add(1)
+	go func() {
+		defer group.Done()
+		assert.NoError(t, syncer.Sync(), "Unexpected sync error")
+	}()
+	<-writer.started
+
+	for index := 0; index < 4; index++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			assert.NoError(t, syncer.Sync(), "Unexpected sync error")
+		}()
+	}
+	for syncer.Stats().CoalescedSyncs < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	close(writer.release)
+	group.Wait()
+
+	stats := syncer.Stats()
+	assert.Equal(t, uint64(2), stats.Syncs, "Unexpected sync count")
+	assert.Equal(t, uint64(3), stats.CoalescedSyncs,
+		"Unexpected coalesced sync count")
+	assert.False(t, stats.LastSync.IsZero(), "Unexpected last sync")
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+}
+
+// testUnsyncableFile is a file whose Sync function always fails with
+// EINVAL.
+type testUnsyncableFile struct {
+	*os.File
+}
+
+func (f testUnsyncableFile) Sync() error {
+	return &os.PathError {
+		Op: "sync",
+		Path: f.Name(),
+		Err: syscall.EINVAL,
+	}
+}
+
+func TestStandardSyncerUnsyncable(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	assert.NoError(t, err, "Unexpected pipe error")
+	defer reader.Close()
+	defer writer.Close()
+
+	syncer, err := NewStandardSyncerOption().UseWriter(
+		testUnsyncableFile { writer }).Build()
+	assert.NoError(t, err, "Unexpected create error")
+	assert.NoError(t, syncer.Sync(), "Unexpected sync error")
+
+	file, err := ioutil.TempFile("", "santa-unsyncable-*.log")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	syncer, err = NewStandardSyncerOption().UseWriter(
+		testUnsyncableFile { file }).Build()
+	assert.NoError(t, err, "Unexpected create error")
+	assert.True(t, errors.Is(syncer.Sync(), syscall.EINVAL),
+		"Unexpected sync error")
+}
+
 func TestFileSyncerWrite(t *testing.T) {
 	syncer, err := NewFileSyncer()
 	assert.NoError(t, err, "Unexpected create error")
@@ -140,14 +282,95 @@ func TestFileSyncerWrite(t *testing.T) {
 	_, err = syncer.Write([]byte("Hello Test!"))
 	assert.NoError(t, err, "Unexpected write error")
 	assert.NoError(t, syncer.Close(), "Unexpected close error")
+
+	_, err = syncer.Write([]byte("Hello Test!"))
+	assert.Equal(t, ErrClosed, err, "Unexpected write error")
+	assert.Equal(t, ErrClosed, syncer.Sync(), "Unexpected sync error")
+	assert.Equal(t, ErrClosed, syncer.Reopen(), "Unexpected reopen error")
+	assert.Equal(t, ErrClosed, syncer.Close(), "Unexpected close error")
+}
+
+func TestFileSyncerVectoredWrites(t *testing.T) {
+	directory, err := ioutil.TempDir("", "santa")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	name := directory + "/santa.log"
+	syncer, err := NewFileSyncerOption().UseName(name).
+		UseCacheCapacity(1024).UseVectoredWrites().Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	expected := []byte { }
+	for index := 0; index < 100; index++ {
+		data := []byte(strings.Repeat(strconv.Itoa(index % 10),
+			index * 7) + "\n")
+		size, err := syncer.Write(data)
+		assert.NoError(t, err, "Unexpected write error")
+		assert.Equal(t, len(data), size, "Unexpected write size")
+		expected = append(expected, data...)
+	}
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+
+	data, err := ioutil.ReadFile(name)
+	assert.NoError(t, err, "Unexpected read error")
+	assert.Equal(t, string(expected), string(data), "Unexpected file content")
+}
+
+func TestFileSyncerReopen(t *testing.T) {
+	directory, err := ioutil.TempDir("", "santa")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	name := directory + "/santa.log"
+	syncer, err := NewFileSyncerOption().UseName(name).
+		UseReopenOnHangup().Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	_, err = syncer.Write([]byte("Hello Test!"))
+	assert.NoError(t, err, "Unexpected write error")
+
+	// The cached data is flushed to the rotated file.
+	assert.NoError(t, os.Rename(name, name + ".1"), "Unexpected rename error")
+	assert.NoError(t, syncer.Reopen(), "Unexpected reopen error")
+	_, err = syncer.Write([]byte("Bye!"))
+	assert.NoError(t, err, "Unexpected write error")
+
+	hangup := runtime.GOOS != "windows" && len(hangupSignals) > 0
+	if hangup {
+		assert.NoError(t, os.Rename(name, name + ".2"),
+			"Unexpected rename error")
+		process, err := os.FindProcess(os.Getpid())
+		assert.NoError(t, err, "Unexpected find error")
+		assert.NoError(t, process.Signal(hangupSignals[0]),
+			"Unexpected signal error")
+		for index := 0; index < 100; index++ {
+			if _, err = os.Stat(name); err == nil {
+				break
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+		assert.NoError(t, err, "Unexpected reopen result")
+	}
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+
+	data, err := ioutil.ReadFile(name + ".1")
+	assert.NoError(t, err, "Unexpected read error")
+	assert.Equal(t, "Hello Test!", string(data), "Unexpected file content")
+
+	if hangup {
+		data, err = ioutil.ReadFile(name + ".2")
+		assert.NoError(t, err, "Unexpected read error")
+		assert.Equal(t, "Bye!", string(data), "Unexpected file content")
+	}
 }
 
 func TestNetworkSyncerWrite(t *testing.T) {
 	closed := make(chan byte, 1)
 
+	listener, err := net.Listen("tcp", "127.0.0.1:10001")
+	assert.NoError(t, err, "Unexpected listen 127.0.0.1:10001 error")
+
 	go func() {
-		listener, err := net.Listen("tcp", "127.0.0.1:10001")
-		assert.NoError(t, err, "Unexpected listen 127.0.0.1:10001 error")
 		defer listener.Close()
 		for count := 0; count < 2; count++ {
 			connect, err := listener.Accept()
@@ -177,7 +400,7 @@ func TestNetworkSyncerWrite(t *testing.T) {
 
 	_, err = syncer.Write([]byte("Hello Test!"))
 	assert.NoError(t, err, "Unexpected write error")
-	syncer.writer.(net.Conn).Close()
+	syncer.balancer.connections[0].conn.Close()
 
 	// Test whether the automatic reconnection function works correctly
 	// when the connection is accidentally disconnected.
@@ -185,7 +408,7 @@ func TestNetworkSyncerWrite(t *testing.T) {
 		_, err = syncer.Write([]byte("Hello Test!"))
 
 		if err != nil {
-			if strings.Contains(err.Error(), "use of closed network connection") {
+			if errors.Is(err, net.ErrClosed) {
 				time.Sleep(100 * time.Microsecond)
 				continue
 			}
@@ -198,3 +421,491 @@ func TestNetworkSyncerWrite(t *testing.T) {
 	<-closed
 	syncer.Close()
 }
+
+type testLockedWriter struct {
+	mutex sync.Mutex
+	data []byte
+}
+
+func (w *testLockedWriter) Write(buffer []byte) (int, error) {
+	w.mutex.Lock()
+	w.data = append(w.data, buffer...)
+	w.mutex.Unlock()
+	return len(buffer), nil
+}
+
+func (w *testLockedWriter) Len() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return len(w.data)
+}
+
+func TestStandardSyncerFlushTriggers(t *testing.T) {
+	writer := &testLockedWriter { }
+
+	option := NewStandardSyncerOption()
+	option.UseWriter(writer)
+	option.UseCacheCapacity(4096)
+	option.UseFlushTriggers(16, 0)
+
+	assert.Equal(t, 16, option.FlushBytes, "Unexpected option value")
+
+	syncer, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	_, err = syncer.Write([]byte("Hello Test!"))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.Equal(t, 0, writer.Len(), "Unexpected flushed data")
+
+	_, err = syncer.Write([]byte("Hello Test!"))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.Equal(t, 22, writer.Len(), "Unexpected flushed data")
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+
+	writer = &testLockedWriter { }
+	option.UseWriter(writer)
+	option.UseFlushTriggers(0, time.Millisecond * 10)
+
+	syncer, err = option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	_, err = syncer.Write([]byte("Hello Test!"))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.Equal(t, 0, writer.Len(), "Unexpected flushed data")
+
+	assert.Eventually(t, func() bool {
+		return writer.Len() == 11
+	}, time.Second, time.Millisecond, "Unexpected flushed data")
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+}
+
+type testPartialWriter struct {
+	testLockedWriter
+	failures int
+}
+
+func (w *testPartialWriter) Write(buffer []byte) (int, error) {
+	if w.failures > 0 {
+		w.failures--
+		size, _ := w.testLockedWriter.Write(buffer[ : 4])
+		return size, errors.New("Error")
+	}
+	return w.testLockedWriter.Write(buffer)
+}
+
+func TestStandardSyncerSwapBuffers(t *testing.T) {
+	writer := &testPartialWriter { failures: 1 }
+
+	option := NewStandardSyncerOption()
+	option.UseWriter(writer)
+	option.UseCacheCapacity(1024)
+
+	syncer, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	_, err = syncer.Write([]byte("Hello Test!"))
+	assert.NoError(t, err, "Unexpected write error")
+
+	// The sealed buffer is partially written, and the remaining data is
+	// kept in the internal cache before the data written later.
+	assert.Error(t, syncer.Sync(), "Unexpected sync error")
+	_, err = syncer.Write([]byte(" Bye!"))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.NoError(t, syncer.Sync(), "Unexpected sync error")
+	assert.Equal(t, "Hello Test! Bye!", string(writer.data),
+		"Unexpected written data")
+
+	// Data that does not fit the internal cache is written directly after
+	// the cached data.
+	_, err = syncer.Write([]byte("Hello"))
+	assert.NoError(t, err, "Unexpected write error")
+	_, err = syncer.Write([]byte(strings.Repeat("a", 2048)))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.Equal(t, "Hello Test! Bye!Hello" + strings.Repeat("a", 2048),
+		string(writer.data), "Unexpected written data")
+
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+}
+
+func TestStandardSyncerConcurrentWrite(t *testing.T) {
+	for _, shards := range []int { 1, 4 } {
+		testStandardSyncerConcurrentWrite(t, shards)
+	}
+}
+
+func testStandardSyncerConcurrentWrite(t *testing.T, shards int) {
+	writer := &testLockedWriter { }
+
+	option := NewStandardSyncerOption()
+	option.UseWriter(writer)
+	option.UseCacheCapacity(1024 * shards)
+	option.UseCacheShards(shards)
+
+	syncer, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.Len(t, syncer.shards, shards, "Unexpected instance error")
+	assert.Equal(t, 1024, syncer.shardCapacity, "Unexpected instance error")
+
+	group := sync.WaitGroup { }
+	for index := 0; index < 8; index++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			for count := 0; count < 1000; count++ {
+				_, _ = syncer.Write([]byte("Hello Test!\n"))
+			}
+		}()
+	}
+	group.Wait()
+
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+	assert.Equal(t, 8 * 1000 * 12, writer.Len(), "Unexpected written data")
+	assert.Equal(t, 8 * 1000, strings.Count(string(writer.data),
+		"Hello Test!\n"), "Unexpected written data")
+}
+
+func BenchmarkStandardSyncerWrite(b *testing.B) {
+	benchmarkStandardSyncerWrite(b, 1)
+}
+
+func BenchmarkStandardSyncerWriteSharded(b *testing.B) {
+	benchmarkStandardSyncerWrite(b, runtime.NumCPU())
+}
+
+func benchmarkStandardSyncerWrite(b *testing.B, shards int) {
+	option := NewStandardSyncerOption()
+	option.UseWriter(ioutil.Discard)
+	option.UseCacheShards(shards)
+
+	syncer, _ := option.Build()
+	buffer := []byte("Hello Test!\n")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = syncer.Write(buffer)
+		}
+	})
+	_ = syncer.Close()
+}
+
+func TestNetworkSyncerWriteTimeout(t *testing.T) {
+	SetDiagnosticsWriter(ioutil.Discard)
+	defer SetDiagnosticsWriter(os.Stderr)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:10002")
+	assert.NoError(t, err, "Unexpected listen 127.0.0.1:10002 error")
+	defer listener.Close()
+
+	// The peer accepts connections but never reads, so writes stall once
+	// the socket buffers are full.
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			connect, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- connect
+		}
+	}()
+
+	option := NewNetworkSyncerOption()
+	option.UseProtocol(ProtocolTCP)
+	option.UseAddress("127.0.0.1:10002")
+	option.UseCacheCapacity(0)
+	option.UseWriteTimeout(time.Millisecond * 50)
+
+	assert.Equal(t, time.Millisecond * 50, option.WriteTimeout,
+		"Unexpected option value")
+
+	syncer, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	buffer := make([]byte, 1024 * 64)
+	for count := 0; count < 4096; count++ {
+		if _, err = syncer.Write(buffer); err != nil {
+			break
+		}
+	}
+	var netErr net.Error
+	assert.True(t, errors.As(err, &netErr) && netErr.Timeout(),
+		"Unexpected write error")
+
+	// The timed-out write triggers the reconnection.
+	select {
+	case connect := <-accepted:
+		connect.Close()
+	case <-time.After(time.Second):
+		assert.Fail(t, "Unexpected accept timeout")
+	}
+	select {
+	case connect := <-accepted:
+		connect.Close()
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "Unexpected reconnection timeout")
+	}
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+}
+
+type testTimeoutError struct { }
+
+func (testTimeoutError) Error() string {
+	return "i/o timeout"
+}
+
+func (testTimeoutError) Timeout() bool {
+	return true
+}
+
+func (testTimeoutError) Temporary() bool {
+	return true
+}
+
+func TestIsDisconnectError(t *testing.T) {
+	cases := []struct {
+		err error
+		expected bool
+	} {
+		{ err: net.ErrClosed, expected: true },
+		{ err: io.ErrClosedPipe, expected: true },
+		{ err: testTimeoutError { }, expected: true },
+		{ err: &net.OpError {
+			Op: "write",
+			Err: os.NewSyscallError("write", syscall.ECONNRESET),
+		}, expected: true },
+		{ err: &net.OpError {
+			Op: "write",
+			Err: os.NewSyscallError("write", syscall.EPIPE),
+		}, expected: true },
+		{ err: &net.OpError {
+			Op: "write",
+			Err: os.NewSyscallError("write", syscall.ECONNREFUSED),
+		}, expected: true },
+		{ err: &net.OpError {
+			Op: "write",
+			Err: os.NewSyscallError("write", syscall.ENOBUFS),
+		}, expected: false },
+		{ err: errors.New("Error"), expected: false },
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, isDisconnectError(c.err),
+			"Unexpected result for %v", c.err)
+	}
+}
+
+type testFakeConn struct {
+	net.Conn
+	err error
+	closed int32
+}
+
+func (c *testFakeConn) Write(buffer []byte) (int, error) {
+	return 0, c.err
+}
+
+func (c *testFakeConn) SetWriteDeadline(deadline time.Time) error {
+	return nil
+}
+
+func (c *testFakeConn) Close() error {
+	atomic.StoreInt32(&c.closed, 1)
+	return nil
+}
+
+func TestNetworkSyncerDisconnect(t *testing.T) {
+	SetDiagnosticsWriter(ioutil.Discard)
+	defer SetDiagnosticsWriter(os.Stderr)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:10003")
+	assert.NoError(t, err, "Unexpected listen 127.0.0.1:10003 error")
+	defer listener.Close()
+
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			connect, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- connect
+		}
+	}()
+
+	syncer, err := NewNetworkSyncerOption().
+		UseProtocol(ProtocolTCP).
+		UseAddress("127.0.0.1:10003").
+		UseCacheCapacity(0).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	(<-accepted).Close()
+
+	// Errors that do not mean disconnection do not trigger reconnection.
+	fake := &testFakeConn { err: &net.OpError {
+		Op: "write",
+		Err: os.NewSyscallError("write", syscall.ENOBUFS),
+	} }
+	syncer.balancer.connections[0].replace(fake)
+	_, err = syncer.Write([]byte("Hello Test!"))
+	assert.Error(t, err, "Unexpected write error")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&syncer.balancer.connections[0].disconnected),
+		"Unexpected disconnected state")
+
+	fake.err = &net.OpError {
+		Op: "write",
+		Err: os.NewSyscallError("write", syscall.ECONNRESET),
+	}
+	_, err = syncer.Write([]byte("Hello Test!"))
+	assert.True(t, errors.Is(err, syscall.ECONNRESET), "Unexpected write error")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fake.closed),
+		"Unexpected closed state")
+
+	select {
+	case connect := <-accepted:
+		connect.Close()
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "Unexpected reconnection timeout")
+	}
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+}
+
+func TestNetworkSyncerConnections(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:10004")
+	assert.NoError(t, err, "Unexpected listen 127.0.0.1:10004 error")
+	defer listener.Close()
+
+	received := make(chan int, 16)
+	go func() {
+		for {
+			connect, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer connect.Close()
+				data, _ := ioutil.ReadAll(connect)
+				received <- len(data)
+			}()
+		}
+	}()
+
+	option := NewNetworkSyncerOption()
+	option.UseProtocol(ProtocolTCP)
+	option.UseAddress("127.0.0.1:10004")
+	option.UseCacheCapacity(0)
+
+	_, err = option.UseConnections(-1).Build()
+	assert.Equal(t, ErrInvalidConnections, err, "Unexpected build error")
+
+	syncer, err := option.UseConnections(3).Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.Equal(t, 3, option.Connections, "Unexpected option value")
+
+	for index := 0; index < 6; index++ {
+		_, err = syncer.Write([]byte("Hello Test!"))
+		assert.NoError(t, err, "Unexpected write error")
+	}
+
+	health := syncer.Health()
+	assert.Len(t, health, 3, "Unexpected connection count")
+	for _, connection := range health {
+		assert.Equal(t, NetworkConnectionHealth {
+			Connected: true,
+			Writes: 2,
+		}, connection, "Unexpected connection health")
+	}
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+
+	for index := 0; index < 3; index++ {
+		assert.Equal(t, 22, <-received, "Unexpected received data")
+	}
+}
+
+func TestNetworkSyncerUnixgram(t *testing.T) {
+	if !unixSocketsSupported {
+		t.Skip("Unix Domain Sockets are unsupported")
+	}
+	directory, err := ioutil.TempDir("", "santa")
+	assert.NoError(t, err, "Unexpected create error")
+	defer os.RemoveAll(directory)
+
+	addresses := []string { directory + "/santa.sock" }
+	if runtime.GOOS == "linux" {
+		addresses = append(addresses, "@santa-test-" + strconv.Itoa(
+			os.Getpid()))
+	}
+	for _, address := range addresses {
+		listener, err := net.ListenUnixgram(ProtocolUnixgram, &net.UnixAddr {
+			Name: address,
+			Net: ProtocolUnixgram,
+		})
+		assert.NoError(t, err, "Unexpected listen %s error", address)
+
+		option := NewNetworkSyncerOption()
+		option.UseProtocol(ProtocolUnixgram)
+		option.UseAddress(address)
+
+		syncer, err := option.Build()
+		assert.NoError(t, err, "Unexpected build error")
+		assert.False(t, syncer.cached, "Unexpected instance error")
+
+		for _, message := range []string { "Hello Test!", "Bye!" } {
+			_, err = syncer.Write([]byte(message))
+			assert.NoError(t, err, "Unexpected write error")
+		}
+
+		// Each write is received as a separate datagram.
+		buffer := make([]byte, 1024)
+		for _, message := range []string { "Hello Test!", "Bye!" } {
+			size, err := listener.Read(buffer)
+			assert.NoError(t, err, "Unexpected read error")
+			assert.Equal(t, message, string(buffer[ : size]),
+				"Unexpected datagram")
+		}
+
+		assert.NoError(t, syncer.Close(), "Unexpected close error")
+		assert.NoError(t, listener.Close(), "Unexpected close error")
+	}
+}
+
+func TestSyncerOptionValidate(t *testing.T) {
+	assert.NoError(t, NewSyncerOption().Validate(), "Unexpected validate error")
+
+	_, err := NewStandardSyncerOption().UseCacheCapacity(-1).Build()
+	assert.True(t, errors.Is(err, ErrInvalidCacheCapacity),
+		"Unexpected build error")
+	assert.Equal(t, "invalid cache capacity: -1", err.Error(),
+		"Unexpected build error")
+
+	option := NewFileSyncerOption()
+	option.CacheShards = -1
+	_, err = option.Build()
+	assert.True(t, errors.Is(err, ErrInvalidCacheShards),
+		"Unexpected build error")
+
+	_, err = NewNetworkSyncerOption().UseProtocol("invalid").Build()
+	assert.Equal(t, ErrInvalidProtocol, err, "Unexpected build error")
+}
+
+func TestStandardSyncerBanners(t *testing.T) {
+	writer := &testLockedWriter { }
+	syncer, err := NewStandardSyncerOption().UseWriter(writer).
+		UseBanners(TextBanner("time,level,message\n"),
+			TextBanner("# end\n")).Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	_, err = syncer.Write([]byte("1,info,hello\n"))
+	assert.NoError(t, err, "Unexpected write error")
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+	assert.Equal(t, "time,level,message\n1,info,hello\n# end\n",
+		string(writer.data), "Unexpected written data")
+}
+
+func TestProcessBanner(t *testing.T) {
+	banner := string(ProcessBanner("log started")(nil))
+	assert.True(t, strings.HasPrefix(banner, "log started at "),
+		"Unexpected banner")
+	assert.Contains(t, banner, "(pid " + strconv.Itoa(os.Getpid()) + ", ",
+		"Unexpected banner")
+	assert.True(t, strings.HasSuffix(banner, ")\n"), "Unexpected banner")
+}
@@ -22,7 +22,7 @@
 
 package santa
 
-import "sync/atomic"
+import "time"
 
 // TemplateLogger is the structure of the template logger instance.
 //
@@ -49,10 +49,10 @@ import "sync/atomic"
 // details, please refer to the comment section of the Syncer interface.
 //
 // Unless explicitly stated, the API provided by the logger is
-// thread-safe. It’s worth noting that APIs that allow post-build
-// changes to logger instances are generally not thread-safe. If you
-// need to change the logger instance (including but not limited to:
-// minimum log entry level, etc.), use the Duplicate function to create
+// thread-safe, including the APIs that allow post-build changes to
+// logger instances (including but not limited to: minimum log entry
+// level, etc.). If you need to change the logger instance without
+// affecting other users of it, use the Duplicate function to create
 // a copy of the logger instance, and then make changes to the copy of
 // the logger instance.
 type TemplateLogger struct {
@@ -119,19 +119,40 @@ func (l *TemplateLogger) Fatalf(template string, args ...interface { }) error {
 	return err
 }
 
+// DPanicf outputs a template log message with a log level of ERROR, a
+// given template string and one or more parameters, and then returns any
+// errors encountered. If the logger is in development mode, it panics
+// after the log entry is output. For details, please refer to the comment
+// section of the Development option.
+func (l *TemplateLogger) DPanicf(template string, args ...interface { }) error {
+	message := pool.Message.Template.New(template, args)
+	err := l.OutputDPanic(2, message)
+	pool.Message.Template.Free(message)
+	return err
+}
+
 // Duplicate creates and returns a copy of the logger. If the logger is
 // closed, it returns nil.
 //
 // Please note that the application must explicitly close each copy of
 // the logger, otherwise the logger may be leaked.
 func (l *TemplateLogger) Duplicate() *TemplateLogger {
-	if atomic.AddInt32(l.contextReferences, 1) == 1 {
-		// The logger has been shut down, and using the created copy
-		// may cause panic.
+	instance, ok := l.duplicate()
+	if !ok {
 		return nil
 	}
-	instance := *l
-	return &instance
+	return &TemplateLogger {
+		StandardLogger: instance,
+	}
+}
+
+// Decorator gets and returns a template decorator instance of the logger
+// from the global pool. For details, please refer to the comment section
+// of the TemplateDecorator structure.
+func (l *TemplateLogger) Decorator() *TemplateDecorator {
+	instance := pool.decorator.template.Get().(*TemplateDecorator)
+	instance.reset(&l.Logger)
+	return instance
 }
 
 // TemplateOption is a structure that contains options for the template
@@ -180,6 +201,87 @@ func (o *TemplateOption) UseLabels(labels ...Label) *TemplateOption {
 	return o
 }
 
+// UseClock uses the given clock as the value of the option Clock. For
+// details, please refer to the comment section of the Clock option. Then
+// return to the option instance itself.
+func (o *TemplateOption) UseClock(clock Clock) *TemplateOption {
+	o.Clock = clock
+	return o
+}
+
+// UseClockResolution uses the given resolution as the value of the option
+// ClockResolution. For details, please refer to the comment section of the
+// ClockResolution option. Then return to the option instance itself.
+func (o *TemplateOption) UseClockResolution(resolution time.Duration) *TemplateOption {
+	o.ClockResolution = resolution
+	return o
+}
+
+// UseSequence enables the option Sequence. For details, please refer to
+// the comment section of the Sequence option. Then return to the option
+// instance itself.
+func (o *TemplateOption) UseSequence() *TemplateOption {
+	o.Sequence = true
+	return o
+}
+
+// UseErrorHandler uses the given function as the value of the option
+// ErrorHandler. For details, please refer to the comment section of the
+// ErrorHandler option. Then return to the option instance itself.
+func (o *TemplateOption) UseErrorHandler(handler func(error)) *TemplateOption {
+	o.ErrorHandler = handler
+	return o
+}
+
+// UseSourceLocationLevel uses the given log level as the value of the
+// option SourceLocationLevel. For details, please refer to the comment
+// section of the SourceLocationLevel option. Then return to the option
+// instance itself.
+func (o *TemplateOption) UseSourceLocationLevel(level Level) *TemplateOption {
+	o.SourceLocationLevel = level
+	return o
+}
+
+// UseCallerSkip uses the given number of stack frames as the value of the
+// option CallerSkip. For details, please refer to the comment section of
+// the CallerSkip option. Then return to the option instance itself.
+func (o *TemplateOption) UseCallerSkip(skip int) *TemplateOption {
+	o.CallerSkip = skip
+	return o
+}
+
+// UseBestEffort enables the option BestEffort. For details, please refer
+// to the comment section of the BestEffort option. Then return to the
+// option instance itself.
+func (o *TemplateOption) UseBestEffort() *TemplateOption {
+	o.BestEffort = true
+	return o
+}
+
+// UseDevelopment enables the option Development. For details, please refer
+// to the comment section of the Development option. Then return to the
+// option instance itself.
+func (o *TemplateOption) UseDevelopment() *TemplateOption {
+	o.Development = true
+	return o
+}
+
+// UseProfiling enables the option Profiling. For details, please refer
+// to the comment section of the Profiling option. Then return to the
+// option instance itself.
+func (o *TemplateOption) UseProfiling() *TemplateOption {
+	o.Profiling = true
+	return o
+}
+
+// UseExporters appends the given one or more exporters to the o.Exporters
+// option slice, and then returns the option instance itself. For details,
+// please refer to the comment section of the o.Exporters option.
+func (o *TemplateOption) UseExporters(exporters ...Exporter) *TemplateOption {
+	o.Exporters = append(o.Exporters, exporters...)
+	return o
+}
+
 // UseEncoding uses the given encoding option as the value of the option
 // Encoding, please refer to the comment section of the Encoding option for
 // details. Then return to the option instance itself.
@@ -212,6 +314,16 @@ func (o *TemplateOption) UseFlushing(option *FlushingOption) *TemplateOption {
 	return o
 }
 
+// UseExporterFlushing appends the given exporter to the Exporters option,
+// and uses the given flushing option as the option for automatic flushing
+// of the exporter. For details, see the comment section of the
+// ExporterFlushing option. Then return to the option instance itself.
+func (o *TemplateOption) UseExporterFlushing(exporter Exporter,
+	option *FlushingOption) *TemplateOption {
+	o.StandardOption.UseExporterFlushing(exporter, option)
+	return o
+}
+
 // DisableCache disable the internal cache of output and error output. For
 // details, please refer to the DisableCache option of the OutputtingOption
 // structure. Then return to the option instance itself.
@@ -237,6 +349,15 @@ func (o *TemplateOption) DisableFlushing() *TemplateOption {
 	return o
 }
 
+// Clone creates and returns a deep copy of the option instance. For
+// details, please refer to the comment section of the StandardOption.Clone
+// function.
+func (o *TemplateOption) Clone() *TemplateOption {
+	return &TemplateOption {
+		StandardOption: *o.StandardOption.Clone(),
+	}
+}
+
 // Build builds and returns a template logger instance.
 func (o *TemplateOption) Build() (*TemplateLogger, error) {
 	logger, err := o.StandardOption.Build()
@@ -248,6 +369,14 @@ func (o *TemplateOption) Build() (*TemplateLogger, error) {
 	}, nil
 }
 
+// MustBuild builds and returns a template logger instance. If any errors
+// are encountered, it panics with an error describing the failure.
+func (o *TemplateOption) MustBuild() *TemplateLogger {
+	instance, err := o.Build()
+	mustBuild("template logger", err)
+	return instance
+}
+
 // NewTemplateOption creates an instance of a template logger option with
 // default optional values.
 func NewTemplateOption() *TemplateOption {
@@ -257,9 +386,20 @@ func NewTemplateOption() *TemplateOption {
 }
 
 // NewTemplate creates and returns a template logger instance using default
-// optional values.
-func NewTemplate() (*TemplateLogger, error) {
-	return NewTemplateOption().Build()
+// optional values changed by the given option functions.
+func NewTemplate(options ...OptionFunc) (*TemplateLogger, error) {
+	option := NewTemplateOption()
+	option.Apply(options...)
+	return option.Build()
+}
+
+// MustNewTemplate creates and returns a template logger instance using
+// default optional values changed by the given option functions. If any
+// errors are encountered, it panics with an error describing the failure.
+func MustNewTemplate(options ...OptionFunc) *TemplateLogger {
+	option := NewTemplateOption()
+	option.Apply(options...)
+	return option.MustBuild()
 }
 
 // NewTemplateBenchmark creates and returns an instance of a template logger
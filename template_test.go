@@ -69,13 +69,13 @@ func TestTemplateLoggerOption(t *testing.T) {
 	logger, err := option.Build()
 	assert.NoError(t, err, "Unexpected build error")
 
-	assert.NotNil(t, logger.sampler, "Unexpected instance error")
+	assert.NotNil(t, logger.loadSettings().sampler, "Unexpected instance error")
 	assert.Len(t, logger.exporters, 2, "Unexpected instance error")
 	assert.NotNil(t, logger.exporters[0], "Unexpected instance error")
 	assert.NotNil(t, logger.exporters[1], "Unexpected instance error")
 
-	assert.Equal(t, option.Level, logger.level, "Unexpected instance error")
-	assert.Equal(t, option.Name, logger.name, "Unexpected instance error")
+	assert.Equal(t, option.Level, logger.loadSettings().level, "Unexpected instance error")
+	assert.Equal(t, option.Name, logger.loadSettings().name, "Unexpected instance error")
 
 	option.DisableCache()
 	option.DisableFlushing()
@@ -155,9 +155,46 @@ func TestTemplateLoggerDuplicate(t *testing.T) {
 	assert.NotNil(t, instance, "Unexpected nil value")
 
 	instance.SetName("testing")
-	assert.Equal(t, "testing", instance.name, "Unexpected instance error")
-	assert.Equal(t, "", logger.name, "Unexpected instance error")
+	assert.Equal(t, "testing", instance.loadSettings().name, "Unexpected instance error")
+	assert.Equal(t, "", logger.loadSettings().name, "Unexpected instance error")
 
 	assert.NoError(t, instance.Close(), "Unexpected close error")
 	assert.NoError(t, logger.Close(), "Unexpected close error")
 }
+
+func TestTemplateLoggerFormatOnce(t *testing.T) {
+	exporter, err := NewStandardExporter()
+	assert.NoError(t, err, "Unexpected create error")
+
+	option := NewTemplateOption().UseExporters(exporter)
+	option.Outputting.UseDiscard()
+	option.ErrorOutputting.UseDiscard()
+	option.DisableSampling()
+
+	logger, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+
+	stringer := &testCountingStringer { }
+	assert.NoError(t, logger.Infof("Hello %s!", stringer),
+		"Unexpected print error")
+	assert.Equal(t, 1, stringer.count, "Unexpected format count")
+
+	logger.SetSampler(testDiscardSampler { })
+	assert.NoError(t, logger.Infof("Hello %s!", stringer),
+		"Unexpected print error")
+	assert.Equal(t, 1, stringer.count, "Unexpected format count")
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+}
+
+func TestTemplateLoggerMustBuild(t *testing.T) {
+	logger := MustNewTemplate()
+	assert.NotNil(t, logger, "Unexpected logger instance")
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+
+	option := NewTemplateOption()
+	option.Outputting.Type = "invalid"
+	assert.PanicsWithError(t, "santa: failed to build template logger: " +
+		"invalid type", func() {
+		option.MustBuild()
+	}, "Unexpected panic error")
+}
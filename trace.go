@@ -0,0 +1,178 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync/atomic"
+)
+
+var (
+	// ErrInvalidTraceParent represents that the given value of the W3C
+	// traceparent header is malformed or contains an invalid trace ID or
+	// span ID.
+	ErrInvalidTraceParent = errors.New("invalid traceparent")
+)
+
+// SpanContext is a structure that contains the W3C trace context of a
+// span of a distributed trace, which is used to join log entries with
+// distributed traces.
+type SpanContext struct {
+	// TraceID represents the identifier of the trace.
+	TraceID [16]byte
+
+	// SpanID represents the identifier of the span.
+	SpanID [8]byte
+
+	// TraceFlags represents the W3C trace flags of the span, such as the
+	// sampled flag 0x01.
+	TraceFlags byte
+}
+
+// IsValid checks whether both the trace ID and the span ID of the span
+// context are not all zeros.
+func (c SpanContext) IsValid() bool {
+	return c.TraceID != [16]byte { } && c.SpanID != [8]byte { }
+}
+
+// TraceParent returns the value of the W3C traceparent header of the span
+// context, such as
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func (c SpanContext) TraceParent() string {
+	return "00-" + hex.EncodeToString(c.TraceID[ : ]) + "-" +
+		hex.EncodeToString(c.SpanID[ : ]) + "-" +
+		hex.EncodeToString([]byte { c.TraceFlags })
+}
+
+// Fields returns the fields of the span context in the W3C format, which
+// are trace_id, span_id and trace_flags, such as
+// "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7" and "01".
+func (c SpanContext) Fields() []Field {
+	return []Field {
+		String("trace_id", hex.EncodeToString(c.TraceID[ : ])),
+		String("span_id", hex.EncodeToString(c.SpanID[ : ])),
+		String("trace_flags", hex.EncodeToString([]byte { c.TraceFlags })),
+	}
+}
+
+// ParseTraceParent parses and returns the span context of the given value
+// of the W3C traceparent header. If the value is malformed or the span
+// context is not valid, ErrInvalidTraceParent is returned.
+func ParseTraceParent(value string) (SpanContext, error) {
+	var span SpanContext
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		(parts[0] == "00" && len(parts) != 4) || len(parts[1]) != 32 ||
+		len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return span, ErrInvalidTraceParent
+	}
+	var flags [1]byte
+	if _, err := hex.Decode(span.TraceID[ : ], []byte(parts[1])); err != nil {
+		return span, ErrInvalidTraceParent
+	}
+	if _, err := hex.Decode(span.SpanID[ : ], []byte(parts[2])); err != nil {
+		return span, ErrInvalidTraceParent
+	}
+	if _, err := hex.Decode(flags[ : ], []byte(parts[3])); err != nil {
+		return span, ErrInvalidTraceParent
+	}
+	span.TraceFlags = flags[0]
+	if !span.IsValid() {
+		return span, ErrInvalidTraceParent
+	}
+	return span, nil
+}
+
+// spanContextKey is the type of the key of the span context stored in
+// contexts.
+type spanContextKey struct { }
+
+// spanContextExtractor is the structure that holds the span context
+// extractor registered by the SetSpanContextExtractor function.
+type spanContextExtractor struct {
+	extract func(ctx context.Context) (SpanContext, bool)
+}
+
+// spanExtractor stores the registered span context extractor.
+var spanExtractor atomic.Value
+
+// SetSpanContextExtractor registers the given function that extracts the
+// span context from contexts, which allows the span contexts of tracing
+// libraries to be used without depending on them. For example, for
+// OpenTelemetry:
+//
+//	santa.SetSpanContextExtractor(func(ctx context.Context) (santa.SpanContext, bool) {
+//		span := trace.SpanContextFromContext(ctx)
+//		return santa.SpanContext {
+//			TraceID: span.TraceID(),
+//			SpanID: span.SpanID(),
+//			TraceFlags: byte(span.TraceFlags()),
+//		}, span.IsValid()
+//	})
+//
+// If the given function is nil, the registered function is removed.
+//
+// This function is thread-safe.
+func SetSpanContextExtractor(extract func(ctx context.Context) (SpanContext, bool)) {
+	spanExtractor.Store(spanContextExtractor { extract: extract })
+}
+
+// ContextWithSpanContext returns a copy of the given context that stores
+// the given span context.
+func ContextWithSpanContext(ctx context.Context, span SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey { }, span)
+}
+
+// SpanContextFromContext returns the span context stored in the given
+// context by the ContextWithSpanContext function, or extracted from the
+// given context by the function registered by the SetSpanContextExtractor
+// function, and whether a valid span context is found.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	if span, ok := ctx.Value(spanContextKey { }).(SpanContext); ok &&
+		span.IsValid() {
+		return span, true
+	}
+	if registered, ok := spanExtractor.Load().(spanContextExtractor); ok &&
+		registered.extract != nil {
+		if span, ok := registered.extract(ctx); ok && span.IsValid() {
+			return span, true
+		}
+	}
+	return SpanContext { }, false
+}
+
+// TraceContext returns a field named "trace" whose value is an object of
+// the fields of the span context of the given context. For details,
+// please refer to the Fields function of the SpanContext structure. If
+// the given context does not have a valid span context, a nil field is
+// returned.
+func TraceContext(ctx context.Context) Field {
+	span, ok := SpanContextFromContext(ctx)
+	if !ok {
+		return Nil("trace")
+	}
+	return Object("trace", span.Fields()...)
+}
@@ -0,0 +1,106 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceParent(t *testing.T) {
+	span, err := ParseTraceParent(testTraceParent)
+	assert.NoError(t, err, "Unexpected parse error")
+	assert.True(t, span.IsValid(), "Unexpected span context")
+	assert.Equal(t, byte(1), span.TraceFlags, "Unexpected span context")
+	assert.Equal(t, testTraceParent, span.TraceParent(),
+		"Unexpected traceparent")
+
+	for _, value := range []string { "",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902zz-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		testTraceParent + "-extra" } {
+		_, err = ParseTraceParent(value)
+		assert.Equal(t, ErrInvalidTraceParent, err, "Unexpected parse error")
+	}
+}
+
+func TestTraceContext(t *testing.T) {
+	span, _ := ParseTraceParent(testTraceParent)
+	ctx := ContextWithSpanContext(context.Background(), span)
+	found, ok := SpanContextFromContext(ctx)
+	assert.True(t, ok, "Unexpected span context")
+	assert.Equal(t, span, found, "Unexpected span context")
+
+	field := TraceContext(ctx)
+	assert.Equal(t, `{"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736", ` +
+		`"span_id": "00f067aa0ba902b7", "trace_flags": "01"}`,
+		string(field.SerializeJSON(nil)), "Unexpected field value")
+	assert.Equal(t, TypeNil, TraceContext(context.Background()).Type,
+		"Unexpected field type")
+
+	// The registered extractor is used if the context does not store a
+	// span context.
+	type key struct { }
+	SetSpanContextExtractor(func(ctx context.Context) (SpanContext, bool) {
+		span, ok := ctx.Value(key { }).(SpanContext)
+		return span, ok
+	})
+	defer SetSpanContextExtractor(nil)
+	found, ok = SpanContextFromContext(context.WithValue(
+		context.Background(), key { }, span))
+	assert.True(t, ok, "Unexpected span context")
+	assert.Equal(t, span, found, "Unexpected span context")
+	_, ok = SpanContextFromContext(context.Background())
+	assert.False(t, ok, "Unexpected span context")
+}
+
+func TestStructLoggerWithContext(t *testing.T) {
+	logger, err := NewStruct()
+	assert.NoError(t, err, "Unexpected create error")
+	exporter := &testRecordExporter { }
+	logger.exporters = []Exporter { exporter }
+
+	span, _ := ParseTraceParent(testTraceParent)
+	decorator := logger.WithContext(ContextWithSpanContext(
+		context.Background(), span))
+	assert.NoError(t, decorator.Infos("Hello Test!"), "Unexpected print error")
+	decorator.Free()
+	decorator = logger.WithContext(context.Background())
+	assert.NoError(t, decorator.Infos("Hello Test!"), "Unexpected print error")
+	decorator.Free()
+
+	assert.Len(t, exporter.entries, 2, "Unexpected log entries")
+	assert.Equal(t, `{"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736", ` +
+		`"span_id": "00f067aa0ba902b7", "trace_flags": "01"}`,
+		string(exporter.entries[0].Message.(StructMessage).Fields.
+			SerializeJSON(nil)), "Unexpected log entry")
+	assert.Empty(t, exporter.entries[1].Message.(StructMessage).Fields,
+		"Unexpected log entry")
+	assert.NoError(t, logger.Close(), "Unexpected close error")
+}
@@ -0,0 +1,152 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+var (
+	// ErrMalformedOutput represents that the output of an encoder is not
+	// well-formed, usually because of a bug in escaping values.
+	ErrMalformedOutput = errors.New("malformed encoder output")
+)
+
+// MalformedOutputError is the structure of an error returned by the
+// validating encoder when the output of the wrapped encoder is malformed.
+// It contains the offending log entry, so that the bug can be reproduced.
+type MalformedOutputError struct {
+	// Entry represents a copy of the log entry whose output is malformed.
+	Entry *Entry
+
+	// Output represents a copy of the malformed output.
+	Output []byte
+}
+
+// Error returns the error message, which contains the malformed output.
+func (e *MalformedOutputError) Error() string {
+	return ErrMalformedOutput.Error() + ": " + strconv.Quote(string(e.Output))
+}
+
+// Unwrap returns ErrMalformedOutput.
+func (e *MalformedOutputError) Unwrap() error {
+	return ErrMalformedOutput
+}
+
+// ValidatingEncoder is the structure of the validating encoder instance.
+//
+// The validating encoder wraps an encoder that produces JSON (for example,
+// the JSON encoder) and checks that the output of each log entry is a
+// single well-formed JSON value. Malformed output is not returned, instead
+// a MalformedOutputError containing the offending log entry is returned,
+// which catches escaping bugs before they corrupt downstream pipelines.
+//
+// Validation is expensive, so it is only enabled in debug builds (built
+// with the santadebug build tag), unless the Force option is enabled, for
+// example in tests and fuzzing. Otherwise the validating encoder only
+// forwards to the wrapped encoder.
+type ValidatingEncoder struct {
+	encoder Encoder
+	validate bool
+}
+
+// Encode encodes the given log entry using the wrapped encoder, and then
+// validates the output if validation is enabled. For details, please refer
+// to the comment section of the ValidatingEncoder structure.
+func (e *ValidatingEncoder) Encode(buffer []byte, entry *Entry) ([]byte, error) {
+	start := len(buffer)
+	buffer, err := e.encoder.Encode(buffer, entry)
+	if err != nil || !e.validate {
+		return buffer, err
+	}
+	if !json.Valid(buffer[start : ]) {
+		return buffer[ : start], &MalformedOutputError {
+			Entry: entry.Clone(),
+			Output: append([]byte(nil), buffer[start : ]...),
+		}
+	}
+	return buffer, nil
+}
+
+// Option returns the value of the basic options of the wrapped encoder.
+func (e *ValidatingEncoder) Option() EncoderOption {
+	return e.encoder.Option()
+}
+
+// ValidatingEncoderOption is a structure containing options for the
+// validating encoder.
+type ValidatingEncoderOption struct {
+	// Encoder represents the wrapped encoder, which must produce JSON. If
+	// not provided, the default value is the JSON encoder.
+	Encoder Encoder
+
+	// Force represents whether to validate the output even if it is not a
+	// debug build. If not provided, the default value is false.
+	Force bool
+}
+
+// UseEncoder uses the given encoder as the value of the Encoder option.
+// For details, please refer to the comment section of the Encoder option.
+// Then return to the option instance itself.
+func (o *ValidatingEncoderOption) UseEncoder(encoder Encoder) *ValidatingEncoderOption {
+	o.Encoder = encoder
+	return o
+}
+
+// UseForce enables the Force option. For details, please refer to the
+// comment section of the Force option. Then return to the option instance
+// itself.
+func (o *ValidatingEncoderOption) UseForce() *ValidatingEncoderOption {
+	o.Force = true
+	return o
+}
+
+// Build builds and returns an instance of the validating encoder.
+func (o *ValidatingEncoderOption) Build() (*ValidatingEncoder, error) {
+	encoder := o.Encoder
+	if encoder == nil {
+		instance, err := NewJSONEncoder()
+		if err != nil {
+			return nil, err
+		}
+		encoder = instance
+	}
+	return &ValidatingEncoder {
+		encoder: encoder,
+		validate: o.Force || debugBuild,
+	}, nil
+}
+
+// NewValidatingEncoderOption creates and returns a validating encoder
+// option instance with default optional values.
+func NewValidatingEncoderOption() *ValidatingEncoderOption {
+	return &ValidatingEncoderOption { }
+}
+
+// NewValidatingEncoder creates and returns a validating encoder instance
+// that wraps the given encoder, using the default optional values.
+func NewValidatingEncoder(encoder Encoder) (*ValidatingEncoder, error) {
+	return NewValidatingEncoderOption().UseEncoder(encoder).Build()
+}
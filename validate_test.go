@@ -0,0 +1,88 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package santa
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testBrokenEncoder struct {
+	EncoderOption
+}
+
+func (e *testBrokenEncoder) Encode(buffer []byte, entry *Entry) ([]byte, error) {
+	buffer = append(buffer, `{"message":"`...)
+	buffer = append(buffer, messageText(entry.Message)...)
+	return append(buffer, "\"}\n"...), nil
+}
+
+func (e *testBrokenEncoder) Option() EncoderOption {
+	return e.EncoderOption
+}
+
+func TestValidatingEncoder(t *testing.T) {
+	encoder, err := NewValidatingEncoderOption().UseForce().Build()
+	if !assert.NoError(t, err, "Unexpected build error") {
+		return
+	}
+	valid := entry.Clone()
+	valid.Message = StructMessage {
+		Text: "Hello Test!",
+		Fields: ElementObject {
+			String("unicode", "你好"),
+			Ints("values", []int64 { 1, 2 }),
+		},
+	}
+	buffer, err := encoder.Encode([]byte("prefix"), valid)
+	assert.NoError(t, err, "Unexpected encode error")
+	assert.Greater(t, len(buffer), len("prefix"), "Unexpected encode output")
+
+	invalid := entry.Clone()
+	invalid.Message = StringMessage("quote \" and newline \n")
+
+	encoder, err = NewValidatingEncoderOption().UseForce().
+		UseEncoder(&testBrokenEncoder { }).Build()
+	if !assert.NoError(t, err, "Unexpected build error") {
+		return
+	}
+	buffer, err = encoder.Encode([]byte("prefix"), invalid)
+	assert.Equal(t, "prefix", string(buffer), "Unexpected encode output")
+	assert.True(t, errors.Is(err, ErrMalformedOutput), "Unexpected encode error")
+	var malformed *MalformedOutputError
+	if assert.True(t, errors.As(err, &malformed), "Unexpected encode error") {
+		assert.Equal(t, invalid.Message, malformed.Entry.Message,
+			"Unexpected error entry")
+		assert.Contains(t, string(malformed.Output), "quote",
+			"Unexpected error output")
+	}
+
+	encoder, err = NewValidatingEncoder(&testBrokenEncoder { })
+	if !assert.NoError(t, err, "Unexpected build error") {
+		return
+	}
+	_, err = encoder.Encode(nil, invalid)
+	assert.Equal(t, debugBuild, err != nil, "Unexpected encode error")
+}
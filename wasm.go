@@ -0,0 +1,56 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build js || wasip1
+// +build js wasip1
+
+package santa
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// unixSocketsSupported represents whether Unix Domain Sockets are supported
+// on the current platform. WebAssembly platforms cannot create them, so
+// the network synchronizer rejects the Unix protocols.
+const unixSocketsSupported = false
+
+// hangupSignals represents the signals that reopen files when the
+// ReopenOnHangup option of the file synchronizer is enabled. WebAssembly
+// platforms do not deliver any hangup signal.
+var hangupSignals []os.Signal
+
+// isUnsyncableError checks whether the given error returned by the Sync
+// function of a file is caused by the file not supporting synchronization.
+// The regular parameter represents whether the file is a regular file.
+// In addition to the errors of pipes and terminals ignored on the other
+// platforms, the file systems of WebAssembly hosts may not implement
+// synchronization at all, even for regular files.
+func isUnsyncableError(err error, regular bool) bool {
+	if errors.Is(err, syscall.ENOSYS) {
+		return true
+	}
+	return !regular && (errors.Is(err, syscall.EINVAL) ||
+		errors.Is(err, syscall.ENOTSUP))
+}
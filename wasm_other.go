@@ -0,0 +1,55 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build !js && !wasip1
+// +build !js,!wasip1
+
+package santa
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// unixSocketsSupported represents whether Unix Domain Sockets are supported
+// on the current platform.
+const unixSocketsSupported = true
+
+// hangupSignals represents the signals that reopen files when the
+// ReopenOnHangup option of the file synchronizer is enabled.
+var hangupSignals = []os.Signal { syscall.SIGHUP }
+
+// isUnsyncableError checks whether the given error returned by the Sync
+// function of a file is caused by the file not supporting synchronization.
+// The regular parameter represents whether the file is a regular file.
+//
+// Pipes, terminals and character devices (for example, the standard output
+// of a container) reject fsync with EINVAL or ENOTSUP, although they hold
+// no cached data. The standard synchronizer ignores these errors, so that
+// the ExitFlush function can sync the console loggers registered with the
+// RegisterExitFlush function without reporting a failure on every exit.
+// The same errors of regular files are real synchronization failures.
+func isUnsyncableError(err error, regular bool) bool {
+	return !regular && (errors.Is(err, syscall.EINVAL) ||
+		errors.Is(err, syscall.ENOTSUP))
+}
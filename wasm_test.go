@@ -0,0 +1,52 @@
+// MIT License
+//
+// Copyright (c) 2020 Nobody Night
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build js || wasip1
+// +build js wasip1
+
+package santa
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWasmPlatform(t *testing.T) {
+	assert.Empty(t, hangupSignals, "Unexpected hangup signals")
+	assert.True(t, isUnsyncableError(syscall.ENOSYS, true),
+		"Unexpected unsyncable error")
+
+	err := NewNetworkSyncerOption().UseProtocol(ProtocolUnix).Validate()
+	assert.True(t, errors.Is(err, ErrInvalidProtocol),
+		"Unexpected validate error")
+
+	option := NewOutputtingOption().UseNetwork(ProtocolUnixgram,
+		"/run/systemd/journal/socket")
+	assert.NoError(t, option.Validate(), "Unexpected validate error")
+	syncer, err := option.Build()
+	assert.NoError(t, err, "Unexpected build error")
+	assert.IsType(t, &StandardSyncer { }, syncer, "Unexpected syncer")
+	assert.NoError(t, syncer.Close(), "Unexpected close error")
+}